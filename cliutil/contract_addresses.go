@@ -0,0 +1,45 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cliutil
+
+import (
+	"os"
+	"path/filepath"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+// ContractAddresses is the format of the {DATA_DIR}/contract-addresses.json file,
+// which is written after deploying contracts and read by swapd on startup.
+type ContractAddresses struct {
+	SwapCreatorAddr        ethcommon.Address `json:"swapCreatorAddr" validate:"required"`
+	ForwarderAddr          ethcommon.Address `json:"forwarderAddr" validate:"required"`
+	SwapCreatorDeployBlock uint64            `json:"swapCreatorDeployBlock,omitempty"`
+}
+
+// WriteContractAddressesToFile writes the contract addresses to the given file
+func WriteContractAddressesToFile(filePath string, addresses *ContractAddresses) error {
+	jsonData, err := vjson.MarshalIndentStruct(addresses, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Clean(filePath), jsonData, 0600)
+}
+
+// ReadContractAddressesFromFile reads the contract addresses from the given file
+func ReadContractAddressesFromFile(filePath string) (*ContractAddresses, error) {
+	jsonData, err := os.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := new(ContractAddresses)
+	if err = vjson.UnmarshalStruct(jsonData, addresses); err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
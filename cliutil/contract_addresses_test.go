@@ -0,0 +1,38 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cliutil
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestWriteAndReadContractAddresses(t *testing.T) {
+	filePath := path.Join(t.TempDir(), common.DefaultContractAddressesFileName)
+	addresses := &ContractAddresses{
+		SwapCreatorAddr:        ethcommon.HexToAddress("0x45cc2dB5021dc9C01513D9ee7914b61810bd6Ad6"),
+		ForwarderAddr:          ethcommon.HexToAddress("0xa030E074b8398005a454CB7c51E9b7CDb966744a"),
+		SwapCreatorDeployBlock: 3_000_000,
+	}
+
+	require.NoError(t, WriteContractAddressesToFile(filePath, addresses))
+	readAddresses, err := ReadContractAddressesFromFile(filePath)
+	require.NoError(t, err)
+	require.Equal(t, addresses, readAddresses)
+}
+
+func TestReadContractAddresses_missingField(t *testing.T) {
+	filePath := path.Join(t.TempDir(), common.DefaultContractAddressesFileName)
+	jsonData := []byte(`{"swapCreatorAddr": "0x45cc2dB5021dc9C01513D9ee7914b61810bd6Ad6"}`)
+	require.NoError(t, os.WriteFile(filePath, jsonData, 0600))
+
+	_, err := ReadContractAddressesFromFile(filePath)
+	require.ErrorContains(t, err, "ForwarderAddr")
+}
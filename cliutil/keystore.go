@@ -0,0 +1,127 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cliutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
+)
+
+var errKeystorePasswordRequired = errors.New("ethereum key file is an encrypted keystore, but no password was " +
+	"provided: pass it with a password flag or file, or start from a terminal to be prompted for it")
+
+// ReadEthPrivateKeyFile reads an ethereum private key file. The file can either
+// contain the hex encoded key or an encrypted keystore, which is decrypted with
+// the password.
+func ReadEthPrivateKeyFile(path string, keystorePassword string) (*ecdsa.PrivateKey, error) {
+	fileData, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eth-privkey file: %w", err)
+	}
+
+	if isEthKeystore(fileData) {
+		return decryptEthKeystore(fileData, keystorePassword)
+	}
+
+	return ethcrypto.HexToECDSA(strings.TrimSpace(string(fileData)))
+}
+
+// isEthKeystore returns true if the key file data is a JSON keystore rather than a
+// hex encoded private key.
+func isEthKeystore(fileData []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(fileData), []byte("{"))
+}
+
+// decryptEthKeystore decrypts a web3 secret storage (v3) keystore.
+func decryptEthKeystore(fileData []byte, password string) (*ecdsa.PrivateKey, error) {
+	if password == "" {
+		return nil, errKeystorePasswordRequired
+	}
+
+	key, err := keystore.DecryptKey(fileData, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt ethereum keystore: %w", err)
+	}
+
+	return key.PrivateKey, nil
+}
+
+// WriteEthKeystoreFile encrypts the private key with the password and writes it to
+// the given path as a web3 secret storage (v3) keystore. Any existing file at the
+// path is replaced atomically, so an interrupted write never loses the key.
+func WriteEthKeystoreFile(path string, privKey *ecdsa.PrivateKey, password string) error {
+	if password == "" {
+		return errors.New("keystore password cannot be empty")
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    ethcrypto.PubkeyToAddress(privKey.PublicKey),
+		PrivateKey: privKey,
+	}, password, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt ethereum key: %w", err)
+	}
+
+	tmpFile := filepath.Clean(path) + ".tmp"
+	if err = os.WriteFile(tmpFile, keyJSON, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, path)
+}
+
+// RestoreEthKeyFile recreates the ethereum key derived from the mnemonic in the
+// given key file and returns its address. If the file already holds a different
+// key, it is kept by renaming it with a ".bak" suffix instead of being overwritten.
+func RestoreEthKeyFile(ethPrivKeyFile string, mnemonic string, keystorePassword string) (ethcommon.Address, error) {
+	key, err := secp256k1.EthereumKeyFromMnemonic(mnemonic)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	address := ethcrypto.PubkeyToAddress(key.PublicKey)
+
+	exists, err := common.FileExists(ethPrivKeyFile)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	if exists {
+		existingKey, err := ReadEthPrivateKeyFile(ethPrivKeyFile, keystorePassword)
+		if err == nil && existingKey.D.Cmp(key.D) == 0 {
+			return address, nil
+		}
+
+		backupFile := fmt.Sprintf("%s.%d.bak", ethPrivKeyFile, time.Now().Unix())
+		if err = os.Rename(ethPrivKeyFile, backupFile); err != nil {
+			return ethcommon.Address{}, fmt.Errorf("failed to back up existing key file: %w", err)
+		}
+		log.Infof("Moved existing ETH key file to %s", backupFile)
+	}
+
+	if err = WriteEthKeyFile(ethPrivKeyFile, key, keystorePassword); err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	return address, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cliutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestWriteEthKeystoreFile(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	keyPath := getKeyPath(t)
+	const password = "hunter2"
+
+	require.NoError(t, WriteEthKeystoreFile(keyPath, key, password))
+	data, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	require.True(t, isEthKeystore(data))
+
+	readKey, err := ReadEthPrivateKeyFile(keyPath, password)
+	require.NoError(t, err)
+	require.Equal(t, key.D, readKey.D)
+
+	_, err = ReadEthPrivateKeyFile(keyPath, "")
+	require.ErrorIs(t, err, errKeystorePasswordRequired)
+
+	_, err = ReadEthPrivateKeyFile(keyPath, "wrong")
+	require.ErrorIs(t, err, keystore.ErrDecrypt)
+}
+
+func TestGetEthereumPrivateKey_newKeystore(t *testing.T) {
+	keyPath := getKeyPath(t)
+	const password = "hunter2"
+
+	key, err := GetEthereumPrivateKey(keyPath, password, common.Stagenet, false, false)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	require.True(t, isEthKeystore(data))
+
+	readKey, err := GetEthereumPrivateKey(keyPath, password, common.Stagenet, false, false)
+	require.NoError(t, err)
+	require.Equal(t, key.D, readKey.D)
+}
+
+func TestRestoreEthKeyFile(t *testing.T) {
+	const mnemonic = "test test test test test test test test test test test junk"
+	expectedAddr := "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	keyPath := getKeyPath(t)
+
+	// an existing, different key is moved out of the way
+	otherKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, WriteEthKeyFile(keyPath, otherKey, ""))
+
+	addr, err := RestoreEthKeyFile(keyPath, mnemonic, "")
+	require.NoError(t, err)
+	require.Equal(t, expectedAddr, addr.Hex())
+
+	key, err := ReadEthPrivateKeyFile(keyPath, "")
+	require.NoError(t, err)
+	require.Equal(t, expectedAddr, ethcrypto.PubkeyToAddress(key.PublicKey).Hex())
+
+	backups, err := filepath.Glob(keyPath + ".*.bak")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backupKey, err := ReadEthPrivateKeyFile(backups[0], "")
+	require.NoError(t, err)
+	require.Equal(t, otherKey.D, backupKey.D)
+
+	// restoring the same key again is a no-op
+	_, err = RestoreEthKeyFile(keyPath, mnemonic, "")
+	require.NoError(t, err)
+	backups, err = filepath.Glob(keyPath + ".*.bak")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+}
@@ -38,6 +38,7 @@ func SetLogLevelsFromContext(c *cli.Context) error {
 // SetLogLevels sets the log levels for all packages.
 func SetLogLevels(level string) {
 	// alphabetically ordered
+	_ = logging.SetLogLevel("backend", level)
 	_ = logging.SetLogLevel("bootnode", level)
 	_ = logging.SetLogLevel("cmd", level)
 	_ = logging.SetLogLevel("coins", level)
@@ -0,0 +1,107 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cliutil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+var errNotATerminal = errors.New("can't prompt for a password, standard input is not a terminal")
+
+// GetEthKeystorePassword returns the password of the ethereum key file. It is the
+// given password if set, otherwise the first line of the password file if set.
+// When neither is set and the key file is an encrypted keystore, the password is
+// prompted for if standard input is a terminal. An empty password is returned
+// otherwise.
+func GetEthKeystorePassword(password string, passwordFile string, ethPrivKeyFile string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+
+	if passwordFile != "" {
+		return ReadPasswordFile(passwordFile)
+	}
+
+	isKeystore, err := IsEthKeystoreFile(ethPrivKeyFile)
+	if err != nil {
+		return "", err
+	}
+	if !isKeystore || !stdinIsTerminal() {
+		return "", nil
+	}
+
+	return PromptPassword(fmt.Sprintf("Enter the password of the ethereum keystore %s: ", ethPrivKeyFile))
+}
+
+// ReadPasswordFile returns the first line of the password file, without the
+// line ending. The password can't be empty.
+func ReadPasswordFile(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file: %w", err)
+	}
+
+	password, _, _ := strings.Cut(string(data), "\n")
+	password = strings.TrimRight(password, "\r")
+	if password == "" {
+		return "", fmt.Errorf("password file %s is empty", path)
+	}
+
+	return password, nil
+}
+
+// PromptPassword prints the prompt and reads a password from standard input, which
+// must be a terminal. The password can't be empty.
+func PromptPassword(prompt string) (string, error) {
+	if !stdinIsTerminal() {
+		return "", errNotATerminal
+	}
+
+	fmt.Print(prompt)
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return "", errors.New("the password can't be empty")
+	}
+
+	return password, nil
+}
+
+// IsEthKeystoreFile returns true if the ethereum key file exists and is an
+// encrypted keystore rather than a hex encoded private key.
+func IsEthKeystoreFile(path string) (bool, error) {
+	exists, err := common.FileExists(path)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	fileData, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return false, fmt.Errorf("failed to read eth-privkey file: %w", err)
+	}
+
+	return isEthKeystore(fileData), nil
+}
+
+// stdinIsTerminal returns true if standard input is a terminal, as opposed to a
+// pipe, a file or /dev/null when running as a service.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
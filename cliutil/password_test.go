@@ -0,0 +1,73 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package cliutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPasswordFile(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+
+	require.NoError(t, os.WriteFile(passwordFile, []byte("hunter2\r\nignored\n"), 0600))
+	password, err := ReadPasswordFile(passwordFile)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", password)
+
+	require.NoError(t, os.WriteFile(passwordFile, []byte("hunter2"), 0600))
+	password, err = ReadPasswordFile(passwordFile)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", password)
+
+	require.NoError(t, os.WriteFile(passwordFile, []byte("\n"), 0600))
+	_, err = ReadPasswordFile(passwordFile)
+	require.ErrorContains(t, err, "is empty")
+
+	_, err = ReadPasswordFile(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "failed to read password file")
+}
+
+func TestGetEthKeystorePassword(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	hexKeyPath := getKeyPath(t)
+	require.NoError(t, WriteEthKeyFile(hexKeyPath, key, ""))
+	keystorePath := filepath.Join(t.TempDir(), "keystore.key")
+	require.NoError(t, WriteEthKeystoreFile(keystorePath, key, "hunter2"))
+
+	isKeystore, err := IsEthKeystoreFile(hexKeyPath)
+	require.NoError(t, err)
+	require.False(t, isKeystore)
+	isKeystore, err = IsEthKeystoreFile(keystorePath)
+	require.NoError(t, err)
+	require.True(t, isKeystore)
+	isKeystore, err = IsEthKeystoreFile(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	require.False(t, isKeystore)
+
+	// the password takes precedence over the password file
+	password, err := GetEthKeystorePassword("flag-password", "", keystorePath)
+	require.NoError(t, err)
+	require.Equal(t, "flag-password", password)
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("hunter2\n"), 0600))
+	password, err = GetEthKeystorePassword("", passwordFile, keystorePath)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", password)
+
+	readKey, err := ReadEthPrivateKeyFile(keystorePath, password)
+	require.NoError(t, err)
+	require.Equal(t, key.D, readKey.D)
+
+	// hex keys don't need a password, so none is prompted for
+	password, err = GetEthKeystorePassword("", "", hexKeyPath)
+	require.NoError(t, err)
+	require.Empty(t, password)
+}
@@ -8,7 +8,6 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"os"
-	"path/filepath"
 	"runtime/debug"
 	"strings"
 
@@ -19,6 +18,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
 )
 
 var (
@@ -26,9 +26,18 @@ var (
 	log = logging.Logger("cmd")
 )
 
-func createAndWriteEthKeyFile(ethPrivKeyFile string, env common.Environment, devXMRMaker, devXMRTaker bool) error {
-	var key *ecdsa.PrivateKey
-	var err error
+func createAndWriteEthKeyFile(
+	ethPrivKeyFile string,
+	keystorePassword string,
+	env common.Environment,
+	devXMRMaker bool,
+	devXMRTaker bool,
+) error {
+	var (
+		key      *ecdsa.PrivateKey
+		mnemonic string
+		err      error
+	)
 
 	switch {
 	case env == common.Development && devXMRMaker:
@@ -36,27 +45,66 @@ func createAndWriteEthKeyFile(ethPrivKeyFile string, env common.Environment, dev
 	case env == common.Development && devXMRTaker:
 		key, err = ethcrypto.HexToECDSA(common.DefaultPrivKeyXMRTaker)
 	default:
-		key, err = ethcrypto.GenerateKey()
+		// derive the key from a mnemonic, so the user has a backup that is
+		// independent of the key file
+		mnemonic, err = secp256k1.NewMnemonic()
+		if err != nil {
+			return err
+		}
+		key, err = secp256k1.EthereumKeyFromMnemonic(mnemonic)
 	}
 	if err != nil {
 		return err
 	}
 
-	privKeyStr := hexutil.Encode(ethcrypto.FromECDSA(key))
-	privKeyStr = strings.TrimPrefix(privKeyStr, "0x")
-
-	if err := os.WriteFile(ethPrivKeyFile, []byte(privKeyStr), 0600); err != nil {
+	if err = WriteEthKeyFile(ethPrivKeyFile, key, keystorePassword); err != nil {
 		return err
 	}
 
+	if mnemonic != "" {
+		printMnemonic(mnemonic)
+	}
+
 	log.Infof("New ETH wallet key generated in %s", ethPrivKeyFile)
 	log.Infof("Fund address %s to take an offer",
 		ethcrypto.PubkeyToAddress(*(key.Public().(*ecdsa.PublicKey))).Hex())
 	return nil
 }
 
+// WriteEthKeyFile writes the private key to the given path. The key is written as an
+// encrypted keystore if keystorePassword is set, otherwise it is written as hex.
+func WriteEthKeyFile(ethPrivKeyFile string, key *ecdsa.PrivateKey, keystorePassword string) error {
+	if keystorePassword != "" {
+		return WriteEthKeystoreFile(ethPrivKeyFile, key, keystorePassword)
+	}
+
+	privKeyStr := hexutil.Encode(ethcrypto.FromECDSA(key))
+	privKeyStr = strings.TrimPrefix(privKeyStr, "0x")
+	return os.WriteFile(ethPrivKeyFile, []byte(privKeyStr), 0600)
+}
+
+// printMnemonic prints the mnemonic of a newly generated key directly to the
+// terminal instead of the log, so it does not end up in log files.
+func printMnemonic(mnemonic string) {
+	separator := strings.Repeat("#", 70)
+	fmt.Printf("\n%s\n", separator)
+	fmt.Println("Your new ETH key was generated from the mnemonic below. Write it down")
+	fmt.Println("and store it safely, it will NOT be shown again. The key can be")
+	fmt.Println("restored from it with \"swapcli restore-eth-key\".")
+	fmt.Printf("\n%s\n", mnemonic)
+	fmt.Printf("%s\n\n", separator)
+}
+
 // GetEthereumPrivateKey reads or creates and returns an ethereum private key for the given the CLI options.
-func GetEthereumPrivateKey(ethPrivKeyFile string, env common.Environment, devXMRMaker, devXMRTaker bool) (
+// The key file can either contain the hex encoded key or an encrypted keystore, in
+// which case keystorePassword is used to decrypt it.
+func GetEthereumPrivateKey(
+	ethPrivKeyFile string,
+	keystorePassword string,
+	env common.Environment,
+	devXMRMaker bool,
+	devXMRTaker bool,
+) (
 	*ecdsa.PrivateKey,
 	error,
 ) {
@@ -69,17 +117,13 @@ func GetEthereumPrivateKey(ethPrivKeyFile string, env common.Environment, devXMR
 		return nil, err
 	}
 	if !exists {
-		if err = createAndWriteEthKeyFile(ethPrivKeyFile, env, devXMRMaker, devXMRTaker); err != nil {
+		err = createAndWriteEthKeyFile(ethPrivKeyFile, keystorePassword, env, devXMRMaker, devXMRTaker)
+		if err != nil {
 			return nil, err
 		}
 	}
 
-	fileData, err := os.ReadFile(filepath.Clean(ethPrivKeyFile))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read eth-privkey file: %w", err)
-	}
-	ethPrivKeyHex := strings.TrimSpace(string(fileData))
-	privkey, err := ethcrypto.HexToECDSA(ethPrivKeyHex)
+	privkey, err := ReadEthPrivateKeyFile(ethPrivKeyFile, keystorePassword)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,273 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+
+	"github.com/athanorlabs/atomic-swap/cliutil"
+	"github.com/athanorlabs/atomic-swap/common"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+const (
+	flagEnv                     = "env"
+	flagDataDir                 = "data-dir"
+	flagEthEndpoint             = "eth-endpoint"
+	flagEthPrivKey              = "eth-privkey"
+	flagEthKeystorePassword     = "eth-keystore-password"
+	flagEthKeystorePasswordFile = "eth-keystore-password-file"
+	flagForwarderAddress        = "forwarder-address"
+	flagForwarderOnly           = "forwarder-only"
+	flagDeterministic           = "deterministic"
+	flagWriteAddresses          = "write-addresses"
+	flagVerifySource            = "verify-source"
+	flagEtherscanAPIURL         = "etherscan-api-url"
+	flagEtherscanAPIKey         = "etherscan-api-key"
+	flagProxy                   = "proxy"
+)
+
+var deployContractFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    flagEnv,
+		Usage:   "Environment to use: one of mainnet, stagenet, or dev",
+		EnvVars: []string{"SWAPD_ENV"},
+		Value:   "dev",
+	},
+	&cli.StringFlag{
+		Name:  flagDataDir,
+		Usage: "swapd data directory, used to locate the daemon's key and the contract addresses file",
+		Value: "{HOME}/.atomicswap/{ENV}", // For --help only, actual default replaces variables
+	},
+	&cli.StringFlag{
+		Name:    flagEthEndpoint,
+		Usage:   "Ethereum client endpoint",
+		EnvVars: []string{"SWAPD_ETH_ENDPOINT"},
+		Value:   common.DefaultEthEndpoint,
+	},
+	&cli.StringFlag{
+		Name:    flagEthPrivKey,
+		Usage:   "File containing the ethereum private key (hex or encrypted keystore) that pays for the deployment",
+		EnvVars: []string{"SWAPD_ETH_PRIVKEY"},
+		Value:   fmt.Sprintf("{DATA_DIR}/%s", common.DefaultEthKeyFileName),
+	},
+	ethKeystorePasswordFlag,
+	&cli.StringFlag{
+		Name: flagEthKeystorePasswordFile,
+		Usage: "File whose first line is the --" + flagEthKeystorePassword +
+			", prompted for on a terminal if neither is set",
+		EnvVars: []string{"SWAPD_ETH_KEYSTORE_PASSWORD_FILE"},
+	},
+	&cli.StringFlag{
+		Name:  flagForwarderAddress,
+		Usage: "Address of an already deployed GSN forwarder to use instead of deploying a new one",
+	},
+	&cli.BoolFlag{
+		Name:  flagForwarderOnly,
+		Usage: "Only deploy the GSN forwarder contract",
+	},
+	&cli.BoolFlag{
+		Name:  flagDeterministic,
+		Usage: "Deploy SwapCreator.sol via CREATE2, so it has the same address on every chain",
+	},
+	&cli.BoolFlag{
+		Name: flagWriteAddresses,
+		Usage: fmt.Sprintf("Write the deployed addresses to {DATA_DIR}/%s for swapd to use",
+			common.DefaultContractAddressesFileName),
+	},
+	&cli.BoolFlag{
+		Name:  flagVerifySource,
+		Usage: "Submit the contract source to Etherscan (or a Blockscout instance) for verification after deploying",
+	},
+	&cli.StringFlag{
+		Name:  flagEtherscanAPIURL,
+		Usage: "Etherscan compatible API URL used by --" + flagVerifySource + " (defaults to Etherscan for known chains)",
+	},
+	&cli.StringFlag{
+		Name:    flagEtherscanAPIKey,
+		Usage:   "API key used by --" + flagVerifySource,
+		EnvVars: []string{"ETHERSCAN_API_KEY"},
+	},
+	&cli.StringFlag{
+		Name:    flagProxy,
+		Usage:   "IP:PORT of a SOCKS5 proxy, like Tor's 127.0.0.1:9050, through which the endpoints are connected to",
+		EnvVars: []string{"SWAPD_PROXY"},
+	},
+}
+
+func runDeployContract(ctx *cli.Context) error {
+	env, err := common.NewEnv(ctx.String(flagEnv))
+	if err != nil {
+		return err
+	}
+
+	conf := common.ConfigDefaultsForEnv(env)
+	if ctx.IsSet(flagDataDir) {
+		conf.DataDir = ctx.String(flagDataDir)
+		if conf.DataDir == "" {
+			return errFlagValueEmpty(flagDataDir)
+		}
+	}
+
+	ethPrivKeyFile := conf.EthKeyFileName()
+	if ctx.IsSet(flagEthPrivKey) {
+		ethPrivKeyFile = ctx.String(flagEthPrivKey)
+		if ethPrivKeyFile == "" {
+			return errFlagValueEmpty(flagEthPrivKey)
+		}
+	}
+
+	forwarderOnly := ctx.Bool(flagForwarderOnly)
+	if forwarderOnly && ctx.IsSet(flagForwarderAddress) {
+		return errFlagsMutuallyExclusive(flagForwarderOnly, flagForwarderAddress)
+	}
+
+	if ctx.IsSet(flagEthKeystorePassword) && ctx.IsSet(flagEthKeystorePasswordFile) {
+		return errFlagsMutuallyExclusive(flagEthKeystorePassword, flagEthKeystorePasswordFile)
+	}
+
+	keystorePassword, err := cliutil.GetEthKeystorePassword(
+		ctx.String(flagEthKeystorePassword),
+		ctx.String(flagEthKeystorePasswordFile),
+		ethPrivKeyFile,
+	)
+	if err != nil {
+		return err
+	}
+
+	privKey, err := cliutil.ReadEthPrivateKeyFile(ethPrivKeyFile, keystorePassword)
+	if err != nil {
+		return err
+	}
+
+	var proxy *common.Proxy
+	if ctx.IsSet(flagProxy) {
+		proxy, err = common.NewProxy(ctx.String(flagProxy))
+		if err != nil {
+			return fmt.Errorf("invalid %q value: %w", flagProxy, err)
+		}
+	}
+
+	rpcClient, err := rpc.DialOptions(
+		ctx.Context,
+		ctx.String(flagEthEndpoint),
+		rpc.WithHTTPClient(&http.Client{Transport: proxy.HTTPTransport()}),
+	)
+	if err != nil {
+		return err
+	}
+	ec := ethclient.NewClient(rpcClient)
+	defer ec.Close()
+
+	var forwarderAddr ethcommon.Address
+	if ctx.IsSet(flagForwarderAddress) {
+		forwarderAddr, err = readAddressFlag(ctx, flagForwarderAddress)
+		if err != nil {
+			return err
+		}
+		if _, err = contracts.CheckForwarderContractCode(ctx.Context, ec, forwarderAddr); err != nil {
+			return err
+		}
+	} else {
+		var receipt *ethtypes.Receipt
+		forwarderAddr, receipt, err = contracts.DeployGSNForwarderWithReceipt(ctx.Context, ec, privKey)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Forwarder.sol address: %s\n", forwarderAddr)
+		fmt.Printf("Forwarder.sol deployment block: %s\n", receipt.BlockNumber)
+	}
+
+	if forwarderOnly {
+		return nil
+	}
+
+	var (
+		swapCreatorAddr ethcommon.Address
+		receipt         *ethtypes.Receipt
+	)
+	if ctx.Bool(flagDeterministic) {
+		swapCreatorAddr, receipt, err = contracts.DeploySwapCreatorDeterministic(ctx.Context, ec, privKey, forwarderAddr)
+	} else {
+		swapCreatorAddr, receipt, err = contracts.DeploySwapCreatorWithReceipt(ctx.Context, ec, privKey, forwarderAddr)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("SwapCreator.sol address: %s\n", swapCreatorAddr)
+	fmt.Printf("SwapCreator.sol trusted forwarder: %s\n", forwarderAddr)
+	addresses := &cliutil.ContractAddresses{
+		SwapCreatorAddr: swapCreatorAddr,
+		ForwarderAddr:   forwarderAddr,
+	}
+	if receipt != nil {
+		// receipt is nil if a deterministic deployment already existed
+		fmt.Printf("SwapCreator.sol deployment block: %s\n", receipt.BlockNumber)
+		addresses.SwapCreatorDeployBlock = receipt.BlockNumber.Uint64()
+	}
+
+	if ctx.Bool(flagWriteAddresses) {
+		if err = common.MakeDir(conf.DataDir); err != nil {
+			return err
+		}
+		if err = cliutil.WriteContractAddressesToFile(conf.ContractAddressesFile(), addresses); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote contract addresses to %s\n", conf.ContractAddressesFile())
+	}
+
+	if ctx.Bool(flagVerifySource) {
+		if err = verifySwapCreatorSource(ctx, ec, proxy, swapCreatorAddr, forwarderAddr); err != nil {
+			return err
+		}
+		fmt.Println("Source code verified")
+	}
+
+	return nil
+}
+
+func verifySwapCreatorSource(
+	ctx *cli.Context,
+	ec *ethclient.Client,
+	proxy *common.Proxy,
+	swapCreatorAddr ethcommon.Address,
+	forwarderAddr ethcommon.Address,
+) error {
+	apiURL := ctx.String(flagEtherscanAPIURL)
+	if apiURL == "" {
+		chainID, err := ec.ChainID(ctx.Context)
+		if err != nil {
+			return err
+		}
+		apiURL = contracts.EtherscanAPIURL(chainID)
+		if apiURL == "" {
+			return fmt.Errorf("--%s is required for chain ID %s", flagEtherscanAPIURL, chainID)
+		}
+	}
+
+	conf := &contracts.SourceVerifierConf{
+		APIURL: apiURL,
+		APIKey: ctx.String(flagEtherscanAPIKey),
+		Proxy:  proxy,
+	}
+
+	fmt.Printf("Submitting source code to %s for verification...\n", apiURL)
+	return contracts.VerifySwapCreatorSource(ctx.Context, conf, swapCreatorAddr, forwarderAddr)
+}
+
+func readAddressFlag(ctx *cli.Context, flagName string) (ethcommon.Address, error) {
+	addrStr := ctx.String(flagName)
+	if !ethcommon.IsHexAddress(addrStr) {
+		return ethcommon.Address{}, fmt.Errorf("--%s requires a valid ethereum address", flagName)
+	}
+	return ethcommon.HexToAddress(addrStr), nil
+}
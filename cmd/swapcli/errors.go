@@ -14,3 +14,11 @@ var (
 func errInvalidFlagValue(flagName string, err error) error {
 	return fmt.Errorf("invalid value passed to --%s: %w", flagName, err)
 }
+
+func errFlagsMutuallyExclusive(flag1, flag2 string) error {
+	return fmt.Errorf("flags --%s and --%s are mutually exclusive", flag1, flag2)
+}
+
+func errFlagValueEmpty(flag string) error {
+	return fmt.Errorf("flag --%s requires a non-empty value", flag)
+}
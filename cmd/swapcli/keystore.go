@@ -0,0 +1,156 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+
+	"github.com/athanorlabs/atomic-swap/cliutil"
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+const (
+	flagOutFile = "out"
+)
+
+var ethKeystorePasswordFlag = &cli.StringFlag{
+	Name:    flagEthKeystorePassword,
+	Usage:   "Password of the encrypted ethereum keystore",
+	EnvVars: []string{"SWAPD_ETH_KEYSTORE_PASSWORD"},
+}
+
+var ethKeyEnvFlag = &cli.StringFlag{
+	Name:    flagEnv,
+	Usage:   "Environment to use: one of mainnet, stagenet, or dev",
+	EnvVars: []string{"SWAPD_ENV"},
+	Value:   "dev",
+}
+
+var ethKeyDataDirFlag = &cli.StringFlag{
+	Name:  flagDataDir,
+	Usage: "swapd data directory, used to locate the daemon's key",
+	Value: "{HOME}/.atomicswap/{ENV}", // For --help only, actual default replaces variables
+}
+
+var convertEthKeyFlags = []cli.Flag{
+	ethKeyEnvFlag,
+	ethKeyDataDirFlag,
+	&cli.StringFlag{
+		Name:    flagEthPrivKey,
+		Usage:   "File containing the hex encoded ethereum private key to convert",
+		EnvVars: []string{"SWAPD_ETH_PRIVKEY"},
+		Value:   fmt.Sprintf("{DATA_DIR}/%s", common.DefaultEthKeyFileName),
+	},
+	&cli.StringFlag{
+		Name:  flagOutFile,
+		Usage: "File to write the encrypted keystore to",
+		Value: "{ETH_PRIVKEY}", // For --help only, the key file is replaced by default
+	},
+	ethKeystorePasswordFlag,
+}
+
+// ethKeyFileFromFlags returns the ethereum key file path, defaulting to the key
+// file in swapd's data directory for the environment.
+func ethKeyFileFromFlags(ctx *cli.Context) (string, error) {
+	env, err := common.NewEnv(ctx.String(flagEnv))
+	if err != nil {
+		return "", err
+	}
+
+	conf := common.ConfigDefaultsForEnv(env)
+	if ctx.IsSet(flagDataDir) {
+		conf.DataDir = ctx.String(flagDataDir)
+		if conf.DataDir == "" {
+			return "", errFlagValueEmpty(flagDataDir)
+		}
+	}
+
+	if !ctx.IsSet(flagEthPrivKey) {
+		return conf.EthKeyFileName(), nil
+	}
+
+	ethPrivKeyFile := ctx.String(flagEthPrivKey)
+	if ethPrivKeyFile == "" {
+		return "", errFlagValueEmpty(flagEthPrivKey)
+	}
+	return ethPrivKeyFile, nil
+}
+
+func runConvertEthKey(ctx *cli.Context) error {
+	ethPrivKeyFile, err := ethKeyFileFromFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	outFile := ethPrivKeyFile
+	if ctx.IsSet(flagOutFile) {
+		outFile = ctx.String(flagOutFile)
+		if outFile == "" {
+			return errFlagValueEmpty(flagOutFile)
+		}
+	}
+
+	password := ctx.String(flagEthKeystorePassword)
+	if password == "" {
+		return errFlagValueEmpty(flagEthKeystorePassword)
+	}
+
+	privKey, err := cliutil.ReadEthPrivateKeyFile(ethPrivKeyFile, password)
+	if err != nil {
+		return err
+	}
+
+	if err = cliutil.WriteEthKeystoreFile(outFile, privKey, password); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote encrypted keystore for %s to %s\n", ethcrypto.PubkeyToAddress(privKey.PublicKey), outFile)
+	return nil
+}
+
+var restoreEthKeyFlags = []cli.Flag{
+	ethKeyEnvFlag,
+	ethKeyDataDirFlag,
+	&cli.StringFlag{
+		Name:    flagEthPrivKey,
+		Usage:   "File to write the restored ethereum key to, an existing different key is renamed to *.bak",
+		EnvVars: []string{"SWAPD_ETH_PRIVKEY"},
+		Value:   fmt.Sprintf("{DATA_DIR}/%s", common.DefaultEthKeyFileName),
+	},
+	&cli.StringFlag{
+		Name:    flagEthKeystorePassword,
+		Usage:   "Write the restored key as an encrypted keystore with this password",
+		EnvVars: []string{"SWAPD_ETH_KEYSTORE_PASSWORD"},
+	},
+}
+
+func runRestoreEthKey(ctx *cli.Context) error {
+	ethPrivKeyFile, err := ethKeyFileFromFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The mnemonic is read from stdin instead of a flag, so it does not end up
+	// in the shell history.
+	fmt.Print("Enter the mnemonic of the ETH key: ")
+	mnemonic, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	addr, err := cliutil.RestoreEthKeyFile(ethPrivKeyFile, mnemonic, ctx.String(flagEthKeystorePassword))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored ETH key of %s to %s\n", addr, ethPrivKeyFile)
+	return nil
+}
@@ -6,8 +6,12 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -23,7 +27,10 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/rpc"
 	"github.com/athanorlabs/atomic-swap/rpcclient"
 	"github.com/athanorlabs/atomic-swap/rpcclient/wsclient"
 )
@@ -32,18 +39,33 @@ const (
 	defaultDiscoverSearchTimeSecs = 12
 
 	flagSwapdPort      = "swapd-port"
+	flagRPCAuthToken   = "rpc-auth-token"
+	flagRPCTLS         = "rpc-tls"
+	flagRPCTLSCA       = "rpc-tls-ca"
+	flagRPCUnixSocket  = "rpc-unix-socket"
 	flagMinAmount      = "min-amount"
 	flagMaxAmount      = "max-amount"
 	flagPeerID         = "peer-id"
 	flagOfferID        = "offer-id"
 	flagOfferIDs       = "offer-ids"
 	flagExchangeRate   = "exchange-rate"
+	flagMaxRate        = "max-exchange-rate"
 	flagProvides       = "provides"
 	flagProvidesAmount = "provides-amount"
 	flagUseRelayer     = "use-relayer"
 	flagSearchTime     = "search-time"
 	flagToken          = "token"
 	flagDetached       = "detached"
+	flagTo             = "to"
+	flagAll            = "all"
+	flagPriority       = "priority"
+	flagBelowAmount    = "below-amount"
+	flagMinHeight      = "min-height"
+	flagRestoreHeight  = "restore-height"
+	flagProfile        = "profile"
+	flagConsolidate    = "consolidate"
+	flagPegSource      = "peg-source"
+	flagPegMarkup      = "peg-markup"
 )
 
 func cliApp() *cli.App {
@@ -53,6 +75,28 @@ func cliApp() *cli.App {
 		Version:              cliutil.GetVersion(),
 		EnableBashCompletion: true,
 		Suggest:              true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagRPCAuthToken,
+				Usage:   "Bearer token authenticating requests to swapd, if swapd requires one",
+				EnvVars: []string{"SWAPD_RPC_AUTH_TOKEN"},
+			},
+			&cli.BoolFlag{
+				Name:  flagRPCTLS,
+				Usage: "Connect to swapd with HTTPS and WSS, verifying its certificate with the system's trusted certificates",
+			},
+			&cli.StringFlag{
+				Name: flagRPCTLSCA,
+				Usage: "Connect to swapd with HTTPS and WSS, verifying its certificate with this PEM " +
+					"certificate file, like swapd's self-signed rpc-tls-cert.pem",
+			},
+			&cli.StringFlag{
+				Name: flagRPCUnixSocket,
+				Usage: "Connect to swapd over its unix socket at this path, like {DATA_DIR}/" +
+					common.DefaultRPCUnixSocketFileName + ", instead of its RPC port",
+				EnvVars: []string{"SWAPD_RPC_UNIX_SOCKET"},
+			},
+		},
 		Commands: []*cli.Command{
 			{
 				Name:    "addresses",
@@ -72,6 +116,14 @@ func cliApp() *cli.App {
 					swapdPortFlag,
 				},
 			},
+			{
+				Name:   "nat-status",
+				Usage:  "Show whether our daemon is publicly reachable by peers, and its relay addresses if not",
+				Action: runNATStatus,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
 			{
 				Name:    "balances",
 				Aliases: []string{"b"},
@@ -85,6 +137,27 @@ func cliApp() *cli.App {
 						EnvVars: []string{"SWAPCLI_TOKENS"},
 						Usage:   "Token address to include in the balance response",
 					},
+					profileFlag,
+				},
+			},
+			{
+				Name:   "profiles",
+				Usage:  "List the profiles of our daemon whose wallets and accounts can fund offers and swaps",
+				Action: runProfiles,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "incoming-transfers",
+				Usage:  "Show the incoming transfers of our monero wallet, like the claims of swaps that we took",
+				Action: runIncomingTransfers,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+					&cli.UintFlag{
+						Name:  flagMinHeight,
+						Usage: "Only show the transfers from this block height",
+					},
 				},
 			},
 			{
@@ -134,6 +207,22 @@ func cliApp() *cli.App {
 						Usage:    "Peer's ID, as provided by discover",
 						Required: true,
 					},
+					&cli.StringFlag{
+						Name:  flagToken,
+						Usage: "Only return offers for the ethereum ERC20 token address, or \"ETH\"",
+					},
+					&cli.StringFlag{
+						Name:  flagMinAmount,
+						Usage: "Only return offers whose maximum amount is at least this XMR amount, like \"1.5\" or \"1.5 XMR\"",
+					},
+					&cli.StringFlag{
+						Name:  flagMaxAmount,
+						Usage: "Only return offers whose minimum amount is at most this XMR amount, like \"1.5\" or \"1.5 XMR\"",
+					},
+					&cli.StringFlag{
+						Name:  flagMaxRate,
+						Usage: "Only return offers whose exchange rate of XMR:ETH is at most this rate",
+					},
 					swapdPortFlag,
 				},
 			},
@@ -154,6 +243,85 @@ func cliApp() *cli.App {
 						Usage: "Duration of time to search for, in seconds",
 						Value: defaultDiscoverSearchTimeSecs,
 					},
+					&cli.StringFlag{
+						Name:  flagToken,
+						Usage: "Only return offers for the ethereum ERC20 token address, or \"ETH\"",
+					},
+					&cli.StringFlag{
+						Name:  flagMinAmount,
+						Usage: "Only return offers whose maximum amount is at least this XMR amount, like \"1.5\" or \"1.5 XMR\"",
+					},
+					&cli.StringFlag{
+						Name:  flagMaxAmount,
+						Usage: "Only return offers whose minimum amount is at most this XMR amount, like \"1.5\" or \"1.5 XMR\"",
+					},
+					&cli.StringFlag{
+						Name:  flagMaxRate,
+						Usage: "Only return offers whose exchange rate of XMR:ETH is at most this rate",
+					},
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "peer-reputation",
+				Usage:  "Show the outcomes of our past swaps with a peer",
+				Action: runPeerReputation,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagPeerID,
+						Usage:    "Peer's ID, as provided by discover",
+						Required: true,
+					},
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "ban-peer",
+				Usage:  "Disconnect from a peer and refuse its connections until it is unbanned",
+				Action: runBanPeer,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagPeerID,
+						Usage:    "ID of the peer to ban",
+						Required: true,
+					},
+					&cli.UintFlag{
+						Name:  "duration",
+						Usage: "Duration of the ban, in seconds. The ban is permanent if not set.",
+					},
+					&cli.StringFlag{
+						Name:  "reason",
+						Usage: "Reason of the ban, shown by list-banned",
+					},
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "unban-peer",
+				Usage:  "Remove the ban of a peer",
+				Action: runUnbanPeer,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagPeerID,
+						Usage:    "ID of the peer to unban",
+						Required: true,
+					},
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "list-banned",
+				Usage:  "List the banned peers",
+				Action: runListBanned,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "net-stats",
+				Usage:  "Show the traffic with peers and the messages they sent, by protocol and by peer",
+				Action: runNetStats,
+				Flags: []cli.Flag{
 					swapdPortFlag,
 				},
 			},
@@ -165,18 +333,29 @@ func cliApp() *cli.App {
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:     flagMinAmount,
-						Usage:    "Minimum amount to be swapped, in XMR",
+						Usage:    "Minimum amount to be swapped, in XMR, like \"0.5\" or \"0.5 XMR\"",
 						Required: true,
 					},
 					&cli.StringFlag{
 						Name:     flagMaxAmount,
-						Usage:    "Maximum amount to be swapped, in XMR",
+						Usage:    "Maximum amount to be swapped, in XMR, like \"1.5\" or \"1.5 XMR\"",
 						Required: true,
 					},
 					&cli.StringFlag{
-						Name:     flagExchangeRate,
-						Usage:    "Desired exchange rate of XMR:ETH, eg. --exchange-rate=0.1 means 10XMR = 1ETH",
-						Required: true,
+						Name: flagExchangeRate,
+						Usage: "Desired exchange rate of XMR:ETH, eg. --exchange-rate=0.1 means 10XMR = 1ETH. " +
+							"Required unless --" + flagPegSource + " is set",
+					},
+					&cli.StringFlag{
+						Name: flagPegSource,
+						Usage: "Peg the exchange rate to the XMR/ETH market price of this price source " +
+							"(chainlink, coingecko or kraken), which swapd keeps up to date and resolves when " +
+							"the offer is taken",
+					},
+					&cli.StringFlag{
+						Name:  flagPegMarkup,
+						Usage: "Percent added to the market price of a pegged offer, eg. 2 or -0.5",
+						Value: "0",
 					},
 					&cli.BoolFlag{
 						Name:  flagDetached,
@@ -190,6 +369,7 @@ func cliApp() *cli.App {
 						Name:  flagUseRelayer,
 						Usage: "Use the relayer even if the receiving account has enough ETH to claim",
 					},
+					profileFlag,
 					swapdPortFlag,
 				},
 			},
@@ -211,13 +391,37 @@ func cliApp() *cli.App {
 					},
 					&cli.StringFlag{
 						Name:     flagProvidesAmount,
-						Usage:    "Amount of coin to send in the swap",
+						Usage:    "Amount of the offer's ETH asset to send in the swap, like \"0.5 ETH\" or \"250 USDT\"",
 						Required: true,
 					},
 					&cli.BoolFlag{
 						Name:  flagDetached,
 						Usage: "Exit immediately instead of subscribing to notifications about the swap's status",
 					},
+					profileFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "quote",
+				Usage:  "Get the XMR that we receive and the fees that we pay for taking an offer",
+				Action: runQuote,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagPeerID,
+						Usage:    "Peer's ID, as provided by discover",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     flagOfferID,
+						Usage:    "ID of the offer to quote",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     flagProvidesAmount,
+						Usage:    "Amount of the offer's ETH asset to send in the swap, like \"0.5 ETH\" or \"250 USDT\"",
+						Required: true,
+					},
 					swapdPortFlag,
 				},
 			},
@@ -245,6 +449,50 @@ func cliApp() *cli.App {
 					swapdPortFlag,
 				},
 			},
+			{
+				Name:   "export",
+				Usage:  "Export the past swaps for accounting and tax reporting",
+				Action: runExportSwaps,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Format of the export, csv or json",
+						Value: rpc.ExportFormatCSV,
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "File to write the export to, instead of stdout",
+					},
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "estimate-fees",
+				Usage:  "Estimate the gas, XMR network and relayer fees that we pay in a swap",
+				Action: runEstimateFees,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name: flagProvides,
+						Usage: fmt.Sprintf("Coin that we provide in the swap: one of [%s, %s]",
+							coins.ProvidesXMR, coins.ProvidesETH),
+						Value: string(coins.ProvidesXMR),
+					},
+					&cli.StringFlag{
+						Name:     "amount",
+						Usage:    "Amount of ETH, or of the token, in the swap, like \"0.5 ETH\" or \"250 USDT\"",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  flagToken,
+						Usage: "Use to pass the ethereum ERC20 token address of the swap instead of ETH",
+					},
+					&cli.BoolFlag{
+						Name:  flagUseRelayer,
+						Usage: "Estimate the claim as relayed even if we have enough ETH to claim",
+					},
+					swapdPortFlag,
+				},
+			},
 			{
 				Name:   "cancel",
 				Usage:  "Cancel a ongoing swap if possible. Depending on the swap stage, this may not be possible.",
@@ -257,6 +505,25 @@ func cliApp() *cli.App {
 					swapdPortFlag,
 				},
 			},
+			{
+				Name: "set-xmr-priority",
+				Usage: "Set the fee priority of the monero lock and sweep transactions of an ongoing swap, " +
+					"e.g. to confirm the lock faster when the swap is close to its timeout.",
+				Action: runSetXMRPriority,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagOfferID,
+						Usage:    "ID of the ongoing swap",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     flagPriority,
+						Usage:    fmt.Sprintf("Fee priority, one of %s", strings.Join(monero.PriorityNames, ", ")),
+						Required: true,
+					},
+					swapdPortFlag,
+				},
+			},
 			{
 				Name:   "clear-offers",
 				Usage:  "Clear current offers. If no offer IDs are provided, clears all current offers.",
@@ -317,6 +584,142 @@ func cliApp() *cli.App {
 					swapdPortFlag,
 				},
 			},
+			{
+				Name:   "deploy-contract",
+				Usage:  "Deploy the GSN forwarder and/or SwapCreator.sol contracts",
+				Action: runDeployContract,
+				Flags:  deployContractFlags,
+			},
+			{
+				Name:   "convert-eth-key",
+				Usage:  "Encrypt a hex encoded ethereum key file as a web3 keystore for use with swapd",
+				Action: runConvertEthKey,
+				Flags:  convertEthKeyFlags,
+			},
+			{
+				Name:   "transfer-eth",
+				Usage:  "Withdraw ETH, or an ERC20 token, from swapd's ethereum account",
+				Action: runTransferETH,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagTo,
+						Usage:    "Ethereum address to send the funds to",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "amount",
+						Usage: "Amount of ETH, or of the token, to send, like \"0.5 ETH\" or \"250 USDT\"",
+					},
+					&cli.BoolFlag{
+						Name:  flagAll,
+						Usage: "Send the whole balance instead of an amount",
+					},
+					&cli.StringFlag{
+						Name:  flagToken,
+						Usage: "Use to pass the ethereum ERC20 token address to send instead of ETH",
+					},
+					priorityFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "transfer-xmr",
+				Usage:  "Withdraw XMR from swapd's primary monero account",
+				Action: runTransferXMR,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagTo,
+						Usage:    "Monero address to send the funds to",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "amount",
+						Usage: "Amount of XMR to send, like \"1.5\" or \"1.5 XMR\"",
+					},
+					&cli.BoolFlag{
+						Name:  flagAll,
+						Usage: "Sweep the whole unlocked balance instead of an amount",
+					},
+					priorityFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "sweep-xmr",
+				Usage:  "Sweep the unlocked XMR outputs, by default to our own address to consolidate them",
+				Action: runSweepXMR,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flagTo,
+						Usage: "Monero address to sweep to instead of our primary address",
+					},
+					&cli.StringFlag{
+						Name:  flagBelowAmount,
+						Usage: "Only sweep outputs of amounts below this XMR amount, like \"0.1\" or \"0.1 XMR\"",
+					},
+					priorityFlag,
+					profileFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name: "plan-consolidation",
+				Usage: "Show how many XMR outputs locking an amount would spend, and which outputs " +
+					"to consolidate first if they are too many",
+				Action: runPlanConsolidation,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "amount",
+						Usage:    "Amount of XMR to lock, like the maximum amount of an offer",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  flagConsolidate,
+						Usage: "Sweep the recommended outputs to our own address to consolidate them",
+					},
+					priorityFlag,
+					profileFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "clear-token-cache",
+				Usage:  "Remove the cached metadata of a token, or of all tokens",
+				Action: runClearTokenInfoCache,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flagToken,
+						Usage: "Address of the token whose metadata is removed, instead of all tokens",
+					},
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "unlock",
+				Usage:  "Unlock the monero wallet of a swapd started with --wallet-unlock-rpc",
+				Action: runUnlock,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "restore-xmr-wallet",
+				Usage:  "Recreate swapd's monero wallet from its mnemonic seed, keeping the replaced wallet as a backup",
+				Action: runRestoreXMRWallet,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+					&cli.UintFlag{
+						Name:  flagRestoreHeight,
+						Usage: "Block height from which the restored wallet scans, like the height when it was created",
+					},
+				},
+			},
+			{
+				Name:   "restore-eth-key",
+				Usage:  "Restore swapd's ethereum key file from its mnemonic",
+				Action: runRestoreEthKey,
+				Flags:  restoreEthKeyFlags,
+			},
 			{
 				Name:   "version",
 				Usage:  "Get the client and server versions",
@@ -325,11 +728,51 @@ func cliApp() *cli.App {
 					swapdPortFlag,
 				},
 			},
+			{
+				Name:   "daemon-status",
+				Usage:  "Get swapd's sync status and whether it is ready to accept swaps",
+				Action: runGetDaemonStatus,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "monero-nodes",
+				Usage:  "Show the ranking of the configured monerod nodes by health and which one is in use",
+				Action: runMoneroNodes,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "pause",
+				Usage:  "Stop taking offers and accepting takes of our offers, letting ongoing swaps complete",
+				Action: runPause,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "resume",
+				Usage:  "Resume taking offers and accepting takes of our offers after a pause",
+				Action: runResume,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
 			{
 				Name:   "shutdown",
 				Usage:  "Shutdown swapd",
 				Action: runShutdown,
 				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "drain",
+						Usage: "Refuse new swaps and shut down once the ongoing swaps have locked funds or completed",
+					},
+					&cli.Uint64Flag{
+						Name:  "drain-timeout",
+						Usage: "Maximum duration to wait for the ongoing swaps when draining, in seconds (default: 1800)",
+					},
 					swapdPortFlag,
 				},
 			},
@@ -345,6 +788,16 @@ var (
 		Value:   common.DefaultSwapdPort,
 		EnvVars: []string{"SWAPD_PORT"},
 	}
+	priorityFlag = &cli.StringFlag{
+		Name: flagPriority,
+		Usage: fmt.Sprintf("Fee priority of the transaction: one of [%s, %s, %s]",
+			rpc.FeePriorityLow, rpc.FeePriorityNormal, rpc.FeePriorityHigh),
+		Value: string(rpc.FeePriorityNormal),
+	}
+	profileFlag = &cli.StringFlag{
+		Name:  flagProfile,
+		Usage: "Profile of swapd whose wallet and account to use, instead of the default ones",
+	}
 )
 
 func main() {
@@ -354,28 +807,86 @@ func main() {
 	}
 }
 
-func newRRPClient(ctx *cli.Context) *rpcclient.Client {
-	swapdPort := ctx.Uint(flagSwapdPort)
-	endpoint := fmt.Sprintf("http://127.0.0.1:%d", swapdPort)
-	return rpcclient.NewClient(ctx.Context, endpoint)
+func newRRPClient(ctx *cli.Context) (*rpcclient.Client, error) {
+	opts, err := rpcClientOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpcclient.NewClientWithOptions(ctx.Context, rpcEndpoint(ctx, "http", ""), opts), nil
 }
 
 func newWSClient(ctx *cli.Context) (wsclient.WsClient, error) {
-	swapdPort := ctx.Uint(flagSwapdPort)
-	endpoint := fmt.Sprintf("ws://127.0.0.1:%d/ws", swapdPort)
-	return wsclient.NewWsClient(ctx.Context, endpoint)
+	opts, err := rpcClientOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return wsclient.NewWsClientWithOptions(ctx.Context, rpcEndpoint(ctx, "ws", "/ws"), &wsclient.Options{
+		AuthToken:  opts.AuthToken,
+		TLSConfig:  opts.TLSConfig,
+		UnixSocket: opts.UnixSocket,
+		Profile:    opts.Profile,
+	})
 }
 
-func runAddresses(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-	resp, err := c.Addresses()
-	if err != nil {
-		return err
+// rpcEndpoint returns the URL of the swapd endpoint with the path. The secure
+// variant of the URL scheme is used if swapd serves TLS, except over the unix
+// socket, whose host is only a placeholder.
+func rpcEndpoint(ctx *cli.Context, scheme string, urlPath string) string {
+	if ctx.IsSet(flagRPCUnixSocket) {
+		return fmt.Sprintf("%s://localhost%s", scheme, urlPath)
 	}
 
-	fmt.Println("Local listening multi-addresses:")
-	for i, a := range resp.Addrs {
-		fmt.Printf("%d: %s\n", i+1, a)
+	if ctx.Bool(flagRPCTLS) || ctx.IsSet(flagRPCTLSCA) {
+		scheme += "s"
+	}
+	return fmt.Sprintf("%s://127.0.0.1:%d%s", scheme, ctx.Uint(flagSwapdPort), urlPath)
+}
+
+// rpcClientOptions returns the auth token, unix socket and TLS settings of the
+// connection to swapd.
+func rpcClientOptions(ctx *cli.Context) (*rpcclient.Options, error) {
+	opts := &rpcclient.Options{
+		AuthToken:  ctx.String(flagRPCAuthToken),
+		UnixSocket: ctx.String(flagRPCUnixSocket),
+		Profile:    ctx.String(flagProfile),
+	}
+
+	if ctx.IsSet(flagRPCTLSCA) && opts.UnixSocket == "" {
+		caFile := ctx.String(flagRPCTLSCA)
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %q", caFile)
+		}
+
+		opts.TLSConfig = &tls.Config{
+			RootCAs:    roots,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return opts, nil
+}
+
+func runAddresses(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Addresses()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Local listening multi-addresses:")
+	for i, a := range resp.Addrs {
+		fmt.Printf("%d: %s\n", i+1, a)
 	}
 	if len(resp.Addrs) == 0 {
 		fmt.Println("[none]")
@@ -384,7 +895,10 @@ func runAddresses(ctx *cli.Context) error {
 }
 
 func runPeers(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	resp, err := c.Peers()
 	if err != nil {
 		return err
@@ -400,10 +914,34 @@ func runPeers(ctx *cli.Context) error {
 	return nil
 }
 
+func runNATStatus(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.NATStatus()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Reachability: %s\n", resp.Reachability)
+	fmt.Println("Relay multi-addresses:")
+	for i, a := range resp.RelayAddrs {
+		fmt.Printf("%d: %s\n", i+1, a)
+	}
+	if len(resp.RelayAddrs) == 0 {
+		fmt.Println("[none]")
+	}
+	return nil
+}
+
 func runBalances(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 
-	request := &rpctypes.BalancesRequest{}
+	request := &rpctypes.BalancesRequest{Profile: ctx.String(flagProfile)}
 	tokens := ctx.StringSlice(flagToken)
 	for _, tokenAddr := range tokens {
 		if !ethcommon.IsHexAddress(tokenAddr) {
@@ -433,12 +971,85 @@ func runBalances(ctx *cli.Context) error {
 	fmt.Printf("XMR Balance: %s\n", balances.PiconeroBalance.AsMoneroString())
 	fmt.Printf("Unlocked XMR balance: %s\n",
 		balances.PiconeroUnlockedBalance.AsMoneroString())
+	if balances.PiconeroLockedBalance != nil {
+		fmt.Printf("Locked XMR balance: %s\n", balances.PiconeroLockedBalance.AsMoneroString())
+	}
 	fmt.Printf("Blocks to unlock: %d\n", balances.BlocksToUnlock)
+	for _, o := range balances.LockedOutputs {
+		fmt.Printf("  %s XMR unlocks in %d blocks (tx %s)\n", o.Amount.AsMoneroString(), o.BlocksToUnlock, o.TxID)
+	}
+	if balances.ViewOnly {
+		fmt.Println("The monero wallet is view-only, its balances include spent outputs")
+	}
+	return nil
+}
+
+func runProfiles(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := c.Profiles()
+	if err != nil {
+		return err
+	}
+
+	if len(profiles) == 0 {
+		fmt.Println("[none]")
+		return nil
+	}
+
+	for i, p := range profiles {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Profile: %s\n", p.Name)
+		fmt.Printf("Monero address: %s\n", p.MoneroAddress)
+		fmt.Printf("Ethereum address: %s\n", p.EthAddress)
+	}
+	return nil
+}
+
+func runIncomingTransfers(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	transfers, err := c.IncomingTransfers(uint64(ctx.Uint(flagMinHeight)))
+	if err != nil {
+		return err
+	}
+
+	if len(transfers) == 0 {
+		fmt.Println("[none]")
+		return nil
+	}
+
+	for i, t := range transfers {
+		if i > 0 {
+			fmt.Println("---")
+		}
+		fmt.Printf("TX ID: %s\n", t.TxID)
+		fmt.Printf("Amount: %s XMR\n", t.Amount.AsMoneroString())
+		if t.Height == 0 {
+			fmt.Println("Height: [pool]")
+		} else {
+			fmt.Printf("Height: %d\n", t.Height)
+		}
+		fmt.Printf("Confirmations: %d\n", t.Confirmations)
+		fmt.Printf("Time: %s\n", time.Unix(int64(t.Timestamp), 0).Format(common.TimeFmtSecs))
+	}
+
 	return nil
 }
 
 func runETHAddress(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	balances, err := c.Balances(nil)
 	if err != nil {
 		return err
@@ -453,7 +1064,10 @@ func runETHAddress(ctx *cli.Context) error {
 }
 
 func runXMRAddress(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	balances, err := c.Balances(nil)
 	if err != nil {
 		return err
@@ -468,7 +1082,10 @@ func runXMRAddress(ctx *cli.Context) error {
 }
 
 func runDiscover(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	provides := ctx.String(flagProvides)
 	peerIDs, err := c.Discover(provides, ctx.Uint64(flagSearchTime))
 	if err != nil {
@@ -491,8 +1108,16 @@ func runQuery(ctx *cli.Context) error {
 		return errInvalidFlagValue(flagPeerID, err)
 	}
 
-	c := newRRPClient(ctx)
-	res, err := c.Query(peerID)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	filter, err := readOfferFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.Query(peerID, filter)
 	if err != nil {
 		return err
 	}
@@ -514,8 +1139,16 @@ func runQueryAll(ctx *cli.Context) error {
 
 	searchTime := ctx.Uint64(flagSearchTime)
 
-	c := newRRPClient(ctx)
-	peerOffers, err := c.QueryAll(provides, searchTime)
+	filter, err := readOfferFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	peerOffers, err := c.QueryAll(provides, searchTime, filter)
 	if err != nil {
 		return err
 	}
@@ -526,6 +1159,9 @@ func runQueryAll(ctx *cli.Context) error {
 		}
 		fmt.Printf("Peer %d:\n", i)
 		fmt.Printf("  Peer ID: %v\n", po.PeerID)
+		if po.Reputation != nil {
+			printReputation(po.Reputation, "  ")
+		}
 		fmt.Printf("  Offers:\n")
 		for j, o := range po.Offers {
 			err = printOffer(c, o, j, "    ")
@@ -538,15 +1174,201 @@ func runQueryAll(ctx *cli.Context) error {
 	return nil
 }
 
+func runPeerReputation(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
+	if err != nil {
+		return errInvalidFlagValue(flagPeerID, err)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	rep, err := c.PeerReputation(peerID)
+	if err != nil {
+		return err
+	}
+
+	printReputation(rep, "")
+	return nil
+}
+
+func runBanPeer(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
+	if err != nil {
+		return errInvalidFlagValue(flagPeerID, err)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	if err = c.BanPeer(peerID, uint64(ctx.Uint("duration")), ctx.String("reason")); err != nil {
+		return err
+	}
+
+	fmt.Printf("Banned peer %s\n", peerID)
+	return nil
+}
+
+func runUnbanPeer(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
+	if err != nil {
+		return errInvalidFlagValue(flagPeerID, err)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	if err = c.UnbanPeer(peerID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Unbanned peer %s\n", peerID)
+	return nil
+}
+
+func runListBanned(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.ListBanned()
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Peers) == 0 {
+		fmt.Println("[none]")
+		return nil
+	}
+
+	for i, bp := range resp.Peers {
+		until := "permanent"
+		if bp.Until != nil {
+			until = bp.Until.Format(common.TimeFmtSecs)
+		}
+		fmt.Printf("Peer %d:\n", i)
+		fmt.Printf("\tPeer ID: %s\n", bp.PeerID)
+		fmt.Printf("\tUntil: %s\n", until)
+		if bp.Reason != "" {
+			fmt.Printf("\tReason: %s\n", bp.Reason)
+		}
+	}
+	return nil
+}
+
+func printBandwidth(indent string, bw *rpctypes.BandwidthStats) {
+	fmt.Printf("%sBytes in: %d (%.1f B/s)\n", indent, bw.BytesIn, bw.RateIn)
+	fmt.Printf("%sBytes out: %d (%.1f B/s)\n", indent, bw.BytesOut, bw.RateOut)
+}
+
+func runNetStats(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.NetStats()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Total:")
+	printBandwidth("\t", &resp.Total)
+
+	fmt.Println("Protocols:")
+	for _, ps := range resp.Protocols {
+		fmt.Printf("\t%s:\n", ps.Protocol)
+		printBandwidth("\t\t", &ps.Bandwidth)
+		fmt.Printf("\t\tMessages: %d (%d invalid)\n", ps.Messages, ps.InvalidMessages)
+	}
+
+	fmt.Println("Peers:")
+	if len(resp.Peers) == 0 {
+		fmt.Println("\t[none]")
+	}
+	for _, ps := range resp.Peers {
+		fmt.Printf("\t%s:\n", ps.PeerID)
+		printBandwidth("\t\t", &ps.Bandwidth)
+		protocols := make([]string, 0, len(ps.Messages))
+		for protocol := range ps.Messages {
+			protocols = append(protocols, protocol)
+		}
+		sort.Strings(protocols)
+		for _, protocol := range protocols {
+			fmt.Printf("\t\tMessages on %s: %d\n", protocol, ps.Messages[protocol])
+		}
+		fmt.Printf("\t\tInvalid messages: %d\n", ps.InvalidMessages)
+	}
+	return nil
+}
+
+// readOfferFilter returns the filter of the offers to query from the flags that are
+// set, or nil if none of them are.
+func readOfferFilter(ctx *cli.Context) (*types.OfferFilter, error) {
+	filter := new(types.OfferFilter)
+	isSet := false
+
+	if ctx.IsSet(flagToken) {
+		ethAsset := types.EthAssetETH
+		if !strings.EqualFold(ctx.String(flagToken), "ETH") {
+			addr, err := readETHAddressFlag(ctx, flagToken)
+			if err != nil {
+				return nil, err
+			}
+			ethAsset = types.EthAsset(addr)
+		}
+		filter.EthAsset = &ethAsset
+		isSet = true
+	}
+
+	if ctx.IsSet(flagMinAmount) {
+		min, err := readXMRAmountFlag(ctx, flagMinAmount)
+		if err != nil {
+			return nil, err
+		}
+		filter.MinAmount = min
+		isSet = true
+	}
+
+	if ctx.IsSet(flagMaxAmount) {
+		max, err := readXMRAmountFlag(ctx, flagMaxAmount)
+		if err != nil {
+			return nil, err
+		}
+		filter.MaxAmount = max
+		isSet = true
+	}
+
+	if ctx.IsSet(flagMaxRate) {
+		rate, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMaxRate)
+		if err != nil {
+			return nil, err
+		}
+		filter.MaxExchangeRate = coins.ToExchangeRate(rate)
+		isSet = true
+	}
+
+	if !isSet {
+		return nil, nil
+	}
+
+	return filter, nil
+}
+
 func runMake(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 
-	min, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMinAmount)
+	min, err := readXMRAmountFlag(ctx, flagMinAmount)
 	if err != nil {
 		return err
 	}
 
-	max, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMaxAmount)
+	max, err := readXMRAmountFlag(ctx, flagMaxAmount)
 	if err != nil {
 		return err
 	}
@@ -557,49 +1379,75 @@ func runMake(ctx *cli.Context) error {
 		ethAsset = types.EthAsset(ethcommon.HexToAddress(ethAssetStr))
 	}
 
-	exchangeRateDec, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagExchangeRate)
+	peg, err := readOfferPeg(ctx)
 	if err != nil {
 		return err
 	}
-	exchangeRate := coins.ToExchangeRate(exchangeRateDec)
 
-	var otherMin, otherMax *apd.Decimal
-	var symbol string
+	// the exchange rate of pegged offers is resolved by swapd
+	var exchangeRate *coins.ExchangeRate
+	if peg == nil {
+		exchangeRateDec, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagExchangeRate) //nolint:govet
+		if err != nil {
+			return err
+		}
+		exchangeRate = coins.ToExchangeRate(exchangeRateDec)
+	} else if ethAsset.IsToken() {
+		return fmt.Errorf("only offers for ETH can be pegged with --%s", flagPegSource)
+	}
 
-	if ethAsset.IsETH() {
-		symbol = "ETH"
+	var tokenInfo *coins.ERC20TokenInfo
+	symbol := "ETH"
 
-		if otherMin, err = exchangeRate.ToETH(min); err != nil {
+	if ethAsset.IsToken() {
+		info, err := c.TokenInfo(ethAsset.Address()) //nolint:govet
+		if err != nil {
 			return err
 		}
 
-		if otherMax, err = exchangeRate.ToETH(max); err != nil {
-			return err
+		if info.NonStandard {
+			return fmt.Errorf("token %s does not return a bool from transfer and approve, which is unsupported",
+				info.SanitizedSymbol())
 		}
-	} else {
-		tokenInfo, err := c.TokenInfo(ethAsset.Address()) //nolint:govet
-		if err != nil {
-			return err
+
+		symbol = strconv.Quote(info.Symbol)
+		tokenInfo = info.ERC20TokenInfo
+	}
+
+	// takerAmount returns the amount that takers provide for the XMR amount, the take
+	// range is rounded inwards so that the maker accepts it
+	takerAmount := func(rate *coins.ExchangeRate, xmrAmount *apd.Decimal, rounding apd.Rounder) (*apd.Decimal, error) {
+		if tokenInfo == nil {
+			return rate.ToETHRounded(xmrAmount, rounding)
 		}
+		return rate.ToERC20AmountRounded(xmrAmount, tokenInfo, rounding)
+	}
 
-		symbol = strconv.Quote(tokenInfo.Symbol)
+	printOfferSummary := func(offerResp *rpctypes.MakeOfferResponse) error {
+		rate := exchangeRate
+		if offerResp.ExchangeRate != nil {
+			rate = offerResp.ExchangeRate
+		}
 
-		if otherMin, err = exchangeRate.ToERC20Amount(min, tokenInfo); err != nil {
+		otherMin, err := takerAmount(rate, min, apd.RoundCeiling)
+		if err != nil {
 			return err
 		}
 
-		if otherMax, err = exchangeRate.ToERC20Amount(max, tokenInfo); err != nil {
+		otherMax, err := takerAmount(rate, max, apd.RoundFloor)
+		if err != nil {
 			return err
 		}
 
-	}
-
-	printOfferSummary := func(offerResp *rpctypes.MakeOfferResponse) {
 		fmt.Println("Published:")
 		fmt.Printf("\tOffer ID:  %s\n", offerResp.OfferID)
 		fmt.Printf("\tPeer ID:   %s\n", offerResp.PeerID)
+		if peg != nil {
+			fmt.Printf("\tPegged to: %s (currently %s ETH/XMR)\n", peg, rate)
+		}
 		fmt.Printf("\tTaker Min: %s %s\n", otherMin.Text('f'), symbol)
 		fmt.Printf("\tTaker Max: %s %s\n", otherMax.Text('f'), symbol)
+		return nil
 	}
 
 	alwaysUseRelayer := ctx.Bool(flagUseRelayer)
@@ -611,18 +1459,26 @@ func runMake(ctx *cli.Context) error {
 		}
 		defer wsc.Close()
 
-		resp, statusCh, err := wsc.MakeOfferAndSubscribe(
-			min,
-			max,
-			exchangeRate,
-			ethAsset,
-			alwaysUseRelayer,
-		)
+		var resp *rpctypes.MakeOfferResponse
+		var statusCh <-chan types.Status
+		if peg != nil {
+			resp, statusCh, err = wsc.MakePeggedOfferAndSubscribe(min, max, peg, alwaysUseRelayer)
+		} else {
+			resp, statusCh, err = wsc.MakeOfferAndSubscribe(
+				min,
+				max,
+				exchangeRate,
+				ethAsset,
+				alwaysUseRelayer,
+			)
+		}
 		if err != nil {
 			return err
 		}
 
-		printOfferSummary(resp)
+		if err = printOfferSummary(resp); err != nil {
+			return err
+		}
 
 		for stage := range statusCh {
 			fmt.Printf("%s > Stage updated: %s\n", time.Now().Format(common.TimeFmtSecs), stage)
@@ -634,16 +1490,45 @@ func runMake(ctx *cli.Context) error {
 		return nil
 	}
 
-	resp, err := c.MakeOffer(min, max, exchangeRate, ethAsset, alwaysUseRelayer)
+	var resp *rpctypes.MakeOfferResponse
+	if peg != nil {
+		resp, err = c.MakePeggedOffer(min, max, peg, alwaysUseRelayer)
+	} else {
+		resp, err = c.MakeOffer(min, max, exchangeRate, ethAsset, alwaysUseRelayer)
+	}
 	if err != nil {
 		return err
 	}
 
-	printOfferSummary(resp)
-	return nil
+	return printOfferSummary(resp)
 }
 
-func runTake(ctx *cli.Context) error {
+// readOfferPeg returns the peg of the offer's exchange rate, nil if the offer has a
+// fixed exchange rate.
+func readOfferPeg(ctx *cli.Context) (*types.OfferPeg, error) {
+	if !ctx.IsSet(flagPegSource) {
+		if ctx.IsSet(flagPegMarkup) {
+			return nil, fmt.Errorf("--%s requires --%s", flagPegMarkup, flagPegSource)
+		}
+		return nil, nil
+	}
+
+	if ctx.IsSet(flagExchangeRate) {
+		return nil, fmt.Errorf("--%s and --%s cannot both be set", flagExchangeRate, flagPegSource)
+	}
+
+	markup, _, err := apd.NewFromString(ctx.String(flagPegMarkup))
+	if err != nil {
+		return nil, errInvalidFlagValue(flagPegMarkup, err)
+	}
+
+	return &types.OfferPeg{
+		Source: ctx.String(flagPegSource),
+		Markup: markup,
+	}, nil
+}
+
+func runQuote(ctx *cli.Context) error {
 	peerID, err := peer.Decode(ctx.String(flagPeerID))
 	if err != nil {
 		return errInvalidFlagValue(flagPeerID, err)
@@ -653,12 +1538,47 @@ func runTake(ctx *cli.Context) error {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	providesAmount, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagProvidesAmount)
+	providesAmount, err := readProvidesAmountFlag(ctx)
 	if err != nil {
 		return err
 	}
 
-	if !ctx.Bool(flagDetached) {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Quote(peerID, offerID, providesAmount)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Provided: %s\n", coins.FormatAmount(resp.ProvidedAmount, resp.EthAsset.String()))
+	fmt.Printf("Exchange rate: %s\n", resp.ExchangeRate)
+	fmt.Printf("Locked by maker: %s\n", coins.FormatAmount(resp.ExpectedAmount, "XMR"))
+	fmt.Printf("XMR network fee: %s\n", coins.FormatAmount(resp.XMRFee, "XMR"))
+	fmt.Printf("Received: %s\n", coins.FormatAmount(resp.NetAmount, "XMR"))
+	fmt.Printf("Gas cost: %s\n", coins.FormatAmount(resp.GasCost, "ETH"))
+	fmt.Printf("Valid until: %s\n", resp.ValidUntil.Format(common.TimeFmtSecs))
+	return nil
+}
+
+func runTake(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
+	if err != nil {
+		return errInvalidFlagValue(flagPeerID, err)
+	}
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
+
+	providesAmount, err := readProvidesAmountFlag(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !ctx.Bool(flagDetached) {
 		wsc, err := newWSClient(ctx)
 		if err != nil {
 			return err
@@ -682,7 +1602,10 @@ func runTake(ctx *cli.Context) error {
 		return nil
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	if err := c.TakeOffer(peerID, offerID, providesAmount); err != nil {
 		return err
 	}
@@ -702,7 +1625,10 @@ func runGetOngoingSwap(ctx *cli.Context) error {
 		offerID = &hash
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	resp, err := c.GetOngoingSwap(offerID)
 	if err != nil {
 		return err
@@ -752,7 +1678,10 @@ func runGetPastSwap(ctx *cli.Context) error {
 		offerID = &hash
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	resp, err := c.GetPastSwap(offerID)
 	if err != nil {
 		return err
@@ -791,13 +1720,312 @@ func runGetPastSwap(ctx *cli.Context) error {
 	return nil
 }
 
+func runExportSwaps(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	format := ctx.String("format")
+	resp, err := c.ExportSwaps(format)
+	if err != nil {
+		return err
+	}
+
+	data := []byte(resp.CSV)
+	if format != rpc.ExportFormatCSV {
+		data, err = json.MarshalIndent(resp.Swaps, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	}
+
+	if !ctx.IsSet("output") {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	return os.WriteFile(ctx.String("output"), data, 0600)
+}
+
+func runEstimateFees(ctx *cli.Context) error {
+	provides, err := coins.NewProvidesCoin(ctx.String(flagProvides))
+	if err != nil {
+		return errInvalidFlagValue(flagProvides, err)
+	}
+
+	ethAsset := types.EthAssetETH
+	if ethAssetStr := ctx.String(flagToken); ethAssetStr != "" {
+		ethAsset = types.EthAsset(ethcommon.HexToAddress(ethAssetStr))
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	amount, err := readETHAssetAmountFlag(ctx, c, "amount", ethAsset)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.EstimateFees(&rpc.EstimateFeesRequest{
+		Provides:   provides,
+		EthAsset:   ethAsset,
+		Amount:     amount,
+		UseRelayer: ctx.Bool(flagUseRelayer),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Gas price: %s ETH\n", resp.GasPrice.AsEtherString())
+	for _, tx := range resp.Transactions {
+		fmt.Printf("\t%s: %d gas, %s ETH\n", tx.Name, tx.Gas, tx.Cost.Text('f'))
+	}
+	fmt.Printf("Total gas cost: %s ETH\n", resp.TotalGasCost.Text('f'))
+	fmt.Printf("XMR network fee: %s XMR\n", resp.XMRFee.Text('f'))
+	if resp.RelayerFee != nil {
+		fmt.Printf("Relayer fee: %s %s\n", resp.RelayerFee.Text('f'), ethAsset)
+	}
+
+	return nil
+}
+
+// readWithdrawalAmount returns the amount flag read by readAmount, or nil if the all
+// flag is set.
+func readWithdrawalAmount(
+	ctx *cli.Context,
+	readAmount func(ctx *cli.Context, flagName string) (*apd.Decimal, error),
+) (*apd.Decimal, error) {
+	if ctx.Bool(flagAll) {
+		if ctx.IsSet("amount") {
+			return nil, errFlagsMutuallyExclusive("amount", flagAll)
+		}
+		return nil, nil
+	}
+
+	if !ctx.IsSet("amount") {
+		return nil, fmt.Errorf("one of the flags --amount or --%s is required", flagAll)
+	}
+	return readAmount(ctx, "amount")
+}
+
+func readETHAddressFlag(ctx *cli.Context, flagName string) (ethcommon.Address, error) {
+	addr := ctx.String(flagName)
+	if !ethcommon.IsHexAddress(addr) {
+		return ethcommon.Address{}, errInvalidFlagValue(flagName, fmt.Errorf("invalid ethereum address %q", addr))
+	}
+	return ethcommon.HexToAddress(addr), nil
+}
+
+func runTransferETH(ctx *cli.Context) error {
+	to, err := readETHAddressFlag(ctx, flagTo)
+	if err != nil {
+		return err
+	}
+
+	req := &rpc.TransferETHRequest{
+		To:       to,
+		All:      ctx.Bool(flagAll),
+		Priority: rpc.FeePriority(ctx.String(flagPriority)),
+	}
+
+	ethAsset := types.EthAssetETH
+	unit := "ETH"
+	if ctx.IsSet(flagToken) {
+		var tokenAddr ethcommon.Address
+		tokenAddr, err = readETHAddressFlag(ctx, flagToken)
+		if err != nil {
+			return err
+		}
+		req.TokenAddr = &tokenAddr
+		ethAsset = types.EthAsset(tokenAddr)
+		unit = tokenAddr.Hex()
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	req.Amount, err = readWithdrawalAmount(ctx, func(ctx *cli.Context, flagName string) (*apd.Decimal, error) {
+		return readETHAssetAmountFlag(ctx, c, flagName, ethAsset)
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.TransferETH(req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Sent %s %s in transaction %s\n", resp.Amount.Text('f'), unit, resp.TxHash)
+	return nil
+}
+
+func runTransferXMR(ctx *cli.Context) error {
+	to := new(mcrypto.Address)
+	if err := to.UnmarshalText([]byte(ctx.String(flagTo))); err != nil {
+		return errInvalidFlagValue(flagTo, err)
+	}
+
+	amount, err := readWithdrawalAmount(ctx, readXMRAmountFlag)
+	if err != nil {
+		return err
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.TransferXMR(&rpc.TransferXMRRequest{
+		To:       to,
+		Amount:   amount,
+		All:      ctx.Bool(flagAll),
+		Priority: rpc.FeePriority(ctx.String(flagPriority)),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Sent %s XMR with a fee of %s XMR\n", resp.Amount.Text('f'), resp.Fee.Text('f'))
+	for _, txID := range resp.TxIDs {
+		fmt.Printf("\tTransaction ID: %s\n", txID)
+	}
+	return nil
+}
+
+func runSweepXMR(ctx *cli.Context) error {
+	req := &rpc.SweepXMRRequest{
+		Priority: rpc.FeePriority(ctx.String(flagPriority)),
+		Profile:  ctx.String(flagProfile),
+	}
+
+	if ctx.IsSet(flagTo) {
+		req.To = new(mcrypto.Address)
+		if err := req.To.UnmarshalText([]byte(ctx.String(flagTo))); err != nil {
+			return errInvalidFlagValue(flagTo, err)
+		}
+	}
+
+	if ctx.IsSet(flagBelowAmount) {
+		belowAmount, err := readXMRAmountFlag(ctx, flagBelowAmount)
+		if err != nil {
+			return err
+		}
+		req.BelowAmount = belowAmount
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.SweepXMR(req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Swept %s XMR to %s with a fee of %s XMR\n", resp.Amount.Text('f'), resp.To, resp.Fee.Text('f'))
+	for _, txID := range resp.TxIDs {
+		fmt.Printf("\tTransaction ID: %s\n", txID)
+	}
+	return nil
+}
+
+func runPlanConsolidation(ctx *cli.Context) error {
+	amount, err := readXMRAmountFlag(ctx, "amount")
+	if err != nil {
+		return err
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	plan, err := c.PlanConsolidation(&rpc.PlanConsolidationRequest{
+		Amount:  amount,
+		Profile: ctx.String(flagProfile),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Unlocked outputs: %d (%s XMR)\n", plan.UnlockedOutputs, plan.UnlockedBalance.Text('f'))
+	if !plan.Sufficient {
+		fmt.Printf("The unlocked balance doesn't cover %s XMR\n", amount.Text('f'))
+	}
+	fmt.Printf("Inputs needed to lock %s XMR: at least %d\n", amount.Text('f'), plan.InputsNeeded)
+
+	if !plan.Recommended {
+		fmt.Println("No consolidation needed")
+		return nil
+	}
+
+	fmt.Printf("Recommended: consolidate the %d outputs below %s XMR (%s XMR)\n",
+		plan.SweepOutputs, plan.SweepBelow.Text('f'), plan.SweepAmount.Text('f'))
+	if !ctx.Bool(flagConsolidate) {
+		fmt.Printf("Run again with --%s, or run `swapcli sweep-xmr --%s %s`, to consolidate them\n",
+			flagConsolidate, flagBelowAmount, plan.SweepBelow.Text('f'))
+		return nil
+	}
+
+	resp, err := c.SweepXMR(&rpc.SweepXMRRequest{
+		BelowAmount: plan.SweepBelow,
+		Priority:    rpc.FeePriority(ctx.String(flagPriority)),
+		Profile:     ctx.String(flagProfile),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Swept %s XMR to %s with a fee of %s XMR\n", resp.Amount.Text('f'), resp.To, resp.Fee.Text('f'))
+	for _, txID := range resp.TxIDs {
+		fmt.Printf("\tTransaction ID: %s\n", txID)
+	}
+	fmt.Println("The swept XMR is locked until the sweep transactions have 10 confirmations")
+	return nil
+}
+
+func runClearTokenInfoCache(ctx *cli.Context) error {
+	var tokenAddr *ethcommon.Address
+	if ctx.IsSet(flagToken) {
+		addr, err := readETHAddressFlag(ctx, flagToken)
+		if err != nil {
+			return err
+		}
+		tokenAddr = &addr
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.ClearTokenInfoCache(tokenAddr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed the cached metadata of %d token(s)\n", resp.Removed)
+	return nil
+}
+
 func runCancel(ctx *cli.Context) error {
 	offerID, err := types.HexToHash(ctx.String(flagOfferID))
 	if err != nil {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("Attempting to exit swap with id %s\n", offerID)
 	resp, err := c.Cancel(offerID)
 	if err != nil {
@@ -808,8 +2036,31 @@ func runCancel(ctx *cli.Context) error {
 	return nil
 }
 
+func runSetXMRPriority(ctx *cli.Context) error {
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	priority := ctx.String(flagPriority)
+	if err = c.SetXMRPriority(offerID, priority); err != nil {
+		return err
+	}
+
+	fmt.Printf("Monero transactions of swap %s will use the %s fee priority\n", offerID, priority)
+	return nil
+}
+
 func runClearOffers(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 
 	ids := ctx.String(flagOfferIDs)
 	if ids == "" {
@@ -830,7 +2081,7 @@ func runClearOffers(ctx *cli.Context) error {
 		}
 		offerIDs = append(offerIDs, id)
 	}
-	err := c.ClearOffers(offerIDs)
+	err = c.ClearOffers(offerIDs)
 	if err != nil {
 		return err
 	}
@@ -840,7 +2091,10 @@ func runClearOffers(ctx *cli.Context) error {
 }
 
 func runGetOffers(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	resp, err := c.GetOffers()
 	if err != nil {
 		return err
@@ -867,7 +2121,10 @@ func runGetStatus(ctx *cli.Context) error {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	resp, err := c.GetStatus(offerID)
 	if err != nil {
 		return err
@@ -884,8 +2141,11 @@ func runSetSwapTimeout(ctx *cli.Context) error {
 		return errNoDuration
 	}
 
-	c := newRRPClient(ctx)
-	err := c.SetSwapTimeout(uint64(duration))
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.SetSwapTimeout(uint64(duration))
 	if err != nil {
 		return err
 	}
@@ -895,7 +2155,10 @@ func runSetSwapTimeout(ctx *cli.Context) error {
 }
 
 func runGetSwapTimeout(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	resp, err := c.GetSwapTimeout()
 	if err != nil {
 		return err
@@ -906,7 +2169,10 @@ func runGetSwapTimeout(ctx *cli.Context) error {
 }
 
 func runSuggestedExchangeRate(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	resp, err := c.SuggestedExchangeRate()
 	if err != nil {
 		return err
@@ -922,7 +2188,10 @@ func runSuggestedExchangeRate(ctx *cli.Context) error {
 func runGetVersions(ctx *cli.Context) error {
 	fmt.Printf("swapcli: %s\n", cliutil.GetVersion())
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 	resp, err := c.Version()
 	if err != nil {
 		return err
@@ -936,12 +2205,106 @@ func runGetVersions(ctx *cli.Context) error {
 	return nil
 }
 
+func runGetDaemonStatus(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("ETH height: %d of %d (synced: %t)\n", resp.EthHeight, resp.EthHighestBlock, resp.EthSynced)
+	fmt.Printf("XMR wallet height: %d of %d (synced: %t)\n",
+		resp.MoneroWalletHeight, resp.MoneroDaemonHeight, resp.MoneroSynced)
+	if !resp.MoneroSynced {
+		eta := "unknown"
+		if resp.MoneroSyncETASeconds > 0 {
+			eta = (time.Duration(resp.MoneroSyncETASeconds) * time.Second).String()
+		}
+		fmt.Printf("XMR wallet sync: %.1f%% (ETA %s)\n", resp.MoneroSyncPercent, eta)
+	}
+	fmt.Printf("Peers: %d\n", resp.Peers)
+	fmt.Printf("Ongoing swaps: %d\n", resp.OngoingSwaps)
+	fmt.Printf("Open offers: %d\n", resp.OpenOffers)
+	fmt.Printf("Paused: %t\n", resp.Paused)
+	fmt.Printf("Ready: %t\n", resp.Ready)
+	if resp.Draining {
+		fmt.Printf("Draining: waiting for %d swaps to lock funds, until %s\n",
+			resp.DrainPending, resp.DrainDeadline.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func runMoneroNodes(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.MoneroNodeStatus()
+	if err != nil {
+		return err
+	}
+
+	for _, node := range resp.Nodes {
+		current := ""
+		if node.Current {
+			current = " (current)"
+		}
+		fmt.Printf("Node %d: %s:%d%s\n", node.Rank, node.Host, node.Port, current)
+		switch {
+		case node.Checked == nil:
+			fmt.Printf("\tNot checked yet\n")
+		case node.Healthy:
+			fmt.Printf("\tHealthy: height %d, version %s, latency %dms\n", node.Height, node.Version, node.LatencyMs)
+		default:
+			fmt.Printf("\tUnhealthy: %s\n", node.Error)
+		}
+	}
+
+	return nil
+}
+
+func runPause(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	if err = c.Pause(); err != nil {
+		return err
+	}
+	fmt.Println("Paused, ongoing swaps will continue until they complete")
+	return nil
+}
+
+func runResume(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+	if err = c.Resume(); err != nil {
+		return err
+	}
+	fmt.Println("Resumed")
+	return nil
+}
+
 func runShutdown(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-	err := c.Shutdown()
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
+
+	if !ctx.Bool("drain") {
+		return c.Shutdown()
+	}
+
+	if err = c.ShutdownDrain(ctx.Uint64("drain-timeout")); err != nil {
+		return err
+	}
+	fmt.Println("Draining, swapd shuts down when the ongoing swaps have locked funds or completed")
 	return nil
 }
 
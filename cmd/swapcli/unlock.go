@@ -0,0 +1,60 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func runUnlock(ctx *cli.Context) error {
+	// The password is read from stdin instead of a flag, so it does not end up in
+	// the shell history.
+	fmt.Print("Enter the password of the monero wallet: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = c.Unlock(strings.TrimRight(password, "\r\n")); err != nil {
+		return err
+	}
+
+	fmt.Println("Unlocked the monero wallet, swapd is starting")
+	return nil
+}
+
+func runRestoreXMRWallet(ctx *cli.Context) error {
+	// The seed is read from stdin instead of a flag, so it does not end up in the
+	// shell history.
+	fmt.Print("Enter the 25 word mnemonic seed of the monero wallet: ")
+	seed, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.RestoreXMRWallet(seed, uint64(ctx.Uint(flagRestoreHeight)))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored the monero wallet of %s, it is scanning the blockchain in the background\n", resp.Address)
+	return nil
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
 
 	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/rpcclient"
 )
@@ -20,14 +23,14 @@ func lookupToken(c *rpcclient.Client, tokenAddr ethcommon.Address) (*coins.ERC20
 		return token, nil
 	}
 
-	token, err := c.TokenInfo(tokenAddr)
+	tokenInfo, err := c.TokenInfo(tokenAddr)
 	if err != nil {
 		return nil, err
 	}
 
-	_tokenCache[tokenAddr] = token
+	_tokenCache[tokenAddr] = tokenInfo.ERC20TokenInfo
 
-	return token, nil
+	return tokenInfo.ERC20TokenInfo, nil
 }
 
 func ethAssetSymbol(c *rpcclient.Client, ethAsset types.EthAsset) (string, error) {
@@ -70,6 +73,8 @@ func printOffer(c *rpcclient.Client, o *types.Offer, index int, indent string) e
 		fmt.Printf("%s---\n", indent)
 	}
 
+	// the take range is rounded inwards, as the maker rejects provided amounts that
+	// convert to XMR amounts outside of the offer's range
 	xRate := o.ExchangeRate
 	var (
 		minTake *apd.Decimal
@@ -77,12 +82,12 @@ func printOffer(c *rpcclient.Client, o *types.Offer, index int, indent string) e
 		err     error
 	)
 	if o.EthAsset.IsETH() {
-		minTake, err = xRate.ToETH(o.MinAmount)
+		minTake, err = xRate.ToETHRounded(o.MinAmount, apd.RoundCeiling)
 		if err != nil {
 			return err
 		}
 
-		maxTake, err = xRate.ToETH(o.MaxAmount)
+		maxTake, err = xRate.ToETHRounded(o.MaxAmount, apd.RoundFloor)
 		if err != nil {
 			return err
 		}
@@ -92,12 +97,12 @@ func printOffer(c *rpcclient.Client, o *types.Offer, index int, indent string) e
 			return err
 		}
 
-		minTake, err = xRate.ToERC20Amount(o.MinAmount, token)
+		minTake, err = xRate.ToERC20AmountRounded(o.MinAmount, token, apd.RoundCeiling)
 		if err != nil {
 			return err
 		}
 
-		maxTake, err = xRate.ToERC20Amount(o.MaxAmount, token)
+		maxTake, err = xRate.ToERC20AmountRounded(o.MaxAmount, token, apd.RoundFloor)
 		if err != nil {
 			return err
 		}
@@ -118,9 +123,78 @@ func printOffer(c *rpcclient.Client, o *types.Offer, index int, indent string) e
 		fmt.Printf("%s       %s (self reported symbol)\n", indent, receivedCoin)
 	}
 	fmt.Printf("%sExchange Rate: %s %s/%s\n", indent, o.ExchangeRate, receivedCoin, providedCoin)
-	fmt.Printf("%sMaker Min: %s %s\n", indent, o.MinAmount.Text('f'), providedCoin)
-	fmt.Printf("%sMaker Max: %s %s\n", indent, o.MaxAmount.Text('f'), providedCoin)
-	fmt.Printf("%sTaker Min: %s %s\n", indent, minTake.Text('f'), receivedCoin)
-	fmt.Printf("%sTaker Max: %s %s\n", indent, maxTake.Text('f'), receivedCoin)
+	if o.Peg != nil {
+		fmt.Printf("%sPegged To: %s\n", indent, o.Peg)
+	}
+	fmt.Printf("%sMaker Min: %s\n", indent, coins.FormatAmount(o.MinAmount, providedCoin))
+	fmt.Printf("%sMaker Max: %s\n", indent, coins.FormatAmount(o.MaxAmount, providedCoin))
+	fmt.Printf("%sTaker Min: %s\n", indent, coins.FormatAmount(minTake, receivedCoin))
+	fmt.Printf("%sTaker Max: %s\n", indent, coins.FormatAmount(maxTake, receivedCoin))
 	return nil
 }
+
+// readXMRAmountFlag reads the XMR amount of the flag, like "1.5", "1.5 XMR" or
+// "1500000000000 piconero".
+func readXMRAmountFlag(ctx *cli.Context, flagName string) (*apd.Decimal, error) {
+	amount, err := coins.ParseXMRAmount(ctx.String(flagName))
+	if err != nil {
+		return nil, errInvalidFlagValue(flagName, err)
+	}
+	return amount, nil
+}
+
+// readETHAssetAmountFlag reads the amount of the ETH asset of the flag in standard
+// units, like "0.5", "0.5 ETH" or "20 gwei" for ETH, or "250" or "250 USDT" for a
+// token, whose decimals are looked up.
+func readETHAssetAmountFlag(
+	ctx *cli.Context,
+	c *rpcclient.Client,
+	flagName string,
+	ethAsset types.EthAsset,
+) (*apd.Decimal, error) {
+	var (
+		amount *apd.Decimal
+		token  *coins.ERC20TokenInfo
+		err    error
+	)
+	if ethAsset.IsETH() {
+		amount, err = coins.ParseETHAmount(ctx.String(flagName))
+	} else {
+		token, err = lookupToken(c, ethAsset.Address())
+		if err != nil {
+			return nil, err
+		}
+		amount, err = coins.ParseTokenAmount(ctx.String(flagName), token)
+	}
+	if err != nil {
+		return nil, errInvalidFlagValue(flagName, err)
+	}
+
+	return amount, nil
+}
+
+// readProvidesAmountFlag reads the amount of the ETH asset that we provide when
+// taking an offer, which swapd validates against the offer's asset. Amounts in XMR
+// are rejected, as the amount is what we provide, not what we receive.
+func readProvidesAmountFlag(ctx *cli.Context) (*apd.Decimal, error) {
+	amount, symbol, err := coins.ParseAmount(ctx.String(flagProvidesAmount))
+	if err == nil {
+		switch symbol {
+		case "XMR":
+			err = errors.New("the amount is of the offer's ETH asset that we provide, not XMR")
+		case "ETH":
+			err = coins.ValidateETHAmount(flagProvidesAmount, amount)
+		}
+	}
+	if err != nil {
+		return nil, errInvalidFlagValue(flagProvidesAmount, err)
+	}
+
+	return amount, nil
+}
+
+func printReputation(rep *rpctypes.PeerReputation, indent string) {
+	fmt.Printf("%sCompleted swaps: %d\n", indent, rep.Completed)
+	fmt.Printf("%sAborted after we locked: %d\n", indent, rep.AbortedAfterLock)
+	fmt.Printf("%sRefunded: %d\n", indent, rep.Refunded)
+}
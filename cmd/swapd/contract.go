@@ -7,12 +7,10 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"fmt"
-	"os"
 	"path"
-	"path/filepath"
 
+	"github.com/athanorlabs/atomic-swap/cliutil"
 	"github.com/athanorlabs/atomic-swap/common"
-	"github.com/athanorlabs/atomic-swap/common/vjson"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 
@@ -20,19 +18,10 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-const (
-	contractAddressesFile = "contract-addresses.json"
-)
-
 var (
 	errNoEthPrivateKey = fmt.Errorf("must provide --%s file for non-development environment", flagEthPrivKey)
 )
 
-type contractAddresses struct {
-	SwapCreatorAddr ethcommon.Address `json:"swapCreatorAddr" validate:"required"`
-	ForwarderAddr   ethcommon.Address `json:"forwarderAddr" validate:"required"`
-}
-
 func getOrDeploySwapCreator(
 	ctx context.Context,
 	swapCreatorAddr ethcommon.Address,
@@ -83,22 +72,28 @@ func deploySwapCreator(
 			return ethcommon.Address{}, nil, err
 		}
 	} else {
-		if err := contracts.CheckForwarderContractCode(ctx, ec, forwarderAddr); err != nil {
+		if _, err := contracts.CheckForwarderContractCode(ctx, ec, forwarderAddr); err != nil {
 			return ethcommon.Address{}, nil, err
 		}
 	}
 
-	swapCreatorAddr, sf, err := contracts.DeploySwapCreatorWithKey(ctx, ec, privkey, forwarderAddr)
+	swapCreatorAddr, receipt, err := contracts.DeploySwapCreatorWithReceipt(ctx, ec, privkey, forwarderAddr)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	sf, err := contracts.NewSwapCreator(swapCreatorAddr, ec)
 	if err != nil {
 		return ethcommon.Address{}, nil, err
 	}
 
 	// store the contract addresses on disk
-	err = writeContractAddressesToFile(
-		path.Join(dataDir, contractAddressesFile),
-		&contractAddresses{
-			SwapCreatorAddr: swapCreatorAddr,
-			ForwarderAddr:   forwarderAddr,
+	err = cliutil.WriteContractAddressesToFile(
+		path.Join(dataDir, common.DefaultContractAddressesFileName),
+		&cliutil.ContractAddresses{
+			SwapCreatorAddr:        swapCreatorAddr,
+			ForwarderAddr:          forwarderAddr,
+			SwapCreatorDeployBlock: receipt.BlockNumber.Uint64(),
 		},
 	)
 	if err != nil {
@@ -107,12 +102,3 @@ func deploySwapCreator(
 
 	return swapCreatorAddr, sf, nil
 }
-
-// writeContractAddressesToFile writes the contract addresses to the given file
-func writeContractAddressesToFile(filePath string, addresses *contractAddresses) error {
-	jsonData, err := vjson.MarshalIndentStruct(addresses, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(filepath.Clean(filePath), jsonData, 0600)
-}
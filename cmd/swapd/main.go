@@ -6,24 +6,38 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	logging "github.com/ipfs/go-log"
 	"github.com/urfave/cli/v2"
 
 	"github.com/athanorlabs/atomic-swap/cliutil"
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/daemon"
+	"github.com/athanorlabs/atomic-swap/ethereum/erc4337"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker"
 	"github.com/athanorlabs/atomic-swap/relayer"
+	"github.com/athanorlabs/atomic-swap/rpc"
+	"github.com/athanorlabs/atomic-swap/webhook"
 )
 
 const (
@@ -51,25 +65,96 @@ var (
 )
 
 const (
-	flagRPCPort    = "rpc-port"
-	flagDataDir    = "data-dir"
-	flagLibp2pKey  = "libp2p-key"
-	flagLibp2pPort = "libp2p-port"
-	flagBootnodes  = "bootnodes"
-
-	flagEnv                  = "env"
-	flagMoneroDaemonHost     = "monerod-host"
-	flagMoneroDaemonPort     = "monerod-port"
-	flagMoneroWalletPath     = "wallet-file"
-	flagMoneroWalletPassword = "wallet-password"
-	flagMoneroWalletPort     = "wallet-port"
-	flagEthEndpoint          = "eth-endpoint"
-	flagEthPrivKey           = "eth-privkey"
-	flagContractAddress      = "contract-address"
-	flagGasPrice             = "gas-price"
-	flagGasLimit             = "gas-limit"
-	flagUseExternalSigner    = "external-signer"
-	flagRelayer              = "relayer"
+	flagRPCPort     = "rpc-port"
+	flagRPCAuth     = "rpc-auth-file"
+	flagRPCTLSCert  = "rpc-tls-cert"
+	flagRPCTLSKey   = "rpc-tls-key"
+	flagRPCTLSAuto  = "rpc-tls-self-signed"
+	flagRPCTLSHosts = "rpc-tls-hosts"
+	flagRPCOrigins  = "rpc-cors-origins"
+	flagRPCUnix     = "rpc-unix-socket"
+	flagRPCNoTCP    = "rpc-unix-only"
+	flagRPCPublic   = "rpc-public-addr"
+	flagRPCMethods  = "rpc-cors-methods"
+	flagDataDir     = "data-dir"
+	flagLibp2pKey   = "libp2p-key"
+	flagLibp2pPort  = "libp2p-port"
+	flagBootnodes   = "bootnodes"
+	flagNoPortMap   = "no-port-mapping"
+	flagMDNS        = "mdns"
+	flagStaticPeers = "static-peers"
+
+	flagListenAddrs   = "libp2p-listen-addrs"
+	flagAnnounceAddrs = "libp2p-announce-addrs"
+
+	flagConnLowWater   = "conn-low-water"
+	flagConnHighWater  = "conn-high-water"
+	flagMaxPeerStreams = "max-streams-per-peer"
+	flagDialTimeout    = "dial-timeout"
+
+	flagProxy              = "proxy"
+	flagTorControl         = "tor-control"
+	flagTorControlPassword = "tor-control-password"
+
+	flagEnv                     = "env"
+	flagMoneroDaemonHost        = "monerod-host"
+	flagMoneroDaemonPort        = "monerod-port"
+	flagMoneroWalletPath        = "wallet-file"
+	flagMoneroWalletPassword    = "wallet-password"
+	flagMoneroWalletPort        = "wallet-port"
+	flagMoneroWalletRPCURL      = "wallet-rpc-url"
+	flagMoneroWalletRPCLogin    = "wallet-rpc-login"
+	flagMoneroWalletRPCName     = "wallet-rpc-wallet"
+	flagMoneroWalletPrompt      = "wallet-password-prompt"
+	flagMoneroWalletUnlock      = "wallet-unlock-rpc"
+	flagMoneroViewKey           = "wallet-view-key"
+	flagMoneroViewAddress       = "wallet-view-address"
+	flagMoneroRestoreHeight     = "wallet-restore-height"
+	flagMoneroRestoreDate       = "wallet-restore-date"
+	flagMoneroLightWalletURL    = "wallet-lws-url"
+	flagMoneroPriority          = "xmr-priority"
+	flagWalletProfiles          = "wallet-profiles"
+	flagEthEndpoint             = "eth-endpoint"
+	flagEthPrivKey              = "eth-privkey"
+	flagEthKeystorePassword     = "eth-keystore-password"
+	flagEthKeystorePasswordFile = "eth-keystore-password-file"
+	flagContractAddress         = "contract-address"
+	flagGasPrice                = "gas-price"
+	flagGasLimit                = "gas-limit"
+	flagUseExternalSigner       = "external-signer"
+	flagUseLedger               = "ledger"
+	flagLedgerPath              = "ledger-derivation-path"
+	flagEthSignerEndpoint       = "eth-signer-endpoint"
+	flagEthSignerAccount        = "eth-signer-account"
+	flagRelayer                 = "relayer"
+	flagRelayerFeeBPS           = "relayer-fee-bps"
+	flagRelayerMinFee           = "relayer-min-fee"
+	flagRelayerMaxFee           = "relayer-max-fee"
+	flagRelayerBatchWindow      = "relayer-batch-window"
+	flagRelayerMaxBatchSize     = "relayer-max-batch-size"
+	flagRelayerTokenFeeRate     = "relayer-token-fee-rate"
+	flagNoDirectClaim           = "no-direct-claim-fallback"
+	flagClaimRelayMargin        = "claim-relay-margin"
+	flagRelayAccessList         = "relay-access-list"
+	flagBundlerEndpoint         = "bundler-endpoint"
+	flagEntryPoint              = "entry-point"
+	flagClaimAccount            = "claim-account"
+	flagTokenList               = "token-list"
+	flagWebhook                 = "webhook"
+	flagWebhookSecret           = "webhook-secret"
+	flagPriceMaxStaleness       = "price-max-staleness"
+	flagPegMaxDeviation         = "peg-max-deviation"
+	flagPriceSource             = "price-source"
+
+	flagMarketMakerOffers      = "market-maker-offers"
+	flagMarketMakerMinAmount   = "market-maker-min-amount"
+	flagMarketMakerMaxAmount   = "market-maker-max-amount"
+	flagMarketMakerSpread      = "market-maker-spread"
+	flagMarketMakerPriceSource = "market-maker-price-source"
+	flagMarketMakerInterval    = "market-maker-reprice-interval"
+	flagMarketMakerXMRReserve  = "market-maker-xmr-reserve"
+	flagMarketMakerMinETH      = "market-maker-min-eth-balance"
+	flagMarketMakerRelayer     = "market-maker-use-relayer"
 
 	flagDevXMRTaker      = "dev-xmrtaker"
 	flagDevXMRMaker      = "dev-xmrmaker"
@@ -96,6 +181,56 @@ func cliApp() *cli.App {
 				Value:   defaultRPCPort,
 				EnvVars: []string{"SWAPD_RPC_PORT"},
 			},
+			&cli.StringFlag{
+				Name: flagRPCAuth,
+				Usage: "JSON file with the bearer tokens that RPC requests must present and their scopes " +
+					"(read, personal or admin), RPC requests are not authenticated if unset",
+			},
+			&cli.StringFlag{
+				Name:  flagRPCTLSCert,
+				Usage: "PEM certificate file with which the RPC server serves HTTPS and WSS, requires --" + flagRPCTLSKey,
+			},
+			&cli.StringFlag{
+				Name:  flagRPCTLSKey,
+				Usage: "PEM key file of the RPC server's TLS certificate",
+			},
+			&cli.BoolFlag{
+				Name: flagRPCTLSAuto,
+				Usage: "Serve HTTPS and WSS with a self-signed certificate for the local host, generated in " +
+					"{DATA_DIR}/" + common.DefaultRPCTLSCertFileName + " if missing",
+			},
+			&cli.StringSliceFlag{
+				Name: flagRPCTLSHosts,
+				Usage: "Extra hostnames and IP addresses of the --" + flagRPCTLSAuto + " certificate, " +
+					"which is regenerated when they change",
+			},
+			&cli.BoolFlag{
+				Name: flagRPCUnix,
+				Usage: "Also serve RPC on the unix socket {DATA_DIR}/" + common.DefaultRPCUnixSocketFileName +
+					", which only the user running swapd can connect to",
+			},
+			&cli.BoolFlag{
+				Name:  flagRPCNoTCP,
+				Usage: "Only serve RPC on the unix socket of --" + flagRPCUnix + ", without opening the RPC port",
+			},
+			&cli.StringFlag{
+				Name: flagRPCPublic,
+				Usage: "IP:PORT on which to also serve the read-only daemon_version, daemon_status, " +
+					"swap_getOffers and swap_getPast methods without authentication, e.g. 0.0.0.0:5005",
+			},
+			&cli.StringSliceFlag{
+				Name: flagRPCOrigins,
+				Usage: "Origins of the web frontends allowed to call the RPC server from a browser, " +
+					"comma separated if passing multiple to a single flag, \"*\" allows all origins",
+				Value:   cli.NewStringSlice(rpc.DefaultCORSConfig().AllowedOrigins...),
+				EnvVars: []string{"SWAPD_RPC_CORS_ORIGINS"},
+			},
+			&cli.StringSliceFlag{
+				Name: flagRPCMethods,
+				Usage: "HTTP methods that web frontends are allowed to use, " +
+					"comma separated if passing multiple to a single flag",
+				Value: cli.NewStringSlice(rpc.DefaultCORSConfig().AllowedMethods...),
+			},
 			&cli.StringFlag{
 				Name:  flagDataDir,
 				Usage: "Path to store swap artifacts",
@@ -112,6 +247,68 @@ func cliApp() *cli.App {
 				Value:   defaultLibp2pPort,
 				EnvVars: []string{"SWAPD_LIBP2P_PORT"},
 			},
+			&cli.StringSliceFlag{
+				Name: flagListenAddrs,
+				Usage: "libp2p multiaddr to listen on instead of all the interfaces at --libp2p-port, " +
+					"like /ip6/::/tcp/9900 or /ip4/0.0.0.0/udp/9900/quic-v1, " +
+					"comma separated if passing multiple to a single flag",
+				EnvVars: []string{"SWAPD_LIBP2P_LISTEN_ADDRS"},
+			},
+			&cli.StringSliceFlag{
+				Name: flagAnnounceAddrs,
+				Usage: "libp2p multiaddr to advertise to peers in addition to the addresses that we " +
+					"listen on and that peers observe, comma separated if passing multiple to a single flag",
+				EnvVars: []string{"SWAPD_LIBP2P_ANNOUNCE_ADDRS"},
+			},
+			&cli.BoolFlag{
+				Name: flagNoPortMap,
+				Usage: "Don't forward the libp2p port on the router with UPnP or NAT-PMP, " +
+					"which makes us reachable by peers without configuring the router",
+				EnvVars: []string{"SWAPD_NO_PORT_MAPPING"},
+			},
+			&cli.BoolFlag{
+				Name: flagMDNS,
+				Usage: "Find the swapd instances on the local network, and be found by them, with " +
+					"multicast DNS, which doesn't require bootnodes",
+				EnvVars: []string{"SWAPD_MDNS"},
+			},
+			&cli.IntFlag{
+				Name:  flagConnLowWater,
+				Usage: "Number of peer connections that are kept when closing connections above --" + flagConnHighWater,
+				Value: net.DefaultConnLimits().LowWater,
+			},
+			&cli.IntFlag{
+				Name: flagConnHighWater,
+				Usage: "Number of peer connections above which the least useful ones are closed, " +
+					"except the connections of peers with ongoing swaps",
+				Value: net.DefaultConnLimits().HighWater,
+			},
+			&cli.IntFlag{
+				Name:  flagMaxPeerStreams,
+				Usage: "Maximum number of streams that each peer can have open with us (default: libp2p's limit)",
+			},
+			&cli.DurationFlag{
+				Name:  flagDialTimeout,
+				Usage: "Time after which connecting to a peer fails",
+				Value: net.DefaultConnLimits().DialTimeout,
+			},
+			&cli.StringFlag{
+				Name: flagProxy,
+				Usage: "IP:PORT of a SOCKS5 proxy, like Tor's 127.0.0.1:9050, through which peers, " +
+					"the ethereum endpoint and non-local monerod nodes are connected to",
+				EnvVars: []string{"SWAPD_PROXY"},
+			},
+			&cli.StringFlag{
+				Name: flagTorControl,
+				Usage: "IP:PORT of the Tor control port, like 127.0.0.1:9051, through which an onion service " +
+					"is created that peers reach us at, requires --" + flagProxy,
+				EnvVars: []string{"SWAPD_TOR_CONTROL"},
+			},
+			&cli.StringFlag{
+				Name:    flagTorControlPassword,
+				Usage:   "Password of the Tor control port, its cookie file is used if unset",
+				EnvVars: []string{"SWAPD_TOR_CONTROL_PASSWORD"},
+			},
 			&cli.StringFlag{
 				Name:    flagEnv,
 				Usage:   "Environment to use: one of mainnet, stagenet, or dev",
@@ -136,14 +333,77 @@ func cliApp() *cli.App {
 				Value: fmt.Sprintf("{DATA-DIR}/wallet/%s", common.DefaultMoneroWalletName),
 			},
 			&cli.StringFlag{
-				Name:  flagMoneroWalletPassword,
-				Usage: "Password of monero wallet file",
+				Name:    flagMoneroWalletPassword,
+				Usage:   "Password of monero wallet file, used to encrypt the wallet when it is created",
+				EnvVars: []string{"SWAPD_WALLET_PASSWORD"},
+			},
+			&cli.BoolFlag{
+				Name:  flagMoneroWalletPrompt,
+				Usage: "Prompt for the password of the monero wallet file at startup",
+			},
+			&cli.BoolFlag{
+				Name: flagMoneroWalletUnlock,
+				Usage: "Wait for the password of the monero wallet file from the personal_unlock " +
+					"RPC method before starting, only personal_unlock is served until then",
 			},
 			&cli.UintFlag{
 				Name:   flagMoneroWalletPort,
 				Usage:  "The port that the internal monero-wallet-rpc instance listens on",
 				Hidden: true, // flag is for integration tests and won't be supported long term
 			},
+			&cli.StringFlag{
+				Name: flagMoneroViewKey,
+				Usage: "Private view key of another swapd's monero wallet, with which the wallet file is " +
+					"created as a view-only wallet that can't spend, for monitoring the other wallet",
+				EnvVars: []string{"SWAPD_WALLET_VIEW_KEY"},
+			},
+			&cli.StringFlag{
+				Name:  flagMoneroViewAddress,
+				Usage: fmt.Sprintf("Primary address of the monero wallet of --%s", flagMoneroViewKey),
+			},
+			&cli.UintFlag{
+				Name:  flagMoneroRestoreHeight,
+				Usage: fmt.Sprintf("Block height from which the view-only wallet of --%s scans", flagMoneroViewKey),
+			},
+			&cli.StringFlag{
+				Name: flagMoneroRestoreDate,
+				Usage: fmt.Sprintf("Creation date (YYYY-MM-DD) of the wallet of --%s, from which the block height "+
+					"that the view-only wallet scans from is estimated", flagMoneroViewKey),
+			},
+			&cli.StringFlag{
+				Name: flagMoneroLightWalletURL,
+				Usage: fmt.Sprintf("URL of a monero-lws light wallet server that scans the view-only wallet of --%s, "+
+					"instead of a wallet file synced with monerod", flagMoneroViewKey),
+				EnvVars: []string{"SWAPD_WALLET_LWS_URL"},
+			},
+			&cli.StringFlag{
+				Name: flagMoneroPriority,
+				Usage: fmt.Sprintf("Fee priority of the monero lock and sweep transactions of swaps, one of %s. "+
+					"Defaults to the wallet's default priority", strings.Join(monero.PriorityNames, ", ")),
+				EnvVars: []string{"SWAPD_XMR_PRIORITY"},
+			},
+			&cli.StringSliceFlag{
+				Name: flagWalletProfiles,
+				Usage: "Names of profiles, each with its own monero wallet and ethereum key in " +
+					"{DATA-DIR}/profiles/{NAME}, that offers and swaps can select to be funded from",
+				EnvVars: []string{"SWAPD_WALLET_PROFILES"},
+			},
+			&cli.StringFlag{
+				Name: flagMoneroWalletRPCURL,
+				Usage: "URL of an external monero-wallet-rpc instance, like http://10.0.0.5:18083, " +
+					"whose wallet is used instead of launching monero-wallet-rpc with --wallet-file",
+				EnvVars: []string{"SWAPD_WALLET_RPC_URL"},
+			},
+			&cli.StringFlag{
+				Name:    flagMoneroWalletRPCLogin,
+				Usage:   "USER:PASSWORD of the --rpc-login of the external monero-wallet-rpc instance",
+				EnvVars: []string{"SWAPD_WALLET_RPC_LOGIN"},
+			},
+			&cli.StringFlag{
+				Name: flagMoneroWalletRPCName,
+				Usage: "Name of the wallet file that the external monero-wallet-rpc instance opens, " +
+					"the wallet that is already open is used if not set",
+			},
 			&cli.StringFlag{
 				Name:    flagEthEndpoint,
 				Usage:   "Ethereum client endpoint",
@@ -152,14 +412,27 @@ func cliApp() *cli.App {
 			},
 			&cli.StringFlag{
 				Name:    flagEthPrivKey,
-				Usage:   "File containing ethereum private key as hex, new key is generated if missing",
+				Usage:   "File containing ethereum private key as hex or an encrypted keystore, new key is generated if missing",
 				Aliases: []string{"ethereum-privkey"},
 				EnvVars: []string{"SWAPD_ETH_PRIVKEY"},
 				Value:   fmt.Sprintf("{DATA-DIR}/%s", common.DefaultEthKeyFileName),
 			},
 			&cli.StringFlag{
-				Name:  flagContractAddress,
-				Usage: "Address of instance of SwapCreator.sol already deployed on-chain; required if running on mainnet",
+				Name:    flagEthKeystorePassword,
+				Usage:   "Password of an encrypted --" + flagEthPrivKey + " keystore, also used to encrypt a newly generated key",
+				EnvVars: []string{"SWAPD_ETH_KEYSTORE_PASSWORD"},
+			},
+			&cli.StringFlag{
+				Name: flagEthKeystorePasswordFile,
+				Usage: "File whose first line is the --" + flagEthKeystorePassword +
+					", prompted for on a terminal if neither is set",
+				EnvVars: []string{"SWAPD_ETH_KEYSTORE_PASSWORD_FILE"},
+			},
+			&cli.StringFlag{
+				Name: flagContractAddress,
+				Usage: fmt.Sprintf("Address of instance of SwapCreator.sol already deployed on-chain "+
+					"(defaults to the address in {DATA_DIR}/%s, if present); required if running on mainnet",
+					common.DefaultContractAddressesFileName),
 			},
 			&cli.StringSliceFlag{
 				Name:    flagBootnodes,
@@ -167,6 +440,12 @@ func cliApp() *cli.App {
 				Usage:   "libp2p bootnode, comma separated if passing multiple to a single flag",
 				EnvVars: []string{"SWAPD_BOOTNODES"},
 			},
+			&cli.StringSliceFlag{
+				Name: flagStaticPeers,
+				Usage: "libp2p address, with the peer ID, of a peer to always stay connected to, " +
+					"comma separated if passing multiple to a single flag",
+				EnvVars: []string{"SWAPD_STATIC_PEERS"},
+			},
 			&cli.UintFlag{
 				Name:  flagGasPrice,
 				Usage: "Ethereum gas price to use for transactions (in gwei). If not set, the gas price is set via oracle.",
@@ -205,14 +484,169 @@ func cliApp() *cli.App {
 				Name:  flagUseExternalSigner,
 				Usage: "Use external signer, for usage with the swap UI",
 			},
+			&cli.BoolFlag{
+				Name:  flagUseLedger,
+				Usage: "Sign ethereum transactions with a connected Ledger device instead of a key file",
+			},
+			&cli.StringFlag{
+				Name:  flagLedgerPath,
+				Usage: "Derivation path of the Ledger account to use with --" + flagUseLedger,
+				Value: extethclient.DefaultLedgerDerivationPath,
+			},
+			&cli.StringFlag{
+				Name:  flagEthSignerEndpoint,
+				Usage: "Delegate ethereum signing to a Clef instance at this IPC path or HTTP URL",
+			},
+			&cli.StringFlag{
+				Name:  flagEthSignerAccount,
+				Usage: "Account of the --" + flagEthSignerEndpoint + " signer to use (default: first listed)",
+			},
 			&cli.BoolFlag{
 				Name: flagRelayer,
 				Usage: fmt.Sprintf(
-					"Relay claims for XMR makers and earn %s ETH (minus gas fees) per transaction",
-					relayer.FeeEth.Text('f'),
+					"Relay claims for XMR makers and earn the --%s fee (minus gas fees) per transaction",
+					flagRelayerFeeBPS,
 				),
 				Value: false,
 			},
+			&cli.UintFlag{
+				Name: flagRelayerFeeBPS,
+				Usage: "Relayer fee in basis points of the swap value, offered to relayers of our " +
+					"claims and required for claims we relay",
+				Value: relayer.DefaultFeeBasisPoints,
+			},
+			&cli.StringFlag{
+				Name:  flagRelayerMinFee,
+				Usage: "Minimum relayer fee in ETH",
+				Value: coins.FmtWeiAsETH(relayer.DefaultMinFeeWei),
+			},
+			&cli.StringFlag{
+				Name:  flagRelayerMaxFee,
+				Usage: "Maximum relayer fee in ETH",
+				Value: coins.FmtWeiAsETH(relayer.DefaultMaxFeeWei),
+			},
+			&cli.StringSliceFlag{
+				Name: flagRelayerTokenFeeRate,
+				Usage: "TOKEN_ADDRESS=TOKENS_PER_ETH rate at which relayer fees of the token's swaps are " +
+					"paid in the token, comma separated if passing multiple to a single flag",
+			},
+			&cli.DurationFlag{
+				Name: flagRelayerBatchWindow,
+				Usage: fmt.Sprintf(
+					"Time to collect relayed claims into one Multicall3 transaction, 0 disables batching (suggested: %s)",
+					relayer.DefaultBatchWindow,
+				),
+			},
+			&cli.IntFlag{
+				Name:  flagRelayerMaxBatchSize,
+				Usage: "Maximum number of relayed claims in one batch transaction",
+				Value: relayer.DefaultMaxBatchSize,
+			},
+			&cli.StringFlag{
+				Name: flagRelayAccessList,
+				Usage: "JSON file with the peers and claimer addresses allowed or denied relaying, " +
+					"and the trusted relayers of our claims, created by the relayer_setAccessList RPC if missing",
+			},
+			&cli.StringSliceFlag{
+				Name: flagWebhook,
+				Usage: "URL that swap lifecycle events are POSTed to, comma separated if passing " +
+					"multiple to a single flag",
+				EnvVars: []string{"SWAPD_WEBHOOKS"},
+			},
+			&cli.StringFlag{
+				Name:    flagWebhookSecret,
+				Usage:   "Key of the HMAC-SHA256 signature of the payloads POSTed to the --" + flagWebhook + " URLs",
+				EnvVars: []string{"SWAPD_WEBHOOK_SECRET"},
+			},
+			&cli.BoolFlag{
+				Name:  flagNoDirectClaim,
+				Usage: "Don't claim with our own transaction, paying for gas, when relaying our claim fails",
+			},
+			&cli.DurationFlag{
+				Name: flagClaimRelayMargin,
+				Usage: "Stop trying relayers for our claim this long before the swap timeout, " +
+					"leaving time for a direct claim (default: a tenth of the claim window)",
+			},
+			&cli.StringFlag{
+				Name: flagBundlerEndpoint,
+				Usage: "ERC-4337 bundler endpoint whose paymaster service sponsors user operations, " +
+					"relayed claims are first submitted as user operations to it",
+			},
+			&cli.StringFlag{
+				Name:  flagEntryPoint,
+				Usage: "ERC-4337 entry point contract used with --" + flagBundlerEndpoint,
+				Value: erc4337.DefaultEntryPointAddress.Hex(),
+			},
+			&cli.StringFlag{
+				Name:  flagClaimAccount,
+				Usage: "SwapClaimAccount contract that claims are submitted through with --" + flagBundlerEndpoint,
+			},
+			&cli.StringFlag{
+				Name: flagPriceSource,
+				Usage: fmt.Sprintf("Price source of the suggested exchange rate: %s, %s or %s",
+					pricefeed.SourceChainlink, pricefeed.SourceCoinGecko, pricefeed.SourceKraken),
+				Value: pricefeed.SourceChainlink,
+			},
+			&cli.DurationFlag{
+				Name: flagPriceMaxStaleness,
+				Usage: "Maximum age of the prices that the exchange rates of pegged offers are resolved with, " +
+					"pegged offers can't be taken while their price source is staler",
+				Value: pricefeed.DefaultMaxStaleness,
+			},
+			&cli.StringFlag{
+				Name: flagPegMaxDeviation,
+				Usage: "Percent by which the exchange rate that a taker takes a pegged offer at can be " +
+					"below the offer's current exchange rate",
+				Value: strconv.Itoa(xmrmaker.DefaultPegMaxDeviation),
+			},
+			&cli.IntFlag{
+				Name: flagMarketMakerOffers,
+				Usage: "Number of XMR offers that the market maker keeps open at the market exchange rate " +
+					"plus the --" + flagMarketMakerSpread + ", 0 disables the market maker",
+			},
+			&cli.StringFlag{
+				Name:  flagMarketMakerMinAmount,
+				Usage: "Minimum XMR amount of the market maker's offers",
+			},
+			&cli.StringFlag{
+				Name:  flagMarketMakerMaxAmount,
+				Usage: "Maximum XMR amount of the market maker's offers, lowered to their share of the available XMR",
+			},
+			&cli.StringFlag{
+				Name:  flagMarketMakerSpread,
+				Usage: "Percent above the market exchange rate that the market maker offers XMR at",
+				Value: "1",
+			},
+			&cli.StringFlag{
+				Name: flagMarketMakerPriceSource,
+				Usage: fmt.Sprintf("Price source of the market maker's exchange rate: %s, %s or %s",
+					pricefeed.SourceChainlink, pricefeed.SourceCoinGecko, pricefeed.SourceKraken),
+				Value: pricefeed.SourceChainlink,
+			},
+			&cli.DurationFlag{
+				Name:  flagMarketMakerInterval,
+				Usage: "Interval at which the market maker reprices its offers",
+				Value: xmrmaker.DefaultMarketMakerInterval,
+			},
+			&cli.StringFlag{
+				Name: flagMarketMakerXMRReserve,
+				Usage: "Unlocked XMR that the market maker never offers, quoting pauses when the " +
+					"unlocked balance above it is below the --" + flagMarketMakerMinAmount,
+				Value: "0.01",
+			},
+			&cli.StringFlag{
+				Name:  flagMarketMakerMinETH,
+				Usage: "ETH balance below which the market maker pauses quoting, as claims need gas, 0 disables it",
+				Value: "0.01",
+			},
+			&cli.BoolFlag{
+				Name:  flagMarketMakerRelayer,
+				Usage: "Have relayers claim the ETH of the market maker's swaps",
+			},
+			&cli.StringFlag{
+				Name:  flagTokenList,
+				Usage: "Path to a JSON token list, in the Uniswap token list format, that adds to or overrides the built-in supported tokens",
+			},
 			&cli.StringFlag{
 				Name:   flagProfile,
 				Usage:  "BIND_IP:PORT to provide profiling information on",
@@ -259,17 +693,37 @@ func runDaemon(c *cli.Context) error {
 		return err
 	}
 
-	mc, err := createMoneroClient(c, envConf)
+	proxy, err := getProxy(c)
 	if err != nil {
 		return err
 	}
-	defer mc.Close()
 
-	if err = maybeBackgroundMine(c.Context, devXMRMaker, mc.PrimaryAddress()); err != nil {
+	walletConf, err := createMoneroWalletConf(c, envConf, proxy)
+	if err != nil {
 		return err
 	}
 
-	ec, err := createEthClient(c, envConf)
+	// With --wallet-unlock-rpc, the daemon opens the wallet once personal_unlock
+	// supplies its password
+	unlockOverRPC := c.Bool(flagMoneroWalletUnlock)
+	if unlockOverRPC && devXMRMaker {
+		return errFlagsMutuallyExclusive(flagMoneroWalletUnlock, flagDevXMRMaker)
+	}
+
+	var mc monero.WalletClient
+	if !unlockOverRPC {
+		mc, err = monero.NewWalletClient(walletConf)
+		if err != nil {
+			return err
+		}
+		defer mc.Close()
+
+		if err = maybeBackgroundMine(c.Context, devXMRMaker, mc.PrimaryAddress()); err != nil {
+			return err
+		}
+	}
+
+	ec, err := createEthClient(c, envConf, proxy)
 	if err != nil {
 		return err
 	}
@@ -279,11 +733,27 @@ func runDaemon(c *cli.Context) error {
 		return err
 	}
 
-	conf, err := createSwapdConf(c, envConf, mc, ec)
+	conf, err := createSwapdConf(c, envConf, mc, ec, proxy)
 	if err != nil {
 		return err
 	}
 
+	if unlockOverRPC && c.IsSet(flagWalletProfiles) {
+		return errFlagsMutuallyExclusive(flagMoneroWalletUnlock, flagWalletProfiles)
+	}
+	conf.Profiles, err = createProfiles(c, envConf, walletConf, proxy)
+	if err != nil {
+		return err
+	}
+	defer closeProfiles(conf.Profiles)
+	if unlockOverRPC {
+		conf.UnlockMoneroWallet = func(password string) (monero.WalletClient, error) {
+			unlockConf := *walletConf
+			unlockConf.WalletPassword = password
+			return monero.NewWalletClient(&unlockConf)
+		}
+	}
+
 	err = daemon.RunSwapDaemon(c.Context, conf)
 	if err != nil && !errors.Is(err, context.Canceled) {
 		return err
@@ -338,6 +808,8 @@ func getEnvConfig(c *cli.Context, devXMRMaker bool, devXMRTaker bool) (*common.C
 				return nil, fmt.Errorf("%q requires a valid ethereum address", flagContractAddress)
 			}
 			conf.SwapCreatorAddr = ethcommon.HexToAddress(contractAddrStr)
+		} else if err = useContractAddressesFile(conf); err != nil {
+			return nil, err
 		}
 
 		if conf.SwapCreatorAddr == (ethcommon.Address{}) {
@@ -348,6 +820,38 @@ func getEnvConfig(c *cli.Context, devXMRMaker bool, devXMRTaker bool) (*common.C
 	return conf, nil
 }
 
+// useContractAddressesFile uses the contract of a previous deployment into the data
+// dir, if any. The file is ignored on mainnet, where the hard-coded contract is only
+// replaced by an explicit --contract-address.
+func useContractAddressesFile(conf *common.Config) error {
+	exists, err := common.FileExists(conf.ContractAddressesFile())
+	if err != nil || !exists {
+		return err
+	}
+
+	if conf.Env == common.Mainnet {
+		log.Warnf("Ignoring %s on %s, pass --%s to use another SwapCreator.sol than %s",
+			conf.ContractAddressesFile(), conf.Env, flagContractAddress, conf.SwapCreatorAddr)
+		return nil
+	}
+
+	addresses, err := cliutil.ReadContractAddressesFromFile(conf.ContractAddressesFile())
+	if err != nil {
+		return err
+	}
+
+	if conf.SwapCreatorAddr != (ethcommon.Address{}) && conf.SwapCreatorAddr != addresses.SwapCreatorAddr {
+		log.Warnf("Using SwapCreator.sol at %s from %s instead of the %s default %s",
+			addresses.SwapCreatorAddr, conf.ContractAddressesFile(), conf.Env, conf.SwapCreatorAddr)
+	} else {
+		log.Infof("Using SwapCreator.sol address from %s", conf.ContractAddressesFile())
+	}
+
+	conf.SwapCreatorAddr = addresses.SwapCreatorAddr
+	conf.SwapCreatorDeployBlock = addresses.SwapCreatorDeployBlock
+	return nil
+}
+
 // validateOrDeployContracts validates or deploys the swap creator. The SwapCreatorAddr field
 // of envConf should be all zeros if deploying and its value will be replaced by the new deployed
 // contract.
@@ -390,7 +894,29 @@ func validateOrDeployContracts(c *cli.Context, envConf *common.Config, ec exteth
 	return nil
 }
 
-func createMoneroClient(c *cli.Context, envConf *common.Config) (monero.WalletClient, error) {
+// getProxy returns the SOCKS5 proxy of outbound connections, or nil if none is set.
+func getProxy(c *cli.Context) (*common.Proxy, error) {
+	if !c.IsSet(flagProxy) {
+		if c.IsSet(flagTorControl) {
+			return nil, fmt.Errorf("using flag %q requires the %q flag", flagTorControl, flagProxy)
+		}
+		return nil, nil
+	}
+
+	proxy, err := common.NewProxy(c.String(flagProxy))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q value: %w", flagProxy, err)
+	}
+
+	return proxy, nil
+}
+
+// createMoneroWalletConf returns the configuration of the monero wallet client.
+func createMoneroWalletConf(
+	c *cli.Context,
+	envConf *common.Config,
+	proxy *common.Proxy,
+) (*monero.WalletClientConf, error) {
 	if c.IsSet(flagMoneroDaemonHost) || c.IsSet(flagMoneroDaemonPort) {
 		node := &common.MoneroNode{
 			Host: "127.0.0.1",
@@ -419,54 +945,358 @@ func createMoneroClient(c *cli.Context, envConf *common.Config) (monero.WalletCl
 		}
 	}
 
-	return monero.NewWalletClient(&monero.WalletClientConf{
+	externalWallet, err := getExternalWalletConf(c)
+	if err != nil {
+		return nil, err
+	}
+
+	walletPassword, err := getMoneroWalletPassword(c)
+	if err != nil {
+		return nil, err
+	}
+
+	viewOnly, err := getViewOnlyWalletConf(c, envConf.Env)
+	if err != nil {
+		return nil, err
+	}
+	if viewOnly != nil && externalWallet != nil {
+		return nil, errFlagsMutuallyExclusive(flagMoneroViewKey, flagMoneroWalletRPCURL)
+	}
+
+	var lightWallet *monero.LightWalletConf
+	if c.IsSet(flagMoneroLightWalletURL) {
+		if viewOnly == nil {
+			return nil, fmt.Errorf("using flag %q requires the %q flag", flagMoneroLightWalletURL, flagMoneroViewKey)
+		}
+		lightWallet = &monero.LightWalletConf{URL: c.String(flagMoneroLightWalletURL)}
+		if lightWallet.URL == "" {
+			return nil, errFlagValueEmpty(flagMoneroLightWalletURL)
+		}
+	}
+
+	return &monero.WalletClientConf{
 		Env:                 envConf.Env,
 		WalletFilePath:      walletFilePath,
 		MonerodNodes:        envConf.MoneroNodes,
 		MoneroWalletRPCPath: "", // look for it in "./monero-bin/monero-wallet-rpc" and then the user's path
-		WalletPassword:      c.String(flagMoneroWalletPassword),
+		WalletPassword:      walletPassword,
 		WalletPort:          c.Uint(flagMoneroWalletPort),
-	})
+		Proxy:               proxy,
+		ExternalWallet:      externalWallet,
+		ViewOnly:            viewOnly,
+		LightWallet:         lightWallet,
+	}, nil
 }
 
-func createEthClient(c *cli.Context, envConf *common.Config) (extethclient.EthClient, error) {
-	env := envConf.Env
+// getViewOnlyWalletConf returns the configuration of the view-only wallet, or nil if
+// the wallet can spend.
+func getViewOnlyWalletConf(c *cli.Context, env common.Environment) (*monero.ViewOnlyWalletConf, error) {
+	if !c.IsSet(flagMoneroViewKey) {
+		for _, flag := range []string{flagMoneroViewAddress, flagMoneroRestoreHeight, flagMoneroRestoreDate} {
+			if c.IsSet(flag) {
+				return nil, fmt.Errorf("using flag %q requires the %q flag", flag, flagMoneroViewKey)
+			}
+		}
+		return nil, nil
+	}
+
+	if !c.IsSet(flagMoneroViewAddress) {
+		return nil, fmt.Errorf("using flag %q requires the %q flag", flagMoneroViewKey, flagMoneroViewAddress)
+	}
 
-	ethEndpoint := common.DefaultEthEndpoint
-	if c.String(flagEthEndpoint) != "" {
-		ethEndpoint = c.String(flagEthEndpoint)
+	viewKey := new(mcrypto.PrivateViewKey)
+	if err := viewKey.UnmarshalText([]byte(c.String(flagMoneroViewKey))); err != nil {
+		return nil, fmt.Errorf("invalid %q value: %w", flagMoneroViewKey, err)
 	}
 
-	var ethPrivKey *ecdsa.PrivateKey
+	address, err := mcrypto.NewAddress(c.String(flagMoneroViewAddress), env)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q value: %w", flagMoneroViewAddress, err)
+	}
+
+	conf := &monero.ViewOnlyWalletConf{
+		Address:       address,
+		ViewKey:       viewKey,
+		RestoreHeight: uint64(c.Uint(flagMoneroRestoreHeight)),
+	}
+
+	if c.IsSet(flagMoneroRestoreDate) {
+		if c.IsSet(flagMoneroRestoreHeight) {
+			return nil, errFlagsMutuallyExclusive(flagMoneroRestoreHeight, flagMoneroRestoreDate)
+		}
+
+		conf.RestoreDate, err = parseRestoreDate(c.String(flagMoneroRestoreDate))
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	return conf, nil
+}
+
+// parseRestoreDate parses the value of the wallet restore date flag, which can't be
+// in the future.
+func parseRestoreDate(value string) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %q value: %w", flagMoneroRestoreDate, err)
+	}
+
+	if date.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%q value %s is in the future", flagMoneroRestoreDate, value)
+	}
+
+	return date, nil
+}
+
+// getEthKeystorePassword returns the password of the ethereum key file from the
+// password flag or file, or prompts for it if the file is an encrypted keystore. The
+// password is then set as the flag's value, so that the profiles' keys and the daemon
+// use the same password without prompting again.
+func getEthKeystorePassword(c *cli.Context, ethPrivKeyFile string) (string, error) {
+	if password := c.String(flagEthKeystorePassword); password != "" {
+		return password, nil
+	}
+
+	password, err := cliutil.GetEthKeystorePassword("", c.String(flagEthKeystorePasswordFile), ethPrivKeyFile)
+	if err != nil || password == "" {
+		return "", err
+	}
+
+	if err = c.Set(flagEthKeystorePassword, password); err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+// getMoneroWalletPassword returns the password of the monero wallet file, prompting
+// for it if requested. It is empty when the password is supplied over RPC.
+func getMoneroWalletPassword(c *cli.Context) (string, error) {
+	passwordFlags := []string{flagMoneroWalletPassword, flagMoneroWalletPrompt, flagMoneroWalletUnlock}
+	for i, flag1 := range passwordFlags {
+		for _, flag2 := range passwordFlags[i+1:] {
+			if c.IsSet(flag1) && c.IsSet(flag2) {
+				return "", errFlagsMutuallyExclusive(flag1, flag2)
+			}
+		}
+	}
+
+	if !c.Bool(flagMoneroWalletPrompt) {
+		return c.String(flagMoneroWalletPassword), nil
+	}
+
+	// The password is read from stdin instead of a flag, so it does not end up in
+	// the shell history or the process list.
+	fmt.Print("Enter the password of the monero wallet: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return "", errors.New("the monero wallet password can't be empty")
+	}
+
+	return password, nil
+}
+
+// getExternalWalletConf returns the configuration of the external monero-wallet-rpc
+// instance, or nil if we launch our own. The temporary swap wallets are still created
+// in the directory of the default wallet file.
+func getExternalWalletConf(c *cli.Context) (*monero.ExternalWalletConf, error) {
+	if !c.IsSet(flagMoneroWalletRPCURL) {
+		for _, flag := range []string{flagMoneroWalletRPCLogin, flagMoneroWalletRPCName} {
+			if c.IsSet(flag) {
+				return nil, fmt.Errorf("using flag %q requires the %q flag", flag, flagMoneroWalletRPCURL)
+			}
+		}
+		return nil, nil
+	}
+
+	for _, flag := range []string{flagMoneroWalletPath, flagMoneroWalletPort} {
+		if c.IsSet(flag) {
+			return nil, errFlagsMutuallyExclusive(flagMoneroWalletRPCURL, flag)
+		}
+	}
+
+	conf := &monero.ExternalWalletConf{
+		URL:        c.String(flagMoneroWalletRPCURL),
+		WalletName: c.String(flagMoneroWalletRPCName),
+	}
+	if conf.URL == "" {
+		return nil, errFlagValueEmpty(flagMoneroWalletRPCURL)
+	}
+
+	if c.IsSet(flagMoneroWalletRPCLogin) {
+		var ok bool
+		conf.Username, conf.Password, ok = strings.Cut(c.String(flagMoneroWalletRPCLogin), ":")
+		if !ok || conf.Username == "" {
+			return nil, fmt.Errorf("flag %q requires a USER:PASSWORD value", flagMoneroWalletRPCLogin)
+		}
+	}
+
+	return conf, nil
+}
+
+// ethSignerSource is the source of the signer of swapd's ethereum transactions.
+type ethSignerSource int
+
+const (
+	ethSignerKeyFile  ethSignerSource = iota // the key in --eth-privkey
+	ethSignerExternal                        // the front-end, with --external-signer
+	ethSignerLedger                          // a Ledger device, with --ledger
+	ethSignerClef                            // a Clef instance, with --eth-signer-endpoint
+)
+
+// errEthSignerLoad is returned when the configured signer can't be loaded, as opposed
+// to swapd not having a signer at all with --external-signer.
+var errEthSignerLoad = errors.New("failed to load the configured ethereum signer")
+
+// getEthSignerSource returns the source of the ethereum signer selected by the
+// flags, which are mutually exclusive.
+func getEthSignerSource(c *cli.Context) (ethSignerSource, error) {
 	useExternalSigner := c.Bool(flagUseExternalSigner)
 	if useExternalSigner && c.IsSet(flagEthPrivKey) {
-		return nil, errFlagsMutuallyExclusive(flagUseExternalSigner, flagEthPrivKey)
+		return 0, errFlagsMutuallyExclusive(flagUseExternalSigner, flagEthPrivKey)
 	}
 
-	if !useExternalSigner {
-		ethPrivKeyFile := envConf.EthKeyFileName()
-		if c.IsSet(flagEthPrivKey) {
-			ethPrivKeyFile = c.String(flagEthPrivKey)
-			if ethPrivKeyFile == "" {
-				return nil, errFlagValueEmpty(flagEthPrivKey)
-			}
+	useLedger := c.Bool(flagUseLedger)
+	if useLedger && useExternalSigner {
+		return 0, errFlagsMutuallyExclusive(flagUseLedger, flagUseExternalSigner)
+	}
+	if useLedger && c.IsSet(flagEthPrivKey) {
+		return 0, errFlagsMutuallyExclusive(flagUseLedger, flagEthPrivKey)
+	}
+	if !useLedger && c.IsSet(flagLedgerPath) {
+		return 0, fmt.Errorf("using flag %q requires the %q flag", flagLedgerPath, flagUseLedger)
+	}
+
+	useClef := c.IsSet(flagEthSignerEndpoint)
+	for _, flag := range []string{flagUseExternalSigner, flagUseLedger, flagEthPrivKey} {
+		if useClef && c.IsSet(flag) {
+			return 0, errFlagsMutuallyExclusive(flagEthSignerEndpoint, flag)
 		}
+	}
+	if useClef && c.String(flagEthSignerEndpoint) == "" {
+		return 0, errFlagValueEmpty(flagEthSignerEndpoint)
+	}
+	if !useClef && c.IsSet(flagEthSignerAccount) {
+		return 0, fmt.Errorf("using flag %q requires the %q flag", flagEthSignerAccount, flagEthSignerEndpoint)
+	}
+
+	if c.IsSet(flagEthKeystorePassword) && c.IsSet(flagEthKeystorePasswordFile) {
+		return 0, errFlagsMutuallyExclusive(flagEthKeystorePassword, flagEthKeystorePasswordFile)
+	}
+	if c.IsSet(flagEthKeystorePasswordFile) && c.String(flagEthKeystorePasswordFile) == "" {
+		return 0, errFlagValueEmpty(flagEthKeystorePasswordFile)
+	}
 
-		devXMRMaker := c.Bool(flagDevXMRMaker)
-		devXMRTaker := c.Bool(flagDevXMRTaker)
-		if devXMRMaker && devXMRTaker {
-			return nil, errFlagsMutuallyExclusive(flagDevXMRMaker, flagDevXMRTaker)
+	switch {
+	case useExternalSigner:
+		return ethSignerExternal, nil
+	case useLedger:
+		return ethSignerLedger, nil
+	case useClef:
+		return ethSignerClef, nil
+	default:
+		if c.IsSet(flagEthPrivKey) && c.String(flagEthPrivKey) == "" {
+			return 0, errFlagValueEmpty(flagEthPrivKey)
 		}
+		return ethSignerKeyFile, nil
+	}
+}
+
+// loadEthPrivateKey loads the key of the ethereum key file, generating it if the
+// file does not exist. Failures are wrapped in errEthSignerLoad.
+func loadEthPrivateKey(c *cli.Context, envConf *common.Config) (*ecdsa.PrivateKey, error) {
+	ethPrivKeyFile := envConf.EthKeyFileName()
+	if c.IsSet(flagEthPrivKey) {
+		ethPrivKeyFile = c.String(flagEthPrivKey)
+	}
+
+	devXMRMaker := c.Bool(flagDevXMRMaker)
+	devXMRTaker := c.Bool(flagDevXMRTaker)
+	if devXMRMaker && devXMRTaker {
+		return nil, errFlagsMutuallyExclusive(flagDevXMRMaker, flagDevXMRTaker)
+	}
+
+	keystorePassword, err := getEthKeystorePassword(c, ethPrivKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s): %w", errEthSignerLoad, ethPrivKeyFile, err)
+	}
+
+	ethPrivKey, err := cliutil.GetEthereumPrivateKey(
+		ethPrivKeyFile,
+		keystorePassword,
+		envConf.Env,
+		devXMRMaker,
+		devXMRTaker,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s): %w", errEthSignerLoad, ethPrivKeyFile, err)
+	}
+
+	return ethPrivKey, nil
+}
 
-		var err error
-		ethPrivKey, err = cliutil.GetEthereumPrivateKey(ethPrivKeyFile, env, devXMRMaker, devXMRTaker)
+// loadEthSigner loads the Ledger or Clef signer. Failures are wrapped in
+// errEthSignerLoad.
+func loadEthSigner(c *cli.Context, source ethSignerSource) (extethclient.Signer, error) {
+	if source == ethSignerLedger {
+		signer, err := extethclient.NewLedgerSigner(c.Context, c.String(flagLedgerPath))
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%w (Ledger): %w", errEthSignerLoad, err)
+		}
+		return signer, nil
+	}
+
+	var account *ethcommon.Address
+	if c.IsSet(flagEthSignerAccount) {
+		accountStr := c.String(flagEthSignerAccount)
+		if !ethcommon.IsHexAddress(accountStr) {
+			return nil, fmt.Errorf("invalid %q value %q", flagEthSignerAccount, accountStr)
 		}
+		addr := ethcommon.HexToAddress(accountStr)
+		account = &addr
 	}
 
-	extendedEC, err := extethclient.NewEthClient(c.Context, env, ethEndpoint, ethPrivKey)
+	signer, err := extethclient.NewClefSigner(c.Context, c.String(flagEthSignerEndpoint), account)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s): %w", errEthSignerLoad, c.String(flagEthSignerEndpoint), err)
+	}
+	return signer, nil
+}
+
+func createEthClient(c *cli.Context, envConf *common.Config, proxy *common.Proxy) (extethclient.EthClient, error) {
+	source, err := getEthSignerSource(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		extendedEC  extethclient.EthClient
+		ethPrivKey  *ecdsa.PrivateKey
+		ethEndpoint = getEthEndpoint(c)
+	)
+	switch source {
+	case ethSignerLedger, ethSignerClef:
+		var signer extethclient.Signer
+		signer, err = loadEthSigner(c, source)
+		if err != nil {
+			return nil, err
+		}
+		extendedEC, err = extethclient.NewEthClientWithSigner(c.Context, envConf.Env, ethEndpoint, signer, proxy)
+	case ethSignerKeyFile:
+		ethPrivKey, err = loadEthPrivateKey(c, envConf)
+		if err != nil {
+			return nil, err
+		}
+		fallthrough
+	default:
+		extendedEC, err = extethclient.NewEthClientWithProxy(c.Context, envConf.Env, ethEndpoint, ethPrivKey, proxy)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -478,11 +1308,20 @@ func createEthClient(c *cli.Context, envConf *common.Config) (extethclient.EthCl
 	return extendedEC, nil
 }
 
+// getEthEndpoint returns the endpoint of the ethereum node.
+func getEthEndpoint(c *cli.Context) string {
+	if c.String(flagEthEndpoint) != "" {
+		return c.String(flagEthEndpoint)
+	}
+	return common.DefaultEthEndpoint
+}
+
 func createSwapdConf(
 	c *cli.Context,
 	envConf *common.Config,
 	mc monero.WalletClient,
 	ec extethclient.EthClient,
+	proxy *common.Proxy,
 ) (*daemon.SwapdConfig, error) {
 
 	libp2pKeyFile := envConf.LibP2PKeyFile()
@@ -513,7 +1352,7 @@ func createSwapdConf(
 		}
 	}
 
-	return &daemon.SwapdConfig{
+	conf := &daemon.SwapdConfig{
 		EnvConf:        envConf,
 		Libp2pPort:     uint16(libp2pPort),
 		Libp2pKeyfile:  libp2pKeyFile,
@@ -522,6 +1361,337 @@ func createSwapdConf(
 		NoTransferBack: c.Bool(flagNoTransferBack),
 		MoneroClient:   mc,
 		EthereumClient: ec,
+
+		RelayAccessListFile: c.String(flagRelayAccessList),
+		RPCPublicAddress:    c.String(flagRPCPublic),
+		Proxy:               proxy,
+		PriceMaxStaleness:   c.Duration(flagPriceMaxStaleness),
+		PriceSource:         c.String(flagPriceSource),
+		NoPortMapping:       c.Bool(flagNoPortMap),
+		MDNS:                c.Bool(flagMDNS),
+		StaticPeers:         cliutil.ExpandBootnodes(c.StringSlice(flagStaticPeers)),
+		ListenAddrs:         cliutil.ExpandBootnodes(c.StringSlice(flagListenAddrs)),
+		AnnounceAddrs:       cliutil.ExpandBootnodes(c.StringSlice(flagAnnounceAddrs)),
+		ConnLimits: &net.ConnLimits{
+			LowWater:          c.Int(flagConnLowWater),
+			HighWater:         c.Int(flagConnHighWater),
+			MaxStreamsPerPeer: c.Int(flagMaxPeerStreams),
+			DialTimeout:       c.Duration(flagDialTimeout),
+		},
+	}
+
+	if err := pricefeed.ValidateSource(conf.PriceSource); err != nil {
+		return nil, fmt.Errorf("invalid %q value: %w", flagPriceSource, err)
+	}
+
+	pegMaxDeviation, err := readDecimalFlag(c, flagPegMaxDeviation)
+	if err != nil {
+		return nil, err
+	}
+	if err = xmrmaker.ValidatePegMaxDeviation(pegMaxDeviation); err != nil {
+		return nil, fmt.Errorf("invalid %q value: %w", flagPegMaxDeviation, err)
+	}
+	conf.PegMaxDeviation = pegMaxDeviation
+
+	if conf.MDNS && proxy != nil {
+		return nil, errFlagsMutuallyExclusive(flagMDNS, flagProxy)
+	}
+
+	if len(conf.ListenAddrs) > 0 {
+		if c.IsSet(flagLibp2pPort) {
+			return nil, errFlagsMutuallyExclusive(flagListenAddrs, flagLibp2pPort)
+		}
+		if proxy != nil {
+			return nil, errFlagsMutuallyExclusive(flagListenAddrs, flagProxy)
+		}
+	}
+
+	if len(conf.AnnounceAddrs) > 0 && proxy != nil {
+		return nil, errFlagsMutuallyExclusive(flagAnnounceAddrs, flagProxy)
+	}
+
+	if c.IsSet(flagTorControl) {
+		if c.String(flagTorControl) == "" {
+			return nil, errFlagValueEmpty(flagTorControl)
+		}
+		conf.TorControl = &net.TorControlConfig{
+			Address:  c.String(flagTorControl),
+			Password: c.String(flagTorControlPassword),
+		}
+	}
+
+	if err := setRPCTLSFiles(c, envConf, conf); err != nil {
+		return nil, err
+	}
+
+	if c.Bool(flagRPCUnix) || c.Bool(flagRPCNoTCP) {
+		conf.RPCUnixSocket = envConf.RPCUnixSocketFile()
+		conf.RPCUnixOnly = c.Bool(flagRPCNoTCP)
+	}
+
+	for _, hookURL := range c.StringSlice(flagWebhook) {
+		conf.Webhooks = append(conf.Webhooks, &webhook.Webhook{
+			URL:    hookURL,
+			Secret: c.String(flagWebhookSecret),
+		})
+	}
+
+	conf.RPCCORS = &rpc.CORSConfig{
+		AllowedOrigins: c.StringSlice(flagRPCOrigins),
+		AllowedMethods: c.StringSlice(flagRPCMethods),
+	}
+
+	if c.IsSet(flagRPCAuth) {
+		authTokens, err := rpc.ReadAuthTokensFile(c.String(flagRPCAuth))
+		if err != nil {
+			return nil, err
+		}
+		conf.RPCAuthTokens = authTokens
+	}
+
+	relayerFee, err := getRelayerFeeConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	conf.RelayerFee = relayerFee
+
+	conf.XMRPriority, err = monero.ParsePriority(c.String(flagMoneroPriority))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", flagMoneroPriority, err)
+	}
+
+	conf.DirectClaim = &backend.DirectClaimFallback{
+		Enabled:     !c.Bool(flagNoDirectClaim),
+		RelayMargin: c.Duration(flagClaimRelayMargin),
+	}
+	if conf.DirectClaim.RelayMargin < 0 {
+		return nil, fmt.Errorf("--%s cannot be negative", flagClaimRelayMargin)
+	}
+
+	if window := c.Duration(flagRelayerBatchWindow); window > 0 {
+		if !conf.IsRelayer {
+			return nil, fmt.Errorf("using flag %q requires the %q flag", flagRelayerBatchWindow, flagRelayer)
+		}
+		conf.RelayerBatch = &relayer.BatchConfig{
+			Window:  window,
+			MaxSize: c.Int(flagRelayerMaxBatchSize),
+		}
+	}
+
+	if c.IsSet(flagBundlerEndpoint) {
+		conf.UserOps, err = getUserOpConfig(c)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, flag := range []string{flagEntryPoint, flagClaimAccount} {
+			if c.IsSet(flag) {
+				return nil, fmt.Errorf("using flag %q requires the %q flag", flag, flagBundlerEndpoint)
+			}
+		}
+	}
+
+	if c.IsSet(flagTokenList) {
+		tokenList, err := coins.ReadTokenListFile(c.String(flagTokenList))
+		if err != nil {
+			return nil, err
+		}
+		conf.TokenList = tokenList
+	}
+
+	if c.Int(flagMarketMakerOffers) > 0 {
+		conf.MarketMaker, err = getMarketMakerConfig(c)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, flag := range []string{flagMarketMakerMinAmount, flagMarketMakerMaxAmount, flagMarketMakerRelayer} {
+			if c.IsSet(flag) {
+				return nil, fmt.Errorf("using flag %q requires the %q flag", flag, flagMarketMakerOffers)
+			}
+		}
+	}
+
+	// the key was loaded from a file, unless a hardware wallet or external signer is used
+	if ec.PrivateKey() != nil {
+		conf.EthKeyFile = envConf.EthKeyFileName()
+		if c.IsSet(flagEthPrivKey) {
+			conf.EthKeyFile = c.String(flagEthPrivKey)
+		}
+		conf.EthKeystorePassword = c.String(flagEthKeystorePassword)
+	}
+
+	return conf, nil
+}
+
+// setRPCTLSFiles sets the certificate and key files with which the RPC server
+// serves TLS, generating a self-signed certificate if requested.
+func setRPCTLSFiles(c *cli.Context, envConf *common.Config, conf *daemon.SwapdConfig) error {
+	certFile, keyFile := c.String(flagRPCTLSCert), c.String(flagRPCTLSKey)
+
+	if !c.Bool(flagRPCTLSAuto) && c.IsSet(flagRPCTLSHosts) {
+		return fmt.Errorf("using flag %q requires the %q flag", flagRPCTLSHosts, flagRPCTLSAuto)
+	}
+
+	if c.Bool(flagRPCTLSAuto) {
+		if certFile != "" || keyFile != "" {
+			return fmt.Errorf("flag %q cannot be combined with %q or %q", flagRPCTLSAuto, flagRPCTLSCert, flagRPCTLSKey)
+		}
+
+		extraHosts := c.StringSlice(flagRPCTLSHosts)
+		for _, host := range extraHosts {
+			if host == "" {
+				return errFlagValueEmpty(flagRPCTLSHosts)
+			}
+		}
+
+		certFile, keyFile = envConf.RPCTLSCertFile(), envConf.RPCTLSKeyFile()
+		if err := rpc.EnsureSelfSignedCert(certFile, keyFile, extraHosts); err != nil {
+			return fmt.Errorf("failed to generate RPC TLS certificate: %w", err)
+		}
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("flags %q and %q must be used together", flagRPCTLSCert, flagRPCTLSKey)
+	}
+
+	conf.RPCTLSCertFile = certFile
+	conf.RPCTLSKeyFile = keyFile
+	return nil
+}
+
+func getRelayerFeeConfig(c *cli.Context) (*relayer.FeeConfig, error) {
+	feeBPS := c.Uint(flagRelayerFeeBPS)
+	if feeBPS > relayer.MaxFeeBasisPoints {
+		return nil, fmt.Errorf("--%s cannot exceed %d", flagRelayerFeeBPS, relayer.MaxFeeBasisPoints)
+	}
+
+	minFee, err := cliutil.ReadUnsignedDecimalFlag(c, flagRelayerMinFee)
+	if err != nil {
+		return nil, err
+	}
+
+	maxFee, err := cliutil.ReadUnsignedDecimalFlag(c, flagRelayerMaxFee)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenRates, err := getRelayerTokenFeeRates(c)
+	if err != nil {
+		return nil, err
+	}
+
+	feeConfig := &relayer.FeeConfig{
+		BasisPoints: uint32(feeBPS),
+		MinWei:      coins.EtherToWei(minFee).BigInt(),
+		MaxWei:      coins.EtherToWei(maxFee).BigInt(),
+		TokenRates:  tokenRates,
+	}
+	if err = feeConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	return feeConfig, nil
+}
+
+func getRelayerTokenFeeRates(c *cli.Context) (relayer.TokenFeeRates, error) {
+	rates := make(relayer.TokenFeeRates)
+
+	for _, flagVal := range c.StringSlice(flagRelayerTokenFeeRate) {
+		for _, rateStr := range strings.Split(flagVal, ",") {
+			addrStr, tokensPerETHStr, found := strings.Cut(strings.TrimSpace(rateStr), "=")
+			if !found || !ethcommon.IsHexAddress(addrStr) {
+				return nil, fmt.Errorf("invalid --%s value %q", flagRelayerTokenFeeRate, rateStr)
+			}
+
+			tokensPerETH, _, err := new(apd.Decimal).SetString(tokensPerETHStr)
+			if err != nil || tokensPerETH.Sign() <= 0 {
+				return nil, fmt.Errorf("invalid --%s rate %q", flagRelayerTokenFeeRate, tokensPerETHStr)
+			}
+
+			rates[ethcommon.HexToAddress(addrStr)] = tokensPerETH
+		}
+	}
+
+	return rates, nil
+}
+
+func getMarketMakerConfig(c *cli.Context) (*xmrmaker.MarketMakerConfig, error) {
+	minAmount, err := cliutil.ReadUnsignedDecimalFlag(c, flagMarketMakerMinAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAmount, err := cliutil.ReadUnsignedDecimalFlag(c, flagMarketMakerMaxAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	spread, err := readDecimalFlag(c, flagMarketMakerSpread)
+	if err != nil {
+		return nil, err
+	}
+
+	xmrReserve, err := cliutil.ReadUnsignedDecimalFlag(c, flagMarketMakerXMRReserve)
+	if err != nil {
+		return nil, err
+	}
+
+	minETHBalance, err := readDecimalFlag(c, flagMarketMakerMinETH)
+	if err != nil {
+		return nil, err
+	}
+
+	mmConf := &xmrmaker.MarketMakerConfig{
+		Offers:          c.Int(flagMarketMakerOffers),
+		MinAmount:       minAmount,
+		MaxAmount:       maxAmount,
+		Spread:          spread,
+		PriceSource:     c.String(flagMarketMakerPriceSource),
+		RepriceInterval: c.Duration(flagMarketMakerInterval),
+		XMRReserve:      xmrReserve,
+		MinETHBalance:   minETHBalance,
+		UseRelayer:      c.Bool(flagMarketMakerRelayer),
+	}
+	if err = mmConf.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid market maker configuration: %w", err)
+	}
+
+	return mmConf, nil
+}
+
+// readDecimalFlag reads the decimal value of a flag that can be zero.
+func readDecimalFlag(c *cli.Context, flagName string) (*apd.Decimal, error) {
+	s := c.String(flagName)
+	value, _, err := new(apd.Decimal).SetString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for flag --%s", s, flagName)
+	}
+	return value, nil
+}
+
+func getUserOpConfig(c *cli.Context) (*daemon.UserOpConfig, error) {
+	bundlerEndpoint := c.String(flagBundlerEndpoint)
+	if bundlerEndpoint == "" {
+		return nil, errFlagValueEmpty(flagBundlerEndpoint)
+	}
+
+	addrs := make(map[string]ethcommon.Address)
+	for _, flag := range []string{flagEntryPoint, flagClaimAccount} {
+		addrStr := c.String(flag)
+		if addrStr == "" {
+			return nil, errFlagValueEmpty(flag)
+		}
+		if !ethcommon.IsHexAddress(addrStr) {
+			return nil, fmt.Errorf("invalid %q value %q", flag, addrStr)
+		}
+		addrs[flag] = ethcommon.HexToAddress(addrStr)
+	}
+
+	return &daemon.UserOpConfig{
+		BundlerEndpoint: bundlerEndpoint,
+		EntryPoint:      addrs[flagEntryPoint],
+		ClaimAccount:    addrs[flagClaimAccount],
 	}, nil
 }
 
@@ -5,11 +5,10 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"path"
-	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,8 +17,10 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
 	"golang.org/x/sys/unix"
 
+	"github.com/athanorlabs/atomic-swap/cliutil"
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
@@ -93,26 +94,24 @@ func TestDaemon_DevXMRTaker(t *testing.T) {
 	// deployed a forwarder. At some future point, we will ask the RPC endpoint
 	// what the contract addresses are instead of using this file.
 	//
-	data, err := os.ReadFile(path.Join(dataDir, contractAddressesFile))
+	addresses, err := cliutil.ReadContractAddressesFromFile(
+		path.Join(dataDir, common.DefaultContractAddressesFileName),
+	)
 	require.NoError(t, err)
-	m := make(map[string]string)
-	require.NoError(t, json.Unmarshal(data, &m))
-	swapCreatorAddr, ok := m["swapCreatorAddr"]
-	require.True(t, ok)
-	forwarderAddr, ok := m["forwarderAddr"]
-	require.True(t, ok)
+	require.NotZero(t, addresses.SwapCreatorDeployBlock)
 
 	ec, _ := tests.NewEthClient(t)
 	ecCtx := context.Background()
 	discoveredForwarderAddr, err :=
-		contracts.CheckSwapCreatorContractCode(ecCtx, ec, ethcommon.HexToAddress(swapCreatorAddr))
+		contracts.CheckSwapCreatorContractCode(ecCtx, ec, addresses.SwapCreatorAddr)
 	require.NoError(t, err)
-	require.Equal(t, strings.ToLower(discoveredForwarderAddr.Hex()), forwarderAddr)
+	require.Equal(t, discoveredForwarderAddr, addresses.ForwarderAddr)
 
 	// something is seriously wrong if this next check fails, as CheckSwapCreatorContractCode
 	// should have already validated the forwarder bytecode
-	err = contracts.CheckForwarderContractCode(ecCtx, ec, ethcommon.HexToAddress(forwarderAddr))
+	version, err := contracts.CheckForwarderContractCode(ecCtx, ec, addresses.ForwarderAddr)
 	require.NoError(t, err)
+	require.Equal(t, contracts.ForwarderV2, version)
 }
 
 func TestDaemon_DevXMRMaker(t *testing.T) {
@@ -244,6 +243,151 @@ func TestDaemon_BadFlags(t *testing.T) {
 	}
 }
 
+func Test_parseRestoreDate(t *testing.T) {
+	date, err := parseRestoreDate("2023-04-01")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC), date)
+
+	_, err = parseRestoreDate("04/01/2023")
+	require.ErrorContains(t, err, fmt.Sprintf(`invalid %q value`, flagMoneroRestoreDate))
+
+	tomorrow := time.Now().Add(24 * time.Hour).Format(time.DateOnly)
+	_, err = parseRestoreDate(tomorrow)
+	require.ErrorContains(t, err, "is in the future")
+}
+
+func Test_useContractAddressesFile(t *testing.T) {
+	addresses := &cliutil.ContractAddresses{
+		SwapCreatorAddr:        ethcommon.Address{0x1},
+		ForwarderAddr:          ethcommon.Address{0x2},
+		SwapCreatorDeployBlock: 100,
+	}
+
+	// the file is used on development and test networks
+	for _, env := range []common.Environment{common.Development, common.Stagenet} {
+		conf := common.ConfigDefaultsForEnv(env)
+		conf.DataDir = t.TempDir()
+		require.NoError(t, useContractAddressesFile(conf)) // no file yet
+
+		require.NoError(t, cliutil.WriteContractAddressesToFile(conf.ContractAddressesFile(), addresses))
+		require.NoError(t, useContractAddressesFile(conf))
+		require.Equal(t, addresses.SwapCreatorAddr, conf.SwapCreatorAddr)
+		require.Equal(t, addresses.SwapCreatorDeployBlock, conf.SwapCreatorDeployBlock)
+	}
+
+	// the hard-coded mainnet contract is not overridden by the file
+	conf := common.ConfigDefaultsForEnv(common.Mainnet)
+	conf.DataDir = t.TempDir()
+	mainnetAddr := conf.SwapCreatorAddr
+	require.NoError(t, cliutil.WriteContractAddressesToFile(conf.ContractAddressesFile(), addresses))
+	require.NoError(t, useContractAddressesFile(conf))
+	require.Equal(t, mainnetAddr, conf.SwapCreatorAddr)
+}
+
+// newCLIContext returns the context of swapd's command line with the given flags,
+// without running the daemon.
+func newCLIContext(t *testing.T, flags ...string) *cli.Context {
+	app := cliApp()
+	set := flag.NewFlagSet("testSwapd", flag.ContinueOnError)
+	for _, f := range app.Flags {
+		require.NoError(t, f.Apply(set))
+	}
+	require.NoError(t, set.Parse(flags))
+	return cli.NewContext(app, set, nil)
+}
+
+func Test_getEthSignerSource(t *testing.T) {
+	type testCase struct {
+		flags    []string
+		expected ethSignerSource
+	}
+
+	testCases := []testCase{
+		{nil, ethSignerKeyFile},
+		{[]string{"--" + flagEthPrivKey, "eth.key"}, ethSignerKeyFile},
+		{[]string{"--" + flagUseExternalSigner}, ethSignerExternal},
+		{[]string{"--" + flagUseLedger}, ethSignerLedger},
+		{[]string{"--" + flagUseLedger, "--" + flagLedgerPath, "m/44'/60'/0'/0/1"}, ethSignerLedger},
+		{[]string{"--" + flagEthSignerEndpoint, "/tmp/clef.ipc"}, ethSignerClef},
+		{[]string{"--" + flagEthSignerEndpoint, "/tmp/clef.ipc", "--" + flagEthSignerAccount, "0x01"}, ethSignerClef},
+	}
+
+	for _, tc := range testCases {
+		source, err := getEthSignerSource(newCLIContext(t, tc.flags...))
+		require.NoError(t, err, tc.flags)
+		require.Equal(t, tc.expected, source, tc.flags)
+	}
+}
+
+func Test_getEthSignerSource_errors(t *testing.T) {
+	type testCase struct {
+		flags     []string
+		expectErr string
+	}
+
+	testCases := []testCase{
+		{
+			[]string{"--" + flagUseExternalSigner, "--" + flagEthPrivKey, "eth.key"},
+			errFlagsMutuallyExclusive(flagUseExternalSigner, flagEthPrivKey).Error(),
+		},
+		{
+			[]string{"--" + flagUseLedger, "--" + flagUseExternalSigner},
+			errFlagsMutuallyExclusive(flagUseLedger, flagUseExternalSigner).Error(),
+		},
+		{
+			[]string{"--" + flagLedgerPath, "m/44'/60'/0'/0/1"},
+			fmt.Sprintf("using flag %q requires the %q flag", flagLedgerPath, flagUseLedger),
+		},
+		{
+			[]string{"--" + flagEthSignerEndpoint, "/tmp/clef.ipc", "--" + flagUseLedger},
+			errFlagsMutuallyExclusive(flagEthSignerEndpoint, flagUseLedger).Error(),
+		},
+		{
+			[]string{"--" + flagEthSignerEndpoint, ""},
+			errFlagValueEmpty(flagEthSignerEndpoint).Error(),
+		},
+		{
+			[]string{"--" + flagEthSignerAccount, "0x01"},
+			fmt.Sprintf("using flag %q requires the %q flag", flagEthSignerAccount, flagEthSignerEndpoint),
+		},
+		{
+			[]string{"--" + flagEthKeystorePassword, "pw", "--" + flagEthKeystorePasswordFile, "pw.txt"},
+			errFlagsMutuallyExclusive(flagEthKeystorePassword, flagEthKeystorePasswordFile).Error(),
+		},
+		{
+			[]string{"--" + flagEthPrivKey, ""},
+			errFlagValueEmpty(flagEthPrivKey).Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := getEthSignerSource(newCLIContext(t, tc.flags...))
+		require.ErrorContains(t, err, tc.expectErr, tc.flags)
+	}
+}
+
+func Test_createEthClient_signerLoadFails(t *testing.T) {
+	envConf := common.ConfigDefaultsForEnv(common.Development)
+	envConf.DataDir = t.TempDir()
+
+	// an encrypted keystore without its password
+	keyFile := path.Join(t.TempDir(), "eth.key")
+	require.NoError(t, cliutil.WriteEthKeystoreFile(keyFile, tests.GetMakerTestKey(t), "hunter2"))
+	c := newCLIContext(t, "--"+flagEthPrivKey, keyFile)
+	_, err := createEthClient(c, envConf, nil)
+	require.ErrorIs(t, err, errEthSignerLoad)
+
+	// a password file that does not exist
+	c = newCLIContext(t, "--"+flagEthPrivKey, keyFile, "--"+flagEthKeystorePasswordFile, path.Join(t.TempDir(), "pw"))
+	_, err = createEthClient(c, envConf, nil)
+	require.ErrorIs(t, err, errEthSignerLoad)
+
+	// a Clef instance that is not running
+	c = newCLIContext(t, "--"+flagEthSignerEndpoint, path.Join(t.TempDir(), "clef.ipc"))
+	_, err = createEthClient(c, envConf, nil)
+	require.ErrorIs(t, err, errEthSignerLoad)
+}
+
 func TestDaemon_PersistOffers(t *testing.T) {
 	dataDir := t.TempDir()
 	walletDir := path.Join(dataDir, "wallet")
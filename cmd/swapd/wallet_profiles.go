@@ -0,0 +1,126 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/athanorlabs/atomic-swap/cliutil"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+)
+
+// createProfiles opens the monero wallet and ethereum key of each profile of
+// --wallet-profiles, creating them in the profile's directory on its first use. The
+// profiles' wallets use the password and monerod nodes of the default wallet.
+func createProfiles(
+	c *cli.Context,
+	envConf *common.Config,
+	walletConf *monero.WalletClientConf,
+	proxy *common.Proxy,
+) (_ []*backend.Profile, err error) {
+	names := c.StringSlice(flagWalletProfiles)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	// the profiles' wallets and keys are always our own files
+	for _, flag := range []string{flagMoneroWalletRPCURL, flagMoneroViewKey} {
+		if c.IsSet(flag) {
+			return nil, errFlagsMutuallyExclusive(flag, flagWalletProfiles)
+		}
+	}
+	for _, flag := range []string{flagUseExternalSigner, flagUseLedger, flagEthSignerEndpoint} {
+		if c.IsSet(flag) {
+			return nil, errFlagsMutuallyExclusive(flag, flagWalletProfiles)
+		}
+	}
+
+	var profiles []*backend.Profile
+	defer func() {
+		if err != nil {
+			closeProfiles(profiles)
+		}
+	}()
+
+	for _, name := range names {
+		if name == "" {
+			return nil, errFlagValueEmpty(flagWalletProfiles)
+		}
+		if err = backend.ValidateProfileName(name); err != nil {
+			return nil, err
+		}
+
+		var p *backend.Profile
+		p, err = createProfile(c, envConf, walletConf, proxy, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open profile %q: %w", name, err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+func createProfile(
+	c *cli.Context,
+	envConf *common.Config,
+	walletConf *monero.WalletClientConf,
+	proxy *common.Proxy,
+	name string,
+) (*backend.Profile, error) {
+	profileDir := envConf.ProfileDir(name)
+
+	ethPrivKeyFile := path.Join(profileDir, common.DefaultEthKeyFileName)
+	keystorePassword, err := getEthKeystorePassword(c, ethPrivKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ethPrivKey, err := cliutil.GetEthereumPrivateKey(
+		ethPrivKeyFile,
+		keystorePassword,
+		envConf.Env,
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ec, err := extethclient.NewEthClientWithProxy(c.Context, envConf.Env, getEthEndpoint(c), ethPrivKey, proxy)
+	if err != nil {
+		return nil, err
+	}
+	ec.SetGasPrice(uint64(c.Uint(flagGasPrice)))
+	ec.SetGasLimit(uint64(c.Uint(flagGasLimit)))
+
+	profileWalletConf := *walletConf
+	profileWalletConf.WalletFilePath = path.Join(profileDir, "wallet", common.DefaultMoneroWalletName)
+	profileWalletConf.WalletPort = 0 // the default wallet uses --wallet-port
+	mc, err := monero.NewWalletClient(&profileWalletConf)
+	if err != nil {
+		ec.Close()
+		return nil, err
+	}
+
+	return &backend.Profile{
+		Name:      name,
+		XMRClient: mc,
+		ETHClient: ec,
+	}, nil
+}
+
+// closeProfiles closes the wallets and ethereum clients of the profiles.
+func closeProfiles(profiles []*backend.Profile) {
+	for _, p := range profiles {
+		p.XMRClient.Close()
+		p.ETHClient.Close()
+	}
+}
@@ -10,6 +10,11 @@ import (
 var (
 	errNegativePiconeros = errors.New("negative piconero values are not supported")
 	errNegativeWei       = errors.New("negative Wei values are not supported")
+	errInexactConversion = errors.New("conversion exceeds the decimal precision")
+	errInvalidRounding   = errors.New("invalid rounding mode")
 	// ErrInvalidCoin is generated when a ProvidesCoin type has an invalid string
 	ErrInvalidCoin = errors.New("invalid ProvidesCoin")
+	// ErrAmountOverflow is returned when a converted amount doesn't fit in the
+	// integer type of its smallest units on chain
+	ErrAmountOverflow = errors.New("converted amount overflows its on-chain integer type")
 )
@@ -4,9 +4,26 @@
 package coins
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/cockroachdb/apd/v3"
 )
 
+var (
+	// maxMoneroAmount is the largest XMR amount whose piconeros fit in a uint64, the
+	// integer type of amounts in Monero transactions
+	maxMoneroAmount = NewPiconeroAmount(math.MaxUint64).AsMonero()
+
+	// maxUint256 is the largest amount of wei or token units in a uint256, the
+	// integer type of amounts in the swap contract
+	maxUint256 = func() *apd.Decimal {
+		d := new(apd.Decimal)
+		d.Coeff.Sub(d.Coeff.Lsh(apd.NewBigInt(1), 256), apd.NewBigInt(1))
+		return d
+	}()
+)
+
 // ExchangeRate defines an exchange rate between ETH and XMR.
 // It is defined as the ratio of ETH:XMR that the node wishes to provide.
 // ie. an ExchangeRate of 0.1 means that the node considers 1 ETH = 10 XMR.
@@ -27,6 +44,32 @@ func CalcExchangeRate(xmrPrice *apd.Decimal, ethPrice *apd.Decimal) (*ExchangeRa
 	return ToExchangeRate(rate), nil
 }
 
+// WithMarkup returns the exchange rate increased by the percent, which can be
+// negative, rounded to MaxExchangeRateDecimals.
+func (r *ExchangeRate) WithMarkup(percent *apd.Decimal) (*ExchangeRate, error) {
+	factor := new(apd.Decimal)
+	_, err := decimalCtx.Add(factor, apd.New(100, 0), percent)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := new(apd.Decimal)
+	if _, err = decimalCtx.Mul(rate, r.Decimal(), factor); err != nil {
+		return nil, err
+	}
+	if _, err = decimalCtx.Quo(rate, rate, apd.New(100, 0)); err != nil {
+		return nil, err
+	}
+	if err = roundToDecimalPlace(rate, rate, MaxExchangeRateDecimals); err != nil {
+		return nil, err
+	}
+	if err = ValidatePositive("exchangeRate", MaxExchangeRateDecimals, rate); err != nil {
+		return nil, err
+	}
+
+	return ToExchangeRate(rate), nil
+}
+
 // ToExchangeRate casts an *apd.Decimal to *ExchangeRate
 func ToExchangeRate(rate *apd.Decimal) *ExchangeRate {
 	return (*ExchangeRate)(rate)
@@ -51,23 +94,59 @@ func (r *ExchangeRate) MarshalText() ([]byte, error) {
 	return r.Decimal().MarshalText()
 }
 
-// ToXMR converts an ETH amount to an XMR amount with the given exchange rate
+// ToXMR converts an ETH amount to an XMR amount with the given exchange rate,
+// rounding half up to whole piconeros.
 func (r *ExchangeRate) ToXMR(ethAmount *apd.Decimal) (*apd.Decimal, error) {
-	xmrAmt := new(apd.Decimal)
-	_, err := decimalCtx.Quo(xmrAmt, ethAmount, r.Decimal())
+	return r.ToXMRRounded(ethAmount, apd.RoundHalfUp)
+}
+
+// ToXMRRounded converts an ETH or token amount in standard units to an XMR amount
+// with the given exchange rate, rounding to whole piconeros with the rounding mode.
+// It errors if the XMR amount doesn't fit in a uint64 of piconeros.
+//
+// An XMR amount converted with ToETHRounded and back with ToXMR is the same amount,
+// as ToETHRounded doesn't round. An XMR amount converted with ToERC20AmountRounded
+// rounding up (apd.RoundCeiling) converts back to at least the amount, and rounding
+// down (apd.RoundFloor) converts back to at most the amount.
+func (r *ExchangeRate) ToXMRRounded(ethAmount *apd.Decimal, rounding apd.Rounder) (*apd.Decimal, error) {
+	ctx, err := roundingCtx(rounding)
 	if err != nil {
 		return nil, err
 	}
-	if err = roundToDecimalPlace(xmrAmt, xmrAmt, NumMoneroDecimals); err != nil {
+
+	// the quotient is rounded in the same direction as the piconeros, so that
+	// directed rounding modes never round twice in opposite directions
+	xmrAmt := new(apd.Decimal)
+	if _, err = ctx.Quo(xmrAmt, ethAmount, r.Decimal()); err != nil {
+		return nil, err
+	}
+	if err = roundToDecimalPlaceWith(ctx, xmrAmt, xmrAmt, NumMoneroDecimals); err != nil {
 		return nil, err
 	}
+
+	if xmrAmt.Cmp(maxMoneroAmount) > 0 {
+		return nil, fmt.Errorf("%w: %s XMR", ErrAmountOverflow, xmrAmt.Text('f'))
+	}
+
 	return xmrAmt, nil
 }
 
 // ToETH converts an XMR amount to an ETH amount with the given exchange rate
 func (r *ExchangeRate) ToETH(xmrAmount *apd.Decimal) (*apd.Decimal, error) {
-	ethAmt := new(apd.Decimal)
-	_, err := decimalCtx.Mul(ethAmt, r.Decimal(), xmrAmount)
+	return r.ToETHRounded(xmrAmount, apd.RoundHalfUp)
+}
+
+// ToETHRounded converts an XMR amount to an ETH amount with the given exchange rate,
+// rounding to whole wei with the rounding mode. It errors if the product of the
+// amount and the exchange rate is inexact, or if the ETH amount doesn't fit in a
+// uint256 of wei.
+func (r *ExchangeRate) ToETHRounded(xmrAmount *apd.Decimal, rounding apd.Rounder) (*apd.Decimal, error) {
+	ethAmt, err := r.mulChecked(xmrAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := roundingCtx(rounding)
 	if err != nil {
 		return nil, err
 	}
@@ -75,24 +154,67 @@ func (r *ExchangeRate) ToETH(xmrAmount *apd.Decimal) (*apd.Decimal, error) {
 	// Assuming the xmrAmount was capped at 12 decimal places and the exchange
 	// rate was capped at 6 decimal places, you can't generate more than 18
 	// decimal places below, so no rounding occurs.
-	if err = roundToDecimalPlace(ethAmt, ethAmt, NumEtherDecimals); err != nil {
+	if err = roundToDecimalPlaceWith(ctx, ethAmt, ethAmt, NumEtherDecimals); err != nil {
 		return nil, err
 	}
+
+	if EtherToWei(ethAmt).Decimal().Cmp(maxUint256) > 0 {
+		return nil, fmt.Errorf("%w: %s ETH", ErrAmountOverflow, ethAmt.Text('f'))
+	}
+
 	return ethAmt, nil
 }
 
 // ToERC20Amount converts an XMR amount to a token amount in standard units with
 // the given exchange rate
 func (r *ExchangeRate) ToERC20Amount(xmrAmount *apd.Decimal, token *ERC20TokenInfo) (*apd.Decimal, error) {
-	erc20Amount := new(apd.Decimal)
-	_, err := decimalCtx.Mul(erc20Amount, r.Decimal(), xmrAmount)
+	return r.ToERC20AmountRounded(xmrAmount, token, apd.RoundHalfUp)
+}
+
+// ToERC20AmountRounded converts an XMR amount to a token amount in standard units
+// with the given exchange rate, rounding to whole token units with the rounding
+// mode. Tokens with fewer decimals than ETH usually round, so the minimum amount
+// that takers provide for an offer is rounded up (apd.RoundCeiling) and the maximum
+// amount is rounded down (apd.RoundFloor), otherwise the maker rejects the take.
+// It errors if the token amount doesn't fit in a uint256 of token units.
+func (r *ExchangeRate) ToERC20AmountRounded(
+	xmrAmount *apd.Decimal,
+	token *ERC20TokenInfo,
+	rounding apd.Rounder,
+) (*apd.Decimal, error) {
+	erc20Amount, err := r.mulChecked(xmrAmount)
 	if err != nil {
 		return nil, err
 	}
 
-	// The token, if required, will get rounded to whole token units in
-	// NewERC20TokenAmountFromDecimals.
-	return NewERC20TokenAmountFromDecimals(erc20Amount, token).AsStandard(), nil
+	ctx, err := roundingCtx(rounding)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = roundToDecimalPlaceWith(ctx, erc20Amount, erc20Amount, token.NumDecimals); err != nil {
+		return nil, err
+	}
+
+	if NewERC20TokenAmountFromDecimals(erc20Amount, token).Amount.Cmp(maxUint256) > 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrAmountOverflow, erc20Amount.Text('f'), token.SanitizedSymbol())
+	}
+
+	return erc20Amount, nil
+}
+
+// mulChecked returns the product of the XMR amount and the exchange rate, erroring
+// if the product was rounded to the precision of our decimal context.
+func (r *ExchangeRate) mulChecked(xmrAmount *apd.Decimal) (*apd.Decimal, error) {
+	product := new(apd.Decimal)
+	cond, err := decimalCtx.Mul(product, r.Decimal(), xmrAmount)
+	if err != nil {
+		return nil, err
+	}
+	if cond.Inexact() {
+		return nil, fmt.Errorf("%w: %s XMR at exchange rate %s", errInexactConversion, xmrAmount.Text('f'), r)
+	}
+	return product, nil
 }
 
 func (r *ExchangeRate) String() string {
@@ -4,7 +4,10 @@
 package coins
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"testing/quick"
 
 	"github.com/cockroachdb/apd/v3"
 	"github.com/stretchr/testify/assert"
@@ -122,3 +125,169 @@ func TestCalcExchangeRate_fail(t *testing.T) {
 	_, err := CalcExchangeRate(xmrPrice, ethPrice)
 	require.ErrorContains(t, err, "division by zero")
 }
+
+func TestExchangeRate_WithMarkup(t *testing.T) {
+	rate := StrToExchangeRate("0.075")
+
+	marked, err := rate.WithMarkup(StrToDecimal("2"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.0765", marked.String())
+
+	marked, err = rate.WithMarkup(StrToDecimal("-1.5"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.073875", marked.String())
+
+	// rounded to MaxExchangeRateDecimals
+	marked, err = rate.WithMarkup(StrToDecimal("0.001"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.075001", marked.String())
+
+	_, err = rate.WithMarkup(StrToDecimal("-100"))
+	require.ErrorContains(t, err, "must be non-zero")
+}
+
+func TestExchangeRate_ToERC20AmountRounded_takeRange(t *testing.T) {
+	// A taker providing the half-up rounded token amount of the offer's minimum
+	// would be rejected by the maker, as it converts back to less XMR than the
+	// minimum. Rounding up converts back to at least the minimum.
+	rate := StrToExchangeRate("0.333333")
+	minAmount := StrToDecimal("1.0000015")
+	erc20Info := &ERC20TokenInfo{NumDecimals: 6}
+
+	halfUp, err := rate.ToERC20Amount(minAmount, erc20Info)
+	require.NoError(t, err)
+	xmrAmount, err := rate.ToXMR(halfUp)
+	require.NoError(t, err)
+	assert.Equal(t, -1, xmrAmount.Cmp(minAmount))
+
+	minTake, err := rate.ToERC20AmountRounded(minAmount, erc20Info, apd.RoundCeiling)
+	require.NoError(t, err)
+	assert.Equal(t, "0.333334", minTake.Text('f'))
+	xmrAmount, err = rate.ToXMR(minTake)
+	require.NoError(t, err)
+	assert.Equal(t, "1.000003000003", xmrAmount.Text('f'))
+
+	maxTake, err := rate.ToERC20AmountRounded(StrToDecimal("1.000001501"), erc20Info, apd.RoundFloor)
+	require.NoError(t, err)
+	assert.Equal(t, "0.333333", maxTake.Text('f'))
+}
+
+func TestExchangeRate_ToXMRRounded(t *testing.T) {
+	rate := StrToExchangeRate("0.333333")
+	ethAmount := StrToDecimal("3.1") // 9.3000093000093...
+
+	xmrAmount, err := rate.ToXMRRounded(ethAmount, apd.RoundCeiling)
+	require.NoError(t, err)
+	assert.Equal(t, "9.30000930001", xmrAmount.String())
+
+	xmrAmount, err = rate.ToXMRRounded(ethAmount, apd.RoundFloor)
+	require.NoError(t, err)
+	assert.Equal(t, "9.300009300009", xmrAmount.String())
+
+	_, err = rate.ToXMRRounded(ethAmount, "sideways")
+	require.ErrorIs(t, err, errInvalidRounding)
+}
+
+func TestExchangeRate_overflow(t *testing.T) {
+	rate := StrToExchangeRate("0.000001")
+
+	// the largest XMR amount of a uint64 of piconeros converts
+	maxETH, err := rate.ToETH(maxMoneroAmount)
+	require.NoError(t, err)
+	xmrAmount, err := rate.ToXMR(maxETH)
+	require.NoError(t, err)
+	assert.Equal(t, "18446744.073709551615", xmrAmount.Text('f'))
+
+	_, err = rate.ToXMR(StrToDecimal("18.446745"))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	rate = StrToExchangeRate("1000000")
+	_, err = rate.ToETH(apd.New(1, 60))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = rate.ToERC20Amount(apd.New(1, 60), &ERC20TokenInfo{NumDecimals: 18})
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	// products beyond the precision of our decimal context aren't rounded silently
+	manyDigits := StrToDecimal(strings.Repeat("9", MaxCoinPrecision-10) + ".999999999999")
+	_, err = StrToExchangeRate("0.333333").ToETH(manyDigits)
+	require.ErrorIs(t, err, errInexactConversion)
+}
+
+// newQuickExchangeRate returns a positive exchange rate with up to
+// MaxExchangeRateDecimals decimals from a random number.
+func newQuickExchangeRate(n uint64) *ExchangeRate {
+	return ToExchangeRate(apd.New(int64(n%1e12)+1, -MaxExchangeRateDecimals))
+}
+
+func TestExchangeRate_roundTrip_ETH(t *testing.T) {
+	// ToETH doesn't round, so every XMR amount converts back to itself
+	roundTrip := func(rateN uint64, piconeros uint64) bool {
+		rate := newQuickExchangeRate(rateN)
+		xmrAmount := NewPiconeroAmount(piconeros).AsMonero()
+
+		ethAmount, err := rate.ToETH(xmrAmount)
+		require.NoError(t, err)
+		converted, err := rate.ToXMR(ethAmount)
+		require.NoError(t, err)
+
+		return converted.Cmp(xmrAmount) == 0
+	}
+	require.NoError(t, quick.Check(roundTrip, nil))
+}
+
+func TestExchangeRate_roundTrip_ERC20(t *testing.T) {
+	// the take range of an offer is rounded inwards, so it converts back to XMR
+	// amounts within the offer's range
+	roundTrip := func(rateN uint64, piconeros uint64, decimals uint8) bool {
+		rate := newQuickExchangeRate(rateN)
+		xmrAmount := NewPiconeroAmount(piconeros).AsMonero()
+		token := &ERC20TokenInfo{NumDecimals: decimals % (NumEtherDecimals + 1)}
+
+		minTake, err := rate.ToERC20AmountRounded(xmrAmount, token, apd.RoundCeiling)
+		require.NoError(t, err)
+		maxTake, err := rate.ToERC20AmountRounded(xmrAmount, token, apd.RoundFloor)
+		require.NoError(t, err)
+
+		// ToXMR only overflows if the rounding up of the token amount exceeds the
+		// largest amount of piconeros
+		minXMR, err := rate.ToXMR(minTake)
+		if errors.Is(err, ErrAmountOverflow) {
+			return true
+		}
+		require.NoError(t, err)
+		maxXMR, err := rate.ToXMR(maxTake)
+		require.NoError(t, err)
+
+		return minXMR.Cmp(xmrAmount) >= 0 && maxXMR.Cmp(xmrAmount) <= 0
+	}
+	require.NoError(t, quick.Check(roundTrip, nil))
+}
+
+func TestExchangeRate_roundTrip_XMR(t *testing.T) {
+	// rounding the XMR amount down never converts back to more ETH, and rounding
+	// it up never converts back to less
+	roundTrip := func(rateN uint64, wei int64) bool {
+		rate := newQuickExchangeRate(rateN)
+		if wei < 0 {
+			wei = -wei
+		}
+		ethAmount := apd.New(wei, -NumEtherDecimals)
+
+		floorXMR, err := rate.ToXMRRounded(ethAmount, apd.RoundFloor)
+		require.NoError(t, err)
+		halfUpXMR, err := rate.ToXMR(ethAmount)
+		require.NoError(t, err)
+		ceilXMR, err := rate.ToXMRRounded(ethAmount, apd.RoundCeiling)
+		require.NoError(t, err)
+
+		floorETH, err := rate.ToETH(floorXMR)
+		require.NoError(t, err)
+		ceilETH, err := rate.ToETH(ceilXMR)
+		require.NoError(t, err)
+
+		return floorXMR.Cmp(halfUpXMR) <= 0 && halfUpXMR.Cmp(ceilXMR) <= 0 &&
+			floorETH.Cmp(ethAmount) <= 0 && ceilETH.Cmp(ethAmount) >= 0
+	}
+	require.NoError(t, quick.Check(roundTrip, nil))
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package coins
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/apd/v3"
+)
+
+const (
+	gweiDecimals = 9
+
+	// units of the amounts that are converted to XMR or ETH when parsed
+	unitPiconero = "piconero"
+	unitWei      = "wei"
+	unitGwei     = "gwei"
+)
+
+var (
+	// amountNumberRegex matches the number of an amount, which is parsed the same
+	// regardless of the locale: a period is the only decimal separator, and digit
+	// grouping, signs and exponents are not allowed.
+	amountNumberRegex = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+	// maxETHAmount is an ETH amount several times the ETH supply, larger amounts
+	// are likely wei amounts that were meant to be ETH amounts
+	maxETHAmount = apd.New(1, 9)
+
+	errAmountEmpty = errors.New("amount is empty")
+)
+
+// ParseAmount parses a human-friendly amount of a number and an optional unit,
+// like "0.5 ETH", "1.2 XMR", "20 gwei" or "250 USDT". Amounts in piconero, wei or
+// gwei are converted to XMR or ETH. The returned symbol is "XMR", "ETH", the symbol
+// of another unit as given, or empty if the amount has no unit. The number always
+// has a period as the decimal separator, so it is parsed the same regardless of the
+// locale, and the amount must be positive.
+func ParseAmount(s string) (*apd.Decimal, string, error) {
+	number, unit, _ := strings.Cut(strings.TrimSpace(s), " ")
+	unit = strings.TrimSpace(unit)
+
+	if number == "" {
+		return nil, "", errAmountEmpty
+	}
+
+	// a unit can directly follow the number, like in "0.5ETH"
+	if unit == "" {
+		if i := strings.IndexFunc(number, isUnitRune); i > 0 {
+			number, unit = number[:i], number[i:]
+		}
+	}
+
+	if !amountNumberRegex.MatchString(number) {
+		if strings.Contains(number, ",") {
+			return nil, "", fmt.Errorf("invalid amount %q: use a period as the decimal separator, "+
+				"without digit grouping", s)
+		}
+		return nil, "", fmt.Errorf("invalid amount %q", s)
+	}
+
+	amount, _, err := new(apd.Decimal).SetString(number)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+
+	var symbol string
+	switch {
+	case unit == "":
+	case strings.EqualFold(unit, "XMR"):
+		symbol = "XMR"
+	case strings.EqualFold(unit, unitPiconero):
+		if err = convertFromSmallestUnits(amount, NumMoneroDecimals, s); err != nil {
+			return nil, "", err
+		}
+		symbol = "XMR"
+	case strings.EqualFold(unit, "ETH"):
+		symbol = "ETH"
+	case strings.EqualFold(unit, unitWei):
+		if err = convertFromSmallestUnits(amount, NumEtherDecimals, s); err != nil {
+			return nil, "", err
+		}
+		symbol = "ETH"
+	case strings.EqualFold(unit, unitGwei):
+		if err = convertFromUnits(amount, NumEtherDecimals-gweiDecimals, NumEtherDecimals, s); err != nil {
+			return nil, "", err
+		}
+		symbol = "ETH"
+	default:
+		symbol = unit
+	}
+
+	if err = ValidatePositive("amount", MaxCoinPrecision, amount); err != nil {
+		return nil, "", fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+
+	return amount, symbol, nil
+}
+
+// isUnitRune returns whether the rune starts the unit of an amount.
+func isUnitRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// convertFromSmallestUnits converts an amount in the smallest units of a coin, which
+// must be whole, to standard units.
+func convertFromSmallestUnits(amount *apd.Decimal, decimals uint8, s string) error {
+	return convertFromUnits(amount, decimals, decimals, s)
+}
+
+// convertFromUnits converts an amount in units of 10^-shift standard units to standard
+// units, which can have at most the coin's decimals.
+func convertFromUnits(amount *apd.Decimal, shift uint8, decimals uint8, s string) error {
+	decreaseExponent(amount, shift)
+	_, _ = amount.Reduce(amount)
+	if amount.Exponent < -int32(decimals) {
+		return fmt.Errorf("invalid amount %q: fractions of the smallest unit are not supported", s)
+	}
+	return nil
+}
+
+// ParseXMRAmount parses an XMR amount like "1.2", "1.2 XMR" or "1200000000000
+// piconero" into XMR.
+func ParseXMRAmount(s string) (*apd.Decimal, error) {
+	amount, symbol, err := ParseAmount(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol != "" && symbol != "XMR" {
+		return nil, fmt.Errorf("invalid amount %q: expected an XMR amount", s)
+	}
+
+	if err = ValidateXMRAmount("amount", amount); err != nil {
+		return nil, err
+	}
+
+	return amount, nil
+}
+
+// ParseETHAmount parses an ETH amount like "0.5", "0.5 ETH", "20 gwei" or "1000 wei"
+// into ETH.
+func ParseETHAmount(s string) (*apd.Decimal, error) {
+	amount, symbol, err := ParseAmount(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol != "" && symbol != "ETH" {
+		return nil, fmt.Errorf("invalid amount %q: expected an ETH amount", s)
+	}
+
+	if err = ValidateETHAmount("amount", amount); err != nil {
+		return nil, err
+	}
+
+	return amount, nil
+}
+
+// ParseTokenAmount parses an amount of the token in standard units, like "250" or
+// "250 USDT", which can't have more decimals than the token.
+func ParseTokenAmount(s string, token *ERC20TokenInfo) (*apd.Decimal, error) {
+	amount, symbol, err := ParseAmount(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol != "" && !strings.EqualFold(symbol, token.Symbol) {
+		return nil, fmt.Errorf("invalid amount %q: expected an amount of %s", s, token.SanitizedSymbol())
+	}
+
+	if err = ValidatePositive("amount", token.NumDecimals, amount); err != nil {
+		return nil, err
+	}
+
+	return amount, nil
+}
+
+// ValidateXMRAmount validates a positive XMR amount, which can't be more than the
+// XMR supply, as such amounts are likely piconeros that were meant to be XMR.
+func ValidateXMRAmount(jsonFieldName string, amount *apd.Decimal) error {
+	if err := ValidatePositive(jsonFieldName, NumMoneroDecimals, amount); err != nil {
+		return err
+	}
+
+	if amount.Cmp(maxMoneroAmount) > 0 {
+		return fmt.Errorf("%q of %s XMR is more than the XMR supply, the amount is in XMR, not piconero",
+			jsonFieldName, amount.Text('f'))
+	}
+
+	return nil
+}
+
+// ValidateETHAmount validates a positive ETH amount, which can't be more than the
+// ETH supply, as such amounts are likely wei that were meant to be ETH.
+func ValidateETHAmount(jsonFieldName string, amount *apd.Decimal) error {
+	if err := ValidatePositive(jsonFieldName, NumEtherDecimals, amount); err != nil {
+		return err
+	}
+
+	if amount.Cmp(maxETHAmount) > 0 {
+		return fmt.Errorf("%q of %s ETH is more than the ETH supply, the amount is in ETH, not wei",
+			jsonFieldName, amount.Text('f'))
+	}
+
+	return nil
+}
+
+// FormatAmount formats an amount in standard units with its symbol, like "0.5 ETH".
+// The amount has a period as the decimal separator and no exponent or digit
+// grouping regardless of the locale, so that ParseAmount parses it back.
+func FormatAmount(amount *apd.Decimal, symbol string) string {
+	reduced := new(apd.Decimal)
+	_, _ = reduced.Reduce(amount)
+	if symbol == "" {
+		return reduced.Text('f')
+	}
+	return reduced.Text('f') + " " + symbol
+}
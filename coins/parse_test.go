@@ -0,0 +1,126 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package coins
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	type entry struct {
+		input  string
+		amount string
+		symbol string
+	}
+	testEntries := []entry{
+		{input: "1.5", amount: "1.5", symbol: ""},
+		{input: " .5 ", amount: "0.5", symbol: ""},
+		{input: "2.", amount: "2", symbol: ""},
+		{input: "0.5 ETH", amount: "0.5", symbol: "ETH"},
+		{input: "0.5eth", amount: "0.5", symbol: "ETH"},
+		{input: "1.2 XMR", amount: "1.2", symbol: "XMR"},
+		{input: "1200000000000 piconero", amount: "1.2", symbol: "XMR"},
+		{input: "1000 wei", amount: "0.000000000000001", symbol: "ETH"},
+		{input: "20 gwei", amount: "0.00000002", symbol: "ETH"},
+		{input: "0.5 gwei", amount: "0.0000000005", symbol: "ETH"},
+		{input: "250  USDT", amount: "250", symbol: "USDT"},
+	}
+
+	for _, e := range testEntries {
+		amount, symbol, err := ParseAmount(e.input)
+		require.NoError(t, err, e.input)
+		require.Equal(t, e.amount, amount.Text('f'), e.input)
+		require.Equal(t, e.symbol, symbol, e.input)
+	}
+}
+
+func TestParseAmount_errors(t *testing.T) {
+	type entry struct {
+		input       string
+		errContains string
+	}
+	testEntries := []entry{
+		{input: "", errContains: "amount is empty"},
+		{input: "ETH", errContains: `invalid amount "ETH"`},
+		{input: "1,5 XMR", errContains: "use a period as the decimal separator"},
+		{input: "1,000.5", errContains: "use a period as the decimal separator"},
+		{input: "-1 XMR", errContains: `invalid amount "-1 XMR"`},
+		{input: "1e18 wei", errContains: `invalid amount "1e18 wei"`},
+		{input: "0 ETH", errContains: "must be non-zero"},
+		{input: "0.5 wei", errContains: "fractions of the smallest unit are not supported"},
+		{input: "1.5 piconero", errContains: "fractions of the smallest unit are not supported"},
+	}
+
+	for _, e := range testEntries {
+		_, _, err := ParseAmount(e.input)
+		require.ErrorContains(t, err, e.errContains, e.input)
+	}
+}
+
+func TestParseXMRAmount(t *testing.T) {
+	amount, err := ParseXMRAmount("1.5 XMR")
+	require.NoError(t, err)
+	require.Equal(t, "1.5", amount.String())
+
+	_, err = ParseXMRAmount("1.5 ETH")
+	require.ErrorContains(t, err, "expected an XMR amount")
+
+	_, err = ParseXMRAmount("0.0000000000001")
+	require.ErrorContains(t, err, "too many decimal points")
+
+	// piconero amounts submitted as XMR are more than the XMR supply
+	_, err = ParseXMRAmount("1500000000000000000000")
+	require.ErrorContains(t, err, "the amount is in XMR, not piconero")
+}
+
+func TestParseETHAmount(t *testing.T) {
+	amount, err := ParseETHAmount("0.5")
+	require.NoError(t, err)
+	require.Equal(t, "0.5", amount.String())
+
+	amount, err = ParseETHAmount("500000000000000000 wei")
+	require.NoError(t, err)
+	require.Equal(t, "0.5", amount.String())
+
+	_, err = ParseETHAmount("0.5 XMR")
+	require.ErrorContains(t, err, "expected an ETH amount")
+
+	// wei amounts submitted as ETH are more than the ETH supply
+	_, err = ParseETHAmount("500000000000000000")
+	require.ErrorContains(t, err, "the amount is in ETH, not wei")
+}
+
+func TestParseTokenAmount(t *testing.T) {
+	token := NewERC20TokenInfo(ethcommon.Address{0x1}, 6, "Tether USD", "USDT")
+
+	amount, err := ParseTokenAmount("250 usdt", token)
+	require.NoError(t, err)
+	require.Equal(t, "250", amount.Text('f'))
+
+	amount, err = ParseTokenAmount("0.000001", token)
+	require.NoError(t, err)
+	require.Equal(t, "0.000001", amount.Text('f'))
+
+	_, err = ParseTokenAmount("0.0000001 USDT", token)
+	require.ErrorContains(t, err, "too many decimal points")
+
+	_, err = ParseTokenAmount("250 ETH", token)
+	require.ErrorContains(t, err, `expected an amount of "USDT"`)
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.5 ETH", FormatAmount(StrToDecimal("0.500"), "ETH"))
+	require.Equal(t, "1200 XMR", FormatAmount(StrToDecimal("1.2E+3"), "XMR"))
+	require.Equal(t, "0.00000002", FormatAmount(StrToDecimal("2E-8"), ""))
+
+	// formatted amounts parse back to the same amount
+	amount := StrToDecimal("1234567.000000000001")
+	parsed, symbol, err := ParseAmount(FormatAmount(amount, "XMR"))
+	require.NoError(t, err)
+	require.Equal(t, "XMR", symbol)
+	require.Zero(t, parsed.Cmp(amount))
+}
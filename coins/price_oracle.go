@@ -0,0 +1,77 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package coins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+)
+
+// ErrUnsupportedPair is returned by price oracles that have no rate for a pair.
+var ErrUnsupportedPair = errors.New("unsupported currency pair")
+
+// Currency pairs of the rates that price oracles provide
+var (
+	PairXMRUSD = Pair{Base: "XMR", Quote: "USD"}
+	PairETHUSD = Pair{Base: "ETH", Quote: "USD"}
+)
+
+// Pair is a currency pair, like XMR/USD. Its rate is the price of one unit of the
+// base currency in the quote currency.
+type Pair struct {
+	Base  string
+	Quote string
+}
+
+// String ...
+func (p Pair) String() string {
+	return p.Base + "/" + p.Quote
+}
+
+// Rate is the rate of a currency pair, as of the time it was last updated.
+type Rate struct {
+	Pair      Pair
+	Price     *apd.Decimal
+	UpdatedAt time.Time
+}
+
+// PriceOracle is a source of the rates of currency pairs, like an on-chain price
+// feed or the API of an exchange.
+type PriceOracle interface {
+	// GetRate returns the current rate of the pair. The error wraps
+	// ErrUnsupportedPair if the oracle has no rate for the pair.
+	GetRate(ctx context.Context, pair Pair) (*Rate, error)
+}
+
+// OracleExchangeRate returns the XMR/ETH exchange rate computed from the XMR/USD and
+// ETH/USD rates of the oracle, and the time that the older of the two rates was
+// updated at.
+func OracleExchangeRate(ctx context.Context, oracle PriceOracle) (*ExchangeRate, time.Time, error) {
+	xmrRate, err := oracle.GetRate(ctx, PairXMRUSD)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	ethRate, err := oracle.GetRate(ctx, PairETHUSD)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	exchangeRate, err := CalcExchangeRate(xmrRate.Price, ethRate.Price)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to calculate exchange rate from %s %s and %s %s: %w",
+			xmrRate.Pair, xmrRate.Price, ethRate.Pair, ethRate.Price, err)
+	}
+
+	updatedAt := xmrRate.UpdatedAt
+	if ethRate.UpdatedAt.Before(updatedAt) {
+		updatedAt = ethRate.UpdatedAt
+	}
+
+	return exchangeRate, updatedAt, nil
+}
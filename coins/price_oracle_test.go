@@ -0,0 +1,30 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package coins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOracleExchangeRate(t *testing.T) {
+	now := time.Now()
+	oracle := &MockPriceOracle{Rates: map[Pair]*Rate{
+		PairXMRUSD: {Pair: PairXMRUSD, Price: StrToDecimal("150"), UpdatedAt: now},
+		PairETHUSD: {Pair: PairETHUSD, Price: StrToDecimal("2000"), UpdatedAt: now.Add(-time.Minute)},
+	}}
+
+	rate, updatedAt, err := OracleExchangeRate(context.Background(), oracle)
+	require.NoError(t, err)
+	require.Equal(t, "0.075", rate.String())
+	require.Equal(t, now.Add(-time.Minute), updatedAt)
+
+	delete(oracle.Rates, PairETHUSD)
+	_, _, err = OracleExchangeRate(context.Background(), oracle)
+	require.ErrorIs(t, err, ErrUnsupportedPair)
+	require.ErrorContains(t, err, "ETH/USD")
+}
@@ -4,15 +4,22 @@
 package coins
 
 import (
+	"fmt"
+
 	"github.com/cockroachdb/apd/v3"
 )
 
 func roundToDecimalPlace(result *apd.Decimal, n *apd.Decimal, decimalPlace uint8) error {
+	return roundToDecimalPlaceWith(decimalCtx, result, n, decimalPlace)
+}
+
+// roundToDecimalPlaceWith rounds with the rounding mode of the context.
+func roundToDecimalPlaceWith(ctx *apd.Context, result *apd.Decimal, n *apd.Decimal, decimalPlace uint8) error {
 	result.Set(n) // already optimizes result == n
 
 	// Adjust the exponent to the rounding place, round, then adjust the exponent back
 	increaseExponent(result, decimalPlace)
-	_, err := decimalCtx.RoundToIntegralValue(result, result)
+	_, err := ctx.RoundToIntegralValue(result, result)
 	if err != nil {
 		return err
 	}
@@ -20,3 +27,18 @@ func roundToDecimalPlace(result *apd.Decimal, n *apd.Decimal, decimalPlace uint8
 	_, _ = result.Reduce(result)
 	return nil
 }
+
+// roundingCtx returns a clone of our decimal context with the rounding mode. An
+// empty rounding mode is rounding half up, like in apd.
+func roundingCtx(rounding apd.Rounder) (*apd.Context, error) {
+	switch rounding {
+	case "", apd.RoundHalfUp, apd.RoundHalfEven, apd.RoundHalfDown, apd.RoundDown,
+		apd.RoundUp, apd.RoundFloor, apd.RoundCeiling, apd.Round05Up:
+	default:
+		return nil, fmt.Errorf("%w %q", errInvalidRounding, rounding)
+	}
+
+	ctx := DecimalCtx()
+	ctx.Rounding = rounding
+	return ctx, nil
+}
@@ -6,6 +6,7 @@
 package coins
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -55,3 +56,17 @@ func (a *WeiAmount) Sub(b *WeiAmount) *WeiAmount {
 	}
 	return result
 }
+
+// MockPriceOracle is a PriceOracle for unit tests that returns fixed rates.
+type MockPriceOracle struct {
+	Rates map[Pair]*Rate
+}
+
+// GetRate returns the fixed rate of the pair.
+func (o *MockPriceOracle) GetRate(_ context.Context, pair Pair) (*Rate, error) {
+	rate, ok := o.Rates[pair]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPair, pair)
+	}
+	return rate, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package coins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// Chain IDs of the built-in token lists. These duplicate the values in the common
+// package, which imports this package.
+const (
+	mainnetChainID = 1
+	sepoliaChainID = 11155111
+)
+
+// RegistryToken is an entry of a token list. The JSON format matches the entries of
+// the Uniswap token list standard, so existing lists can be used as overrides.
+type RegistryToken struct {
+	ChainID int64 `json:"chainId" validate:"required"`
+	ERC20TokenInfo
+	LogoURI string `json:"logoURI,omitempty"`
+}
+
+// TokenList is a list of tokens, which can span several chains.
+type TokenList struct {
+	Tokens []*RegistryToken `json:"tokens" validate:"dive,required"`
+}
+
+// TokenRegistry holds the known tokens of one chain, so token metadata doesn't need
+// to be looked up on chain for every address.
+type TokenRegistry struct {
+	chainID int64
+	tokens  map[ethcommon.Address]*RegistryToken
+}
+
+func trustWalletLogo(addr string) string {
+	return fmt.Sprintf("https://raw.githubusercontent.com/trustwallet/assets/master/blockchains/ethereum/assets/%s/logo.png",
+		ethcommon.HexToAddress(addr).Hex())
+}
+
+func builtinToken(chainID int64, addr string, decimals uint8, name string, symbol string, logo string) *RegistryToken {
+	return &RegistryToken{
+		ChainID:        chainID,
+		ERC20TokenInfo: *NewERC20TokenInfo(ethcommon.HexToAddress(addr), decimals, name, symbol),
+		LogoURI:        logo,
+	}
+}
+
+// builtinTokens is the curated token list. Tokens that the swap contract can't
+// handle, like USDT which doesn't return a bool from transfer, are left out.
+var builtinTokens = []*RegistryToken{
+	builtinToken(mainnetChainID, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", 6, "USD Coin", "USDC",
+		trustWalletLogo("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")),
+	builtinToken(mainnetChainID, "0x6B175474E89094C44Da98b954EedeAC495271d0F", 18, "Dai Stablecoin", "DAI",
+		trustWalletLogo("0x6B175474E89094C44Da98b954EedeAC495271d0F")),
+	builtinToken(mainnetChainID, "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", 18, "Wrapped Ether", "WETH",
+		trustWalletLogo("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")),
+	builtinToken(mainnetChainID, "0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599", 8, "Wrapped BTC", "WBTC",
+		trustWalletLogo("0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599")),
+	builtinToken(sepoliaChainID, "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238", 6, "USD Coin", "USDC",
+		trustWalletLogo("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")),
+}
+
+// NewTokenRegistry returns a registry holding the built-in tokens of the chain. Dev
+// chains have no built-in tokens.
+func NewTokenRegistry(chainID *big.Int) *TokenRegistry {
+	r := &TokenRegistry{
+		chainID: chainID.Int64(),
+		tokens:  make(map[ethcommon.Address]*RegistryToken),
+	}
+
+	for _, token := range builtinTokens {
+		r.add(token)
+	}
+
+	return r
+}
+
+func (r *TokenRegistry) add(token *RegistryToken) {
+	if token.ChainID != r.chainID {
+		return
+	}
+
+	r.tokens[token.Address] = token
+}
+
+// AddTokenList adds the list's tokens of the registry's chain, replacing built-in
+// entries with the same address.
+func (r *TokenRegistry) AddTokenList(list *TokenList) {
+	for _, token := range list.Tokens {
+		r.add(token)
+	}
+}
+
+// Get returns the registry entry of the token, or nil if the token is not in the
+// registry.
+func (r *TokenRegistry) Get(addr ethcommon.Address) *RegistryToken {
+	return r.tokens[addr]
+}
+
+// Tokens returns the tokens of the registry sorted by symbol.
+func (r *TokenRegistry) Tokens() []*RegistryToken {
+	tokens := make([]*RegistryToken, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		tokens = append(tokens, token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		si, sj := strings.ToUpper(tokens[i].Symbol), strings.ToUpper(tokens[j].Symbol)
+		if si != sj {
+			return si < sj
+		}
+		return tokens[i].Address.Hex() < tokens[j].Address.Hex()
+	})
+
+	return tokens
+}
+
+// ReadTokenListFile reads a JSON token list in the Uniswap token list format.
+// Fields of the format that the registry doesn't use are ignored.
+func ReadTokenListFile(path string) (*TokenList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	list := new(TokenList)
+	if err = json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("invalid token list %q: %w", path, err)
+	}
+
+	for i, token := range list.Tokens {
+		if err = token.validate(); err != nil {
+			return nil, fmt.Errorf("invalid token list %q, token %d: %w", path, i, err)
+		}
+	}
+
+	return list, nil
+}
+
+func (t *RegistryToken) validate() error {
+	switch {
+	case t == nil:
+		return errors.New("empty entry")
+	case t.ChainID <= 0:
+		return errors.New("missing chainId")
+	case t.Address == (ethcommon.Address{}):
+		return errors.New("missing address")
+	case t.Symbol == "":
+		return errors.New("missing symbol")
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package coins
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenRegistry(t *testing.T) {
+	r := NewTokenRegistry(big.NewInt(mainnetChainID))
+	tokens := r.Tokens()
+	require.NotEmpty(t, tokens)
+
+	for i, token := range tokens {
+		require.Equal(t, int64(mainnetChainID), token.ChainID)
+		if i > 0 {
+			require.LessOrEqual(t, tokens[i-1].Symbol, token.Symbol)
+		}
+	}
+
+	usdc := r.Get(ethcommon.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"))
+	require.NotNil(t, usdc)
+	require.Equal(t, uint8(6), usdc.NumDecimals)
+	require.Equal(t, "USDC", usdc.Symbol)
+
+	// dev chains have no built-in tokens
+	require.Empty(t, NewTokenRegistry(big.NewInt(1337)).Tokens())
+}
+
+func TestTokenRegistry_AddTokenList(t *testing.T) {
+	const tokenList = `{
+	"name": "Test List",
+	"tokens": [
+		{
+			"chainId": 1,
+			"address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			"name": "USD Coin (overridden)",
+			"symbol": "USDC",
+			"decimals": 6,
+			"logoURI": "https://example.com/usdc.png"
+		},
+		{
+			"chainId": 1,
+			"address": "0x1000000000000000000000000000000000000001",
+			"name": "Test Token",
+			"symbol": "TEST",
+			"decimals": 18
+		},
+		{
+			"chainId": 10,
+			"address": "0x2000000000000000000000000000000000000002",
+			"name": "Other Chain Token",
+			"symbol": "OTHER",
+			"decimals": 18
+		}
+	]
+}`
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(tokenList), 0600))
+
+	list, err := ReadTokenListFile(path)
+	require.NoError(t, err)
+	require.Len(t, list.Tokens, 3)
+
+	r := NewTokenRegistry(big.NewInt(mainnetChainID))
+	numBuiltin := len(r.Tokens())
+	r.AddTokenList(list)
+	require.Len(t, r.Tokens(), numBuiltin+1)
+
+	usdc := r.Get(ethcommon.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"))
+	require.Equal(t, "USD Coin (overridden)", usdc.Name)
+	require.Equal(t, "https://example.com/usdc.png", usdc.LogoURI)
+	require.NotNil(t, r.Get(ethcommon.HexToAddress("0x1000000000000000000000000000000000000001")))
+	require.Nil(t, r.Get(ethcommon.HexToAddress("0x2000000000000000000000000000000000000002")))
+}
+
+func TestReadTokenListFile_invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"tokens":[{"chainId":1,"symbol":"X"}]}`), 0600))
+
+	_, err := ReadTokenListFile(path)
+	require.ErrorContains(t, err, "invalid token list")
+}
@@ -21,6 +21,19 @@ const (
 
 	// DefaultEthKeyFileName is the default ethereum private key file name in {DATA_DIR}
 	DefaultEthKeyFileName = "eth.key"
+
+	// DefaultContractAddressesFileName is the default file name in {DATA_DIR} that
+	// stores the addresses of deployed contracts
+	DefaultContractAddressesFileName = "contract-addresses.json"
+
+	// DefaultRPCTLSCertFileName and DefaultRPCTLSKeyFileName are the file names in
+	// {DATA_DIR} of the self-signed certificate and key of the RPC server
+	DefaultRPCTLSCertFileName = "rpc-tls-cert.pem"
+	DefaultRPCTLSKeyFileName  = "rpc-tls-key.pem"
+
+	// DefaultRPCUnixSocketFileName is the file name in {DATA_DIR} of the unix
+	// socket of the RPC server
+	DefaultRPCUnixSocketFileName = "rpc.sock"
 )
 
 var homeDir, _ = os.UserHomeDir()
@@ -41,6 +54,10 @@ type Config struct {
 	SwapCreatorAddr ethcommon.Address
 	ForwarderAddr   ethcommon.Address
 	Bootnodes       []string
+
+	// SwapCreatorDeployBlock is the block SwapCreatorAddr was deployed in, if
+	// known. Zero if unknown.
+	SwapCreatorDeployBlock uint64
 }
 
 // MainnetConfig is the mainnet ethereum and monero configuration
@@ -130,6 +147,12 @@ func (c Config) MoneroWalletPath() string {
 	return path.Join(c.DataDir, "wallet", DefaultMoneroWalletName)
 }
 
+// ProfileDir returns the directory of the wallet and key files of the named profile,
+// which depends on current value of the data dir.
+func (c Config) ProfileDir(name string) string {
+	return path.Join(c.DataDir, "profiles", name)
+}
+
 // LibP2PKeyFile returns the path to the libp2p key file, whose default value
 // depends on current value of the data dir.
 func (c Config) LibP2PKeyFile() string {
@@ -142,6 +165,30 @@ func (c Config) EthKeyFileName() string {
 	return path.Join(c.DataDir, DefaultEthKeyFileName)
 }
 
+// ContractAddressesFile returns the path to the file storing the addresses of
+// deployed contracts, whose default value depends on current value of the data dir.
+func (c Config) ContractAddressesFile() string {
+	return path.Join(c.DataDir, DefaultContractAddressesFileName)
+}
+
+// RPCTLSCertFile returns the path to the self-signed certificate of the RPC server,
+// whose default value depends on current value of the data dir.
+func (c Config) RPCTLSCertFile() string {
+	return path.Join(c.DataDir, DefaultRPCTLSCertFileName)
+}
+
+// RPCTLSKeyFile returns the path to the key of the RPC server's self-signed
+// certificate, whose default value depends on current value of the data dir.
+func (c Config) RPCTLSKeyFile() string {
+	return path.Join(c.DataDir, DefaultRPCTLSKeyFileName)
+}
+
+// RPCUnixSocketFile returns the path to the unix socket of the RPC server, whose
+// default value depends on current value of the data dir.
+func (c Config) RPCUnixSocketFile() string {
+	return path.Join(c.DataDir, DefaultRPCUnixSocketFileName)
+}
+
 // ConfigDefaultsForEnv returns the configuration defaults for the given environment.
 func ConfigDefaultsForEnv(env Environment) *Config {
 	switch env {
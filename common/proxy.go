@@ -0,0 +1,91 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+)
+
+// Proxy is a SOCKS5 proxy, like the one of a Tor daemon, that connections to remote
+// hosts are made through. Host names are resolved by the proxy. Connections to
+// loopback hosts, like a local monerod, are made directly, as Tor refuses them.
+type Proxy struct {
+	addr   string
+	dialer proxy.ContextDialer
+}
+
+// NewProxy returns the SOCKS5 proxy at the "host:port" address.
+func NewProxy(addr string) (*Proxy, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy address %q: %w", addr, err)
+	}
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("invalid proxy address %q: host and port are required", addr)
+	}
+
+	dialer, err := proxy.SOCKS5("tcp", addr, nil, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Proxy{
+		addr:   addr,
+		dialer: dialer.(proxy.ContextDialer),
+	}, nil
+}
+
+// Addr returns the "host:port" address of the proxy.
+func (p *Proxy) Addr() string {
+	return p.addr
+}
+
+// DialContext connects to the "host:port" address through the proxy.
+func (p *Proxy) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	if host, _, err := net.SplitHostPort(addr); err == nil && IsLoopbackHost(host) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+
+	return p.dialer.DialContext(ctx, network, addr)
+}
+
+// ProxyURL returns the URL of the proxy for requests to remote hosts, and nil for
+// requests to loopback hosts. It is the Proxy function of HTTP transports and
+// websocket dialers.
+func (p *Proxy) ProxyURL(req *http.Request) (*url.URL, error) {
+	if IsLoopbackHost(req.URL.Hostname()) {
+		return nil, nil
+	}
+
+	return &url.URL{Scheme: "socks5", Host: p.addr}, nil
+}
+
+// HTTPTransport returns the transport of HTTP clients whose requests go through the
+// proxy. The proxy can be nil, in which case it returns http.DefaultTransport.
+func (p *Proxy) HTTPTransport() http.RoundTripper {
+	if p == nil {
+		return http.DefaultTransport
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = p.ProxyURL
+	return transport
+}
+
+// IsLoopbackHost returns whether the host name or IP address is of the local host.
+func IsLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package common
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serveSOCKS5 accepts one connection on the listener as a SOCKS5 proxy without
+// authentication, sends the requested "host:port" address to addrCh, and echoes
+// what the client writes instead of connecting to the address. On a protocol error
+// it closes the connection, failing the client's dial.
+func serveSOCKS5(l net.Listener, addrCh chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// greeting: version, number of methods, methods
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(conn, header); err != nil {
+		return
+	}
+	if _, err = io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return
+	}
+	if _, err = conn.Write([]byte{5, 0}); err != nil {
+		return
+	}
+
+	// request: version, command, reserved, address type, then the domain name
+	// length, domain name and port, as the proxy resolves the domain name
+	request := make([]byte, 5)
+	if _, err = io.ReadFull(conn, request); err != nil || request[3] != 3 {
+		return
+	}
+	hostLen := int(request[4])
+	hostPort := make([]byte, hostLen+2)
+	if _, err = io.ReadFull(conn, hostPort); err != nil {
+		return
+	}
+
+	port := binary.BigEndian.Uint16(hostPort[hostLen:])
+	addrCh <- net.JoinHostPort(string(hostPort[:hostLen]), strconv.Itoa(int(port)))
+
+	if _, err = conn.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 0}); err != nil {
+		return
+	}
+
+	_, _ = io.Copy(conn, conn)
+}
+
+func TestNewProxy_invalidAddress(t *testing.T) {
+	for _, addr := range []string{"", "127.0.0.1", ":9050", "127.0.0.1:"} {
+		_, err := NewProxy(addr)
+		require.ErrorContains(t, err, "invalid proxy address", addr)
+	}
+}
+
+func TestProxy_DialContext(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	addrCh := make(chan string, 1)
+	go serveSOCKS5(l, addrCh)
+
+	proxy, err := NewProxy(l.Addr().String())
+	require.NoError(t, err)
+	require.Equal(t, l.Addr().String(), proxy.Addr())
+
+	const onionAddr = "abcdefghijklmnopqrstuvwxyz234567abcdefghijklmnopqrstuvw.onion:9900"
+	conn, err := proxy.DialContext(context.Background(), "tcp", onionAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, onionAddr, <-addrCh)
+
+	msg := []byte("hello")
+	_, err = conn.Write(msg)
+	require.NoError(t, err)
+	reply := make([]byte, len(msg))
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	require.Equal(t, msg, reply)
+}
+
+func TestProxy_DialContext_loopback(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	// the proxy isn't listening, so the connection must be made directly
+	proxy, err := NewProxy("127.0.0.1:1")
+	require.NoError(t, err)
+
+	conn, err := proxy.DialContext(context.Background(), "tcp", l.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestProxy_ProxyURL(t *testing.T) {
+	proxy, err := NewProxy("127.0.0.1:9050")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:18081/json_rpc", nil)
+	require.NoError(t, err)
+	proxyURL, err := proxy.ProxyURL(req)
+	require.NoError(t, err)
+	require.Nil(t, proxyURL)
+
+	req, err = http.NewRequest(http.MethodPost, "https://node.example.com/json_rpc", nil)
+	require.NoError(t, err)
+	proxyURL, err = proxy.ProxyURL(req)
+	require.NoError(t, err)
+	require.Equal(t, "socks5://127.0.0.1:9050", proxyURL.String())
+}
+
+func TestProxy_HTTPTransport_nil(t *testing.T) {
+	var proxy *Proxy
+	require.Equal(t, http.DefaultTransport, proxy.HTTPTransport())
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	require.True(t, IsLoopbackHost("localhost"))
+	require.True(t, IsLoopbackHost("127.0.0.1"))
+	require.True(t, IsLoopbackHost("::1"))
+	require.False(t, IsLoopbackHost("192.168.1.2"))
+	require.False(t, IsLoopbackHost("node.example.com"))
+}
@@ -30,6 +30,18 @@ type Response struct {
 // ErrCode is a int type used for the rpc error codes
 type ErrCode int
 
+// Codes of the errors of swapd's methods that clients may want to handle. Errors
+// without a more specific code have ErrCodeServer.
+const (
+	ErrCodeServer              ErrCode = -32000
+	ErrCodeOfferNotFound       ErrCode = -32001
+	ErrCodeSwapNotFound        ErrCode = -32002
+	ErrCodeSwapOngoing         ErrCode = -32003
+	ErrCodeInsufficientBalance ErrCode = -32004
+	ErrCodePaused              ErrCode = -32005
+	ErrCodeDraining            ErrCode = -32006
+)
+
 // Error is a struct that holds the error message and the error code for a error
 type Error struct {
 	Message   string                 `json:"message"`
@@ -6,6 +6,9 @@
 package rpctypes
 
 import (
+	"math/big"
+	"time"
+
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -20,12 +23,94 @@ const (
 	NetDiscover         = "net_discover"
 	NetQueryPeer        = "net_queryPeer"
 	SubscribeNewPeer    = "net_subscribeNewPeer"
+	SubscribeOffers     = "net_subscribeOffers"
 	SubscribeMakeOffer  = "net_makeOfferAndSubscribe"
 	SubscribeTakeOffer  = "net_takeOfferAndSubscribe"
 	SubscribeSwapStatus = "swap_subscribeStatus"
 	SubscribeSigner     = "signer_subscribe"
+	SubscribeBalances   = "personal_subscribeBalances"
+	SubscribeMoneroSync = "daemon_subscribeMoneroSync"
+)
+
+// SubscribeOffersRequest ...
+type SubscribeOffersRequest struct {
+	Provides   string `json:"provides"`
+	SearchTime uint64 `json:"searchTime"` // in seconds
+	Interval   uint64 `json:"interval"`   // in seconds between refreshes of the offer book
+	types.OfferFilter
+}
+
+// OfferUpdateType is the type of change of an offer in the offer book. The ID of an
+// offer is the hash of its fields, so a changed offer is a removed offer followed by
+// an added offer.
+type OfferUpdateType string
+
+// Offer book update types
+const (
+	OfferAdded   OfferUpdateType = "added"
+	OfferRemoved OfferUpdateType = "removed"
+)
+
+// OfferBookUpdate is a change of the offer book streamed by net_subscribeOffers.
+type OfferBookUpdate struct {
+	Type   OfferUpdateType `json:"type" validate:"required"`
+	PeerID peer.ID         `json:"peerID" validate:"required"`
+	Offer  *types.Offer    `json:"offer" validate:"required"`
+}
+
+// SubscribeBalancesRequest ...
+type SubscribeBalancesRequest struct {
+	TokenAddrs []ethcommon.Address `json:"tokenAddrs" validate:"dive,required"`
+	Interval   uint64              `json:"interval"` // in seconds between balance checks
+	// Thresholds below which a balance is reported as low, in standard units. Token
+	// thresholds are keyed by token address.
+	ETHThreshold    *apd.Decimal                       `json:"ethThreshold,omitempty"`
+	XMRThreshold    *apd.Decimal                       `json:"xmrThreshold,omitempty"`
+	TokenThresholds map[ethcommon.Address]*apd.Decimal `json:"tokenThresholds,omitempty"`
+}
+
+// BalanceEventType is the type of a balance event.
+type BalanceEventType string
+
+// Balance event types
+const (
+	// BalanceChanged is emitted with the first balance of each asset, and then every
+	// time that it changes.
+	BalanceChanged BalanceEventType = "changed"
+	// BalanceLow is emitted when a balance drops below its threshold, including the
+	// first balance. It is emitted again only after the balance recovers.
+	BalanceLow BalanceEventType = "low"
 )
 
+// BalanceEvent is a balance event streamed by personal_subscribeBalances. The asset
+// is ETH, XMR or the address of a token. XMR balances include locked funds.
+type BalanceEvent struct {
+	Type      BalanceEventType `json:"type" validate:"required"`
+	Asset     string           `json:"asset" validate:"required"`
+	Balance   *apd.Decimal     `json:"balance" validate:"required"` // in standard units
+	Previous  *apd.Decimal     `json:"previous,omitempty"`          // in standard units
+	Threshold *apd.Decimal     `json:"threshold,omitempty"`         // in standard units
+}
+
+// SubscribeMoneroSyncRequest ...
+type SubscribeMoneroSyncRequest struct {
+	Interval uint64 `json:"interval"` // in seconds between sync checks
+}
+
+// MoneroSyncEvent is the sync progress of the monero wallet streamed by
+// daemon_subscribeMoneroSync.
+type MoneroSyncEvent struct {
+	WalletHeight uint64 `json:"walletHeight"`
+	DaemonHeight uint64 `json:"daemonHeight"`
+	Synced       bool   `json:"synced"`
+	// Percent of the blocks that the wallet had to scan when it fell behind, which it
+	// has scanned since
+	Percent float64 `json:"percent"`
+	// ETASeconds is the estimated time until the wallet is synced, unset if it is
+	// synced or if the scan rate is not known yet
+	ETASeconds uint64 `json:"etaSeconds,omitempty"`
+}
+
 // SubscribeSwapStatusRequest ...
 type SubscribeSwapStatusRequest struct {
 	OfferID types.Hash `json:"offerID" validate:"required"`
@@ -51,6 +136,7 @@ type DiscoverResponse struct {
 type QueryPeerRequest struct {
 	// Peer ID of peer to query
 	PeerID peer.ID `json:"peerID" validate:"required"`
+	types.OfferFilter
 }
 
 // QueryPeerResponse ...
@@ -60,12 +146,156 @@ type QueryPeerResponse struct {
 
 // PeerWithOffers ...
 type PeerWithOffers struct {
-	PeerID peer.ID        `json:"peerID" validate:"required"`
-	Offers []*types.Offer `json:"offers" validate:"dive,required"`
+	PeerID     peer.ID         `json:"peerID" validate:"required"`
+	Offers     []*types.Offer  `json:"offers" validate:"dive,required"`
+	Reputation *PeerReputation `json:"reputation,omitempty"`
+}
+
+// PeerReputation counts the outcomes of our past swaps with a peer, so that
+// takers can prefer makers with a track record.
+type PeerReputation struct {
+	// Completed is the number of swaps that completed successfully.
+	Completed uint64 `json:"completed"`
+	// AbortedAfterLock is the number of swaps that the peer abandoned after we
+	// locked our funds, before locking theirs, so that we refunded.
+	AbortedAfterLock uint64 `json:"abortedAfterLock"`
+	// Refunded is the number of swaps that were refunded after both of us locked
+	// our funds, as the peer didn't complete them.
+	Refunded uint64 `json:"refunded"`
+}
+
+// PeerReputationRequest ...
+type PeerReputationRequest struct {
+	PeerID peer.ID `json:"peerID" validate:"required"`
+}
+
+// PeerReputationResponse ...
+type PeerReputationResponse = PeerReputation
+
+// BanPeerRequest ...
+type BanPeerRequest struct {
+	PeerID   peer.ID `json:"peerID" validate:"required"`
+	Duration uint64  `json:"duration"` // in seconds, 0 for a permanent ban
+	Reason   string  `json:"reason,omitempty"`
+}
+
+// UnbanPeerRequest ...
+type UnbanPeerRequest struct {
+	PeerID peer.ID `json:"peerID" validate:"required"`
+}
+
+// BannedPeer is a peer that we don't connect to, and that can't connect to us.
+type BannedPeer struct {
+	PeerID peer.ID    `json:"peerID" validate:"required"`
+	Until  *time.Time `json:"until,omitempty"` // not set if the ban is permanent
+	Reason string     `json:"reason,omitempty"`
+}
+
+// ListBannedResponse ...
+type ListBannedResponse struct {
+	Peers []*BannedPeer `json:"peers" validate:"dive,required"`
+}
+
+// BandwidthStats is the traffic in bytes, and its current rate in bytes per second.
+type BandwidthStats struct {
+	BytesIn  int64   `json:"bytesIn"`
+	BytesOut int64   `json:"bytesOut"`
+	RateIn   float64 `json:"rateIn"`
+	RateOut  float64 `json:"rateOut"`
+}
+
+// ProtocolStats is the traffic and the number of received messages of a protocol.
+type ProtocolStats struct {
+	Protocol        string         `json:"protocol" validate:"required"`
+	Bandwidth       BandwidthStats `json:"bandwidth"`
+	Messages        uint64         `json:"messages"`
+	InvalidMessages uint64         `json:"invalidMessages"`
+}
+
+// PeerStats is the traffic with a peer and the number of messages that it sent us
+// on each protocol.
+type PeerStats struct {
+	PeerID          peer.ID           `json:"peerID" validate:"required"`
+	Bandwidth       BandwidthStats    `json:"bandwidth"`
+	Messages        map[string]uint64 `json:"messages"`
+	InvalidMessages uint64            `json:"invalidMessages"`
+}
+
+// NetStatsResponse ...
+type NetStatsResponse struct {
+	Total     BandwidthStats   `json:"total"`
+	Protocols []*ProtocolStats `json:"protocols" validate:"dive,required"`
+	Peers     []*PeerStats     `json:"peers" validate:"dive,required"`
+}
+
+// DiscoverRelayersRequest ...
+type DiscoverRelayersRequest struct {
+	// Value is the swap value in ETH that relayers are asked to quote a fee
+	// for. It defaults to 1 ETH.
+	Value      *apd.Decimal `json:"value,omitempty"`
+	SearchTime uint64       `json:"searchTime"` // in seconds
+}
+
+// RelayerInfo is a relayer found in the DHT along with its fee quote. Fee is nil
+// if the relayer did not respond to the quote request.
+type RelayerInfo struct {
+	PeerID     peer.ID      `json:"peerID" validate:"required"`
+	Fee        *apd.Decimal `json:"fee"` // in ETH
+	ETASeconds uint64       `json:"etaSeconds"`
+}
+
+// DiscoverRelayersResponse ...
+type DiscoverRelayersResponse struct {
+	Relayers []*RelayerInfo `json:"relayers" validate:"dive,required"`
+}
+
+// RelayerStats is the recorded outcomes of the claims submitted to a relayer,
+// along with the relayer's score.
+type RelayerStats struct {
+	PeerID      peer.ID   `json:"peerID" validate:"required"`
+	Accepted    uint64    `json:"accepted"`
+	Submitted   uint64    `json:"submitted"`
+	Confirmed   uint64    `json:"confirmed"`
+	Failed      uint64    `json:"failed"`
+	TimedOut    uint64    `json:"timedOut"`
+	LastUpdated time.Time `json:"lastUpdated"`
+	Score       float64   `json:"score"`
+}
+
+// RelayerStatsResponse ...
+type RelayerStatsResponse struct {
+	Relayers []*RelayerStats `json:"relayers" validate:"dive,required"`
+}
+
+// RelayerEarnings is the activity of a node relaying claims for other nodes over
+// a period. Amounts are in ETH, except for the fees of token swaps, which are paid
+// in the token and are not part of the net profit. They are listed in base units
+// per token address.
+type RelayerEarnings struct {
+	ClaimsRelayed   uint64                         `json:"claimsRelayed"`
+	FeesEarned      *apd.Decimal                   `json:"feesEarned" validate:"required"`
+	TokenFeesEarned map[ethcommon.Address]*big.Int `json:"tokenFeesEarned,omitempty"`
+	GasSpent        *apd.Decimal                   `json:"gasSpent" validate:"required"`
+	NetProfit       *apd.Decimal                   `json:"netProfit" validate:"required"`
+}
+
+// RelayerDailyEarnings is the relaying activity of one day (UTC).
+type RelayerDailyEarnings struct {
+	Date string `json:"date" validate:"required"` // YYYY-MM-DD
+	RelayerEarnings
+}
+
+// RelayerEarningsResponse ...
+type RelayerEarningsResponse struct {
+	Total *RelayerEarnings        `json:"total" validate:"required"`
+	Days  []*RelayerDailyEarnings `json:"days" validate:"dive,required"`
 }
 
 // QueryAllRequest ...
-type QueryAllRequest = DiscoverRequest
+type QueryAllRequest struct {
+	DiscoverRequest
+	types.OfferFilter
+}
 
 // QueryAllResponse ...
 type QueryAllResponse struct {
@@ -77,21 +307,29 @@ type TakeOfferRequest struct {
 	PeerID         peer.ID      `json:"peerID" validate:"required"`
 	OfferID        types.Hash   `json:"offerID" validate:"required"`
 	ProvidesAmount *apd.Decimal `json:"providesAmount" validate:"required"` // eth asset amount
+	Profile        string       `json:"profile,omitempty"`                  // funds the swap, the default if empty
 }
 
 // MakeOfferRequest ...
 type MakeOfferRequest struct {
 	MinAmount    *apd.Decimal        `json:"minAmount" validate:"required"`
 	MaxAmount    *apd.Decimal        `json:"maxAmount" validate:"required"`
-	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate,omitempty"` // required unless Peg is set
 	EthAsset     types.EthAsset      `json:"ethAsset,omitempty"`
 	UseRelayer   bool                `json:"useRelayer,omitempty"`
+	Profile      string              `json:"profile,omitempty"` // funds the offer's swap, the default if empty
+	// Peg, if set instead of ExchangeRate, pegs the exchange rate of the offer to
+	// the market price of a price source plus a markup.
+	Peg *types.OfferPeg `json:"peg,omitempty"`
 }
 
 // MakeOfferResponse ...
 type MakeOfferResponse struct {
 	PeerID  peer.ID    `json:"peerID" validate:"required"`
 	OfferID types.Hash `json:"offerID" validate:"required"`
+	// ExchangeRate is the exchange rate that the offer was made at, which changes
+	// with the market price if the offer is pegged
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate,omitempty"`
 }
 
 // SignerRequest initiates the signer_subscribe handler from the front-end
@@ -120,13 +358,19 @@ type TokenInfoRequest struct {
 	TokenAddr ethcommon.Address `json:"tokenAddr" validate:"required"`
 }
 
-// TokenInfoResponse contains the metadata for the requested token
-type TokenInfoResponse = coins.ERC20TokenInfo
+// TokenInfoResponse contains the metadata for the requested token. NonStandard is
+// set for tokens whose transfer and approve methods don't return a bool (USDT and
+// some other older tokens). Offers can't be made or taken in those tokens.
+type TokenInfoResponse struct {
+	*coins.ERC20TokenInfo
+	NonStandard bool `json:"nonStandard"`
+}
 
 // BalancesRequest is used to request the combined Monero and Ethereum balances
 // as well as the balances of any tokens included in the request.
 type BalancesRequest struct {
 	TokenAddrs []ethcommon.Address `json:"tokensAddrs" validate:"dive,required"`
+	Profile    string              `json:"profile,omitempty"` // the default profile if empty
 }
 
 // BalancesResponse holds the response for the combined Monero, Ethereum and
@@ -135,10 +379,59 @@ type BalancesResponse struct {
 	MoneroAddress           *mcrypto.Address          `json:"moneroAddress" validate:"required"`
 	PiconeroBalance         *coins.PiconeroAmount     `json:"piconeroBalance" validate:"required"`
 	PiconeroUnlockedBalance *coins.PiconeroAmount     `json:"piconeroUnlockedBalance" validate:"required"`
+	PiconeroLockedBalance   *coins.PiconeroAmount     `json:"piconeroLockedBalance" validate:"required"`
 	BlocksToUnlock          uint64                    `json:"blocksToUnlock"`
+	LockedOutputs           []*LockedOutput           `json:"lockedOutputs" validate:"dive,required"`
 	EthAddress              ethcommon.Address         `json:"ethAddress" validate:"required"`
 	WeiBalance              *coins.WeiAmount          `json:"weiBalance" validate:"required"`
 	TokenBalances           []*coins.ERC20TokenAmount `json:"tokenBalances" validate:"dive,required"`
+
+	// ViewOnly is set when the monero wallet is a view-only wallet, whose balance
+	// includes the outputs that were spent, as it can't see outgoing transfers.
+	ViewOnly bool `json:"viewOnly,omitempty"`
+}
+
+// LockedOutput is a monero output that can't be spent yet, like the XMR of a recent
+// claim or the change of a recent transfer. The locked outputs are the difference
+// between the balance and the unlocked balance.
+type LockedOutput struct {
+	TxID           string                `json:"txID" validate:"required"`
+	Amount         *coins.PiconeroAmount `json:"amount" validate:"required"`
+	Height         uint64                `json:"height"`
+	BlocksToUnlock uint64                `json:"blocksToUnlock"`
+}
+
+// Profile is a named Monero wallet and Ethereum account that offers and swaps can
+// be funded from instead of the default ones.
+type Profile struct {
+	Name          string            `json:"name" validate:"required"`
+	MoneroAddress *mcrypto.Address  `json:"moneroAddress" validate:"required"`
+	EthAddress    ethcommon.Address `json:"ethAddress" validate:"required"`
+}
+
+// ProfilesResponse ...
+type ProfilesResponse struct {
+	Profiles []*Profile `json:"profiles" validate:"dive,required"`
+}
+
+// IncomingTransfersRequest ...
+type IncomingTransfersRequest struct {
+	MinHeight uint64 `json:"minHeight"` // optional, 0 returns all the incoming transfers
+}
+
+// IncomingTransfer is an incoming transfer of the primary monero wallet, like the
+// claim of a swap that we took or the refund of a swap that we made.
+type IncomingTransfer struct {
+	TxID          string                `json:"txID" validate:"required"`
+	Amount        *coins.PiconeroAmount `json:"amount" validate:"required"`
+	Height        uint64                `json:"height"` // 0 while the transfer is in the transaction pool
+	Confirmations uint64                `json:"confirmations"`
+	Timestamp     uint64                `json:"timestamp"` // unix time in seconds
+}
+
+// IncomingTransfersResponse ...
+type IncomingTransfersResponse struct {
+	Transfers []*IncomingTransfer `json:"transfers" validate:"dive,required"`
 }
 
 // AddressesResponse ...
@@ -150,3 +443,11 @@ type AddressesResponse struct {
 type PeersResponse struct {
 	Addrs []string `json:"addresses" validate:"dive,required"`
 }
+
+// NATStatusResponse is the response of net_natStatus. Reachability is one of
+// "unknown", "public" or "private", and RelayAddrs are the circuit relay addresses
+// that peers reach us at when we are not publicly reachable.
+type NATStatusResponse struct {
+	Reachability string   `json:"reachability" validate:"required"`
+	RelayAddrs   []string `json:"relayAddresses" validate:"dive,required"`
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
 )
 
 // EthAsset represents an Ethereum asset (ETH or a token address)
@@ -23,6 +26,17 @@ func (asset EthAsset) IsToken() bool {
 	return asset != EthAssetETH
 }
 
+// ValidateAmount validates a positive amount of the asset in standard units. ETH
+// amounts can't be more than the ETH supply, as such amounts are likely wei that
+// were meant to be ETH. The decimals of token amounts are checked against the
+// token's decimals once they are known.
+func (asset EthAsset) ValidateAmount(jsonFieldName string, amount *apd.Decimal) error {
+	if asset.IsETH() {
+		return coins.ValidateETHAmount(jsonFieldName, amount)
+	}
+	return coins.ValidatePositive(jsonFieldName, coins.NumEtherDecimals, amount)
+}
+
 // String implements fmt.Stringer, returning the asset's address in hex
 // prefixed by `ERC20@` if it's an ERC20 token, or ETH for ether.
 func (asset EthAsset) String() string {
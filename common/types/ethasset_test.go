@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
@@ -71,3 +72,13 @@ func TestEthAsset_IsToken(t *testing.T) {
 	require.False(t, token.IsETH())
 	require.True(t, token.IsToken())
 }
+
+func TestEthAsset_ValidateAmount(t *testing.T) {
+	weiAmount := apd.New(5, 17)
+	require.NoError(t, EthAssetETH.ValidateAmount("amount", apd.New(5, -1)))
+	require.ErrorContains(t, EthAssetETH.ValidateAmount("amount", weiAmount), "the amount is in ETH, not wei")
+
+	token := EthAsset(ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"))
+	require.NoError(t, token.ValidateAmount("amount", weiAmount))
+	require.ErrorContains(t, token.ValidateAmount("amount", apd.New(0, 0)), `"amount" must be non-zero`)
+}
@@ -20,12 +20,22 @@ import (
 
 var (
 	// CurOfferVersion is the latest supported version of a serialised Offer struct
-	CurOfferVersion, _ = semver.NewVersion("1.0.0")
+	CurOfferVersion, _ = semver.NewVersion("1.1.0")
+
+	// plainOfferVersion is the version of offers with a fixed exchange rate. Only
+	// pegged offers use CurOfferVersion, so that swapd versions that don't support
+	// pegs can still take the offers with a fixed exchange rate.
+	plainOfferVersion, _ = semver.NewVersion("1.0.0")
 
 	errOfferVersionMissing = errors.New(`required "version" field missing in offer`)
 	errOfferIDNotSet       = errors.New(`"offerID" is not set`)
 	errExchangeRateNil     = errors.New(`"exchangeRate" is not set`)
 	errMinGreaterThanMax   = errors.New(`"minAmount" must be less than or equal to "maxAmount"`)
+	errPegSourceNotSet     = errors.New(`"peg.source" is not set`)
+	errPegMarkupNotSet     = errors.New(`"peg.markup" is not set`)
+	errPegMarkupTooLow     = errors.New(`"peg.markup" must be greater than -100`)
+	errPegWithToken        = errors.New("only offers for ETH can be pegged")
+	errPegVersionTooOld    = errors.New("pegged offers require a newer offer version")
 )
 
 // Offer represents a swap offer
@@ -38,6 +48,42 @@ type Offer struct {
 	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
 	EthAsset     EthAsset            `json:"ethAsset"`
 	Nonce        uint64              `json:"nonce" validate:"required"`
+	// Peg, if set, pegs the exchange rate to the market price of a price source. The
+	// maker keeps the exchange rate of pegged offers up to date, which doesn't
+	// change their ID.
+	Peg *OfferPeg `json:"peg,omitempty"`
+}
+
+// OfferPeg pegs the exchange rate of an offer to the XMR/ETH market price of a price
+// source, plus a markup.
+type OfferPeg struct {
+	Source string       `json:"source" validate:"required"` // eg. "coingecko" or "kraken"
+	Markup *apd.Decimal `json:"markup" validate:"required"` // percent added to the market price
+}
+
+// String ...
+func (p *OfferPeg) String() string {
+	sign := "+"
+	if p.Markup.Negative {
+		sign = ""
+	}
+	return fmt.Sprintf("%s%s%s%%", p.Source, sign, p.Markup.Text('f'))
+}
+
+func (p *OfferPeg) validate() error {
+	if p.Source == "" {
+		return errPegSourceNotSet
+	}
+
+	if p.Markup == nil {
+		return errPegMarkupNotSet
+	}
+
+	if p.Markup.Cmp(apd.New(-100, 0)) <= 0 {
+		return errPegMarkupTooLow
+	}
+
+	return nil
 }
 
 // NewOffer creates and returns an Offer with an initialised ID and Version fields
@@ -61,7 +107,7 @@ func NewOffer(
 	_, _ = exRate.Decimal().Reduce(exRate.Decimal())
 
 	offer := &Offer{
-		Version:      *CurOfferVersion,
+		Version:      *plainOfferVersion,
 		Provides:     coin,
 		MinAmount:    minAmount,
 		MaxAmount:    maxAmount,
@@ -74,6 +120,31 @@ func NewOffer(
 	return offer
 }
 
+// NewPeggedOffer creates and returns an Offer whose exchange rate is pegged to the
+// market price of a price source. The exchange rate is the current pegged rate.
+func NewPeggedOffer(
+	coin coins.ProvidesCoin,
+	minAmount *apd.Decimal,
+	maxAmount *apd.Decimal,
+	exRate *coins.ExchangeRate,
+	peg *OfferPeg,
+) *Offer {
+	offer := NewOffer(coin, minAmount, maxAmount, exRate, EthAssetETH)
+
+	_, _ = peg.Markup.Reduce(peg.Markup)
+	offer.Version = *CurOfferVersion
+	offer.Peg = peg
+	offer.ID = offer.hash()
+	return offer
+}
+
+// WithExchangeRate returns a copy of the pegged offer with the exchange rate.
+func (o *Offer) WithExchangeRate(exRate *coins.ExchangeRate) *Offer {
+	updated := *o
+	updated.ExchangeRate = exRate
+	return &updated
+}
+
 func (o *Offer) setID() {
 	if !IsHashZero(o.ID) {
 		panic("offer ID is already set")
@@ -89,7 +160,12 @@ func (o *Offer) hash() Hash {
 	b = append(b, []byte(",")...)
 	b = append(b, []byte(o.MaxAmount.Text('f'))...)
 	b = append(b, []byte(",")...)
-	b = append(b, []byte(o.ExchangeRate.String())...)
+	if o.Peg != nil {
+		// the exchange rate of pegged offers changes, so the peg is hashed instead
+		b = append(b, []byte(o.Peg.Source+":"+o.Peg.Markup.Text('f'))...)
+	} else {
+		b = append(b, []byte(o.ExchangeRate.String())...)
+	}
 	b = append(b, []byte(",")...)
 	b = append(b, []byte(o.EthAsset.String())...)
 	b = append(b, []byte(",")...)
@@ -99,7 +175,7 @@ func (o *Offer) hash() Hash {
 
 // String ...
 func (o *Offer) String() string {
-	return fmt.Sprintf("OfferID:%s Provides:%s MinAmount:%s MaxAmount:%s ExchangeRate:%s EthAsset:%s Nonce:%d",
+	str := fmt.Sprintf("OfferID:%s Provides:%s MinAmount:%s MaxAmount:%s ExchangeRate:%s EthAsset:%s Nonce:%d",
 		o.ID,
 		o.Provides,
 		o.MinAmount.String(),
@@ -108,6 +184,10 @@ func (o *Offer) String() string {
 		o.EthAsset,
 		o.Nonce,
 	)
+	if o.Peg != nil {
+		str += fmt.Sprintf(" Peg:%s", o.Peg)
+	}
+	return str
 }
 
 // IsSet returns true if the offer's fields are all set.
@@ -124,10 +204,10 @@ func (o *Offer) validate() error {
 		return errOfferIDNotSet
 	}
 
-	if err := coins.ValidatePositive("minAmount", coins.NumMoneroDecimals, o.MinAmount); err != nil {
+	if err := coins.ValidateXMRAmount("minAmount", o.MinAmount); err != nil {
 		return err
 	}
-	if err := coins.ValidatePositive("maxAmount", coins.NumMoneroDecimals, o.MaxAmount); err != nil {
+	if err := coins.ValidateXMRAmount("maxAmount", o.MaxAmount); err != nil {
 		return err
 	}
 
@@ -141,6 +221,18 @@ func (o *Offer) validate() error {
 		return errExchangeRateNil
 	}
 
+	if o.Peg != nil {
+		if err := o.Peg.validate(); err != nil {
+			return err
+		}
+		if o.EthAsset.IsToken() {
+			return errPegWithToken
+		}
+		if o.Version.LessThan(CurOfferVersion) {
+			return errPegVersionTooOld
+		}
+	}
+
 	if o.ID != o.hash() {
 		return errors.New("hash of offer fields does not match offer ID")
 	}
@@ -152,6 +244,10 @@ func (o *Offer) validate() error {
 type OfferExtra struct {
 	StatusCh   chan Status `json:"-"`
 	UseRelayer bool        `json:"useRelayer,omitempty"`
+	Profile    string      `json:"profile,omitempty"` // empty for the default profile
+	// MarketMaker is whether the offer was made by the market maker, which takes
+	// the offer over after a restart.
+	MarketMaker bool `json:"marketMaker,omitempty"`
 }
 
 // UnmarshalOffer deserializes a JSON offer, checking the version for compatibility before
@@ -0,0 +1,68 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package types
+
+import (
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// OfferFilter holds the constraints that the offers returned by an offer query must
+// match, so that makers only send the offers that the taker is interested in. The
+// constraints that are not set match every offer.
+type OfferFilter struct {
+	// EthAsset is the asset that the offers must be swapped for
+	EthAsset *EthAsset `json:"ethAsset,omitempty"`
+
+	// MinAmount and MaxAmount are the range of XMR amounts that the taker wants to
+	// swap, which must overlap with the range of amounts of the offers
+	MinAmount *apd.Decimal `json:"minAmount,omitempty"`
+	MaxAmount *apd.Decimal `json:"maxAmount,omitempty"`
+
+	// MaxExchangeRate is the highest exchange rate of the offers
+	MaxExchangeRate *coins.ExchangeRate `json:"maxExchangeRate,omitempty"`
+}
+
+// Matches returns whether the offer matches the constraints of the filter. A nil
+// filter matches every offer.
+func (f *OfferFilter) Matches(o *Offer) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.EthAsset != nil && *f.EthAsset != o.EthAsset {
+		return false
+	}
+
+	if f.MinAmount != nil && o.MaxAmount.Cmp(f.MinAmount) < 0 {
+		return false
+	}
+
+	if f.MaxAmount != nil && o.MinAmount.Cmp(f.MaxAmount) > 0 {
+		return false
+	}
+
+	if f.MaxExchangeRate != nil && o.ExchangeRate.Decimal().Cmp(f.MaxExchangeRate.Decimal()) > 0 {
+		return false
+	}
+
+	return true
+}
+
+// Filter returns the offers that match the constraints of the filter.
+func (f *OfferFilter) Filter(offers []*Offer) []*Offer {
+	if f == nil {
+		return offers
+	}
+
+	matching := make([]*Offer, 0, len(offers))
+	for _, o := range offers {
+		if f.Matches(o) {
+			matching = append(matching, o)
+		}
+	}
+
+	return matching
+}
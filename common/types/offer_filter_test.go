@@ -0,0 +1,49 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package types
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestOfferFilter_Matches(t *testing.T) {
+	token := EthAsset(ethcommon.HexToAddress("0x0000000000000000000000000000000000000001"))
+	rate := coins.ToExchangeRate(apd.New(15, -2)) // 0.15
+	offer := NewOffer(coins.ProvidesXMR, apd.New(1, 0), apd.New(10, 0), rate, EthAssetETH)
+
+	var nilFilter *OfferFilter
+	require.True(t, nilFilter.Matches(offer))
+	require.True(t, new(OfferFilter).Matches(offer))
+
+	require.True(t, (&OfferFilter{EthAsset: &EthAssetETH}).Matches(offer))
+	require.False(t, (&OfferFilter{EthAsset: &token}).Matches(offer))
+
+	// the amount ranges must overlap
+	require.True(t, (&OfferFilter{MinAmount: apd.New(10, 0)}).Matches(offer))
+	require.False(t, (&OfferFilter{MinAmount: apd.New(11, 0)}).Matches(offer))
+	require.True(t, (&OfferFilter{MaxAmount: apd.New(1, 0)}).Matches(offer))
+	require.False(t, (&OfferFilter{MaxAmount: apd.New(5, -1)}).Matches(offer))
+	require.True(t, (&OfferFilter{MinAmount: apd.New(5, 0), MaxAmount: apd.New(20, 0)}).Matches(offer))
+
+	require.True(t, (&OfferFilter{MaxExchangeRate: rate}).Matches(offer))
+	require.False(t, (&OfferFilter{MaxExchangeRate: coins.ToExchangeRate(apd.New(1, -1))}).Matches(offer))
+}
+
+func TestOfferFilter_Filter(t *testing.T) {
+	rate := coins.ToExchangeRate(apd.New(1, -1))
+	small := NewOffer(coins.ProvidesXMR, apd.New(1, 0), apd.New(2, 0), rate, EthAssetETH)
+	large := NewOffer(coins.ProvidesXMR, apd.New(10, 0), apd.New(20, 0), rate, EthAssetETH)
+	offers := []*Offer{small, large}
+
+	var nilFilter *OfferFilter
+	require.Equal(t, offers, nilFilter.Filter(offers))
+	require.Equal(t, []*Offer{large}, (&OfferFilter{MinAmount: apd.New(5, 0)}).Filter(offers))
+	require.Empty(t, (&OfferFilter{MinAmount: apd.New(50, 0)}).Filter(offers))
+}
@@ -94,7 +94,7 @@ func TestOffer_UnmarshalJSON_DefaultAsset(t *testing.T) {
 	var res Offer
 	err := vjson.UnmarshalStruct([]byte(offerJSON), &res)
 	require.NoError(t, err)
-	assert.Equal(t, *CurOfferVersion, offer.Version)
+	assert.Equal(t, *plainOfferVersion, offer.Version)
 	assert.Equal(t, offer.ID, res.ID)
 	assert.Equal(t, res.Provides, coins.ProvidesXMR)
 	assert.Equal(t, res.MinAmount.Text('f'), "100")
@@ -241,3 +241,47 @@ func TestUnmarshalOffer_VersionTooNew(t *testing.T) {
 	_, err := UnmarshalOffer([]byte(offerJSON))
 	require.ErrorContains(t, err, fmt.Sprintf("offer version %q not supported", unsupportedVersion))
 }
+
+func TestNewPeggedOffer(t *testing.T) {
+	min := apd.New(1, 0)
+	max := apd.New(2, 0)
+	rate := coins.ToExchangeRate(apd.New(75, -3)) // 0.075
+	peg := &OfferPeg{Source: "kraken", Markup: apd.New(20, -1)}
+	offer := NewPeggedOffer(coins.ProvidesXMR, min, max, rate, peg)
+	require.Equal(t, *CurOfferVersion, offer.Version)
+	require.Equal(t, "kraken+2%", offer.Peg.String())
+	require.NoError(t, offer.validate())
+
+	// updating the exchange rate doesn't change the offer ID
+	updated := offer.WithExchangeRate(coins.ToExchangeRate(apd.New(8, -2)))
+	require.NoError(t, updated.validate())
+	require.Equal(t, offer.ID, updated.ID)
+	require.Equal(t, "0.075", offer.ExchangeRate.String())
+
+	offerJSON, err := vjson.MarshalStruct(updated)
+	require.NoError(t, err)
+	res, err := UnmarshalOffer(offerJSON)
+	require.NoError(t, err)
+	require.Equal(t, "0.08", res.ExchangeRate.String())
+	require.Equal(t, "2", res.Peg.Markup.String())
+
+	// changing the peg changes the ID
+	updated.Peg = &OfferPeg{Source: "kraken", Markup: apd.New(3, 0)}
+	require.ErrorContains(t, updated.validate(), "hash of offer fields does not match offer ID")
+}
+
+func TestOffer_validate_peg(t *testing.T) {
+	min := apd.New(1, 0)
+	max := apd.New(2, 0)
+	rate := coins.ToExchangeRate(apd.New(75, -3)) // 0.075
+
+	offer := NewPeggedOffer(coins.ProvidesXMR, min, max, rate, &OfferPeg{Source: "", Markup: apd.New(0, 0)})
+	require.ErrorIs(t, offer.validate(), errPegSourceNotSet)
+
+	offer = NewPeggedOffer(coins.ProvidesXMR, min, max, rate, &OfferPeg{Source: "kraken", Markup: apd.New(-100, 0)})
+	require.ErrorIs(t, offer.validate(), errPegMarkupTooLow)
+
+	offer = NewPeggedOffer(coins.ProvidesXMR, min, max, rate, &OfferPeg{Source: "kraken", Markup: apd.New(0, 0)})
+	offer.Version = *plainOfferVersion
+	require.ErrorIs(t, offer.validate(), errPegVersionTooOld)
+}
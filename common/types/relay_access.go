@@ -0,0 +1,71 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package types
+
+import (
+	"errors"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+var (
+	errRelayPeerDenied    = errors.New("peer is not allowed to submit claims for relaying")
+	errRelayClaimerDenied = errors.New("claimer is not allowed to have claims relayed")
+)
+
+// RelayAccessList restricts whose claims a relayer relays, and which relayers a
+// claimer submits its claims to. Empty allow lists allow everyone not denied.
+type RelayAccessList struct {
+	// AllowPeers, if not empty, are the only peers whose claims we relay.
+	AllowPeers []peer.ID `json:"allowPeers,omitempty"`
+	// DenyPeers are peers whose claims we don't relay.
+	DenyPeers []peer.ID `json:"denyPeers,omitempty"`
+	// AllowClaimers, if not empty, are the only claimer addresses of the swaps
+	// whose claims we relay.
+	AllowClaimers []ethcommon.Address `json:"allowClaimers,omitempty"`
+	// DenyClaimers are claimer addresses of swaps whose claims we don't relay.
+	DenyClaimers []ethcommon.Address `json:"denyClaimers,omitempty"`
+
+	// TrustedRelayers, if not empty, are the only relayers that we submit our
+	// claims to, instead of the relayers advertising in the DHT.
+	TrustedRelayers []peer.ID `json:"trustedRelayers,omitempty"`
+}
+
+func containsPeer(peers []peer.ID, peerID peer.ID) bool {
+	for _, p := range peers {
+		if p == peerID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAddress(addrs []ethcommon.Address, addr ethcommon.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRelayRequest returns an error if the list doesn't allow relaying a claim
+// of the claimer address submitted by the peer. A nil list allows all claims.
+func (l *RelayAccessList) CheckRelayRequest(peerID peer.ID, claimer ethcommon.Address) error {
+	if l == nil {
+		return nil
+	}
+
+	if containsPeer(l.DenyPeers, peerID) || (len(l.AllowPeers) > 0 && !containsPeer(l.AllowPeers, peerID)) {
+		return errRelayPeerDenied
+	}
+
+	if containsAddress(l.DenyClaimers, claimer) ||
+		(len(l.AllowClaimers) > 0 && !containsAddress(l.AllowClaimers, claimer)) {
+		return errRelayClaimerDenied
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package types
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	libp2ptest "github.com/libp2p/go-libp2p/core/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayAccessList_CheckRelayRequest(t *testing.T) {
+	peerA, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+	peerB, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+	claimerA := ethcommon.Address{0x1}
+	claimerB := ethcommon.Address{0x2}
+
+	var noList *RelayAccessList
+	require.NoError(t, noList.CheckRelayRequest(peerA, claimerA))
+	require.NoError(t, new(RelayAccessList).CheckRelayRequest(peerA, claimerA))
+
+	deny := &RelayAccessList{DenyPeers: []peer.ID{peerB}, DenyClaimers: []ethcommon.Address{claimerB}}
+	require.NoError(t, deny.CheckRelayRequest(peerA, claimerA))
+	require.ErrorIs(t, deny.CheckRelayRequest(peerB, claimerA), errRelayPeerDenied)
+	require.ErrorIs(t, deny.CheckRelayRequest(peerA, claimerB), errRelayClaimerDenied)
+
+	allow := &RelayAccessList{AllowPeers: []peer.ID{peerA}, AllowClaimers: []ethcommon.Address{claimerA}}
+	require.NoError(t, allow.CheckRelayRequest(peerA, claimerA))
+	require.ErrorIs(t, allow.CheckRelayRequest(peerB, claimerA), errRelayPeerDenied)
+	require.ErrorIs(t, allow.CheckRelayRequest(peerA, claimerB), errRelayClaimerDenied)
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package secp256k1
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	mnemonicEntropyBits = 256 // 24 words
+	hardenedKeyStart    = uint32(0x80000000)
+)
+
+// ethDerivationPath is the BIP-44 path of the first ethereum account, m/44'/60'/0'/0/0,
+// which is what other wallets restore from a mnemonic by default.
+var ethDerivationPath = []uint32{hardenedKeyStart + 44, hardenedKeyStart + 60, hardenedKeyStart, 0, 0}
+
+var errInvalidDerivedKey = errors.New("invalid derived key, try the next index")
+
+// NewMnemonic returns a new random 24 word BIP-39 mnemonic.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// EthereumKeyFromMnemonic returns the private key of the first ethereum account
+// derived from the BIP-39 mnemonic, using an empty BIP-39 passphrase.
+func EthereumKeyFromMnemonic(mnemonic string) (*ecdsa.PrivateKey, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	key, err := deriveBIP32Key(seed, ethDerivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ethcrypto.ToECDSA(key)
+}
+
+// deriveBIP32Key derives the BIP-32 private key at the given path from the seed.
+func deriveBIP32Key(seed []byte, path []uint32) ([]byte, error) {
+	curveOrder := ethcrypto.S256().Params().N
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	_, _ = mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	masterKey := new(big.Int).SetBytes(key)
+	if masterKey.Sign() == 0 || masterKey.Cmp(curveOrder) >= 0 {
+		return nil, errInvalidDerivedKey
+	}
+
+	for _, index := range path {
+		data := make([]byte, 0, 33+4)
+		if index >= hardenedKeyStart {
+			data = append(data, 0)
+			data = append(data, key...)
+		} else {
+			privKey, err := ethcrypto.ToECDSA(key)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, ethcrypto.CompressPubkey(&privKey.PublicKey)...)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac = hmac.New(sha512.New, chainCode)
+		_, _ = mac.Write(data)
+		sum = mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(curveOrder) >= 0 {
+			return nil, errInvalidDerivedKey
+		}
+
+		childKey := tweak.Add(tweak, new(big.Int).SetBytes(key))
+		childKey.Mod(childKey, curveOrder)
+		if childKey.Sign() == 0 {
+			return nil, errInvalidDerivedKey
+		}
+
+		key = math.PaddedBigBytes(childKey, 32)
+		chainCode = sum[32:]
+	}
+
+	return key, nil
+}
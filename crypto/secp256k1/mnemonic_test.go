@@ -0,0 +1,48 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package secp256k1
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEthereumKeyFromMnemonic(t *testing.T) {
+	// well known development mnemonic used by Hardhat and Foundry
+	mnemonic := "test test test test test test test test test test test junk"
+	key, err := EthereumKeyFromMnemonic(mnemonic)
+	require.NoError(t, err)
+	require.Equal(t,
+		"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
+		hex.EncodeToString(ethcrypto.FromECDSA(key)),
+	)
+	require.Equal(t,
+		"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		ethcrypto.PubkeyToAddress(key.PublicKey).Hex(),
+	)
+
+	// extra whitespace is ignored
+	key2, err := EthereumKeyFromMnemonic(" test test test test test test\ntest test test test test  junk ")
+	require.NoError(t, err)
+	require.Equal(t, key.D, key2.D)
+}
+
+func TestEthereumKeyFromMnemonic_invalid(t *testing.T) {
+	// bad checksum
+	_, err := EthereumKeyFromMnemonic("test test test test test test test test test test test test")
+	require.ErrorContains(t, err, "invalid mnemonic")
+}
+
+func TestNewMnemonic(t *testing.T) {
+	mnemonic, err := NewMnemonic()
+	require.NoError(t, err)
+	require.Len(t, strings.Fields(mnemonic), 24)
+
+	_, err = EthereumKeyFromMnemonic(mnemonic)
+	require.NoError(t, err)
+}
@@ -11,22 +11,32 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"time"
 
 	"github.com/ChainSafe/chaindb"
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/hashicorp/go-multierror"
 	logging "github.com/ipfs/go-log"
 
+	"github.com/athanorlabs/atomic-swap/cliutil"
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/db"
+	"github.com/athanorlabs/atomic-swap/ethereum/erc4337"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/ethereum/indexer"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrtaker"
+	"github.com/athanorlabs/atomic-swap/relayer"
 	"github.com/athanorlabs/atomic-swap/rpc"
+	"github.com/athanorlabs/atomic-swap/webhook"
 )
 
 var log = logging.Logger("daemon")
@@ -34,13 +44,119 @@ var log = logging.Logger("daemon")
 // SwapdConfig provides startup parameters for swapd.
 type SwapdConfig struct {
 	EnvConf        *common.Config
-	MoneroClient   monero.WalletClient
+	MoneroClient   monero.WalletClient // nil if the wallet is unlocked with UnlockMoneroWallet
 	EthereumClient extethclient.EthClient
 	Libp2pPort     uint16
 	Libp2pKeyfile  string
 	RPCPort        uint16
 	IsRelayer      bool
 	NoTransferBack bool
+
+	// EthKeyFile and EthKeystorePassword are only set when the ethereum key was
+	// loaded from a file, they allow restoring the key file over RPC.
+	EthKeyFile          string
+	EthKeystorePassword string
+
+	// UnlockMoneroWallet, if MoneroClient is nil, opens the Monero wallet with the
+	// password of a personal_unlock request. swapd only starts once the wallet is
+	// unlocked, until then the RPC server only serves personal_unlock.
+	UnlockMoneroWallet func(password string) (monero.WalletClient, error)
+
+	UserOps      *UserOpConfig                // optional
+	TokenList    *coins.TokenList             // optional, adds to or overrides the built-in tokens
+	RelayerFee   *relayer.FeeConfig           // optional, relayer.DefaultFeeConfig() if nil
+	RelayerBatch *relayer.BatchConfig         // optional, relayed claims are not batched if nil
+	DirectClaim  *backend.DirectClaimFallback // optional, backend.DefaultDirectClaimFallback() if nil
+
+	// XMRPriority is the fee priority of our monero lock and sweep transactions, the
+	// wallet's default priority if zero. It can be overridden for each swap over RPC.
+	XMRPriority wallet.Priority
+
+	// Profiles are the named Monero wallets and Ethereum accounts, besides the default
+	// ones, that offers and swaps can select to be funded from.
+	Profiles []*backend.Profile
+
+	// RelayAccessListFile is the optional JSON file with the relay access list,
+	// which restricts whose claims we relay and which relayers relay our claims.
+	RelayAccessListFile string
+
+	// Proxy, if set, is the SOCKS5 proxy that peers are dialed through, in which
+	// case TorControl optionally configures the onion service that peers reach us
+	// at. The ethereum and monero clients are configured with the proxy separately.
+	Proxy      *common.Proxy
+	TorControl *net.TorControlConfig
+
+	// PriceMaxStaleness is the maximum age of the prices that the exchange rates of
+	// pegged offers are resolved with, pricefeed.DefaultMaxStaleness if zero.
+	PriceMaxStaleness time.Duration
+
+	// PegMaxDeviation is the percent by which the exchange rates that takers take
+	// pegged offers at can be below their current exchange rate,
+	// xmrmaker.DefaultPegMaxDeviation if nil.
+	PegMaxDeviation *apd.Decimal
+
+	// PriceSource is the price source of the exchange rate that swapd suggests,
+	// chainlink if empty.
+	PriceSource string
+
+	// MarketMaker, if set, keeps offers open at the market exchange rate plus a
+	// spread, pausing quoting when the inventory or gas balance limits are breached.
+	MarketMaker *xmrmaker.MarketMakerConfig
+
+	// NoPortMapping disables forwarding the libp2p port on the router with UPnP or
+	// NAT-PMP.
+	NoPortMapping bool
+
+	// MDNS enables discovering the swapd instances on the local network.
+	MDNS bool
+
+	// ConnLimits bounds the peer connections, net.DefaultConnLimits() if nil.
+	ConnLimits *net.ConnLimits
+
+	// StaticPeers are the addresses of the peers that we always stay connected to.
+	StaticPeers []string
+
+	// ListenAddrs, if set, are the libp2p multiaddrs that we listen on instead of
+	// all the interfaces at Libp2pPort.
+	ListenAddrs []string
+
+	// AnnounceAddrs are the libp2p multiaddrs that we advertise to peers in addition
+	// to the ones that we listen on.
+	AnnounceAddrs []string
+
+	// RPCAuthTokens are the optional bearer tokens of the RPC server, whose requests
+	// are not authenticated if it is empty.
+	RPCAuthTokens []*rpc.AuthToken
+
+	// RPCTLSCertFile and RPCTLSKeyFile, if set, are the certificate and key with
+	// which the RPC server serves HTTPS and WSS.
+	RPCTLSCertFile string
+	RPCTLSKeyFile  string
+
+	// RPCUnixSocket, if set, is the path of a unix socket on which the RPC server
+	// also serves, or only serves if RPCUnixOnly is set.
+	RPCUnixSocket string
+	RPCUnixOnly   bool
+
+	// Webhooks are the URLs that the swap lifecycle events are POSTed to, in
+	// addition to the webhooks registered over RPC.
+	Webhooks []*webhook.Webhook
+
+	// RPCPublicAddress, if set, is the "IP:port" on which the RPC server also
+	// serves the public, read-only methods without authentication.
+	RPCPublicAddress string
+
+	// RPCCORS is the policy deciding which web frontends can call the RPC server
+	// from a browser, the default policy if nil.
+	RPCCORS *rpc.CORSConfig
+}
+
+// UserOpConfig configures submitting relayed claims as ERC-4337 user operations
+// through a deployed SwapClaimAccount contract.
+type UserOpConfig struct {
+	BundlerEndpoint string
+	EntryPoint      ethcommon.Address
+	ClaimAccount    ethcommon.Address
 }
 
 // RunSwapDaemon assembles and runs a swapd instance blocking until swapd is
@@ -57,6 +173,14 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		panic("swap creator address not specified")
 	}
 
+	if conf.MoneroClient == nil {
+		conf.MoneroClient, err = waitForMoneroUnlock(ctx, conf)
+		if err != nil {
+			return err
+		}
+		defer conf.MoneroClient.Close()
+	}
+
 	ec := conf.EthereumClient
 	chainID := ec.ChainID()
 
@@ -74,7 +198,10 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		}
 	}()
 
-	sm, err := swap.NewManager(sdb)
+	ec.SetTokenInfoCache(sdb)
+	go warmTokenInfoCache(ctx, ec, sdb)
+
+	webhooks, err := webhook.NewDispatcher(ctx, conf.Webhooks, conf.Proxy)
 	if err != nil {
 		return err
 	}
@@ -91,8 +218,19 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		KeyFile:    conf.Libp2pKeyfile,
 		Bootnodes:  conf.EnvConf.Bootnodes,
 		ProtocolID: fmt.Sprintf("%s/%d", net.ProtocolID, chainID.Int64()),
+		Version:    cliutil.GetVersion(),
 		ListenIP:   hostListenIP,
 		IsRelayer:  conf.IsRelayer,
+
+		RelayAccessListFile: conf.RelayAccessListFile,
+		Proxy:               conf.Proxy,
+		TorControl:          conf.TorControl,
+		NoPortMapping:       conf.NoPortMapping,
+		MDNS:                conf.MDNS,
+		ConnLimits:          conf.ConnLimits,
+		StaticPeers:         conf.StaticPeers,
+		ListenAddrs:         conf.ListenAddrs,
+		AnnounceAddrs:       conf.AnnounceAddrs,
 	})
 	if err != nil {
 		return err
@@ -103,6 +241,38 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		}
 	}()
 
+	// the host bans the peers that repeatedly fail to complete swaps
+	sm, err := swap.NewManager(sdb, swap.EventListeners{webhooks, host})
+	if err != nil {
+		return err
+	}
+
+	var userOpAccount *erc4337.ClaimAccount
+	if conf.UserOps != nil {
+		bundler, bundlerErr := erc4337.NewBundlerClient(
+			ctx,
+			conf.UserOps.BundlerEndpoint,
+			conf.UserOps.EntryPoint,
+			conf.Proxy,
+		)
+		if bundlerErr != nil {
+			return fmt.Errorf("failed to connect to bundler: %w", bundlerErr)
+		}
+		defer bundler.Close()
+
+		userOpAccount, err = erc4337.NewClaimAccount(
+			ctx,
+			ec.Raw(),
+			bundler,
+			conf.UserOps.ClaimAccount,
+			conf.EnvConf.SwapCreatorAddr,
+		)
+		if err != nil {
+			return err
+		}
+		log.Infof("relayed claims are submitted as user operations through claim account %s", userOpAccount.Address())
+	}
+
 	swapBackend, err := backend.NewBackend(&backend.Config{
 		Ctx:             ctx,
 		MoneroClient:    conf.MoneroClient,
@@ -111,6 +281,15 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		SwapCreatorAddr: conf.EnvConf.SwapCreatorAddr,
 		SwapManager:     sm,
 		RecoveryDB:      sdb.RecoveryDB(),
+		ContractEvents:  sdb,
+		RelayerStats:    sdb,
+		RelayedClaims:   sdb,
+		UserOpAccount:   userOpAccount,
+		RelayerFee:      conf.RelayerFee,
+		RelayerBatch:    conf.RelayerBatch,
+		DirectClaim:     conf.DirectClaim,
+		XMRPriority:     conf.XMRPriority,
+		Profiles:        conf.Profiles,
 		Net:             host,
 	})
 	if err != nil {
@@ -121,6 +300,35 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		swapBackend.XMRClient().Endpoint(),
 		conf.EthereumClient.Endpoint(),
 	)
+	for _, p := range conf.Profiles {
+		log.Infof("profile %s uses monero address %s and ethereum address %s",
+			p.Name, p.XMRClient.PrimaryAddress(), p.ETHClient.Address())
+	}
+
+	// The indexer backfills any contract events that were emitted while swapd was
+	// offline in the background. The maker and taker instances reconcile the ongoing
+	// swaps they recover with the indexed events, falling back to the contract's logs
+	// for events that aren't indexed yet.
+	// dev chains only mine blocks for new transactions, so the events of the last
+	// blocks would never be confirmed
+	confirmations := uint64(indexer.DefaultConfirmations)
+	if conf.EnvConf.Env == common.Development {
+		confirmations = 0
+	}
+
+	contractIndexer, err := indexer.NewIndexer(&indexer.Config{
+		Ctx:             ctx,
+		EthClient:       ec.Raw(),
+		SwapCreatorAddr: conf.EnvConf.SwapCreatorAddr,
+		StartBlock:      conf.EnvConf.SwapCreatorDeployBlock,
+		Confirmations:   confirmations,
+		Database:        sdb,
+	})
+	if err != nil {
+		return err
+	}
+	contractIndexer.Start()
+	defer contractIndexer.Stop()
 
 	xmrTaker, err := xmrtaker.NewInstance(&xmrtaker.Config{
 		Backend:        swapBackend,
@@ -131,11 +339,24 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		return err
 	}
 
+	priceSource := conf.PriceSource
+	if priceSource == "" {
+		priceSource = pricefeed.SourceChainlink
+	}
+	priceOracle := pricefeed.NewOracle(ec.Raw(), conf.Proxy, conf.PriceMaxStaleness)
+	suggestedRateOracle, err := priceOracle.PriceOracle(priceSource)
+	if err != nil {
+		return err
+	}
+
 	xmrMaker, err := xmrmaker.NewInstance(&xmrmaker.Config{
-		Backend:  swapBackend,
-		DataDir:  conf.EnvConf.DataDir,
-		Database: sdb,
-		Network:  host,
+		Backend:         swapBackend,
+		DataDir:         conf.EnvConf.DataDir,
+		Database:        sdb,
+		Network:         host,
+		PriceOracle:     priceOracle,
+		MarketMaker:     conf.MarketMaker,
+		PegMaxDeviation: conf.PegMaxDeviation,
 	})
 	if err != nil {
 		return err
@@ -147,15 +368,37 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		return err
 	}
 
+	tokenRegistry := coins.NewTokenRegistry(conf.EnvConf.EthereumChainID)
+	if conf.TokenList != nil {
+		tokenRegistry.AddTokenList(conf.TokenList)
+	}
+
 	rpcServer, err := rpc.NewServer(&rpc.Config{
 		Ctx:             ctx,
-		Address:         fmt.Sprintf("127.0.0.1:%d", conf.RPCPort),
+		Address:         conf.rpcAddress(),
+		PublicAddress:   conf.RPCPublicAddress,
 		Net:             host,
 		XMRTaker:        xmrTaker,
 		XMRMaker:        xmrMaker,
 		ProtocolBackend: swapBackend,
 		RecoveryDB:      sdb.RecoveryDB(),
+		ContractEvents:  sdb,
+		RelayerStats:    sdb,
+		RelayedClaims:   sdb,
+		TokenInfoCache:  sdb,
+		RelayAccess:     host,
+		Webhooks:        webhooks,
 		Namespaces:      rpc.AllNamespaces(),
+
+		EthKeyFile:          conf.EthKeyFile,
+		EthKeystorePassword: conf.EthKeystorePassword,
+		TokenRegistry:       tokenRegistry,
+		PriceOracle:         suggestedRateOracle,
+		AuthTokens:          conf.RPCAuthTokens,
+		TLSCertFile:         conf.RPCTLSCertFile,
+		TLSKeyFile:          conf.RPCTLSKeyFile,
+		CORS:                conf.RPCCORS,
+		UnixSocket:          conf.RPCUnixSocket,
 	})
 
 	log.Infof("starting swapd with data-dir %s", conf.EnvConf.DataDir)
@@ -171,3 +414,67 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 	// return statement below (not nil)
 	return err
 }
+
+// warmTokenInfoCache looks up the metadata of the tokens of our stored offers, so it
+// is cached before the offers are queried or taken.
+func warmTokenInfoCache(ctx context.Context, ec extethclient.EthClient, sdb *db.Database) {
+	offers, err := sdb.GetAllOffers()
+	if err != nil {
+		log.Warnf("failed to get offers to cache their token metadata: %s", err)
+		return
+	}
+
+	for _, offer := range offers {
+		if ctx.Err() != nil {
+			return
+		}
+		if !offer.EthAsset.IsToken() {
+			continue
+		}
+
+		if _, err = ec.ERC20Info(ctx, offer.EthAsset.Address()); err != nil {
+			log.Warnf("failed to cache metadata of token %s: %s", offer.EthAsset, err)
+		}
+	}
+}
+
+// rpcAddress returns the "IP:port" address of the RPC server, or an empty string if
+// it only serves on the unix socket.
+func (conf *SwapdConfig) rpcAddress() string {
+	if conf.RPCUnixOnly {
+		return ""
+	}
+	return fmt.Sprintf("127.0.0.1:%d", conf.RPCPort)
+}
+
+// waitForMoneroUnlock serves personal_unlock on the RPC address until a request
+// supplies the password that opens the Monero wallet.
+func waitForMoneroUnlock(ctx context.Context, conf *SwapdConfig) (monero.WalletClient, error) {
+	if conf.UnlockMoneroWallet == nil {
+		panic("monero client or unlock function not specified")
+	}
+
+	var mc monero.WalletClient
+	err := rpc.WaitForUnlock(&rpc.UnlockConfig{
+		Ctx:         ctx,
+		Address:     conf.rpcAddress(),
+		UnixSocket:  conf.RPCUnixSocket,
+		TLSCertFile: conf.RPCTLSCertFile,
+		TLSKeyFile:  conf.RPCTLSKeyFile,
+		CORS:        conf.RPCCORS,
+		AuthTokens:  conf.RPCAuthTokens,
+		Unlock: func(password string) error {
+			var err error
+			mc, err = conf.UnlockMoneroWallet(password)
+			return err
+		},
+	})
+	if err != nil {
+		if mc != nil {
+			mc.Close()
+		}
+		return nil, err
+	}
+
+	return mc, nil
+}
@@ -48,7 +48,7 @@ func TestRunSwapDaemon_ExchangesXMRForERC20Tokens(t *testing.T) {
 
 	// Have Alice query all the offer information back
 	aRPC := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", aliceConf.RPCPort))
-	peersWithOffers, err := aRPC.QueryAll(coins.ProvidesXMR, 3)
+	peersWithOffers, err := aRPC.QueryAll(coins.ProvidesXMR, 3, nil)
 	require.NoError(t, err)
 	require.Len(t, peersWithOffers, 1)
 	require.Len(t, peersWithOffers[0].Offers, 1)
@@ -56,7 +56,8 @@ func TestRunSwapDaemon_ExchangesXMRForERC20Tokens(t *testing.T) {
 	offer := peersWithOffers[0].Offers[0]
 	tokenInfo, err := aRPC.TokenInfo(offer.EthAsset.Address())
 	require.NoError(t, err)
-	providesAmt, err := exRate.ToERC20Amount(offer.MaxAmount, tokenInfo)
+	require.False(t, tokenInfo.NonStandard)
+	providesAmt, err := exRate.ToERC20Amount(offer.MaxAmount, tokenInfo.ERC20TokenInfo)
 	require.NoError(t, err)
 
 	aliceStatusCh, err := ac.TakeOfferAndSubscribe(peerID, offer.ID, providesAmt)
@@ -184,7 +184,8 @@ func TestRunSwapDaemon_SwapBobHasNoEth_AliceRelaysClaim(t *testing.T) {
 	// Bob's ending balance should be Alice's provided amount minus the relayer fee
 	//
 	expectedBal := new(apd.Decimal)
-	_, err = coins.DecimalCtx().Sub(expectedBal, providesAmt, relayer.FeeEth)
+	relayerFee := coins.NewWeiAmount(relayer.DefaultFeeConfig().Fee(coins.EtherToWei(providesAmt).BigInt()))
+	_, err = coins.DecimalCtx().Sub(expectedBal, providesAmt, relayerFee.AsEther())
 	require.NoError(t, err)
 
 	bobBalance, err := bobConf.EthereumClient.Balance(ctx)
@@ -362,7 +363,8 @@ func TestRunSwapDaemon_CharlieRelays(t *testing.T) {
 	// Bob's ending balance should be Alice's provided amount minus the relayer fee
 	//
 	bobExpectedBal := new(apd.Decimal)
-	_, err = coins.DecimalCtx().Sub(bobExpectedBal, providesAmt, relayer.FeeEth)
+	relayerFee := coins.NewWeiAmount(relayer.DefaultFeeConfig().Fee(coins.EtherToWei(providesAmt).BigInt()))
+	_, err = coins.DecimalCtx().Sub(bobExpectedBal, providesAmt, relayerFee.AsEther())
 	require.NoError(t, err)
 	bobBalance, err := bobConf.EthereumClient.Balance(ctx)
 	require.NoError(t, err)
@@ -467,7 +469,8 @@ func TestRunSwapDaemon_CharlieIsBroke_AliceRelays(t *testing.T) {
 	// Bob's ending balance should be Alice's provided amount minus the relayer fee
 	//
 	bobExpectedBal := new(apd.Decimal)
-	_, err = coins.DecimalCtx().Sub(bobExpectedBal, providesAmt, relayer.FeeEth)
+	relayerFee := coins.NewWeiAmount(relayer.DefaultFeeConfig().Fee(coins.EtherToWei(providesAmt).BigInt()))
+	_, err = coins.DecimalCtx().Sub(bobExpectedBal, providesAmt, relayerFee.AsEther())
 	require.NoError(t, err)
 	bobBalance, err := bobConf.EthereumClient.Balance(ctx)
 	require.NoError(t, err)
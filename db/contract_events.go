@@ -0,0 +1,163 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+const (
+	contractEventPrefix = "cevent"
+	indexedBlockPrefix  = "cindexed"
+
+	contractEventKeyLength = ethcommon.AddressLength + 8 + 4
+
+	// the indexed block is stored as its number followed by its hash
+	indexedBlockLength = 8 + ethcommon.HashLength
+)
+
+var errInvalidIndexedBlock = errors.New("invalid indexed block number in database")
+
+func getContractEventKey(contractAddr ethcommon.Address, event *ContractEvent) []byte {
+	key := make([]byte, contractEventKeyLength)
+	copy(key, contractAddr[:])
+	binary.BigEndian.PutUint64(key[ethcommon.AddressLength:], event.BlockNumber)
+	binary.BigEndian.PutUint32(key[ethcommon.AddressLength+8:], uint32(event.LogIndex))
+	return key
+}
+
+// PutContractEvents stores the given events of the contract at contractAddr and
+// records indexedBlock, whose hash is indexedBlockHash, as the last block whose
+// events have been stored. Storing an event that is already in the database
+// overwrites it.
+func (db *Database) PutContractEvents(
+	contractAddr ethcommon.Address,
+	events []*ContractEvent,
+	indexedBlock uint64,
+	indexedBlockHash ethcommon.Hash,
+) error {
+	for _, event := range events {
+		val, err := vjson.MarshalStruct(event)
+		if err != nil {
+			return err
+		}
+
+		err = db.contractEventTable.Put(getContractEventKey(contractAddr, event), val)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := db.contractEventTable.Flush(); err != nil {
+		return err
+	}
+
+	return db.putIndexedBlock(contractAddr, indexedBlock, indexedBlockHash)
+}
+
+// RollbackContractEvents deletes the stored events of the contract at contractAddr
+// that were emitted after the block toBlock, whose hash is toBlockHash, and records
+// toBlock as the last block whose events have been stored. It is used when the
+// blocks after toBlock were reorganized out of the chain.
+func (db *Database) RollbackContractEvents(
+	contractAddr ethcommon.Address,
+	toBlock uint64,
+	toBlockHash ethcommon.Hash,
+) error {
+	iter := db.contractEventTable.NewIterator()
+	var deleteKeys [][]byte
+	for ; iter.Valid(); iter.Next() {
+		key := iter.Key()
+		if len(key) != contractEventKeyLength || !bytes.HasPrefix(key, contractAddr[:]) {
+			continue
+		}
+
+		if binary.BigEndian.Uint64(key[ethcommon.AddressLength:]) > toBlock {
+			deleteKeys = append(deleteKeys, bytes.Clone(key))
+		}
+	}
+	iter.Release()
+
+	for _, key := range deleteKeys {
+		if err := db.contractEventTable.Del(key); err != nil {
+			return err
+		}
+	}
+
+	if err := db.contractEventTable.Flush(); err != nil {
+		return err
+	}
+
+	return db.putIndexedBlock(contractAddr, toBlock, toBlockHash)
+}
+
+func (db *Database) putIndexedBlock(contractAddr ethcommon.Address, number uint64, hash ethcommon.Hash) error {
+	val := make([]byte, indexedBlockLength)
+	binary.BigEndian.PutUint64(val, number)
+	copy(val[8:], hash[:])
+	if err := db.indexedBlockTable.Put(contractAddr[:], val); err != nil {
+		return err
+	}
+
+	return db.indexedBlockTable.Flush()
+}
+
+// GetIndexedBlock returns the number and hash of the last block whose events of the
+// contract at contractAddr have been stored. The hash is zero if it was stored by a
+// version of swapd that didn't record it. Returns the error chaindb.ErrKeyNotFound
+// if no events of the contract have been indexed yet.
+func (db *Database) GetIndexedBlock(contractAddr ethcommon.Address) (uint64, ethcommon.Hash, error) {
+	val, err := db.indexedBlockTable.Get(contractAddr[:])
+	if err != nil {
+		return 0, ethcommon.Hash{}, err
+	}
+
+	switch len(val) {
+	case 8:
+		return binary.BigEndian.Uint64(val), ethcommon.Hash{}, nil
+	case indexedBlockLength:
+		return binary.BigEndian.Uint64(val), ethcommon.BytesToHash(val[8:]), nil
+	default:
+		return 0, ethcommon.Hash{}, errInvalidIndexedBlock
+	}
+}
+
+// GetContractEvents returns the stored events of the contract at contractAddr in
+// the order they were emitted. If swapID is non-nil, only the events of that swap
+// are returned.
+func (db *Database) GetContractEvents(
+	contractAddr ethcommon.Address,
+	swapID *types.Hash,
+) ([]*ContractEvent, error) {
+	iter := db.contractEventTable.NewIterator()
+	defer iter.Release()
+
+	var events []*ContractEvent
+	for ; iter.Valid(); iter.Next() {
+		key := iter.Key()
+		if len(key) != contractEventKeyLength || !bytes.HasPrefix(key, contractAddr[:]) {
+			continue
+		}
+
+		event := new(ContractEvent)
+		if err := vjson.UnmarshalStruct(iter.Value(), event); err != nil {
+			return nil, err
+		}
+
+		if swapID != nil && event.SwapID != *swapID {
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
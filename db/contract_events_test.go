@@ -0,0 +1,154 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestDatabase_ContractEvents(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	contractA := ethcommon.Address{0xa}
+	contractB := ethcommon.Address{0xb}
+
+	_, _, err = db.GetIndexedBlock(contractA)
+	require.True(t, errors.Is(err, chaindb.ErrKeyNotFound))
+
+	swapID1 := types.Hash{0x1}
+	swapID2 := types.Hash{0x2}
+	secret := types.Hash{0x99}
+
+	newEvent := &ContractEvent{
+		Type:             ContractEventNew,
+		SwapID:           swapID1,
+		BlockNumber:      10,
+		TxHash:           ethcommon.Hash{0x10},
+		LogIndex:         3,
+		ClaimCommitment:  &types.Hash{0x3},
+		RefundCommitment: &types.Hash{0x4},
+		Timeout0:         big.NewInt(100),
+		Timeout1:         big.NewInt(200),
+		Asset:            &ethcommon.Address{},
+		Value:            big.NewInt(1e18),
+	}
+	readyEvent := &ContractEvent{
+		Type:        ContractEventReady,
+		SwapID:      swapID1,
+		BlockNumber: 11,
+		TxHash:      ethcommon.Hash{0x11},
+	}
+	otherNewEvent := &ContractEvent{
+		Type:        ContractEventNew,
+		SwapID:      swapID2,
+		BlockNumber: 10,
+		TxHash:      ethcommon.Hash{0x12},
+		LogIndex:    7,
+	}
+	claimedEvent := &ContractEvent{
+		Type:        ContractEventClaimed,
+		SwapID:      swapID1,
+		BlockNumber: 12,
+		TxHash:      ethcommon.Hash{0x13},
+		Secret:      &secret,
+	}
+
+	// insert out of order to check that events are returned in chain order
+	block12Hash := ethcommon.Hash{0x12}
+	err = db.PutContractEvents(contractA, []*ContractEvent{claimedEvent, readyEvent}, 12, block12Hash)
+	require.NoError(t, err)
+	err = db.PutContractEvents(contractA, []*ContractEvent{otherNewEvent, newEvent}, 12, block12Hash)
+	require.NoError(t, err)
+	err = db.PutContractEvents(contractB, []*ContractEvent{readyEvent}, 20, ethcommon.Hash{0x20})
+	require.NoError(t, err)
+
+	indexedBlock, indexedHash, err := db.GetIndexedBlock(contractA)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), indexedBlock)
+	require.Equal(t, block12Hash, indexedHash)
+
+	events, err := db.GetContractEvents(contractA, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*ContractEvent{newEvent, otherNewEvent, readyEvent, claimedEvent}, events)
+
+	events, err = db.GetContractEvents(contractA, &swapID1)
+	require.NoError(t, err)
+	require.Equal(t, []*ContractEvent{newEvent, readyEvent, claimedEvent}, events)
+
+	events, err = db.GetContractEvents(contractB, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*ContractEvent{readyEvent}, events)
+
+	events, err = db.GetContractEvents(ethcommon.Address{0xc}, nil)
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestDatabase_RollbackContractEvents(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	contractA := ethcommon.Address{0xa}
+	contractB := ethcommon.Address{0xb}
+
+	newEvent := &ContractEvent{
+		Type:        ContractEventNew,
+		SwapID:      types.Hash{0x1},
+		BlockNumber: 10,
+		TxHash:      ethcommon.Hash{0x10},
+	}
+	readyEvent := &ContractEvent{
+		Type:        ContractEventReady,
+		SwapID:      types.Hash{0x1},
+		BlockNumber: 11,
+		TxHash:      ethcommon.Hash{0x11},
+	}
+	claimedEvent := &ContractEvent{
+		Type:        ContractEventClaimed,
+		SwapID:      types.Hash{0x1},
+		BlockNumber: 12,
+		TxHash:      ethcommon.Hash{0x12},
+		Secret:      &types.Hash{0x99},
+	}
+
+	err = db.PutContractEvents(contractA, []*ContractEvent{newEvent, readyEvent, claimedEvent}, 12, ethcommon.Hash{0x12})
+	require.NoError(t, err)
+	err = db.PutContractEvents(contractB, []*ContractEvent{readyEvent}, 12, ethcommon.Hash{0x12})
+	require.NoError(t, err)
+
+	// blocks 11 and 12 were reorganized out of the chain
+	block10Hash := ethcommon.Hash{0x10}
+	require.NoError(t, db.RollbackContractEvents(contractA, 10, block10Hash))
+
+	indexedBlock, indexedHash, err := db.GetIndexedBlock(contractA)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), indexedBlock)
+	require.Equal(t, block10Hash, indexedHash)
+
+	events, err := db.GetContractEvents(contractA, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*ContractEvent{newEvent}, events)
+
+	// the events of other contracts are kept
+	events, err = db.GetContractEvents(contractB, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*ContractEvent{readyEvent}, events)
+}
@@ -6,6 +6,7 @@ package db
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/ChainSafe/chaindb"
 	logging "github.com/ipfs/go-log"
@@ -46,6 +47,39 @@ type Database struct {
 	// it contains information about ongoing swaps required to recover funds
 	// in case of a node crash, or any other problem.
 	recoveryDB *RecoveryDB
+
+	// contractEventTable is a key-value store where all the keys are prefixed by
+	// contractEventPrefix in the underlying database.
+	// the key is the 20-byte contract address, followed by the 8-byte block number
+	// and 4-byte log index of the event, both big-endian, so that iteration returns
+	// events in chain order. The value is a JSON-marshalled *ContractEvent.
+	contractEventTable chaindb.Database
+
+	// indexedBlockTable is a key-value store where all the keys are prefixed by
+	// indexedBlockPrefix in the underlying database.
+	// the key is the 20-byte contract address and the value is the big-endian
+	// number of the last block whose events were stored in contractEventTable.
+	indexedBlockTable chaindb.Database
+
+	// relayerStatsTable is a key-value store where all the keys are prefixed by
+	// relayerStatsPrefix in the underlying database.
+	// the key is the peer ID of a relayer that we submitted claims to and the
+	// value is a JSON-marshalled *RelayerStats.
+	relayerStatsTable chaindb.Database
+	relayerStatsMu    sync.Mutex
+
+	// relayedClaimTable is a key-value store where all the keys are prefixed by
+	// relayedClaimPrefix in the underlying database.
+	// the key is the swap ID of a claim that we relayed for another node and the
+	// value is a JSON-marshalled *RelayedClaim.
+	relayedClaimTable chaindb.Database
+
+	// tokenInfoTable is a key-value store where all the keys are prefixed by
+	// tokenInfoPrefix in the underlying database.
+	// the key is the 8-byte big-endian chain ID followed by the 20-byte token
+	// address, and the value is the JSON-marshalled *coins.ERC20TokenInfo of the
+	// token, which caches its on-chain metadata.
+	tokenInfoTable chaindb.Database
 }
 
 // NewDatabase returns a new *Database.
@@ -61,6 +95,12 @@ func NewDatabase(cfg *chaindb.Config) (*Database, error) {
 		offerTable: chaindb.NewTable(db, offerPrefix),
 		swapTable:  chaindb.NewTable(db, swapPrefix),
 		recoveryDB: recoveryDB,
+
+		contractEventTable: chaindb.NewTable(db, contractEventPrefix),
+		indexedBlockTable:  chaindb.NewTable(db, indexedBlockPrefix),
+		relayerStatsTable:  chaindb.NewTable(db, relayerStatsPrefix),
+		relayedClaimTable:  chaindb.NewTable(db, relayedClaimPrefix),
+		tokenInfoTable:     chaindb.NewTable(db, tokenInfoPrefix),
 	}, nil
 }
 
@@ -76,6 +116,31 @@ func (db *Database) Close() error {
 		return err
 	}
 
+	err = db.contractEventTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.indexedBlockTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.relayerStatsTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.relayedClaimTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.tokenInfoTable.Close()
+	if err != nil {
+		return err
+	}
+
 	return db.recoveryDB.close()
 }
 
@@ -0,0 +1,47 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"sort"
+
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+const relayedClaimPrefix = "relayed"
+
+// PutRelayedClaim stores a claim that we relayed.
+func (db *Database) PutRelayedClaim(claim *RelayedClaim) error {
+	val, err := vjson.MarshalStruct(claim)
+	if err != nil {
+		return err
+	}
+
+	if err = db.relayedClaimTable.Put(claim.SwapID[:], val); err != nil {
+		return err
+	}
+
+	return db.relayedClaimTable.Flush()
+}
+
+// GetAllRelayedClaims returns all the claims that we relayed, oldest first.
+func (db *Database) GetAllRelayedClaims() ([]*RelayedClaim, error) {
+	iter := db.relayedClaimTable.NewIterator()
+	defer iter.Release()
+
+	var claims []*RelayedClaim
+	for ; iter.Valid(); iter.Next() {
+		claim := new(RelayedClaim)
+		if err := vjson.UnmarshalStruct(iter.Value(), claim); err != nil {
+			return nil, err
+		}
+		claims = append(claims, claim)
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		return claims[i].Time.Before(claims[j].Time)
+	})
+
+	return claims, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestDatabase_RelayedClaims(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	claims, err := db.GetAllRelayedClaims()
+	require.NoError(t, err)
+	require.Empty(t, claims)
+
+	now := time.Now()
+	newer := &RelayedClaim{
+		SwapID:     types.Hash{0x01},
+		TxHash:     [32]byte{0x02},
+		FeeWei:     big.NewInt(5e15),
+		GasCostWei: big.NewInt(1e15),
+		Time:       now,
+	}
+	older := &RelayedClaim{
+		SwapID:     types.Hash{0x03},
+		TxHash:     [32]byte{0x04},
+		FeeWei:     big.NewInt(1e15),
+		GasCostWei: big.NewInt(2e15),
+		Time:       now.Add(-24 * time.Hour),
+	}
+	require.NoError(t, db.PutRelayedClaim(newer))
+	require.NoError(t, db.PutRelayedClaim(older))
+
+	claims, err = db.GetAllRelayedClaims()
+	require.NoError(t, err)
+	require.Len(t, claims, 2)
+	require.Equal(t, older.SwapID, claims[0].SwapID)
+	require.Equal(t, older.GasCostWei, claims[0].GasCostWei)
+	require.Equal(t, newer.TxHash, claims[1].TxHash)
+	require.Equal(t, newer.FeeWei, claims[1].FeeWei)
+}
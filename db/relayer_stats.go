@@ -0,0 +1,91 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+const relayerStatsPrefix = "rstats"
+
+// RecordRelayerOutcome increments the counter of the outcome in the relayer's
+// stats, creating the stats if this is the first outcome of the relayer.
+func (db *Database) RecordRelayerOutcome(peerID peer.ID, outcome RelayerOutcome) error {
+	db.relayerStatsMu.Lock()
+	defer db.relayerStatsMu.Unlock()
+
+	stats, err := db.GetRelayerStats(peerID)
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		stats = &RelayerStats{PeerID: peerID}
+	} else if err != nil {
+		return err
+	}
+
+	switch outcome {
+	case RelayerOutcomeAccepted:
+		stats.Accepted++
+	case RelayerOutcomeSubmitted:
+		stats.Submitted++
+	case RelayerOutcomeConfirmed:
+		stats.Confirmed++
+	case RelayerOutcomeFailed:
+		stats.Failed++
+	case RelayerOutcomeTimedOut:
+		stats.TimedOut++
+	default:
+		return fmt.Errorf("invalid relayer outcome %q", outcome)
+	}
+	stats.LastUpdated = time.Now()
+
+	val, err := vjson.MarshalStruct(stats)
+	if err != nil {
+		return err
+	}
+
+	if err = db.relayerStatsTable.Put([]byte(peerID), val); err != nil {
+		return err
+	}
+
+	return db.relayerStatsTable.Flush()
+}
+
+// GetRelayerStats returns the stats of the relayer. Returns the error
+// chaindb.ErrKeyNotFound if no outcomes of the relayer have been recorded.
+func (db *Database) GetRelayerStats(peerID peer.ID) (*RelayerStats, error) {
+	val, err := db.relayerStatsTable.Get([]byte(peerID))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := new(RelayerStats)
+	if err = vjson.UnmarshalStruct(val, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetAllRelayerStats returns the stats of all relayers with recorded outcomes.
+func (db *Database) GetAllRelayerStats() ([]*RelayerStats, error) {
+	iter := db.relayerStatsTable.NewIterator()
+	defer iter.Release()
+
+	var all []*RelayerStats
+	for ; iter.Valid(); iter.Next() {
+		stats := new(RelayerStats)
+		if err := vjson.UnmarshalStruct(iter.Value(), stats); err != nil {
+			return nil, err
+		}
+		all = append(all, stats)
+	}
+
+	return all, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	libp2ptest "github.com/libp2p/go-libp2p/core/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_RelayerStats(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	relayerA, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+	relayerB, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+
+	_, err = db.GetRelayerStats(relayerA)
+	require.True(t, errors.Is(err, chaindb.ErrKeyNotFound))
+
+	for _, outcome := range []RelayerOutcome{
+		RelayerOutcomeAccepted,
+		RelayerOutcomeSubmitted,
+		RelayerOutcomeConfirmed,
+		RelayerOutcomeAccepted,
+		RelayerOutcomeTimedOut,
+	} {
+		require.NoError(t, db.RecordRelayerOutcome(relayerA, outcome))
+	}
+	require.NoError(t, db.RecordRelayerOutcome(relayerB, RelayerOutcomeFailed))
+	require.Error(t, db.RecordRelayerOutcome(relayerB, "unknown"))
+
+	stats, err := db.GetRelayerStats(relayerA)
+	require.NoError(t, err)
+	require.Equal(t, relayerA, stats.PeerID)
+	require.Equal(t, uint64(2), stats.Accepted)
+	require.Equal(t, uint64(1), stats.Submitted)
+	require.Equal(t, uint64(1), stats.Confirmed)
+	require.Equal(t, uint64(0), stats.Failed)
+	require.Equal(t, uint64(1), stats.TimedOut)
+	require.Equal(t, 0.5, stats.Score())
+
+	stats, err = db.GetRelayerStats(relayerB)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), stats.Failed)
+	require.InDelta(t, 1.0/3, stats.Score(), 1e-9)
+
+	all, err := db.GetAllRelayerStats()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+}
+
+func TestRelayerStats_Score(t *testing.T) {
+	var noHistory *RelayerStats
+	require.Equal(t, 0.5, noHistory.Score())
+
+	reliable := &RelayerStats{Confirmed: 8}
+	require.Equal(t, 0.9, reliable.Score())
+}
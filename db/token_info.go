@@ -0,0 +1,87 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+const tokenInfoPrefix = "tokeninfo"
+
+// tokenInfoKey returns the key of a token's metadata in tokenInfoTable.
+func tokenInfoKey(chainID *big.Int, tokenAddr ethcommon.Address) []byte {
+	key := make([]byte, 8, 8+ethcommon.AddressLength)
+	binary.BigEndian.PutUint64(key, chainID.Uint64())
+	return append(key, tokenAddr[:]...)
+}
+
+// PutTokenInfo stores the metadata of the token on the chain, replacing any that was
+// stored before.
+func (db *Database) PutTokenInfo(chainID *big.Int, tokenInfo *coins.ERC20TokenInfo) error {
+	val, err := vjson.MarshalStruct(tokenInfo)
+	if err != nil {
+		return err
+	}
+
+	if err = db.tokenInfoTable.Put(tokenInfoKey(chainID, tokenInfo.Address), val); err != nil {
+		return err
+	}
+
+	return db.tokenInfoTable.Flush()
+}
+
+// GetTokenInfo returns the stored metadata of the token on the chain. Returns the
+// error chaindb.ErrKeyNotFound if the token's metadata is not stored.
+func (db *Database) GetTokenInfo(chainID *big.Int, tokenAddr ethcommon.Address) (*coins.ERC20TokenInfo, error) {
+	val, err := db.tokenInfoTable.Get(tokenInfoKey(chainID, tokenAddr))
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo := new(coins.ERC20TokenInfo)
+	if err = vjson.UnmarshalStruct(val, tokenInfo); err != nil {
+		return nil, err
+	}
+
+	return tokenInfo, nil
+}
+
+// DeleteTokenInfo removes the stored metadata of the token on the chain, so it is
+// looked up again the next time it is needed. It returns whether any was stored.
+func (db *Database) DeleteTokenInfo(chainID *big.Int, tokenAddr ethcommon.Address) (bool, error) {
+	key := tokenInfoKey(chainID, tokenAddr)
+	has, err := db.tokenInfoTable.Has(key)
+	if err != nil || !has {
+		return false, err
+	}
+
+	if err = db.tokenInfoTable.Del(key); err != nil {
+		return false, err
+	}
+
+	return true, db.tokenInfoTable.Flush()
+}
+
+// ClearTokenInfo removes the stored metadata of all tokens and returns the number of
+// tokens that were removed.
+func (db *Database) ClearTokenInfo() (int, error) {
+	iter := db.tokenInfoTable.NewIterator()
+	defer iter.Release()
+
+	removed := 0
+	for ; iter.Valid(); iter.Next() {
+		if err := db.tokenInfoTable.Del(iter.Key()); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, db.tokenInfoTable.Flush()
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestDatabase_TokenInfo(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	mainnet := big.NewInt(1)
+	sepolia := big.NewInt(11155111)
+	dai := coins.NewERC20TokenInfo(ethcommon.Address{0x1}, 18, "Dai Stablecoin", "DAI")
+	usdc := coins.NewERC20TokenInfo(ethcommon.Address{0x2}, 6, "USD Coin", "USDC")
+
+	_, err = db.GetTokenInfo(mainnet, dai.Address)
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+
+	require.NoError(t, db.PutTokenInfo(mainnet, dai))
+	require.NoError(t, db.PutTokenInfo(mainnet, usdc))
+
+	tokenInfo, err := db.GetTokenInfo(mainnet, dai.Address)
+	require.NoError(t, err)
+	require.Equal(t, dai, tokenInfo)
+
+	// the metadata is cached per chain
+	_, err = db.GetTokenInfo(sepolia, dai.Address)
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+
+	deleted, err := db.DeleteTokenInfo(mainnet, dai.Address)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	deleted, err = db.DeleteTokenInfo(mainnet, dai.Address)
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	removed, err := db.ClearTokenInfo()
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	_, err = db.GetTokenInfo(mainnet, usdc.Address)
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+}
@@ -5,6 +5,9 @@ package db
 
 import (
 	"math/big"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
@@ -29,3 +32,94 @@ type EthereumSwapInfo struct {
 	// SwapCreatorAddr is the address of the contract on which the swap was created.
 	SwapCreatorAddr ethcommon.Address `json:"swapCreatorAddr" validate:"required"`
 }
+
+// ContractEventType is the name of an event emitted by SwapCreator.sol.
+type ContractEventType string
+
+// Events emitted by SwapCreator.sol that are stored by the contract event indexer.
+const (
+	ContractEventNew      ContractEventType = "New"
+	ContractEventReady    ContractEventType = "Ready"
+	ContractEventClaimed  ContractEventType = "Claimed"
+	ContractEventRefunded ContractEventType = "Refunded"
+)
+
+// ContractEvent is a SwapCreator.sol event log, as stored by the contract event
+// indexer.
+type ContractEvent struct {
+	Type        ContractEventType `json:"type" validate:"required"`
+	SwapID      types.Hash        `json:"swapID" validate:"required"`
+	BlockNumber uint64            `json:"blockNumber" validate:"required"`
+	TxHash      ethcommon.Hash    `json:"txHash" validate:"required"`
+	LogIndex    uint              `json:"logIndex"`
+
+	// The fields below are only set for New events.
+	ClaimCommitment  *types.Hash        `json:"claimCommitment,omitempty"`
+	RefundCommitment *types.Hash        `json:"refundCommitment,omitempty"`
+	Timeout0         *big.Int           `json:"timeout0,omitempty"`
+	Timeout1         *big.Int           `json:"timeout1,omitempty"`
+	Asset            *ethcommon.Address `json:"asset,omitempty"`
+	Value            *big.Int           `json:"value,omitempty"`
+
+	// Secret is the revealed secret of Claimed and Refunded events.
+	Secret *types.Hash `json:"secret,omitempty"`
+}
+
+// RelayerOutcome is a step or result of a claim submitted to a relayer.
+type RelayerOutcome string
+
+// Outcomes of claims submitted to relayers. Every submitted claim ends as
+// confirmed, failed or timed out.
+const (
+	// RelayerOutcomeAccepted is recorded when the relayer responds to the claim
+	// request with a transaction hash.
+	RelayerOutcomeAccepted RelayerOutcome = "accepted"
+	// RelayerOutcomeSubmitted is recorded when the relayer's transaction is
+	// found on the network.
+	RelayerOutcomeSubmitted RelayerOutcome = "submitted"
+	// RelayerOutcomeConfirmed is recorded when the relayer's transaction
+	// claimed the swap.
+	RelayerOutcomeConfirmed RelayerOutcome = "confirmed"
+	// RelayerOutcomeFailed is recorded when the relayer rejects the claim
+	// request, or its transaction is not found or does not claim the swap.
+	RelayerOutcomeFailed RelayerOutcome = "failed"
+	// RelayerOutcomeTimedOut is recorded when the relayer's transaction is not
+	// included in time.
+	RelayerOutcomeTimedOut RelayerOutcome = "timedOut"
+)
+
+// RelayerStats counts the outcomes of the claims submitted to a relayer.
+type RelayerStats struct {
+	PeerID      peer.ID   `json:"peerID" validate:"required"`
+	Accepted    uint64    `json:"accepted"`
+	Submitted   uint64    `json:"submitted"`
+	Confirmed   uint64    `json:"confirmed"`
+	Failed      uint64    `json:"failed"`
+	TimedOut    uint64    `json:"timedOut"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// Score returns the estimated probability that the next claim submitted to the
+// relayer is confirmed, between 0 and 1. It is the relayer's confirmation rate
+// with one success and one failure added, so relayers without history score 0.5
+// and a single outcome doesn't decide a relayer's score.
+func (s *RelayerStats) Score() float64 {
+	if s == nil {
+		return 0.5
+	}
+
+	attempts := s.Confirmed + s.Failed + s.TimedOut
+	return float64(s.Confirmed+1) / float64(attempts+2)
+}
+
+// RelayedClaim is a claim that we relayed for another node, along with what we
+// earned and spent relaying it. The fee of a token swap is paid in base units of
+// the token.
+type RelayedClaim struct {
+	SwapID     types.Hash     `json:"swapID" validate:"required"`
+	TxHash     ethcommon.Hash `json:"txHash" validate:"required"`
+	FeeAsset   types.EthAsset `json:"feeAsset"`
+	FeeWei     *big.Int       `json:"feeWei" validate:"required"`
+	GasCostWei *big.Int       `json:"gasCostWei" validate:"required"`
+	Time       time.Time      `json:"time" validate:"required"`
+}
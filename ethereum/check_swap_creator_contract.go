@@ -9,8 +9,6 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/athanorlabs/atomic-swap/common"
-
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -49,7 +47,10 @@ func CheckSwapCreatorContractCode(
 		return ethcommon.Address{}, err
 	}
 
-	expectedCode := ethcommon.FromHex(expectedSwapCreatorBytecodeHex)
+	// Ignore the compiler's metadata trailer, which changes with the source paths
+	// and other settings that don't affect the executed code.
+	code = stripCodeMetadata(code)
+	expectedCode := stripCodeMetadata(ethcommon.FromHex(expectedSwapCreatorBytecodeHex))
 
 	if len(code) != len(expectedCode) {
 		return ethcommon.Address{}, fmt.Errorf("length mismatch: %w", errInvalidSwapCreatorContract)
@@ -85,50 +86,93 @@ func CheckSwapCreatorContractCode(
 		return ethcommon.Address{}, errInvalidSwapCreatorContract
 	}
 
+	// the contract is valid either way, but only deterministic deployments have the
+	// same address on every chain
+	deterministicAddr, err := DeterministicSwapCreatorAddress(forwarderAddr)
+	if err != nil {
+		log.Warnf("failed to compute the deterministic SwapCreator.sol address: %s", err)
+	} else if contractAddr != deterministicAddr {
+		log.Warnf("SwapCreator.sol at %s is not a deterministic deployment, which would be at %s",
+			contractAddr, deterministicAddr)
+	}
+
 	if (forwarderAddr == ethcommon.Address{}) {
 		return forwarderAddr, nil
 	}
 
-	err = CheckForwarderContractCode(ctx, ec, forwarderAddr)
+	version, err := CheckForwarderContractCode(ctx, ec, forwarderAddr)
 	if err != nil {
 		return ethcommon.Address{}, err
 	}
+	log.Debugf("SwapCreator.sol at %s uses a %s forwarder at %s", contractAddr, version, forwarderAddr)
 
 	// return the trusted forwarder address that was parsed from the deployed contract byte code
 	return forwarderAddr, nil
 }
 
 // CheckForwarderContractCode checks that the trusted forwarder contract used by
-// the given swap contract has the expected bytecode.
+// the given swap contract is either a known OpenGSN v3 forwarder deployment or has
+// the bytecode of the forwarder bundled with go-relayer. It returns the version of
+// the forwarder.
 func CheckForwarderContractCode(
 	ctx context.Context,
 	ec *ethclient.Client,
 	contractAddr ethcommon.Address,
-) error {
-	// mainnet override - since the forwarder contract deployed on mainnet is compiled
-	// with solidity 0.8.7, but we're using 0.8.19 for SwapCreator.sol, we can just
-	// check that the address is what's expected.
+) (ForwarderVersion, error) {
 	chainID, err := ec.ChainID(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if contractAddr == common.MainnetConfig().ForwarderAddr && chainID.Uint64() == common.MainnetChainID {
-		return nil
+	// OpenGSN's v3 forwarders are compiled with solidity 0.8.7, but we're using
+	// 0.8.19 for SwapCreator.sol, so we check that the address is a known
+	// deployment instead of comparing the bytecode.
+	if forwarderVersionOf(chainID.Uint64(), contractAddr) == ForwarderV3 {
+		forwarder, err := gsnforwarder.NewForwarder(contractAddr, ec)
+		if err != nil {
+			return 0, err
+		}
+		if err = checkForwardRequestTypeRegistered(ctx, forwarder); err != nil {
+			return 0, err
+		}
+		return ForwarderV3, nil
 	}
 
 	code, err := ec.CodeAt(ctx, contractAddr, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	expectedCode := ethcommon.FromHex(gsnforwarder.ForwarderMetaData.Bin)
 
 	// expectedCode is the compiled code, while code is the deployed bytecode.
 	// the deployed bytecode is a subset of the compiled code.
-	if !bytes.Equal(expectedCode[705:9585], code) {
-		return errInvalidForwarderContract
+	if !bytes.Equal(stripCodeMetadata(expectedCode[705:9585]), stripCodeMetadata(code)) {
+		return 0, errInvalidForwarderContract
+	}
+
+	return ForwarderV2, nil
+}
+
+// stripCodeMetadata returns the passed runtime bytecode without the CBOR encoded
+// metadata that solc appends to it. The last two bytes of the code hold the length
+// of the CBOR data, which is a map. If the code doesn't end with what looks like
+// a metadata trailer, it is returned unchanged.
+func stripCodeMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+
+	cborLen := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	cborStart := len(code) - 2 - cborLen
+	if cborLen == 0 || cborStart < 0 {
+		return code
+	}
+
+	// CBOR maps with less than 24 entries have a header byte of 0xa0-0xb7
+	if code[cborStart] < 0xa0 || code[cborStart] > 0xb7 {
+		return code
 	}
 
-	return nil
+	return code[:cborStart]
 }
@@ -56,8 +56,9 @@ func TestCheckForwarderContractCode(t *testing.T) {
 	ec, _ := tests.NewEthClient(t)
 	pk := tests.GetMakerTestKey(t)
 	trustedForwarder := deployForwarder(t, ec, pk)
-	err := CheckForwarderContractCode(context.Background(), ec, trustedForwarder)
+	version, err := CheckForwarderContractCode(context.Background(), ec, trustedForwarder)
 	require.NoError(t, err)
+	require.Equal(t, ForwarderV2, version)
 }
 
 // This test will fail if the compiled SwapCreator contract is updated, but the
@@ -151,3 +152,22 @@ func TestSepoliaContract(t *testing.T) {
 		t.Logf("Sepolia SwapCreator deployed with TrustedForwarder=%s", parsedTFAddr.Hex())
 	}
 }
+
+func Test_stripCodeMetadata(t *testing.T) {
+	code := ethcommon.FromHex(expectedSwapCreatorBytecodeHex)
+	stripped := stripCodeMetadata(code)
+
+	// 0x33 bytes of CBOR data plus the 2 length bytes
+	require.Equal(t, len(code)-0x33-2, len(stripped))
+	// solc places an INVALID opcode directly before the metadata
+	require.Equal(t, byte(0xfe), stripped[len(stripped)-1])
+
+	// A different metadata hash must not change the stripped result
+	altCode := bytes.Clone(code)
+	altCode[len(altCode)-20] ^= 0xff
+	require.Equal(t, stripped, stripCodeMetadata(altCode))
+
+	// Code without a metadata trailer is returned unchanged
+	noMetadata := []byte{0x60, 0x80, 0x60, 0x40, 0x52, 0x00, 0x00}
+	require.Equal(t, noMetadata, stripCodeMetadata(noMetadata))
+}
@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: LGPLv3
+pragma solidity 0.8.19 .0;
+
+import {Secp256k1} from "./Secp256k1.sol";
+import {SwapCreator} from "./SwapCreator.sol";
+
+// UserOperation of the v0.6 ERC-4337 EntryPoint
+struct UserOperation {
+    address sender;
+    uint256 nonce;
+    bytes initCode;
+    bytes callData;
+    uint256 callGasLimit;
+    uint256 verificationGasLimit;
+    uint256 preVerificationGas;
+    uint256 maxFeePerGas;
+    uint256 maxPriorityFeePerGas;
+    bytes paymasterAndData;
+    bytes signature;
+}
+
+// the method of the GSN forwarder trusted by SwapCreator that we call
+interface IForwarder {
+    struct ForwardRequest {
+        address from;
+        address to;
+        uint256 value;
+        uint256 gas;
+        uint256 nonce;
+        bytes data;
+        uint256 validUntilTime;
+    }
+
+    function execute(
+        ForwardRequest calldata req,
+        bytes32 domainSeparator,
+        bytes32 requestTypeHash,
+        bytes calldata suffixData,
+        bytes calldata sig
+    ) external payable returns (bool success, bytes memory ret);
+}
+
+// SwapClaimAccount is an ownerless ERC-4337 account that submits relayed claims of
+// SwapCreator swaps. A user operation is valid if it claims a swap with the swap's
+// secret and a forward request signed by the swap's claimer, so claimers without
+// ETH can use public bundlers. The relayer fee of the claim goes to the bundler.
+// The prefund of the user operation must be paid by a paymaster, which the bundler
+// repays from the fee. The account never pays for gas itself, as nothing would
+// reimburse its deposit in the EntryPoint, and anyone could drain it with claims
+// of their own swaps.
+contract SwapClaimAccount is Secp256k1 {
+    // a claim of a swap through the forwarder, signed by the swap's claimer
+    struct Claim {
+        SwapCreator.Swap swap;
+        bytes32 secret;
+        uint256 fee;
+        // gas and nonce of the forward request
+        uint256 gas;
+        uint256 nonce;
+        bytes32 domainSeparator;
+        bytes signature;
+    }
+
+    // the request type signed by claimers, registered in the forwarder
+    bytes32 public constant FORWARD_REQUEST_TYPEHASH =
+        keccak256(
+            "ForwardRequest(address from,address to,uint256 value,uint256 gas,uint256 nonce,bytes data,uint256 validUntilTime)"
+        );
+
+    uint256 private constant SIG_VALIDATION_FAILED = 1;
+
+    address public immutable entryPoint;
+    SwapCreator public immutable swapCreator;
+    IForwarder public immutable forwarder;
+
+    error OnlyEntryPoint();
+    error ClaimFailed(bytes ret);
+
+    constructor(address _entryPoint, SwapCreator _swapCreator) {
+        entryPoint = _entryPoint;
+        swapCreator = _swapCreator;
+        forwarder = IForwarder(_swapCreator._trustedForwarder());
+    }
+
+    // validateUserOp is called by the EntryPoint to validate a user operation. The
+    // operation must call `claim` with a valid claim, and the key of its nonce must
+    // be the swap ID, so each swap can only be claimed once through the account.
+    // The operation is only valid until the swap's t1, after which it can't be
+    // claimed. Operations without a paymaster are rejected, so the account's
+    // deposit is never charged and there is no missing prefund to pay.
+    function validateUserOp(
+        UserOperation calldata _userOp,
+        bytes32,
+        uint256
+    ) external view returns (uint256 validationData) {
+        if (msg.sender != entryPoint) revert OnlyEntryPoint();
+
+        if (_userOp.paymasterAndData.length == 0) {
+            validationData = SIG_VALIDATION_FAILED;
+        } else if (_userOp.callData.length < 4 || bytes4(_userOp.callData[:4]) != this.claim.selector) {
+            validationData = SIG_VALIDATION_FAILED;
+        } else {
+            Claim memory c = abi.decode(_userOp.callData[4:], (Claim));
+            if (!_isValidClaim(c, _userOp.nonce)) {
+                validationData = SIG_VALIDATION_FAILED;
+            }
+            // the EntryPoint rejects the operation after validUntil
+            validationData |= uint256(uint48(c.swap.timeout1)) << 160;
+        }
+    }
+
+    // claim executes the claim's forward request, which calls SwapCreator's
+    // claimRelayer as the swap's claimer.
+    function claim(Claim calldata _claim) external {
+        if (msg.sender != entryPoint) revert OnlyEntryPoint();
+
+        (bool success, bytes memory ret) = forwarder.execute(
+            _forwardRequest(_claim.swap, _claim.secret, _claim.fee, _claim.gas, _claim.nonce),
+            _claim.domainSeparator,
+            FORWARD_REQUEST_TYPEHASH,
+            "",
+            _claim.signature
+        );
+        if (!success) revert ClaimFailed(ret);
+    }
+
+    // _isValidClaim checks the claim's secret and forward request signature. The
+    // stage of the swap and the forwarder nonce can't be checked, as validation can't
+    // access the storage of other contracts.
+    function _isValidClaim(Claim memory _claim, uint256 _opNonce) private view returns (bool) {
+        bytes32 swapID = keccak256(abi.encode(_claim.swap));
+        if (_opNonce != uint256(uint192(uint256(swapID))) << 64) return false;
+
+        if (!mulVerify(uint256(_claim.secret), uint256(_claim.swap.pubKeyClaim))) return false;
+
+        IForwarder.ForwardRequest memory req = _forwardRequest(
+            _claim.swap,
+            _claim.secret,
+            _claim.fee,
+            _claim.gas,
+            _claim.nonce
+        );
+
+        // the digest verified by the forwarder
+        bytes32 digest = keccak256(
+            abi.encodePacked(
+                "\x19\x01",
+                _claim.domainSeparator,
+                keccak256(
+                    abi.encode(
+                        FORWARD_REQUEST_TYPEHASH,
+                        req.from,
+                        req.to,
+                        req.value,
+                        req.gas,
+                        req.nonce,
+                        keccak256(req.data),
+                        req.validUntilTime
+                    )
+                )
+            )
+        );
+
+        return _claim.swap.claimer != address(0) && _recover(digest, _claim.signature) == _claim.swap.claimer;
+    }
+
+    function _forwardRequest(
+        SwapCreator.Swap memory _swap,
+        bytes32 _secret,
+        uint256 _fee,
+        uint256 _gas,
+        uint256 _nonce
+    ) private view returns (IForwarder.ForwardRequest memory) {
+        return
+            IForwarder.ForwardRequest({
+                from: _swap.claimer,
+                to: address(swapCreator),
+                value: 0,
+                gas: _gas,
+                nonce: _nonce,
+                data: abi.encodeCall(SwapCreator.claimRelayer, (_swap, _secret, _fee)),
+                validUntilTime: 0
+            });
+    }
+
+    function _recover(bytes32 _digest, bytes memory _sig) private pure returns (address) {
+        if (_sig.length != 65) return address(0);
+
+        bytes32 r;
+        bytes32 s;
+        uint8 v;
+        /// @solidity memory-safe-assembly
+        assembly {
+            r := mload(add(_sig, 0x20))
+            s := mload(add(_sig, 0x40))
+            v := byte(0, mload(add(_sig, 0x60)))
+        }
+        return ecrecover(_digest, v, r, s);
+    }
+}
@@ -3,6 +3,7 @@ pragma solidity 0.8.19 .0;
 
 import {ERC2771Context} from "./ERC2771Context.sol";
 import {IERC20} from "./IERC20.sol";
+import {IERC20Permit} from "./IERC20Permit.sol";
 import {Secp256k1} from "./Secp256k1.sol";
 
 contract SwapCreator is ERC2771Context, Secp256k1 {
@@ -40,6 +41,16 @@ contract SwapCreator is ERC2771Context, Secp256k1 {
         uint256 nonce;
     }
 
+    // an EIP-2612 permit signature of the swap owner, allowing this contract to
+    // transfer the swap value of an ERC-20 token
+    struct PermitSignature {
+        // timestamp after which the permit can't be used
+        uint256 deadline;
+        uint8 v;
+        bytes32 r;
+        bytes32 s;
+    }
+
     mapping(bytes32 => Stage) public swaps;
 
     event New(
@@ -70,9 +81,13 @@ contract SwapCreator is ERC2771Context, Secp256k1 {
     // returned when the caller of `setReady` or `refund` is not the swap owner
     error OnlySwapOwner();
 
-    // returned when `claimRelayer` is not called by the trusted forwarder
+    // returned when a relayer method like `claimRelayer` is not called by the trusted forwarder
     error OnlyTrustedForwarder();
 
+    // returned when `newSwapRelayer` or `setReadyRelayer` is called for an ETH swap, as their
+    // relayer fee is paid in the swap's token
+    error OnlyTokenSwap();
+
     // returned when the signer of the relayed transaction is not the swap's claimer
     error OnlySwapClaimer();
 
@@ -118,11 +133,12 @@ contract SwapCreator is ERC2771Context, Secp256k1 {
             // TODO: potentially check token balance before/after this step
             // and ensure the balance was increased by swap.value since fee-on-transfer
             // tokens are not supported
-            IERC20(_asset).transferFrom(msg.sender, address(this), _value);
+            IERC20(_asset).transferFrom(_msgSender(), address(this), _value);
         }
 
+        // the owner is the signer of the request when relayed by the trusted forwarder
         Swap memory swap;
-        swap.owner = payable(msg.sender);
+        swap.owner = payable(_msgSender());
         swap.pubKeyClaim = _pubKeyClaim;
         swap.pubKeyRefund = _pubKeyRefund;
         swap.claimer = _claimer;
@@ -150,11 +166,91 @@ contract SwapCreator is ERC2771Context, Secp256k1 {
         return swapID;
     }
 
+    // newSwapWithPermit creates a new ERC-20 swap like newSwap, first granting this
+    // contract an allowance of _value with the caller's EIP-2612 permit signature,
+    // so that no separate approve transaction is needed.
+    function newSwapWithPermit(
+        bytes32 _pubKeyClaim,
+        bytes32 _pubKeyRefund,
+        address payable _claimer,
+        uint256 _timeoutDuration0,
+        uint256 _timeoutDuration1,
+        address _asset,
+        uint256 _value,
+        uint256 _nonce,
+        PermitSignature calldata _permit
+    ) public returns (bytes32) {
+        _submitPermit(_asset, _value, _permit);
+        return
+            newSwap(
+                _pubKeyClaim,
+                _pubKeyRefund,
+                _claimer,
+                _timeoutDuration0,
+                _timeoutDuration1,
+                _asset,
+                _value,
+                _nonce
+            );
+    }
+
+    // newSwapRelayer creates a new ERC-20 swap like newSwapWithPermit for an owner without
+    // ETH to pay for gas, who signed the request that the trusted forwarder executes. The
+    // owner's permit allows this contract to transfer the swap value plus the relayer fee,
+    // which is sent to the originator of the transaction.
+    function newSwapRelayer(
+        bytes32 _pubKeyClaim,
+        bytes32 _pubKeyRefund,
+        address payable _claimer,
+        uint256 _timeoutDuration0,
+        uint256 _timeoutDuration1,
+        address _asset,
+        uint256 _value,
+        uint256 _nonce,
+        uint256 fee,
+        PermitSignature calldata _permit
+    ) public returns (bytes32) {
+        if (!isTrustedForwarder(msg.sender)) revert OnlyTrustedForwarder();
+        if (_asset == address(0)) revert OnlyTokenSwap();
+        _submitPermit(_asset, _value + fee, _permit);
+
+        // tx.origin is okay here, since it isn't for authentication purposes.
+        IERC20(_asset).transferFrom(_msgSender(), tx.origin, fee); // solhint-disable-line
+        return
+            newSwap(
+                _pubKeyClaim,
+                _pubKeyRefund,
+                _claimer,
+                _timeoutDuration0,
+                _timeoutDuration1,
+                _asset,
+                _value,
+                _nonce
+            );
+    }
+
     // Alice should call setReady() within t_0 once she verifies the XMR has been locked
     function setReady(Swap memory _swap) public {
+        _setReady(_swap);
+    }
+
+    // Alice can have setReady relayed when she has no ETH to pay for gas. Her permit
+    // allows this contract to transfer the relayer fee from her balance of the swap's
+    // token to the originator of the transaction.
+    function setReadyRelayer(Swap memory _swap, uint256 fee, PermitSignature calldata _permit) public {
+        if (!isTrustedForwarder(msg.sender)) revert OnlyTrustedForwarder();
+        if (_swap.asset == address(0)) revert OnlyTokenSwap();
+        _setReady(_swap);
+        _submitPermit(_swap.asset, fee, _permit);
+
+        // tx.origin is okay here, since it isn't for authentication purposes.
+        IERC20(_swap.asset).transferFrom(_swap.owner, tx.origin, fee); // solhint-disable-line
+    }
+
+    function _setReady(Swap memory _swap) internal {
         bytes32 swapID = keccak256(abi.encode(_swap));
         if (swaps[swapID] != Stage.PENDING) revert SwapNotPending();
-        if (_swap.owner != msg.sender) revert OnlySwapOwner();
+        if (_swap.owner != _msgSender()) revert OnlySwapOwner();
         swaps[swapID] = Stage.READY;
         emit Ready(swapID);
     }
@@ -220,11 +316,40 @@ contract SwapCreator is ERC2771Context, Secp256k1 {
     // - Until t_0 unless she calls set_ready
     // - After t_1
     function refund(Swap memory _swap, bytes32 _s) public {
+        _refund(_swap, _s);
+
+        // send asset back to owner==caller (Alice)
+        if (_swap.asset == address(0)) {
+            _swap.owner.transfer(_swap.value);
+        } else {
+            IERC20(_swap.asset).transfer(_swap.owner, _swap.value);
+        }
+    }
+
+    // Alice can have a refund relayed when she has no ETH to pay for gas, at the same
+    // times as she can call refund
+    function refundRelayer(Swap memory _swap, bytes32 _s, uint256 fee) public {
+        if (!isTrustedForwarder(msg.sender)) revert OnlyTrustedForwarder();
+        _refund(_swap, _s);
+
+        // send asset back to owner, subtracting the relayer fee which is sent to
+        // the originator of the transaction.
+        // tx.origin is okay here, since it isn't for authentication purposes.
+        if (_swap.asset == address(0)) {
+            _swap.owner.transfer(_swap.value - fee);
+            payable(tx.origin).transfer(fee); // solhint-disable-line
+        } else {
+            IERC20(_swap.asset).transfer(_swap.owner, _swap.value - fee);
+            IERC20(_swap.asset).transfer(tx.origin, fee); // solhint-disable-line
+        }
+    }
+
+    function _refund(Swap memory _swap, bytes32 _s) internal {
         bytes32 swapID = keccak256(abi.encode(_swap));
         Stage swapStage = swaps[swapID];
         if (swapStage == Stage.INVALID) revert InvalidSwap();
         if (swapStage == Stage.COMPLETED) revert SwapCompleted();
-        if (_swap.owner != msg.sender) revert OnlySwapOwner();
+        if (_msgSender() != _swap.owner) revert OnlySwapOwner();
         if (
             block.timestamp < _swap.timeout1 &&
             (block.timestamp > _swap.timeout0 || swapStage == Stage.READY)
@@ -232,14 +357,25 @@ contract SwapCreator is ERC2771Context, Secp256k1 {
 
         verifySecret(_s, _swap.pubKeyRefund);
         emit Refunded(swapID, _s);
-
-        // send asset back to owner==caller (Alice)
         swaps[swapID] = Stage.COMPLETED;
-        if (_swap.asset == address(0)) {
-            _swap.owner.transfer(_swap.value);
-        } else {
-            IERC20(_swap.asset).transfer(_swap.owner, _swap.value);
-        }
+    }
+
+    // _submitPermit submits the caller's permit signature to the token. Anyone who saw the
+    // signature can submit it first, so a failed permit is ignored, and the transfer
+    // of the swap value fails instead if the allowance wasn't granted.
+    function _submitPermit(address _asset, uint256 _value, PermitSignature calldata _permit) private {
+        // solhint-disable-next-line no-empty-blocks
+        try
+            IERC20Permit(_asset).permit(
+                _msgSender(),
+                address(this),
+                _value,
+                _permit.deadline,
+                _permit.v,
+                _permit.r,
+                _permit.s
+            )
+        {} catch {}
     }
 
     function verifySecret(bytes32 _s, bytes32 pubKey) internal pure {
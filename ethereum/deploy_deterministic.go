@@ -0,0 +1,177 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
+)
+
+var (
+	// DeterministicDeployerAddr is the address of the keyless CREATE2 deployment
+	// proxy (https://github.com/Arachnid/deterministic-deployment-proxy). It is
+	// available at the same address on nearly every EVM chain. Its calldata is
+	// the 32-byte salt followed by the contract's init code.
+	DeterministicDeployerAddr = ethcommon.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+	// SwapCreatorSalt is the fixed CREATE2 salt used when deploying SwapCreator.sol
+	// via the deterministic deployer.
+	SwapCreatorSalt = crypto.Keccak256Hash([]byte("athanorlabs/atomic-swap/SwapCreator"))
+
+	errNoDeterministicDeployer = fmt.Errorf("no deterministic deployer contract found at %s", DeterministicDeployerAddr)
+)
+
+// SwapCreatorInitCode returns the init code (creation bytecode followed by the ABI
+// encoded constructor arguments) of SwapCreator.sol for the given trusted forwarder.
+func SwapCreatorInitCode(forwarderAddr ethcommon.Address) ([]byte, error) {
+	args, err := SwapCreatorParsedABI.Pack("", forwarderAddr)
+	if err != nil {
+		return nil, err
+	}
+	return append(ethcommon.FromHex(SwapCreatorMetaData.Bin), args...), nil
+}
+
+// DeterministicSwapCreatorAddress returns the address that SwapCreator.sol lands at
+// when deployed with the given trusted forwarder via the deterministic deployer. The
+// address is the same on every chain.
+func DeterministicSwapCreatorAddress(forwarderAddr ethcommon.Address) (ethcommon.Address, error) {
+	initCode, err := SwapCreatorInitCode(forwarderAddr)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	return crypto.CreateAddress2(DeterministicDeployerAddr, SwapCreatorSalt, crypto.Keccak256(initCode)), nil
+}
+
+// IsDeterministicSwapCreatorAddress returns true if contractAddr is the address that a
+// deterministic deployment of SwapCreator.sol with the given trusted forwarder would
+// land at.
+func IsDeterministicSwapCreatorAddress(contractAddr ethcommon.Address, forwarderAddr ethcommon.Address) bool {
+	expectedAddr, err := DeterministicSwapCreatorAddress(forwarderAddr)
+	if err != nil {
+		return false
+	}
+	return contractAddr == expectedAddr
+}
+
+// DeploySwapCreatorDeterministic deploys the SwapCreator contract via the CREATE2
+// deterministic deployer, using the passed privKey to pay for the gas. If the contract
+// was already deployed at the deterministic address, the existing deployment is
+// validated and the returned receipt is nil.
+func DeploySwapCreatorDeterministic(
+	ctx context.Context,
+	ec *ethclient.Client,
+	privKey *ecdsa.PrivateKey,
+	forwarderAddr ethcommon.Address,
+) (ethcommon.Address, *ethtypes.Receipt, error) {
+	address, err := DeterministicSwapCreatorAddress(forwarderAddr)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	code, err := ec.CodeAt(ctx, address, nil)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	var receipt *ethtypes.Receipt
+	if len(code) == 0 {
+		receipt, err = deploySwapCreatorViaDeployer(ctx, ec, privKey, forwarderAddr)
+		if err != nil {
+			return ethcommon.Address{}, nil, fmt.Errorf("failed to deploy swap creator: %w", err)
+		}
+	} else {
+		log.Infof("SwapCreator.sol already deployed at deterministic address %s", address)
+	}
+
+	if _, err = CheckSwapCreatorContractCode(ctx, ec, address); err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	return address, receipt, nil
+}
+
+func deploySwapCreatorViaDeployer(
+	ctx context.Context,
+	ec *ethclient.Client,
+	privKey *ecdsa.PrivateKey,
+	forwarderAddr ethcommon.Address,
+) (*ethtypes.Receipt, error) {
+	deployerCode, err := ec.CodeAt(ctx, DeterministicDeployerAddr, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(deployerCode) == 0 {
+		return nil, errNoDeterministicDeployer
+	}
+
+	if (forwarderAddr != ethcommon.Address{}) {
+		if err = registerDomainSeparatorIfNeeded(ctx, ec, privKey, forwarderAddr); err != nil {
+			return nil, err
+		}
+	}
+
+	initCode, err := SwapCreatorInitCode(forwarderAddr)
+	if err != nil {
+		return nil, err
+	}
+	data := append(SwapCreatorSalt.Bytes(), initCode...)
+
+	txOpts, err := newTXOpts(ctx, ec, privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit, err := ec.EstimateGas(ctx, ethereum.CallMsg{
+		From: txOpts.From,
+		To:   &DeterministicDeployerAddr,
+		Data: data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	gasPrice, err := ec.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := ec.PendingNonceAt(ctx, txOpts.From)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := ethtypes.NewTx(&ethtypes.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      gasLimit,
+		To:       &DeterministicDeployerAddr,
+		Data:     data,
+	})
+
+	signedTx, err := txOpts.Signer(txOpts.From, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ec.SendTransaction(ctx, signedTx); err != nil {
+		return nil, err
+	}
+
+	receipt, err := block.WaitForReceipt(ctx, ec, signedTx.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("deployed SwapCreator.sol via CREATE2: tx hash=%s", signedTx.Hash())
+	return receipt, nil
+}
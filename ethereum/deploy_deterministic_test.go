@@ -0,0 +1,29 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicSwapCreatorAddress(t *testing.T) {
+	forwarderA := ethcommon.HexToAddress("0xa030E074b8398005a454CB7c51E9b7CDb966744a")
+	forwarderB := ethcommon.HexToAddress("0xB2b5841DBeF766d4b521221732F9B618fCf34A87")
+
+	addrA, err := DeterministicSwapCreatorAddress(forwarderA)
+	require.NoError(t, err)
+	addrA2, err := DeterministicSwapCreatorAddress(forwarderA)
+	require.NoError(t, err)
+	require.Equal(t, addrA, addrA2)
+
+	addrB, err := DeterministicSwapCreatorAddress(forwarderB)
+	require.NoError(t, err)
+	require.NotEqual(t, addrA, addrB)
+
+	require.True(t, IsDeterministicSwapCreatorAddress(addrA, forwarderA))
+	require.False(t, IsDeterministicSwapCreatorAddress(addrA, forwarderB))
+}
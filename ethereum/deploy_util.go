@@ -12,6 +12,7 @@ import (
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	logging "github.com/ipfs/go-log"
 
@@ -28,6 +29,27 @@ func DeploySwapCreatorWithKey(
 	privKey *ecdsa.PrivateKey,
 	forwarderAddr ethcommon.Address,
 ) (ethcommon.Address, *SwapCreator, error) {
+	address, _, err := DeploySwapCreatorWithReceipt(ctx, ec, privKey, forwarderAddr)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	sf, err := NewSwapCreator(address, ec)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	return address, sf, nil
+}
+
+// DeploySwapCreatorWithReceipt is the same as DeploySwapCreatorWithKey, but returns
+// the receipt of the deployment transaction instead of the contract bindings.
+func DeploySwapCreatorWithReceipt(
+	ctx context.Context,
+	ec *ethclient.Client,
+	privKey *ecdsa.PrivateKey,
+	forwarderAddr ethcommon.Address,
+) (ethcommon.Address, *ethtypes.Receipt, error) {
 
 	txOpts, err := newTXOpts(ctx, ec, privKey)
 	if err != nil {
@@ -40,19 +62,19 @@ func DeploySwapCreatorWithKey(
 		}
 	}
 
-	address, tx, sf, err := DeploySwapCreator(txOpts, ec, forwarderAddr)
+	address, tx, _, err := DeploySwapCreator(txOpts, ec, forwarderAddr)
 	if err != nil {
 		return ethcommon.Address{}, nil, fmt.Errorf("failed to deploy swap creator: %w", err)
 	}
 
-	_, err = block.WaitForReceipt(ctx, ec, tx.Hash())
+	receipt, err := block.WaitForReceipt(ctx, ec, tx.Hash())
 	if err != nil {
 		return ethcommon.Address{}, nil, err
 	}
 
 	log.Infof("deployed SwapCreator.sol: address=%s tx hash=%s", address, tx.Hash())
 
-	return address, sf, nil
+	return address, receipt, nil
 }
 
 // DeployGSNForwarderWithKey deploys and registers the GSN forwarder using the passed
@@ -62,28 +84,39 @@ func DeployGSNForwarderWithKey(
 	ec *ethclient.Client,
 	privKey *ecdsa.PrivateKey,
 ) (ethcommon.Address, error) {
+	address, _, err := DeployGSNForwarderWithReceipt(ctx, ec, privKey)
+	return address, err
+}
+
+// DeployGSNForwarderWithReceipt is the same as DeployGSNForwarderWithKey, but also
+// returns the receipt of the deployment transaction.
+func DeployGSNForwarderWithReceipt(
+	ctx context.Context,
+	ec *ethclient.Client,
+	privKey *ecdsa.PrivateKey,
+) (ethcommon.Address, *ethtypes.Receipt, error) {
 
 	txOpts, err := newTXOpts(ctx, ec, privKey)
 	if err != nil {
-		return ethcommon.Address{}, err
+		return ethcommon.Address{}, nil, err
 	}
 
 	address, tx, contract, err := gsnforwarder.DeployForwarder(txOpts, ec)
 	if err != nil {
-		return ethcommon.Address{}, fmt.Errorf("failed to deploy Forwarder.sol: %w", err)
+		return ethcommon.Address{}, nil, fmt.Errorf("failed to deploy Forwarder.sol: %w", err)
 	}
 
-	_, err = block.WaitForReceipt(ctx, ec, tx.Hash())
+	receipt, err := block.WaitForReceipt(ctx, ec, tx.Hash())
 	if err != nil {
-		return ethcommon.Address{}, err
+		return ethcommon.Address{}, nil, err
 	}
 
 	err = registerDomainSeparator(ctx, ec, privKey, address, contract)
 	if err != nil {
-		return ethcommon.Address{}, err
+		return ethcommon.Address{}, nil, err
 	}
 
-	return address, nil
+	return address, receipt, nil
 }
 
 func isDomainSeparatorRegistered(
@@ -0,0 +1,250 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// PermitValidity is how long a permit signed by SignPermit is valid
+const PermitValidity = time.Hour
+
+// erc20PermitABI holds the EIP-2612 extension methods of an ERC-20 token.
+const erc20PermitABI = `[
+	{"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"type":"bytes32"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"},{"name":"value","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"name":"permit","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+// permitDomainVersions are the EIP-712 domain versions tried, in order, when looking
+// for the domain a token uses. OpenZeppelin's ERC20Permit uses "1" and USDC uses "2".
+var permitDomainVersions = []string{"1", "2"}
+
+var errPermitUnsupported = errors.New("token does not support EIP-2612 permit")
+
+// PermitSigner signs the EIP-712 typed data of permits for its address.
+type PermitSigner interface {
+	Address() ethcommon.Address
+	SignTypedData(data *apitypes.TypedData) ([]byte, error)
+}
+
+// ERC20Permit is a binding for the EIP-2612 permit extension of an ERC-20 token.
+type ERC20Permit struct {
+	address  ethcommon.Address
+	contract *bind.BoundContract
+}
+
+// Permit holds the values of an EIP-2612 permit message.
+type Permit struct {
+	Owner    ethcommon.Address
+	Spender  ethcommon.Address
+	Value    *big.Int
+	Nonce    *big.Int
+	Deadline *big.Int
+}
+
+// NewERC20Permit returns a binding for the permit extension of the token at the
+// given address. It does not check that the token implements the extension, use
+// DomainVersion for that.
+func NewERC20Permit(address ethcommon.Address, backend bind.ContractBackend) (*ERC20Permit, error) {
+	parsed, err := abi.JSON(strings.NewReader(erc20PermitABI))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ERC20Permit{
+		address:  address,
+		contract: bind.NewBoundContract(address, parsed, backend, backend, backend),
+	}, nil
+}
+
+// DomainSeparator returns the token's EIP-712 domain separator.
+func (p *ERC20Permit) DomainSeparator(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := p.contract.Call(opts, &out, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	return *abi.ConvertType(out[0], new([32]byte)).(*[32]byte), nil
+}
+
+// Nonces returns the next permit nonce of the owner.
+func (p *ERC20Permit) Nonces(opts *bind.CallOpts, owner ethcommon.Address) (*big.Int, error) {
+	var out []interface{}
+	err := p.contract.Call(opts, &out, "nonces", owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+}
+
+// DomainVersion returns the version of the token's EIP-712 domain, given the token's
+// name and the chain ID. errPermitUnsupported is returned if the token has no
+// DOMAIN_SEPARATOR method or its separator does not match any known domain.
+func (p *ERC20Permit) DomainVersion(opts *bind.CallOpts, name string, chainID *big.Int) (string, error) {
+	separator, err := p.DomainSeparator(opts)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errPermitUnsupported, err)
+	}
+
+	for _, version := range permitDomainVersions {
+		data := PermitTypedData(chainID, p.address, name, version, &Permit{})
+		expected, err := data.HashStruct("EIP712Domain", data.Domain.Map())
+		if err != nil {
+			return "", err
+		}
+
+		if ethcommon.BytesToHash(expected) == separator {
+			return version, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: unknown domain separator %x", errPermitUnsupported, separator)
+}
+
+// SignPermit signs an EIP-2612 permit allowing the spender to transfer the value of
+// the token from the signer's account, valid for PermitValidity from the latest
+// block's timestamp. An error is returned if the token does not implement EIP-2612 or
+// its permit metadata can't be read.
+func SignPermit(
+	ctx context.Context,
+	ec *ethclient.Client,
+	signer PermitSigner,
+	token ethcommon.Address,
+	spender ethcommon.Address,
+	value *big.Int,
+) (*SwapCreatorPermitSignature, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	erc20Contract, err := NewIERC20(token, ec)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := erc20Contract.Name(callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permitContract, err := NewERC20Permit(token, ec)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := permitContract.DomainVersion(callOpts, name, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := permitContract.Nonces(callOpts, signer.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	permit := &Permit{
+		Owner:    signer.Address(),
+		Spender:  spender,
+		Value:    value,
+		Nonce:    nonce,
+		Deadline: big.NewInt(time.Unix(int64(header.Time), 0).Add(PermitValidity).Unix()),
+	}
+
+	sig, err := signer.SignTypedData(PermitTypedData(chainID, token, name, version, permit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign permit: %w", err)
+	}
+
+	return NewPermitSignature(permit, sig)
+}
+
+// NewPermitSignature returns the permit's deadline and 65-byte [R || S || V] signature
+// of the permit's typed data in the form taken by SwapCreator's newSwapWithPermit.
+func NewPermitSignature(permit *Permit, sig []byte) (*SwapCreatorPermitSignature, error) {
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("invalid permit signature length %d", len(sig))
+	}
+
+	permitSig := &SwapCreatorPermitSignature{
+		Deadline: permit.Deadline,
+		V:        sig[ethcrypto.RecoveryIDOffset],
+	}
+	copy(permitSig.R[:], sig[:32])
+	copy(permitSig.S[:], sig[32:64])
+
+	return permitSig, nil
+}
+
+// PermitTypedData returns the EIP-712 typed data of the permit, for a token with the
+// given name and domain version.
+func PermitTypedData(
+	chainID *big.Int,
+	token ethcommon.Address,
+	name string,
+	version string,
+	permit *Permit,
+) *apitypes.TypedData {
+	bigString := func(i *big.Int) string {
+		if i == nil {
+			return "0"
+		}
+		return i.String()
+	}
+
+	return &apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: token.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    permit.Owner.Hex(),
+			"spender":  permit.Spender.Hex(),
+			"value":    bigString(permit.Value),
+			"nonce":    bigString(permit.Nonce),
+			"deadline": bigString(permit.Deadline),
+		},
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPermitTypedData_domainSeparator checks that the domain of the permit typed data
+// hashes to the separator computed the way OpenZeppelin's EIP712 contract does.
+func TestPermitTypedData_domainSeparator(t *testing.T) {
+	chainID := big.NewInt(1)
+	token := ethcommon.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	bytes32Ty, _ := abi.NewType("bytes32", "", nil)
+	uint256Ty, _ := abi.NewType("uint256", "", nil)
+	addressTy, _ := abi.NewType("address", "", nil)
+	args := abi.Arguments{{Type: bytes32Ty}, {Type: bytes32Ty}, {Type: bytes32Ty}, {Type: uint256Ty}, {Type: addressTy}}
+
+	encoded, err := args.Pack(
+		ethcrypto.Keccak256Hash(
+			[]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"),
+		),
+		ethcrypto.Keccak256Hash([]byte("USD Coin")),
+		ethcrypto.Keccak256Hash([]byte("2")),
+		chainID,
+		token,
+	)
+	require.NoError(t, err)
+	expected := ethcrypto.Keccak256(encoded)
+
+	data := PermitTypedData(chainID, token, "USD Coin", "2", &Permit{})
+	separator, err := data.HashStruct("EIP712Domain", data.Domain.Map())
+	require.NoError(t, err)
+	require.Equal(t, expected, []byte(separator))
+
+	other := PermitTypedData(chainID, token, "USD Coin", "1", &Permit{})
+	otherSeparator, err := other.HashStruct("EIP712Domain", other.Domain.Map())
+	require.NoError(t, err)
+	require.NotEqual(t, expected, []byte(otherSeparator))
+}
+
+func TestPermitTypedData_message(t *testing.T) {
+	permit := &Permit{
+		Owner:    ethcommon.HexToAddress("0x1000000000000000000000000000000000000001"),
+		Spender:  ethcommon.HexToAddress("0x2000000000000000000000000000000000000002"),
+		Value:    big.NewInt(1_000_000),
+		Nonce:    big.NewInt(3),
+		Deadline: big.NewInt(1_700_000_000),
+	}
+
+	data := PermitTypedData(big.NewInt(1), ethcommon.Address{0x1}, "Token", "1", permit)
+	_, err := data.HashStruct(data.PrimaryType, data.Message)
+	require.NoError(t, err)
+	require.Equal(t, "1000000", data.Message["value"])
+	require.Equal(t, "3", data.Message["nonce"])
+}
+
+func TestNewPermitSignature(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	permit := &Permit{
+		Owner:    ethcrypto.PubkeyToAddress(key.PublicKey),
+		Spender:  ethcommon.HexToAddress("0x2000000000000000000000000000000000000002"),
+		Value:    big.NewInt(1_000_000),
+		Nonce:    big.NewInt(0),
+		Deadline: big.NewInt(1_700_000_000),
+	}
+	data := PermitTypedData(big.NewInt(1), ethcommon.Address{0x1}, "Token", "1", permit)
+	hash, _, err := apitypes.TypedDataAndHash(*data)
+	require.NoError(t, err)
+
+	sig, err := ethcrypto.Sign(hash, key)
+	require.NoError(t, err)
+	sig[ethcrypto.RecoveryIDOffset] += 27 // the V value expected by ecrecover
+
+	permitSig, err := NewPermitSignature(permit, sig)
+	require.NoError(t, err)
+	require.Equal(t, permit.Deadline, permitSig.Deadline)
+	require.Equal(t, sig[64], permitSig.V)
+
+	// the split signature recovers the owner, as the token's permit does
+	recovered := append(append(permitSig.R[:], permitSig.S[:]...), permitSig.V-27)
+	pub, err := ethcrypto.SigToPub(hash, recovered)
+	require.NoError(t, err)
+	require.Equal(t, permit.Owner, ethcrypto.PubkeyToAddress(*pub))
+
+	_, err = NewPermitSignature(permit, sig[:64])
+	require.ErrorContains(t, err, "invalid permit signature length 64")
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+var errERC20ReturnedFalse = errors.New("token returned false")
+
+// ERC20ReturnsBool probes whether the token's transfer and approve methods return the
+// bool value required by the ERC-20 standard. Some older tokens, USDT being the best
+// known, return nothing. The SwapCreator contract requires the return value, so
+// swaps can't be made in those tokens.
+//
+// The probe simulates zero-value calls from the holder with eth_call, so it costs no
+// gas and does not need the holder to own any tokens. An error is returned if a call
+// reverts or returns false, in which case nothing is known about the token.
+func ERC20ReturnsBool(
+	ctx context.Context,
+	caller bind.ContractCaller,
+	token ethcommon.Address,
+	holder ethcommon.Address,
+) (bool, error) {
+	// ERC-20 implementations commonly reject transfers and approvals from the zero
+	// address, so we use the token's own address when we don't have one.
+	if holder == (ethcommon.Address{}) {
+		holder = token
+	}
+
+	for _, method := range []string{"transfer", "approve"} {
+		returnsBool, err := callERC20(ctx, caller, token, holder, method, holder, big.NewInt(0))
+		if err != nil {
+			return false, err
+		}
+
+		if !returnsBool {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// CheckERC20Call simulates an ERC-20 transfer, transferFrom or approve call from the
+// given address with eth_call, before the call is sent in a transaction. Like with
+// OpenZeppelin's SafeERC20, an error is returned if the call reverts or returns false,
+// while empty return data from tokens that don't return a bool is accepted. Without
+// the check, a token that returns false would fail silently in a successful
+// transaction.
+func CheckERC20Call(
+	ctx context.Context,
+	caller bind.ContractCaller,
+	token ethcommon.Address,
+	from ethcommon.Address,
+	method string,
+	args ...interface{},
+) error {
+	_, err := callERC20(ctx, caller, token, from, method, args...)
+	return err
+}
+
+// callERC20 simulates the ERC-20 method call and returns whether the token returned a
+// bool value.
+func callERC20(
+	ctx context.Context,
+	caller bind.ContractCaller,
+	token ethcommon.Address,
+	from ethcommon.Address,
+	method string,
+	args ...interface{},
+) (bool, error) {
+	parsedABI, err := IERC20MetaData.GetAbi()
+	if err != nil {
+		return false, err
+	}
+
+	calldata, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return false, err
+	}
+
+	msg := ethereum.CallMsg{
+		From: from,
+		To:   &token,
+		Data: calldata,
+	}
+
+	ret, err := caller.CallContract(ctx, msg, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to simulate ERC20 %s: %w", method, err)
+	}
+
+	return decodeERC20Return(parsedABI, method, ret)
+}
+
+// decodeERC20Return decodes the return data of an ERC-20 transfer, transferFrom or
+// approve call. Empty return data is accepted, but reported as not being a bool.
+func decodeERC20Return(parsedABI *abi.ABI, method string, ret []byte) (bool, error) {
+	if len(ret) == 0 {
+		return false, nil
+	}
+
+	out, err := parsedABI.Unpack(method, ret)
+	if err != nil {
+		return false, fmt.Errorf("invalid return value from ERC20 %s: %w", method, err)
+	}
+
+	if ok, _ := out[0].(bool); !ok {
+		return true, fmt.Errorf("ERC20 %s failed: %w", method, errERC20ReturnedFalse)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// mockERC20Caller returns the same result for every simulated call
+type mockERC20Caller struct {
+	ret   []byte
+	err   error
+	calls []ethereum.CallMsg
+}
+
+func (c *mockERC20Caller) CodeAt(_ context.Context, _ ethcommon.Address, _ *big.Int) ([]byte, error) {
+	return []byte{0x1}, nil
+}
+
+func (c *mockERC20Caller) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	c.calls = append(c.calls, msg)
+	return c.ret, c.err
+}
+
+var (
+	abiTrue  = ethcommon.LeftPadBytes([]byte{1}, 32)
+	abiFalse = make([]byte, 32)
+)
+
+func TestERC20ReturnsBool(t *testing.T) {
+	token := ethcommon.Address{0x1}
+	holder := ethcommon.Address{0x2}
+
+	type entry struct {
+		name        string
+		ret         []byte
+		callErr     error
+		returnsBool bool
+		errContains string
+	}
+	testEntries := []entry{
+		{name: "standard", ret: abiTrue, returnsBool: true},
+		{name: "no return value", ret: nil, returnsBool: false},
+		{name: "returns false", ret: abiFalse, errContains: "ERC20 transfer failed: token returned false"},
+		{name: "invalid return value", ret: []byte{0x1}, errContains: "invalid return value from ERC20 transfer"},
+		{name: "reverts", callErr: errors.New("execution reverted"), errContains: "failed to simulate ERC20 transfer"},
+	}
+
+	for _, e := range testEntries {
+		caller := &mockERC20Caller{ret: e.ret, err: e.callErr}
+		returnsBool, err := ERC20ReturnsBool(context.Background(), caller, token, holder)
+		if e.errContains != "" {
+			require.ErrorContains(t, err, e.errContains, e.name)
+			continue
+		}
+		require.NoError(t, err, e.name)
+		require.Equal(t, e.returnsBool, returnsBool, e.name)
+		require.Equal(t, holder, caller.calls[0].From, e.name)
+		require.Equal(t, token, *caller.calls[0].To, e.name)
+	}
+}
+
+func TestERC20ReturnsBool_zeroHolder(t *testing.T) {
+	token := ethcommon.Address{0x1}
+	caller := &mockERC20Caller{ret: abiTrue}
+
+	returnsBool, err := ERC20ReturnsBool(context.Background(), caller, token, ethcommon.Address{})
+	require.NoError(t, err)
+	require.True(t, returnsBool)
+	require.Len(t, caller.calls, 2) // transfer and approve
+	for _, msg := range caller.calls {
+		require.Equal(t, token, msg.From)
+	}
+}
+
+func TestCheckERC20Call(t *testing.T) {
+	token := ethcommon.Address{0x1}
+	from := ethcommon.Address{0x2}
+	to := ethcommon.Address{0x3}
+	amount := big.NewInt(100)
+
+	// like SafeERC20, true and empty return data are accepted
+	for _, ret := range [][]byte{abiTrue, nil} {
+		caller := &mockERC20Caller{ret: ret}
+		err := CheckERC20Call(context.Background(), caller, token, from, "transfer", to, amount)
+		require.NoError(t, err)
+
+		parsedABI, err := IERC20MetaData.GetAbi()
+		require.NoError(t, err)
+		expected, err := parsedABI.Pack("transfer", to, amount)
+		require.NoError(t, err)
+		require.Equal(t, expected, caller.calls[0].Data)
+	}
+
+	caller := &mockERC20Caller{ret: abiFalse}
+	err := CheckERC20Call(context.Background(), caller, token, from, "transferFrom", from, to, amount)
+	require.ErrorIs(t, err, errERC20ReturnedFalse)
+
+	caller = &mockERC20Caller{err: errors.New("execution reverted")}
+	err = CheckERC20Call(context.Background(), caller, token, from, "approve", to, amount)
+	require.ErrorContains(t, err, "failed to simulate ERC20 approve: execution reverted")
+}
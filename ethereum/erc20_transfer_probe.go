@@ -0,0 +1,250 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+// holderSearchBlocks is how many of the latest blocks are searched for Transfer
+// events when looking for an account that holds a token.
+const holderSearchBlocks = 1000
+
+var (
+	erc20TransferTopic = ethcrypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	// probeRecipient is the address that simulated probe transfers are sent to. It
+	// only needs to be an address that no one uses.
+	probeRecipient = ethcommon.BytesToAddress(ethcrypto.Keccak256([]byte("atomic-swap ERC20 transfer probe")))
+
+	// ErrNoERC20Holder is returned by FindERC20Holder when no account with a
+	// balance of the token was found.
+	ErrNoERC20Holder = errors.New("no holder of the token found")
+
+	errMulticall3NotDeployed = errors.New("multicall3 contract is not deployed")
+)
+
+// overrideCaller executes an eth_call with state overrides.
+type overrideCaller interface {
+	CallContract(
+		ctx context.Context,
+		msg ethereum.CallMsg,
+		blockNumber *big.Int,
+		overrides *map[ethcommon.Address]gethclient.OverrideAccount,
+	) ([]byte, error)
+}
+
+// ERC20ReceivedAmount simulates the transfer of amount tokens from the holder, who
+// must have a balance of at least amount, and returns how much the recipient's
+// balance increased by. For fee-on-transfer tokens, and rebasing tokens that round
+// balances on transfer, this is less than amount.
+//
+// The simulation places the code of the chain's Multicall3 contract at the holder's
+// address with an eth_call state override, and calls its aggregate3 method to make
+// the transfer from the holder between two balanceOf calls for the recipient. It
+// costs no gas, but it requires Multicall3 to be deployed and an endpoint that
+// supports overrides.
+func ERC20ReceivedAmount(
+	ctx context.Context,
+	ec *ethclient.Client,
+	token ethcommon.Address,
+	holder ethcommon.Address,
+	amount *big.Int,
+) (*big.Int, error) {
+	multicallCode, err := ec.CodeAt(ctx, Multicall3Address, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(multicallCode) == 0 {
+		return nil, errMulticall3NotDeployed
+	}
+
+	return simulateERC20Transfer(ctx, gethclient.New(ec.Client()), multicallCode, token, holder, amount)
+}
+
+// simulateERC20Transfer runs the transfer probe with the given Multicall3 code placed
+// at the holder's address.
+func simulateERC20Transfer(
+	ctx context.Context,
+	caller overrideCaller,
+	multicallCode []byte,
+	token ethcommon.Address,
+	holder ethcommon.Address,
+	amount *big.Int,
+) (*big.Int, error) {
+	erc20ABI, err := IERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	multicallABI, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, err
+	}
+
+	balanceOfData, err := erc20ABI.Pack("balanceOf", probeRecipient)
+	if err != nil {
+		return nil, err
+	}
+
+	transferData, err := erc20ABI.Pack("transfer", probeRecipient, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	calls := []Multicall3Call{
+		{Target: token, CallData: balanceOfData},
+		{Target: token, CallData: transferData},
+		{Target: token, CallData: balanceOfData},
+	}
+
+	calldata, err := multicallABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := map[ethcommon.Address]gethclient.OverrideAccount{
+		holder: {Code: multicallCode},
+	}
+
+	msg := ethereum.CallMsg{
+		From: holder,
+		To:   &holder,
+		Data: calldata,
+	}
+
+	ret, err := caller.CallContract(ctx, msg, nil, &overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate ERC20 transfer: %w", err)
+	}
+
+	out, err := multicallABI.Unpack("aggregate3", ret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transfer probe result: %w", err)
+	}
+
+	results := *abi.ConvertType(out[0], new([]Multicall3Result)).(*[]Multicall3Result)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("transfer probe returned %d results for %d calls", len(results), len(calls))
+	}
+
+	if _, err = decodeERC20Return(erc20ABI, "transfer", results[1].ReturnData); err != nil {
+		return nil, err
+	}
+
+	before, err := decodeERC20Balance(erc20ABI, results[0].ReturnData)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodeERC20Balance(erc20ABI, results[2].ReturnData)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).Sub(after, before), nil
+}
+
+func decodeERC20Balance(erc20ABI *abi.ABI, ret []byte) (*big.Int, error) {
+	out, err := erc20ABI.Unpack("balanceOf", ret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid return value from ERC20 balanceOf: %w", err)
+	}
+
+	return out[0].(*big.Int), nil
+}
+
+// FindERC20Holder returns an account with a non-zero balance of the token, along with
+// its balance. The candidates are checked first, then the recipients of the token's
+// Transfer events in recent blocks, newest first.
+func FindERC20Holder(
+	ctx context.Context,
+	ec *ethclient.Client,
+	token ethcommon.Address,
+	candidates ...ethcommon.Address,
+) (ethcommon.Address, *big.Int, error) {
+	tokenContract, err := NewIERC20(token, ec)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	checked := make(map[ethcommon.Address]struct{})
+
+	check := func(addr ethcommon.Address) (*big.Int, error) {
+		if _, ok := checked[addr]; ok || addr == (ethcommon.Address{}) {
+			return nil, nil
+		}
+		checked[addr] = struct{}{}
+
+		balance, err := tokenContract.BalanceOf(callOpts, addr)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Sign() <= 0 {
+			return nil, nil
+		}
+		return balance, nil
+	}
+
+	for _, addr := range candidates {
+		balance, err := check(addr)
+		if err != nil {
+			return ethcommon.Address{}, nil, err
+		}
+		if balance != nil {
+			return addr, balance, nil
+		}
+	}
+
+	latest, err := ec.BlockNumber(ctx)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	var fromBlock uint64
+	if latest > holderSearchBlocks {
+		fromBlock = latest - holderSearchBlocks
+	}
+
+	logs, err := ec.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(latest),
+		Addresses: []ethcommon.Address{token},
+		Topics:    [][]ethcommon.Hash{{erc20TransferTopic}},
+	})
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	for i := len(logs) - 1; i >= 0; i-- {
+		if len(logs[i].Topics) < 3 {
+			continue
+		}
+
+		addr := ethcommon.BytesToAddress(logs[i].Topics[2].Bytes())
+		balance, err := check(addr)
+		if err != nil {
+			return ethcommon.Address{}, nil, err
+		}
+		if balance != nil {
+			return addr, balance, nil
+		}
+	}
+
+	return ethcommon.Address{}, nil, ErrNoERC20Holder
+}
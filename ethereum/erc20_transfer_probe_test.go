@@ -0,0 +1,124 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/stretchr/testify/require"
+)
+
+// mockToken executes the aggregate3 calls of the transfer probe against an in-memory
+// token, whose transfers deliver the amount minus feePercent and return transferRet.
+type mockToken struct {
+	t             *testing.T
+	token         ethcommon.Address
+	holder        ethcommon.Address
+	multicallCode []byte
+	feePercent    int64
+	transferRet   []byte
+	balance       *big.Int // of the probe recipient
+}
+
+func (m *mockToken) CallContract(
+	_ context.Context,
+	msg ethereum.CallMsg,
+	_ *big.Int,
+	overrides *map[ethcommon.Address]gethclient.OverrideAccount,
+) ([]byte, error) {
+	// the probe must run as the holder, with the multicall code at its address
+	require.Equal(m.t, m.holder, msg.From)
+	require.Equal(m.t, m.holder, *msg.To)
+	require.Equal(m.t, m.multicallCode, (*overrides)[m.holder].Code)
+
+	multicallABI, err := abi.JSON(strings.NewReader(multicall3ABI))
+	require.NoError(m.t, err)
+	erc20ABI, err := IERC20MetaData.GetAbi()
+	require.NoError(m.t, err)
+
+	method := multicallABI.Methods["aggregate3"]
+	require.Equal(m.t, method.ID, msg.Data[:4])
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	require.NoError(m.t, err)
+	calls := *abi.ConvertType(args[0], new([]Multicall3Call)).(*[]Multicall3Call)
+
+	results := make([]Multicall3Result, len(calls))
+	for i, call := range calls {
+		require.Equal(m.t, m.token, call.Target)
+		require.False(m.t, call.AllowFailure)
+
+		switch {
+		case bytes.Equal(call.CallData[:4], erc20ABI.Methods["balanceOf"].ID):
+			ret, packErr := erc20ABI.Methods["balanceOf"].Outputs.Pack(m.balance)
+			require.NoError(m.t, packErr)
+			results[i] = Multicall3Result{Success: true, ReturnData: ret}
+		case bytes.Equal(call.CallData[:4], erc20ABI.Methods["transfer"].ID):
+			transferArgs, unpackErr := erc20ABI.Methods["transfer"].Inputs.Unpack(call.CallData[4:])
+			require.NoError(m.t, unpackErr)
+			require.Equal(m.t, probeRecipient, transferArgs[0])
+			amount := transferArgs[1].(*big.Int)
+			fee := new(big.Int).Div(new(big.Int).Mul(amount, big.NewInt(m.feePercent)), big.NewInt(100))
+			m.balance = new(big.Int).Add(m.balance, new(big.Int).Sub(amount, fee))
+			results[i] = Multicall3Result{Success: true, ReturnData: m.transferRet}
+		default:
+			return nil, errors.New("unexpected call")
+		}
+	}
+
+	return multicallABI.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+func TestSimulateERC20Transfer(t *testing.T) {
+	token := ethcommon.Address{0x1}
+	holder := ethcommon.Address{0x2}
+	multicallCode := []byte{0x60, 0x80}
+	amount := big.NewInt(1000)
+
+	type entry struct {
+		name        string
+		feePercent  int64
+		transferRet []byte
+		received    *big.Int
+	}
+	testEntries := []entry{
+		{name: "standard", transferRet: abiTrue, received: big.NewInt(1000)},
+		{name: "fee on transfer", feePercent: 1, transferRet: abiTrue, received: big.NewInt(990)},
+		{name: "no return value", transferRet: nil, received: big.NewInt(1000)},
+	}
+
+	for _, e := range testEntries {
+		caller := &mockToken{
+			t:             t,
+			token:         token,
+			holder:        holder,
+			multicallCode: multicallCode,
+			feePercent:    e.feePercent,
+			transferRet:   e.transferRet,
+			balance:       big.NewInt(5), // the recipient's existing balance is ignored
+		}
+		received, err := simulateERC20Transfer(context.Background(), caller, multicallCode, token, holder, amount)
+		require.NoError(t, err, e.name)
+		require.Equal(t, e.received.String(), received.String(), e.name)
+	}
+
+	caller := &mockToken{
+		t:             t,
+		token:         token,
+		holder:        holder,
+		multicallCode: multicallCode,
+		transferRet:   abiFalse,
+		balance:       big.NewInt(0),
+	}
+	_, err := simulateERC20Transfer(context.Background(), caller, multicallCode, token, holder, amount)
+	require.ErrorIs(t, err, errERC20ReturnedFalse)
+}
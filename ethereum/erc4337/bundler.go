@@ -0,0 +1,152 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package erc4337
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+var errEntryPointNotSupported = errors.New("bundler does not support the entry point")
+
+// SponsorResult is the result of pm_sponsorUserOperation. Paymasters may adjust the
+// gas limits when sponsoring, in which case they are returned as well.
+type SponsorResult struct {
+	PaymasterAndData     hexutil.Bytes `json:"paymasterAndData"`
+	PreVerificationGas   *hexutil.Big  `json:"preVerificationGas,omitempty"`
+	VerificationGasLimit *hexutil.Big  `json:"verificationGasLimit,omitempty"`
+	CallGasLimit         *hexutil.Big  `json:"callGasLimit,omitempty"`
+}
+
+// UserOperationReceipt is the result of eth_getUserOperationReceipt.
+type UserOperationReceipt struct {
+	UserOpHash ethcommon.Hash    `json:"userOpHash"`
+	Sender     ethcommon.Address `json:"sender"`
+	Success    bool              `json:"success"`
+	Reason     string            `json:"reason"`
+	Receipt    *ethtypes.Receipt `json:"receipt"`
+}
+
+// BundlerClient talks to an ERC-4337 bundler's JSON-RPC API.
+type BundlerClient struct {
+	client     *rpc.Client
+	entryPoint ethcommon.Address
+}
+
+// NewBundlerClient connects to the bundler at the endpoint, through the SOCKS5 proxy
+// unless it is nil, and checks that it supports the entry point.
+func NewBundlerClient(
+	ctx context.Context,
+	endpoint string,
+	entryPoint ethcommon.Address,
+	proxy *common.Proxy,
+) (*BundlerClient, error) {
+	rpcOpts := []rpc.ClientOption{
+		rpc.WithHTTPClient(&http.Client{Transport: proxy.HTTPTransport()}),
+	}
+	if proxy != nil {
+		rpcOpts = append(rpcOpts, rpc.WithWebsocketDialer(websocket.Dialer{
+			Proxy:           proxy.ProxyURL,
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		}))
+	}
+
+	client, err := rpc.DialOptions(ctx, endpoint, rpcOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var entryPoints []ethcommon.Address
+	if err = client.CallContext(ctx, &entryPoints, "eth_supportedEntryPoints"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to get bundler's supported entry points: %w", err)
+	}
+
+	for _, ep := range entryPoints {
+		if ep == entryPoint {
+			return &BundlerClient{client: client, entryPoint: entryPoint}, nil
+		}
+	}
+
+	client.Close()
+	return nil, fmt.Errorf("%w %s", errEntryPointNotSupported, entryPoint)
+}
+
+// EntryPoint returns the entry point that user operations are submitted to.
+func (c *BundlerClient) EntryPoint() ethcommon.Address {
+	return c.entryPoint
+}
+
+// Close closes the connection to the bundler.
+func (c *BundlerClient) Close() {
+	c.client.Close()
+}
+
+// SponsorUserOperation asks a paymaster to pay for the user operation's gas. Only
+// paymaster services implementing pm_sponsorUserOperation are supported.
+func (c *BundlerClient) SponsorUserOperation(ctx context.Context, op *UserOperation) (*SponsorResult, error) {
+	result := new(SponsorResult)
+	err := c.client.CallContext(ctx, result, "pm_sponsorUserOperation", op, c.entryPoint)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendUserOperation submits the signed user operation and returns its hash.
+func (c *BundlerClient) SendUserOperation(ctx context.Context, op *UserOperation) (ethcommon.Hash, error) {
+	var opHash ethcommon.Hash
+	if err := c.client.CallContext(ctx, &opHash, "eth_sendUserOperation", op, c.entryPoint); err != nil {
+		return ethcommon.Hash{}, err
+	}
+	return opHash, nil
+}
+
+// GetUserOperationReceipt returns the receipt of the user operation, or nil if it
+// has not been included yet.
+func (c *BundlerClient) GetUserOperationReceipt(
+	ctx context.Context,
+	opHash ethcommon.Hash,
+) (*UserOperationReceipt, error) {
+	var receipt *UserOperationReceipt
+	if err := c.client.CallContext(ctx, &receipt, "eth_getUserOperationReceipt", opHash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// WaitForUserOperationReceipt polls the bundler until the user operation is included.
+func (c *BundlerClient) WaitForUserOperationReceipt(
+	ctx context.Context,
+	opHash ethcommon.Hash,
+) (*UserOperationReceipt, error) {
+	const pollInterval = 2 * time.Second
+
+	for {
+		receipt, err := c.GetUserOperationReceipt(ctx, opHash)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+
+		log.Debugf("waiting for user operation %s to be included", opHash)
+		if err = common.SleepWithContext(ctx, pollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
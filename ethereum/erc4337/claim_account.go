@@ -0,0 +1,276 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package erc4337
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+var (
+	errNoClaimAccount        = errors.New("no claim account contract")
+	errWrongSwapCreator      = errors.New("claim account uses a different swap creator contract")
+	errNoBaseFee             = errors.New("latest block has no base fee, the chain must support EIP-1559")
+	errClaimAlreadySubmitted = errors.New("a user operation already claimed the swap through the claim account")
+	errNotSponsored          = errors.New("paymaster did not sponsor the user operation")
+)
+
+const swapCreatorSwapComponents = `[
+	{"name":"owner","type":"address"},{"name":"claimer","type":"address"},
+	{"name":"pubKeyClaim","type":"bytes32"},{"name":"pubKeyRefund","type":"bytes32"},
+	{"name":"timeout0","type":"uint256"},{"name":"timeout1","type":"uint256"},
+	{"name":"asset","type":"address"},{"name":"value","type":"uint256"},{"name":"nonce","type":"uint256"}
+]`
+
+var claimAccountABIJSON = `[
+	{"type":"function","name":"claim","stateMutability":"nonpayable",
+		"inputs":[{"name":"_claim","type":"tuple","components":[
+			{"name":"swap","type":"tuple","components":` + swapCreatorSwapComponents + `},
+			{"name":"secret","type":"bytes32"},{"name":"fee","type":"uint256"},
+			{"name":"gas","type":"uint256"},{"name":"nonce","type":"uint256"},
+			{"name":"domainSeparator","type":"bytes32"},{"name":"signature","type":"bytes"}]}],
+		"outputs":[]},
+	{"type":"function","name":"swapCreator","stateMutability":"view",
+		"inputs":[],
+		"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"getNonce","stateMutability":"view",
+		"inputs":[{"name":"sender","type":"address"},{"name":"key","type":"uint192"}],
+		"outputs":[{"name":"nonce","type":"uint256"}]}
+]`
+
+// claimAccountABI holds the methods we use of the SwapClaimAccount and EntryPoint
+// contracts.
+var claimAccountABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(claimAccountABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Gas limits sent to the paymaster before it fills in the real values.
+const (
+	defaultCallGasLimit         = 300_000
+	defaultVerificationGasLimit = 500_000
+	defaultPreVerificationGas   = 60_000
+)
+
+// Claim is a relayed claim of a swap, submitted through the SwapClaimAccount
+// contract. The forward request that calls the swap creator's claimRelayer is
+// built by the account from these fields, and must be signed by the swap's
+// claimer.
+type Claim struct {
+	Swap            contracts.SwapCreatorSwap
+	Secret          [32]byte
+	Fee             *big.Int
+	Gas             *big.Int // gas of the forward request
+	Nonce           *big.Int // forwarder nonce of the claimer
+	DomainSeparator [32]byte
+	Signature       []byte
+}
+
+// ClaimAccount is a deployed SwapClaimAccount contract. The account has no owner:
+// it validates user operations with the swap secret and the claimer's signature of
+// the forward request, so the claimer does not need ETH. The operation's prefund
+// is paid by the paymaster service behind the bundler endpoint, as the account
+// rejects operations without a paymaster.
+type ClaimAccount struct {
+	ec      *ethclient.Client
+	bundler *BundlerClient
+	address ethcommon.Address
+}
+
+// NewClaimAccount returns the SwapClaimAccount deployed at the given address, which
+// must claim swaps of the given swap creator contract.
+func NewClaimAccount(
+	ctx context.Context,
+	ec *ethclient.Client,
+	bundler *BundlerClient,
+	address ethcommon.Address,
+	swapCreatorAddr ethcommon.Address,
+) (*ClaimAccount, error) {
+	code, err := ec.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("%w at %s", errNoClaimAccount, address)
+	}
+
+	data, err := claimAccountABI.Pack("swapCreator")
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := ec.CallContract(ctx, ethereum.CallMsg{To: &address, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swap creator of claim account %s: %w", address, err)
+	}
+
+	var accountSwapCreator ethcommon.Address
+	if err = claimAccountABI.UnpackIntoInterface(&accountSwapCreator, "swapCreator", ret); err != nil {
+		return nil, err
+	}
+	if accountSwapCreator != swapCreatorAddr {
+		return nil, fmt.Errorf("%w: %s", errWrongSwapCreator, accountSwapCreator)
+	}
+
+	return &ClaimAccount{
+		ec:      ec,
+		bundler: bundler,
+		address: address,
+	}, nil
+}
+
+// Address returns the address of the account contract.
+func (a *ClaimAccount) Address() ethcommon.Address {
+	return a.address
+}
+
+// Claim submits the claim as a user operation and waits for it to be included. A
+// swap can only be claimed once through the account, as the operation's nonce key
+// is the swap ID.
+func (a *ClaimAccount) Claim(ctx context.Context, claim *Claim) (*UserOperationReceipt, error) {
+	op, err := a.newUserOperation(ctx, claim)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.sponsorGas(ctx, op); err != nil {
+		return nil, err
+	}
+
+	sentHash, err := a.bundler.SendUserOperation(ctx, op)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send user operation: %w", err)
+	}
+	log.Infof("sent user operation %s claiming swap %s", sentHash, claim.Swap.SwapID())
+
+	receipt, err := a.bundler.WaitForUserOperationReceipt(ctx, sentHash)
+	if err != nil {
+		return nil, err
+	}
+	if !receipt.Success {
+		return nil, fmt.Errorf("user operation %s failed: %s", sentHash, receipt.Reason)
+	}
+
+	return receipt, nil
+}
+
+// newUserOperation returns the user operation of the claim, without gas limits.
+// The account validates the claim itself, so the operation has no signature.
+func (a *ClaimAccount) newUserOperation(ctx context.Context, claim *Claim) (*UserOperation, error) {
+	callData, err := claimAccountABI.Pack("claim", *claim)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceKey := claimNonceKey(claim.Swap.SwapID())
+	nonce, err := a.nonce(ctx, nonceKey)
+	if err != nil {
+		return nil, err
+	}
+	if nonce.Cmp(new(big.Int).Lsh(nonceKey, 64)) != 0 {
+		return nil, errClaimAlreadySubmitted
+	}
+
+	tipCap, err := a.ec.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := a.ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	feeCap, err := maxFeePerGas(header, tipCap)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserOperation{
+		Sender:               a.address,
+		Nonce:                (*hexutil.Big)(nonce),
+		InitCode:             []byte{},
+		CallData:             callData,
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(defaultCallGasLimit)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(defaultVerificationGasLimit)),
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(defaultPreVerificationGas)),
+		MaxFeePerGas:         (*hexutil.Big)(feeCap),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tipCap),
+		PaymasterAndData:     []byte{},
+		Signature:            []byte{},
+	}, nil
+}
+
+// claimNonceKey returns the nonce key of the user operation claiming the swap, which
+// the account requires to be the low 192 bits of the swap ID.
+func claimNonceKey(swapID [32]byte) *big.Int {
+	return new(big.Int).SetBytes(swapID[8:])
+}
+
+// maxFeePerGas returns the fee cap of a user operation, which leaves room for the
+// base fee to double before the operation is included.
+func maxFeePerGas(header *ethtypes.Header, tipCap *big.Int) (*big.Int, error) {
+	if header.BaseFee == nil {
+		return nil, errNoBaseFee
+	}
+	return new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tipCap), nil
+}
+
+// nonce returns the account's next nonce with the given key from the entry point.
+func (a *ClaimAccount) nonce(ctx context.Context, key *big.Int) (*big.Int, error) {
+	data, err := claimAccountABI.Pack("getNonce", a.address, key)
+	if err != nil {
+		return nil, err
+	}
+
+	entryPoint := a.bundler.EntryPoint()
+	ret, err := a.ec.CallContract(ctx, ethereum.CallMsg{To: &entryPoint, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account nonce from entry point: %w", err)
+	}
+
+	nonce := new(big.Int)
+	if err = claimAccountABI.UnpackIntoInterface(&nonce, "getNonce", ret); err != nil {
+		return nil, err
+	}
+
+	return nonce, nil
+}
+
+func (a *ClaimAccount) sponsorGas(ctx context.Context, op *UserOperation) error {
+	result, err := a.bundler.SponsorUserOperation(ctx, op)
+	if err != nil {
+		return fmt.Errorf("failed to get user operation sponsored: %w", err)
+	}
+	if len(result.PaymasterAndData) == 0 {
+		return errNotSponsored
+	}
+
+	op.PaymasterAndData = result.PaymasterAndData
+	if result.CallGasLimit != nil {
+		op.CallGasLimit = result.CallGasLimit
+	}
+	if result.VerificationGasLimit != nil {
+		op.VerificationGasLimit = result.VerificationGasLimit
+	}
+	if result.PreVerificationGas != nil {
+		op.PreVerificationGas = result.PreVerificationGas
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package erc4337
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+func TestClaimAccountABI_claim(t *testing.T) {
+	claim := Claim{
+		Swap: contracts.SwapCreatorSwap{
+			Owner:        ethcommon.Address{0x1},
+			Claimer:      ethcommon.Address{0x2},
+			PubKeyClaim:  [32]byte{0x3},
+			PubKeyRefund: [32]byte{0x4},
+			Timeout0:     big.NewInt(100),
+			Timeout1:     big.NewInt(200),
+			Asset:        ethcommon.Address{},
+			Value:        big.NewInt(1e18),
+			Nonce:        big.NewInt(7),
+		},
+		Secret:          [32]byte{0x5},
+		Fee:             big.NewInt(1e15),
+		Gas:             big.NewInt(100_000),
+		Nonce:           big.NewInt(3),
+		DomainSeparator: [32]byte{0x6},
+		Signature:       make([]byte, 65),
+	}
+
+	callData, err := claimAccountABI.Pack("claim", claim)
+	require.NoError(t, err)
+
+	// selector of claim(((address,address,bytes32,bytes32,uint256,uint256,address,uint256,uint256),
+	// bytes32,uint256,uint256,uint256,bytes32,bytes)) in SwapClaimAccount.sol
+	require.Equal(t, "0x22d5ab58", hexutil.Encode(callData[:4]))
+
+	args, err := claimAccountABI.Methods["claim"].Inputs.Unpack(callData[4:])
+	require.NoError(t, err)
+	require.Len(t, args, 1)
+
+	unpacked := *abi.ConvertType(args[0], new(Claim)).(*Claim)
+	require.Equal(t, claim.Swap.SwapID(), unpacked.Swap.SwapID())
+	require.Equal(t, claim.Secret, unpacked.Secret)
+	require.Equal(t, claim.Nonce, unpacked.Nonce)
+	require.Equal(t, claim.DomainSeparator, unpacked.DomainSeparator)
+	require.Equal(t, claim.Signature, unpacked.Signature)
+}
+
+func TestClaimNonceKey(t *testing.T) {
+	swapID := ethcommon.HexToHash("0x0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20")
+
+	// the account requires the key to be uint192(uint256(swapID))
+	key := claimNonceKey(swapID)
+	require.Equal(t, "0x90a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20", hexutil.EncodeBig(key))
+	require.LessOrEqual(t, key.BitLen(), 192)
+}
+
+func TestMaxFeePerGas(t *testing.T) {
+	tipCap := big.NewInt(1e9)
+
+	feeCap, err := maxFeePerGas(&ethtypes.Header{BaseFee: big.NewInt(10e9)}, tipCap)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(21e9), feeCap)
+
+	// pre-London and some L2 headers have no base fee
+	_, err = maxFeePerGas(&ethtypes.Header{}, tipCap)
+	require.ErrorIs(t, err, errNoBaseFee)
+}
+
+// mockPaymaster implements pm_sponsorUserOperation
+type mockPaymaster struct {
+	result *SponsorResult
+}
+
+func (p *mockPaymaster) SponsorUserOperation(_ *UserOperation, _ ethcommon.Address) (*SponsorResult, error) {
+	return p.result, nil
+}
+
+func newTestClaimAccount(t *testing.T, paymaster *mockPaymaster) *ClaimAccount {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("pm", paymaster))
+	t.Cleanup(server.Stop)
+
+	return &ClaimAccount{
+		bundler: &BundlerClient{client: rpc.DialInProc(server), entryPoint: DefaultEntryPointAddress},
+	}
+}
+
+func TestClaimAccount_sponsorGas(t *testing.T) {
+	ctx := context.Background()
+	paymaster := &mockPaymaster{
+		result: &SponsorResult{
+			PaymasterAndData: []byte{0x1, 0x2},
+			CallGasLimit:     (*hexutil.Big)(big.NewInt(200_000)),
+		},
+	}
+	account := newTestClaimAccount(t, paymaster)
+
+	op := testUserOperation()
+	err := account.sponsorGas(ctx, op)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Bytes{0x1, 0x2}, op.PaymasterAndData)
+	require.Equal(t, big.NewInt(200_000), op.CallGasLimit.ToInt())
+	require.Equal(t, big.NewInt(150_000), op.VerificationGasLimit.ToInt())
+
+	// the account rejects operations without a paymaster, so they aren't sent
+	paymaster.result = &SponsorResult{}
+	op = testUserOperation()
+	err = account.sponsorGas(ctx, op)
+	require.ErrorIs(t, err, errNotSponsored)
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package erc4337 provides the types and clients needed to submit ERC-4337
+// UserOperations to a bundler for the v0.6 EntryPoint contract.
+package erc4337
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	logging "github.com/ipfs/go-log"
+)
+
+// DefaultEntryPointAddress is the address of the v0.6 EntryPoint contract, which
+// is deployed at the same address on all chains that support ERC-4337.
+var DefaultEntryPointAddress = ethcommon.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+
+var log = logging.Logger("erc4337")
+
+// UserOperation is an ERC-4337 user operation, as defined by the v0.6 EntryPoint.
+type UserOperation struct {
+	Sender               ethcommon.Address `json:"sender"`
+	Nonce                *hexutil.Big      `json:"nonce"`
+	InitCode             hexutil.Bytes     `json:"initCode"`
+	CallData             hexutil.Bytes     `json:"callData"`
+	CallGasLimit         *hexutil.Big      `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big      `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big      `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes     `json:"paymasterAndData"`
+	Signature            hexutil.Bytes     `json:"signature"`
+}
+
+var userOpPackArgs = func() abi.Arguments {
+	addressTy, _ := abi.NewType("address", "", nil)
+	uint256Ty, _ := abi.NewType("uint256", "", nil)
+	bytes32Ty, _ := abi.NewType("bytes32", "", nil)
+	return abi.Arguments{
+		{Type: addressTy}, // sender
+		{Type: uint256Ty}, // nonce
+		{Type: bytes32Ty}, // keccak(initCode)
+		{Type: bytes32Ty}, // keccak(callData)
+		{Type: uint256Ty}, // callGasLimit
+		{Type: uint256Ty}, // verificationGasLimit
+		{Type: uint256Ty}, // preVerificationGas
+		{Type: uint256Ty}, // maxFeePerGas
+		{Type: uint256Ty}, // maxPriorityFeePerGas
+		{Type: bytes32Ty}, // keccak(paymasterAndData)
+	}
+}()
+
+var userOpHashArgs = func() abi.Arguments {
+	addressTy, _ := abi.NewType("address", "", nil)
+	uint256Ty, _ := abi.NewType("uint256", "", nil)
+	bytes32Ty, _ := abi.NewType("bytes32", "", nil)
+	return abi.Arguments{
+		{Type: bytes32Ty}, // keccak(pack(userOp))
+		{Type: addressTy}, // entryPoint
+		{Type: uint256Ty}, // chainID
+	}
+}()
+
+// Hash returns the hash of the user operation that the account signs. It matches
+// EntryPoint.getUserOpHash, so it commits to the entry point and chain ID.
+func (op *UserOperation) Hash(entryPoint ethcommon.Address, chainID *big.Int) (ethcommon.Hash, error) {
+	packed, err := userOpPackArgs.Pack(
+		op.Sender,
+		op.Nonce.ToInt(),
+		ethcrypto.Keccak256Hash(op.InitCode),
+		ethcrypto.Keccak256Hash(op.CallData),
+		op.CallGasLimit.ToInt(),
+		op.VerificationGasLimit.ToInt(),
+		op.PreVerificationGas.ToInt(),
+		op.MaxFeePerGas.ToInt(),
+		op.MaxPriorityFeePerGas.ToInt(),
+		ethcrypto.Keccak256Hash(op.PaymasterAndData),
+	)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	encoded, err := userOpHashArgs.Pack(ethcrypto.Keccak256Hash(packed), entryPoint, chainID)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	return ethcrypto.Keccak256Hash(encoded), nil
+}
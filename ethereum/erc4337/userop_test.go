@@ -0,0 +1,67 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package erc4337
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func testUserOperation() *UserOperation {
+	return &UserOperation{
+		Sender:               ethcommon.HexToAddress("0x1000000000000000000000000000000000000001"),
+		Nonce:                (*hexutil.Big)(big.NewInt(1)),
+		CallData:             []byte{0xb6, 0x1d, 0x27, 0xf6},
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(100_000)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(150_000)),
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(50_000)),
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(2_000_000_000)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1_000_000_000)),
+	}
+}
+
+func TestUserOperation_Hash(t *testing.T) {
+	op := testUserOperation()
+
+	hash, err := op.Hash(DefaultEntryPointAddress, big.NewInt(1))
+	require.NoError(t, err)
+
+	// the signature is not part of the hash
+	op.Signature = make([]byte, 65)
+	sameHash, err := op.Hash(DefaultEntryPointAddress, big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, hash, sameHash)
+
+	// the hash commits to the chain ID and entry point
+	otherChainHash, err := op.Hash(DefaultEntryPointAddress, big.NewInt(5))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherChainHash)
+
+	otherEntryPointHash, err := op.Hash(ethcommon.Address{0x1}, big.NewInt(1))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherEntryPointHash)
+
+	// and to the fields of the operation
+	op.Nonce = (*hexutil.Big)(big.NewInt(2))
+	otherNonceHash, err := op.Hash(DefaultEntryPointAddress, big.NewInt(1))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherNonceHash)
+}
+
+func TestUserOperation_JSON(t *testing.T) {
+	data, err := json.Marshal(testUserOperation())
+	require.NoError(t, err)
+
+	fields := make(map[string]string)
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.Equal(t, "0x1", fields["nonce"])
+	require.Equal(t, "0x186a0", fields["callGasLimit"])
+	require.Equal(t, "0x", fields["initCode"])
+	require.Equal(t, "0xb61d27f6", fields["callData"])
+}
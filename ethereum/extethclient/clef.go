@@ -0,0 +1,99 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package extethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var errNoClefAccounts = errors.New("external signer did not list any accounts")
+
+// clefSigner delegates all signing to an external Clef instance. Clef receives the
+// unsigned transactions and the full EIP-712 typed data, so that its rules can
+// decide what to sign.
+type clefSigner struct {
+	signer  *external.ExternalSigner
+	client  *rpc.Client
+	account accounts.Account
+}
+
+// NewClefSigner returns a Signer that delegates signing to the Clef instance at the
+// given endpoint (an IPC path or HTTP URL). If account is nil, the first account
+// listed by Clef is used.
+func NewClefSigner(ctx context.Context, endpoint string, account *ethcommon.Address) (Signer, error) {
+	signer, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer: %w", err)
+	}
+
+	// ExternalSigner does not expose typed data signing, so we keep our own client
+	// for account_signTypedData
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer: %w", err)
+	}
+
+	s := &clefSigner{
+		signer: signer,
+		client: client,
+	}
+
+	// Accounts() requires approval in Clef's UI unless a rule allows listing
+	listed := signer.Accounts()
+	switch {
+	case account != nil:
+		s.account = accounts.Account{Address: *account}
+		if !signer.Contains(s.account) {
+			client.Close()
+			return nil, fmt.Errorf("external signer does not manage account %s", account)
+		}
+	case len(listed) > 0:
+		s.account = listed[0]
+	default:
+		client.Close()
+		return nil, errNoClefAccounts
+	}
+
+	log.Infof("using external signer %s with account %s", endpoint, s.account.Address)
+	return s, nil
+}
+
+func (s *clefSigner) Address() ethcommon.Address {
+	return s.account.Address
+}
+
+func (s *clefSigner) SignTx(tx *ethtypes.Transaction, chainID *big.Int) (*ethtypes.Transaction, error) {
+	return s.signer.SignTx(s.account, tx, chainID)
+}
+
+func (s *clefSigner) SignTypedData(data *apitypes.TypedData) ([]byte, error) {
+	var sig hexutil.Bytes
+	addr := ethcommon.NewMixedcaseAddress(s.account.Address)
+	if err := s.client.Call(&sig, "account_signTypedData", &addr, data); err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("external signer returned signature of invalid length %d", len(sig))
+	}
+	return normalizeSignatureV(sig), nil
+}
+
+func (s *clefSigner) SignText(text []byte) ([]byte, error) {
+	sig, err := s.signer.SignText(s.account, text)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeSignatureV(sig), nil
+}
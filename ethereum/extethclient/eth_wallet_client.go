@@ -8,39 +8,47 @@ package extethclient
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
 	"time"
 
+	"github.com/ChainSafe/chaindb"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
 	logging "github.com/ipfs/go-log"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
+	"github.com/athanorlabs/atomic-swap/metrics"
 )
 
 var log = logging.Logger("extethclient")
 
-// EthClient provides management of a private key and other convenience functions layered
+// EthClient provides management of a signing key and other convenience functions layered
 // on top of the go-ethereum client. You can still access the raw go-ethereum client via
 // the Raw() method.
 type EthClient interface {
 	Address() ethcommon.Address
 	SetAddress(addr ethcommon.Address)
 	PrivateKey() *ecdsa.PrivateKey
-	HasPrivateKey() bool
+	Signer() Signer
+	HasSigner() bool
 	Endpoint() string
 
 	Balance(ctx context.Context) (*coins.WeiAmount, error)
 	ERC20Balance(ctx context.Context, token ethcommon.Address) (*coins.ERC20TokenAmount, error)
 
 	ERC20Info(ctx context.Context, tokenAddr ethcommon.Address) (*coins.ERC20TokenInfo, error)
+	SetTokenInfoCache(cache TokenInfoCache)
 
 	SetGasPrice(uint64)
 	SetGasLimit(uint64)
@@ -52,6 +60,12 @@ type EthClient interface {
 	Unlock() // Unlock the wallet after a transaction is complete
 
 	WaitForReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error)
+	TransferETH(
+		ctx context.Context,
+		to ethcommon.Address,
+		amount *coins.WeiAmount,
+		gasPrice *big.Int,
+	) (*ethtypes.Receipt, *coins.WeiAmount, error)
 	WaitForTimestamp(ctx context.Context, ts time.Time) error
 	LatestBlockTimestamp(ctx context.Context) (time.Time, error)
 
@@ -59,15 +73,24 @@ type EthClient interface {
 	Raw() *ethclient.Client
 }
 
+// TokenInfoCache persists the metadata of ERC20 tokens, so the metadata of a token is
+// only looked up on chain the first time that it is needed.
+type TokenInfoCache interface {
+	GetTokenInfo(chainID *big.Int, tokenAddr ethcommon.Address) (*coins.ERC20TokenInfo, error)
+	PutTokenInfo(chainID *big.Int, tokenInfo *coins.ERC20TokenInfo) error
+}
+
 type ethClient struct {
-	endpoint   string
-	ec         *ethclient.Client
-	ethPrivKey *ecdsa.PrivateKey
-	ethAddress ethcommon.Address
-	gasPrice   *big.Int
-	gasLimit   uint64
-	chainID    *big.Int
-	mu         sync.Mutex
+	endpoint       string
+	ec             *ethclient.Client
+	ethPrivKey     *ecdsa.PrivateKey // only set if signer holds a raw key
+	signer         Signer
+	ethAddress     ethcommon.Address
+	gasPrice       *big.Int
+	gasLimit       uint64
+	chainID        *big.Int
+	tokenInfoCache TokenInfoCache // optional
+	mu             sync.Mutex
 }
 
 // NewEthClient creates and returns our extended ethereum client/wallet. The passed context
@@ -78,11 +101,62 @@ func NewEthClient(
 	endpoint string,
 	privKey *ecdsa.PrivateKey,
 ) (EthClient, error) {
-	ec, err := ethclient.Dial(endpoint)
+	return NewEthClientWithProxy(ctx, env, endpoint, privKey, nil)
+}
+
+// NewEthClientWithProxy is like NewEthClient, but connects to the endpoint through the
+// SOCKS5 proxy, unless it is nil.
+func NewEthClientWithProxy(
+	ctx context.Context,
+	env common.Environment,
+	endpoint string,
+	privKey *ecdsa.PrivateKey,
+	proxy *common.Proxy,
+) (EthClient, error) {
+	var signer Signer
+	if privKey != nil {
+		signer = NewPrivateKeySigner(privKey)
+	}
+
+	ec, err := NewEthClientWithSigner(ctx, env, endpoint, signer, proxy)
 	if err != nil {
 		return nil, err
 	}
 
+	ec.(*ethClient).ethPrivKey = privKey
+	return ec, nil
+}
+
+// NewEthClientWithSigner creates and returns our extended ethereum client/wallet that
+// signs transactions with the passed signer. The signer can be nil if you are using an
+// external signer. The endpoint is connected to through the SOCKS5 proxy, unless it is
+// nil.
+func NewEthClientWithSigner(
+	ctx context.Context,
+	env common.Environment,
+	endpoint string,
+	signer Signer,
+	proxy *common.Proxy,
+) (EthClient, error) {
+	// The HTTP client is only used for http(s) endpoints, so the latencies of
+	// websocket endpoints are not recorded
+	rpcOpts := []rpc.ClientOption{
+		rpc.WithHTTPClient(metrics.NewHTTPClient(metrics.EndpointEth, proxy.HTTPTransport())),
+	}
+	if proxy != nil {
+		rpcOpts = append(rpcOpts, rpc.WithWebsocketDialer(websocket.Dialer{
+			Proxy:           proxy.ProxyURL,
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		}))
+	}
+
+	rpcClient, err := rpc.DialOptions(ctx, endpoint, rpcOpts...)
+	if err != nil {
+		return nil, err
+	}
+	ec := ethclient.NewClient(rpcClient)
+
 	chainID, err := ec.ChainID(ctx)
 	if err != nil {
 		return nil, err
@@ -93,14 +167,14 @@ func NewEthClient(
 	}
 
 	var addr ethcommon.Address
-	if privKey != nil {
-		addr = common.EthereumPrivateKeyToAddress(privKey)
+	if signer != nil {
+		addr = signer.Address()
 	}
 
 	return &ethClient{
 		endpoint:   endpoint,
 		ec:         ec,
-		ethPrivKey: privKey,
+		signer:     signer,
 		ethAddress: addr,
 		chainID:    chainID,
 	}, nil
@@ -111,18 +185,28 @@ func (c *ethClient) Address() ethcommon.Address {
 }
 
 func (c *ethClient) SetAddress(addr ethcommon.Address) {
-	if c.HasPrivateKey() {
+	if c.HasSigner() {
 		panic("SetAddress should not have been invoked when using an external signer")
 	}
 	c.ethAddress = addr
 }
 
+// PrivateKey returns the raw ethereum private key, if swapd was started with one.
+// It returns nil if signing is done by a hardware wallet or an external signer.
 func (c *ethClient) PrivateKey() *ecdsa.PrivateKey {
 	return c.ethPrivKey
 }
 
-func (c *ethClient) HasPrivateKey() bool {
-	return c.ethPrivKey != nil
+// Signer returns the signer of swapd's ethereum account, or nil when using an
+// external signer.
+func (c *ethClient) Signer() Signer {
+	return c.signer
+}
+
+// HasSigner returns true if swapd can sign its own transactions, false if
+// transactions are signed by an external signer (the swap UI).
+func (c *ethClient) HasSigner() bool {
+	return c.signer != nil
 }
 
 // Endpoint returns the endpoint URL that we are connected to
@@ -172,6 +256,36 @@ func (c *ethClient) erc20Info(
 	ctx context.Context,
 	tokenAddr ethcommon.Address,
 	tokenContract *contracts.IERC20,
+) (*coins.ERC20TokenInfo, error) {
+	if c.tokenInfoCache != nil {
+		tokenInfo, err := c.tokenInfoCache.GetTokenInfo(c.chainID, tokenAddr)
+		if err == nil {
+			return tokenInfo, nil
+		}
+		if !errors.Is(err, chaindb.ErrKeyNotFound) {
+			log.Warnf("failed to get cached metadata of token %s: %s", tokenAddr, err)
+		}
+	}
+
+	tokenInfo, err := c.queryERC20Info(ctx, tokenAddr, tokenContract)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.tokenInfoCache != nil {
+		if err = c.tokenInfoCache.PutTokenInfo(c.chainID, tokenInfo); err != nil {
+			log.Warnf("failed to cache metadata of token %s: %s", tokenAddr, err)
+		}
+	}
+
+	return tokenInfo, nil
+}
+
+// queryERC20Info looks up the metadata of the token on chain.
+func (c *ethClient) queryERC20Info(
+	ctx context.Context,
+	tokenAddr ethcommon.Address,
+	tokenContract *contracts.IERC20,
 ) (*coins.ERC20TokenInfo, error) {
 	name, err := tokenContract.Name(c.CallOpts(ctx))
 	if err != nil {
@@ -201,6 +315,12 @@ func (c *ethClient) ERC20Info(ctx context.Context, tokenAddr ethcommon.Address)
 	return c.erc20Info(ctx, tokenAddr, tokenContract)
 }
 
+// SetTokenInfoCache sets the cache of token metadata used by ERC20Info and
+// ERC20Balance. It must be set before the client is used concurrently.
+func (c *ethClient) SetTokenInfoCache(cache TokenInfoCache) {
+	c.tokenInfoCache = cache
+}
+
 // SetGasPrice sets the ethereum gas price (in wei) for use in transactions. In most
 // cases, you should not use this function and let the ethereum client determine the
 // suggested gas price at the current time. Setting a value of zero reverts to using
@@ -230,15 +350,20 @@ func (c *ethClient) CallOpts(ctx context.Context) *bind.CallOpts {
 }
 
 func (c *ethClient) TxOpts(ctx context.Context) (*bind.TransactOpts, error) {
-	if !c.HasPrivateKey() {
+	if !c.HasSigner() {
 		panic("TxOpts() should not have been invoked when using an external signer")
 	}
 
-	txOpts, err := bind.NewKeyedTransactorWithChainID(c.ethPrivKey, c.chainID)
-	if err != nil {
-		return nil, err
+	txOpts := &bind.TransactOpts{
+		From: c.signer.Address(),
+		Signer: func(addr ethcommon.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+			if addr != c.signer.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return c.signer.SignTx(tx, c.chainID)
+		},
+		Context: ctx,
 	}
-	txOpts.Context = ctx
 
 	// TODO: set gas limit + price based on network (#153)
 	txOpts.GasPrice = c.gasPrice
@@ -278,6 +403,80 @@ func (c *ethClient) LatestBlockTimestamp(ctx context.Context) (time.Time, error)
 	return time.Unix(int64(hdr.Time), 0), nil
 }
 
+// TransferETH sends the amount to the address at the gas price, waits for the
+// transaction's receipt, and returns it with the sent amount. A nil amount sends the
+// whole balance minus the gas cost. The wallet is locked during the transfer, so it
+// must not already be locked.
+func (c *ethClient) TransferETH(
+	ctx context.Context,
+	to ethcommon.Address,
+	amount *coins.WeiAmount,
+	gasPrice *big.Int,
+) (*ethtypes.Receipt, *coins.WeiAmount, error) {
+	if !c.HasSigner() {
+		panic("TransferETH() should not have been invoked when using an external signer")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	msg := ethereum.CallMsg{From: c.Address(), To: &to}
+	if amount != nil {
+		msg.Value = amount.BigInt()
+	}
+
+	gas, err := c.ec.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	value := msg.Value
+	if value == nil {
+		var balance *big.Int
+		balance, err = c.ec.BalanceAt(ctx, c.Address(), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		gasCost := new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+		value = new(big.Int).Sub(balance, gasCost)
+		if value.Sign() <= 0 {
+			return nil, nil, fmt.Errorf("balance of %s ETH does not cover the gas cost of %s ETH",
+				coins.FmtWeiAsETH(balance), coins.FmtWeiAsETH(gasCost))
+		}
+	}
+
+	nonce, err := c.ec.PendingNonceAt(ctx, c.Address())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx := ethtypes.NewTx(&ethtypes.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Value:    value,
+		Gas:      gas,
+		GasPrice: gasPrice,
+	})
+
+	signedTx, err := c.signer.SignTx(tx, c.chainID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = c.ec.SendTransaction(ctx, signedTx); err != nil {
+		return nil, nil, err
+	}
+	log.Infof("transfer of %s ETH to %s submitted, tx hash=%s", coins.FmtWeiAsETH(value), to, signedTx.Hash())
+
+	receipt, err := c.WaitForReceipt(ctx, signedTx.Hash())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return receipt, coins.NewWeiAmount(value), nil
+}
+
 func (c *ethClient) Lock() {
 	c.mu.Lock()
 }
@@ -0,0 +1,71 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package extethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/usbwallet"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+const (
+	// DefaultLedgerDerivationPath is the derivation path of the first account of
+	// the Ledger Ethereum app.
+	DefaultLedgerDerivationPath = "m/44'/60'/0'/0/0"
+
+	ledgerDetectTimeout = 10 * time.Second
+	ledgerPollInterval  = 250 * time.Millisecond
+)
+
+var errNoLedgerFound = errors.New("no Ledger device found, make sure it is connected and unlocked")
+
+// NewLedgerSigner returns a Signer for the account at the given derivation path of
+// the first connected Ledger device. The device must have the Ethereum app open.
+// Every transaction has to be confirmed on the device.
+func NewLedgerSigner(ctx context.Context, derivationPath string) (Signer, error) {
+	path, err := accounts.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path %q: %w", derivationPath, err)
+	}
+
+	hub, err := usbwallet.NewLedgerHub()
+	if err != nil {
+		return nil, fmt.Errorf("failed to access USB devices: %w", err)
+	}
+
+	// the hub enumerates devices in the background, so they might not be listed
+	// right away
+	ctx, cancel := context.WithTimeout(ctx, ledgerDetectTimeout)
+	defer cancel()
+
+	var wallets []accounts.Wallet
+	for {
+		if wallets = hub.Wallets(); len(wallets) > 0 {
+			break
+		}
+		if err = common.SleepWithContext(ctx, ledgerPollInterval); err != nil {
+			return nil, errNoLedgerFound
+		}
+	}
+
+	wallet := wallets[0]
+	if err = wallet.Open(""); err != nil {
+		return nil, fmt.Errorf("failed to open Ledger %s: %w", wallet.URL(), err)
+	}
+
+	account, err := wallet.Derive(path, true)
+	if err != nil {
+		_ = wallet.Close()
+		return nil, fmt.Errorf("failed to derive Ledger account: %w", err)
+	}
+
+	log.Infof("using Ledger %s account %s at derivation path %s", wallet.URL(), account.Address, path)
+	return NewWalletSigner(wallet, account), nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package extethclient
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+// Signer signs transactions and EIP-712 typed data on behalf of swapd's ethereum
+// account. Implementations hold the key in memory or delegate signing to a device
+// or process that holds it.
+type Signer interface {
+	Address() ethcommon.Address
+	SignTx(tx *ethtypes.Transaction, chainID *big.Int) (*ethtypes.Transaction, error)
+
+	// SignTypedData signs the EIP-712 typed data. The full typed data is passed,
+	// instead of only its digest, so that external signers can display and apply
+	// policy to the message. The returned 65-byte signature is in [R || S || V]
+	// format with V set to 27 or 28.
+	SignTypedData(data *apitypes.TypedData) ([]byte, error)
+
+	// SignText signs the EIP-191 personal message hash of the text, in the same
+	// signature format as SignTypedData.
+	SignText(text []byte) ([]byte, error)
+}
+
+// typedDataPayload returns the EIP-712 encoding of the typed data:
+// 0x19 0x01 || domainSeparator || structHash
+func typedDataPayload(data *apitypes.TypedData) ([]byte, error) {
+	_, payload, err := apitypes.TypedDataAndHash(*data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+	return []byte(payload), nil
+}
+
+// normalizeSignatureV converts a signature with a V value of 0 or 1 to use 27 or 28,
+// as expected by Solidity's ecrecover.
+func normalizeSignatureV(sig []byte) []byte {
+	if sig[ethcrypto.RecoveryIDOffset] < 27 {
+		sig[ethcrypto.RecoveryIDOffset] += 27
+	}
+	return sig
+}
+
+type privateKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address ethcommon.Address
+}
+
+// NewPrivateKeySigner returns a Signer that signs with the passed in-memory key.
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &privateKeySigner{
+		key:     key,
+		address: common.EthereumPrivateKeyToAddress(key),
+	}
+}
+
+func (s *privateKeySigner) Address() ethcommon.Address {
+	return s.address
+}
+
+func (s *privateKeySigner) SignTx(tx *ethtypes.Transaction, chainID *big.Int) (*ethtypes.Transaction, error) {
+	return ethtypes.SignTx(tx, ethtypes.LatestSignerForChainID(chainID), s.key)
+}
+
+func (s *privateKeySigner) SignTypedData(data *apitypes.TypedData) ([]byte, error) {
+	payload, err := typedDataPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ethcrypto.Sign(ethcrypto.Keccak256(payload), s.key)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeSignatureV(sig), nil
+}
+
+func (s *privateKeySigner) SignText(text []byte) ([]byte, error) {
+	sig, err := ethcrypto.Sign(accounts.TextHash(text), s.key)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeSignatureV(sig), nil
+}
+
+// walletSigner signs with an account of a go-ethereum accounts.Wallet, such as a
+// hardware wallet.
+type walletSigner struct {
+	wallet  accounts.Wallet
+	account accounts.Account
+}
+
+// NewWalletSigner returns a Signer that signs with the given account of an already
+// opened go-ethereum wallet.
+func NewWalletSigner(wallet accounts.Wallet, account accounts.Account) Signer {
+	return &walletSigner{
+		wallet:  wallet,
+		account: account,
+	}
+}
+
+func (s *walletSigner) Address() ethcommon.Address {
+	return s.account.Address
+}
+
+func (s *walletSigner) SignTx(tx *ethtypes.Transaction, chainID *big.Int) (*ethtypes.Transaction, error) {
+	return s.wallet.SignTx(s.account, tx, chainID)
+}
+
+func (s *walletSigner) SignTypedData(data *apitypes.TypedData) ([]byte, error) {
+	payload, err := typedDataPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.wallet.SignData(s.account, accounts.MimetypeTypedData, payload)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeSignatureV(sig), nil
+}
+
+func (s *walletSigner) SignText(text []byte) ([]byte, error) {
+	sig, err := s.wallet.SignText(s.account, text)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeSignatureV(sig), nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package extethclient
+
+import (
+	"math/big"
+	"testing"
+
+	rcommon "github.com/athanorlabs/go-relayer/common"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateKeySigner(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewPrivateKeySigner(key)
+	require.Equal(t, ethcrypto.PubkeyToAddress(key.PublicKey), signer.Address())
+
+	chainID := big.NewInt(1337)
+	to := ethcommon.Address{0x1}
+	tx := ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	signedTx, err := signer.SignTx(tx, chainID)
+	require.NoError(t, err)
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(chainID), signedTx)
+	require.NoError(t, err)
+	require.Equal(t, signer.Address(), sender)
+
+	// the signature must match the one the relayer library creates
+	data := &apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+			},
+			"Mail": {
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: apitypes.TypedDataDomain{
+			Name:    "test",
+			ChainId: (*math.HexOrDecimal256)(chainID),
+		},
+		Message: apitypes.TypedDataMessage{
+			"contents": "hello",
+		},
+	}
+	sig, err := signer.SignTypedData(data)
+	require.NoError(t, err)
+	digest, _, err := apitypes.TypedDataAndHash(*data)
+	require.NoError(t, err)
+	expectedSig, err := rcommon.NewKeyFromPrivateKey(key).Sign(ethcommon.BytesToHash(digest))
+	require.NoError(t, err)
+	require.Equal(t, expectedSig, sig)
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rcommon "github.com/athanorlabs/go-relayer/common"
+	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+// ForwarderVersion identifies the trusted forwarder implementation that a
+// SwapCreator contract was deployed with. Both versions take the same forward
+// request struct, whose fields end with validUntilTime, so they share the
+// gsnforwarder bindings. They differ in the EIP-712 domain that their deployers
+// register.
+type ForwarderVersion int
+
+const (
+	// ForwarderV2 is the forwarder compiled into go-relayer, which is what we deploy
+	// on dev and test chains. Its GSN v2-style EIP-712 domain is "Forwarder",
+	// version "0.0.1".
+	ForwarderV2 ForwarderVersion = iota
+	// ForwarderV3 is a forwarder deployed by OpenGSN v3, whose EIP-712 domain is
+	// "GSN Relayed Transaction", version "3".
+	ForwarderV3
+)
+
+// String returns the name of the forwarder version.
+func (v ForwarderVersion) String() string {
+	switch v {
+	case ForwarderV2:
+		return "v2"
+	case ForwarderV3:
+		return "v3"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(v))
+	}
+}
+
+// ForwarderDomain is the name and version of an EIP-712 domain that a forwarder
+// verifies forward request signatures against.
+type ForwarderDomain struct {
+	Name    string
+	Version string
+}
+
+var (
+	// ForwarderV2Domain is the EIP-712 domain of ForwarderV2. It is also the domain
+	// that we register on any forwarder when deploying SwapCreator.sol.
+	ForwarderV2Domain = ForwarderDomain{Name: gsnforwarder.DefaultName, Version: gsnforwarder.DefaultVersion}
+
+	// ForwarderV3Domain is the EIP-712 domain of ForwarderV3.
+	ForwarderV3Domain = ForwarderDomain{Name: "GSN Relayed Transaction", Version: "3"}
+
+	errForwarderDomainNotRegistered = errors.New("forwarder has no registered EIP-712 domain that we can sign for")
+)
+
+// Domains returns the EIP-712 domains that a forwarder of the version can have
+// registered, in order of preference.
+func (v ForwarderVersion) Domains() []ForwarderDomain {
+	if v == ForwarderV3 {
+		return []ForwarderDomain{ForwarderV3Domain, ForwarderV2Domain}
+	}
+	return []ForwarderDomain{ForwarderV2Domain}
+}
+
+// knownV3Forwarders holds the OpenGSN v3 forwarder deployments that we accept
+// without comparing their bytecode, as they were compiled with a different solidity
+// version than the bundled forwarder.
+var knownV3Forwarders = map[uint64]ethcommon.Address{
+	// https://docs.opengsn.org/networks/addresses.html
+	common.MainnetChainID: common.MainnetConfig().ForwarderAddr,
+}
+
+// forwarderVersionOf returns ForwarderV3 if the address is a known OpenGSN v3
+// forwarder deployment on the chain, and ForwarderV2 otherwise.
+func forwarderVersionOf(chainID uint64, forwarderAddr ethcommon.Address) ForwarderVersion {
+	if addr, ok := knownV3Forwarders[chainID]; ok && addr == forwarderAddr {
+		return ForwarderV3
+	}
+	return ForwarderV2
+}
+
+// checkForwardRequestTypeRegistered returns an error if the forwarder doesn't
+// accept requests of the forward request type that we sign. Both forwarder
+// versions register the type on construction.
+func checkForwardRequestTypeRegistered(ctx context.Context, forwarder *gsnforwarder.Forwarder) error {
+	registered, err := forwarder.TypeHashes(&bind.CallOpts{Context: ctx}, gsnforwarder.ForwardRequestTypehash)
+	if err != nil {
+		return err
+	}
+	if !registered {
+		return fmt.Errorf("forward request type is not registered: %w", errInvalidForwarderContract)
+	}
+	return nil
+}
+
+// RegisteredForwarderDomain returns the preferred EIP-712 domain of the forwarder's
+// version that is registered on the forwarder, along with its domain separator.
+// Forward requests must be signed and verified against this domain.
+func RegisteredForwarderDomain(
+	ctx context.Context,
+	ec *ethclient.Client,
+	forwarderAddr ethcommon.Address,
+) (*ForwarderDomain, [32]byte, error) {
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+
+	version := forwarderVersionOf(chainID.Uint64(), forwarderAddr)
+
+	forwarder, err := gsnforwarder.NewForwarder(forwarderAddr, ec)
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+
+	for _, domain := range version.Domains() {
+		separator, err := rcommon.GetEIP712DomainSeparator(domain.Name, domain.Version, chainID, forwarderAddr)
+		if err != nil {
+			return nil, [32]byte{}, fmt.Errorf("failed to get EIP712 domain separator: %w", err)
+		}
+
+		registered, err := forwarder.Domains(&bind.CallOpts{Context: ctx}, separator)
+		if err != nil {
+			return nil, [32]byte{}, err
+		}
+
+		if registered {
+			domain := domain
+			return &domain, separator, nil
+		}
+	}
+
+	return nil, [32]byte{}, errForwarderDomainNotRegistered
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/tests"
+)
+
+func TestForwarderVersionOf(t *testing.T) {
+	mainnetForwarder := common.MainnetConfig().ForwarderAddr
+	require.Equal(t, ForwarderV3, forwarderVersionOf(common.MainnetChainID, mainnetForwarder))
+	require.Equal(t, ForwarderV2, forwarderVersionOf(common.SepoliaChainID, mainnetForwarder))
+	require.Equal(t, ForwarderV2, forwarderVersionOf(common.MainnetChainID, ethcommon.Address{0x1}))
+
+	require.Equal(t, []ForwarderDomain{ForwarderV3Domain, ForwarderV2Domain}, ForwarderV3.Domains())
+	require.Equal(t, []ForwarderDomain{ForwarderV2Domain}, ForwarderV2.Domains())
+}
+
+func TestRegisteredForwarderDomain(t *testing.T) {
+	ec, _ := tests.NewEthClient(t)
+	ctx := context.Background()
+	privKey := tests.GetMakerTestKey(t)
+
+	txOpts, err := newTXOpts(ctx, ec, privKey)
+	require.NoError(t, err)
+
+	forwarderAddr, tx, _, err := gsnforwarder.DeployForwarder(txOpts, ec)
+	require.NoError(t, err)
+	_ = tests.MineTransaction(t, ec, tx)
+
+	_, _, err = RegisteredForwarderDomain(ctx, ec, forwarderAddr)
+	require.ErrorIs(t, err, errForwarderDomainNotRegistered)
+
+	err = registerDomainSeparatorIfNeeded(ctx, ec, privKey, forwarderAddr)
+	require.NoError(t, err)
+
+	domain, _, err := RegisteredForwarderDomain(ctx, ec, forwarderAddr)
+	require.NoError(t, err)
+	require.Equal(t, ForwarderV2Domain, *domain)
+}
@@ -0,0 +1,401 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package indexer provides a service that stores the events emitted by the swap
+// creator contract in the local database, so they can be queried without going
+// back to the chain.
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	eth "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	logging "github.com/ipfs/go-log"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+const (
+	// maxBlockRange is the maximum number of blocks that are queried for logs in a
+	// single eth_getLogs call, as many endpoints limit the range.
+	maxBlockRange = 2000
+
+	pollInterval = 5 * time.Second
+
+	// DefaultConfirmations is the default number of blocks that are built on a
+	// block before its events are indexed.
+	DefaultConfirmations = 12
+
+	// reorgRollbackDepth is the number of blocks before the last indexed block
+	// whose events are indexed again when a reorg is detected. Post-merge blocks
+	// are final after two epochs of 32 blocks, so reorgs can't be deeper.
+	reorgRollbackDepth = 64
+)
+
+var (
+	log = logging.Logger("ethereum/indexer")
+
+	newTopic      = contracts.SwapCreatorParsedABI.Events["New"].ID
+	readyTopic    = common.GetTopic(common.ReadyEventSignature)
+	claimedTopic  = common.GetTopic(common.ClaimedEventSignature)
+	refundedTopic = common.GetTopic(common.RefundedEventSignature)
+)
+
+// Database contains the methods used by the Indexer to store contract events.
+type Database interface {
+	PutContractEvents(
+		contractAddr ethcommon.Address,
+		events []*db.ContractEvent,
+		indexedBlock uint64,
+		indexedBlockHash ethcommon.Hash,
+	) error
+	RollbackContractEvents(contractAddr ethcommon.Address, toBlock uint64, toBlockHash ethcommon.Hash) error
+	GetIndexedBlock(contractAddr ethcommon.Address) (uint64, ethcommon.Hash, error)
+}
+
+// ChainReader contains the ethereum client methods used by the Indexer, which
+// *ethclient.Client implements.
+type ChainReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+	FilterLogs(ctx context.Context, q eth.FilterQuery) ([]ethtypes.Log, error)
+}
+
+// Config contains the parameters to create an Indexer.
+type Config struct {
+	Ctx             context.Context
+	EthClient       ChainReader
+	SwapCreatorAddr ethcommon.Address
+	// StartBlock is the block to start indexing from if nothing has been indexed
+	// for the contract yet, ideally the block the contract was deployed in. If
+	// zero, indexing starts at the current head of the chain.
+	StartBlock uint64
+	// Confirmations is the number of blocks that are built on a block before its
+	// events are indexed, so that the events of blocks that are reorganized out
+	// of the chain are rarely indexed. Reorgs that remove indexed blocks anyway
+	// are detected, and the events of the removed blocks are deleted.
+	Confirmations uint64
+	Database      Database
+}
+
+// Indexer scans the chain for events emitted by the swap creator contract and
+// stores them in the database.
+type Indexer struct {
+	ctx             context.Context
+	cancel          context.CancelFunc
+	ec              ChainReader
+	swapCreatorAddr ethcommon.Address
+	filterer        *contracts.SwapCreatorFilterer
+	startBlock      uint64
+	confirmations   uint64
+	db              Database
+	done            chan struct{}
+
+	// hash of the last indexed block, zero if unknown, to detect reorgs. It is
+	// only accessed by the indexing goroutine.
+	indexedHash ethcommon.Hash
+}
+
+// NewIndexer returns a new *Indexer.
+func NewIndexer(cfg *Config) (*Indexer, error) {
+	// the filterer is only used to parse logs
+	filterer, err := contracts.NewSwapCreatorFilterer(cfg.SwapCreatorAddr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(cfg.Ctx)
+	return &Indexer{
+		ctx:             ctx,
+		cancel:          cancel,
+		ec:              cfg.EthClient,
+		swapCreatorAddr: cfg.SwapCreatorAddr,
+		filterer:        filterer,
+		startBlock:      cfg.StartBlock,
+		confirmations:   cfg.Confirmations,
+		db:              cfg.Database,
+		done:            make(chan struct{}),
+	}, nil
+}
+
+// Start starts indexing in the background. The events emitted since the last
+// indexed block (or since the configured start block, if nothing has been indexed
+// yet) are backfilled up to the confirmed head of the chain first, before new
+// blocks are indexed. RPC errors are logged and the failed requests are retried,
+// so a slow or unreliable endpoint never holds up the rest of swapd.
+func (ix *Indexer) Start() {
+	go func() {
+		defer close(ix.done)
+
+		fromBlock, err := ix.resumeBlockWithRetries()
+		if err != nil {
+			return
+		}
+
+		log.Infof("indexing SwapCreator.sol events of %s from block %d", ix.swapCreatorAddr, fromBlock)
+		ix.run(fromBlock)
+	}()
+}
+
+// Stop stops the Indexer and waits for it to exit.
+func (ix *Indexer) Stop() {
+	ix.cancel()
+	<-ix.done
+}
+
+// resumeBlock returns the first block that has not been indexed yet.
+func (ix *Indexer) resumeBlock() (uint64, error) {
+	indexedBlock, indexedHash, err := ix.db.GetIndexedBlock(ix.swapCreatorAddr)
+	if err == nil {
+		ix.indexedHash = indexedHash
+		return indexedBlock + 1, nil
+	}
+	if !errors.Is(err, chaindb.ErrKeyNotFound) {
+		return 0, err
+	}
+
+	if ix.startBlock != 0 {
+		return ix.startBlock, nil
+	}
+
+	header, err := ix.ec.HeaderByNumber(ix.ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Warnf("deployment block of %s is unknown, events before block %s will not be indexed",
+		ix.swapCreatorAddr, header.Number)
+	return header.Number.Uint64(), nil
+}
+
+// resumeBlockWithRetries returns the first block that has not been indexed yet,
+// retrying every pollInterval until it succeeds. An error is only returned when
+// the Indexer is stopped.
+func (ix *Indexer) resumeBlockWithRetries() (uint64, error) {
+	for {
+		fromBlock, err := ix.resumeBlock()
+		if err == nil {
+			return fromBlock, nil
+		}
+		if ix.ctx.Err() != nil || errors.Is(err, ethrpc.ErrClientQuit) {
+			return 0, err
+		}
+		log.Warnf("failed to get the block to resume indexing contract events from: %s", err)
+
+		if err = common.SleepWithContext(ix.ctx, pollInterval); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// run indexes the blocks from fromBlock up to the confirmed head of the chain, and
+// then each new confirmed block, until the Indexer is stopped. After an error, the
+// indexing continues from the first block that was not indexed on the next poll.
+func (ix *Indexer) run(fromBlock uint64) {
+	backfilled := false
+	for {
+		nextBlock, err := ix.indexToHead(fromBlock)
+		fromBlock = nextBlock
+		if err != nil {
+			if ix.ctx.Err() != nil || errors.Is(err, ethrpc.ErrClientQuit) {
+				return
+			}
+			log.Warnf("failed to index contract events: %s", err)
+		} else if !backfilled {
+			backfilled = true
+			log.Infof("backfilled SwapCreator.sol events of %s, indexing new blocks from block %d",
+				ix.swapCreatorAddr, fromBlock)
+		}
+
+		if err = common.SleepWithContext(ix.ctx, pollInterval); err != nil {
+			return
+		}
+	}
+}
+
+// indexToHead indexes the blocks from fromBlock up to the confirmed head of the
+// chain in batches of at most maxBlockRange blocks, after rolling back the blocks
+// that were reorganized out of the chain. It returns the first block that has not
+// been indexed, which is the block to continue from, even if an error is returned.
+func (ix *Indexer) indexToHead(fromBlock uint64) (uint64, error) {
+	fromBlock, err := ix.checkReorg(fromBlock)
+	if err != nil {
+		return fromBlock, err
+	}
+
+	head, err := ix.ec.BlockNumber(ix.ctx)
+	if err != nil {
+		return fromBlock, err
+	}
+	if head < ix.confirmations {
+		return fromBlock, nil
+	}
+	head -= ix.confirmations
+
+	for fromBlock <= head {
+		toBlock := fromBlock + maxBlockRange - 1
+		if toBlock > head {
+			toBlock = head
+		}
+
+		if err = ix.indexRange(fromBlock, toBlock); err != nil {
+			return fromBlock, fmt.Errorf("failed to index blocks %d to %d: %w", fromBlock, toBlock, err)
+		}
+
+		fromBlock = toBlock + 1
+	}
+
+	return fromBlock, nil
+}
+
+// checkReorg checks that the last indexed block, the block before fromBlock, is
+// still in the chain. If it isn't, the events of the last reorgRollbackDepth blocks
+// are deleted, and the first of the deleted blocks is returned as the block to
+// continue from. Otherwise fromBlock is returned.
+func (ix *Indexer) checkReorg(fromBlock uint64) (uint64, error) {
+	if fromBlock == 0 || ix.indexedHash == (ethcommon.Hash{}) {
+		return fromBlock, nil
+	}
+
+	indexedBlock := fromBlock - 1
+	header, err := ix.ec.HeaderByNumber(ix.ctx, new(big.Int).SetUint64(indexedBlock))
+	if err != nil {
+		return fromBlock, err
+	}
+	if header.Hash() == ix.indexedHash {
+		return fromBlock, nil
+	}
+
+	var rollbackTo uint64
+	if indexedBlock > reorgRollbackDepth {
+		rollbackTo = indexedBlock - reorgRollbackDepth
+	}
+
+	ancestor, err := ix.ec.HeaderByNumber(ix.ctx, new(big.Int).SetUint64(rollbackTo))
+	if err != nil {
+		return fromBlock, err
+	}
+
+	log.Warnf("indexed block %d was reorganized out of the chain, indexing the events since block %d again",
+		indexedBlock, rollbackTo+1)
+
+	if err = ix.db.RollbackContractEvents(ix.swapCreatorAddr, rollbackTo, ancestor.Hash()); err != nil {
+		return fromBlock, err
+	}
+
+	ix.indexedHash = ancestor.Hash()
+	return rollbackTo + 1, nil
+}
+
+// indexRange stores the contract's events in the inclusive range of blocks from
+// fromBlock to toBlock.
+func (ix *Indexer) indexRange(fromBlock uint64, toBlock uint64) error {
+	toHeader, err := ix.ec.HeaderByNumber(ix.ctx, new(big.Int).SetUint64(toBlock))
+	if err != nil {
+		return err
+	}
+
+	logs, err := ix.ec.FilterLogs(ix.ctx, eth.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []ethcommon.Address{ix.swapCreatorAddr},
+		Topics:    [][]ethcommon.Hash{{newTopic, readyTopic, claimedTopic, refundedTopic}},
+	})
+	if err != nil {
+		return err
+	}
+
+	events := make([]*db.ContractEvent, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+
+		// the stored hash of toBlock must be the hash of the block whose logs we got
+		if l.BlockNumber == toBlock && l.BlockHash != toHeader.Hash() {
+			return fmt.Errorf("block %d was reorganized while it was indexed", toBlock)
+		}
+
+		event, parseErr := ix.parseLog(l)
+		if parseErr != nil {
+			return fmt.Errorf("failed to parse log in tx %s: %w", l.TxHash, parseErr)
+		}
+
+		log.Debugf("indexed %s event for swap %s in block %d", event.Type, event.SwapID, event.BlockNumber)
+		events = append(events, event)
+	}
+
+	if err = ix.db.PutContractEvents(ix.swapCreatorAddr, events, toBlock, toHeader.Hash()); err != nil {
+		return err
+	}
+
+	ix.indexedHash = toHeader.Hash()
+	return nil
+}
+
+// parseLog converts a log emitted by the swap creator contract into a *db.ContractEvent.
+func (ix *Indexer) parseLog(l ethtypes.Log) (*db.ContractEvent, error) {
+	event := &db.ContractEvent{
+		BlockNumber: l.BlockNumber,
+		TxHash:      l.TxHash,
+		LogIndex:    l.Index,
+	}
+
+	switch l.Topics[0] {
+	case newTopic:
+		e, err := ix.filterer.ParseNew(l)
+		if err != nil {
+			return nil, err
+		}
+		claimCommitment := types.Hash(e.ClaimKey)
+		refundCommitment := types.Hash(e.RefundKey)
+		event.Type = db.ContractEventNew
+		event.SwapID = e.SwapID
+		event.ClaimCommitment = &claimCommitment
+		event.RefundCommitment = &refundCommitment
+		event.Timeout0 = e.Timeout0
+		event.Timeout1 = e.Timeout1
+		event.Asset = &e.Asset
+		event.Value = e.Value
+	case readyTopic:
+		e, err := ix.filterer.ParseReady(l)
+		if err != nil {
+			return nil, err
+		}
+		event.Type = db.ContractEventReady
+		event.SwapID = e.SwapID
+	case claimedTopic:
+		e, err := ix.filterer.ParseClaimed(l)
+		if err != nil {
+			return nil, err
+		}
+		secret := types.Hash(e.S)
+		event.Type = db.ContractEventClaimed
+		event.SwapID = e.SwapID
+		event.Secret = &secret
+	case refundedTopic:
+		e, err := ix.filterer.ParseRefunded(l)
+		if err != nil {
+			return nil, err
+		}
+		secret := types.Hash(e.S)
+		event.Type = db.ContractEventRefunded
+		event.SwapID = e.SwapID
+		event.Secret = &secret
+	default:
+		return nil, fmt.Errorf("unexpected topic %s", l.Topics[0])
+	}
+
+	return event, nil
+}
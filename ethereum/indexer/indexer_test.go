@@ -0,0 +1,248 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	eth "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/db"
+)
+
+var testContractAddr = ethcommon.Address{0x1}
+
+// mockChain is a chain whose blocks can be replaced to simulate reorgs.
+type mockChain struct {
+	headers []*ethtypes.Header
+	logs    map[uint64][]ethtypes.Log // by block number
+
+	// number of FilterLogs calls that fail before it succeeds
+	filterFailures int
+}
+
+func newMockChain(numBlocks uint64) *mockChain {
+	c := &mockChain{logs: make(map[uint64][]ethtypes.Log)}
+	c.reorg(0, numBlocks, 0)
+	return c
+}
+
+// reorg replaces the blocks from fromBlock with new blocks up to numBlocks, whose
+// hashes depend on fork.
+func (c *mockChain) reorg(fromBlock uint64, numBlocks uint64, fork byte) {
+	c.headers = c.headers[:fromBlock]
+	for n := fromBlock; n < numBlocks; n++ {
+		c.headers = append(c.headers, &ethtypes.Header{
+			Number: new(big.Int).SetUint64(n),
+			Extra:  []byte{fork},
+		})
+		delete(c.logs, n)
+	}
+}
+
+// addReadyEvent adds a Ready event of the swap to the block.
+func (c *mockChain) addReadyEvent(block uint64, swapID ethcommon.Hash) {
+	c.logs[block] = append(c.logs[block], ethtypes.Log{
+		Address:     testContractAddr,
+		Topics:      []ethcommon.Hash{readyTopic, swapID},
+		BlockNumber: block,
+		BlockHash:   c.headers[block].Hash(),
+		TxHash:      swapID,
+		Index:       uint(len(c.logs[block])),
+	})
+}
+
+func (c *mockChain) BlockNumber(_ context.Context) (uint64, error) {
+	return uint64(len(c.headers) - 1), nil
+}
+
+func (c *mockChain) HeaderByNumber(_ context.Context, number *big.Int) (*ethtypes.Header, error) {
+	if number == nil {
+		return c.headers[len(c.headers)-1], nil
+	}
+	if number.Uint64() >= uint64(len(c.headers)) {
+		return nil, eth.NotFound
+	}
+	return c.headers[number.Uint64()], nil
+}
+
+func (c *mockChain) FilterLogs(_ context.Context, q eth.FilterQuery) ([]ethtypes.Log, error) {
+	if c.filterFailures > 0 {
+		c.filterFailures--
+		return nil, errors.New("rate limited")
+	}
+
+	var logs []ethtypes.Log
+	for n := q.FromBlock.Uint64(); n <= q.ToBlock.Uint64(); n++ {
+		logs = append(logs, c.logs[n]...)
+	}
+	return logs, nil
+}
+
+// mockDatabase stores the events of one contract in memory.
+type mockDatabase struct {
+	mu           sync.Mutex
+	events       map[uint64][]*db.ContractEvent // by block number
+	indexedBlock *uint64
+	indexedHash  ethcommon.Hash
+}
+
+func newMockDatabase() *mockDatabase {
+	return &mockDatabase{events: make(map[uint64][]*db.ContractEvent)}
+}
+
+func (d *mockDatabase) PutContractEvents(
+	_ ethcommon.Address,
+	events []*db.ContractEvent,
+	indexedBlock uint64,
+	indexedBlockHash ethcommon.Hash,
+) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, e := range events {
+		d.events[e.BlockNumber] = append(d.events[e.BlockNumber], e)
+	}
+	d.indexedBlock = &indexedBlock
+	d.indexedHash = indexedBlockHash
+	return nil
+}
+
+func (d *mockDatabase) RollbackContractEvents(_ ethcommon.Address, toBlock uint64, toBlockHash ethcommon.Hash) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for n := range d.events {
+		if n > toBlock {
+			delete(d.events, n)
+		}
+	}
+	d.indexedBlock = &toBlock
+	d.indexedHash = toBlockHash
+	return nil
+}
+
+func (d *mockDatabase) GetIndexedBlock(_ ethcommon.Address) (uint64, ethcommon.Hash, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.indexedBlock == nil {
+		return 0, ethcommon.Hash{}, chaindb.ErrKeyNotFound
+	}
+	return *d.indexedBlock, d.indexedHash, nil
+}
+
+// blocksWithEvents returns the sorted numbers of the blocks with stored events.
+func (d *mockDatabase) blocksWithEvents() []uint64 {
+	var blocks []uint64
+	for n := range d.events {
+		blocks = append(blocks, n)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+	return blocks
+}
+
+func newTestIndexer(t *testing.T, chain *mockChain, database *mockDatabase, confirmations uint64) *Indexer {
+	ix, err := NewIndexer(&Config{
+		Ctx:             context.Background(),
+		EthClient:       chain,
+		SwapCreatorAddr: testContractAddr,
+		StartBlock:      1,
+		Confirmations:   confirmations,
+		Database:        database,
+	})
+	require.NoError(t, err)
+	return ix
+}
+
+func TestIndexer_confirmations(t *testing.T) {
+	chain := newMockChain(21) // head is block 20
+	chain.addReadyEvent(5, ethcommon.Hash{0x5})
+	chain.addReadyEvent(18, ethcommon.Hash{0x18})
+	database := newMockDatabase()
+
+	ix := newTestIndexer(t, chain, database, 3)
+	fromBlock, err := ix.resumeBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), fromBlock)
+
+	// block 18 has only 2 confirmations
+	fromBlock, err = ix.indexToHead(fromBlock)
+	require.NoError(t, err)
+	require.Equal(t, uint64(18), fromBlock)
+	require.Equal(t, []uint64{5}, database.blocksWithEvents())
+	require.Equal(t, uint64(17), *database.indexedBlock)
+	require.Equal(t, chain.headers[17].Hash(), database.indexedHash)
+
+	chain.reorg(21, 22, 0)
+	fromBlock, err = ix.indexToHead(fromBlock)
+	require.NoError(t, err)
+	require.Equal(t, uint64(19), fromBlock)
+	require.Equal(t, []uint64{5, 18}, database.blocksWithEvents())
+}
+
+func TestIndexer_reorg(t *testing.T) {
+	chain := newMockChain(101) // head is block 100
+	chain.addReadyEvent(5, ethcommon.Hash{0x5})
+	chain.addReadyEvent(90, ethcommon.Hash{0x90})
+	database := newMockDatabase()
+
+	ix := newTestIndexer(t, chain, database, 0)
+	fromBlock, err := ix.resumeBlock()
+	require.NoError(t, err)
+	fromBlock, err = ix.indexToHead(fromBlock)
+	require.NoError(t, err)
+	require.Equal(t, uint64(101), fromBlock)
+	require.Equal(t, []uint64{5, 90}, database.blocksWithEvents())
+
+	// the blocks since 90 are replaced, and the event of block 90 moves to block 92
+	chain.reorg(90, 104, 1)
+	chain.addReadyEvent(92, ethcommon.Hash{0x90})
+
+	// a restarted indexer detects the reorg too
+	ix = newTestIndexer(t, chain, database, 0)
+	fromBlock, err = ix.resumeBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(101), fromBlock)
+
+	fromBlock, err = ix.indexToHead(fromBlock)
+	require.NoError(t, err)
+	require.Equal(t, uint64(104), fromBlock)
+	require.Equal(t, []uint64{5, 92}, database.blocksWithEvents())
+	require.Equal(t, uint64(103), *database.indexedBlock)
+	require.Equal(t, chain.headers[103].Hash(), database.indexedHash)
+
+	// without a reorg, the indexed blocks are kept
+	chain.reorg(104, 106, 1)
+	fromBlock, err = ix.indexToHead(fromBlock)
+	require.NoError(t, err)
+	require.Equal(t, uint64(106), fromBlock)
+	require.Equal(t, []uint64{5, 92}, database.blocksWithEvents())
+}
+
+func TestIndexer_Start(t *testing.T) {
+	chain := newMockChain(11) // head is block 10
+	chain.addReadyEvent(5, ethcommon.Hash{0x5})
+	chain.filterFailures = 1
+	database := newMockDatabase()
+
+	// the backfill runs in the background, and is retried after the failure
+	ix := newTestIndexer(t, chain, database, 0)
+	ix.Start()
+
+	require.Eventually(t, func() bool {
+		indexedBlock, _, err := database.GetIndexedBlock(testContractAddr)
+		return err == nil && indexedBlock == 10
+	}, 3*pollInterval, 100*time.Millisecond)
+
+	ix.Stop()
+	require.Equal(t, []uint64{5}, database.blocksWithEvents())
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Multicall3Address is the address of the Multicall3 contract, which is deployed
+// at the same address on mainnet, the public testnets and most other EVM chains.
+var Multicall3Address = ethcommon.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// multicall3ABI holds the aggregate3 method of Multicall3.
+const multicall3ABI = `[
+	{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}
+]`
+
+// Multicall3Call is a call in a Multicall3 aggregate3 batch. If AllowFailure is
+// false, a failure of the call reverts the whole batch.
+type Multicall3Call struct {
+	Target       ethcommon.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// Multicall3Result is the result of a call in a Multicall3 aggregate3 batch.
+type Multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Multicall3 is a binding for the aggregate3 method of a Multicall3 contract.
+type Multicall3 struct {
+	contract *bind.BoundContract
+}
+
+// NewMulticall3 returns a binding for the Multicall3 contract at the given
+// address. It does not check that the contract is deployed, use
+// Multicall3Deployed for that.
+func NewMulticall3(address ethcommon.Address, backend bind.ContractBackend) (*Multicall3, error) {
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Multicall3{
+		contract: bind.NewBoundContract(address, parsed, backend, backend, backend),
+	}, nil
+}
+
+// Multicall3Deployed returns whether there is a contract at the address.
+func Multicall3Deployed(ctx context.Context, caller bind.ContractCaller, address ethcommon.Address) (bool, error) {
+	code, err := caller.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, err
+	}
+
+	return len(code) > 0, nil
+}
+
+// SimulateAggregate3 executes the calls with eth_call and returns their results.
+func (m *Multicall3) SimulateAggregate3(opts *bind.CallOpts, calls []Multicall3Call) ([]Multicall3Result, error) {
+	var out []interface{}
+	err := m.contract.Call(opts, &out, "aggregate3", calls)
+	if err != nil {
+		return nil, err
+	}
+
+	return *abi.ConvertType(out[0], new([]Multicall3Result)).(*[]Multicall3Result), nil
+}
+
+// Aggregate3 submits a transaction executing the calls.
+func (m *Multicall3) Aggregate3(opts *bind.TransactOpts, calls []Multicall3Call) (*ethtypes.Transaction, error) {
+	return m.contract.Transact(opts, "aggregate3", calls)
+}
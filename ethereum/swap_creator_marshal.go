@@ -60,3 +60,37 @@ func (sfs *SwapCreatorSwap) UnmarshalJSON(data []byte) error {
 	}
 	return nil
 }
+
+// permitSignature is the same as the auto-generated SwapCreatorPermitSignature type,
+// but with some type adjustments and annotations for JSON marshalling.
+type permitSignature struct {
+	Deadline *big.Int   `json:"deadline" validate:"required"`
+	V        uint8      `json:"v" validate:"required"`
+	R        types.Hash `json:"r" validate:"required"`
+	S        types.Hash `json:"s" validate:"required"`
+}
+
+// MarshalJSON provides JSON marshalling for SwapCreatorPermitSignature
+func (ps *SwapCreatorPermitSignature) MarshalJSON() ([]byte, error) {
+	return vjson.MarshalStruct(&permitSignature{
+		Deadline: ps.Deadline,
+		V:        ps.V,
+		R:        ps.R,
+		S:        ps.S,
+	})
+}
+
+// UnmarshalJSON provides JSON unmarshalling for SwapCreatorPermitSignature
+func (ps *SwapCreatorPermitSignature) UnmarshalJSON(data []byte) error {
+	s := &permitSignature{}
+	if err := vjson.UnmarshalStruct(data, s); err != nil {
+		return err
+	}
+	*ps = SwapCreatorPermitSignature{
+		Deadline: s.Deadline,
+		V:        s.V,
+		R:        s.R,
+		S:        s.S,
+	}
+	return nil
+}
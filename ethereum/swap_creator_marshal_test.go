@@ -57,3 +57,32 @@ func TestSwapCreatorSwap_JSON_fieldCountEqual(t *testing.T) {
 	numSwapCreatorSwapFields := reflect.TypeOf(SwapCreatorSwap{}).NumField()
 	require.Equal(t, numSwapCreatorSwapFields, numSwapFields)
 }
+
+func TestSwapCreatorPermitSignature_JSON(t *testing.T) {
+	sig := &SwapCreatorPermitSignature{
+		Deadline: big.NewInt(1672531200),
+		V:        27,
+		R:        ethcommon.HexToHash("0x5ab9467e70d4e98567991f0179d1f82a3096ed7973f7aff9ea50f649cafa88b9"),
+		S:        ethcommon.HexToHash("0x4897bc3b9e02c2a8cd6353b9b29377157bf2694daaf52b59c0b42daa39877f14"),
+	}
+	expectedJSON := `{
+		"deadline": 1672531200,
+		"v": 27,
+		"r": "0x5ab9467e70d4e98567991f0179d1f82a3096ed7973f7aff9ea50f649cafa88b9",
+		"s": "0x4897bc3b9e02c2a8cd6353b9b29377157bf2694daaf52b59c0b42daa39877f14"
+	}`
+	jsonData, err := vjson.MarshalStruct(sig)
+	require.NoError(t, err)
+	require.JSONEq(t, expectedJSON, string(jsonData))
+
+	sig2 := &SwapCreatorPermitSignature{}
+	err = json.Unmarshal(jsonData, sig2)
+	require.NoError(t, err)
+	require.EqualValues(t, sig, sig2)
+}
+
+func TestSwapCreatorPermitSignature_JSON_fieldCountEqual(t *testing.T) {
+	numFields := reflect.TypeOf(permitSignature{}).NumField()
+	numPermitSignatureFields := reflect.TypeOf(SwapCreatorPermitSignature{}).NumField()
+	require.Equal(t, numPermitSignatureFields, numFields)
+}
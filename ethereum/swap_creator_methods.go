@@ -0,0 +1,62 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// Methods of SwapCreator.sol that were added after the contract was first deployed.
+// Deployed contracts can lack them, so SwapCreatorHasMethod is checked before they
+// are called.
+const (
+	NewSwapWithPermitMethod = "newSwapWithPermit"
+	NewSwapRelayerMethod    = "newSwapRelayer"
+	RefundRelayerMethod     = "refundRelayer"
+	SetReadyRelayerMethod   = "setReadyRelayer"
+)
+
+// push4Opcode is the EVM instruction that pushes a 4-byte value on the stack
+const push4Opcode = 0x63
+
+var errNoSwapCreatorCode = errors.New("no contract code at the SwapCreator address")
+
+// SwapCreatorHasMethod returns whether the SwapCreator contract deployed at the
+// address implements the named method of the SwapCreator ABI. Solidity's function
+// dispatcher pushes the selector of every external method with PUSH4, so the
+// method is implemented if its PUSH4 instruction is found in the deployed code.
+func SwapCreatorHasMethod(
+	ctx context.Context,
+	ec bind.ContractCaller,
+	swapCreatorAddr ethcommon.Address,
+	method string,
+) (bool, error) {
+	swapCreatorABI, err := SwapCreatorMetaData.GetAbi()
+	if err != nil {
+		return false, err
+	}
+
+	abiMethod, ok := swapCreatorABI.Methods[method]
+	if !ok {
+		return false, fmt.Errorf("SwapCreator has no method %q", method)
+	}
+
+	code, err := ec.CodeAt(ctx, swapCreatorAddr, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if len(code) == 0 {
+		return false, fmt.Errorf("%w: %s", errNoSwapCreatorCode, swapCreatorAddr)
+	}
+
+	pushSelector := append([]byte{push4Opcode}, abiMethod.ID...)
+	return bytes.Contains(stripCodeMetadata(code), pushSelector), nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCodeReader returns the same code for every address
+type mockCodeReader struct {
+	code []byte
+}
+
+func (r *mockCodeReader) CodeAt(_ context.Context, _ ethcommon.Address, _ *big.Int) ([]byte, error) {
+	return r.code, nil
+}
+
+func (r *mockCodeReader) CallContract(_ context.Context, _ ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	panic("not implemented")
+}
+
+func TestSwapCreatorHasMethod(t *testing.T) {
+	ctx := context.Background()
+	deployedCode := ethcommon.FromHex(expectedSwapCreatorBytecodeHex)
+
+	swapCreatorABI, err := SwapCreatorMetaData.GetAbi()
+	require.NoError(t, err)
+	permitSelector := swapCreatorABI.Methods[NewSwapWithPermitMethod].ID
+	codeWithPermit := append(append([]byte{push4Opcode}, permitSelector...), deployedCode...)
+
+	type entry struct {
+		name        string
+		code        []byte
+		method      string
+		hasMethod   bool
+		expectedErr error
+	}
+
+	testCases := []entry{
+		{
+			name:      "method of the deployed contract",
+			code:      deployedCode,
+			method:    "newSwap",
+			hasMethod: true,
+		},
+		{
+			name:      "permit method missing from the deployed contract",
+			code:      deployedCode,
+			method:    NewSwapWithPermitMethod,
+			hasMethod: false,
+		},
+		{
+			name:      "relayed refund method missing from the deployed contract",
+			code:      deployedCode,
+			method:    RefundRelayerMethod,
+			hasMethod: false,
+		},
+		{
+			name:      "relayed new swap method missing from the deployed contract",
+			code:      deployedCode,
+			method:    NewSwapRelayerMethod,
+			hasMethod: false,
+		},
+		{
+			name:      "relayed set ready method missing from the deployed contract",
+			code:      deployedCode,
+			method:    SetReadyRelayerMethod,
+			hasMethod: false,
+		},
+		{
+			name:      "permit method in the dispatcher",
+			code:      codeWithPermit,
+			method:    NewSwapWithPermitMethod,
+			hasMethod: true,
+		},
+		{
+			name:        "no contract",
+			code:        nil,
+			method:      "newSwap",
+			expectedErr: errNoSwapCreatorCode,
+		},
+	}
+
+	for _, tc := range testCases {
+		hasMethod, err := SwapCreatorHasMethod(ctx, &mockCodeReader{code: tc.code}, ethcommon.Address{0x1}, tc.method)
+		if tc.expectedErr != nil {
+			require.ErrorIs(t, err, tc.expectedErr, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.hasMethod, hasMethod, tc.name)
+	}
+
+	_, err = SwapCreatorHasMethod(ctx, &mockCodeReader{code: deployedCode}, ethcommon.Address{0x1}, "unknown")
+	require.ErrorContains(t, err, `SwapCreator has no method "unknown"`)
+}
@@ -0,0 +1,233 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+const (
+	// SwapCreatorCompilerVersion is the full solc version that the SwapCreator bindings
+	// were generated with.
+	SwapCreatorCompilerVersion = "v0.8.19+commit.7dd6d404"
+
+	spdxPrefix = "// SPDX-License-Identifier:"
+
+	verifyStatusPollInterval = 5 * time.Second
+	verifyStatusMaxPolls     = 60
+)
+
+//go:embed contracts/*.sol
+var contractSources embed.FS
+
+var (
+	importRegex = regexp.MustCompile(`^import\s+(?:\{[^}]*\}\s+from\s+)?"([^"]+)";`)
+
+	errVerificationFailed  = errors.New("source verification failed")
+	errVerificationTimeout = errors.New("timed out waiting for source verification")
+)
+
+// SourceVerifierConf holds the settings for submitting contract source code to an
+// Etherscan compatible (Etherscan or Blockscout) verification API.
+type SourceVerifierConf struct {
+	APIURL string        // eg. https://api.etherscan.io/api
+	APIKey string        // not required by all Blockscout instances
+	Proxy  *common.Proxy // SOCKS5 proxy that the API is connected to through, if not nil
+}
+
+// EtherscanAPIURL returns the default Etherscan API URL for the passed chain ID or
+// the empty string if we don't know the URL.
+func EtherscanAPIURL(chainID *big.Int) string {
+	switch chainID.Uint64() {
+	case common.MainnetChainID:
+		return "https://api.etherscan.io/api"
+	case common.SepoliaChainID:
+		return "https://api-sepolia.etherscan.io/api"
+	default:
+		return ""
+	}
+}
+
+// FlattenedSwapCreatorSource returns the source code of SwapCreator.sol with all of
+// its imports inlined into a single file. Only the SPDX license identifier of
+// SwapCreator.sol is kept, as solc rejects files with more than one identifier.
+func FlattenedSwapCreatorSource() (string, error) {
+	var files []string // file bodies with dependencies ordered first
+	if err := collectSources("SwapCreator.sol", make(map[string]bool), &files); err != nil {
+		return "", err
+	}
+
+	// SwapCreator.sol is last, since it was appended after its dependencies
+	top := files[len(files)-1]
+	var sb strings.Builder
+	if strings.HasPrefix(top, spdxPrefix) {
+		end := strings.Index(top, "\n") + 1
+		sb.WriteString(top[:end])
+		top = top[end:]
+	}
+
+	for _, body := range files[:len(files)-1] {
+		body = strings.Replace(body, spdxPrefix, "// License:", 1)
+		sb.WriteString(body)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(top)
+
+	return sb.String(), nil
+}
+
+// collectSources appends the bodies of fileName's (transitive) imports, followed by
+// the body of fileName itself, to files. Import statements are removed.
+func collectSources(fileName string, seen map[string]bool, files *[]string) error {
+	if seen[fileName] {
+		return nil
+	}
+	seen[fileName] = true
+
+	data, err := contractSources.ReadFile(path.Join("contracts", fileName))
+	if err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := importRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if err = collectSources(path.Clean(m[1]), seen, files); err != nil {
+				return err
+			}
+			continue
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+
+	*files = append(*files, strings.TrimRight(body.String(), "\n")+"\n")
+	return nil
+}
+
+// etherscanResponse is the response format shared by all Etherscan API calls
+type etherscanResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  string `json:"result"`
+}
+
+// VerifySwapCreatorSource submits the flattened source code of SwapCreator.sol and
+// the constructor arguments that it was deployed with to an Etherscan compatible
+// API, and waits for the verification to complete.
+func VerifySwapCreatorSource(
+	ctx context.Context,
+	conf *SourceVerifierConf,
+	contractAddr ethcommon.Address,
+	forwarderAddr ethcommon.Address,
+) error {
+	source, err := FlattenedSwapCreatorSource()
+	if err != nil {
+		return err
+	}
+
+	constructorArgs, err := SwapCreatorParsedABI.Pack("", forwarderAddr)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	form.Set("apikey", conf.APIKey)
+	form.Set("module", "contract")
+	form.Set("action", "verifysourcecode")
+	form.Set("contractaddress", contractAddr.Hex())
+	form.Set("sourceCode", source)
+	form.Set("codeformat", "solidity-single-file")
+	form.Set("contractname", "SwapCreator")
+	form.Set("compilerversion", SwapCreatorCompilerVersion)
+	form.Set("optimizationUsed", "0")
+	// the misspelling is part of the Etherscan API
+	form.Set("constructorArguements", ethcommon.Bytes2Hex(constructorArgs))
+
+	client := &http.Client{Transport: conf.Proxy.HTTPTransport()}
+	resp, err := postEtherscan(ctx, client, conf.APIURL, form)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "1" {
+		return fmt.Errorf("%w: %s: %s", errVerificationFailed, resp.Message, resp.Result)
+	}
+
+	guid := resp.Result
+	log.Infof("submitted SwapCreator.sol source for verification: address=%s guid=%s", contractAddr, guid)
+
+	for i := 0; i < verifyStatusMaxPolls; i++ {
+		if err = common.SleepWithContext(ctx, verifyStatusPollInterval); err != nil {
+			return err
+		}
+
+		form = url.Values{}
+		form.Set("apikey", conf.APIKey)
+		form.Set("module", "contract")
+		form.Set("action", "checkverifystatus")
+		form.Set("guid", guid)
+
+		resp, err = postEtherscan(ctx, client, conf.APIURL, form)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case resp.Status == "1":
+			log.Infof("SwapCreator.sol source verified: %s", resp.Result)
+			return nil
+		case strings.Contains(resp.Result, "Pending"):
+			continue
+		default:
+			return fmt.Errorf("%w: %s", errVerificationFailed, resp.Result)
+		}
+	}
+
+	return errVerificationTimeout
+}
+
+func postEtherscan(
+	ctx context.Context,
+	client *http.Client,
+	apiURL string,
+	form url.Values,
+) (*etherscanResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status from %s: %s", apiURL, httpResp.Status)
+	}
+
+	resp := new(etherscanResponse)
+	if err = json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package contracts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenedSwapCreatorSource(t *testing.T) {
+	source, err := FlattenedSwapCreatorSource()
+	require.NoError(t, err)
+
+	require.True(t, strings.HasPrefix(source, "// SPDX-License-Identifier: LGPLv3\n"))
+	require.Equal(t, 1, strings.Count(source, "SPDX-License-Identifier"))
+	require.NotContains(t, source, "import ")
+
+	// every dependency is included exactly once and before SwapCreator
+	swapCreatorIdx := strings.Index(source, "contract SwapCreator ")
+	require.Greater(t, swapCreatorIdx, 0)
+	for _, decl := range []string{
+		"abstract contract Context ",
+		"abstract contract ERC2771Context ",
+		"interface IERC20 ",
+		"contract Secp256k1 ",
+	} {
+		require.Equal(t, 1, strings.Count(source, decl), decl)
+		require.Less(t, strings.Index(source, decl), swapCreatorIdx, decl)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package metrics defines the Prometheus metrics of swapd and the HTTP handler that
+// serves them. The metrics are recorded by the subsystems that own the events, so
+// they are process-wide and registered once.
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const namespace = "swapd"
+
+// Endpoint labels of the RPC latency histogram
+const (
+	EndpointEth               = "eth"
+	EndpointMoneroWallet      = "monero_wallet"
+	EndpointMoneroDaemon      = "monero_daemon"
+	EndpointMoneroLightWallet = "monero_light_wallet"
+)
+
+// Result labels of the relay request counter
+const (
+	RelayResultRelayed     = "relayed"
+	RelayResultRejected    = "rejected"
+	RelayResultRateLimited = "rate_limited"
+	RelayResultFailed      = "failed"
+)
+
+// ProtocolBandwidth is the number of bytes received and sent on the streams of a
+// p2p protocol.
+type ProtocolBandwidth struct {
+	Protocol string
+	BytesIn  int64
+	BytesOut int64
+}
+
+var (
+	registry = prometheus.NewRegistry()
+
+	swapsStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swaps_started_total",
+		Help:      "Number of swaps started, by the coin we provide.",
+	}, []string{"provides"})
+
+	swapsCompleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swaps_completed_total",
+		Help:      "Number of swaps that completed successfully, by the coin we provide.",
+	}, []string{"provides"})
+
+	swapsRefunded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swaps_refunded_total",
+		Help:      "Number of swaps that were refunded, by the coin we provide.",
+	}, []string{"provides"})
+
+	swapsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swaps_failed_total",
+		Help:      "Number of swaps that aborted before any funds were locked, by the coin we provide.",
+	}, []string{"provides"})
+
+	swapStageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "swap_stage_duration_seconds",
+		Help:      "Time that swaps spent in each stage before moving to the next one.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 16), // 1s to ~9h
+	}, []string{"stage"})
+
+	relayRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "relay_requests_total",
+		Help:      "Number of claim relay requests received from peers, by result.",
+	}, []string{"result"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "Latency of the HTTP requests to the ethereum and monero endpoints.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	gasSpent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gas_spent_eth_total",
+		Help:      "ETH spent on the gas of our included transactions, by transaction type.",
+	}, []string{"tx"})
+
+	p2pMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "p2p_messages_received_total",
+		Help:      "Number of messages received from peers, by protocol and whether they could be decoded.",
+	}, []string{"protocol", "result"})
+
+	staticPeerConnected = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "p2p_static_peer_connected",
+		Help:      "Whether we are connected to each static peer (1) or failed to reconnect to it (0).",
+	}, []string{"peer"})
+
+	p2pBandwidth = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "p2p_bandwidth_bytes_total"),
+		"Bytes received and sent on the streams of each p2p protocol.",
+		[]string{"protocol", "direction"}, nil,
+	)
+
+	// peerCounter returns the number of connected peers, it is nil until the p2p
+	// host is started.
+	peerCounter atomic.Pointer[func() int]
+
+	// bandwidthReporter returns the bandwidth used by each p2p protocol, it is nil
+	// until the p2p host is started.
+	bandwidthReporter atomic.Pointer[func() []ProtocolBandwidth]
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		swapsStarted,
+		swapsCompleted,
+		swapsRefunded,
+		swapsFailed,
+		swapStageDuration,
+		relayRequests,
+		rpcDuration,
+		gasSpent,
+		p2pMessages,
+		staticPeerConnected,
+		bandwidthCollector{},
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "p2p_peers",
+			Help:      "Number of connected p2p peers.",
+		}, func() float64 {
+			count := peerCounter.Load()
+			if count == nil {
+				return 0
+			}
+			return float64((*count)())
+		}),
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// SwapStarted records the start of a swap in which we provide the coin.
+func SwapStarted(provides coins.ProvidesCoin) {
+	swapsStarted.WithLabelValues(string(provides)).Inc()
+}
+
+// SwapEnded records the end of a swap in which we provide the coin, with its final
+// status.
+func SwapEnded(provides coins.ProvidesCoin, status types.Status) {
+	switch status {
+	case types.CompletedSuccess:
+		swapsCompleted.WithLabelValues(string(provides)).Inc()
+	case types.CompletedRefund:
+		swapsRefunded.WithLabelValues(string(provides)).Inc()
+	case types.CompletedAbort:
+		swapsFailed.WithLabelValues(string(provides)).Inc()
+	}
+}
+
+// SwapStageEnded records the time that a swap spent in the stage that it is leaving.
+func SwapStageEnded(stage types.Status, enteredAt time.Time) {
+	swapStageDuration.WithLabelValues(stage.String()).Observe(time.Since(enteredAt).Seconds())
+}
+
+// RelayRequest records the result of a claim relay request received from a peer.
+func RelayRequest(result string) {
+	relayRequests.WithLabelValues(result).Inc()
+}
+
+// SetPeerCounter sets the function returning the number of connected p2p peers.
+func SetPeerCounter(count func() int) {
+	peerCounter.Store(&count)
+}
+
+// P2PMessageReceived records a message received from a peer on the protocol, which
+// is invalid if it could not be decoded.
+func P2PMessageReceived(protocol string, valid bool) {
+	result := "valid"
+	if !valid {
+		result = "invalid"
+	}
+	p2pMessages.WithLabelValues(protocol, result).Inc()
+}
+
+// StaticPeerConnected records whether we are connected to the static peer.
+func StaticPeerConnected(peerID string, connected bool) {
+	value := 0.0
+	if connected {
+		value = 1
+	}
+	staticPeerConnected.WithLabelValues(peerID).Set(value)
+}
+
+// SetBandwidthReporter sets the function returning the bandwidth used by each p2p
+// protocol.
+func SetBandwidthReporter(report func() []ProtocolBandwidth) {
+	bandwidthReporter.Store(&report)
+}
+
+// bandwidthCollector collects the bandwidth of the p2p protocols from the bandwidth
+// reporter, as the protocols are only known once peers use them.
+type bandwidthCollector struct{}
+
+func (bandwidthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p2pBandwidth
+}
+
+func (bandwidthCollector) Collect(ch chan<- prometheus.Metric) {
+	report := bandwidthReporter.Load()
+	if report == nil {
+		return
+	}
+
+	for _, bw := range (*report)() {
+		ch <- prometheus.MustNewConstMetric(p2pBandwidth, prometheus.CounterValue, float64(bw.BytesIn), bw.Protocol, "in")
+		ch <- prometheus.MustNewConstMetric(p2pBandwidth, prometheus.CounterValue, float64(bw.BytesOut), bw.Protocol, "out")
+	}
+}
+
+// GasSpent records the gas cost of an included transaction of the given type.
+func GasSpent(tx string, receipt *ethtypes.Receipt) {
+	if receipt.EffectiveGasPrice == nil {
+		return
+	}
+
+	wei := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	eth, _ := coins.NewWeiAmount(wei).AsEther().Float64()
+	gasSpent.WithLabelValues(tx).Add(eth)
+}
+
+// NewHTTPClient returns an HTTP client that records the latency of its requests to
+// the endpoint in the RPC latency histogram. The requests are made with the transport,
+// http.DefaultTransport if it is nil.
+func NewHTTPClient(endpoint string, transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	observer := rpcDuration.MustCurryWith(prometheus.Labels{"endpoint": endpoint})
+	return &http.Client{
+		Transport: promhttp.InstrumentRoundTripperDuration(observer, transport),
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package metrics
+
+import (
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestHandler(t *testing.T) {
+	SwapStarted(coins.ProvidesXMR)
+	SwapEnded(coins.ProvidesXMR, types.CompletedRefund)
+	SwapStageEnded(types.XMRLocked, time.Now().Add(-time.Minute))
+	RelayRequest(RelayResultRelayed)
+	SetPeerCounter(func() int { return 3 })
+	GasSpent("claim", &ethtypes.Receipt{GasUsed: 50000, EffectiveGasPrice: big.NewInt(2e9)})
+	P2PMessageReceived("/query/1", true)
+	P2PMessageReceived("/query/1", false)
+	StaticPeerConnected("peer1", true)
+	StaticPeerConnected("peer2", false)
+	SetBandwidthReporter(func() []ProtocolBandwidth {
+		return []ProtocolBandwidth{{Protocol: "/query/1", BytesIn: 100, BytesOut: 2000}}
+	})
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	for _, line := range []string{
+		`swapd_swaps_started_total{provides="XMR"} 1`,
+		`swapd_swaps_refunded_total{provides="XMR"} 1`,
+		`swapd_swap_stage_duration_seconds_count{stage="XMRLocked"} 1`,
+		`swapd_relay_requests_total{result="relayed"} 1`,
+		`swapd_p2p_peers 3`,
+		`swapd_gas_spent_eth_total{tx="claim"} 0.0001`,
+		`swapd_p2p_messages_received_total{protocol="/query/1",result="valid"} 1`,
+		`swapd_p2p_messages_received_total{protocol="/query/1",result="invalid"} 1`,
+		`swapd_p2p_bandwidth_bytes_total{direction="in",protocol="/query/1"} 100`,
+		`swapd_p2p_bandwidth_bytes_total{direction="out",protocol="/query/1"} 2000`,
+		`swapd_p2p_static_peer_connected{peer="peer1"} 1`,
+		`swapd_p2p_static_peer_connected{peer="peer2"} 0`,
+	} {
+		require.Contains(t, string(body), line)
+	}
+}
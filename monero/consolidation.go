@@ -0,0 +1,134 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"sort"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+const (
+	// ConsolidationInputThreshold is the number of inputs above which funding a transfer
+	// is considered fragmented. Every input adds to the size and the fee of the
+	// transaction, so the outputs should be consolidated before funding it.
+	ConsolidationInputThreshold = 16
+
+	// MaxTransferInputs is the number of inputs above which a transfer is likely to
+	// fail, as the transaction gets too big to be relayed.
+	MaxTransferInputs = 100
+)
+
+// ConsolidationPlan is the number of unlocked outputs that a transfer of an amount
+// spends, and the outputs that should be swept to our own address beforehand if it
+// spends too many.
+type ConsolidationPlan struct {
+	Amount          uint64 // piconero to transfer
+	UnlockedOutputs int    // number of unlocked outputs of the primary account
+	UnlockedBalance uint64 // piconero
+	// InputsNeeded is the minimum number of unlocked outputs whose sum covers the
+	// amount, excluding the fee, or all of them if they don't cover it. The wallet
+	// doesn't always select the largest outputs, so it can spend more.
+	InputsNeeded int
+	Recommended  bool // whether the outputs should be consolidated first
+	// SweepBelow is the amount below which the outputs should be swept to our own
+	// address, zero if no consolidation is recommended
+	SweepBelow   uint64
+	SweepOutputs int    // number of outputs below SweepBelow
+	SweepAmount  uint64 // piconero sum of the outputs below SweepBelow
+}
+
+// Sufficient returns whether the unlocked outputs cover the amount.
+func (p *ConsolidationPlan) Sufficient() bool {
+	return p.UnlockedBalance >= p.Amount
+}
+
+// PlanConsolidation returns how many unlocked outputs of the primary account a
+// transfer of the amount spends, and which outputs to sweep to our own address
+// beforehand if the transfer would need more than ConsolidationInputThreshold inputs.
+// The outputs can then be consolidated with SweepUnlocked, which locks the swept
+// amount for MinSpendConfirmations blocks.
+func (c *walletClient) PlanConsolidation(amount *coins.PiconeroAmount) (*ConsolidationPlan, error) {
+	amt, err := amount.Uint64()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.refresh(); err != nil {
+		return nil, err
+	}
+
+	res := new(incomingTransfersResponse)
+	err = c.callWalletRPC("incoming_transfers", &incomingTransfersRequest{
+		TransferType: "available",
+		AccountIndex: 0,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []uint64
+	for _, t := range res.Transfers {
+		if t.Unlocked {
+			outputs = append(outputs, t.Amount)
+		}
+	}
+
+	return planConsolidation(outputs, amt), nil
+}
+
+// planConsolidation plans the consolidation of the outputs for a transfer of the
+// amount. The fewest outputs that cover an amount are the largest ones, so when more
+// than ConsolidationInputThreshold of them are needed, the plan keeps the largest
+// outputs and sweeps the smaller ones into a few new outputs.
+func planConsolidation(outputs []uint64, amount uint64) *ConsolidationPlan {
+	sorted := append([]uint64{}, outputs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] > sorted[j]
+	})
+
+	p := &ConsolidationPlan{
+		Amount:          amount,
+		UnlockedOutputs: len(sorted),
+	}
+
+	for _, o := range sorted {
+		p.UnlockedBalance += o
+	}
+
+	var sum uint64
+	for _, o := range sorted {
+		if sum >= amount {
+			break
+		}
+		sum += o
+		p.InputsNeeded++
+	}
+
+	if p.InputsNeeded <= ConsolidationInputThreshold {
+		return p
+	}
+
+	// keep the largest outputs, leaving room in the transfer for the outputs that
+	// the sweep creates
+	kept := ConsolidationInputThreshold / 2
+	p.SweepBelow = sorted[kept-1]
+	for _, o := range sorted[kept:] {
+		if o < p.SweepBelow {
+			p.SweepOutputs++
+			p.SweepAmount += o
+		}
+	}
+
+	// many outputs of the same amount as the smallest kept output can't be swept
+	// below it, so the plan would not reduce the inputs
+	p.Recommended = p.SweepOutputs > 1
+	if !p.Recommended {
+		p.SweepBelow = 0
+		p.SweepOutputs = 0
+		p.SweepAmount = 0
+	}
+
+	return p
+}
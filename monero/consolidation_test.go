@@ -0,0 +1,68 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_planConsolidation_notFragmented(t *testing.T) {
+	outputs := []uint64{5, 100, 20, 1}
+	p := planConsolidation(outputs, 110)
+	require.Equal(t, 4, p.UnlockedOutputs)
+	require.Equal(t, uint64(126), p.UnlockedBalance)
+	require.True(t, p.Sufficient())
+	require.Equal(t, 2, p.InputsNeeded) // 100 + 20
+	require.False(t, p.Recommended)
+	require.Zero(t, p.SweepBelow)
+
+	// the input order is not changed
+	require.Equal(t, []uint64{5, 100, 20, 1}, outputs)
+
+	p = planConsolidation(outputs, 0)
+	require.Zero(t, p.InputsNeeded)
+	require.False(t, p.Recommended)
+}
+
+func Test_planConsolidation_fragmented(t *testing.T) {
+	// one large output and many dust outputs of decreasing amounts
+	outputs := []uint64{1000}
+	for i := 0; i < 50; i++ {
+		outputs = append(outputs, uint64(100-i))
+	}
+
+	p := planConsolidation(outputs, 3000)
+	require.Equal(t, 51, p.UnlockedOutputs)
+	require.True(t, p.Sufficient())
+	require.Greater(t, p.InputsNeeded, ConsolidationInputThreshold)
+	require.True(t, p.Recommended)
+
+	// the 1000 output and the next 7 largest are kept
+	kept := ConsolidationInputThreshold / 2
+	require.Equal(t, uint64(100-(kept-2)), p.SweepBelow)
+	require.Equal(t, 51-kept, p.SweepOutputs)
+
+	var swept uint64
+	for _, o := range outputs[kept:] {
+		swept += o
+	}
+	require.Equal(t, swept, p.SweepAmount)
+}
+
+func Test_planConsolidation_insufficient(t *testing.T) {
+	outputs := make([]uint64, 20)
+	for i := range outputs {
+		outputs[i] = 10
+	}
+
+	p := planConsolidation(outputs, 1000)
+	require.False(t, p.Sufficient())
+	require.Equal(t, 20, p.InputsNeeded)
+
+	// all outputs have the same amount, so none of them can be swept below the kept ones
+	require.False(t, p.Recommended)
+	require.Zero(t, p.SweepOutputs)
+}
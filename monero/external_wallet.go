@@ -0,0 +1,98 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/MarinX/monerorpc"
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/gabstv/httpdigest"
+
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+// ExternalWalletConf is the configuration of an operator-managed monero-wallet-rpc
+// instance, which swapd uses for its primary wallet instead of launching its own
+// monero-wallet-rpc process. The temporary wallets of swaps are still created in
+// monero-wallet-rpc processes launched by swapd.
+type ExternalWalletConf struct {
+	URL        string // Required, like "http://10.0.0.5:18083", "/json_rpc" is added if there is no path
+	Username   string // Optional, user of the instance's --rpc-login
+	Password   string // Optional, password of the instance's --rpc-login
+	WalletName string // Optional, wallet file opened in the instance's --wallet-dir, else the open wallet is used
+}
+
+// endpoint returns the JSON-RPC endpoint of the monero-wallet-rpc instance.
+func (conf *ExternalWalletConf) endpoint() (string, error) {
+	u, err := url.Parse(conf.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid monero-wallet-rpc URL %q: %w", conf.URL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid monero-wallet-rpc URL %q: expected http(s)://host:port", conf.URL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/json_rpc"
+	}
+	return u.String(), nil
+}
+
+// transport returns the transport of the requests to the monero-wallet-rpc
+// instance, which authenticates them with HTTP digest authentication if the
+// instance has a login.
+func (conf *ExternalWalletConf) transport(base http.RoundTripper) http.RoundTripper {
+	if conf.Username == "" && conf.Password == "" {
+		return base
+	}
+
+	t := httpdigest.New(conf.Username, conf.Password)
+	t.Transport = base
+	return t
+}
+
+// newExternalWalletClient returns the client of the wallet of an operator-managed
+// monero-wallet-rpc instance, opening the configured wallet if there is one.
+func newExternalWalletClient(conf *WalletClientConf) (*walletClient, error) {
+	ext := conf.ExternalWallet
+	endpoint, err := ext.endpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	validatedNode := conf.MonerodNodes[0]
+	c := newThinWalletClient(validatedNode.Host, validatedNode.Port, 0, conf.Proxy)
+	transport := ext.transport(conf.Proxy.HTTPTransport())
+	c.wRPC = monerorpc.New(endpoint, metrics.NewHTTPClient(metrics.EndpointMoneroWallet, transport)).Wallet
+	c.endpoint = endpoint
+	c.conf = conf
+
+	if ext.WalletName != "" {
+		err = c.wRPC.OpenWallet(&wallet.OpenWalletRequest{
+			Filename: ext.WalletName,
+			Password: conf.WalletPassword,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open wallet %q of monero-wallet-rpc at %s: %w",
+				ext.WalletName, endpoint, err)
+		}
+	}
+
+	acctResp, err := c.GetAddress(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the wallet address of monero-wallet-rpc at %s: %w", endpoint, err)
+	}
+
+	c.walletAddr, err = mcrypto.NewAddress(acctResp.Address, conf.Env)
+	if err != nil {
+		return nil, fmt.Errorf("wallet of monero-wallet-rpc at %s: %w", endpoint, err)
+	}
+
+	log.Infof("Using the wallet of monero-wallet-rpc at %s, address is %s", endpoint, c.walletAddr)
+	conf.nodePool.register(c)
+	return c, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestExternalWalletConf_endpoint(t *testing.T) {
+	conf := &ExternalWalletConf{URL: "http://10.0.0.5:18083"}
+	endpoint, err := conf.endpoint()
+	require.NoError(t, err)
+	require.Equal(t, "http://10.0.0.5:18083/json_rpc", endpoint)
+
+	conf.URL = "https://wallet.example.com/json_rpc"
+	endpoint, err = conf.endpoint()
+	require.NoError(t, err)
+	require.Equal(t, "https://wallet.example.com/json_rpc", endpoint)
+
+	for _, invalidURL := range []string{"10.0.0.5:18083", "ftp://10.0.0.5:18083", "http://"} {
+		conf.URL = invalidURL
+		_, err = conf.endpoint()
+		require.Error(t, err, invalidURL)
+	}
+}
+
+func TestNewWalletClient_external(t *testing.T) {
+	managed := CreateWalletClient(t)
+
+	conf := &WalletClientConf{
+		Env:                 common.Development,
+		WalletFilePath:      path.Join(t.TempDir(), "wallet", "unused"),
+		MoneroWalletRPCPath: GetWalletRPCDirectory(t),
+		ExternalWallet:      &ExternalWalletConf{URL: managed.Endpoint()},
+	}
+	external, err := NewWalletClient(conf)
+	require.NoError(t, err)
+	require.Equal(t, managed.PrimaryAddress(), external.PrimaryAddress())
+	require.Equal(t, managed.Endpoint(), external.Endpoint())
+
+	// the external monero-wallet-rpc instance is not stopped
+	external.Close()
+	_, err = managed.GetHeight()
+	require.NoError(t, err)
+
+	// the external instance opens the configured wallet
+	conf.ExternalWallet.WalletName = managed.WalletName()
+	external, err = NewWalletClient(conf)
+	require.NoError(t, err)
+	require.Equal(t, managed.PrimaryAddress(), external.PrimaryAddress())
+	require.Equal(t, managed.WalletName(), external.WalletName())
+
+	// swap wallets are created in monero-wallet-rpc processes that we launch
+	swapConf := external.CreateWalletConf("swap-wallet")
+	require.Nil(t, swapConf.ExternalWallet)
+	require.Equal(t, path.Dir(conf.WalletFilePath), path.Dir(swapConf.WalletFilePath))
+}
+
+func TestNewWalletClient_externalNoWallet(t *testing.T) {
+	_, err := NewWalletClient(&WalletClientConf{
+		Env:                 common.Development,
+		WalletFilePath:      path.Join(t.TempDir(), "wallet", "unused"),
+		MoneroWalletRPCPath: GetWalletRPCDirectory(t),
+		ExternalWallet: &ExternalWalletConf{
+			URL:        "http://127.0.0.1:1",
+			WalletName: "missing",
+		},
+	})
+	require.ErrorContains(t, err, "failed to open wallet")
+}
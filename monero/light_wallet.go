@@ -0,0 +1,492 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MarinX/monerorpc/wallet"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+const (
+	// coinbaseConfirmations is the number of confirmations required on coinbase
+	// outputs before they can be spent.
+	coinbaseConfirmations = 60
+
+	// maxUnlockHeight is the largest unlock time that is a block height, larger unlock
+	// times are unix timestamps
+	maxUnlockHeight = 500_000_000
+
+	lightWalletRequestTimeout = 30 * time.Second
+)
+
+// ErrLightWalletUnsupported is returned by the operations that a light wallet server
+// doesn't support, which need the wallet file of monero-wallet-rpc.
+var ErrLightWalletUnsupported = errors.New("not supported with a light wallet server")
+
+// LightWalletConf is the configuration of a view-only primary wallet whose transfers
+// are scanned by a monero-lws light wallet server, instead of by a wallet file that
+// monero-wallet-rpc keeps synced with monerod. The address and the private view key
+// of the wallet are those of the ViewOnly configuration, which is required. The
+// temporary wallets of swaps are still created in monero-wallet-rpc processes
+// launched by swapd, which only scan the blocks of the swap.
+type LightWalletConf struct {
+	URL string // Required, like "http://127.0.0.1:8443", the base URL of the REST API
+}
+
+// baseURL returns the base URL of the REST API of the light wallet server.
+func (conf *LightWalletConf) baseURL() (string, error) {
+	u, err := url.Parse(conf.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid light wallet server URL %q: %w", conf.URL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid light wallet server URL %q: expected http(s)://host:port", conf.URL)
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+// lightWalletClient is a view-only primary wallet whose balance and incoming transfers
+// come from a monero-lws light wallet server. The embedded thin client, which has no
+// monero-wallet-rpc, is only used for the monerod requests and the configuration, so
+// every method that calls monero-wallet-rpc is overridden.
+type lightWalletClient struct {
+	*walletClient
+	httpClient *http.Client
+	url        string
+	viewKey    *mcrypto.PrivateViewKey
+}
+
+var _ WalletClient = (*lightWalletClient)(nil)
+
+// lwsAccountRequest is the body of the requests of an account of the light wallet
+// server, which authenticates them with the account's private view key.
+type lwsAccountRequest struct {
+	Address string `json:"address"`
+	ViewKey string `json:"view_key"`
+}
+
+type lwsLoginRequest struct {
+	lwsAccountRequest
+	CreateAccount    bool `json:"create_account"`
+	GeneratedLocally bool `json:"generated_locally"`
+}
+
+type lwsLoginResponse struct {
+	NewAddress  bool   `json:"new_address"`
+	StartHeight uint64 `json:"start_height"`
+}
+
+type lwsAddressInfoResponse struct {
+	ScannedBlockHeight uint64 `json:"scanned_block_height"`
+	StartHeight        uint64 `json:"start_height"`
+	BlockchainHeight   uint64 `json:"blockchain_height"`
+}
+
+type lwsTransaction struct {
+	Hash          string    `json:"hash"`
+	Timestamp     time.Time `json:"timestamp"`
+	TotalReceived uint64    `json:"total_received,string"`
+	UnlockTime    uint64    `json:"unlock_time"`
+	Height        uint64    `json:"height"`
+	Mempool       bool      `json:"mempool"`
+	Coinbase      bool      `json:"coinbase"`
+}
+
+type lwsAddressTxsResponse struct {
+	ScannedBlockHeight uint64            `json:"scanned_block_height"`
+	BlockchainHeight   uint64            `json:"blockchain_height"`
+	Transactions       []*lwsTransaction `json:"transactions"`
+}
+
+// newLightWalletClient returns the client of the account of the configured view-only
+// wallet on the light wallet server, creating the account if it doesn't exist.
+func newLightWalletClient(conf *WalletClientConf) (*lightWalletClient, error) {
+	if conf.ViewOnly == nil {
+		return nil, errors.New("a light wallet server requires the address and view key of a view-only wallet")
+	}
+	if conf.ExternalWallet != nil {
+		return nil, errors.New("a light wallet server can't be used with an external monero-wallet-rpc")
+	}
+
+	baseURL, err := conf.LightWallet.baseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	validatedNode := conf.MonerodNodes[0]
+	thin := newThinWalletClient(validatedNode.Host, validatedNode.Port, 0, conf.Proxy)
+	thin.walletAddr = conf.ViewOnly.Address
+	thin.conf = conf
+
+	httpClient := metrics.NewHTTPClient(metrics.EndpointMoneroLightWallet, conf.Proxy.HTTPTransport())
+	httpClient.Timeout = lightWalletRequestTimeout
+	c := &lightWalletClient{
+		walletClient: thin,
+		httpClient:   httpClient,
+		url:          baseURL,
+		viewKey:      conf.ViewOnly.ViewKey,
+	}
+
+	if err = c.login(conf.ViewOnly); err != nil {
+		return nil, err
+	}
+
+	conf.nodePool.register(thin)
+	return c, nil
+}
+
+// login creates the account of the wallet on the light wallet server if it doesn't
+// exist yet. The server only scans the account from the height that it was created
+// at, unless its operator rescans it, so a lower restore height is only warned about.
+func (c *lightWalletClient) login(viewOnly *ViewOnlyWalletConf) error {
+	res := new(lwsLoginResponse)
+	err := c.post("login", &lwsLoginRequest{
+		lwsAccountRequest: c.accountRequest(),
+		CreateAccount:     true,
+	}, res)
+	if err != nil {
+		return err
+	}
+
+	if res.NewAddress {
+		log.Infof("Created the account of %s on the light wallet server %s", c.PrimaryAddress(), c.url)
+	}
+	log.Infof("Using the light wallet server %s for the view-only wallet of %s, scanned from block %d",
+		c.url, c.PrimaryAddress(), res.StartHeight)
+
+	restoreHeight := viewOnly.RestoreHeight
+	if !viewOnly.RestoreDate.IsZero() {
+		restoreHeight, err = c.restoreHeightFromDate(viewOnly.RestoreDate)
+		if err != nil {
+			return err
+		}
+	}
+	if (viewOnly.RestoreHeight != 0 || !viewOnly.RestoreDate.IsZero()) && restoreHeight < res.StartHeight {
+		log.Warnf("The light wallet server scans the wallet from block %d, not from the restore height %d, "+
+			"the transfers before it are missing until the server's operator rescans the account",
+			res.StartHeight, restoreHeight)
+	}
+
+	return nil
+}
+
+func (c *lightWalletClient) accountRequest() lwsAccountRequest {
+	return lwsAccountRequest{
+		Address: c.PrimaryAddress().String(),
+		ViewKey: c.viewKey.Hex(),
+	}
+}
+
+// post sends a request to the REST API of the light wallet server.
+func (c *lightWalletClient) post(method string, req any, res any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpResp, err := c.httpClient.Post(c.url+"/"+method, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("light wallet server request %s failed: %w", method, err)
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return fmt.Errorf("light wallet server request %s failed: the account of %s is not approved by the "+
+			"server's operator yet", method, c.PrimaryAddress())
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		return fmt.Errorf("light wallet server request %s failed: %s %s",
+			method, httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if err = json.NewDecoder(httpResp.Body).Decode(res); err != nil {
+		return fmt.Errorf("failed to decode the light wallet server response of %s: %w", method, err)
+	}
+	return nil
+}
+
+func (c *lightWalletClient) getAddressInfo() (*lwsAddressInfoResponse, error) {
+	res := new(lwsAddressInfoResponse)
+	if err := c.post("get_address_info", c.accountRequest(), res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *lightWalletClient) getAddressTxs() (*lwsAddressTxsResponse, error) {
+	res := new(lwsAddressTxsResponse)
+	if err := c.post("get_address_txs", c.accountRequest(), res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// inPool returns whether the transaction is in the transaction pool.
+func (t *lwsTransaction) inPool() bool {
+	return t.Mempool || t.Height == 0
+}
+
+// blocksToUnlock returns the number of blocks until the outputs of the mined
+// transaction can be spent, at the blockchain height. Unlock times that are
+// timestamps are not taken into account.
+func (t *lwsTransaction) blocksToUnlock(chainHeight uint64) uint64 {
+	confirmations := uint64(MinSpendConfirmations)
+	if t.Coinbase {
+		confirmations = coinbaseConfirmations
+	}
+
+	unlockHeight := t.Height + confirmations
+	if t.UnlockTime < maxUnlockHeight && t.UnlockTime > unlockHeight {
+		unlockHeight = t.UnlockTime
+	}
+
+	if unlockHeight <= chainHeight {
+		return 0
+	}
+	return unlockHeight - chainHeight
+}
+
+func (c *lightWalletClient) WalletName() string {
+	return fmt.Sprintf("light wallet of %s", c.PrimaryAddress())
+}
+
+func (c *lightWalletClient) GetAccounts() (*wallet.GetAccountsResponse, error) {
+	return nil, ErrLightWalletUnsupported
+}
+
+// GetAddress returns the primary address, the light wallet server doesn't support
+// other accounts.
+func (c *lightWalletClient) GetAddress(idx uint64) (*wallet.GetAddressResponse, error) {
+	if idx != 0 {
+		return nil, ErrLightWalletUnsupported
+	}
+	return &wallet.GetAddressResponse{Address: c.PrimaryAddress().String()}, nil
+}
+
+func (c *lightWalletClient) CreateSubaddress(_ string) (*mcrypto.Address, error) {
+	return nil, ErrLightWalletUnsupported
+}
+
+func (c *lightWalletClient) RestoreFromSeed(_ string, _ uint64) (*mcrypto.Address, error) {
+	return nil, ErrViewOnlyWallet
+}
+
+// GetBalance returns the balance of the primary account from the transactions that
+// the light wallet server found. Like the balance of a view-only wallet-file, it
+// includes the outputs that were spent, as spends can't be told apart without the
+// private spend key.
+func (c *lightWalletClient) GetBalance(idx uint64) (*wallet.GetBalanceResponse, error) {
+	if idx != 0 {
+		return nil, ErrLightWalletUnsupported
+	}
+
+	res, err := c.getAddressTxs()
+	if err != nil {
+		return nil, err
+	}
+
+	balance := new(wallet.GetBalanceResponse)
+	for _, t := range res.Transactions {
+		if t.inPool() || t.TotalReceived == 0 {
+			continue
+		}
+
+		balance.Balance += t.TotalReceived
+		blocks := t.blocksToUnlock(res.BlockchainHeight)
+		if blocks == 0 {
+			balance.UnlockedBalance += t.TotalReceived
+		} else if blocks > balance.BlocksToUnlock {
+			balance.BlocksToUnlock = blocks
+		}
+	}
+
+	return balance, nil
+}
+
+func (c *lightWalletClient) Transfer(
+	_ context.Context,
+	_ *mcrypto.Address,
+	_ uint64,
+	_ *coins.PiconeroAmount,
+	_ uint64,
+	_ wallet.Priority,
+) (*wallet.Transfer, error) {
+	return nil, ErrViewOnlyWallet
+}
+
+func (c *lightWalletClient) SweepAll(
+	_ context.Context,
+	_ *mcrypto.Address,
+	_ uint64,
+	_ uint64,
+	_ wallet.Priority,
+) ([]*wallet.Transfer, error) {
+	return nil, ErrViewOnlyWallet
+}
+
+func (c *lightWalletClient) Withdraw(
+	_ *mcrypto.Address,
+	_ *coins.PiconeroAmount,
+	_ wallet.Priority,
+) (*Withdrawal, error) {
+	return nil, ErrViewOnlyWallet
+}
+
+func (c *lightWalletClient) SweepUnlocked(
+	_ *mcrypto.Address,
+	_ *coins.PiconeroAmount,
+	_ wallet.Priority,
+) (*Withdrawal, error) {
+	return nil, ErrViewOnlyWallet
+}
+
+func (c *lightWalletClient) PlanConsolidation(_ *coins.PiconeroAmount) (*ConsolidationPlan, error) {
+	return nil, ErrViewOnlyWallet
+}
+
+func (c *lightWalletClient) GetTxProof(_ string, _ *mcrypto.Address, _ string) (string, error) {
+	return "", ErrViewOnlyWallet
+}
+
+// CheckTxProof isn't supported, as proofs are checked by monero-wallet-rpc. Takers
+// find the lock of the swap with a swap wallet instead.
+func (c *lightWalletClient) CheckTxProof(
+	_ string,
+	_ *mcrypto.Address,
+	_ string,
+	_ string,
+) (*wallet.CheckTxProofResponse, error) {
+	return nil, ErrLightWalletUnsupported
+}
+
+// GetHeight returns the height up to which the light wallet server scanned the
+// wallet.
+func (c *lightWalletClient) GetHeight() (uint64, error) {
+	res, err := c.getAddressInfo()
+	if err != nil {
+		return 0, err
+	}
+	return res.ScannedBlockHeight, nil
+}
+
+// GetSyncHeights returns the height up to which the light wallet server scanned the
+// wallet and the blockchain height of the server.
+func (c *lightWalletClient) GetSyncHeights() (uint64, uint64, error) {
+	res, err := c.getAddressInfo()
+	if err != nil {
+		return 0, 0, err
+	}
+	return res.ScannedBlockHeight, res.BlockchainHeight, nil
+}
+
+func (c *lightWalletClient) GetSyncProgress() (*SyncProgress, error) {
+	walletHeight, daemonHeight, err := c.GetSyncHeights()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.syncTracker.progress(walletHeight, daemonHeight, time.Now()), nil
+}
+
+// GetIncomingTransfers returns the transactions that the light wallet server found
+// to send to the wallet, from the given block height, with the ones in the
+// transaction pool last.
+func (c *lightWalletClient) GetIncomingTransfers(minHeight uint64) ([]*wallet.Transfer, error) {
+	res, err := c.getAddressTxs()
+	if err != nil {
+		return nil, err
+	}
+
+	var in, pool []*wallet.Transfer
+	for _, t := range res.Transactions {
+		if t.TotalReceived == 0 {
+			continue
+		}
+
+		transfer := &wallet.Transfer{
+			Address:    c.PrimaryAddress().String(),
+			TxID:       t.Hash,
+			Amount:     t.TotalReceived,
+			Timestamp:  uint64(t.Timestamp.Unix()),
+			UnlockTime: t.UnlockTime,
+		}
+
+		if t.inPool() {
+			transfer.Type = "pool"
+			pool = append(pool, transfer)
+			continue
+		}
+
+		if t.Height < minHeight {
+			continue
+		}
+
+		transfer.Type = "in"
+		transfer.Height = t.Height
+		if res.BlockchainHeight > t.Height {
+			transfer.Confirmations = res.BlockchainHeight - t.Height
+		}
+		in = append(in, transfer)
+	}
+
+	return append(in, pool...), nil
+}
+
+// GetLockedOutputs returns the mined transactions to the wallet whose outputs can't
+// be spent yet, from the first to unlock. The light wallet server reports amounts
+// per transaction, so the outputs of a transaction are combined.
+func (c *lightWalletClient) GetLockedOutputs() ([]*LockedOutput, error) {
+	res, err := c.getAddressTxs()
+	if err != nil {
+		return nil, err
+	}
+
+	var locked []*LockedOutput
+	for _, t := range res.Transactions {
+		if t.inPool() || t.TotalReceived == 0 {
+			continue
+		}
+
+		blocks := t.blocksToUnlock(res.BlockchainHeight)
+		if blocks == 0 {
+			continue
+		}
+
+		locked = append(locked, &LockedOutput{
+			TxID:           t.Hash,
+			Amount:         t.TotalReceived,
+			Height:         t.Height,
+			BlocksToUnlock: blocks,
+		})
+	}
+
+	sortLockedOutputs(locked)
+	return locked, nil
+}
+
+// Endpoint returns the base URL of the light wallet server.
+func (c *lightWalletClient) Endpoint() string {
+	return c.url
+}
+
+// CloseAndRemoveWallet only closes the client, there are no wallet files to remove.
+func (c *lightWalletClient) CloseAndRemoveWallet() {
+	c.Close()
+}
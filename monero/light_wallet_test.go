@@ -0,0 +1,154 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+// newTestLightWalletClient returns a light wallet client of a new view-only wallet
+// whose light wallet server responds to each method with the response.
+func newTestLightWalletClient(t *testing.T, responses map[string]string) *lightWalletClient {
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	address := kp.PublicKeyPair().Address(common.Development)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(lwsAccountRequest)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		if req.Address != address.String() || req.ViewKey != kp.ViewKey().Hex() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		res, ok := responses[r.URL.Path[1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(res))
+	}))
+	t.Cleanup(srv.Close)
+
+	return &lightWalletClient{
+		walletClient: &walletClient{
+			walletAddr: address,
+			conf: &WalletClientConf{
+				Env:      common.Development,
+				ViewOnly: &ViewOnlyWalletConf{Address: address, ViewKey: kp.ViewKey()},
+			},
+		},
+		httpClient: srv.Client(),
+		url:        srv.URL,
+		viewKey:    kp.ViewKey(),
+	}
+}
+
+const testAddressTxs = `{
+  "scanned_block_height": 1000,
+  "blockchain_height": 1000,
+  "transactions": [
+    {"hash": "aa", "timestamp": "2023-05-01T10:00:00Z", "total_received": "5000", "height": 900},
+    {"hash": "bb", "timestamp": "2023-05-02T10:00:00Z", "total_received": "0", "total_sent": "5000", "height": 950},
+    {"hash": "cc", "timestamp": "2023-05-03T10:00:00Z", "total_received": "300", "height": 995},
+    {"hash": "dd", "timestamp": "2023-05-03T11:00:00Z", "total_received": "700", "height": 998},
+    {"hash": "ee", "timestamp": "2023-05-03T12:00:00Z", "total_received": "40", "mempool": true}
+  ]
+}`
+
+func TestLightWalletClient_GetBalance(t *testing.T) {
+	c := newTestLightWalletClient(t, map[string]string{"get_address_txs": testAddressTxs})
+
+	balance, err := c.GetBalance(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6000), balance.Balance)
+	require.Equal(t, uint64(5000), balance.UnlockedBalance)
+	require.Equal(t, uint64(8), balance.BlocksToUnlock)
+
+	locked, err := c.GetLockedOutputs()
+	require.NoError(t, err)
+	require.Len(t, locked, 2)
+	require.Equal(t, &LockedOutput{TxID: "cc", Amount: 300, Height: 995, BlocksToUnlock: 5}, locked[0])
+	require.Equal(t, &LockedOutput{TxID: "dd", Amount: 700, Height: 998, BlocksToUnlock: 8}, locked[1])
+}
+
+func TestLightWalletClient_GetIncomingTransfers(t *testing.T) {
+	c := newTestLightWalletClient(t, map[string]string{"get_address_txs": testAddressTxs})
+
+	transfers, err := c.GetIncomingTransfers(0)
+	require.NoError(t, err)
+	require.Len(t, transfers, 4)
+	require.Equal(t, "aa", transfers[0].TxID)
+	require.Equal(t, uint64(100), transfers[0].Confirmations)
+	require.Equal(t, uint64(1682935200), transfers[0].Timestamp)
+	require.Equal(t, "ee", transfers[3].TxID)
+	require.Equal(t, "pool", transfers[3].Type)
+	require.Zero(t, transfers[3].Height)
+
+	// the transfers in the pool are always included
+	transfers, err = c.GetIncomingTransfers(996)
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Equal(t, "dd", transfers[0].TxID)
+	require.Equal(t, "ee", transfers[1].TxID)
+}
+
+func TestLightWalletClient_login(t *testing.T) {
+	c := newTestLightWalletClient(t, map[string]string{
+		"login":            `{"new_address": true, "start_height": 990}`,
+		"get_address_info": `{"scanned_block_height": 995, "start_height": 990, "blockchain_height": 1000}`,
+	})
+
+	require.NoError(t, c.login(&ViewOnlyWalletConf{RestoreHeight: 500}))
+
+	walletHeight, chainHeight, err := c.GetSyncHeights()
+	require.NoError(t, err)
+	require.Equal(t, uint64(995), walletHeight)
+	require.Equal(t, uint64(1000), chainHeight)
+
+	// a request of another wallet is not approved
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	c.viewKey = kp.ViewKey()
+	_, err = c.GetHeight()
+	require.ErrorContains(t, err, "is not approved by the server's operator yet")
+}
+
+func TestLightWalletClient_viewOnly(t *testing.T) {
+	c := newTestLightWalletClient(t, nil)
+	require.True(t, c.IsViewOnly())
+
+	_, err := c.Withdraw(nil, nil, 0)
+	require.ErrorIs(t, err, ErrViewOnlyWallet)
+
+	_, err = c.CreateSubaddress("swap")
+	require.ErrorIs(t, err, ErrLightWalletUnsupported)
+
+	_, err = c.GetBalance(1)
+	require.ErrorIs(t, err, ErrLightWalletUnsupported)
+}
+
+func Test_lwsTransaction_blocksToUnlock(t *testing.T) {
+	tx := &lwsTransaction{Height: 100}
+	require.Equal(t, uint64(1), tx.blocksToUnlock(109))
+	require.Zero(t, tx.blocksToUnlock(110))
+
+	tx.Coinbase = true
+	require.Equal(t, uint64(50), tx.blocksToUnlock(110))
+
+	tx = &lwsTransaction{Height: 100, UnlockTime: 200}
+	require.Equal(t, uint64(90), tx.blocksToUnlock(110))
+
+	// unlock times that are timestamps are ignored
+	tx.UnlockTime = maxUnlockHeight + 1
+	require.Zero(t, tx.blocksToUnlock(110))
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"sort"
+)
+
+// LockedOutput is an unspent output of the primary account that can't be spent yet.
+type LockedOutput struct {
+	TxID           string
+	Amount         uint64 // piconero
+	Height         uint64
+	BlocksToUnlock uint64
+}
+
+type incomingTransfersRequest struct {
+	TransferType string `json:"transfer_type"`
+	AccountIndex uint64 `json:"account_index"`
+}
+
+type incomingTransfersResponse struct {
+	Transfers []struct {
+		TxHash      string `json:"tx_hash"`
+		Amount      uint64 `json:"amount"`
+		BlockHeight uint64 `json:"block_height"`
+		Unlocked    bool   `json:"unlocked"`
+	} `json:"transfers"`
+}
+
+// GetLockedOutputs returns the unspent outputs of the primary account that are still
+// locked, like the XMR of a recent claim or the change of a recent transfer, from the
+// first to unlock. Their amounts are the difference between the balance and the
+// unlocked balance.
+func (c *walletClient) GetLockedOutputs() ([]*LockedOutput, error) {
+	if err := c.refresh(); err != nil {
+		return nil, err
+	}
+
+	height, err := c.GetHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(incomingTransfersResponse)
+	err = c.callWalletRPC("incoming_transfers", &incomingTransfersRequest{
+		TransferType: "available",
+		AccountIndex: 0,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked []*LockedOutput
+	for _, t := range res.Transfers {
+		if t.Unlocked {
+			continue
+		}
+		locked = append(locked, &LockedOutput{
+			TxID:           t.TxHash,
+			Amount:         t.Amount,
+			Height:         t.BlockHeight,
+			BlocksToUnlock: blocksToUnlock(t.BlockHeight, height),
+		})
+	}
+
+	sortLockedOutputs(locked)
+	return locked, nil
+}
+
+// sortLockedOutputs sorts the locked outputs from the first to unlock.
+func sortLockedOutputs(locked []*LockedOutput) {
+	sort.SliceStable(locked, func(i, j int) bool {
+		return locked[i].BlocksToUnlock < locked[j].BlocksToUnlock
+	})
+}
+
+// blocksToUnlock returns the number of blocks until a locked output mined at the
+// output height can be spent, when the wallet is at the wallet height. Outputs unlock
+// after MinSpendConfirmations blocks, unless they are coinbase outputs or have an
+// unlock time, so it is at least one for those.
+func blocksToUnlock(outputHeight uint64, walletHeight uint64) uint64 {
+	unlockHeight := outputHeight + MinSpendConfirmations
+	if unlockHeight <= walletHeight {
+		return 1
+	}
+	return unlockHeight - walletHeight
+}
@@ -0,0 +1,19 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_blocksToUnlock(t *testing.T) {
+	// mined in the last block of the wallet's height
+	require.Equal(t, uint64(MinSpendConfirmations-1), blocksToUnlock(99, 100))
+	require.Equal(t, uint64(1), blocksToUnlock(91, 100))
+
+	// locked for longer than usual
+	require.Equal(t, uint64(1), blocksToUnlock(50, 100))
+}
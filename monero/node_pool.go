@@ -0,0 +1,370 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MarinX/monerorpc"
+	monerodaemon "github.com/MarinX/monerorpc/daemon"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+const (
+	nodeCheckInterval = 30 * time.Second
+	nodeCheckTimeout  = 10 * time.Second
+
+	// maxNodeLag is the number of blocks that the current node can trail the highest
+	// healthy node before the wallets are switched away from it
+	maxNodeLag = 10
+
+	// minLatencyGain is how much lower than the current node's latency the latency of
+	// another node has to be, besides being under half of it, for the wallets to switch
+	// to the faster node
+	minLatencyGain = 200 * time.Millisecond
+)
+
+// NodeHealth is the result of the last health check of a monerod node.
+type NodeHealth struct {
+	Node    *common.MoneroNode
+	Rank    int           // 1 for the best node by height freshness and latency
+	Current bool          // whether the wallets are using the node
+	Height  uint64        // blockchain height of the node
+	Latency time.Duration // duration of the health check request
+	Version string        // monerod version of the node
+	Err     error         // nil if the node is healthy
+	Checked time.Time     // when the node was checked, zero if it hasn't been checked yet
+}
+
+// nodePool health checks the configured monerod nodes while wallets are using them,
+// and switches the wallets to another node if the current node stops responding,
+// falls behind the other nodes, or is much slower than another node.
+type nodePool struct {
+	env   common.Environment
+	proxy *common.Proxy
+	nodes []*common.MoneroNode // in order of preference
+
+	mu      sync.Mutex
+	current *common.MoneroNode // nil until selectInitialNode is called
+	health  map[*common.MoneroNode]*NodeHealth
+	wallets map[*walletClient]struct{}
+	cancel  context.CancelFunc // stops the health checks, nil if they are not running
+}
+
+func newNodePool(
+	env common.Environment,
+	proxy *common.Proxy,
+	nodes []*common.MoneroNode,
+	current *common.MoneroNode,
+) *nodePool {
+	return &nodePool{
+		env:     env,
+		proxy:   proxy,
+		nodes:   nodes,
+		current: current,
+		health:  make(map[*common.MoneroNode]*NodeHealth),
+		wallets: make(map[*walletClient]struct{}),
+	}
+}
+
+// currentNode returns the node that the wallets are using.
+func (p *nodePool) currentNode() *common.MoneroNode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current
+}
+
+// register adds a wallet that is switched when the current node changes, starting the
+// health checks if it is the first wallet.
+func (p *nodePool) register(c *walletClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.wallets[c] = struct{}{}
+	if p.cancel == nil {
+		var ctx context.Context
+		ctx, p.cancel = context.WithCancel(context.Background())
+		go p.run(ctx)
+	}
+}
+
+// unregister removes a closed wallet, stopping the health checks if it was the last
+// wallet.
+func (p *nodePool) unregister(c *walletClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.wallets, c)
+	if len(p.wallets) == 0 && p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
+// nodeHealth returns the health of the nodes, ranked by height freshness and latency.
+func (p *nodePool) nodeHealth() []*NodeHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ranked := rankNodes(p.nodes, p.health)
+	health := make([]*NodeHealth, 0, len(ranked))
+	for i, node := range ranked {
+		h := &NodeHealth{Node: node}
+		if checked, ok := p.health[node]; ok {
+			*h = *checked
+		}
+		h.Rank = i + 1
+		h.Current = node == p.current
+		health = append(health, h)
+	}
+	return health
+}
+
+// selectInitialNode checks all nodes and selects the best of them by height freshness
+// and latency for the wallets to start with.
+func (p *nodePool) selectInitialNode() (*common.MoneroNode, error) {
+	if len(p.nodes) == 0 {
+		return nil, errors.New("no monero nodes")
+	}
+
+	health := p.checkAll()
+	for _, node := range p.nodes {
+		if err := health[node].Err; err != nil {
+			log.Warnf("Non-working node: %s", err)
+		}
+	}
+
+	best := rankNodes(p.nodes, health)[0]
+	if err := health[best].Err; err != nil {
+		return nil, fmt.Errorf("failed to validate any monerod RPC node, last error: %w", err)
+	}
+
+	log.Infof("Using monerod node %s:%d at height %d, latency %s",
+		best.Host, best.Port, health[best].Height, health[best].Latency.Round(time.Millisecond))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.health = health
+	p.current = best
+	return best, nil
+}
+
+func (p *nodePool) run(ctx context.Context) {
+	for {
+		p.checkNodes()
+
+		select {
+		case <-time.After(nodeCheckInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkAll checks the health of all nodes concurrently.
+func (p *nodePool) checkAll() map[*common.MoneroNode]*NodeHealth {
+	results := make([]*NodeHealth, len(p.nodes))
+	var wg sync.WaitGroup
+	for i, node := range p.nodes {
+		wg.Add(1)
+		go func(i int, node *common.MoneroNode) {
+			defer wg.Done()
+			results[i] = checkNode(p.env, node, p.proxy)
+		}(i, node)
+	}
+	wg.Wait()
+
+	health := make(map[*common.MoneroNode]*NodeHealth, len(p.nodes))
+	for i, node := range p.nodes {
+		health[node] = results[i]
+	}
+	return health
+}
+
+// checkNodes checks the health of all nodes, and switches the wallets to another node
+// if there is a better node than the current node.
+func (p *nodePool) checkNodes() {
+	health := p.checkAll()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.health = health
+	next := selectNode(p.nodes, health, p.current)
+	if next == p.current {
+		return
+	}
+
+	log.Warnf("Switching from monerod node %s:%d (%v) to %s:%d",
+		p.current.Host, p.current.Port, nodeStatus(health[p.current]), next.Host, next.Port)
+	p.current = next
+	for c := range p.wallets {
+		if err := c.setDaemon(next); err != nil {
+			log.Errorf("Failed to switch monero wallet to monerod node %s:%d: %s", next.Host, next.Port, err)
+		}
+	}
+}
+
+// nodeStatus describes why a node is unhealthy, or its height if it is healthy.
+func nodeStatus(h *NodeHealth) string {
+	if h.Err != nil {
+		return h.Err.Error()
+	}
+	return fmt.Sprintf("at height %d", h.Height)
+}
+
+// nodeClass is the category of a node in the ranking, from the best to the worst.
+type nodeClass int
+
+const (
+	nodeFresh   nodeClass = iota // healthy and no more than maxNodeLag blocks behind
+	nodeLagging                  // healthy but behind
+	nodeFailed                   // unhealthy or not checked yet
+)
+
+// classifyNodes returns the class of each node.
+func classifyNodes(health map[*common.MoneroNode]*NodeHealth) func(node *common.MoneroNode) nodeClass {
+	var maxHeight uint64
+	for _, h := range health {
+		if h.Err == nil && h.Height > maxHeight {
+			maxHeight = h.Height
+		}
+	}
+
+	return func(node *common.MoneroNode) nodeClass {
+		h, ok := health[node]
+		switch {
+		case !ok || h.Err != nil:
+			return nodeFailed
+		case h.Height+maxNodeLag < maxHeight:
+			return nodeLagging
+		default:
+			return nodeFresh
+		}
+	}
+}
+
+// rankNodes returns the nodes from the best to the worst. The fresh nodes come first,
+// from the lowest latency, then the lagging nodes, from the highest height, and then
+// the unhealthy nodes. Ties keep the order of preference.
+func rankNodes(nodes []*common.MoneroNode, health map[*common.MoneroNode]*NodeHealth) []*common.MoneroNode {
+	class := classifyNodes(health)
+	ranked := append([]*common.MoneroNode{}, nodes...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		ci, cj := class(ranked[i]), class(ranked[j])
+		if ci != cj {
+			return ci < cj
+		}
+
+		switch ci {
+		case nodeFresh:
+			return health[ranked[i]].Latency < health[ranked[j]].Latency
+		case nodeLagging:
+			return health[ranked[i]].Height > health[ranked[j]].Height
+		default:
+			return false
+		}
+	})
+	return ranked
+}
+
+// selectNode returns the node that the wallets should use. The current node is kept
+// while it is fresh, unless the best node's latency is under half of the current
+// node's and lower by at least minLatencyGain, so that the wallets don't switch back
+// and forth between similar nodes. Otherwise it is the best node, if it is healthy.
+func selectNode(
+	nodes []*common.MoneroNode,
+	health map[*common.MoneroNode]*NodeHealth,
+	current *common.MoneroNode,
+) *common.MoneroNode {
+	class := classifyNodes(health)
+	best := rankNodes(nodes, health)[0]
+	if class(best) != nodeFresh {
+		// no node is healthy, so we stay on the current node until one recovers
+		return current
+	}
+
+	if class(current) != nodeFresh {
+		return best
+	}
+
+	currentLatency, bestLatency := health[current].Latency, health[best].Latency
+	if currentLatency > 2*bestLatency && currentLatency-bestLatency >= minLatencyGain {
+		return best
+	}
+
+	return current
+}
+
+// checkNode checks that the monerod node responds, and that it is a synchronised node
+// of the environment's network.
+func checkNode(env common.Environment, node *common.MoneroNode, proxy *common.Proxy) *NodeHealth {
+	endpoint := fmt.Sprintf("http://%s:%d/json_rpc", node.Host, node.Port)
+	httpClient := &http.Client{Transport: proxy.HTTPTransport(), Timeout: nodeCheckTimeout}
+	daemonCli := monerorpc.New(endpoint, httpClient).Daemon
+
+	h := &NodeHealth{Node: node, Checked: time.Now()}
+	info, err := daemonCli.GetInfo()
+	h.Latency = time.Since(h.Checked)
+	if err != nil {
+		h.Err = fmt.Errorf("could not validate monerod endpoint %s: %w", endpoint, err)
+		return h
+	}
+
+	h.Height = info.Height
+	h.Version = info.Version
+	h.Err = validateNodeInfo(env, endpoint, info)
+	return h
+}
+
+// newDaemonClient returns the client of the monerod node's RPC API.
+func newDaemonClient(node *common.MoneroNode, proxy *common.Proxy) monerodaemon.Daemon {
+	endpoint := fmt.Sprintf("http://%s:%d/json_rpc", node.Host, node.Port)
+	httpClient := metrics.NewHTTPClient(metrics.EndpointMoneroDaemon, proxy.HTTPTransport())
+	return monerorpc.New(endpoint, httpClient).Daemon
+}
+
+// setDaemon switches the wallet to the monerod node. The monero-wallet-rpc of an
+// external wallet is managed by its operator, so only our own monerod requests are
+// switched for it.
+func (c *walletClient) setDaemon(node *common.MoneroNode) error {
+	if c.rpcProcess != nil {
+		err := c.callWalletRPC("set_daemon", map[string]any{
+			"address": fmt.Sprintf("http://%s:%d", node.Host, node.Port),
+			"trusted": false,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.daemonMu.Lock()
+	defer c.daemonMu.Unlock()
+	c.dRPC = newDaemonClient(node, c.conf.Proxy)
+	return nil
+}
+
+// daemon returns the client of the monerod node that the wallet is using.
+func (c *walletClient) daemon() monerodaemon.Daemon {
+	c.daemonMu.RLock()
+	defer c.daemonMu.RUnlock()
+	return c.dRPC
+}
+
+// MoneroNodes returns the health of the configured monerod nodes, as of their last
+// check, in order of preference.
+func (c *walletClient) MoneroNodes() []*NodeHealth {
+	if c.conf == nil || c.conf.nodePool == nil {
+		return nil
+	}
+	return c.conf.nodePool.nodeHealth()
+}
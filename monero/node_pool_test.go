@@ -0,0 +1,105 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func Test_selectNode(t *testing.T) {
+	nodeA := &common.MoneroNode{Host: "a", Port: 18081}
+	nodeB := &common.MoneroNode{Host: "b", Port: 18081}
+	nodeC := &common.MoneroNode{Host: "c", Port: 18081}
+	nodes := []*common.MoneroNode{nodeA, nodeB, nodeC}
+
+	health := map[*common.MoneroNode]*NodeHealth{
+		nodeA: {Node: nodeA, Height: 1000},
+		nodeB: {Node: nodeB, Height: 1000},
+		nodeC: {Node: nodeC, Height: 1000},
+	}
+
+	// a healthy current node is kept, even if it isn't the preferred node
+	require.Equal(t, nodeB, selectNode(nodes, health, nodeB))
+
+	// a current node that falls behind is switched to the first node that isn't
+	health[nodeA].Height = 900
+	health[nodeB].Height = 1000 - maxNodeLag - 1
+	require.Equal(t, nodeC, selectNode(nodes, health, nodeB))
+
+	// a little behind is fine
+	health[nodeB].Height = 1000 - maxNodeLag
+	require.Equal(t, nodeB, selectNode(nodes, health, nodeB))
+
+	// an unresponsive current node is switched to the first healthy node
+	health[nodeA].Height = 1000
+	health[nodeB].Err = errors.New("connection refused")
+	require.Equal(t, nodeA, selectNode(nodes, health, nodeB))
+
+	// a much faster node is switched to, but not a slightly faster one
+	health[nodeB].Err = nil
+	health[nodeA].Latency = 400 * time.Millisecond
+	health[nodeB].Latency = 150 * time.Millisecond
+	health[nodeC].Latency = 100 * time.Millisecond
+	require.Equal(t, nodeB, selectNode(nodes, health, nodeB))
+	require.Equal(t, nodeC, selectNode(nodes, health, nodeA))
+
+	// the current node is kept if no node is healthy
+	for _, h := range health {
+		h.Err = errors.New("connection refused")
+	}
+	require.Equal(t, nodeB, selectNode(nodes, health, nodeB))
+}
+
+func Test_rankNodes(t *testing.T) {
+	nodeA := &common.MoneroNode{Host: "a", Port: 18081}
+	nodeB := &common.MoneroNode{Host: "b", Port: 18081}
+	nodeC := &common.MoneroNode{Host: "c", Port: 18081}
+	nodeD := &common.MoneroNode{Host: "d", Port: 18081}
+	nodeE := &common.MoneroNode{Host: "e", Port: 18081}
+	nodes := []*common.MoneroNode{nodeA, nodeB, nodeC, nodeD, nodeE}
+
+	health := map[*common.MoneroNode]*NodeHealth{
+		nodeA: {Node: nodeA, Err: errors.New("connection refused")},
+		nodeB: {Node: nodeB, Height: 900, Latency: 10 * time.Millisecond},
+		nodeC: {Node: nodeC, Height: 1000, Latency: 300 * time.Millisecond},
+		nodeD: {Node: nodeD, Height: 999, Latency: 50 * time.Millisecond},
+		nodeE: {Node: nodeE, Height: 950, Latency: 20 * time.Millisecond},
+	}
+
+	// the fresh nodes by latency, then the lagging nodes by height, then the rest
+	ranked := rankNodes(nodes, health)
+	require.Equal(t, []*common.MoneroNode{nodeD, nodeC, nodeE, nodeB, nodeA}, ranked)
+
+	// unchecked nodes keep the order of preference
+	ranked = rankNodes(nodes, nil)
+	require.Equal(t, nodes, ranked)
+}
+
+func Test_nodePool_checkNodes(t *testing.T) {
+	deadNode := &common.MoneroNode{Host: "127.0.0.1", Port: 1}
+	liveNode := common.ConfigDefaultsForEnv(common.Development).MoneroNodes[0]
+	pool := newNodePool(common.Development, nil, []*common.MoneroNode{deadNode, liveNode}, deadNode)
+
+	pool.checkNodes()
+	require.Equal(t, liveNode, pool.currentNode())
+
+	// the live node is ranked first
+	health := pool.nodeHealth()
+	require.Len(t, health, 2)
+	require.Equal(t, liveNode, health[0].Node)
+	require.Equal(t, 1, health[0].Rank)
+	require.NoError(t, health[0].Err)
+	require.True(t, health[0].Current)
+	require.Greater(t, health[0].Height, uint64(0))
+	require.Equal(t, deadNode, health[1].Node)
+	require.Equal(t, 2, health[1].Rank)
+	require.Error(t, health[1].Err)
+	require.False(t, health[1].Current)
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"fmt"
+
+	"github.com/MarinX/monerorpc/wallet"
+)
+
+// PriorityHighest is the highest fee priority of monero-wallet-rpc, which monero calls
+// "priority".
+const PriorityHighest = wallet.PriorityElevated + 1
+
+// PriorityNames are the names of the fee priorities of monero transactions, from the
+// lowest fee to the highest.
+var PriorityNames = []string{"unimportant", "normal", "elevated", "priority"}
+
+var priorities = map[string]wallet.Priority{
+	"unimportant": wallet.PriorityUnimportant,
+	"normal":      wallet.PriorityNormal,
+	"elevated":    wallet.PriorityElevated,
+	"priority":    PriorityHighest,
+}
+
+// ParsePriority returns the fee priority with the name. An empty name is the default
+// priority of the wallet, which is normal unless the wallet lowers it when the
+// blockchain isn't congested.
+func ParsePriority(name string) (wallet.Priority, error) {
+	if name == "" || name == "default" {
+		return wallet.PriorityDefault, nil
+	}
+
+	priority, ok := priorities[name]
+	if !ok {
+		return 0, fmt.Errorf("invalid monero fee priority %q, expected one of %v", name, PriorityNames)
+	}
+	return priority, nil
+}
+
+// PriorityName returns the name of the fee priority.
+func PriorityName(priority wallet.Priority) string {
+	if priority == wallet.PriorityDefault {
+		return "default"
+	}
+
+	for name, p := range priorities {
+		if p == priority {
+			return name
+		}
+	}
+	return fmt.Sprintf("unknown(%d)", priority)
+}
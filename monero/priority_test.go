@@ -0,0 +1,31 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"testing"
+
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriority(t *testing.T) {
+	for _, name := range PriorityNames {
+		priority, err := ParsePriority(name)
+		require.NoError(t, err)
+		require.Equal(t, name, PriorityName(priority))
+	}
+
+	priority, err := ParsePriority("")
+	require.NoError(t, err)
+	require.Equal(t, wallet.PriorityDefault, priority)
+	require.Equal(t, "default", PriorityName(priority))
+
+	priority, err = ParsePriority("priority")
+	require.NoError(t, err)
+	require.Equal(t, wallet.Priority(4), priority)
+
+	_, err = ParsePriority("high")
+	require.ErrorContains(t, err, `invalid monero fee priority "high"`)
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+// seedWords is the number of words of a monero mnemonic seed
+const seedWords = 25
+
+var errRestoreExternalWallet = errors.New(
+	"the wallet of an external monero-wallet-rpc must be restored by its operator",
+)
+
+// walletFileSuffixes are the suffixes of the files of a wallet, after its file path
+var walletFileSuffixes = []string{"", ".keys", ".address.txt"}
+
+type restoreDeterministicWalletRequest struct {
+	Filename        string `json:"filename"`
+	Password        string `json:"password"`
+	Seed            string `json:"seed"`
+	RestoreHeight   uint64 `json:"restore_height"`
+	Language        string `json:"language"`
+	AutosaveCurrent bool   `json:"autosave_current"`
+}
+
+type restoreDeterministicWalletResponse struct {
+	Address string `json:"address"`
+	Info    string `json:"info"`
+}
+
+// RestoreFromSeed recreates the primary wallet from its 25 word mnemonic seed,
+// scanning the blockchain from the restore height, and returns its primary address.
+// The files of the replaced wallet are kept with a ".<unix time>.bak" suffix. The
+// restored wallet scans in the background after this returns.
+func (c *walletClient) RestoreFromSeed(seed string, restoreHeight uint64) (*mcrypto.Address, error) {
+	if c.conf.ExternalWallet != nil {
+		return nil, errRestoreExternalWallet
+	}
+	if c.IsViewOnly() {
+		return nil, ErrViewOnlyWallet
+	}
+
+	words := strings.Fields(seed)
+	if len(words) != seedWords {
+		return nil, fmt.Errorf("monero seed has %d words, expected %d", len(words), seedWords)
+	}
+
+	if err := c.callWalletRPC("close_wallet", struct{}{}, nil); err != nil {
+		return nil, fmt.Errorf("failed to close the wallet: %w", err)
+	}
+
+	backupSuffix := fmt.Sprintf(".%d.bak", time.Now().Unix())
+	if err := c.renameWalletFiles("", backupSuffix); err != nil {
+		c.reopenWallet(backupSuffix)
+		return nil, fmt.Errorf("failed to back up the wallet files: %w", err)
+	}
+
+	res := new(restoreDeterministicWalletResponse)
+	err := c.callWalletRPC("restore_deterministic_wallet", &restoreDeterministicWalletRequest{
+		Filename:      c.WalletName(),
+		Password:      c.conf.WalletPassword,
+		Seed:          strings.Join(words, " "),
+		RestoreHeight: restoreHeight,
+		Language:      "English",
+	}, res)
+	if err != nil {
+		c.reopenWallet(backupSuffix)
+		return nil, fmt.Errorf("failed to restore the wallet: %w", err)
+	}
+
+	address, err := mcrypto.NewAddress(res.Address, c.conf.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	c.addrMu.Lock()
+	c.walletAddr = address
+	c.addrMu.Unlock()
+
+	log.Infof("Restored monero wallet %s with address %s, replaced wallet files have the suffix %s",
+		c.conf.WalletFilePath, address, backupSuffix)
+	return address, nil
+}
+
+// renameWalletFiles renames the files of the wallet from one suffix to another,
+// ignoring the files that don't exist.
+func (c *walletClient) renameWalletFiles(fromSuffix string, toSuffix string) error {
+	for _, suffix := range walletFileSuffixes {
+		from := c.conf.WalletFilePath + suffix + fromSuffix
+		to := c.conf.WalletFilePath + suffix + toSuffix
+		if err := os.Rename(from, to); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// reopenWallet moves the backed up files of the wallet back after a failed restore
+// and opens the wallet again, so that swapd keeps working with it.
+func (c *walletClient) reopenWallet(backupSuffix string) {
+	// monero-wallet-rpc may have partially created the files of the restored wallet
+	for _, suffix := range walletFileSuffixes {
+		_ = os.Remove(c.conf.WalletFilePath + suffix)
+	}
+
+	if err := c.renameWalletFiles(backupSuffix, ""); err != nil {
+		log.Errorf("Failed to move the backed up wallet files with suffix %s back: %s", backupSuffix, err)
+		return
+	}
+
+	err := c.callWalletRPC("open_wallet", map[string]string{
+		"filename": c.WalletName(),
+		"password": c.conf.WalletPassword,
+	}, nil)
+	if err != nil {
+		log.Errorf("Failed to reopen the monero wallet: %s", err)
+	}
+}
+
+// callWalletRPC calls a monero-wallet-rpc method that the monerorpc library doesn't
+// have, unmarshalling the result into `result` if it isn't nil.
+func (c *walletClient) callWalletRPC(method string, params any, result any) error {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	reqJSON, err := json.Marshal(&rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  method,
+		Params:  paramsJSON,
+		ID:      0,
+	})
+	if err != nil {
+		return err
+	}
+
+	httpClient := metrics.NewHTTPClient(metrics.EndpointMoneroWallet, nil)
+	httpResp, err := httpClient.Post(c.endpoint, "application/json", bytes.NewReader(reqJSON))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	resp := new(rpctypes.Response)
+	if err = json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return fmt.Errorf("failed to decode the %s response: %w", method, err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s: %s", method, resp.Error.Message)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
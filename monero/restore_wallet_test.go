@@ -0,0 +1,54 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestClient_RestoreFromSeed(t *testing.T) {
+	walletPath := path.Join(t.TempDir(), "wallet", "test-wallet")
+	c, err := NewWalletClient(&WalletClientConf{
+		Env:                 common.Development,
+		WalletFilePath:      walletPath,
+		MoneroWalletRPCPath: moneroWalletRPCPath,
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	// restore the seed of another wallet, so that the address changes
+	other := CreateWalletClient(t)
+	var key struct {
+		Key string `json:"key"`
+	}
+	err = other.(*walletClient).callWalletRPC("query_key", map[string]string{"key_type": "mnemonic"}, &key)
+	require.NoError(t, err)
+
+	address, err := c.RestoreFromSeed(key.Key, 0)
+	require.NoError(t, err)
+	require.Equal(t, other.PrimaryAddress(), address)
+	require.Equal(t, other.PrimaryAddress(), c.PrimaryAddress())
+
+	backups, err := filepath.Glob(walletPath + ".[0-9]*.bak")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	keysBackups, err := filepath.Glob(walletPath + ".keys.*.bak")
+	require.NoError(t, err)
+	require.Len(t, keysBackups, 1)
+}
+
+func TestClient_RestoreFromSeed_invalidSeed(t *testing.T) {
+	c := CreateWalletClient(t)
+	address := c.PrimaryAddress()
+
+	_, err := c.RestoreFromSeed("not a seed", 0)
+	require.ErrorContains(t, err, "monero seed has 3 words, expected 25")
+	require.Equal(t, address, c.PrimaryAddress())
+}
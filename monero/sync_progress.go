@@ -0,0 +1,86 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// syncedMargin is the number of blocks that the wallet can trail the daemon and still
+// be considered synced, as the wallet only refreshes periodically.
+const syncedMargin = 2
+
+// SyncProgress is the progress of the wallet catching up with the blockchain height
+// of the monero daemon.
+type SyncProgress struct {
+	WalletHeight uint64
+	DaemonHeight uint64
+	Synced       bool
+	// Percent of the blocks that the wallet had to scan when it fell behind, which it
+	// has scanned since
+	Percent float64
+	// ETA is the estimated time until the wallet is synced, zero if it is synced or
+	// if the scan rate is not known yet
+	ETA time.Duration
+}
+
+// syncSample is a wallet height and when it was seen.
+type syncSample struct {
+	height uint64
+	time   time.Time
+}
+
+// syncTracker estimates the progress of the wallet's scan from the wallet heights that
+// it was polled with since the wallet fell behind.
+type syncTracker struct {
+	mu    sync.Mutex
+	start *syncSample // nil while the wallet is synced
+}
+
+func (t *syncTracker) progress(walletHeight uint64, daemonHeight uint64, now time.Time) *SyncProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := &SyncProgress{
+		WalletHeight: walletHeight,
+		DaemonHeight: daemonHeight,
+		Synced:       walletHeight+syncedMargin >= daemonHeight,
+	}
+
+	if p.Synced {
+		t.start = nil
+		p.Percent = 100
+		return p
+	}
+
+	// the wallet height goes down if the wallet is restored with a lower height
+	if t.start == nil || walletHeight < t.start.height {
+		t.start = &syncSample{height: walletHeight, time: now}
+	}
+
+	scanned := walletHeight - t.start.height
+	remaining := daemonHeight - walletHeight
+	p.Percent = math.Floor(float64(scanned)/float64(scanned+remaining)*1000) / 10
+
+	elapsed := now.Sub(t.start.time)
+	if scanned > 0 && elapsed > 0 {
+		p.ETA = time.Duration(float64(elapsed) * float64(remaining) / float64(scanned)).Round(time.Second)
+	}
+
+	return p
+}
+
+// GetSyncProgress returns the sync heights of the wallet and the daemon, with the
+// progress and the estimated time remaining of the wallet's scan. The estimates are
+// based on the heights of the previous calls since the wallet fell behind.
+func (c *walletClient) GetSyncProgress() (*SyncProgress, error) {
+	walletHeight, daemonHeight, err := c.GetSyncHeights()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.syncTracker.progress(walletHeight, daemonHeight, time.Now()), nil
+}
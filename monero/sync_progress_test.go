@@ -0,0 +1,46 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_syncTracker_progress(t *testing.T) {
+	tracker := new(syncTracker)
+	now := time.Now()
+
+	p := tracker.progress(1000, 1001, now)
+	require.True(t, p.Synced)
+	require.Equal(t, float64(100), p.Percent)
+	require.Zero(t, p.ETA)
+
+	// the rate is not known when the wallet falls behind
+	p = tracker.progress(1000, 2000, now)
+	require.False(t, p.Synced)
+	require.Zero(t, p.Percent)
+	require.Zero(t, p.ETA)
+
+	// 250 blocks in 10 seconds, so the other 750 take 30 seconds
+	p = tracker.progress(1250, 2000, now.Add(10*time.Second))
+	require.Equal(t, 25.0, p.Percent)
+	require.Equal(t, 30*time.Second, p.ETA)
+
+	// new blocks while scanning reduce the percentage
+	p = tracker.progress(1500, 2500, now.Add(20*time.Second))
+	require.Equal(t, 33.3, p.Percent)
+	require.Equal(t, 40*time.Second, p.ETA)
+
+	// a restored wallet starts over
+	p = tracker.progress(100, 2500, now.Add(30*time.Second))
+	require.Zero(t, p.Percent)
+	require.Zero(t, p.ETA)
+
+	p = tracker.progress(2499, 2500, now.Add(40*time.Second))
+	require.True(t, p.Synced)
+	require.Nil(t, tracker.start)
+}
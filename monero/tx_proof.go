@@ -0,0 +1,114 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/MarinX/monerorpc/wallet"
+
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+type getTransactionsRequest struct {
+	TxsHashes    []string `json:"txs_hashes"`
+	DecodeAsJSON bool     `json:"decode_as_json"`
+}
+
+type getTransactionsResponse struct {
+	Status string `json:"status"`
+	Txs    []struct {
+		AsJSON string `json:"as_json"`
+	} `json:"txs"`
+}
+
+// GetTxProof returns the proof that our transaction paid the address, signing the
+// message with it. For standard addresses, the proof is an OutProofV2 that anyone
+// can check with their own daemon, without the address's view key.
+func (c *walletClient) GetTxProof(txID string, to *mcrypto.Address, message string) (string, error) {
+	if c.IsViewOnly() {
+		return "", ErrViewOnlyWallet
+	}
+
+	resp, err := c.wRPC.GetTxProof(&wallet.GetTxProofRequest{
+		TxID:    txID,
+		Address: to.String(),
+		Message: message,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get proof of TXID=%s: %w", txID, err)
+	}
+
+	return resp.Signature, nil
+}
+
+// CheckTxProof checks the proof that the transaction paid the address, returning the
+// amount received by the address and the confirmations of the transaction. The proof
+// must sign the message. Any wallet can check a proof, including a view-only wallet
+// of another address.
+func (c *walletClient) CheckTxProof(
+	txID string,
+	to *mcrypto.Address,
+	message string,
+	proof string,
+) (*wallet.CheckTxProofResponse, error) {
+	resp, err := c.wRPC.CheckTxProof(&wallet.CheckTxProofRequest{
+		TxID:      txID,
+		Address:   to.String(),
+		Message:   message,
+		Signature: proof,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check proof of TXID=%s: %w", txID, err)
+	}
+
+	return resp, nil
+}
+
+// GetTxUnlockTime returns the unlock time of the transaction's outputs, which is zero
+// unless the sender locked them for longer than the default of MinSpendConfirmations.
+// Transaction proofs don't cover the unlock time, so it's looked up in the monerod
+// node that the wallet is using.
+func (c *walletClient) GetTxUnlockTime(txID string) (uint64, error) {
+	node := c.conf.MonerodNodes[0]
+	if c.conf.nodePool != nil {
+		node = c.conf.nodePool.currentNode()
+	}
+
+	reqJSON, err := json.Marshal(&getTransactionsRequest{
+		TxsHashes:    []string{txID},
+		DecodeAsJSON: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	endpoint := fmt.Sprintf("http://%s:%d/get_transactions", node.Host, node.Port)
+	httpClient := metrics.NewHTTPClient(metrics.EndpointMoneroDaemon, c.conf.Proxy.HTTPTransport())
+	httpResp, err := httpClient.Post(endpoint, "application/json", bytes.NewReader(reqJSON))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	resp := new(getTransactionsResponse)
+	if err = json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return 0, fmt.Errorf("failed to decode the get_transactions response: %w", err)
+	}
+	if resp.Status != "OK" || len(resp.Txs) != 1 {
+		return 0, fmt.Errorf("transaction TXID=%s not found, status %q", txID, resp.Status)
+	}
+
+	tx := new(struct {
+		UnlockTime uint64 `json:"unlock_time"`
+	})
+	if err = json.Unmarshal([]byte(resp.Txs[0].AsJSON), tx); err != nil {
+		return 0, fmt.Errorf("failed to decode transaction TXID=%s: %w", txID, err)
+	}
+
+	return tx.UnlockTime, nil
+}
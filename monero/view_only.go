@@ -0,0 +1,78 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MarinX/monerorpc/wallet"
+
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+// ErrViewOnlyWallet is returned when spending from a view-only wallet.
+var ErrViewOnlyWallet = errors.New("the monero wallet is view-only and cannot spend")
+
+// ViewOnlyWalletConf is the configuration of a view-only primary wallet, which is
+// created from the address and private view key of another swapd's wallet. It sees
+// the incoming transfers and the balance of that wallet, but it cannot spend, and it
+// doesn't see the outgoing transfers, so its balance includes spent outputs.
+type ViewOnlyWalletConf struct {
+	Address       *mcrypto.Address        // Required
+	ViewKey       *mcrypto.PrivateViewKey // Required
+	RestoreHeight uint64                  // Optional, height from which a new wallet scans for transfers
+	RestoreDate   time.Time               // Optional, estimates RestoreHeight from the wallet creation date
+}
+
+// generateViewOnlyWallet creates the view-only wallet of the configuration.
+func (c *walletClient) generateViewOnlyWallet(conf *ViewOnlyWalletConf, filename string, password string) error {
+	restoreHeight := conf.RestoreHeight
+	if !conf.RestoreDate.IsZero() {
+		var err error
+		restoreHeight, err = c.restoreHeightFromDate(conf.RestoreDate)
+		if err != nil {
+			return err
+		}
+		log.Infof("Scanning the view-only wallet from block %d, estimated from the date %s",
+			restoreHeight, conf.RestoreDate.Format(time.DateOnly))
+	}
+
+	return c.generateFromKeys(nil, conf.ViewKey, conf.Address, restoreHeight, filename, password)
+}
+
+// checkViewOnlyAddress checks that an existing wallet is the view-only wallet of
+// the configured address.
+func (c *walletClient) checkViewOnlyAddress(conf *ViewOnlyWalletConf) error {
+	if !c.walletAddr.Equal(conf.Address) {
+		return fmt.Errorf("wallet address %s does not match the view-only address %s", c.walletAddr, conf.Address)
+	}
+	return nil
+}
+
+// IsViewOnly returns whether the wallet was configured as a view-only wallet.
+func (c *walletClient) IsViewOnly() bool {
+	return c.conf != nil && c.conf.ViewOnly != nil
+}
+
+// GetIncomingTransfers returns the incoming transfers of the primary account,
+// including the ones in the transaction pool, from the given block height.
+func (c *walletClient) GetIncomingTransfers(minHeight uint64) ([]*wallet.Transfer, error) {
+	if err := c.refresh(); err != nil {
+		return nil, err
+	}
+
+	res, err := c.wRPC.GetTransfers(&wallet.GetTransfersRequest{
+		In:             true,
+		Pool:           true,
+		FilterByHeight: minHeight > 0,
+		MinHeight:      minHeight,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(res.In, res.Pool...), nil
+}
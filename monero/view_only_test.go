@@ -0,0 +1,69 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+func TestNewWalletClient_viewOnly(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	address := kp.PublicKeyPair().Address(common.Development)
+
+	height, err := CreateWalletClient(t).GetHeight()
+	require.NoError(t, err)
+
+	conf := &WalletClientConf{
+		Env:                 common.Development,
+		WalletFilePath:      path.Join(t.TempDir(), "wallet", "view-only-wallet"),
+		MoneroWalletRPCPath: moneroWalletRPCPath,
+		ViewOnly: &ViewOnlyWalletConf{
+			Address:       address,
+			ViewKey:       kp.ViewKey(),
+			RestoreHeight: height,
+		},
+	}
+	viewOnly, err := NewWalletClient(conf)
+	require.NoError(t, err)
+	require.True(t, viewOnly.IsViewOnly())
+	require.Equal(t, address, viewOnly.PrimaryAddress())
+
+	_, err = viewOnly.Withdraw(address, nil, 0)
+	require.ErrorIs(t, err, ErrViewOnlyWallet)
+
+	// the view-only wallet sees the incoming transfers
+	amount := coins.MoneroToPiconero(coins.StrToDecimal("1"))
+	sender := CreateWalletClient(t)
+	MineMinXMRBalance(t, sender, coins.MoneroToPiconero(coins.StrToDecimal("1.01")))
+	transfer, err := sender.Transfer(ctx, address, 0, amount, 1, wallet.PriorityDefault)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		transfers, getErr := viewOnly.GetIncomingTransfers(height)
+		require.NoError(t, getErr)
+		return len(transfers) == 1 && transfers[0].TxID == transfer.TxID
+	}, time.Minute, time.Second)
+
+	// an existing wallet of another address is not used as the view-only wallet
+	viewOnly.Close() // the wallet file can only be opened by one monero-wallet-rpc
+	otherKP, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	conf.ViewOnly.Address = otherKP.PublicKeyPair().Address(common.Development)
+	_, err = NewWalletClient(conf)
+	require.ErrorContains(t, err, "does not match the view-only address")
+}
@@ -16,6 +16,7 @@ import (
 	"os/exec"
 	"path"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/metrics"
 )
 
 const (
@@ -38,6 +40,11 @@ const (
 	// SweepToSelfConfirmations is the number of confirmations that we wait for when
 	// sweeping funds from an A+B wallet to our primary wallet.
 	SweepToSelfConfirmations = 2
+
+	// typicalTxWeight is the approximate weight in bytes of a transaction with two
+	// inputs and two outputs, which is larger than the lock transfer of a swap or
+	// the sweep of a swap wallet in most cases.
+	typicalTxWeight = 2000
 )
 
 // WalletClient represents a monero-wallet-rpc client.
@@ -45,6 +52,8 @@ type WalletClient interface {
 	GetAccounts() (*wallet.GetAccountsResponse, error)
 	GetAddress(idx uint64) (*wallet.GetAddressResponse, error)
 	PrimaryAddress() *mcrypto.Address
+	CreateSubaddress(label string) (*mcrypto.Address, error)
+	RestoreFromSeed(seed string, restoreHeight uint64) (*mcrypto.Address, error)
 	GetBalance(idx uint64) (*wallet.GetBalanceResponse, error)
 	Transfer(
 		ctx context.Context,
@@ -52,16 +61,31 @@ type WalletClient interface {
 		accountIdx uint64,
 		amount *coins.PiconeroAmount,
 		numConfirmations uint64,
+		priority wallet.Priority,
 	) (*wallet.Transfer, error)
 	SweepAll(
 		ctx context.Context,
 		to *mcrypto.Address,
 		accountIdx uint64,
 		numConfirmations uint64,
+		priority wallet.Priority,
 	) ([]*wallet.Transfer, error)
+	GetTxProof(txID string, to *mcrypto.Address, message string) (string, error)
+	CheckTxProof(txID string, to *mcrypto.Address, message string, proof string) (*wallet.CheckTxProofResponse, error)
+	GetTxUnlockTime(txID string) (uint64, error)
 	CreateWalletConf(walletNamePrefix string) *WalletClientConf
 	WalletName() string
 	GetHeight() (uint64, error)
+	GetSyncHeights() (walletHeight uint64, chainHeight uint64, err error)
+	GetSyncProgress() (*SyncProgress, error)
+	MoneroNodes() []*NodeHealth
+	EstimateTransferFee() (*coins.PiconeroAmount, error)
+	Withdraw(to *mcrypto.Address, amount *coins.PiconeroAmount, priority wallet.Priority) (*Withdrawal, error)
+	SweepUnlocked(to *mcrypto.Address, belowAmount *coins.PiconeroAmount, priority wallet.Priority) (*Withdrawal, error)
+	GetIncomingTransfers(minHeight uint64) ([]*wallet.Transfer, error)
+	GetLockedOutputs() ([]*LockedOutput, error)
+	PlanConsolidation(amount *coins.PiconeroAmount) (*ConsolidationPlan, error)
+	IsViewOnly() bool // IsViewOnly returns whether the wallet can't spend
 	Endpoint() string // URL on which the wallet is accepting RPC requests
 	Close()           // Close closes the client itself, including any open wallet
 	CloseAndRemoveWallet()
@@ -76,11 +100,18 @@ type WalletClientConf struct {
 	MonerodNodes        []*common.MoneroNode // Optional, defaulted from environment if nil
 	MoneroWalletRPCPath string               // optional, path to monero-rpc-binary
 	LogPath             string               // optional, default is dir(WalletFilePath)/../monero-wallet-rpc.log
+	Proxy               *common.Proxy        // optional, SOCKS5 proxy of the connections to monerod
+	ExternalWallet      *ExternalWalletConf  // optional, monero-wallet-rpc of the wallet instead of launching one
+	ViewOnly            *ViewOnlyWalletConf  // optional, the primary wallet is a view-only wallet of these keys
+	LightWallet         *LightWalletConf     // optional, a light wallet server scans the view-only wallet
+	nodePool            *nodePool            // health checks the configured MonerodNodes, set by Fill
 }
 
 // Fill fills in the optional configuration values (Port, MonerodNodes, MoneroWalletRPCPath,
 // and LogPath) if they are not set.
-// Note: MonerodNodes is set to the first validated node.
+// Note: MonerodNodes is set to the best validated node by height freshness and latency.
+// The other nodes are kept for failover, which the wallet clients of the configuration
+// switch to if the validated node becomes unhealthy or slow.
 func (conf *WalletClientConf) Fill() error {
 	if conf.WalletFilePath == "" {
 		panic("WalletFilePath is a required conf field") // should have been caught before we were invoked
@@ -98,10 +129,12 @@ func (conf *WalletClientConf) Fill() error {
 		conf.MonerodNodes = common.ConfigDefaultsForEnv(conf.Env).MoneroNodes
 	}
 
-	validatedNode, err := findWorkingNode(conf.Env, conf.MonerodNodes)
+	pool := newNodePool(conf.Env, conf.Proxy, conf.MonerodNodes, nil)
+	validatedNode, err := pool.selectInitialNode()
 	if err != nil {
 		return err
 	}
+	conf.nodePool = pool
 	conf.MonerodNodes = []*common.MoneroNode{validatedNode}
 
 	if conf.LogPath == "" {
@@ -109,7 +142,7 @@ func (conf *WalletClientConf) Fill() error {
 		conf.LogPath = path.Join(path.Dir(path.Dir(conf.WalletFilePath)), "monero-wallet-rpc.log")
 	}
 
-	if conf.WalletPort == 0 {
+	if conf.WalletPort == 0 && conf.ExternalWallet == nil && conf.LightWallet == nil {
 		conf.WalletPort, err = common.GetFreeTCPPort()
 		if err != nil {
 			return err
@@ -119,6 +152,14 @@ func (conf *WalletClientConf) Fill() error {
 	return nil
 }
 
+// Withdrawal is a withdrawal from the primary account that was submitted to the
+// network.
+type Withdrawal struct {
+	TxIDs  []string
+	Amount *coins.PiconeroAmount // total amount received by the destination
+	Fee    *coins.PiconeroAmount // total fee of the transactions
+}
+
 // waitForReceiptRequest wraps the input parameters for waitForReceipt
 type waitForReceiptRequest struct {
 	Ctx              context.Context
@@ -128,15 +169,21 @@ type waitForReceiptRequest struct {
 }
 
 type walletClient struct {
-	wRPC       wallet.Wallet       // full monero-wallet-rpc API (larger than the WalletClient interface)
-	dRPC       monerodaemon.Daemon // full monerod RPC API
-	endpoint   string
-	walletAddr *mcrypto.Address
-	conf       *WalletClientConf
-	rpcProcess *os.Process // monero-wallet-rpc process that we create
+	wRPC        wallet.Wallet       // full monero-wallet-rpc API (larger than the WalletClient interface)
+	dRPC        monerodaemon.Daemon // full monerod RPC API, use daemon() as it changes on failover
+	daemonMu    sync.RWMutex        // guards dRPC
+	endpoint    string
+	walletAddr  *mcrypto.Address
+	addrMu      sync.RWMutex // guards walletAddr, which changes when the wallet is restored
+	conf        *WalletClientConf
+	rpcProcess  *os.Process // monero-wallet-rpc process that we create
+	syncTracker syncTracker // progress of the wallet's scan for GetSyncProgress
 }
 
-// NewWalletClient returns a WalletClient for a newly created monero-wallet-rpc process.
+// NewWalletClient returns a WalletClient for a newly created monero-wallet-rpc process,
+// or for the external monero-wallet-rpc instance or the light wallet server of the
+// configuration if it has one. The directory of WalletFilePath is where the temporary
+// swap wallets are created in all cases.
 func NewWalletClient(conf *WalletClientConf) (WalletClient, error) {
 	if path.Dir(conf.WalletFilePath) == "." {
 		return nil, errors.New("wallet file cannot be in the current working directory")
@@ -147,6 +194,14 @@ func NewWalletClient(conf *WalletClientConf) (WalletClient, error) {
 		return nil, err
 	}
 
+	if conf.LightWallet != nil {
+		return newLightWalletClient(conf)
+	}
+
+	if conf.ExternalWallet != nil {
+		return newExternalWalletClient(conf)
+	}
+
 	walletExists, err := common.FileExists(conf.WalletFilePath)
 	if err != nil {
 		return nil, err
@@ -161,17 +216,24 @@ func NewWalletClient(conf *WalletClientConf) (WalletClient, error) {
 		conf.WalletPort,
 		path.Dir(conf.WalletFilePath),
 		conf.LogPath,
-		validatedNode)
+		validatedNode,
+		conf.Proxy,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	c := NewThinWalletClient(validatedNode.Host, validatedNode.Port, conf.WalletPort).(*walletClient)
+	c := newThinWalletClient(validatedNode.Host, validatedNode.Port, conf.WalletPort, conf.Proxy)
 	c.rpcProcess = proc
 
 	walletName := path.Base(conf.WalletFilePath)
 	if isNewWallet {
-		if err = c.CreateWallet(walletName, conf.WalletPassword); err != nil {
+		if conf.ViewOnly != nil {
+			err = c.generateViewOnlyWallet(conf.ViewOnly, walletName, conf.WalletPassword)
+		} else {
+			err = c.CreateWallet(walletName, conf.WalletPassword)
+		}
+		if err != nil {
 			c.Close()
 			return nil, err
 		}
@@ -186,6 +248,12 @@ func NewWalletClient(conf *WalletClientConf) (WalletClient, error) {
 			return nil, err
 		}
 	}
+
+	if err = c.syncWallet(); err != nil {
+		c.Close()
+		return nil, err
+	}
+
 	acctResp, err := c.GetAddress(0)
 	if err != nil {
 		c.Close()
@@ -198,22 +266,38 @@ func NewWalletClient(conf *WalletClientConf) (WalletClient, error) {
 		return nil, err
 	}
 
+	if conf.ViewOnly != nil {
+		if err = c.checkViewOnlyAddress(conf.ViewOnly); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
 	c.conf = conf
+	conf.nodePool.register(c)
 	return c, nil
 }
 
 // NewThinWalletClient returns a WalletClient for an existing monero-wallet-rpc process.
 func NewThinWalletClient(monerodHost string, monerodPort uint, walletPort uint) WalletClient {
-	monerodEndpoint := fmt.Sprintf("http://%s:%d/json_rpc", monerodHost, monerodPort)
+	return newThinWalletClient(monerodHost, monerodPort, walletPort, nil)
+}
+
+// newThinWalletClient returns a client of an existing monero-wallet-rpc process, which
+// connects to monerod through the SOCKS5 proxy if it isn't nil.
+func newThinWalletClient(monerodHost string, monerodPort uint, walletPort uint, proxy *common.Proxy) *walletClient {
 	walletEndpoint := fmt.Sprintf("http://127.0.0.1:%d/json_rpc", walletPort)
 	return &walletClient{
-		dRPC:     monerorpc.New(monerodEndpoint, nil).Daemon,
-		wRPC:     monerorpc.New(walletEndpoint, nil).Wallet,
+		dRPC:     newDaemonClient(&common.MoneroNode{Host: monerodHost, Port: monerodPort}, proxy),
+		wRPC:     monerorpc.New(walletEndpoint, metrics.NewHTTPClient(metrics.EndpointMoneroWallet, nil)).Wallet,
 		endpoint: walletEndpoint,
 	}
 }
 
 func (c *walletClient) WalletName() string {
+	if c.conf.ExternalWallet != nil {
+		return c.conf.ExternalWallet.WalletName
+	}
 	return path.Base(c.conf.WalletFilePath)
 }
 
@@ -279,19 +363,25 @@ func (c *walletClient) Transfer(
 	accountIdx uint64,
 	amount *coins.PiconeroAmount,
 	numConfirmations uint64,
+	priority wallet.Priority,
 ) (*wallet.Transfer, error) {
+	if c.IsViewOnly() {
+		return nil, ErrViewOnlyWallet
+	}
+
 	amt, err := amount.Uint64()
 	if err != nil {
 		return nil, err
 	}
 	amountStr := amount.AsMoneroString()
-	log.Infof("Transferring %s XMR to %s", amountStr, to)
+	log.Infof("Transferring %s XMR to %s with %s priority", amountStr, to, PriorityName(priority))
 	reqResp, err := c.wRPC.Transfer(&wallet.TransferRequest{
 		Destinations: []wallet.Destination{{
 			Amount:  amt,
 			Address: to.String(),
 		}},
 		AccountIndex: accountIdx,
+		Priority:     priority,
 	})
 	if err != nil {
 		log.Warnf("Transfer of %s XMR failed: %s", amountStr, err)
@@ -320,7 +410,12 @@ func (c *walletClient) SweepAll(
 	to *mcrypto.Address,
 	accountIdx uint64,
 	numConfirmations uint64,
+	priority wallet.Priority,
 ) ([]*wallet.Transfer, error) {
+	if c.IsViewOnly() {
+		return nil, ErrViewOnlyWallet
+	}
+
 	addrResp, err := c.GetAddress(accountIdx)
 	if err != nil {
 		return nil, fmt.Errorf("sweep operation failed to get address: %w", err)
@@ -345,6 +440,7 @@ func (c *walletClient) SweepAll(
 	reqResp, err := c.wRPC.SweepAll(&wallet.SweepAllRequest{
 		AccountIndex: accountIdx,
 		Address:      to.String(),
+		Priority:     priority,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("sweep_all from %s failed: %w", from, err)
@@ -374,6 +470,98 @@ func (c *walletClient) SweepAll(
 	return transfers, nil
 }
 
+// Withdraw transfers the amount from the primary account to the address with the fee
+// priority, or sweeps the whole unlocked balance if the amount is nil. Unlike
+// Transfer and SweepAll, it returns once the transactions are submitted, without
+// waiting for confirmations.
+func (c *walletClient) Withdraw(
+	to *mcrypto.Address,
+	amount *coins.PiconeroAmount,
+	priority wallet.Priority,
+) (*Withdrawal, error) {
+	if c.IsViewOnly() {
+		return nil, ErrViewOnlyWallet
+	}
+
+	if amount == nil {
+		return c.SweepUnlocked(to, nil, priority)
+	}
+
+	amt, err := amount.Uint64()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Withdrawing %s XMR to %s", amount.AsMoneroString(), to)
+	reqResp, err := c.wRPC.Transfer(&wallet.TransferRequest{
+		Destinations: []wallet.Destination{{
+			Amount:  amt,
+			Address: to.String(),
+		}},
+		AccountIndex: 0,
+		Priority:     priority,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("withdrawal failed: %w", err)
+	}
+	log.Infof("Withdrawal of %s XMR submitted, TXID=%s", amount.AsMoneroString(), reqResp.TxHash)
+
+	return &Withdrawal{
+		TxIDs:  []string{reqResp.TxHash},
+		Amount: amount,
+		Fee:    coins.NewPiconeroAmount(reqResp.Fee),
+	}, nil
+}
+
+// SweepUnlocked sends the unlocked outputs of the primary account to the address
+// with the fee priority, in as few transactions as possible. If belowAmount is not
+// nil, only the outputs of smaller amounts are swept. Sweeping to our own address
+// consolidates many small outputs into a few larger ones. Like Withdraw, it returns
+// once the transactions are submitted.
+func (c *walletClient) SweepUnlocked(
+	to *mcrypto.Address,
+	belowAmount *coins.PiconeroAmount,
+	priority wallet.Priority,
+) (*Withdrawal, error) {
+	if c.IsViewOnly() {
+		return nil, ErrViewOnlyWallet
+	}
+
+	req := &wallet.SweepAllRequest{
+		AccountIndex: 0,
+		Address:      to.String(),
+		Priority:     priority,
+	}
+
+	if belowAmount != nil {
+		below, err := belowAmount.Uint64()
+		if err != nil {
+			return nil, err
+		}
+		req.BelowAmount = below
+	}
+
+	log.Infof("Sweeping the unlocked balance to %s", to)
+	reqResp, err := c.wRPC.SweepAll(req)
+	if err != nil {
+		return nil, fmt.Errorf("sweep of the unlocked balance failed: %w", err)
+	}
+
+	var amount, fee uint64
+	for i := range reqResp.TxHashList {
+		amount += reqResp.AmountList[i]
+		fee += reqResp.FeeList[i]
+	}
+	log.Infof("Sweep of %s XMR submitted, TX IDs: %s",
+		coins.FmtPiconeroAsXMR(amount), strings.Join(reqResp.TxHashList, ", "))
+
+	return &Withdrawal{
+		TxIDs:  reqResp.TxHashList,
+		Amount: coins.NewPiconeroAmount(amount),
+		Fee:    coins.NewPiconeroAmount(fee),
+	}, nil
+}
+
 func (c *walletClient) CreateWalletConf(walletNamePrefix string) *WalletClientConf {
 	walletName := fmt.Sprintf("%s-%s", walletNamePrefix, time.Now().Format(common.TimeFmtNSecs))
 	walletPath := path.Join(path.Dir(c.conf.WalletFilePath), walletName)
@@ -385,6 +573,11 @@ func (c *walletClient) CreateWalletConf(walletNamePrefix string) *WalletClientCo
 		MonerodNodes:        c.conf.MonerodNodes,
 		MoneroWalletRPCPath: c.conf.MoneroWalletRPCPath,
 		LogPath:             c.conf.LogPath,
+		Proxy:               c.conf.Proxy,
+		nodePool:            c.conf.nodePool,
+	}
+	if c.conf.nodePool != nil {
+		conf.MonerodNodes = []*common.MoneroNode{c.conf.nodePool.currentNode()}
 	}
 	return conf
 }
@@ -413,12 +606,13 @@ func createWalletFromKeys(
 		path.Dir(conf.WalletFilePath),
 		conf.LogPath,
 		monerodNode,
+		conf.Proxy,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	c := NewThinWalletClient(monerodNode.Host, monerodNode.Port, conf.WalletPort).(*walletClient)
+	c := newThinWalletClient(monerodNode.Host, monerodNode.Port, conf.WalletPort, conf.Proxy)
 	c.rpcProcess = proc
 	c.conf = conf
 	err = c.generateFromKeys(
@@ -464,6 +658,9 @@ func createWalletFromKeys(
 		bal.BlocksToUnlock,
 		c.PrimaryAddress(),
 	)
+	if conf.nodePool != nil {
+		conf.nodePool.register(c)
+	}
 	return c, nil
 }
 
@@ -538,6 +735,20 @@ func (c *walletClient) GetAddress(idx uint64) (*wallet.GetAddressResponse, error
 	})
 }
 
+// CreateSubaddress creates a new subaddress of the primary account with the label,
+// which the wallet history shows for the transfers that it receives.
+func (c *walletClient) CreateSubaddress(label string) (*mcrypto.Address, error) {
+	res, err := c.wRPC.CreateAddress(&wallet.CreateAddressRequest{
+		AccountIndex: 0,
+		Label:        label,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mcrypto.NewAddress(res.Address, c.conf.Env)
+}
+
 func (c *walletClient) refresh() error {
 	_, err := c.wRPC.Refresh(&wallet.RefreshRequest{})
 	return err
@@ -552,6 +763,8 @@ func (c *walletClient) CreateWallet(filename, password string) error {
 }
 
 func (c *walletClient) PrimaryAddress() *mcrypto.Address {
+	c.addrMu.RLock()
+	defer c.addrMu.RUnlock()
 	if c.walletAddr == nil {
 		// Initialised in constructor function, so this shouldn't ever happen
 		panic("primary wallet address was not initialised")
@@ -572,10 +785,27 @@ func (c *walletClient) GetHeight() (uint64, error) {
 	return res.Height, nil
 }
 
+// GetSyncHeights returns the wallet height and the blockchain height of the monero
+// daemon. Unlike GetHeight, it doesn't refresh the wallet, so the difference shows
+// how far the wallet's background sync is behind the daemon.
+func (c *walletClient) GetSyncHeights() (uint64, uint64, error) {
+	res, err := c.wRPC.GetHeight()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	chainHeight, err := c.getChainHeight()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return res.Height, chainHeight, nil
+}
+
 // getChainHeight gets the blockchain height directly from the monero daemon instead
 // of the wallet height.
 func (c *walletClient) getChainHeight() (uint64, error) {
-	res, err := c.dRPC.GetBlockCount()
+	res, err := c.daemon().GetBlockCount()
 	if err != nil {
 		return 0, err
 	}
@@ -583,13 +813,35 @@ func (c *walletClient) getChainHeight() (uint64, error) {
 	return res.Count, nil
 }
 
+// EstimateTransferFee returns the approximate fee of a typical transfer at the monero
+// daemon's current fee per byte.
+func (c *walletClient) EstimateTransferFee() (*coins.PiconeroAmount, error) {
+	res, err := c.daemon().GetFeeEstimate(&monerodaemon.GetFeeEstimateRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	fee := res.Fee * typicalTxWeight
+	if mask := res.QuantizationMask; mask > 1 {
+		// the wallet rounds fees up to a multiple of the quantization mask
+		fee = (fee + mask - 1) / mask * mask
+	}
+
+	return coins.NewPiconeroAmount(fee), nil
+}
+
 func (c *walletClient) Endpoint() string {
 	return c.endpoint
 }
 
 // Close kills the monero-wallet-rpc process closing the wallet. It is designed to only be
-// called a single time from a single go process.
+// called a single time from a single go process. An external monero-wallet-rpc instance
+// is left running with its wallet open.
 func (c *walletClient) Close() {
+	if c.conf != nil && c.conf.nodePool != nil {
+		c.conf.nodePool.unregister(c)
+	}
+
 	if c.rpcProcess == nil {
 		return // no monero-wallet-rpc instance was created
 	}
@@ -631,36 +883,15 @@ func (c *walletClient) CloseAndRemoveWallet() {
 
 }
 
-func findWorkingNode(env common.Environment, nodes []*common.MoneroNode) (*common.MoneroNode, error) {
-	if len(nodes) == 0 {
-		return nil, errors.New("no monero nodes")
-	}
-
-	var err error
-	for _, n := range nodes {
-		err = validateMonerodNode(env, n)
-		if err != nil {
-			log.Warnf("Non-working node: %s", err)
-			continue
-		}
-		return n, nil
-	}
-
-	// err is non-nil if we get here
-	return nil, fmt.Errorf("failed to validate any monerod RPC node, last error: %w", err)
-}
-
 // validateMonerodNode validates the monerod node before we launch monero-wallet-rpc, as
 // doing the pre-checks creates more obvious error messages and faster failure.
-func validateMonerodNode(env common.Environment, node *common.MoneroNode) error {
-	endpoint := fmt.Sprintf("http://%s:%d/json_rpc", node.Host, node.Port)
-	daemonCli := monerorpc.New(endpoint, nil).Daemon
-
-	info, err := daemonCli.GetInfo()
-	if err != nil {
-		return fmt.Errorf("could not validate monerod endpoint %s: %w", endpoint, err)
-	}
+func validateMonerodNode(env common.Environment, node *common.MoneroNode, proxy *common.Proxy) error {
+	return checkNode(env, node, proxy).Err
+}
 
+// validateNodeInfo validates that the monerod node is a synchronised node of the
+// environment's network.
+func validateNodeInfo(env common.Environment, endpoint string, info *monerodaemon.GetInfoResponse) error {
 	switch env {
 	case common.Stagenet:
 		if !info.Stagenet {
@@ -700,8 +931,9 @@ func createWalletRPCService(
 	walletDir string,
 	logFilePath string,
 	moneroNode *common.MoneroNode,
+	proxy *common.Proxy,
 ) (*os.Process, error) {
-	walletRPCBinArgs := getWalletRPCFlags(env, walletPort, walletDir, logFilePath, moneroNode)
+	walletRPCBinArgs := getWalletRPCFlags(env, walletPort, walletDir, logFilePath, moneroNode, proxy)
 	proc, err := launchMoneroWalletRPCChild(walletRPCBinPath, walletRPCBinArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("%w, see %s for details", err, logFilePath)
@@ -812,6 +1044,7 @@ func getWalletRPCFlags(
 	walletDir string,
 	logFilePath string,
 	moneroNode *common.MoneroNode,
+	proxy *common.Proxy,
 ) []string {
 	args := []string{
 		"--rpc-bind-ip=127.0.0.1",
@@ -824,6 +1057,10 @@ func getWalletRPCFlags(
 		fmt.Sprintf("--daemon-port=%d", moneroNode.Port),
 	}
 
+	if proxy != nil && !common.IsLoopbackHost(moneroNode.Host) {
+		args = append(args, fmt.Sprintf("--proxy=%s", proxy.Addr()))
+	}
+
 	switch env {
 	case common.Development:
 		// See https://github.com/monero-project/monero/issues/8600
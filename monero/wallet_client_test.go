@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/MarinX/monerorpc/wallet"
 	logging "github.com/ipfs/go-log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,7 +55,7 @@ func TestClient_Transfer(t *testing.T) {
 	vkABPriv := mcrypto.SumPrivateViewKeys(kpA.ViewKey(), kpB.ViewKey())
 
 	// Transfer from Bob's account to the Alice+Bob swap account
-	transfer, err := cXMRMaker.Transfer(ctx, abAddress, 0, transferAmt, MinSpendConfirmations)
+	transfer, err := cXMRMaker.Transfer(ctx, abAddress, 0, transferAmt, MinSpendConfirmations, wallet.PriorityDefault)
 	require.NoError(t, err)
 	t.Logf("Bob sent %s (+fee %s) XMR to A+B address with TX ID %s",
 		coins.FmtPiconeroAsXMR(transfer.Amount),
@@ -63,6 +64,8 @@ func TestClient_Transfer(t *testing.T) {
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, transfer.Confirmations, uint64(MinSpendConfirmations))
 	t.Logf("Bob's TX was mined at height %d with %d confirmations", transfer.Height, transfer.Confirmations)
+	txProof, err := cXMRMaker.GetTxProof(transfer.TxID, abAddress, "swap-id")
+	require.NoError(t, err)
 	cXMRMaker.Close() // Done with bob, make sure no one uses him again
 	cXMRMaker = nil
 
@@ -76,6 +79,23 @@ func TestClient_Transfer(t *testing.T) {
 	require.NoError(t, err)
 	alicePrimaryAddr := cXMRTaker.PrimaryAddress()
 
+	// Alice can check Bob's proof of the transfer without the view key of the A+B wallet
+	proofCheck, err := cXMRTaker.CheckTxProof(transfer.TxID, abAddress, "swap-id", txProof)
+	require.NoError(t, err)
+	require.True(t, proofCheck.Good)
+	require.False(t, proofCheck.InPool)
+	require.Equal(t, transferAmtU64, proofCheck.Received)
+	require.GreaterOrEqual(t, proofCheck.Confirmations, uint64(MinSpendConfirmations))
+
+	unlockTime, err := cXMRTaker.GetTxUnlockTime(transfer.TxID)
+	require.NoError(t, err)
+	require.Zero(t, unlockTime)
+
+	// the proof doesn't sign other messages
+	proofCheck, err = cXMRTaker.CheckTxProof(transfer.TxID, abAddress, "other-swap-id", txProof)
+	require.NoError(t, err)
+	require.False(t, proofCheck.Good)
+
 	// Alice generates a view-only wallet for A+B to confirm that Bob sent the funds
 	conf := cXMRTaker.CreateWalletConf("alice-view-wallet-to-verify-funds")
 	abViewCli, err := CreateViewOnlyWalletFromKeys(conf, vkABPriv, abAddress, transfer.Height)
@@ -111,7 +131,7 @@ func TestClient_Transfer(t *testing.T) {
 	require.Equal(t, transferAmtU64, balanceABWal.UnlockedBalance)
 
 	// Alice transfers from A+B spend wallet to her primary wallet's address
-	transfers, err := abSpendCli.SweepAll(ctx, alicePrimaryAddr, 0, SweepToSelfConfirmations)
+	transfers, err := abSpendCli.SweepAll(ctx, alicePrimaryAddr, 0, SweepToSelfConfirmations, wallet.PriorityDefault)
 	require.NoError(t, err)
 	t.Logf("Alice swept AB wallet funds with %d transfers", len(transfers))
 	require.Len(t, transfers, 1) // In our case, it should always be a single transaction
@@ -134,6 +154,17 @@ func TestClient_Transfer(t *testing.T) {
 		coins.FmtPiconeroAsXMR(balanceAlice.UnlockedBalance),
 		balanceAlice.BlocksToUnlock)
 	require.Equal(t, balanceAlice.Balance, sweepAmount)
+
+	// the locked outputs make up the part of the balance that isn't unlocked yet
+	lockedOutputs, err := cXMRTaker.GetLockedOutputs()
+	require.NoError(t, err)
+	var lockedAmount uint64
+	for _, o := range lockedOutputs {
+		require.Equal(t, transfers[0].TxID, o.TxID)
+		require.NotZero(t, o.BlocksToUnlock)
+		lockedAmount += o.Amount
+	}
+	require.Equal(t, balanceAlice.Balance-balanceAlice.UnlockedBalance, lockedAmount)
 }
 
 func Test_walletClient_SweepAll_nothingToSweepReturnsError(t *testing.T) {
@@ -146,7 +177,7 @@ func Test_walletClient_SweepAll_nothingToSweepReturnsError(t *testing.T) {
 	destAddr, err := mcrypto.NewAddress(addrResp.Address, common.Development)
 	require.NoError(t, err)
 
-	_, err = emptyWallet.SweepAll(context.Background(), destAddr, 0, SweepToSelfConfirmations)
+	_, err = emptyWallet.SweepAll(context.Background(), destAddr, 0, SweepToSelfConfirmations, wallet.PriorityDefault)
 	require.ErrorContains(t, err, "no balance to sweep")
 }
 
@@ -181,6 +212,30 @@ func TestClient_GetAccounts(t *testing.T) {
 	require.Equal(t, 1, len(resp.SubaddressAccounts))
 }
 
+func TestClient_CreateSubaddress(t *testing.T) {
+	c, err := NewWalletClient(&WalletClientConf{
+		Env:                 common.Development,
+		WalletFilePath:      path.Join(t.TempDir(), "wallet", "test-wallet"),
+		MoneroWalletRPCPath: moneroWalletRPCPath,
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	subaddr1, err := c.CreateSubaddress("swap-1")
+	require.NoError(t, err)
+	require.Equal(t, mcrypto.Subaddress, subaddr1.Type())
+	require.False(t, subaddr1.Equal(c.PrimaryAddress()))
+
+	subaddr2, err := c.CreateSubaddress("swap-2")
+	require.NoError(t, err)
+	require.False(t, subaddr1.Equal(subaddr2))
+
+	resp, err := c.GetAddress(0)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(resp.Addresses))
+	require.Equal(t, "swap-2", resp.Addresses[2].Label)
+}
+
 func TestClient_GetHeight(t *testing.T) {
 	c, err := NewWalletClient(&WalletClientConf{
 		Env:                 common.Development,
@@ -285,21 +340,21 @@ func Test_getMoneroWalletRPCBin(t *testing.T) {
 
 func Test_validateMonerodConfigs_dev(t *testing.T) {
 	env := common.Development
-	node, err := findWorkingNode(env, common.ConfigDefaultsForEnv(env).MoneroNodes)
+	node, err := newNodePool(env, nil, common.ConfigDefaultsForEnv(env).MoneroNodes, nil).selectInitialNode()
 	require.NoError(t, err)
 	require.NotNil(t, node)
 }
 
 func Test_validateMonerodConfigs_stagenet(t *testing.T) {
 	env := common.Stagenet
-	node, err := findWorkingNode(env, common.ConfigDefaultsForEnv(env).MoneroNodes)
+	node, err := newNodePool(env, nil, common.ConfigDefaultsForEnv(env).MoneroNodes, nil).selectInitialNode()
 	require.NoError(t, err)
 	require.NotNil(t, node)
 }
 
 func Test_validateMonerodConfigs_mainnet(t *testing.T) {
 	env := common.Mainnet
-	node, err := findWorkingNode(env, common.ConfigDefaultsForEnv(env).MoneroNodes)
+	node, err := newNodePool(env, nil, common.ConfigDefaultsForEnv(env).MoneroNodes, nil).selectInitialNode()
 	require.NoError(t, err)
 	require.NotNil(t, node)
 }
@@ -309,7 +364,7 @@ func Test_validateMonerodConfig_misMatchedEnv(t *testing.T) {
 		Host: "127.0.0.1",
 		Port: common.DefaultMoneroDaemonDevPort,
 	}
-	err := validateMonerodNode(common.Mainnet, node)
+	err := validateMonerodNode(common.Mainnet, node, nil)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "is not a mainnet node")
 }
@@ -321,7 +376,7 @@ func Test_validateMonerodConfig_invalidPort(t *testing.T) {
 		Host: "127.0.0.1",
 		Port: nonUsedPort,
 	}
-	err = validateMonerodNode(common.Development, node)
+	err = validateMonerodNode(common.Development, node, nil)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "connection refused")
 }
@@ -341,7 +396,7 @@ func Test_walletClient_waitForConfirmations_contextCancelled(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = c.Transfer(ctx, destAddr, 0, coins.NewPiconeroAmount(amount), numConfirmations)
+	_, err = c.Transfer(ctx, destAddr, 0, coins.NewPiconeroAmount(amount), numConfirmations, wallet.PriorityDefault)
 	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
 
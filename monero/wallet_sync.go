@@ -0,0 +1,86 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"time"
+)
+
+const (
+	// blockTime is the target time between monero blocks
+	blockTime = 2 * time.Minute
+
+	// restoreDateMargin is scanned before the estimated height of a restore date, as
+	// the actual block times vary from the target
+	restoreDateMargin = 7 * 24 * time.Hour
+
+	// scanProgressInterval is how often the progress of a wallet scan is logged
+	scanProgressInterval = 10 * time.Second
+)
+
+// restoreHeightFromDate estimates the height of the first block mined on the date
+// from the current blockchain height.
+func (c *walletClient) restoreHeightFromDate(date time.Time) (uint64, error) {
+	chainHeight, err := c.getChainHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	return estimateRestoreHeight(chainHeight, time.Since(date)), nil
+}
+
+// estimateRestoreHeight returns the height of the block mined `age` ago, minus the
+// safety margin, assuming the target block time.
+func estimateRestoreHeight(chainHeight uint64, age time.Duration) uint64 {
+	if age < 0 {
+		age = 0
+	}
+
+	blocks := uint64((age + restoreDateMargin) / blockTime)
+	if blocks >= chainHeight {
+		return 0
+	}
+
+	return chainHeight - blocks
+}
+
+// syncWallet refreshes the wallet up to the blockchain height, logging the progress
+// while it scans, as scanning a restored or long-unopened wallet can take a long time.
+func (c *walletClient) syncWallet() error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.refresh()
+	}()
+
+	ticker := time.NewTicker(scanProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			c.logScanProgress()
+		}
+	}
+}
+
+func (c *walletClient) logScanProgress() {
+	p, err := c.GetSyncProgress()
+	if err != nil {
+		log.Infof("Monero wallet is scanning the blockchain")
+		return
+	}
+
+	if p.Synced {
+		return
+	}
+
+	eta := "unknown"
+	if p.ETA > 0 {
+		eta = p.ETA.String()
+	}
+	log.Infof("Monero wallet is scanning the blockchain: at block %d of %d (%.1f%%, ETA %s)",
+		p.WalletHeight, p.DaemonHeight, p.Percent, eta)
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_estimateRestoreHeight(t *testing.T) {
+	marginBlocks := uint64(restoreDateMargin / blockTime)
+	const chainHeight = 3_000_000
+
+	// one day is 720 blocks
+	height := estimateRestoreHeight(chainHeight, 24*time.Hour)
+	require.Equal(t, uint64(chainHeight-720-marginBlocks), height)
+
+	// dates in the future scan from the margin
+	height = estimateRestoreHeight(chainHeight, -time.Hour)
+	require.Equal(t, uint64(chainHeight-marginBlocks), height)
+
+	// dates before the genesis block scan the whole chain
+	height = estimateRestoreHeight(chainHeight, 20*365*24*time.Hour)
+	require.Zero(t, height)
+}
@@ -0,0 +1,299 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/libp2p/go-libp2p/core/connmgr"
+	"github.com/libp2p/go-libp2p/core/control"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+const (
+	bannedPeersPrefix = "/swapd/banned-peers"
+
+	// A peer whose misbehavior score reaches misbehaviorBanScore within
+	// misbehaviorWindow is banned for misbehaviorBanDuration.
+	misbehaviorBanScore    = 10
+	misbehaviorWindow      = time.Hour * 24
+	misbehaviorBanDuration = time.Hour * 24
+)
+
+// Misbehavior penalties, which are added to the misbehavior score of a peer.
+const (
+	// InvalidMessagePenalty is the penalty of a peer for sending a message that
+	// can't be decoded, or that is of the wrong type for the stream.
+	InvalidMessagePenalty = 1
+	// SwapRefundPenalty is the penalty of a peer for not completing a swap after
+	// funds were locked, so that the swap was refunded.
+	SwapRefundPenalty = 5
+)
+
+var errBanSelf = errors.New("cannot ban our own peer ID")
+
+// BannedPeer is a peer that we don't connect to, and that can't connect to us.
+type BannedPeer struct {
+	PeerID peer.ID    `json:"peerID"`
+	Until  *time.Time `json:"until,omitempty"` // nil if the ban is permanent
+	Reason string     `json:"reason,omitempty"`
+}
+
+func (b *BannedPeer) expired() bool {
+	return b.Until != nil && time.Now().After(*b.Until)
+}
+
+func bannedPeerKey(p peer.ID) datastore.Key {
+	return datastore.NewKey(bannedPeersPrefix).ChildString(p.String())
+}
+
+// misbehavior is the misbehavior score of a peer since the time of its first
+// misbehavior within misbehaviorWindow.
+type misbehavior struct {
+	score int
+	since time.Time
+}
+
+// banList is the connection gater of the host, which refuses the connections of
+// banned peers. The bans are kept in the datastore, so that they last across
+// restarts.
+type banList struct {
+	ds datastore.Datastore
+
+	mu          sync.Mutex
+	banned      map[peer.ID]*BannedPeer
+	misbehavior map[peer.ID]*misbehavior
+}
+
+var (
+	_ connmgr.ConnectionGater = (*banList)(nil)
+	_ pswap.EventListener     = (*Host)(nil)
+)
+
+// newBanList returns the ban list with the bans in the datastore, deleting the
+// expired ones.
+func newBanList(ctx context.Context, ds datastore.Datastore) (*banList, error) {
+	b := &banList{
+		ds:          ds,
+		banned:      make(map[peer.ID]*BannedPeer),
+		misbehavior: make(map[peer.ID]*misbehavior),
+	}
+
+	results, err := ds.Query(ctx, query.Query{Prefix: bannedPeersPrefix})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := results.Rest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		bp := new(BannedPeer)
+		if err = json.Unmarshal(e.Value, bp); err != nil || bp.expired() {
+			_ = ds.Delete(ctx, datastore.NewKey(e.Key))
+			continue
+		}
+		b.banned[bp.PeerID] = bp
+	}
+
+	return b, nil
+}
+
+// isBanned returns whether the peer is banned, deleting its ban if it expired.
+func (b *banList) isBanned(p peer.ID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bp, ok := b.banned[p]
+	if !ok {
+		return false
+	}
+
+	if bp.expired() {
+		delete(b.banned, p)
+		_ = b.ds.Delete(context.Background(), bannedPeerKey(p))
+		return false
+	}
+
+	return true
+}
+
+// ban bans the peer for the duration, or permanently if the duration is zero,
+// replacing any previous ban of the peer.
+func (b *banList) ban(p peer.ID, duration time.Duration, reason string) error {
+	bp := &BannedPeer{
+		PeerID: p,
+		Reason: reason,
+	}
+	if duration > 0 {
+		until := time.Now().Add(duration)
+		bp.Until = &until
+	}
+
+	data, err := json.Marshal(bp)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err = b.ds.Put(context.Background(), bannedPeerKey(p), data); err != nil {
+		return err
+	}
+
+	b.banned[p] = bp
+	delete(b.misbehavior, p)
+	return nil
+}
+
+// unban removes the ban of the peer, if it is banned.
+func (b *banList) unban(p peer.ID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.ds.Delete(context.Background(), bannedPeerKey(p)); err != nil {
+		return err
+	}
+
+	delete(b.banned, p)
+	delete(b.misbehavior, p)
+	return nil
+}
+
+// list returns the banned peers, sorted by peer ID.
+func (b *banList) list() []*BannedPeer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	banned := make([]*BannedPeer, 0, len(b.banned))
+	for _, bp := range b.banned {
+		if !bp.expired() {
+			banned = append(banned, bp)
+		}
+	}
+
+	sort.Slice(banned, func(i, j int) bool {
+		return banned[i].PeerID < banned[j].PeerID
+	})
+
+	return banned
+}
+
+// addMisbehavior adds the penalty to the misbehavior score of the peer, and
+// returns whether the score reached misbehaviorBanScore, in which case the score
+// is reset.
+func (b *banList) addMisbehavior(p peer.ID, penalty int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	m, ok := b.misbehavior[p]
+	if !ok || time.Since(m.since) > misbehaviorWindow {
+		m = &misbehavior{since: time.Now()}
+		b.misbehavior[p] = m
+	}
+
+	m.score += penalty
+	if m.score < misbehaviorBanScore {
+		return false
+	}
+
+	delete(b.misbehavior, p)
+	return true
+}
+
+// InterceptPeerDial implements connmgr.ConnectionGater, refusing to dial banned
+// peers.
+func (b *banList) InterceptPeerDial(p peer.ID) bool {
+	return !b.isBanned(p)
+}
+
+// InterceptAddrDial implements connmgr.ConnectionGater.
+func (b *banList) InterceptAddrDial(p peer.ID, _ ma.Multiaddr) bool {
+	return !b.isBanned(p)
+}
+
+// InterceptAccept implements connmgr.ConnectionGater. The peer of an inbound
+// connection is only known once the connection is secured.
+func (b *banList) InterceptAccept(_ libp2pnetwork.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptSecured implements connmgr.ConnectionGater, refusing the connections
+// of banned peers.
+func (b *banList) InterceptSecured(_ libp2pnetwork.Direction, p peer.ID, _ libp2pnetwork.ConnMultiaddrs) bool {
+	return !b.isBanned(p)
+}
+
+// InterceptUpgraded implements connmgr.ConnectionGater.
+func (b *banList) InterceptUpgraded(_ libp2pnetwork.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
+
+// BanPeer bans the peer for the duration, or permanently if the duration is zero,
+// and closes our connections to it.
+func (lh *libp2pHost) BanPeer(p peer.ID, duration time.Duration, reason string) error {
+	if p == lh.h.ID() {
+		return errBanSelf
+	}
+
+	if err := lh.bans.ban(p, duration, reason); err != nil {
+		return err
+	}
+
+	log.Infof("banned peer %s: %s", p, reason)
+	return lh.h.Network().ClosePeer(p)
+}
+
+// UnbanPeer removes the ban of the peer, if it is banned.
+func (lh *libp2pHost) UnbanPeer(p peer.ID) error {
+	return lh.bans.unban(p)
+}
+
+// BannedPeers returns the banned peers, sorted by peer ID.
+func (lh *libp2pHost) BannedPeers() []*BannedPeer {
+	return lh.bans.list()
+}
+
+// ReportMisbehavior adds the penalty to the misbehavior score of the peer, which
+// is banned for misbehaviorBanDuration once its score reaches misbehaviorBanScore
+// within misbehaviorWindow. It doesn't block.
+func (lh *libp2pHost) ReportMisbehavior(p peer.ID, penalty int, reason string) {
+	log.Debugf("peer %s misbehaved: %s", p, reason)
+	if !lh.bans.addMisbehavior(p, penalty) {
+		return
+	}
+
+	go func() {
+		if err := lh.BanPeer(p, misbehaviorBanDuration, "repeated misbehavior: "+reason); err != nil {
+			log.Warnf("failed to ban misbehaving peer %s: %s", p, err)
+		}
+	}()
+}
+
+// SwapStarted implements pswap.EventListener.
+func (h *Host) SwapStarted(_ *pswap.Info) {}
+
+// SwapStatusChanged implements pswap.EventListener, adding the penalty of a refunded
+// swap to the misbehavior score of the counterparty, if the counterparty caused the
+// refund.
+func (h *Host) SwapStatusChanged(info *pswap.Info) {
+	if info.RefundCausedByCounterparty() {
+		h.h.ReportMisbehavior(info.PeerID, SwapRefundPenalty, fmt.Sprintf("swap %s was refunded", info.OfferID))
+	}
+}
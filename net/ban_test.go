@@ -0,0 +1,184 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func isBanned(h *Host, p peer.ID) bool {
+	for _, bp := range h.BannedPeers() {
+		if bp.PeerID == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHost_BanPeer(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+
+	err := h2.h.Connect(h2.ctx, h1.AddrInfo())
+	require.NoError(t, err)
+
+	err = h2.BanPeer(h1.PeerID(), 0, "test")
+	require.NoError(t, err)
+	require.Equal(t, []*BannedPeer{{PeerID: h1.PeerID(), Reason: "test"}}, h2.BannedPeers())
+	require.NotEqual(t, libp2pnetwork.Connected, h2.h.Connectedness(h1.PeerID()))
+
+	// the banned peer can't connect to us, and we don't connect to it
+	err = h1.h.Connect(h1.ctx, h2.AddrInfo())
+	require.Error(t, err)
+	err = h2.h.Connect(h2.ctx, h1.AddrInfo())
+	require.Error(t, err)
+
+	err = h2.UnbanPeer(h1.PeerID())
+	require.NoError(t, err)
+	require.Empty(t, h2.BannedPeers())
+
+	err = h1.h.Connect(h1.ctx, h2.AddrInfo())
+	require.NoError(t, err)
+}
+
+func TestHost_BanPeer_self(t *testing.T) {
+	h := newHost(t, basicTestConfig(t))
+	err := h.BanPeer(h.PeerID(), 0, "")
+	require.ErrorIs(t, err, errBanSelf)
+}
+
+func TestHost_BanPeer_afterRestart(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	banned := peer.ID("banned")
+
+	cfg := basicTestConfig(t)
+	h2, err := NewHost(cfg)
+	require.NoError(t, err)
+	require.NoError(t, h2.BanPeer(h1.PeerID(), time.Hour, "test"))
+	require.NoError(t, h2.BanPeer(banned, 0, ""))
+
+	// an expired ban is deleted when the host restarts
+	data, err := json.Marshal(&BannedPeer{PeerID: banned, Until: new(time.Time)})
+	require.NoError(t, err)
+	lh := h2.h.(*libp2pHost)
+	err = lh.ds.Put(context.Background(), bannedPeerKey(banned), data)
+	require.NoError(t, err)
+	require.NoError(t, h2.Stop())
+
+	h2 = newHost(t, cfg)
+	bannedPeers := h2.BannedPeers()
+	require.Len(t, bannedPeers, 1)
+	require.Equal(t, h1.PeerID(), bannedPeers[0].PeerID)
+	require.NotNil(t, bannedPeers[0].Until)
+
+	err = h2.h.Connect(h2.ctx, h1.AddrInfo())
+	require.Error(t, err)
+}
+
+func TestBanList_expired(t *testing.T) {
+	lh := newHost(t, basicTestConfig(t)).h.(*libp2pHost)
+	p := peer.ID("peer")
+
+	require.NoError(t, lh.bans.ban(p, time.Millisecond, ""))
+	require.True(t, lh.bans.isBanned(p))
+
+	time.Sleep(time.Millisecond * 10)
+	require.False(t, lh.bans.isBanned(p))
+	require.Empty(t, lh.bans.list())
+}
+
+func TestBanList_addMisbehavior(t *testing.T) {
+	lh := newHost(t, basicTestConfig(t)).h.(*libp2pHost)
+	p := peer.ID("peer")
+
+	for i := 1; i < misbehaviorBanScore; i++ {
+		require.False(t, lh.bans.addMisbehavior(p, InvalidMessagePenalty))
+	}
+	require.True(t, lh.bans.addMisbehavior(p, InvalidMessagePenalty))
+
+	// the score is reset once the peer reaches the ban score
+	require.False(t, lh.bans.addMisbehavior(p, InvalidMessagePenalty))
+
+	// misbehavior before the window is forgotten
+	lh.bans.misbehavior[p].since = time.Now().Add(-misbehaviorWindow - time.Minute)
+	lh.bans.misbehavior[p].score = misbehaviorBanScore - 1
+	require.False(t, lh.bans.addMisbehavior(p, InvalidMessagePenalty))
+}
+
+func TestHost_invalidMessagesBan(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+
+	require.NoError(t, h1.h.Connect(h1.ctx, h2.AddrInfo()))
+
+	// a QueryResponse is the wrong message type for a query stream
+	for i := 0; i < misbehaviorBanScore/InvalidMessagePenalty; i++ {
+		stream, err := h1.h.NewStream(h1.ctx, h2.PeerID(), filteredQueryProtocolID)
+		require.NoError(t, err)
+		err = writeMessage(stream, &QueryResponse{}, h2.PeerID())
+		require.NoError(t, err)
+		_, _ = h1.codec.readStreamMessage(stream, maxMessageSize)
+		_ = stream.Close()
+	}
+
+	require.Eventually(t, func() bool {
+		return isBanned(h2, h1.PeerID())
+	}, time.Second*5, time.Millisecond*50)
+}
+
+func TestHost_refundedSwapsBan(t *testing.T) {
+	h := newHost(t, basicTestConfig(t))
+	p := peer.ID("peer")
+
+	// a swap refunded just now, long before T0
+	newRefundedInfo := func(offerID types.Hash, provides coins.ProvidesCoin, prevStatus types.Status) *pswap.Info {
+		t0 := time.Now().Add(time.Hour)
+		t1 := t0.Add(time.Hour)
+		return &pswap.Info{
+			PeerID:   p,
+			OfferID:  offerID,
+			Provides: provides,
+			Status:   types.CompletedRefund,
+			Timeout0: &t0,
+			Timeout1: &t1,
+			Stages: []*pswap.Stage{
+				{Status: prevStatus, EnteredAt: time.Now().Add(-time.Minute)},
+				{Status: types.CompletedRefund, EnteredAt: time.Now()},
+			},
+		}
+	}
+
+	// as the maker, the taker refunded instead of setting the contract to ready
+	h.SwapStatusChanged(newRefundedInfo(types.Hash{1}, coins.ProvidesXMR, types.XMRLocked))
+	require.False(t, isBanned(h, p))
+
+	// completed swaps are not penalized
+	info := &pswap.Info{PeerID: p, OfferID: types.Hash{2}, Status: types.CompletedSuccess}
+	h.SwapStatusChanged(info)
+	require.False(t, isBanned(h, p))
+
+	// neither are refunds that we caused, like cancelling a swap as the taker long
+	// before T0
+	for i := byte(3); i < 6; i++ {
+		h.SwapStatusChanged(newRefundedInfo(types.Hash{i}, coins.ProvidesETH, types.ETHLocked))
+	}
+	require.False(t, isBanned(h, p))
+
+	// the maker not claiming after we set the contract to ready is penalized
+	h.SwapStatusChanged(newRefundedInfo(types.Hash{6}, coins.ProvidesETH, types.ContractReady))
+	require.Eventually(t, func() bool {
+		return isBanned(h, p)
+	}, time.Second*5, time.Millisecond*50)
+}
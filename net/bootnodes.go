@@ -0,0 +1,123 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
+)
+
+const (
+	dnsaddrResolveTimeout = time.Second * 10
+	maxDNSAddrDepth       = 3 // dnsaddr records can point to other dnsaddr domains
+)
+
+// bootnodes are the peers that the host joins the network through. Besides
+// addresses with peer IDs, they can be /dnsaddr addresses without peer IDs, like
+// /dnsaddr/bootnodes.example.com, whose _dnsaddr TXT records hold the addresses of
+// the bootnodes as "dnsaddr=<multiaddr>". These are resolved each time that the
+// bootnodes are used, so the bootnodes behind a domain can change while we run.
+type bootnodes struct {
+	static   []peer.AddrInfo
+	dnsaddrs []ma.Multiaddr
+	resolver *madns.Resolver
+}
+
+// parseBootnodes returns the bootnodes of the addresses, which must either have a
+// peer ID or be /dnsaddr addresses.
+func parseBootnodes(addrs []string) (*bootnodes, error) {
+	b := &bootnodes{resolver: madns.DefaultResolver}
+
+	p2pAddrs := make([]ma.Multiaddr, 0, len(addrs))
+	for _, addr := range addrs {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bootnode address %q: %w", addr, err)
+		}
+
+		if _, err = maddr.ValueForProtocol(ma.P_P2P); err == nil {
+			p2pAddrs = append(p2pAddrs, maddr)
+			continue
+		}
+
+		if _, err = maddr.ValueForProtocol(ma.P_DNSADDR); err != nil {
+			return nil, fmt.Errorf("bootnode address %q has neither a peer ID nor a /dnsaddr domain", addr)
+		}
+		b.dnsaddrs = append(b.dnsaddrs, maddr)
+	}
+
+	var err error
+	b.static, err = peer.AddrInfosFromP2pAddrs(p2pAddrs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// addrInfos returns the bootnodes, resolving the /dnsaddr addresses. The ones that
+// fail to resolve are left out.
+func (b *bootnodes) addrInfos(ctx context.Context) []peer.AddrInfo {
+	if len(b.dnsaddrs) == 0 {
+		return b.static
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dnsaddrResolveTimeout)
+	defer cancel()
+
+	var resolved []ma.Multiaddr
+	for _, addr := range b.dnsaddrs {
+		addrs, err := b.resolve(ctx, addr, maxDNSAddrDepth)
+		if err != nil {
+			log.Warnf("failed to resolve bootnode address %s: %s", addr, err)
+			continue
+		}
+		resolved = append(resolved, addrs...)
+	}
+
+	resolvedInfos, err := peer.AddrInfosFromP2pAddrs(resolved...)
+	if err != nil {
+		// not reached, as resolve only returns addresses with peer IDs
+		log.Warnf("invalid resolved bootnode addresses: %s", err)
+		return b.static
+	}
+
+	return append(append([]peer.AddrInfo{}, b.static...), resolvedInfos...)
+}
+
+// resolve returns the addresses with peer IDs that the /dnsaddr address resolves
+// to, following up to depth /dnsaddr domains.
+func (b *bootnodes) resolve(ctx context.Context, addr ma.Multiaddr, depth int) ([]ma.Multiaddr, error) {
+	addrs, err := b.resolver.Resolve(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved []ma.Multiaddr
+	for _, a := range addrs {
+		if _, err = a.ValueForProtocol(ma.P_P2P); err == nil {
+			resolved = append(resolved, a)
+			continue
+		}
+
+		if _, err = a.ValueForProtocol(ma.P_DNSADDR); err != nil || depth <= 1 {
+			log.Debugf("ignoring resolved bootnode address %s without a peer ID", a)
+			continue
+		}
+
+		var nested []ma.Multiaddr
+		nested, err = b.resolve(ctx, a, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, nested...)
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBootnodeID1 = "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5"
+	testBootnodeID2 = "12D3KooWSc4yFkPWBFmPToTMbhChH3FAgGH96DNzSg5fio1pQYoN"
+	testBootnodeID3 = "12D3KooWLbfkLZZvvn8Lxs1KDU3u7gyvBk88ZNtJBbugytBr5RCG"
+)
+
+func TestParseBootnodes_invalid(t *testing.T) {
+	_, err := parseBootnodes([]string{"not an address"})
+	require.ErrorContains(t, err, "invalid bootnode address")
+
+	_, err = parseBootnodes([]string{"/ip4/1.2.3.4/tcp/9900"})
+	require.ErrorContains(t, err, "neither a peer ID nor a /dnsaddr domain")
+}
+
+func TestBootnodes_addrInfos(t *testing.T) {
+	b, err := parseBootnodes([]string{
+		"/ip4/1.2.3.4/tcp/9900/p2p/" + testBootnodeID1,
+		"/dnsaddr/bootnodes.example.com",
+	})
+	require.NoError(t, err)
+	require.Len(t, b.static, 1)
+
+	b.resolver, err = madns.NewResolver(madns.WithDefaultResolver(&madns.MockResolver{
+		TXT: map[string][]string{
+			"_dnsaddr.bootnodes.example.com": {
+				"dnsaddr=/ip4/5.6.7.8/tcp/9900/p2p/" + testBootnodeID2,
+				"dnsaddr=/ip4/5.6.7.8/udp/9900/quic-v1/p2p/" + testBootnodeID2,
+				"dnsaddr=/dnsaddr/more.example.com",
+				"dnsaddr=/ip4/9.9.9.9/tcp/9900", // no peer ID
+			},
+			"_dnsaddr.more.example.com": {
+				"dnsaddr=/ip4/10.11.12.13/tcp/9900/p2p/" + testBootnodeID3,
+			},
+		},
+	}))
+	require.NoError(t, err)
+
+	id1 := mustDecodePeerID(t, testBootnodeID1)
+	id2 := mustDecodePeerID(t, testBootnodeID2)
+	id3 := mustDecodePeerID(t, testBootnodeID3)
+
+	var ids []peer.ID
+	addrs := make(map[peer.ID][]ma.Multiaddr)
+	for _, ai := range b.addrInfos(context.Background()) {
+		ids = append(ids, ai.ID)
+		addrs[ai.ID] = ai.Addrs
+	}
+
+	require.ElementsMatch(t, []peer.ID{id1, id2, id3}, ids)
+	require.Len(t, addrs[id2], 2)
+	require.Len(t, addrs[id3], 1)
+	require.Equal(t, "/ip4/10.11.12.13/tcp/9900", addrs[id3][0].String())
+}
+
+func mustDecodePeerID(t *testing.T, id string) peer.ID {
+	p, err := peer.Decode(id)
+	require.NoError(t, err)
+	return p
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	capabilitiesProtocolID     = "/capabilities/0"
+	maxCapabilitiesMessageSize = 4096
+	capabilitiesTimeout        = time.Second * 15
+)
+
+// Features that hosts advertise in their Capabilities
+const (
+	// FeatureTokenSwaps is the support of swaps of ERC20 tokens for XMR.
+	FeatureTokenSwaps = "token-swaps"
+)
+
+var errIncompatiblePeer = errors.New("peer runs an incompatible swapd version")
+
+// capabilities returns the capabilities that we advertise to peers.
+func (h *Host) capabilities() *Capabilities {
+	return &Capabilities{
+		Version: h.version,
+		Protocols: []string{
+			swapID,
+			queryProtocolID,
+			filteredQueryProtocolID,
+			relayProtocolID,
+			relayQuoteProtocolID,
+			peerExchangeProtocolID,
+			capabilitiesProtocolID,
+		},
+		Features: []string{FeatureTokenSwaps},
+	}
+}
+
+// handleCapabilitiesStream is called when a peer sends us its capabilities, which we
+// answer with ours.
+func (h *Host) handleCapabilitiesStream(stream libp2pnetwork.Stream) {
+	defer func() { _ = stream.Close() }()
+
+	curPeer := stream.Conn().RemotePeer()
+
+	msg, err := h.codec.readStreamMessage(stream, maxCapabilitiesMessageSize)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			log.Debugf("error reading Capabilities: %s", err)
+			h.reportInvalidMessage(curPeer, err)
+		}
+		return
+	}
+
+	caps, ok := msg.(*Capabilities)
+	if !ok {
+		log.Debugf("ignoring wrong message type=%s sent to capabilities stream from %s",
+			message.TypeToString(msg.Type()), curPeer)
+		h.reportWrongMessageType(curPeer, msg, stream.Protocol())
+		return
+	}
+
+	log.Debugf("peer %s runs swapd %s with features %v", curPeer, caps.Version, caps.Features)
+
+	if err = h.codec.writeStreamMessage(stream, h.capabilities()); err != nil {
+		log.Warnf("failed to send Capabilities message to peer: %s", err)
+	}
+}
+
+// PeerCapabilities exchanges capabilities with the peer. It returns nil capabilities
+// if the peer runs a swapd version that predates the capability exchange.
+func (h *Host) PeerCapabilities(who peer.ID) (*Capabilities, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, h.dialTimeout)
+	defer cancel()
+
+	stream, err := h.h.NewStream(ctx, who, capabilitiesProtocolID)
+	if err != nil {
+		// if we could connect, the peer doesn't support the protocol
+		if h.h.Connectedness(who) == libp2pnetwork.Connected {
+			log.Debugf("peer %s doesn't exchange capabilities: %s", who, err)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	defer func() { _ = stream.Close() }()
+
+	if err = h.codec.writeStreamMessage(stream, h.capabilities()); err != nil {
+		return nil, err
+	}
+
+	return h.receiveCapabilities(stream)
+}
+
+func (h *Host) receiveCapabilities(stream libp2pnetwork.Stream) (*Capabilities, error) {
+	select {
+	case msg := <-h.codec.nextStreamMessage(stream, maxCapabilitiesMessageSize):
+		if msg == nil {
+			return nil, errors.New("failed to read Capabilities")
+		}
+
+		caps, ok := msg.(*Capabilities)
+		if !ok {
+			return nil, fmt.Errorf("expected %s message but received %s",
+				message.TypeToString(message.CapabilitiesType),
+				message.TypeToString(msg.Type()))
+		}
+
+		return caps, nil
+	case <-time.After(capabilitiesTimeout):
+		return nil, errors.New("timed out waiting for Capabilities")
+	}
+}
+
+// CheckSwapCapabilities returns an error if the peer with the capabilities can't
+// swap the offer with us. Peers whose capabilities are nil, as they predate the
+// capability exchange, are assumed to be compatible.
+func CheckSwapCapabilities(caps *Capabilities, offer *types.Offer) error {
+	if caps == nil {
+		return nil
+	}
+
+	if !containsString(caps.Protocols, swapID) {
+		return fmt.Errorf("%w: peer runs swapd %s, which supports none of our swap protocols (%s)",
+			errIncompatiblePeer, caps.Version, swapID)
+	}
+
+	if offer.EthAsset.IsToken() && !containsString(caps.Features, FeatureTokenSwaps) {
+		return fmt.Errorf("%w: peer runs swapd %s, which doesn't support token swaps",
+			errIncompatiblePeer, caps.Version)
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestHost_PeerCapabilities(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.Version = "v0.4.0"
+	maker := newHost(t, cfg)
+	taker := newHost(t, basicTestConfig(t))
+
+	err := taker.h.Connect(taker.ctx, maker.AddrInfo())
+	require.NoError(t, err)
+
+	caps, err := taker.PeerCapabilities(maker.PeerID())
+	require.NoError(t, err)
+	require.Equal(t, maker.capabilities(), caps)
+	require.Equal(t, "v0.4.0", caps.Version)
+	require.NoError(t, CheckSwapCapabilities(caps, newTestOffer()))
+
+	// peers predating the capability exchange are assumed to be compatible
+	lh := maker.h.(*libp2pHost)
+	lh.h.RemoveStreamHandler(protocol.ID(lh.protocolID + capabilitiesProtocolID))
+	caps, err = taker.PeerCapabilities(maker.PeerID())
+	require.NoError(t, err)
+	require.Nil(t, caps)
+}
+
+func TestCheckSwapCapabilities(t *testing.T) {
+	offer := newTestOffer()
+	tokenOffer := newTestOffer()
+	tokenOffer.EthAsset = types.EthAsset(ethcommon.Address{1})
+
+	require.NoError(t, CheckSwapCapabilities(nil, offer))
+	require.NoError(t, CheckSwapCapabilities(nil, tokenOffer))
+
+	caps := &Capabilities{Version: "v1.0.0", Protocols: []string{"/swap/1"}, Features: []string{FeatureTokenSwaps}}
+	err := CheckSwapCapabilities(caps, offer)
+	require.ErrorIs(t, err, errIncompatiblePeer)
+	require.ErrorContains(t, err, "v1.0.0")
+
+	caps = &Capabilities{Version: "v0.3.1", Protocols: []string{swapID}}
+	require.NoError(t, CheckSwapCapabilities(caps, offer))
+	err = CheckSwapCapabilities(caps, tokenOffer)
+	require.ErrorIs(t, err, errIncompatiblePeer)
+	require.ErrorContains(t, err, "doesn't support token swaps")
+}
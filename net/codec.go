@@ -0,0 +1,248 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+// compressionThreshold is the size of the encoded messages above which they are
+// compressed, the smaller messages don't compress enough to be worth it.
+const compressionThreshold = 512
+
+// Compression algorithms of compressed messages
+const (
+	compressionZstd byte = iota + 1
+	compressionSnappy
+)
+
+// compressionProtocols are the protocols through which hosts advertise the
+// compression algorithms that they can decompress, in our order of preference.
+// They have no streams, the peers supporting them are known through identify.
+var compressionProtocols = []struct {
+	algorithm byte
+	id        string
+}{
+	{compressionZstd, "/compression/zstd/1"},
+	{compressionSnappy, "/compression/snappy/1"},
+}
+
+var errMessageTooLarge = errors.New("decompressed message is too large")
+
+// streamCodec reads and writes the messages of streams. The messages larger than
+// compressionThreshold are compressed with the preferred algorithm that the peer
+// supports, so older peers keep receiving uncompressed messages.
+type streamCodec struct {
+	protocolPrefix string
+	peerstore      peerstore.Peerstore
+	stats          *netStats
+	zstdEncoder    *zstd.Encoder
+	zstdDecoder    *zstd.Decoder
+}
+
+func newStreamCodec(protocolPrefix string, ps peerstore.Peerstore, stats *netStats) (*streamCodec, error) {
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxMessageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamCodec{
+		protocolPrefix: protocolPrefix,
+		peerstore:      ps,
+		stats:          stats,
+		zstdEncoder:    enc,
+		zstdDecoder:    dec,
+	}, nil
+}
+
+// protocolIDs returns the IDs of the compression protocols that we advertise.
+func (c *streamCodec) protocolIDs() []protocol.ID {
+	ids := make([]protocol.ID, 0, len(compressionProtocols))
+	for _, cp := range compressionProtocols {
+		ids = append(ids, protocol.ID(c.protocolPrefix+cp.id))
+	}
+	return ids
+}
+
+// peerAlgorithm returns the preferred compression algorithm that the peer supports,
+// or false if it doesn't support any.
+func (c *streamCodec) peerAlgorithm(p peer.ID) (byte, bool) {
+	supported, err := c.peerstore.FirstSupportedProtocol(p, c.protocolIDs()...)
+	if err != nil || supported == "" {
+		return 0, false
+	}
+
+	for _, cp := range compressionProtocols {
+		if string(supported) == c.protocolPrefix+cp.id {
+			return cp.algorithm, true
+		}
+	}
+	return 0, false
+}
+
+// encode returns the encoded message to send to the peer, compressed if it is large
+// and the peer supports compression.
+func (c *streamCodec) encode(p peer.ID, msg common.Message) ([]byte, error) {
+	msgBytes, err := msg.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msgBytes) < compressionThreshold {
+		return msgBytes, nil
+	}
+
+	algorithm, ok := c.peerAlgorithm(p)
+	if !ok {
+		return msgBytes, nil
+	}
+
+	compressed := []byte{message.CompressedType, algorithm}
+	switch algorithm {
+	case compressionZstd:
+		compressed = c.zstdEncoder.EncodeAll(msgBytes, compressed)
+	case compressionSnappy:
+		compressed = append(compressed, snappy.Encode(nil, msgBytes)...)
+	}
+
+	if len(compressed) >= len(msgBytes) {
+		return msgBytes, nil
+	}
+	return compressed, nil
+}
+
+// decompress returns the message of a compressed envelope, or the message itself if
+// it is not compressed. The decompressed message can't be larger than maxSize.
+func (c *streamCodec) decompress(msgBytes []byte, maxSize uint32) ([]byte, error) {
+	if len(msgBytes) < 2 || msgBytes[0] != message.CompressedType {
+		return msgBytes, nil
+	}
+
+	algorithm, compressed := msgBytes[1], msgBytes[2:]
+	switch algorithm {
+	case compressionZstd:
+		decompressed, err := c.zstdDecoder.DecodeAll(compressed, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(decompressed) > int(maxSize) {
+			return nil, errMessageTooLarge
+		}
+		return decompressed, nil
+	case compressionSnappy:
+		size, err := snappy.DecodedLen(compressed)
+		if err != nil {
+			return nil, err
+		}
+		if size > int(maxSize) {
+			return nil, errMessageTooLarge
+		}
+		return snappy.Decode(nil, compressed)
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %d", algorithm)
+	}
+}
+
+// writeStreamMessage writes the message to the stream, compressed if the peer
+// supports it.
+func (c *streamCodec) writeStreamMessage(stream libp2pnetwork.Stream, msg common.Message) error {
+	p := stream.Conn().RemotePeer()
+	msgBytes, err := c.encode(p, msg)
+	if err != nil {
+		return err
+	}
+
+	return writeMessage(stream, &encodedMessage{msg: msg, encoded: msgBytes}, p)
+}
+
+// decode decodes the message, decompressing it first if it is compressed.
+func (c *streamCodec) decode(msgBytes []byte, maxSize uint32) (common.Message, error) {
+	msgBytes, err := c.decompress(msgBytes, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.DecodeMessage(msgBytes)
+}
+
+// readStreamMessage reads the next message from the stream, counting it as a
+// message of the stream's peer and protocol.
+func (c *streamCodec) readStreamMessage(stream libp2pnetwork.Stream, maxMessageSize uint32) (common.Message, error) {
+	msgBytes, err := readMessage(stream, maxMessageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := c.decode(msgBytes, maxMessageSize)
+	c.stats.recordMessage(stream.Conn().RemotePeer(), stream.Protocol(), err == nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidMessage, err)
+	}
+
+	return msg, nil
+}
+
+// nextStreamMessage returns a channel that will receive the next message from the stream.
+// if there is an error reading from the stream, the channel will be closed, thus
+// the received value will be nil.
+func (c *streamCodec) nextStreamMessage(stream libp2pnetwork.Stream, maxMessageSize uint32) <-chan common.Message {
+	ch := make(chan common.Message)
+	go func() {
+		for {
+			msg, err := c.readStreamMessage(stream, maxMessageSize)
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					log.Warnf("failed to read stream message: %s", err)
+				}
+				close(ch)
+				return
+			}
+
+			ch <- msg
+		}
+	}()
+
+	return ch
+}
+
+// close releases the resources of the zstd encoder and decoder.
+func (c *streamCodec) close() {
+	_ = c.zstdEncoder.Close()
+	c.zstdDecoder.Close()
+}
+
+// encodedMessage is a message whose encoding, possibly compressed, is already known.
+type encodedMessage struct {
+	msg     common.Message
+	encoded []byte
+}
+
+func (m *encodedMessage) String() string {
+	return m.msg.String()
+}
+
+func (m *encodedMessage) Encode() ([]byte, error) {
+	return m.encoded, nil
+}
+
+func (m *encodedMessage) Type() byte {
+	return m.msg.Type()
+}
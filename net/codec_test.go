@@ -0,0 +1,119 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoremem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+func newTestCodec(t *testing.T) *streamCodec {
+	ps, err := pstoremem.NewPeerstore()
+	require.NoError(t, err)
+	c, err := newStreamCodec("/testid", ps, newNetStats("/testid"))
+	require.NoError(t, err)
+	t.Cleanup(c.close)
+	return c
+}
+
+func newLargeQueryResponse(numOffers int) *QueryResponse {
+	offers := make([]*types.Offer, 0, numOffers)
+	for i := 0; i < numOffers; i++ {
+		offers = append(offers, newTestOffer())
+	}
+	return &QueryResponse{Offers: offers}
+}
+
+func TestStreamCodec_compression(t *testing.T) {
+	c := newTestCodec(t)
+	msg := newLargeQueryResponse(20)
+	uncompressed, err := msg.Encode()
+	require.NoError(t, err)
+
+	zstdPeer, snappyPeer, oldPeer := peer.ID("zstd"), peer.ID("snappy"), peer.ID("old")
+	require.NoError(t, c.peerstore.AddProtocols(zstdPeer, c.protocolIDs()...))
+	require.NoError(t, c.peerstore.AddProtocols(snappyPeer, protocol.ID("/testid/compression/snappy/1")))
+
+	for _, tc := range []struct {
+		peer      peer.ID
+		algorithm byte
+	}{
+		{zstdPeer, compressionZstd},
+		{snappyPeer, compressionSnappy},
+	} {
+		compressed, encodeErr := c.encode(tc.peer, msg)
+		require.NoError(t, encodeErr)
+		require.Equal(t, []byte{message.CompressedType, tc.algorithm}, compressed[:2])
+		require.Less(t, len(compressed), len(uncompressed))
+
+		decoded, decodeErr := c.decode(compressed, maxMessageSize)
+		require.NoError(t, decodeErr)
+		require.Equal(t, msg, decoded)
+	}
+
+	// peers that don't support compression receive uncompressed messages
+	encoded, err := c.encode(oldPeer, msg)
+	require.NoError(t, err)
+	require.Equal(t, uncompressed, encoded)
+
+	// as do all peers for small messages
+	small := &QueryResponse{Offers: []*types.Offer{}}
+	encoded, err = c.encode(zstdPeer, small)
+	require.NoError(t, err)
+	smallEncoded, err := small.Encode()
+	require.NoError(t, err)
+	require.Equal(t, smallEncoded, encoded)
+}
+
+func TestStreamCodec_decompressTooLarge(t *testing.T) {
+	c := newTestCodec(t)
+	msg := newLargeQueryResponse(20)
+	uncompressed, err := msg.Encode()
+	require.NoError(t, err)
+
+	compressed := append([]byte{message.CompressedType, compressionSnappy}, snappy.Encode(nil, uncompressed)...)
+	_, err = c.decompress(compressed, uint32(len(uncompressed)-1))
+	require.ErrorIs(t, err, errMessageTooLarge)
+
+	compressed = c.zstdEncoder.EncodeAll(uncompressed, []byte{message.CompressedType, compressionZstd})
+	_, err = c.decompress(compressed, uint32(len(uncompressed)-1))
+	require.ErrorIs(t, err, errMessageTooLarge)
+
+	_, err = c.decompress([]byte{message.CompressedType, 0xff, 1, 2, 3}, maxMessageSize)
+	require.ErrorContains(t, err, "unknown compression algorithm")
+}
+
+func TestHost_Query_compressed(t *testing.T) {
+	maker := newHost(t, basicTestConfig(t))
+	makerHandler := &mockOffersMakerHandler{mockMakerHandler: mockMakerHandler{t: t}}
+	maker.SetHandlers(makerHandler, &mockRelayHandler{t: t})
+	require.NoError(t, maker.Start())
+
+	taker := newHost(t, basicTestConfig(t))
+	require.NoError(t, taker.Start())
+
+	err := taker.h.Connect(taker.ctx, maker.h.AddrInfo())
+	require.NoError(t, err)
+
+	offers := newLargeQueryResponse(50).Offers
+	makerHandler.setOffers(offers...)
+
+	require.Eventually(t, func() bool {
+		algorithm, ok := maker.codec.peerAlgorithm(taker.PeerID())
+		return ok && algorithm == compressionZstd
+	}, time.Second*5, time.Millisecond*50)
+
+	resp, err := taker.Query(maker.PeerID(), nil)
+	require.NoError(t, err)
+	require.Equal(t, offers, resp.Offers)
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const (
+	maxPeers        = 50
+	connGracePeriod = time.Minute
+)
+
+var (
+	errInvalidConnWatermarks = errors.New("connection low watermark must be positive and below the high watermark")
+	errInvalidDialTimeout    = errors.New("dial timeout must be positive")
+	errInvalidStreamLimit    = errors.New("per-peer stream limit cannot be negative")
+)
+
+// ConnLimits bounds the connections of the host. Once we have more than HighWater
+// connections, the connection manager closes the connections of the least useful
+// peers until LowWater remain. The peers that we have ongoing swaps with are never
+// closed.
+type ConnLimits struct {
+	LowWater  int
+	HighWater int
+
+	// MaxStreamsPerPeer is the number of streams that each peer can have open with
+	// us, or the libp2p default if zero.
+	MaxStreamsPerPeer int
+
+	// DialTimeout bounds the time that connecting to a peer takes.
+	DialTimeout time.Duration
+}
+
+// DefaultConnLimits returns the default connection limits.
+func DefaultConnLimits() *ConnLimits {
+	return &ConnLimits{
+		LowWater:    minPeers,
+		HighWater:   maxPeers,
+		DialTimeout: connectionTimeout,
+	}
+}
+
+func (l *ConnLimits) validate() error {
+	if l.LowWater <= 0 || l.LowWater >= l.HighWater {
+		return fmt.Errorf("%w: low=%d high=%d", errInvalidConnWatermarks, l.LowWater, l.HighWater)
+	}
+	if l.MaxStreamsPerPeer < 0 {
+		return errInvalidStreamLimit
+	}
+	if l.DialTimeout <= 0 {
+		return errInvalidDialTimeout
+	}
+	return nil
+}
+
+// options returns the libp2p options applying the limits.
+func (l *ConnLimits) options() ([]libp2p.Option, error) {
+	cm, err := connmgr.NewConnManager(l.LowWater, l.HighWater, connmgr.WithGracePeriod(connGracePeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []libp2p.Option{
+		libp2p.ConnectionManager(cm),
+		libp2p.WithDialTimeout(l.DialTimeout),
+	}
+
+	if l.MaxStreamsPerPeer > 0 {
+		mgr, err := newStreamLimitedResourceManager(l.MaxStreamsPerPeer)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, libp2p.ResourceManager(mgr))
+	}
+
+	return opts, nil
+}
+
+// newStreamLimitedResourceManager returns a resource manager with the limits of the
+// default libp2p resource manager, except for the streams that each peer can have
+// open.
+func newStreamLimitedResourceManager(maxStreamsPerPeer int) (libp2pnetwork.ResourceManager, error) {
+	limits := rcmgr.DefaultLimits
+	libp2p.SetDefaultServiceLimits(&limits)
+
+	streams := rcmgr.LimitVal(maxStreamsPerPeer)
+	partial := rcmgr.PartialLimitConfig{
+		PeerDefault: rcmgr.ResourceLimits{
+			Streams:         streams,
+			StreamsInbound:  streams,
+			StreamsOutbound: streams,
+		},
+	}
+
+	return rcmgr.NewResourceManager(rcmgr.NewFixedLimiter(partial.Build(limits.AutoScale())))
+}
+
+// swapProtectionTag is the tag with which the peer of a swap is protected from
+// being closed by the connection manager, one per swap, as we can have several
+// swaps with the same peer.
+func swapProtectionTag(offerID types.Hash) string {
+	return "swap-" + offerID.String()
+}
+
+// ProtectPeer prevents the connection manager from closing our connections to the
+// peer until it is unprotected with the same tag.
+func (lh *libp2pHost) ProtectPeer(p peer.ID, tag string) {
+	lh.h.ConnManager().Protect(p, tag)
+}
+
+// UnprotectPeer removes the protection of the peer with the tag, and returns
+// whether the peer is still protected by other tags.
+func (lh *libp2pHost) UnprotectPeer(p peer.ID, tag string) bool {
+	return lh.h.ConnManager().Unprotect(p, tag)
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestConnLimits_validate(t *testing.T) {
+	require.NoError(t, DefaultConnLimits().validate())
+
+	limits := DefaultConnLimits()
+	limits.LowWater = limits.HighWater
+	require.ErrorIs(t, limits.validate(), errInvalidConnWatermarks)
+
+	limits = DefaultConnLimits()
+	limits.LowWater = 0
+	require.ErrorIs(t, limits.validate(), errInvalidConnWatermarks)
+
+	limits = DefaultConnLimits()
+	limits.MaxStreamsPerPeer = -1
+	require.ErrorIs(t, limits.validate(), errInvalidStreamLimit)
+
+	limits = DefaultConnLimits()
+	limits.DialTimeout = 0
+	require.ErrorIs(t, limits.validate(), errInvalidDialTimeout)
+}
+
+func TestNewHost_invalidConnLimits(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.ConnLimits = &ConnLimits{LowWater: 10, HighWater: 5, DialTimeout: time.Second}
+	_, err := NewHost(cfg)
+	require.ErrorIs(t, err, errInvalidConnWatermarks)
+}
+
+func TestHost_ConnLimits(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.ConnLimits = &ConnLimits{
+		LowWater:          1,
+		HighWater:         2,
+		MaxStreamsPerPeer: 16,
+		DialTimeout:       time.Second,
+	}
+	h1 := newHost(t, cfg)
+	h2 := newHost(t, basicTestConfig(t))
+	require.Equal(t, time.Second, h1.dialTimeout)
+
+	err := h1.h.Connect(h1.ctx, h2.AddrInfo())
+	require.NoError(t, err)
+
+	resp, err := h1.Query(h2.PeerID(), nil)
+	require.NoError(t, err)
+	require.Empty(t, resp.Offers)
+}
+
+func TestLibp2pHost_ProtectPeer(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+	lh := h1.h.(*libp2pHost)
+
+	err := h1.h.Connect(h1.ctx, h2.AddrInfo())
+	require.NoError(t, err)
+
+	tag1, tag2 := swapProtectionTag(types.Hash{1}), swapProtectionTag(types.Hash{2})
+	lh.ProtectPeer(h2.PeerID(), tag1)
+	lh.ProtectPeer(h2.PeerID(), tag2)
+	require.True(t, lh.h.ConnManager().IsProtected(h2.PeerID(), ""))
+
+	// the peer stays protected until all of its swaps end
+	require.True(t, lh.UnprotectPeer(h2.PeerID(), tag1))
+	require.False(t, lh.UnprotectPeer(h2.PeerID(), tag2))
+	require.False(t, lh.h.ConnManager().IsProtected(h2.PeerID(), ""))
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p-kad-dht/dual"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/discovery/routing"
+)
+
+const (
+	tryAdvertiseTimeout = time.Second * 30
+	defaultAdvertiseTTL = time.Minute * 5
+	minPeers            = 3
+)
+
+// discovery advertises the namespaces that we provide in the DHT, and finds the peers
+// that provide a namespace.
+type discovery struct {
+	ctx                  context.Context
+	h                    libp2phost.Host
+	dht                  *dual.DHT
+	rd                   *routing.RoutingDiscovery
+	bootnodes            *bootnodes
+	advertiseCh          chan struct{}
+	advertisedNamespaces func() []string
+}
+
+func newDiscovery(
+	ctx context.Context,
+	h libp2phost.Host,
+	dht *dual.DHT,
+	bootnodes *bootnodes,
+	advertisedNamespaces func() []string,
+) *discovery {
+	return &discovery{
+		ctx:                  ctx,
+		h:                    h,
+		dht:                  dht,
+		rd:                   routing.NewRoutingDiscovery(dht),
+		bootnodes:            bootnodes,
+		advertiseCh:          make(chan struct{}, 1),
+		advertisedNamespaces: advertisedNamespaces,
+	}
+}
+
+// start connects to the bootnodes and starts advertising our namespaces.
+func (d *discovery) start() error {
+	d.connectBootnodes()
+
+	if err := d.dht.Bootstrap(d.ctx); err != nil {
+		return err
+	}
+
+	go d.advertiseLoop()
+	return nil
+}
+
+// advertise signals the advertise loop to advertise our namespaces now.
+func (d *discovery) advertise() {
+	select {
+	case d.advertiseCh <- struct{}{}:
+	default:
+		// an advertisement is already pending
+	}
+}
+
+func (d *discovery) advertiseLoop() {
+	ttl := d.advertiseNamespaces()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-d.advertiseCh:
+			ttl = d.advertiseNamespaces()
+		case <-time.After(ttl):
+			ttl = d.advertiseNamespaces()
+		}
+	}
+}
+
+// advertiseNamespaces advertises our namespaces, and returns when they should be
+// advertised again.
+func (d *discovery) advertiseNamespaces() time.Duration {
+	if len(d.h.Network().Peers()) < minPeers {
+		d.connectBootnodes()
+	}
+
+	for _, ns := range d.advertisedNamespaces() {
+		log.Debugf("advertising in namespace %q", ns)
+		if _, err := d.rd.Advertise(d.ctx, ns); err != nil {
+			log.Debugf("failed to advertise in namespace %q: %s", ns, err)
+			return tryAdvertiseTimeout
+		}
+	}
+
+	return defaultAdvertiseTTL
+}
+
+// connectBootnodes connects to the bootnodes that we are not connected to.
+func (d *discovery) connectBootnodes() {
+	for _, bn := range d.bootnodes.addrInfos(d.ctx) {
+		if d.h.Network().Connectedness(bn.ID) == libp2pnetwork.Connected {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(d.ctx, connectionTimeout)
+		err := d.h.Connect(ctx, bn)
+		cancel()
+		if err != nil {
+			log.Debugf("failed to connect to bootnode %s: %s", bn.ID, err)
+		}
+	}
+}
+
+// findPeers returns the peers providing the namespace that it finds within the search
+// time, connecting to them.
+func (d *discovery) findPeers(ns string, searchTime time.Duration) ([]peer.ID, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, searchTime)
+	defer cancel()
+
+	log.Debugf("attempting to find DHT peers that provide [%s]...", ns)
+	peerCh, err := d.rd.FindPeers(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []peer.ID
+	for {
+		select {
+		case <-ctx.Done():
+			return peers, nil
+		case ai, ok := <-peerCh:
+			if !ok {
+				return peers, nil
+			}
+
+			if ai.ID == d.h.ID() || len(ai.Addrs) == 0 {
+				continue
+			}
+
+			log.Debugf("found new peer via DHT: %s", ai)
+			if err = d.h.Connect(ctx, ai); err != nil {
+				log.Debugf("failed to connect to discovered peer %s: %s", ai.ID, err)
+				continue
+			}
+
+			peers = append(peers, ai.ID)
+		}
+	}
+}
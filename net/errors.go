@@ -8,8 +8,12 @@ import (
 )
 
 var (
-	errBootnodeCannotRelay   = errors.New("bootnode cannot be a relayer")
-	errNilHandler            = errors.New("handler is nil")
-	errNoOngoingSwap         = errors.New("no swap currently happening")
-	errSwapAlreadyInProgress = errors.New("already have ongoing swap")
+	errBootnodeCannotRelay = errors.New("bootnode cannot be a relayer")
+	errNilHandler          = errors.New("handler is nil")
+	errNoOngoingSwap       = errors.New("no swap currently happening")
+	errInvalidMessage      = errors.New("invalid message")
+
+	// ErrSwapAlreadyInProgress is returned when initiating a swap with a peer while
+	// another swap is being initiated.
+	ErrSwapAlreadyInProgress = errors.New("already have ongoing swap")
 )
@@ -1,19 +1,21 @@
 // Copyright 2023 The AthanorLabs/atomic-swap Authors
 // SPDX-License-Identifier: LGPL-3.0-only
 
-// Package net adds swap-specific functionality to go-p2p-net/Host,
-// in particular the swap messages for querying and initiation.
+// Package net implements the libp2p host of swapd, which discovers peers through a
+// DHT, and adds swap-specific functionality, in particular the swap messages for
+// querying and initiation.
 package net
 
 import (
 	"context"
 	"errors"
-	"io"
+	"fmt"
 	"sync"
 	"time"
 
-	p2pnet "github.com/athanorlabs/go-p2p-net"
 	logging "github.com/ipfs/go-log"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/crypto"
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
@@ -22,6 +24,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/metrics"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
@@ -45,6 +48,7 @@ type P2pHost interface {
 	Discover(provides string, searchTime time.Duration) ([]peer.ID, error)
 
 	SetStreamHandler(string, func(libp2pnetwork.Stream))
+	JoinTopic(topic string, validator pubsub.ValidatorEx) (*pubsub.Topic, error)
 
 	Connectedness(peer.ID) libp2pnetwork.Connectedness
 	Connect(context.Context, peer.AddrInfo) error
@@ -53,20 +57,44 @@ type P2pHost interface {
 	AddrInfo() peer.AddrInfo
 	Addresses() []ma.Multiaddr
 	PeerID() peer.ID
+	PrivKey() crypto.PrivKey
 	ConnectedPeers() []string
+	NATStatus() *NATStatus
+
+	BanPeer(p peer.ID, duration time.Duration, reason string) error
+	UnbanPeer(p peer.ID) error
+	BannedPeers() []*BannedPeer
+	ReportMisbehavior(p peer.ID, penalty int, reason string)
+
+	ProtectPeer(p peer.ID, tag string)
+	UnprotectPeer(p peer.ID, tag string) bool
 }
 
 // Host represents a p2p node that implements the atomic swap protocol.
 type Host struct {
-	ctx       context.Context
-	h         P2pHost
-	isRelayer bool
+	ctx         context.Context
+	h           P2pHost
+	isRelayer   bool
+	version     string
+	dialTimeout time.Duration
+	stats       *netStats
+	codec       *streamCodec
 
 	// set to true if the node is a bootnode-only node
 	isBootnode bool
 
 	makerHandler MakerHandler
 	relayHandler RelayHandler
+	relayLimiter *relayLimiter
+	// limits relay fee quote requests separately from claim requests
+	relayQuoteLimiter *relayLimiter
+	offerGossip       *offerGossip
+
+	// restricts whose claims we relay and which relayers we submit claims to,
+	// saved to relayAccessFile if set
+	relayAccessMu   sync.RWMutex
+	relayAccess     *types.RelayAccessList
+	relayAccessFile string
 
 	// swap instance info
 	swapMu sync.RWMutex
@@ -81,9 +109,57 @@ type Config struct {
 	KeyFile        string
 	Bootnodes      []string
 	ProtocolID     string
+	Version        string // swapd version that we advertise to peers
 	ListenIP       string
 	IsRelayer      bool
 	IsBootnodeOnly bool
+	RelayLimits    *RelayLimits // optional, DefaultRelayLimits() if nil
+	ConnLimits     *ConnLimits  // optional, DefaultConnLimits() if nil
+
+	// RelayAccessListFile is the JSON file with the relay access list. It is
+	// optional, without it the list is empty and changes to it are not saved.
+	RelayAccessListFile string
+
+	// Proxy, if set, is the SOCKS5 proxy that peers are dialed through. The host then
+	// only listens on the loopback interface, and only advertises the address of its
+	// onion service, so that its IP addresses are not revealed to peers.
+	Proxy *common.Proxy
+
+	// TorControl, if set, configures the Tor daemon that creates the onion service
+	// that peers reach the host at. It requires Proxy.
+	TorControl *TorControlConfig
+
+	// NoPortMapping disables forwarding the listening port on the router with UPnP
+	// or NAT-PMP, which is otherwise done when listening on a non-loopback address
+	// without a proxy.
+	NoPortMapping bool
+
+	// MDNS enables finding and being found by the hosts on the local network with
+	// multicast DNS, without bootnodes. It can't be used with Proxy.
+	MDNS bool
+
+	// StaticPeers are the addresses, with peer IDs, of the peers that the host always
+	// stays connected to, like our own relayer or a partner desk. The host reconnects
+	// to them when disconnected, and the connection manager never closes their
+	// connections.
+	StaticPeers []string
+
+	// ListenAddrs, if set, are the multiaddrs that the host listens on instead of
+	// ListenIP and Port, like "/ip6/::/tcp/9900" or "/ip4/0.0.0.0/udp/9900/quic-v1".
+	// It can't be used with Proxy.
+	ListenAddrs []string
+
+	// AnnounceAddrs are the multiaddrs that the host advertises to peers in addition
+	// to its listen addresses and the addresses that peers observed it at, like the
+	// public address of a port forwarded by hand. It can't be used with Proxy.
+	AnnounceAddrs []string
+}
+
+func (cfg *Config) connLimits() *ConnLimits {
+	if cfg.ConnLimits == nil {
+		return DefaultConnLimits()
+	}
+	return cfg.ConnLimits
 }
 
 // NewHost returns a new Host.
@@ -94,29 +170,56 @@ func NewHost(cfg *Config) (*Host, error) {
 		return nil, errBootnodeCannotRelay
 	}
 
+	relayLimits := cfg.RelayLimits
+	if relayLimits == nil {
+		relayLimits = DefaultRelayLimits()
+	}
+
+	connLimits := cfg.connLimits()
+	if err := connLimits.validate(); err != nil {
+		return nil, err
+	}
+
+	relayAccess := new(types.RelayAccessList)
+	if cfg.RelayAccessListFile != "" {
+		var err error
+		relayAccess, err = ReadRelayAccessListFile(cfg.RelayAccessListFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	h := &Host{
-		ctx:        cfg.Ctx,
-		h:          nil, // set below
-		isRelayer:  cfg.IsRelayer,
-		isBootnode: cfg.IsBootnodeOnly,
-		swaps:      make(map[types.Hash]*swap),
+		ctx:               cfg.Ctx,
+		h:                 nil, // set below
+		isRelayer:         cfg.IsRelayer,
+		version:           cfg.Version,
+		isBootnode:        cfg.IsBootnodeOnly,
+		dialTimeout:       connLimits.DialTimeout,
+		relayLimiter:      newRelayLimiter(relayLimits),
+		relayQuoteLimiter: newRelayLimiter(relayLimits),
+		relayAccess:       relayAccess,
+		relayAccessFile:   cfg.RelayAccessListFile,
+		swaps:             make(map[types.Hash]*swap),
+	}
+
+	lh, err := newLibp2pHost(cfg, h.advertisedNamespaces)
+	if err != nil {
+		return nil, err
 	}
+	h.h = lh
+	h.stats = lh.stats
+	h.codec = lh.codec
 
-	var err error
-	h.h, err = p2pnet.NewHost(&p2pnet.Config{
-		Ctx:                      cfg.Ctx,
-		DataDir:                  cfg.DataDir,
-		Port:                     cfg.Port,
-		KeyFile:                  cfg.KeyFile,
-		Bootnodes:                cfg.Bootnodes,
-		ProtocolID:               cfg.ProtocolID,
-		ListenIP:                 cfg.ListenIP,
-		AdvertisedNamespacesFunc: h.advertisedNamespaces,
-	})
+	h.offerGossip, err = newOfferGossip(cfg.Ctx, h.h, h.publishedOffers)
 	if err != nil {
+		_ = h.h.Stop()
 		return nil, err
 	}
 
+	metrics.SetPeerCounter(func() int { return len(h.h.ConnectedPeers()) })
+	metrics.SetBandwidthReporter(h.stats.bandwidthByProtocol)
+
 	log.Debugf("using base protocol %s", cfg.ProtocolID)
 	return h, nil
 }
@@ -135,6 +238,15 @@ func (h *Host) advertisedNamespaces() []string {
 	return provides
 }
 
+// publishedOffers returns the offers that we publish on the offers topic. Bootnodes
+// don't make offers, but relay the offers of the makers.
+func (h *Host) publishedOffers() []*types.Offer {
+	if h.isBootnode {
+		return nil
+	}
+	return h.makerHandler.GetOffers()
+}
+
 // SetHandlers sets the maker and taker instances used by the host, and configures
 // the stream handlers.
 func (h *Host) SetHandlers(makerHandler MakerHandler, relayHandler RelayHandler) {
@@ -142,8 +254,11 @@ func (h *Host) SetHandlers(makerHandler MakerHandler, relayHandler RelayHandler)
 	h.relayHandler = relayHandler
 
 	h.h.SetStreamHandler(queryProtocolID, h.handleQueryStream)
+	h.h.SetStreamHandler(filteredQueryProtocolID, h.handleFilteredQueryStream)
 	h.h.SetStreamHandler(relayProtocolID, h.handleRelayStream)
+	h.h.SetStreamHandler(relayQuoteProtocolID, h.handleRelayQuoteStream)
 	h.h.SetStreamHandler(swapID, h.handleProtocolStream)
+	h.h.SetStreamHandler(capabilitiesProtocolID, h.handleCapabilitiesStream)
 }
 
 // Start starts the bootstrap and discovery process.
@@ -157,12 +272,13 @@ func (h *Host) Start() error {
 		return err
 	}
 
+	h.offerGossip.start()
 	return nil
 }
 
 // Stop stops the host.
 func (h *Host) Stop() error {
-	return h.h.Stop()
+	return errors.Join(h.offerGossip.Close(), h.h.Stop())
 }
 
 // SendSwapMessage sends a message to the peer who we're currently doing a swap with.
@@ -175,7 +291,7 @@ func (h *Host) SendSwapMessage(msg Message, id types.Hash) error {
 		return errNoOngoingSwap
 	}
 
-	return p2pnet.WriteStreamMessage(swap.stream, msg, swap.stream.Conn().RemotePeer())
+	return h.codec.writeStreamMessage(swap.stream, msg)
 }
 
 // CloseProtocolStream closes the current swap protocol stream.
@@ -193,10 +309,17 @@ func (h *Host) CloseProtocolStream(offerID types.Hash) {
 	_ = swap.stream.Close()
 }
 
-// Advertise advertises the namespaces now instead of waiting for the next periodic
-// update. We use it when a new advertised namespace is added.
+// Advertise advertises the namespaces and publishes our offers now instead of
+// waiting for the next periodic update. We use it when our offers change.
 func (h *Host) Advertise() {
 	h.h.Advertise()
+	h.offerGossip.publish()
+}
+
+// GossipedOffers returns the offers that makers published on the offers topic
+// within the last few minutes, by maker.
+func (h *Host) GossipedOffers() map[peer.ID][]*types.Offer {
+	return h.offerGossip.offers()
 }
 
 // Discover searches the DHT for peers that advertise that they provide the given coin..
@@ -225,34 +348,39 @@ func (h *Host) PeerID() peer.ID {
 	return h.h.AddrInfo().ID
 }
 
-func readStreamMessage(stream libp2pnetwork.Stream, maxMessageSize uint32) (common.Message, error) {
-	msgBytes, err := p2pnet.ReadStreamMessage(stream, maxMessageSize)
-	if err != nil {
-		return nil, err
-	}
+// NATStatus returns whether the host is publicly reachable, and its relay addresses
+// if it is not.
+func (h *Host) NATStatus() *NATStatus {
+	return h.h.NATStatus()
+}
 
-	return message.DecodeMessage(msgBytes)
+// BanPeer bans the peer for the duration, or permanently if the duration is zero,
+// so that we don't connect to it and it can't connect to us.
+func (h *Host) BanPeer(p peer.ID, duration time.Duration, reason string) error {
+	return h.h.BanPeer(p, duration, reason)
 }
 
-// nextStreamMessage returns a channel that will receive the next message from the stream.
-// if there is an error reading from the stream, the channel will be closed, thus
-// the received value will be nil.
-func nextStreamMessage(stream libp2pnetwork.Stream, maxMessageSize uint32) <-chan common.Message {
-	ch := make(chan common.Message)
-	go func() {
-		for {
-			msg, err := readStreamMessage(stream, maxMessageSize)
-			if err != nil {
-				if !errors.Is(err, io.EOF) {
-					log.Warnf("failed to read stream message: %s", err)
-				}
-				close(ch)
-				return
-			}
-
-			ch <- msg
-		}
-	}()
+// UnbanPeer removes the ban of the peer, if it is banned.
+func (h *Host) UnbanPeer(p peer.ID) error {
+	return h.h.UnbanPeer(p)
+}
+
+// BannedPeers returns the banned peers, sorted by peer ID.
+func (h *Host) BannedPeers() []*BannedPeer {
+	return h.h.BannedPeers()
+}
+
+// reportInvalidMessage adds the penalty of an invalid message to the misbehavior
+// score of the peer, if the error is due to the peer sending an invalid message.
+func (h *Host) reportInvalidMessage(p peer.ID, err error) {
+	if errors.Is(err, errInvalidMessage) {
+		h.h.ReportMisbehavior(p, InvalidMessagePenalty, err.Error())
+	}
+}
 
-	return ch
+// reportWrongMessageType adds the penalty of an invalid message to the misbehavior
+// score of the peer, which sent a message of the wrong type for the stream.
+func (h *Host) reportWrongMessageType(p peer.ID, msg common.Message, protocolID protocol.ID) {
+	reason := fmt.Sprintf("wrong message type=%s sent to %s stream", message.TypeToString(msg.Type()), protocolID)
+	h.h.ReportMisbehavior(p, InvalidMessagePenalty, reason)
 }
@@ -5,6 +5,7 @@ package net
 
 import (
 	"context"
+	"math/big"
 	"path"
 	"testing"
 
@@ -25,6 +26,8 @@ func init() {
 var (
 	testID        = types.Hash{99}
 	mockEthTXHash = ethcommon.Hash{33}
+
+	mockRelayFeeWei = big.NewInt(5e15)
 )
 
 type mockMakerHandler struct {
@@ -56,6 +59,31 @@ func (h *mockRelayHandler) HandleRelayClaimRequest(_ *RelayClaimRequest) (*Relay
 	}, nil
 }
 
+func (h *mockRelayHandler) HandleRelayRefundRequest(_ *RelayRefundRequest) (*RelayClaimResponse, error) {
+	return &RelayClaimResponse{
+		TxHash: mockEthTXHash,
+	}, nil
+}
+
+func (h *mockRelayHandler) HandleRelayNewSwapRequest(_ *RelayNewSwapRequest) (*RelayClaimResponse, error) {
+	return &RelayClaimResponse{
+		TxHash: mockEthTXHash,
+	}, nil
+}
+
+func (h *mockRelayHandler) HandleRelaySetReadyRequest(_ *RelaySetReadyRequest) (*RelayClaimResponse, error) {
+	return &RelayClaimResponse{
+		TxHash: mockEthTXHash,
+	}, nil
+}
+
+func (h *mockRelayHandler) HandleRelayFeeQuoteRequest(_ *RelayFeeQuoteRequest) (*RelayFeeQuote, error) {
+	return &RelayFeeQuote{
+		FeeWei:     mockRelayFeeWei,
+		ETASeconds: 12,
+	}, nil
+}
+
 type mockSwapState struct {
 	offerID types.Hash
 }
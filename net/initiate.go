@@ -10,7 +10,6 @@ import (
 	"io"
 	"time"
 
-	p2pnet "github.com/athanorlabs/go-p2p-net"
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
@@ -32,10 +31,10 @@ func (h *Host) Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s com
 	id := s.OfferID()
 
 	if h.swaps[id] != nil {
-		return errSwapAlreadyInProgress
+		return ErrSwapAlreadyInProgress
 	}
 
-	ctx, cancel := context.WithTimeout(h.ctx, connectionTimeout)
+	ctx, cancel := context.WithTimeout(h.ctx, h.dialTimeout)
 	defer cancel()
 
 	if h.h.Connectedness(who.ID) != libp2pnetwork.Connected {
@@ -54,7 +53,7 @@ func (h *Host) Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s com
 		"opened protocol stream, peer=", who.ID,
 	)
 
-	if err := p2pnet.WriteStreamMessage(stream, sendKeysMessage, who.ID); err != nil {
+	if err := h.codec.writeStreamMessage(stream, sendKeysMessage); err != nil {
 		log.Warnf("failed to send initial SendKeysMessage to peer: err=%s", err)
 		return err
 	}
@@ -64,6 +63,7 @@ func (h *Host) Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s com
 		stream:    stream,
 		isTaker:   true,
 	}
+	h.h.ProtectPeer(who.ID, swapProtectionTag(id))
 
 	go h.receiveInitiateResponse(stream, s)
 	return nil
@@ -75,7 +75,7 @@ func (h *Host) receiveInitiateResponse(stream libp2pnetwork.Stream, s SwapState)
 	const initiateResponseTimeout = time.Minute
 
 	select {
-	case msg := <-nextStreamMessage(stream, maxMessageSize):
+	case msg := <-h.codec.nextStreamMessage(stream, maxMessageSize):
 		if msg == nil {
 			log.Errorf("failed to read initial SendKeysMessage response")
 			return
@@ -104,24 +104,26 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 		return
 	}
 
-	msg, err := readStreamMessage(stream, maxMessageSize)
+	curPeer := stream.Conn().RemotePeer()
+
+	msg, err := h.codec.readStreamMessage(stream, maxMessageSize)
 	if err != nil {
 		if errors.Is(err, io.EOF) {
 			log.Debugf("Peer closed stream-id=%s, protocol exited", stream.ID())
 		} else {
 			log.Debugf("Failed to read message from peer, stream-id=%s: %s", stream.ID(), err)
+			h.reportInvalidMessage(curPeer, err)
 		}
 		_ = stream.Close()
 		return
 	}
 
-	curPeer := stream.Conn().RemotePeer()
-
 	log.Debugf("received message from peer=%s type=%s", curPeer, message.TypeToString(msg.Type()))
 
 	im, ok := msg.(*SendKeysMessage)
 	if !ok {
 		log.Warnf("failed to handle protocol message: message was not SendKeysMessage")
+		h.reportWrongMessageType(curPeer, msg, stream.Protocol())
 		_ = stream.Close()
 		return
 	}
@@ -134,7 +136,7 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 		return
 	}
 
-	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
+	if err := h.codec.writeStreamMessage(stream, resp); err != nil {
 		log.Warnf("failed to send response to peer: %s", err)
 		if err = s.Exit(); err != nil {
 			log.Warnf("Swap exit failure: %s", err)
@@ -150,6 +152,7 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 		isTaker:   false,
 	}
 	h.swapMu.Unlock()
+	h.h.ProtectPeer(curPeer, swapProtectionTag(s.OfferID()))
 
 	h.handleProtocolStreamInner(stream, s)
 }
@@ -159,13 +162,14 @@ func (h *Host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapStat
 	defer h.handleProtocolStreamClose(stream, s)
 
 	for {
-		msg, err := readStreamMessage(stream, maxMessageSize)
+		msg, err := h.codec.readStreamMessage(stream, maxMessageSize)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				log.Debug("Peer closed stream with us, protocol exited")
 			} else {
 				log.Debugf("Failed to read message from peer, id=%s protocol=%s: %s",
 					stream.ID(), stream.Protocol(), err)
+				h.reportInvalidMessage(stream.Conn().RemotePeer(), err)
 			}
 			return
 		}
@@ -192,4 +196,5 @@ func (h *Host) handleProtocolStreamClose(stream libp2pnetwork.Stream, s SwapStat
 	h.swapMu.Lock()
 	delete(h.swaps, s.OfferID())
 	h.swapMu.Unlock()
+	h.h.UnprotectPeer(stream.Conn().RemotePeer(), swapProtectionTag(s.OfferID()))
 }
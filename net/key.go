@@ -0,0 +1,63 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+// loadOrGenerateKey returns the libp2p identity key of the hex key file, generating
+// the key and writing the file if it doesn't exist.
+func loadOrGenerateKey(keyFile string) (crypto.PrivKey, error) {
+	exists, err := common.FileExists(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return loadKey(keyFile)
+	}
+
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = saveKey(key, keyFile); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func loadKey(keyFile string) (crypto.PrivKey, error) {
+	keyHex, err := os.ReadFile(filepath.Clean(keyFile))
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.UnmarshalEd25519PrivateKey(keyBytes)
+}
+
+func saveKey(key crypto.PrivKey, keyFile string) error {
+	keyBytes, err := key.Raw()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Clean(keyFile), []byte(hex.EncodeToString(keyBytes)), 0600)
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrGenerateKey(t *testing.T) {
+	keyFile := path.Join(t.TempDir(), "node.key")
+
+	// the key is generated and saved when the key file doesn't exist
+	key, err := loadOrGenerateKey(keyFile)
+	require.NoError(t, err)
+	require.FileExists(t, keyFile)
+
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// then it is loaded from the key file
+	loaded, err := loadOrGenerateKey(keyFile)
+	require.NoError(t, err)
+	require.True(t, key.Equals(loaded))
+}
+
+func TestLoadKey_trailingNewline(t *testing.T) {
+	keyFile := path.Join(t.TempDir(), "node.key")
+	key, err := loadOrGenerateKey(keyFile)
+	require.NoError(t, err)
+
+	keyHex, err := os.ReadFile(keyFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, append(keyHex, '\n'), 0600))
+
+	loaded, err := loadKey(keyFile)
+	require.NoError(t, err)
+	require.True(t, key.Equals(loaded))
+}
+
+func TestLoadKey_invalid(t *testing.T) {
+	keyFile := path.Join(t.TempDir(), "node.key")
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("not hex"), 0600))
+	_, err := loadKey(keyFile)
+	require.Error(t, err)
+
+	// valid hex, but not an ed25519 key
+	require.NoError(t, os.WriteFile(keyFile, []byte("deadbeef"), 0600))
+	_, err = loadKey(keyFile)
+	require.Error(t, err)
+}
@@ -0,0 +1,466 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	badger "github.com/ipfs/go-ds-badger2"
+	"github.com/libp2p/go-libp2p"
+	kaddht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p-kad-dht/dual"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/event"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoreds"
+	libp2pquic "github.com/libp2p/go-libp2p/p2p/transport/quic"
+	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+const (
+	datastoreDirName = "libp2p-datastore"
+)
+
+var errOnionWithoutProxy = errors.New("an onion service requires a proxy")
+
+// libp2pHost is the P2pHost of a Host. It finds the peers providing a namespace
+// through a DHT, which it joins through the bootnodes. The protocol IDs of its
+// streams are prefixed with the host's protocol ID.
+type libp2pHost struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	protocolID  string
+	dialTimeout time.Duration
+	h           libp2phost.Host
+	ds          *badger.Datastore
+	dht         *dual.DHT
+	discovery   *discovery
+	ps          *pubsub.PubSub
+	onion       *onionService // nil if the host has no onion service
+	mdns        *mdnsService  // nil if mDNS discovery is disabled
+	bootnodes   *bootnodes
+	staticPeers []peer.AddrInfo // peers that we always stay connected to
+	bans        *banList
+	stats       *netStats
+	codec       *streamCodec
+
+	// hostReady is closed once h is set, as the relay candidates are requested by
+	// libp2p from a background goroutine started while creating the host
+	hostReady    chan struct{}
+	reachability atomic.Int32 // libp2pnetwork.Reachability found by AutoNAT
+}
+
+var _ P2pHost = (*libp2pHost)(nil)
+
+// newLibp2pHost returns the libp2p host of the configuration, advertising the
+// namespaces returned by advertisedNamespaces.
+func newLibp2pHost(cfg *Config, advertisedNamespaces func() []string) (*libp2pHost, error) {
+	if cfg.TorControl != nil && cfg.Proxy == nil {
+		return nil, errOnionWithoutProxy
+	}
+	if cfg.MDNS && cfg.Proxy != nil {
+		return nil, errMDNSWithProxy
+	}
+	if len(cfg.ListenAddrs) > 0 && cfg.Proxy != nil {
+		return nil, errListenAddrsWithProxy
+	}
+	if len(cfg.AnnounceAddrs) > 0 && cfg.Proxy != nil {
+		return nil, errAnnounceAddrsWithProxy
+	}
+
+	key, err := loadOrGenerateKey(cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	bootnodes, err := parseBootnodes(cfg.Bootnodes)
+	if err != nil {
+		return nil, err
+	}
+
+	staticPeers, err := parseStaticPeers(cfg.StaticPeers)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(cfg.Ctx)
+	lh := &libp2pHost{
+		ctx:         ctx,
+		cancel:      cancel,
+		protocolID:  cfg.ProtocolID,
+		dialTimeout: cfg.connLimits().DialTimeout,
+		stats:       newNetStats(cfg.ProtocolID),
+		bootnodes:   bootnodes,
+		staticPeers: staticPeers,
+		hostReady:   make(chan struct{}),
+	}
+
+	if err = lh.init(cfg, key, advertisedNamespaces); err != nil {
+		_ = lh.Stop()
+		return nil, err
+	}
+
+	log.Infof("peer ID %s listening on %s", lh.h.ID(), lh.Addresses())
+	return lh, nil
+}
+
+func (lh *libp2pHost) init(
+	cfg *Config,
+	key crypto.PrivKey,
+	advertisedNamespaces func() []string,
+) error {
+	listenIP := cfg.ListenIP
+	if cfg.Proxy != nil {
+		// peers reach us through the onion service, if we have one
+		listenIP = "127.0.0.1"
+	}
+
+	port := uint(cfg.Port)
+	if cfg.TorControl != nil {
+		// the onion service is created before we listen, so it must know our port
+		var err error
+		if port == 0 {
+			port, err = common.GetFreeTCPPort()
+			if err != nil {
+				return err
+			}
+		}
+
+		keyFile := filepath.Join(cfg.DataDir, onionKeyFileName)
+		lh.onion, err = newOnionService(cfg.TorControl, keyFile, strconv.FormatUint(uint64(port), 10))
+		if err != nil {
+			return err
+		}
+	}
+
+	psk, err := loadSwarmKey(cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	if psk != nil {
+		log.Infof("private network enabled, only connecting to peers with our swarm key")
+	}
+
+	// QUIC is UDP, which SOCKS5 proxies like Tor don't carry, and libp2p doesn't
+	// support private networks over QUIC
+	useQUIC := cfg.Proxy == nil && psk == nil
+	var listenAddrs []ma.Multiaddr
+	if len(cfg.ListenAddrs) > 0 {
+		listenAddrs, err = parseListenAddrs(cfg.ListenAddrs, useQUIC)
+	} else {
+		listenAddrs, err = getListenAddrs(listenIP, port, useQUIC)
+	}
+	if err != nil {
+		return err
+	}
+
+	announceAddrs, err := parseAnnounceAddrs(cfg.AnnounceAddrs)
+	if err != nil {
+		return err
+	}
+
+	lh.ds, err = badger.NewDatastore(filepath.Join(cfg.DataDir, datastoreDirName), &badger.DefaultOptions)
+	if err != nil {
+		return err
+	}
+
+	ps, err := pstoreds.NewPeerstore(lh.ctx, lh.ds, pstoreds.DefaultOpts())
+	if err != nil {
+		return err
+	}
+
+	lh.bans, err = newBanList(lh.ctx, lh.ds)
+	if err != nil {
+		return err
+	}
+
+	lh.codec, err = newStreamCodec(cfg.ProtocolID, ps, lh.stats)
+	if err != nil {
+		return err
+	}
+
+	connOpts, err := cfg.connLimits().options()
+	if err != nil {
+		return err
+	}
+
+	opts := []libp2p.Option{
+		libp2p.ListenAddrs(listenAddrs...),
+		libp2p.Identity(key),
+		libp2p.Peerstore(ps),
+		libp2p.ConnectionGater(lh.bans),
+		libp2p.BandwidthReporter(lh.stats.bwc),
+	}
+	opts = append(opts, connOpts...)
+	if psk != nil {
+		opts = append(opts, libp2p.PrivateNetwork(psk))
+	}
+
+	if cfg.Proxy == nil {
+		opts = append(opts, libp2p.Transport(tcp.NewTCPTransport))
+		if useQUIC {
+			opts = append(opts, libp2p.Transport(libp2pquic.NewTransport))
+		}
+		opts = append(opts, natTraversalOptions(lh.relayCandidates)...)
+		if !cfg.NoPortMapping && hasPublicListenAddr(listenAddrs) {
+			opts = append(opts, libp2p.NATPortMap())
+		}
+		if len(announceAddrs) > 0 {
+			opts = append(opts, libp2p.AddrsFactory(announceAddrsFactory(announceAddrs)))
+		}
+	} else {
+		opts = append(opts, libp2p.DisableRelay())
+		opts = append(opts, proxyOptions(cfg.Proxy, lh.onionAddrs)...)
+	}
+
+	lh.h, err = libp2p.New(opts...)
+	if err != nil {
+		return err
+	}
+	close(lh.hostReady)
+	go lh.stats.trimIdle(lh.ctx)
+
+	// peers learn the compression algorithms that we support through identify
+	for _, id := range lh.codec.protocolIDs() {
+		lh.h.SetStreamHandler(id, func(stream libp2pnetwork.Stream) { _ = stream.Reset() })
+	}
+
+	reachabilitySub, err := lh.h.EventBus().Subscribe(new(event.EvtLocalReachabilityChanged))
+	if err != nil {
+		return err
+	}
+	go lh.watchReachability(reachabilitySub)
+
+	if err = lh.watchRecentPeers(); err != nil {
+		return err
+	}
+
+	lh.SetStreamHandler(peerExchangeProtocolID, lh.handlePeerExchangeStream)
+
+	lh.ps, err = pubsub.NewGossipSub(lh.ctx, lh.h)
+	if err != nil {
+		return err
+	}
+
+	lh.dht, err = dual.New(lh.ctx, lh.h,
+		dual.DHTOption(
+			kaddht.Mode(kaddht.ModeAutoServer),
+			kaddht.BootstrapPeers(lh.bootnodes.addrInfos(lh.ctx)...),
+			kaddht.ProtocolPrefix(protocol.ID(cfg.ProtocolID)),
+			kaddht.Datastore(lh.ds),
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	lh.discovery = newDiscovery(lh.ctx, lh.h, lh.dht, lh.bootnodes, advertisedNamespaces)
+
+	if cfg.MDNS {
+		lh.mdns, err = newMDNSService(lh.ctx, lh.h, lh.connectLocalPeer)
+		if err != nil {
+			return fmt.Errorf("failed to start mDNS discovery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// connectLocalPeer connects to the peer on the local network found by mDNS, after
+// which it is found by DHT searches.
+func (lh *libp2pHost) connectLocalPeer(ai peer.AddrInfo) {
+	if lh.h.Network().Connectedness(ai.ID) == libp2pnetwork.Connected {
+		return
+	}
+
+	log.Debugf("found local peer via mDNS: %s", ai)
+	go func() {
+		ctx, cancel := context.WithTimeout(lh.ctx, lh.dialTimeout)
+		defer cancel()
+		if err := lh.h.Connect(ctx, ai); err != nil {
+			log.Debugf("failed to connect to local peer %s: %s", ai.ID, err)
+		}
+	}()
+}
+
+// getListenAddrs returns the TCP address, and the QUIC address if enabled, that the
+// host listens on. When listening on all the IPv4 interfaces, the host also listens on
+// all the IPv6 interfaces. The addresses use the port, unless it is zero, in which
+// case the OS picks a random port for each.
+func getListenAddrs(ip string, port uint, quic bool) ([]ma.Multiaddr, error) {
+	ips := []string{ip}
+	if ip == unspecifiedIPv4 {
+		ips = append(ips, unspecifiedIPv6)
+	}
+
+	formats := []string{"/%s/%s/tcp/%d"}
+	if quic {
+		formats = append(formats, "/%s/%s/udp/%d/quic-v1")
+	}
+
+	addrs := make([]ma.Multiaddr, 0, len(ips)*len(formats))
+	for _, ip := range ips {
+		ipProtocol := "ip4"
+		if strings.Contains(ip, ":") {
+			ipProtocol = "ip6"
+		}
+
+		for _, format := range formats {
+			addr, err := ma.NewMultiaddr(fmt.Sprintf(format, ipProtocol, ip, port))
+			if err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs, nil
+}
+
+// onionAddrs returns the address of the onion service, if we have one. They are the
+// only addresses that we advertise when connecting through a proxy.
+func (lh *libp2pHost) onionAddrs() []ma.Multiaddr {
+	if lh.onion == nil {
+		return nil
+	}
+	return []ma.Multiaddr{lh.onion.Multiaddr()}
+}
+
+// Start connects to the static peers, the bootnodes and the peers that we have seen
+// recently, and starts advertising our namespaces and exchanging peers.
+func (lh *libp2pHost) Start() error {
+	lh.maintainStaticPeers()
+	lh.connectRecentPeers()
+	if lh.mdns != nil {
+		lh.mdns.start()
+	}
+	if err := lh.discovery.start(); err != nil {
+		return err
+	}
+
+	go lh.peerExchangeLoop()
+	return nil
+}
+
+// Stop closes the host and its DHT.
+func (lh *libp2pHost) Stop() error {
+	lh.cancel()
+
+	var errs []error
+	if lh.dht != nil {
+		errs = append(errs, lh.dht.Close())
+	}
+	if lh.onion != nil {
+		errs = append(errs, lh.onion.Close())
+	}
+	if lh.mdns != nil {
+		errs = append(errs, lh.mdns.Close())
+	}
+	if lh.h != nil {
+		errs = append(errs, lh.h.Close())
+	}
+	if lh.ds != nil {
+		errs = append(errs, lh.ds.Close())
+	}
+	if lh.codec != nil {
+		lh.codec.close()
+	}
+
+	return errors.Join(errs...)
+}
+
+// Advertise advertises our namespaces now.
+func (lh *libp2pHost) Advertise() {
+	lh.discovery.advertise()
+}
+
+// Discover returns the peers providing the namespace that it finds within the search
+// time.
+func (lh *libp2pHost) Discover(provides string, searchTime time.Duration) ([]peer.ID, error) {
+	return lh.discovery.findPeers(provides, searchTime)
+}
+
+// JoinTopic joins the gossipsub topic, whose name is prefixed with the host's
+// protocol ID. The messages that the validator doesn't accept are not delivered
+// or relayed.
+func (lh *libp2pHost) JoinTopic(topic string, validator pubsub.ValidatorEx) (*pubsub.Topic, error) {
+	name := lh.protocolID + topic
+	if err := lh.ps.RegisterTopicValidator(name, validator); err != nil {
+		return nil, err
+	}
+	return lh.ps.Join(name)
+}
+
+// SetStreamHandler sets the handler of the streams of the protocol ID.
+func (lh *libp2pHost) SetStreamHandler(pid string, handler func(libp2pnetwork.Stream)) {
+	lh.h.SetStreamHandler(protocol.ID(lh.protocolID+pid), handler)
+	log.Debugf("supporting protocol %s", lh.protocolID+pid)
+}
+
+// Connectedness returns whether we are connected to the peer.
+func (lh *libp2pHost) Connectedness(p peer.ID) libp2pnetwork.Connectedness {
+	return lh.h.Network().Connectedness(p)
+}
+
+// Connect connects to the peer.
+func (lh *libp2pHost) Connect(ctx context.Context, ai peer.AddrInfo) error {
+	return lh.h.Connect(ctx, ai)
+}
+
+// NewStream opens a stream of the protocol ID with the peer.
+func (lh *libp2pHost) NewStream(ctx context.Context, p peer.ID, pid protocol.ID) (libp2pnetwork.Stream, error) {
+	return lh.h.NewStream(ctx, p, protocol.ID(lh.protocolID)+pid)
+}
+
+// AddrInfo returns our peer ID and advertised addresses.
+func (lh *libp2pHost) AddrInfo() peer.AddrInfo {
+	return peer.AddrInfo{
+		ID:    lh.h.ID(),
+		Addrs: lh.h.Addrs(),
+	}
+}
+
+// Addresses returns our advertised addresses, including our peer ID.
+func (lh *libp2pHost) Addresses() []ma.Multiaddr {
+	ai := lh.AddrInfo()
+	addrs, err := peer.AddrInfoToP2pAddrs(&ai)
+	if err != nil {
+		log.Warnf("failed to format our addresses: %s", err)
+		return nil
+	}
+	return addrs
+}
+
+// PeerID returns our peer ID.
+func (lh *libp2pHost) PeerID() peer.ID {
+	return lh.h.ID()
+}
+
+// PrivKey returns the private key of our peer ID.
+func (lh *libp2pHost) PrivKey() crypto.PrivKey {
+	return lh.h.Peerstore().PrivKey(lh.h.ID())
+}
+
+// ConnectedPeers returns the addresses of our connected peers, including their peer IDs.
+func (lh *libp2pHost) ConnectedPeers() []string {
+	var peers []string
+	for _, conn := range lh.h.Network().Conns() {
+		peers = append(peers, fmt.Sprintf("%s/p2p/%s", conn.RemoteMultiaddr(), conn.RemotePeer()))
+	}
+	return peers
+}
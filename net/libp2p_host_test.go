@@ -0,0 +1,195 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestGetListenAddrs(t *testing.T) {
+	addrs, err := getListenAddrs("0.0.0.0", 9900, true)
+	require.NoError(t, err)
+	require.Len(t, addrs, 4)
+	require.Equal(t, "/ip4/0.0.0.0/tcp/9900", addrs[0].String())
+	require.Equal(t, "/ip4/0.0.0.0/udp/9900/quic-v1", addrs[1].String())
+	require.Equal(t, "/ip6/::/tcp/9900", addrs[2].String())
+	require.Equal(t, "/ip6/::/udp/9900/quic-v1", addrs[3].String())
+
+	addrs, err = getListenAddrs("127.0.0.1", 9900, false)
+	require.NoError(t, err)
+	require.Len(t, addrs, 1)
+	require.Equal(t, "/ip4/127.0.0.1/tcp/9900", addrs[0].String())
+
+	addrs, err = getListenAddrs("::1", 9900, false)
+	require.NoError(t, err)
+	require.Len(t, addrs, 1)
+	require.Equal(t, "/ip6/::1/tcp/9900", addrs[0].String())
+}
+
+func TestHost_connectQUIC(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+
+	var quicAddrs []ma.Multiaddr
+	for _, addr := range h1.AddrInfo().Addrs {
+		if _, err := addr.ValueForProtocol(ma.P_QUIC_V1); err == nil {
+			quicAddrs = append(quicAddrs, addr)
+		}
+	}
+	require.NotEmpty(t, quicAddrs)
+
+	err := h2.h.Connect(context.Background(), peer.AddrInfo{ID: h1.PeerID(), Addrs: quicAddrs})
+	require.NoError(t, err)
+	require.Equal(t, libp2pnetwork.Connected, h2.h.Connectedness(h1.PeerID()))
+
+	connectedPeers := h2.ConnectedPeers()
+	require.Len(t, connectedPeers, 1)
+	require.Contains(t, connectedPeers[0], "/quic-v1/p2p/"+h1.PeerID().String())
+}
+
+func newTestLibp2pHost(t *testing.T, cfg *Config, namespaces ...string) *libp2pHost {
+	lh, err := newLibp2pHost(cfg, func() []string { return namespaces })
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, lh.Stop())
+	})
+	return lh
+}
+
+func TestNewLibp2pHost_proxyConfig(t *testing.T) {
+	proxy, err := common.NewProxy("127.0.0.1:9050")
+	require.NoError(t, err)
+
+	type entry struct {
+		name      string
+		configure func(cfg *Config)
+		err       error
+	}
+	testEntries := []entry{
+		{
+			name:      "onion service without proxy",
+			configure: func(cfg *Config) { cfg.TorControl = &TorControlConfig{Address: "127.0.0.1:9051"} },
+			err:       errOnionWithoutProxy,
+		},
+		{
+			name: "mDNS with proxy",
+			configure: func(cfg *Config) {
+				cfg.Proxy = proxy
+				cfg.MDNS = true
+			},
+			err: errMDNSWithProxy,
+		},
+		{
+			name: "listen addresses with proxy",
+			configure: func(cfg *Config) {
+				cfg.Proxy = proxy
+				cfg.ListenAddrs = []string{"/ip4/127.0.0.1/tcp/9900"}
+			},
+			err: errListenAddrsWithProxy,
+		},
+		{
+			name: "announced addresses with proxy",
+			configure: func(cfg *Config) {
+				cfg.Proxy = proxy
+				cfg.AnnounceAddrs = []string{"/ip4/1.2.3.4/tcp/9900"}
+			},
+			err: errAnnounceAddrsWithProxy,
+		},
+	}
+
+	for _, e := range testEntries {
+		cfg := basicTestConfig(t)
+		e.configure(cfg)
+		_, err = newLibp2pHost(cfg, func() []string { return nil })
+		require.ErrorIs(t, err, e.err, e.name)
+	}
+}
+
+func TestNewLibp2pHost_init(t *testing.T) {
+	cfg := basicTestConfig(t)
+	lh, err := newLibp2pHost(cfg, func() []string { return nil })
+	require.NoError(t, err)
+
+	// the host listens on TCP and QUIC on the configured IP
+	var hasTCP, hasQUIC bool
+	for _, addr := range lh.AddrInfo().Addrs {
+		ip, ipErr := addr.ValueForProtocol(ma.P_IP4)
+		require.NoError(t, ipErr)
+		require.Equal(t, "127.0.0.1", ip)
+		_, tcpErr := addr.ValueForProtocol(ma.P_TCP)
+		_, quicErr := addr.ValueForProtocol(ma.P_QUIC_V1)
+		hasTCP = hasTCP || tcpErr == nil
+		hasQUIC = hasQUIC || quicErr == nil
+	}
+	require.True(t, hasTCP)
+	require.True(t, hasQUIC)
+
+	// the addresses include our peer ID
+	require.NotEmpty(t, lh.Addresses())
+	require.Contains(t, lh.Addresses()[0].String(), "/p2p/"+lh.PeerID().String())
+	require.True(t, lh.PrivKey().GetPublic().Equals(lh.h.Peerstore().PubKey(lh.PeerID())))
+
+	peerID := lh.PeerID()
+	require.NoError(t, lh.Stop())
+
+	// the identity key and the datastore are reused by the next host of the config
+	lh = newTestLibp2pHost(t, cfg)
+	require.Equal(t, peerID, lh.PeerID())
+}
+
+func TestLibp2pHost_connectBootnodes(t *testing.T) {
+	bootnode := newTestLibp2pHost(t, basicTestConfig(t))
+	require.NoError(t, bootnode.Start())
+
+	cfg := basicTestConfig(t)
+	cfg.Bootnodes = []string{bootnode.Addresses()[0].String()}
+	lh := newTestLibp2pHost(t, cfg)
+	require.Equal(t, libp2pnetwork.NotConnected, lh.Connectedness(bootnode.PeerID()))
+
+	require.NoError(t, lh.Start())
+	require.Equal(t, libp2pnetwork.Connected, lh.Connectedness(bootnode.PeerID()))
+
+	// we reconnect to bootnodes that we got disconnected from when advertising
+	require.NoError(t, lh.h.Network().ClosePeer(bootnode.PeerID()))
+	require.Equal(t, libp2pnetwork.NotConnected, lh.Connectedness(bootnode.PeerID()))
+	lh.discovery.advertiseNamespaces()
+	require.Equal(t, libp2pnetwork.Connected, lh.Connectedness(bootnode.PeerID()))
+}
+
+func TestLibp2pHost_advertiseAndDiscover(t *testing.T) {
+	const namespace = "test-namespace"
+
+	ha := newTestLibp2pHost(t, basicTestConfig(t))
+	require.NoError(t, ha.Start())
+
+	hbCfg := basicTestConfig(t)
+	hbCfg.Bootnodes = []string{ha.Addresses()[0].String()}
+	hb := newTestLibp2pHost(t, hbCfg, namespace)
+	require.NoError(t, hb.Start())
+
+	hb.Advertise()                     // ha wasn't in hb's routing table on its first advertisement
+	time.Sleep(500 * time.Millisecond) // give hb time to advertise in DHT
+
+	peerIDs, err := ha.Discover(namespace, 3*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []peer.ID{hb.PeerID()}, peerIDs)
+
+	// we don't discover ourselves, nor peers of namespaces that nobody provides
+	peerIDs, err = hb.Discover(namespace, time.Second)
+	require.NoError(t, err)
+	require.Empty(t, peerIDs)
+
+	peerIDs, err = ha.Discover("other-namespace", time.Second)
+	require.NoError(t, err)
+	require.Empty(t, peerIDs)
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"errors"
+	"fmt"
+
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+const (
+	unspecifiedIPv4 = "0.0.0.0"
+	unspecifiedIPv6 = "::"
+)
+
+var (
+	errListenAddrsWithProxy   = errors.New("listen addresses can't be set when using a proxy")
+	errAnnounceAddrsWithProxy = errors.New("announced addresses can't be set when using a proxy")
+)
+
+// parseListenAddrs returns the addresses that the host listens on, which must be
+// /ip4 or /ip6 addresses with a TCP port, or a QUIC port if quic is enabled.
+func parseListenAddrs(addrs []string, quic bool) ([]ma.Multiaddr, error) {
+	maddrs := make([]ma.Multiaddr, 0, len(addrs))
+	for _, addr := range addrs {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid listen address %q: %w", addr, err)
+		}
+
+		if !isListenAddr(maddr, quic) {
+			transports := "/tcp"
+			if quic {
+				transports = "/tcp or /udp/<port>/quic-v1"
+			}
+			return nil, fmt.Errorf("listen address %q must be an /ip4 or /ip6 address with %s", addr, transports)
+		}
+
+		maddrs = append(maddrs, maddr)
+	}
+
+	return maddrs, nil
+}
+
+func isListenAddr(addr ma.Multiaddr, quic bool) bool {
+	protocols := addr.Protocols()
+	if len(protocols) < 2 || (protocols[0].Code != ma.P_IP4 && protocols[0].Code != ma.P_IP6) {
+		return false
+	}
+
+	switch {
+	case len(protocols) == 2 && protocols[1].Code == ma.P_TCP:
+		return true
+	case len(protocols) == 3 && protocols[1].Code == ma.P_UDP && protocols[2].Code == ma.P_QUIC_V1:
+		return quic
+	default:
+		return false
+	}
+}
+
+// hasPublicListenAddr returns whether one of the addresses is not a loopback
+// address, in which case the host can be reached from other machines.
+func hasPublicListenAddr(addrs []ma.Multiaddr) bool {
+	for _, addr := range addrs {
+		if !manet.IsIPLoopback(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAnnounceAddrs returns the addresses that the host advertises to peers in
+// addition to the ones it listens on, which must not have a peer ID.
+func parseAnnounceAddrs(addrs []string) ([]ma.Multiaddr, error) {
+	maddrs := make([]ma.Multiaddr, 0, len(addrs))
+	for _, addr := range addrs {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid announced address %q: %w", addr, err)
+		}
+		if _, err = maddr.ValueForProtocol(ma.P_P2P); err == nil {
+			return nil, fmt.Errorf("announced address %q can't have a peer ID", addr)
+		}
+		maddrs = append(maddrs, maddr)
+	}
+
+	return maddrs, nil
+}
+
+// announceAddrsFactory returns the libp2p address factory adding the announced
+// addresses to the addresses that libp2p advertises, which are the addresses that
+// we listen on and the addresses that peers observed us at.
+func announceAddrsFactory(announced []ma.Multiaddr) func([]ma.Multiaddr) []ma.Multiaddr {
+	return func(addrs []ma.Multiaddr) []ma.Multiaddr {
+		for _, a := range announced {
+			if !ma.Contains(addrs, a) {
+				addrs = append(addrs, a)
+			}
+		}
+		return addrs
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestParseListenAddrs(t *testing.T) {
+	addrs, err := parseListenAddrs([]string{
+		"/ip4/0.0.0.0/tcp/9900",
+		"/ip6/::/tcp/9900",
+		"/ip6/::/udp/9900/quic-v1",
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, addrs, 3)
+	require.Equal(t, "/ip6/::/udp/9900/quic-v1", addrs[2].String())
+
+	for _, addr := range []string{
+		"not-an-address",
+		"/dns4/example.com/tcp/9900",
+		"/ip4/0.0.0.0/udp/9900",
+		"/ip4/0.0.0.0/tcp/9900/ws",
+	} {
+		_, err = parseListenAddrs([]string{addr}, true)
+		require.Error(t, err, addr)
+	}
+
+	// QUIC addresses are not allowed when QUIC is disabled
+	_, err = parseListenAddrs([]string{"/ip4/0.0.0.0/udp/9900/quic-v1"}, false)
+	require.Error(t, err)
+}
+
+func TestParseAnnounceAddrs(t *testing.T) {
+	addrs, err := parseAnnounceAddrs([]string{"/dns4/example.com/tcp/9900", "/ip6/2001:db8::1/tcp/9900"})
+	require.NoError(t, err)
+	require.Len(t, addrs, 2)
+
+	_, err = parseAnnounceAddrs([]string{
+		"/ip4/1.2.3.4/tcp/9900/p2p/12D3KooWAAxG7eTEHr2uBVw3BDMxYsxyqfKvj3qqqpRGtTfuzTuH",
+	})
+	require.ErrorContains(t, err, "peer ID")
+}
+
+func TestAnnounceAddrsFactory(t *testing.T) {
+	listenAddr := ma.StringCast("/ip4/127.0.0.1/tcp/9900")
+	announced := ma.StringCast("/ip4/1.2.3.4/tcp/9900")
+	factory := announceAddrsFactory([]ma.Multiaddr{announced, listenAddr})
+
+	addrs := factory([]ma.Multiaddr{listenAddr})
+	require.Equal(t, []ma.Multiaddr{listenAddr, announced}, addrs)
+}
+
+func TestHost_listenAddrs(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.ListenAddrs = []string{
+		"/ip4/127.0.0.1/tcp/0",
+		"/ip4/127.0.0.1/udp/0/quic-v1",
+	}
+	cfg.AnnounceAddrs = []string{"/ip4/1.2.3.4/tcp/9900"}
+	h1 := newHost(t, cfg)
+
+	var hasTCP, hasQUIC, hasAnnounced bool
+	for _, addr := range h1.AddrInfo().Addrs {
+		if addr.String() == cfg.AnnounceAddrs[0] {
+			hasAnnounced = true
+			continue
+		}
+		_, quicErr := addr.ValueForProtocol(ma.P_QUIC_V1)
+		_, tcpErr := addr.ValueForProtocol(ma.P_TCP)
+		hasQUIC = hasQUIC || quicErr == nil
+		hasTCP = hasTCP || tcpErr == nil
+	}
+	require.True(t, hasTCP)
+	require.True(t, hasQUIC)
+	require.True(t, hasAnnounced)
+
+	h2 := newHost(t, basicTestConfig(t))
+	require.NoError(t, h2.h.Connect(h2.ctx, h1.AddrInfo()))
+}
+
+func TestHost_listenAddrsWithProxy(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.Proxy = &common.Proxy{}
+	cfg.ListenAddrs = []string{"/ip4/127.0.0.1/tcp/0"}
+	_, err := NewHost(cfg)
+	require.ErrorIs(t, err, errListenAddrsWithProxy)
+}
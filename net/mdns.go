@@ -0,0 +1,297 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// mdnsServiceName is the DNS-SD service of the swapd instances on the local
+	// network, whose TXT records hold their addresses as "dnsaddr=<multiaddr>".
+	mdnsServiceName   = "_atomic-swap._udp.local."
+	mdnsDNSAddrPrefix = "dnsaddr="
+	mdnsQueryInterval = time.Minute
+	mdnsTTL           = 120 // seconds
+	mdnsMaxPacketSize = 9000
+)
+
+var (
+	mdnsGroupAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+	errMDNSWithProxy = errors.New("mDNS discovery cannot be used with a proxy")
+)
+
+// mdnsService finds the swapd instances on the local network with multicast DNS,
+// and makes itself found by them. Queries are sent to the mDNS group from an
+// ephemeral port, to which the instances respond directly.
+type mdnsService struct {
+	ctx       context.Context
+	h         libp2phost.Host
+	groupConn *net.UDPConn // receives the queries sent to the mDNS group
+	conn      *net.UDPConn // sends queries and responses, and receives responses
+	peerFound func(peer.AddrInfo)
+}
+
+// newMDNSService returns the mDNS service of the host, which calls peerFound with
+// the instances that it finds.
+func newMDNSService(ctx context.Context, h libp2phost.Host, peerFound func(peer.AddrInfo)) (*mdnsService, error) {
+	groupConn, err := net.ListenMulticastUDP("udp4", nil, mdnsGroupAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		_ = groupConn.Close()
+		return nil, err
+	}
+
+	return &mdnsService{
+		ctx:       ctx,
+		h:         h,
+		groupConn: groupConn,
+		conn:      conn,
+		peerFound: peerFound,
+	}, nil
+}
+
+// start starts responding to queries and querying for instances.
+func (s *mdnsService) start() {
+	go s.readQueries()
+	go s.readResponses()
+	go s.queryLoop()
+}
+
+// Close stops the service.
+func (s *mdnsService) Close() error {
+	return errors.Join(s.groupConn.Close(), s.conn.Close())
+}
+
+func (s *mdnsService) queryLoop() {
+	query, err := mdnsQuery()
+	if err != nil {
+		log.Warnf("failed to create mDNS query: %s", err)
+		return
+	}
+
+	for {
+		if _, err = s.conn.WriteToUDP(query, mdnsGroupAddr); err != nil {
+			log.Debugf("failed to send mDNS query: %s", err)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(mdnsQueryInterval):
+		}
+	}
+}
+
+func (s *mdnsService) readQueries() {
+	buf := make([]byte, mdnsMaxPacketSize)
+	for {
+		n, from, err := s.groupConn.ReadFromUDP(buf)
+		if err != nil {
+			return // closed
+		}
+
+		id, isQuery := parseMDNSQuery(buf[:n])
+		addrs := s.h.Addrs()
+		if !isQuery || len(addrs) == 0 {
+			continue
+		}
+
+		resp, err := mdnsResponse(id, s.h.ID(), addrs)
+		if err != nil {
+			log.Debugf("failed to create mDNS response: %s", err)
+			continue
+		}
+
+		if _, err = s.conn.WriteToUDP(resp, from); err != nil {
+			log.Debugf("failed to send mDNS response to %s: %s", from, err)
+		}
+	}
+}
+
+func (s *mdnsService) readResponses() {
+	buf := make([]byte, mdnsMaxPacketSize)
+	for {
+		n, _, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return // closed
+		}
+
+		for _, ai := range parseMDNSResponse(buf[:n]) {
+			if ai.ID != s.h.ID() {
+				s.peerFound(ai)
+			}
+		}
+	}
+}
+
+// mdnsQuery returns the query for the instances of our service.
+func mdnsQuery() ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{})
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+
+	err := b.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName(mdnsServiceName),
+		Type:  dnsmessage.TypePTR,
+		Class: dnsmessage.ClassINET,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Finish()
+}
+
+// parseMDNSQuery returns the ID of the message, and whether it is a query for the
+// instances of our service.
+func parseMDNSQuery(msg []byte) (uint16, bool) {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil || header.Response {
+		return 0, false
+	}
+
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return 0, false
+	}
+
+	for _, q := range questions {
+		if q.Type == dnsmessage.TypePTR && strings.EqualFold(q.Name.String(), mdnsServiceName) {
+			return header.ID, true
+		}
+	}
+
+	return 0, false
+}
+
+// mdnsResponse returns the response to the query with the ID, which points to our
+// instance, whose TXT record holds our addresses. Relay addresses are left out,
+// as instances on the local network can dial us directly.
+func mdnsResponse(id uint16, self peer.ID, addrs []ma.Multiaddr) ([]byte, error) {
+	instanceName, err := dnsmessage.NewName(self.String() + "." + mdnsServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	p2pAddr, err := ma.NewMultiaddr("/p2p/" + self.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var txt []string
+	for _, addr := range addrs {
+		if _, err = addr.ValueForProtocol(ma.P_CIRCUIT); err == nil {
+			continue
+		}
+		txt = append(txt, mdnsDNSAddrPrefix+addr.Encapsulate(p2pAddr).String())
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, Response: true, Authoritative: true})
+	b.EnableCompression()
+
+	if err = b.StartAnswers(); err != nil {
+		return nil, err
+	}
+	err = b.PTRResource(
+		dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName(mdnsServiceName),
+			Class: dnsmessage.ClassINET,
+			TTL:   mdnsTTL,
+		},
+		dnsmessage.PTRResource{PTR: instanceName},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.StartAdditionals(); err != nil {
+		return nil, err
+	}
+	err = b.TXTResource(
+		dnsmessage.ResourceHeader{
+			Name:  instanceName,
+			Class: dnsmessage.ClassINET,
+			TTL:   mdnsTTL,
+		},
+		dnsmessage.TXTResource{TXT: txt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Finish()
+}
+
+// parseMDNSResponse returns the instances of our service in the TXT records of the
+// response.
+func parseMDNSResponse(msg []byte) []peer.AddrInfo {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil || !header.Response {
+		return nil
+	}
+
+	if err = p.SkipAllQuestions(); err != nil {
+		return nil
+	}
+	answers, err := p.AllAnswers()
+	if err != nil {
+		return nil
+	}
+	if err = p.SkipAllAuthorities(); err != nil {
+		return nil
+	}
+	additionals, err := p.AllAdditionals()
+	if err != nil {
+		return nil
+	}
+
+	var addrs []ma.Multiaddr
+	for _, r := range append(answers, additionals...) {
+		txt, ok := r.Body.(*dnsmessage.TXTResource)
+		if !ok || !strings.HasSuffix(strings.ToLower(r.Header.Name.String()), mdnsServiceName) {
+			continue
+		}
+
+		for _, s := range txt.TXT {
+			addrStr, found := strings.CutPrefix(s, mdnsDNSAddrPrefix)
+			if !found {
+				continue
+			}
+			addr, parseErr := ma.NewMultiaddr(addrStr)
+			if parseErr != nil {
+				continue
+			}
+			if _, parseErr = addr.ValueForProtocol(ma.P_P2P); parseErr != nil {
+				continue
+			}
+			addrs = append(addrs, addr)
+		}
+	}
+
+	addrInfos, err := peer.AddrInfosFromP2pAddrs(addrs...)
+	if err != nil {
+		log.Debugf("invalid addresses in mDNS response: %s", err)
+		return nil
+	}
+
+	return addrInfos
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	libp2ptest "github.com/libp2p/go-libp2p/core/test"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMDNSQuery(t *testing.T) {
+	query, err := mdnsQuery()
+	require.NoError(t, err)
+
+	id, isQuery := parseMDNSQuery(query)
+	require.True(t, isQuery)
+	require.Zero(t, id)
+
+	_, isQuery = parseMDNSQuery([]byte("not a DNS message"))
+	require.False(t, isQuery)
+}
+
+func TestMDNSResponse(t *testing.T) {
+	self, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+
+	var addrs []ma.Multiaddr
+	for _, addr := range []string{
+		"/ip4/192.168.1.2/tcp/9900",
+		"/ip4/192.168.1.2/udp/9900/quic-v1",
+		"/ip4/1.2.3.4/tcp/9900/p2p/12D3KooWQQWDJ7KA1Fwdf2ejWz9VXHKvY8cC5PB7Sf34fbEGbsgV/p2p-circuit",
+	} {
+		addrs = append(addrs, ma.StringCast(addr))
+	}
+
+	resp, err := mdnsResponse(7, self, addrs)
+	require.NoError(t, err)
+
+	_, isQuery := parseMDNSQuery(resp)
+	require.False(t, isQuery)
+
+	// the relay address is left out
+	expected := peer.AddrInfo{ID: self, Addrs: addrs[:2]}
+	require.Equal(t, []peer.AddrInfo{expected}, parseMDNSResponse(resp))
+}
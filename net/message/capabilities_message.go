@@ -0,0 +1,44 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"fmt"
+
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+// Capabilities is exchanged by hosts before they start a swap, so that hosts running
+// incompatible versions fail with a clear error instead of failing to decode each
+// other's messages in the middle of the swap.
+type Capabilities struct {
+	// Version is the swapd version of the sender, for logging.
+	Version string `json:"version"`
+	// Protocols are the stream protocols that the sender supports, without the
+	// protocol ID prefix, like "/swap/0".
+	Protocols []string `json:"protocols" validate:"max=64,dive,required"`
+	// Features are the optional features that the sender supports.
+	Features []string `json:"features" validate:"max=64,dive,required"`
+}
+
+// String converts the Capabilities to a string usable for debugging purposes
+func (m *Capabilities) String() string {
+	return fmt.Sprintf("Capabilities Version=%s Protocols=%v Features=%v", m.Version, m.Protocols, m.Features)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *Capabilities) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{CapabilitiesType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *Capabilities) Type() byte {
+	return CapabilitiesType
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
@@ -28,6 +29,20 @@ const (
 	RelayClaimResponseType
 	SendKeysType
 	NotifyETHLockedType
+	RelayFeeQuoteRequestType
+	RelayFeeQuoteType
+	PeerExchangeType
+	OfferAnnouncementType
+	QueryRequestType
+	// CompressedType is the envelope of a compressed message, whose next byte is
+	// the compression algorithm followed by the compressed message. The envelope is
+	// removed by the net package before decoding the message.
+	CompressedType
+	CapabilitiesType
+	NotifyXMRLockType
+	RelayRefundRequestType
+	RelayNewSwapRequestType
+	RelaySetReadyRequestType
 )
 
 // TypeToString converts a message type into a string.
@@ -43,6 +58,28 @@ func TypeToString(t byte) string {
 		return "RelayClaimRequestType"
 	case RelayClaimResponseType:
 		return "RelayClaimResponse"
+	case RelayFeeQuoteRequestType:
+		return "RelayFeeQuoteRequest"
+	case RelayFeeQuoteType:
+		return "RelayFeeQuote"
+	case PeerExchangeType:
+		return "PeerExchange"
+	case OfferAnnouncementType:
+		return "OfferAnnouncement"
+	case QueryRequestType:
+		return "QueryRequest"
+	case CompressedType:
+		return "Compressed"
+	case CapabilitiesType:
+		return "Capabilities"
+	case NotifyXMRLockType:
+		return "NotifyXMRLock"
+	case RelayRefundRequestType:
+		return "RelayRefundRequest"
+	case RelayNewSwapRequestType:
+		return "RelayNewSwapRequest"
+	case RelaySetReadyRequestType:
+		return "RelaySetReadyRequest"
 	default:
 		return fmt.Sprintf("Unknown(%d)", t)
 	}
@@ -66,10 +103,30 @@ func DecodeMessage(b []byte) (common.Message, error) {
 		msg = new(RelayClaimRequest)
 	case RelayClaimResponseType:
 		msg = new(RelayClaimResponse)
+	case RelayFeeQuoteRequestType:
+		msg = new(RelayFeeQuoteRequest)
+	case RelayFeeQuoteType:
+		msg = new(RelayFeeQuote)
+	case PeerExchangeType:
+		msg = new(PeerExchange)
+	case OfferAnnouncementType:
+		msg = new(OfferAnnouncement)
+	case QueryRequestType:
+		msg = new(QueryRequest)
+	case CapabilitiesType:
+		msg = new(Capabilities)
 	case SendKeysType:
 		msg = new(SendKeysMessage)
 	case NotifyETHLockedType:
 		msg = new(NotifyETHLocked)
+	case NotifyXMRLockType:
+		msg = new(NotifyXMRLock)
+	case RelayRefundRequestType:
+		msg = new(RelayRefundRequest)
+	case RelayNewSwapRequestType:
+		msg = new(RelayNewSwapRequest)
+	case RelaySetReadyRequestType:
+		msg = new(RelaySetReadyRequest)
 	default:
 		return nil, fmt.Errorf("invalid message type=%d", msgType)
 	}
@@ -81,6 +138,33 @@ func DecodeMessage(b []byte) (common.Message, error) {
 	return msg, nil
 }
 
+// QueryRequest is sent by takers to query the offers of a maker that match the
+// filter, which the maker applies before responding.
+type QueryRequest struct {
+	Filter types.OfferFilter `json:"filter"`
+}
+
+// String ...
+func (m *QueryRequest) String() string {
+	return fmt.Sprintf("QueryRequest Filter=%+v", m.Filter)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *QueryRequest) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{QueryRequestType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *QueryRequest) Type() byte {
+	return QueryRequestType
+}
+
 // QueryResponse ...
 type QueryResponse struct {
 	Offers []*types.Offer `json:"offers" validate:"dive,required"`
@@ -121,6 +205,12 @@ type SendKeysMessage struct {
 	DLEqProof          []byte                  `json:"dleqProof" validate:"required"`
 	Secp256k1PublicKey *secp256k1.PublicKey    `json:"secp256k1PublicKey" validate:"required"`
 	EthAddress         ethcommon.Address       `json:"ethAddress"` // not set by XMR Taker
+	// XMRLockProof is set by XMR Takers that accept a NotifyXMRLock message after
+	// the XMR is locked. Takers that don't set it only verify the lock by scanning.
+	XMRLockProof bool `json:"xmrLockProof,omitempty"`
+	// ExchangeRate is set by XMR Takers of pegged offers to the offer's exchange
+	// rate that they take it at.
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate,omitempty"`
 }
 
 // String ...
@@ -186,3 +276,35 @@ func (m *NotifyETHLocked) Encode() ([]byte, error) {
 func (m *NotifyETHLocked) Type() byte {
 	return NotifyETHLockedType
 }
+
+// NotifyXMRLock is sent by XMRMaker to XMRTaker after locking his XMR, if XMRTaker
+// set XMRLockProof in her SendKeysMessage. The proof of the lock transaction lets
+// XMRTaker verify the lock with her daemon without scanning the blockchain with a
+// view-only wallet of the swap's address.
+type NotifyXMRLock struct {
+	TxID string `json:"txID" validate:"required"`
+	// TxProof is the OutProofV2 of the lock transaction's output to the swap's
+	// address, as returned by get_tx_proof with the offer ID as the message.
+	TxProof string `json:"txProof" validate:"required"`
+}
+
+// String ...
+func (m *NotifyXMRLock) String() string {
+	return fmt.Sprintf("NotifyXMRLock TxID=%s TxProof=%s", m.TxID, m.TxProof)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *NotifyXMRLock) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{NotifyXMRLockType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *NotifyXMRLock) Type() byte {
+	return NotifyXMRLockType
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+// offerAnnouncementSigPrefix is prepended to the bytes that makers sign, so that
+// their signatures of offer announcements can't be used for anything else.
+const offerAnnouncementSigPrefix = "atomic-swap offer announcement:"
+
+var errInvalidOfferAnnouncementSig = errors.New("invalid offer announcement signature")
+
+// OfferAnnouncement is published by makers on the offers topic with all of their
+// current offers. Makers publish it when their offers change, and periodically so
+// that takers know that the offers are still available. It is signed with the
+// maker's peer ID key, so that it can't be spoofed, whichever peer relays it.
+type OfferAnnouncement struct {
+	Maker  peer.ID        `json:"maker" validate:"required"`
+	Offers []*types.Offer `json:"offers" validate:"dive,required"`
+	// Version increases with every announcement of the maker, so that the
+	// announcements that are replayed after the maker removed offers are ignored
+	Version uint64 `json:"version" validate:"required"`
+	// Timestamp is the unix time in seconds when the announcement was published,
+	// so that old announcements are dropped
+	Timestamp int64  `json:"timestamp" validate:"required"`
+	Signature []byte `json:"signature" validate:"required"`
+}
+
+// String converts the OfferAnnouncement to a string usable for debugging purposes
+func (m *OfferAnnouncement) String() string {
+	return fmt.Sprintf("OfferAnnouncement Maker=%s Offers=%v Version=%d Timestamp=%d",
+		m.Maker, m.Offers, m.Version, m.Timestamp)
+}
+
+// signingBytes returns the bytes that the maker signs. The offers are covered by
+// their IDs, which are the hashes of their fields.
+func (m *OfferAnnouncement) signingBytes() []byte {
+	b := []byte(offerAnnouncementSigPrefix)
+	b = append(b, []byte(m.Maker)...)
+	b = binary.BigEndian.AppendUint64(b, m.Version)
+	b = binary.BigEndian.AppendUint64(b, uint64(m.Timestamp))
+	for _, o := range m.Offers {
+		b = append(b, o.ID[:]...)
+	}
+	return b
+}
+
+// Sign sets the maker of the announcement to the peer ID of the key, and signs the
+// announcement with the key.
+func (m *OfferAnnouncement) Sign(key crypto.PrivKey) error {
+	maker, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	m.Maker = maker
+	m.Signature, err = key.Sign(m.signingBytes())
+	return err
+}
+
+// Verify returns an error if the announcement is not signed by its maker.
+func (m *OfferAnnouncement) Verify() error {
+	pubKey, err := m.Maker.ExtractPublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to get public key of maker %s: %w", m.Maker, err)
+	}
+
+	ok, err := pubKey.Verify(m.signingBytes(), m.Signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errInvalidOfferAnnouncementSig
+	}
+
+	return nil
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *OfferAnnouncement) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{OfferAnnouncementType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *OfferAnnouncement) Type() byte {
+	return OfferAnnouncementType
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package message
+
+import (
+	"fmt"
+
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+// MaxPeerExchangeAddrs is the maximum number of addresses in a PeerExchange message.
+const MaxPeerExchangeAddrs = 64
+
+// PeerExchange is sent in response to a peer exchange request, and holds a sample
+// of the swap peers that the sender is connected to, so that the receiver is less
+// dependent on the bootnodes for finding peers.
+type PeerExchange struct {
+	// Peers are the addresses of the peers, including their peer IDs, of which
+	// there can be several per peer
+	Peers []string `json:"peers" validate:"max=64,dive,required"`
+}
+
+// String converts the PeerExchange to a string usable for debugging purposes
+func (m *PeerExchange) String() string {
+	return fmt.Sprintf("PeerExchange Peers=%v", m.Peers)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *PeerExchange) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{PeerExchangeType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *PeerExchange) Type() byte {
+	return PeerExchangeType
+}
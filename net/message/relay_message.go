@@ -5,6 +5,7 @@ package message
 
 import (
 	"fmt"
+	"math/big"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 
@@ -23,11 +24,20 @@ type RelayClaimRequest struct {
 	Swap            *contracts.SwapCreatorSwap `json:"swap" validate:"required"`
 	Secret          []byte                     `json:"secret" validate:"required,len=32"`
 	Signature       []byte                     `json:"signature" validate:"required,len=65"`
+
+	// FeeWei is the relayer fee in the signed claimRelayer call. It is computed by
+	// the claimer from its fee configuration and checked by the relayer against its
+	// own. For token swaps, the fee is paid in base units of the token.
+	FeeWei *big.Int `json:"feeWei" validate:"required"`
 }
 
-// RelayClaimResponse implements common.Message for our p2p relay claim responses
+// RelayClaimResponse implements common.Message for our p2p relay claim responses.
+// It is also the response to relay refund requests.
 type RelayClaimResponse struct {
-	TxHash ethcommon.Hash `json:"transactionHash" validate:"required"`
+	TxHash ethcommon.Hash `json:"transactionHash" validate:"required_without=Error"`
+	// Error is the reason that the relayer rejected the request, in which case
+	// TxHash is not set.
+	Error string `json:"error,omitempty"`
 }
 
 // String converts the RelayClaimRequest to a string usable for debugging purposes
@@ -71,3 +81,184 @@ func (m *RelayClaimResponse) Encode() ([]byte, error) {
 func (m *RelayClaimResponse) Type() byte {
 	return RelayClaimResponseType
 }
+
+// RelayRefundRequest implements common.Message for our p2p relay refund requests,
+// which an XMR taker without ETH for gas sends to relay nodes to refund its swap.
+type RelayRefundRequest struct {
+	SwapCreatorAddr ethcommon.Address          `json:"swapCreatorAddr" validate:"required"`
+	Swap            *contracts.SwapCreatorSwap `json:"swap" validate:"required"`
+	Secret          []byte                     `json:"secret" validate:"required,len=32"`
+	Signature       []byte                     `json:"signature" validate:"required,len=65"`
+
+	// FeeWei is the relayer fee in the signed refundRelayer call. For token swaps,
+	// the fee is paid in base units of the token.
+	FeeWei *big.Int `json:"feeWei" validate:"required"`
+}
+
+// String converts the RelayRefundRequest to a string usable for debugging purposes
+func (m *RelayRefundRequest) String() string {
+	return fmt.Sprintf("RelayRefundRequest=%#v", m)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *RelayRefundRequest) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{RelayRefundRequestType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *RelayRefundRequest) Type() byte {
+	return RelayRefundRequestType
+}
+
+// RelayNewSwapRequest implements common.Message for our p2p relay new swap
+// requests, which an XMR taker without ETH for gas sends to relay nodes to lock
+// the tokens of its swap. The relayer fee is paid in the swap's token, out of
+// the owner's balance, with the permit signed by the owner.
+type RelayNewSwapRequest struct {
+	SwapCreatorAddr  ethcommon.Address                     `json:"swapCreatorAddr" validate:"required"`
+	Owner            ethcommon.Address                     `json:"owner" validate:"required"`
+	PubKeyClaim      types.Hash                            `json:"pubKeyClaim" validate:"required"`
+	PubKeyRefund     types.Hash                            `json:"pubKeyRefund" validate:"required"`
+	Claimer          ethcommon.Address                     `json:"claimer" validate:"required"`
+	TimeoutDuration0 *big.Int                              `json:"timeoutDuration0" validate:"required"`
+	TimeoutDuration1 *big.Int                              `json:"timeoutDuration1" validate:"required"`
+	Asset            ethcommon.Address                     `json:"asset" validate:"required"`
+	Value            *big.Int                              `json:"value" validate:"required"`
+	Nonce            *big.Int                              `json:"nonce" validate:"required"`
+	Permit           *contracts.SwapCreatorPermitSignature `json:"permit" validate:"required"`
+	Signature        []byte                                `json:"signature" validate:"required,len=65"`
+
+	// FeeWei is the relayer fee in the signed newSwapRelayer call, in base units of
+	// the token. The permit covers the swap value plus the fee.
+	FeeWei *big.Int `json:"feeWei" validate:"required"`
+}
+
+// String converts the RelayNewSwapRequest to a string usable for debugging purposes
+func (m *RelayNewSwapRequest) String() string {
+	return fmt.Sprintf("RelayNewSwapRequest=%#v", m)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *RelayNewSwapRequest) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{RelayNewSwapRequestType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *RelayNewSwapRequest) Type() byte {
+	return RelayNewSwapRequestType
+}
+
+// RelaySetReadyRequest implements common.Message for our p2p relay set ready
+// requests, which an XMR taker without ETH for gas sends to relay nodes to set
+// its token swap as ready. The relayer fee is paid in the swap's token, out of
+// the owner's balance, with the permit signed by the owner.
+type RelaySetReadyRequest struct {
+	SwapCreatorAddr ethcommon.Address                     `json:"swapCreatorAddr" validate:"required"`
+	Swap            *contracts.SwapCreatorSwap            `json:"swap" validate:"required"`
+	Permit          *contracts.SwapCreatorPermitSignature `json:"permit" validate:"required"`
+	Signature       []byte                                `json:"signature" validate:"required,len=65"`
+
+	// FeeWei is the relayer fee in the signed setReadyRelayer call, in base units
+	// of the token.
+	FeeWei *big.Int `json:"feeWei" validate:"required"`
+}
+
+// String converts the RelaySetReadyRequest to a string usable for debugging purposes
+func (m *RelaySetReadyRequest) String() string {
+	return fmt.Sprintf("RelaySetReadyRequest=%#v", m)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *RelaySetReadyRequest) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{RelaySetReadyRequestType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *RelaySetReadyRequest) Type() byte {
+	return RelaySetReadyRequestType
+}
+
+// RelayFeeQuoteRequest implements common.Message for requests sent to relayers
+// asking for the fee they require to relay the claim of a swap. It is sent before
+// the claimer signs the forwarder request, since the signature covers the fee.
+type RelayFeeQuoteRequest struct {
+	SwapCreatorAddr ethcommon.Address `json:"swapCreatorAddr" validate:"required"`
+	Asset           ethcommon.Address `json:"asset"`
+	Value           *big.Int          `json:"value" validate:"required"`
+}
+
+// RelayFeeQuote implements common.Message for relayer responses to a
+// RelayFeeQuoteRequest.
+type RelayFeeQuote struct {
+	// FeeWei is the minimum relayer fee that the relayer will accept for the
+	// claim, in base units of the token for token swaps.
+	FeeWei *big.Int `json:"feeWei" validate:"required"`
+
+	// ETASeconds is the relayer's estimate of the time until a claim submitted
+	// now is included in a block.
+	ETASeconds uint64 `json:"etaSeconds"`
+}
+
+// String converts the RelayFeeQuoteRequest to a string usable for debugging purposes
+func (m *RelayFeeQuoteRequest) String() string {
+	return fmt.Sprintf("RelayFeeQuoteRequest SwapCreatorAddr=%s Asset=%s Value=%s",
+		m.SwapCreatorAddr,
+		m.Asset,
+		m.Value,
+	)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *RelayFeeQuoteRequest) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{RelayFeeQuoteRequestType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *RelayFeeQuoteRequest) Type() byte {
+	return RelayFeeQuoteRequestType
+}
+
+// String converts the RelayFeeQuote to a string usable for debugging purposes
+func (m *RelayFeeQuote) String() string {
+	return fmt.Sprintf("RelayFeeQuote FeeWei=%s ETASeconds=%d", m.FeeWei, m.ETASeconds)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *RelayFeeQuote) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{RelayFeeQuoteType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *RelayFeeQuote) Type() byte {
+	return RelayFeeQuoteType
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/event"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/host/autorelay"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// NATStatus is the status of the NAT traversal of the host.
+type NATStatus struct {
+	// Reachability is whether AutoNAT found that peers can dial us directly. It is
+	// unknown until enough peers have tried to dial us back.
+	Reachability libp2pnetwork.Reachability
+
+	// RelayAddrs are the circuit relay addresses that peers reach us at when we are
+	// not publicly reachable. Peers connected through a relay then try to upgrade
+	// the connection to a direct one by hole punching.
+	RelayAddrs []ma.Multiaddr
+}
+
+// natTraversalOptions returns the libp2p options with which peers behind a NAT can
+// be reached:
+//   - AutoNAT, with which peers find out whether they are publicly reachable, by
+//     asking other peers to dial them back
+//   - a circuit relay v2 service, which we run when we are publicly reachable
+//   - relay reservations, which we make with the relays among the relay candidates
+//     when we are not publicly reachable, so that peers can reach us through them
+//   - hole punching (DCUtR) of the connections through relays
+func natTraversalOptions(relayCandidates autorelay.PeerSource) []libp2p.Option {
+	return []libp2p.Option{
+		libp2p.EnableNATService(),
+		libp2p.EnableRelayService(),
+		libp2p.EnableAutoRelayWithPeerSource(relayCandidates),
+		libp2p.EnableHolePunching(),
+	}
+}
+
+// relayCandidates returns up to num peers that autorelay can try to make relay
+// reservations with, which are the bootnodes and the peers we are connected to.
+// Autorelay ignores the ones that don't run a relay service.
+func (lh *libp2pHost) relayCandidates(ctx context.Context, num int) <-chan peer.AddrInfo {
+	candidatesCh := make(chan peer.AddrInfo, num)
+
+	go func() {
+		defer close(candidatesCh)
+
+		select {
+		case <-lh.hostReady:
+		case <-ctx.Done():
+			return
+		}
+
+		candidates := make([]peer.AddrInfo, 0, num)
+		seen := make(map[peer.ID]bool)
+		add := func(ai peer.AddrInfo) {
+			if len(candidates) < num && !seen[ai.ID] && len(ai.Addrs) > 0 {
+				seen[ai.ID] = true
+				candidates = append(candidates, ai)
+			}
+		}
+
+		for _, bn := range lh.bootnodes.addrInfos(ctx) {
+			add(bn)
+		}
+		for _, p := range lh.h.Network().Peers() {
+			add(lh.h.Peerstore().PeerInfo(p))
+		}
+
+		for _, ai := range candidates {
+			candidatesCh <- ai
+		}
+	}()
+
+	return candidatesCh
+}
+
+// watchReachability records the reachability found by AutoNAT until the host is
+// stopped.
+func (lh *libp2pHost) watchReachability(sub event.Subscription) {
+	defer func() { _ = sub.Close() }()
+
+	for {
+		select {
+		case <-lh.ctx.Done():
+			return
+		case e, ok := <-sub.Out():
+			if !ok {
+				return
+			}
+			reachability := e.(event.EvtLocalReachabilityChanged).Reachability
+			log.Infof("NAT reachability changed to %s", reachability)
+			lh.reachability.Store(int32(reachability))
+		}
+	}
+}
+
+// NATStatus returns the status of our NAT traversal.
+func (lh *libp2pHost) NATStatus() *NATStatus {
+	status := &NATStatus{
+		Reachability: libp2pnetwork.Reachability(lh.reachability.Load()),
+	}
+
+	for _, addr := range lh.h.Addrs() {
+		if _, err := addr.ValueForProtocol(ma.P_CIRCUIT); err == nil {
+			status.RelayAddrs = append(status.RelayAddrs, addr)
+		}
+	}
+
+	return status
+}
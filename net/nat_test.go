@@ -0,0 +1,47 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"testing"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_NATStatus(t *testing.T) {
+	h := newHost(t, basicTestConfig(t))
+
+	status := h.NATStatus()
+	require.Equal(t, libp2pnetwork.ReachabilityUnknown, status.Reachability)
+	require.Empty(t, status.RelayAddrs)
+}
+
+func TestLibp2pHost_relayCandidates(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+
+	cfg := basicTestConfig(t)
+	cfg.Bootnodes = []string{h1.Addresses()[0].String()}
+	h2 := newHost(t, cfg)
+	h3 := newHost(t, basicTestConfig(t))
+
+	err := h2.h.Connect(context.Background(), h3.AddrInfo())
+	require.NoError(t, err)
+
+	lh := h2.h.(*libp2pHost)
+	var candidates []peer.ID
+	for ai := range lh.relayCandidates(context.Background(), 10) {
+		candidates = append(candidates, ai.ID)
+	}
+	require.Equal(t, []peer.ID{h1.PeerID(), h3.PeerID()}, candidates)
+
+	// the bootnode comes first
+	candidates = nil
+	for ai := range lh.relayCandidates(context.Background(), 1) {
+		candidates = append(candidates, ai.ID)
+	}
+	require.Equal(t, []peer.ID{h1.PeerID()}, candidates)
+}
@@ -0,0 +1,251 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	offersTopic = "/offers/0"
+
+	// offerAnnounceInterval is how often makers publish their offers when they
+	// don't change. Offers that are not announced again within gossipedOffersTTL
+	// are dropped, as their maker is likely gone.
+	offerAnnounceInterval = time.Minute
+	gossipedOffersTTL     = offerAnnounceInterval * 3
+
+	// maxAnnouncementClockSkew is how far in the future the timestamps of offer
+	// announcements can be
+	maxAnnouncementClockSkew = time.Minute
+)
+
+// gossipedOffers are the offers of a maker from its latest announcement.
+type gossipedOffers struct {
+	offers    []*types.Offer
+	version   uint64
+	timestamp time.Time
+}
+
+// offerGossip publishes our offers on the offers topic, and keeps a live book of
+// the offers that the makers on the network publish on it, so that takers don't
+// need to find the makers in the DHT and query them.
+type offerGossip struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	self      peer.ID
+	key       crypto.PrivKey
+	topic     *pubsub.Topic
+	sub       *pubsub.Subscription
+	getOffers func() []*types.Offer
+	publishCh chan struct{}
+	version   uint64 // version of our latest announcement, only used by publishLoop
+
+	mu   sync.Mutex
+	book map[peer.ID]*gossipedOffers
+}
+
+// newOfferGossip joins the offers topic. Once started, the offers that getOffers
+// returns are published.
+func newOfferGossip(ctx context.Context, h P2pHost, getOffers func() []*types.Offer) (*offerGossip, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &offerGossip{
+		ctx:       ctx,
+		cancel:    cancel,
+		self:      h.PeerID(),
+		key:       h.PrivKey(),
+		getOffers: getOffers,
+		publishCh: make(chan struct{}, 1),
+		book:      make(map[peer.ID]*gossipedOffers),
+	}
+
+	var err error
+	g.topic, err = h.JoinTopic(offersTopic, validateOfferAnnouncement)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	g.sub, err = g.topic.Subscribe()
+	if err != nil {
+		cancel()
+		_ = g.topic.Close()
+		return nil, err
+	}
+
+	go g.readLoop()
+	return g, nil
+}
+
+// start starts publishing our offers.
+func (g *offerGossip) start() {
+	go g.publishLoop()
+}
+
+// validateOfferAnnouncement is the validator of the offers topic, which rejects the
+// messages that are not valid offer announcements signed by their maker, and
+// ignores the announcements that are too old, so that they are not relayed.
+func validateOfferAnnouncement(_ context.Context, _ peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+	decoded, err := message.DecodeMessage(msg.GetData())
+	if err != nil {
+		return pubsub.ValidationReject
+	}
+
+	announcement, ok := decoded.(*OfferAnnouncement)
+	if !ok {
+		return pubsub.ValidationReject
+	}
+
+	if err = announcement.Verify(); err != nil {
+		log.Debugf("rejecting offer announcement of maker %s: %s", announcement.Maker, err)
+		return pubsub.ValidationReject
+	}
+
+	timestamp := time.Unix(announcement.Timestamp, 0)
+	if time.Since(timestamp) > gossipedOffersTTL || time.Until(timestamp) > maxAnnouncementClockSkew {
+		return pubsub.ValidationIgnore
+	}
+
+	msg.ValidatorData = announcement
+	return pubsub.ValidationAccept
+}
+
+// publish signals the publish loop to publish our offers now.
+func (g *offerGossip) publish() {
+	select {
+	case g.publishCh <- struct{}{}:
+	default:
+		// a publication is already pending
+	}
+}
+
+func (g *offerGossip) publishLoop() {
+	// whether we have announced offers, in which case we keep announcing, even
+	// without offers, so that takers drop our removed offers right away
+	announced := false
+
+	for {
+		offers := g.getOffers()
+		if len(offers) > 0 || announced {
+			if err := g.publishOffers(offers); err != nil {
+				log.Debugf("failed to publish offers: %s", err)
+			} else {
+				announced = len(offers) > 0
+			}
+		}
+
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-g.publishCh:
+		case <-time.After(offerAnnounceInterval):
+		}
+	}
+}
+
+// publishOffers publishes an announcement of the offers with a higher version than
+// our previous announcements. The version is based on the current time, so that it
+// keeps increasing after a restart.
+func (g *offerGossip) publishOffers(offers []*types.Offer) error {
+	now := time.Now()
+	g.version++
+	if version := uint64(now.UnixNano()); version > g.version {
+		g.version = version
+	}
+
+	msg := &OfferAnnouncement{
+		Offers:    offers,
+		Version:   g.version,
+		Timestamp: now.Unix(),
+	}
+
+	if err := msg.Sign(g.key); err != nil {
+		return err
+	}
+
+	data, err := msg.Encode()
+	if err != nil {
+		return err
+	}
+
+	return g.topic.Publish(g.ctx, data)
+}
+
+func (g *offerGossip) readLoop() {
+	for {
+		msg, err := g.sub.Next(g.ctx)
+		if err != nil {
+			// the subscription was cancelled or the host is stopping
+			return
+		}
+
+		// the maker is not necessarily the author of the message, as signed
+		// announcements can be published again by other peers
+		announcement := msg.ValidatorData.(*OfferAnnouncement)
+		if announcement.Maker == g.self {
+			continue
+		}
+
+		g.addAnnouncement(announcement)
+	}
+}
+
+// addAnnouncement replaces the offers of the maker with the ones of the
+// announcement, unless we have an announcement of the maker with the same or a
+// higher version, so that replayed announcements can't add back removed offers.
+func (g *offerGossip) addAnnouncement(announcement *OfferAnnouncement) {
+	maker := announcement.Maker
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if prev, ok := g.book[maker]; ok && prev.version >= announcement.Version {
+		return
+	}
+
+	// makers without offers are kept, so that older announcements of their
+	// removed offers are ignored
+	log.Debugf("received %d offers of maker %s via gossip", len(announcement.Offers), maker)
+	g.book[maker] = &gossipedOffers{
+		offers:    announcement.Offers,
+		version:   announcement.Version,
+		timestamp: time.Unix(announcement.Timestamp, 0),
+	}
+}
+
+// offers returns the offers of the makers that announced them within
+// gossipedOffersTTL, dropping the rest.
+func (g *offerGossip) offers() map[peer.ID][]*types.Offer {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	offers := make(map[peer.ID][]*types.Offer, len(g.book))
+	for maker, o := range g.book {
+		if time.Since(o.timestamp) > gossipedOffersTTL {
+			delete(g.book, maker)
+			continue
+		}
+		if len(o.offers) > 0 {
+			offers[maker] = o.offers
+		}
+	}
+
+	return offers
+}
+
+// Close stops publishing our offers and leaves the offers topic.
+func (g *offerGossip) Close() error {
+	g.cancel()
+	g.sub.Cancel()
+	return g.topic.Close()
+}
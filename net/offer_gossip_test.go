@@ -0,0 +1,154 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"testing"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+type mockOffersMakerHandler struct {
+	mockMakerHandler
+	mu     sync.Mutex
+	offers []*types.Offer
+}
+
+func (h *mockOffersMakerHandler) GetOffers() []*types.Offer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.offers
+}
+
+func (h *mockOffersMakerHandler) setOffers(offers ...*types.Offer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.offers = offers
+}
+
+func newTestOffer() *types.Offer {
+	one := coins.StrToDecimal("1")
+	return types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+}
+
+func TestHost_GossipedOffers(t *testing.T) {
+	maker := newHost(t, basicTestConfig(t))
+	makerHandler := &mockOffersMakerHandler{mockMakerHandler: mockMakerHandler{t: t}}
+	maker.SetHandlers(makerHandler, &mockRelayHandler{t: t})
+	require.NoError(t, maker.Start())
+
+	taker := newHost(t, basicTestConfig(t))
+	require.NoError(t, taker.Start())
+
+	err := taker.h.Connect(context.Background(), maker.AddrInfo())
+	require.NoError(t, err)
+
+	offer := newTestOffer()
+	makerHandler.setOffers(offer)
+	require.Eventually(t, func() bool {
+		maker.Advertise()
+		return len(taker.GossipedOffers()[maker.PeerID()]) == 1
+	}, time.Second*10, time.Millisecond*500)
+	require.Equal(t, offer.ID, taker.GossipedOffers()[maker.PeerID()][0].ID)
+
+	// the maker doesn't see its own offers
+	require.Empty(t, maker.GossipedOffers())
+
+	// removed offers are dropped once the maker publishes its offers again
+	makerHandler.setOffers()
+	maker.Advertise()
+	require.Eventually(t, func() bool {
+		return len(taker.GossipedOffers()) == 0
+	}, time.Second*10, time.Millisecond*100)
+}
+
+func TestOfferGossip_addAnnouncement(t *testing.T) {
+	g := &offerGossip{book: make(map[peer.ID]*gossipedOffers)}
+	maker := peer.ID("maker")
+	offer1 := newTestOffer()
+	offer2 := newTestOffer()
+	now := time.Now().Unix()
+
+	announce := func(maker peer.ID, version uint64, timestamp int64, offers ...*types.Offer) {
+		g.addAnnouncement(&OfferAnnouncement{Maker: maker, Offers: offers, Version: version, Timestamp: timestamp})
+	}
+
+	announce(maker, 2, now, offer1)
+	require.Equal(t, map[peer.ID][]*types.Offer{maker: {offer1}}, g.offers())
+
+	// announcements of lower or equal versions are ignored
+	announce(maker, 1, now, offer2)
+	announce(maker, 2, now, offer2)
+	require.Equal(t, map[peer.ID][]*types.Offer{maker: {offer1}}, g.offers())
+
+	// the offers are removed by an announcement without offers, after which the
+	// replayed announcement can't add them back
+	announce(maker, 3, now)
+	require.Empty(t, g.offers())
+	announce(maker, 2, now, offer1)
+	require.Empty(t, g.offers())
+
+	// offers that are not announced again expire
+	expired := now - int64((gossipedOffersTTL + time.Second).Seconds())
+	announce(peer.ID("other"), 1, expired, offer2)
+	require.Empty(t, g.offers())
+}
+
+func TestValidateOfferAnnouncement(t *testing.T) {
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+
+	validate := func(msg Message) pubsub.ValidationResult {
+		data, encodeErr := msg.Encode()
+		require.NoError(t, encodeErr)
+		return validateOfferAnnouncement(context.Background(), "", &pubsub.Message{Message: &pb.Message{Data: data}})
+	}
+	signed := func(timestamp time.Time, offers ...*types.Offer) *OfferAnnouncement {
+		msg := &OfferAnnouncement{Offers: offers, Version: 1, Timestamp: timestamp.Unix()}
+		require.NoError(t, msg.Sign(key))
+		return msg
+	}
+
+	now := time.Now()
+	offer := newTestOffer()
+	require.Equal(t, pubsub.ValidationAccept, validate(signed(now, offer)))
+
+	// announcements that are too old or too far in the future are not relayed
+	old := now.Add(-gossipedOffersTTL - time.Second)
+	require.Equal(t, pubsub.ValidationIgnore, validate(signed(old, offer)))
+	future := now.Add(maxAnnouncementClockSkew + time.Minute)
+	require.Equal(t, pubsub.ValidationIgnore, validate(signed(future, offer)))
+
+	// announcements that were changed after being signed are rejected
+	msg := signed(now, offer)
+	msg.Offers = append(msg.Offers, newTestOffer())
+	require.Equal(t, pubsub.ValidationReject, validate(msg))
+
+	msg = signed(now, offer)
+	msg.Version++
+	require.Equal(t, pubsub.ValidationReject, validate(msg))
+
+	// as are announcements signed by a peer other than the maker
+	otherKey, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	msg = signed(now, offer)
+	maker := msg.Maker
+	require.NoError(t, msg.Sign(otherKey))
+	msg.Maker = maker
+	require.Equal(t, pubsub.ValidationReject, validate(msg))
+
+	// other messages are rejected
+	require.Equal(t, pubsub.ValidationReject, validate(&QueryResponse{Offers: []*types.Offer{offer}}))
+}
@@ -0,0 +1,195 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+const onionKeyFileName = "onion.key"
+
+// TorControlConfig is the configuration of the control port of the Tor daemon that
+// the host creates its onion service with.
+type TorControlConfig struct {
+	// Address is the "host:port" address of the control port, like 127.0.0.1:9051
+	Address string
+
+	// Password is the optional password of the control port. Without it, the
+	// control port's cookie file is used, if it has one.
+	Password string
+}
+
+// onionService is the Tor onion service of the host, whose connections are forwarded
+// by Tor to the host's listening port. Tor removes it when its control connection is
+// closed.
+type onionService struct {
+	conn *textproto.Conn
+	addr ma.Multiaddr
+}
+
+// newOnionService creates the onion service of the host listening on the local port.
+// Its key is read from the key file, or written to it when the service is new, so
+// that the onion address stays the same across restarts.
+func newOnionService(cfg *TorControlConfig, keyFile string, port string) (*onionService, error) {
+	conn, err := textproto.Dial("tcp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Tor control port: %w", err)
+	}
+
+	s := &onionService{conn: conn}
+	if err = s.authenticate(cfg.Password); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	if err = s.addOnion(keyFile, port); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	log.Infof("listening on onion service %s", s.addr)
+	return s, nil
+}
+
+// command sends the command to the control port, and returns the lines of its
+// successful reply.
+func (s *onionService) command(format string, args ...any) ([]string, error) {
+	id, err := s.conn.Cmd(format, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.conn.StartResponse(id)
+	defer s.conn.EndResponse(id)
+
+	_, msg, err := s.conn.ReadResponse(250)
+	if err != nil {
+		return nil, fmt.Errorf("tor control command failed: %w", err)
+	}
+
+	return strings.Split(msg, "\n"), nil
+}
+
+func (s *onionService) authenticate(password string) error {
+	if password != "" {
+		_, err := s.command("AUTHENTICATE %s", strconv.Quote(password))
+		return err
+	}
+
+	lines, err := s.command("PROTOCOLINFO 1")
+	if err != nil {
+		return err
+	}
+
+	methods, cookieFile := parseAuthMethods(lines)
+	switch {
+	case methods["NULL"]:
+		_, err = s.command("AUTHENTICATE")
+		return err
+	case methods["COOKIE"] && cookieFile != "":
+		var cookie []byte
+		cookie, err = os.ReadFile(filepath.Clean(cookieFile))
+		if err != nil {
+			return fmt.Errorf("failed to read Tor control cookie: %w", err)
+		}
+		_, err = s.command("AUTHENTICATE %s", hex.EncodeToString(cookie))
+		return err
+	default:
+		return errors.New("the Tor control port requires a password")
+	}
+}
+
+// parseAuthMethods returns the authentication methods and cookie file of the reply
+// to PROTOCOLINFO, whose AUTH line is like:
+//
+//	AUTH METHODS=COOKIE,SAFECOOKIE COOKIEFILE="/run/tor/control.authcookie"
+func parseAuthMethods(lines []string) (map[string]bool, string) {
+	methods := make(map[string]bool)
+	cookieFile := ""
+
+	for _, line := range lines {
+		auth, found := strings.CutPrefix(line, "AUTH ")
+		if !found {
+			continue
+		}
+
+		for _, field := range strings.Fields(auth) {
+			if list, isMethods := strings.CutPrefix(field, "METHODS="); isMethods {
+				for _, method := range strings.Split(list, ",") {
+					methods[method] = true
+				}
+			}
+		}
+
+		if _, quoted, hasCookie := strings.Cut(auth, "COOKIEFILE="); hasCookie {
+			if prefix, err := strconv.QuotedPrefix(quoted); err == nil {
+				cookieFile, _ = strconv.Unquote(prefix)
+			}
+		}
+	}
+
+	return methods, cookieFile
+}
+
+func (s *onionService) addOnion(keyFile string, port string) error {
+	keySpec := "NEW:ED25519-V3"
+
+	exists, err := common.FileExists(keyFile)
+	if err != nil {
+		return err
+	}
+	if exists {
+		var key []byte
+		key, err = os.ReadFile(filepath.Clean(keyFile))
+		if err != nil {
+			return err
+		}
+		keySpec = strings.TrimSpace(string(key))
+	}
+
+	lines, err := s.command("ADD_ONION %s Port=%s,127.0.0.1:%s", keySpec, port, port)
+	if err != nil {
+		return err
+	}
+
+	serviceID := ""
+	for _, line := range lines {
+		if id, found := strings.CutPrefix(line, "ServiceID="); found {
+			serviceID = id
+		}
+		if privKey, found := strings.CutPrefix(line, "PrivateKey="); found {
+			if err = os.WriteFile(filepath.Clean(keyFile), []byte(privKey), 0600); err != nil {
+				return err
+			}
+		}
+	}
+
+	if serviceID == "" {
+		return errors.New("the ID of the onion service was not returned by Tor")
+	}
+
+	s.addr, err = ma.NewMultiaddr(fmt.Sprintf("/onion3/%s:%s", serviceID, port))
+	return err
+}
+
+// Multiaddr returns the address of the onion service.
+func (s *onionService) Multiaddr() ma.Multiaddr {
+	return s.addr
+}
+
+// Close removes the onion service.
+func (s *onionService) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,27 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthMethods(t *testing.T) {
+	lines := []string{
+		"PROTOCOLINFO 1",
+		`AUTH METHODS=COOKIE,SAFECOOKIE COOKIEFILE="/run/tor/control.authcookie"`,
+		`VERSION Tor="0.4.7.13"`,
+		"OK",
+	}
+
+	methods, cookieFile := parseAuthMethods(lines)
+	require.Equal(t, map[string]bool{"COOKIE": true, "SAFECOOKIE": true}, methods)
+	require.Equal(t, "/run/tor/control.authcookie", cookieFile)
+
+	methods, cookieFile = parseAuthMethods([]string{"PROTOCOLINFO 1", "AUTH METHODS=NULL", "OK"})
+	require.Equal(t, map[string]bool{"NULL": true}, methods)
+	require.Empty(t, cookieFile)
+}
@@ -0,0 +1,196 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	peerExchangeProtocolID = "/px/0"
+
+	// peerExchangeDelay gives the connections to the bootnodes and recent peers
+	// time to be identified, before the first peer exchange.
+	peerExchangeDelay    = time.Second * 10
+	peerExchangeInterval = time.Minute * 5
+
+	// peerExchangeTargetPeers is the number of peers below which we connect to the
+	// peers that we learn of by peer exchange.
+	peerExchangeTargetPeers = 10
+	peerExchangeSampleSize  = 8
+)
+
+// handlePeerExchangeStream responds with a sample of our swap peers, leaving out
+// the requesting peer.
+func (lh *libp2pHost) handlePeerExchangeStream(stream libp2pnetwork.Stream) {
+	defer func() { _ = stream.Close() }()
+
+	curPeer := stream.Conn().RemotePeer()
+	resp := &PeerExchange{
+		Peers: lh.peerExchangeSample(curPeer),
+	}
+
+	if err := lh.codec.writeStreamMessage(stream, resp); err != nil {
+		log.Debugf("failed to send PeerExchange message to peer: %s", err)
+	}
+}
+
+// swapPeers returns the connected peers that support the peer exchange protocol,
+// which are the swapd instances and bootnodes on our network.
+func (lh *libp2pHost) swapPeers() []peer.ID {
+	pid := protocol.ID(lh.protocolID + peerExchangeProtocolID)
+
+	var peers []peer.ID
+	for _, p := range lh.h.Network().Peers() {
+		supported, err := lh.h.Peerstore().SupportsProtocols(p, pid)
+		if err == nil && len(supported) > 0 {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// peerExchangeSample returns the addresses of up to peerExchangeSampleSize random
+// swap peers, other than the excluded peer, with their peer IDs.
+func (lh *libp2pHost) peerExchangeSample(exclude peer.ID) []string {
+	peers := lh.swapPeers()
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] }) //nolint:gosec
+
+	var addrs []string
+	numPeers := 0
+	for _, p := range peers {
+		if p == exclude {
+			continue
+		}
+
+		ai := lh.h.Peerstore().PeerInfo(p)
+		p2pAddrs, err := peer.AddrInfoToP2pAddrs(&ai)
+		if err != nil || len(p2pAddrs) == 0 {
+			continue
+		}
+
+		if len(addrs)+len(p2pAddrs) > message.MaxPeerExchangeAddrs {
+			break
+		}
+		for _, addr := range p2pAddrs {
+			addrs = append(addrs, addr.String())
+		}
+
+		numPeers++
+		if numPeers == peerExchangeSampleSize {
+			break
+		}
+	}
+
+	return addrs
+}
+
+// exchangePeers asks the peer for a sample of its swap peers.
+func (lh *libp2pHost) exchangePeers(ctx context.Context, p peer.ID) ([]peer.AddrInfo, error) {
+	stream, err := lh.NewStream(ctx, p, peerExchangeProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	defer func() { _ = stream.Close() }()
+
+	var resp *PeerExchange
+	select {
+	case msg := <-lh.codec.nextStreamMessage(stream, maxMessageSize):
+		if msg == nil {
+			return nil, errors.New("failed to read PeerExchange")
+		}
+
+		var ok bool
+		resp, ok = msg.(*PeerExchange)
+		if !ok {
+			return nil, fmt.Errorf("expected %s message but received %s",
+				message.TypeToString(message.PeerExchangeType),
+				message.TypeToString(msg.Type()))
+		}
+	case <-ctx.Done():
+		return nil, errors.New("timed out waiting for PeerExchange")
+	}
+
+	var addrs []ma.Multiaddr
+	for _, addrStr := range resp.Peers {
+		addr, parseErr := ma.NewMultiaddr(addrStr)
+		if parseErr != nil {
+			log.Debugf("ignoring invalid address %q from peer exchange with %s", addrStr, p)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+
+	return peer.AddrInfosFromP2pAddrs(addrs...)
+}
+
+func (lh *libp2pHost) peerExchangeLoop() {
+	delay := peerExchangeDelay
+	for {
+		select {
+		case <-lh.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		lh.peerExchange()
+		delay = peerExchangeInterval
+	}
+}
+
+// peerExchange asks a random swap peer for a sample of its swap peers, and
+// connects to the ones that we are not connected to, while we have fewer than
+// peerExchangeTargetPeers peers. This way, the network stays connected when the
+// bootnodes are down.
+func (lh *libp2pHost) peerExchange() {
+	numPeers := len(lh.h.Network().Peers())
+	if numPeers >= peerExchangeTargetPeers {
+		return
+	}
+
+	peers := lh.swapPeers()
+	if len(peers) == 0 {
+		return
+	}
+	p := peers[rand.Intn(len(peers))] //nolint:gosec
+
+	ctx, cancel := context.WithTimeout(lh.ctx, lh.dialTimeout)
+	found, err := lh.exchangePeers(ctx, p)
+	cancel()
+	if err != nil {
+		log.Debugf("failed to exchange peers with %s: %s", p, err)
+		return
+	}
+
+	for _, ai := range found {
+		if numPeers >= peerExchangeTargetPeers {
+			return
+		}
+		if ai.ID == lh.h.ID() || lh.h.Network().Connectedness(ai.ID) == libp2pnetwork.Connected {
+			continue
+		}
+
+		log.Debugf("found new peer via peer exchange with %s: %s", p, ai)
+		ctx, cancel = context.WithTimeout(lh.ctx, lh.dialTimeout)
+		err = lh.h.Connect(ctx, ai)
+		cancel()
+		if err != nil {
+			log.Debugf("failed to connect to peer %s from peer exchange: %s", ai.ID, err)
+			continue
+		}
+		numPeers++
+	}
+}
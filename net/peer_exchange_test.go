@@ -0,0 +1,70 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func requireSwapPeers(t *testing.T, h *Host, peers ...peer.ID) {
+	require.Eventually(t, func() bool {
+		return len(h.h.(*libp2pHost).swapPeers()) == len(peers)
+	}, time.Second*5, time.Millisecond*50)
+	require.ElementsMatch(t, peers, h.h.(*libp2pHost).swapPeers())
+}
+
+func TestLibp2pHost_exchangePeers(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+	h3 := newHost(t, basicTestConfig(t))
+
+	// h1 is connected to both others, like a bootnode
+	err := h1.h.Connect(context.Background(), h2.AddrInfo())
+	require.NoError(t, err)
+	err = h1.h.Connect(context.Background(), h3.AddrInfo())
+	require.NoError(t, err)
+	requireSwapPeers(t, h1, h2.PeerID(), h3.PeerID())
+	requireSwapPeers(t, h2, h1.PeerID())
+
+	// h2 only learns of h3, as it is left out of the sample that it gets
+	found, err := h2.h.(*libp2pHost).exchangePeers(context.Background(), h1.PeerID())
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, h3.PeerID(), found[0].ID)
+	require.NotEmpty(t, found[0].Addrs)
+
+	h2.h.(*libp2pHost).peerExchange()
+	require.Equal(t, libp2pnetwork.Connected, h2.h.Connectedness(h3.PeerID()))
+}
+
+func TestLibp2pHost_peerExchangeSample(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+
+	var peers []peer.ID
+	for i := 0; i < peerExchangeSampleSize+2; i++ {
+		h := newHost(t, basicTestConfig(t))
+		err := h1.h.Connect(context.Background(), h.AddrInfo())
+		require.NoError(t, err)
+		peers = append(peers, h.PeerID())
+	}
+	requireSwapPeers(t, h1, peers...)
+
+	var addrs []ma.Multiaddr
+	for _, addrStr := range h1.h.(*libp2pHost).peerExchangeSample("") {
+		addrs = append(addrs, ma.StringCast(addrStr))
+	}
+	found, err := peer.AddrInfosFromP2pAddrs(addrs...)
+	require.NoError(t, err)
+	require.Len(t, found, peerExchangeSampleSize)
+	for _, ai := range found {
+		require.Contains(t, peers, ai.ID)
+	}
+}
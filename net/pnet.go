@@ -0,0 +1,42 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/libp2p/go-libp2p/core/pnet"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+// swarmKeyFileName is the file in the data directory with the pre-shared key of the
+// private network, in the format of the IPFS swarm.key files. When it exists, the
+// host only connects to the peers that have the same key.
+const swarmKeyFileName = "swarm.key"
+
+// loadSwarmKey returns the pre-shared key of the swarm key file in the data
+// directory, or nil if there is no such file.
+func loadSwarmKey(dataDir string) (pnet.PSK, error) {
+	keyFile := filepath.Join(dataDir, swarmKeyFileName)
+	exists, err := common.FileExists(keyFile)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Clean(keyFile))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	psk, err := pnet.DecodeV1PSK(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid swarm key file %s: %w", keyFile, err)
+	}
+
+	return psk, nil
+}
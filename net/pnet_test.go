@@ -0,0 +1,81 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeSwarmKey(t *testing.T, dataDir string, key []byte) {
+	data := fmt.Sprintf("/key/swarm/psk/1.0.0/\n/base16/\n%s\n", hex.EncodeToString(key))
+	err := os.WriteFile(filepath.Join(dataDir, swarmKeyFileName), []byte(data), 0600)
+	require.NoError(t, err)
+}
+
+func newSwarmKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func TestLoadSwarmKey(t *testing.T) {
+	dataDir := t.TempDir()
+	psk, err := loadSwarmKey(dataDir)
+	require.NoError(t, err)
+	require.Nil(t, psk)
+
+	key := newSwarmKey(t)
+	writeSwarmKey(t, dataDir, key)
+	psk, err = loadSwarmKey(dataDir)
+	require.NoError(t, err)
+	require.Equal(t, key, []byte(psk))
+
+	err = os.WriteFile(filepath.Join(dataDir, swarmKeyFileName), []byte("not a key"), 0600)
+	require.NoError(t, err)
+	_, err = loadSwarmKey(dataDir)
+	require.Error(t, err)
+}
+
+func TestHost_privateNetwork(t *testing.T) {
+	key := newSwarmKey(t)
+
+	cfg1 := basicTestConfig(t)
+	writeSwarmKey(t, cfg1.DataDir, key)
+	h1 := newHost(t, cfg1)
+
+	cfg2 := basicTestConfig(t)
+	writeSwarmKey(t, cfg2.DataDir, key)
+	h2 := newHost(t, cfg2)
+
+	// hosts with another key, or without a key, can't connect to the private network
+	cfg3 := basicTestConfig(t)
+	writeSwarmKey(t, cfg3.DataDir, newSwarmKey(t))
+	h3 := newHost(t, cfg3)
+	h4 := newHost(t, basicTestConfig(t))
+
+	for _, addr := range h1.Addresses() {
+		require.NotContains(t, addr.String(), "quic")
+	}
+
+	err := h2.h.Connect(h2.ctx, h1.AddrInfo())
+	require.NoError(t, err)
+	resp, err := h2.Query(h1.PeerID(), nil)
+	require.NoError(t, err)
+	require.Empty(t, resp.Offers)
+
+	err = h3.h.Connect(h3.ctx, h1.AddrInfo())
+	require.Error(t, err)
+	err = h4.h.Connect(h4.ctx, h1.AddrInfo())
+	require.Error(t, err)
+	err = h1.h.Connect(h1.ctx, h4.AddrInfo())
+	require.Error(t, err)
+}
@@ -0,0 +1,190 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/libp2p/go-libp2p"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/transport"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+// proxyOptions returns the libp2p options of a host whose peers are dialed through
+// the proxy. The addresses that the host advertises are only the ones returned by
+// advertisedAddrs, so that the IP addresses of its interfaces aren't revealed.
+func proxyOptions(proxy *common.Proxy, advertisedAddrs func() []ma.Multiaddr) []libp2p.Option {
+	return []libp2p.Option{
+		// replaces the default transports, so no connection bypasses the proxy
+		libp2p.Transport(newProxyTransportFunc(proxy)),
+		libp2p.AddrsFactory(func([]ma.Multiaddr) []ma.Multiaddr {
+			return advertisedAddrs()
+		}),
+	}
+}
+
+// proxyTransport is a libp2p transport of TCP connections made through a SOCKS5
+// proxy. It dials /ip4, /ip6, /dns and /onion3 addresses, and listens like the TCP
+// transport.
+type proxyTransport struct {
+	proxy    *common.Proxy
+	upgrader transport.Upgrader
+	rcmgr    libp2pnetwork.ResourceManager
+}
+
+var _ transport.Transport = (*proxyTransport)(nil)
+
+// newProxyTransportFunc returns the constructor of the proxy transport that libp2p
+// calls when it creates the host.
+func newProxyTransportFunc(
+	proxy *common.Proxy,
+) func(transport.Upgrader, libp2pnetwork.ResourceManager) (*proxyTransport, error) {
+	return func(upgrader transport.Upgrader, rcmgr libp2pnetwork.ResourceManager) (*proxyTransport, error) {
+		if rcmgr == nil {
+			rcmgr = &libp2pnetwork.NullResourceManager{}
+		}
+
+		return &proxyTransport{
+			proxy:    proxy,
+			upgrader: upgrader,
+			rcmgr:    rcmgr,
+		}, nil
+	}
+}
+
+// CanDial returns whether the address can be dialed through the proxy.
+func (t *proxyTransport) CanDial(addr ma.Multiaddr) bool {
+	_, err := proxyDialAddress(addr)
+	return err == nil
+}
+
+// Dial connects to the peer at the address through the proxy.
+func (t *proxyTransport) Dial(ctx context.Context, raddr ma.Multiaddr, p peer.ID) (transport.CapableConn, error) {
+	connScope, err := t.rcmgr.OpenConnection(libp2pnetwork.DirOutbound, true, raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := t.dial(ctx, raddr, p, connScope)
+	if err != nil {
+		connScope.Done()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (t *proxyTransport) dial(
+	ctx context.Context,
+	raddr ma.Multiaddr,
+	p peer.ID,
+	connScope libp2pnetwork.ConnManagementScope,
+) (transport.CapableConn, error) {
+	if err := connScope.SetPeer(p); err != nil {
+		return nil, err
+	}
+
+	addr, err := proxyDialAddress(raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, connectionTimeout)
+	defer cancel()
+
+	conn, err := t.proxy.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	laddr, err := manet.FromNetAddr(conn.LocalAddr())
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	maconn := &proxyConn{Conn: conn, laddr: laddr, raddr: raddr}
+	return t.upgrader.Upgrade(ctx, t, maconn, libp2pnetwork.DirOutbound, p, connScope)
+}
+
+// Listen listens for connections on the TCP address, which are the connections of
+// the host's onion service when it has one.
+func (t *proxyTransport) Listen(laddr ma.Multiaddr) (transport.Listener, error) {
+	l, err := manet.Listen(laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.upgrader.UpgradeListener(t, l), nil
+}
+
+// Protocols returns the protocols of the addresses that the transport dials.
+func (t *proxyTransport) Protocols() []int {
+	return []int{ma.P_TCP, ma.P_ONION3}
+}
+
+// Proxy returns false, as the transport doesn't relay connections over other libp2p
+// transports.
+func (t *proxyTransport) Proxy() bool {
+	return false
+}
+
+func (t *proxyTransport) String() string {
+	return fmt.Sprintf("SOCKS5 proxy %s", t.proxy.Addr())
+}
+
+// proxyDialAddress returns the "host:port" address that the proxy connects to for the
+// multiaddress.
+func proxyDialAddress(addr ma.Multiaddr) (string, error) {
+	first, rest := ma.SplitFirst(addr)
+	if first == nil {
+		return "", errors.New("cannot dial empty address through proxy")
+	}
+
+	switch first.Protocol().Code {
+	case ma.P_ONION3:
+		// the value is the service ID and the port, separated by a colon
+		serviceID, port, found := strings.Cut(first.Value(), ":")
+		if !found || rest != nil {
+			return "", fmt.Errorf("cannot dial %s through proxy", addr)
+		}
+		return net.JoinHostPort(serviceID+".onion", port), nil
+	case ma.P_IP4, ma.P_IP6, ma.P_DNS, ma.P_DNS4, ma.P_DNS6:
+		if rest == nil {
+			return "", fmt.Errorf("cannot dial %s through proxy", addr)
+		}
+		tcp, tail := ma.SplitFirst(rest)
+		if tcp == nil || tcp.Protocol().Code != ma.P_TCP || tail != nil {
+			return "", fmt.Errorf("cannot dial %s through proxy", addr)
+		}
+		return net.JoinHostPort(first.Value(), tcp.Value()), nil
+	default:
+		return "", fmt.Errorf("cannot dial %s through proxy", addr)
+	}
+}
+
+// proxyConn is a connection made through the proxy, whose remote address is the
+// dialed multiaddress instead of the proxy's.
+type proxyConn struct {
+	net.Conn
+	laddr ma.Multiaddr
+	raddr ma.Multiaddr
+}
+
+func (c *proxyConn) LocalMultiaddr() ma.Multiaddr {
+	return c.laddr
+}
+
+func (c *proxyConn) RemoteMultiaddr() ma.Multiaddr {
+	return c.raddr
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyDialAddress(t *testing.T) {
+	const serviceID = "vww6ybal4bd7szmgncyruucpgfkqahzddi37ktceo3ah7ngmcopnpyyd"
+
+	for addr, expected := range map[string]string{
+		"/ip4/1.2.3.4/tcp/9900":                  "1.2.3.4:9900",
+		"/ip6/::1/tcp/9900":                      "[::1]:9900",
+		"/dns/node.example.com/tcp/9900":         "node.example.com:9900",
+		"/onion3/" + serviceID + ":9900":         serviceID + ".onion:9900",
+		"/dns4/bootnode.example.com/tcp/443":     "bootnode.example.com:443",
+		"/dns6/bootnode.example.com/tcp/443":     "bootnode.example.com:443",
+		"/ip4/1.2.3.4/udp/9900/quic-v1":          "",
+		"/ip4/1.2.3.4/tcp/9900/ws":               "",
+		"/ip4/1.2.3.4":                           "",
+		"/onion3/" + serviceID + ":9900/tcp/443": "",
+	} {
+		maddr, err := ma.NewMultiaddr(addr)
+		require.NoError(t, err)
+
+		dialAddr, err := proxyDialAddress(maddr)
+		if expected == "" {
+			require.ErrorContains(t, err, "cannot dial", addr)
+			continue
+		}
+		require.NoError(t, err, addr)
+		require.Equal(t, expected, dialAddr)
+	}
+}
@@ -9,15 +9,22 @@ import (
 	"fmt"
 	"time"
 
-	p2pnet "github.com/athanorlabs/go-p2p-net"
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 const (
+	// queryProtocolID is the protocol of queries without a filter, which are
+	// answered with all of our offers. Makers still serve it for older takers.
 	queryProtocolID = "/query/0"
+
+	// filteredQueryProtocolID is the protocol of queries whose filter is sent in a
+	// QueryRequest, which are answered with the offers matching the filter.
+	filteredQueryProtocolID = "/query/1"
+	queryRequestTimeout     = time.Second * 5
 )
 
 func (h *Host) handleQueryStream(stream libp2pnetwork.Stream) {
@@ -27,23 +34,58 @@ func (h *Host) handleQueryStream(stream libp2pnetwork.Stream) {
 		Offers: h.makerHandler.GetOffers(),
 	}
 
-	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
+	if err := h.codec.writeStreamMessage(stream, resp); err != nil {
+		log.Warnf("failed to send QueryResponse message to peer: err=%s", err)
+	}
+}
+
+func (h *Host) handleFilteredQueryStream(stream libp2pnetwork.Stream) {
+	defer func() { _ = stream.Close() }()
+
+	curPeer := stream.Conn().RemotePeer()
+
+	if err := stream.SetReadDeadline(time.Now().Add(queryRequestTimeout)); err != nil {
+		log.Debugf("failed to set read deadline of query stream: %s", err)
+		return
+	}
+
+	msg, err := h.codec.readStreamMessage(stream, maxMessageSize)
+	if err != nil {
+		log.Debugf("error reading QueryRequest: %s", err)
+		h.reportInvalidMessage(curPeer, err)
+		return
+	}
+
+	req, ok := msg.(*QueryRequest)
+	if !ok {
+		log.Debugf("ignoring wrong message type=%s sent to query stream from %s",
+			message.TypeToString(msg.Type()), curPeer)
+		h.reportWrongMessageType(curPeer, msg, stream.Protocol())
+		return
+	}
+
+	resp := &QueryResponse{
+		Offers: req.Filter.Filter(h.makerHandler.GetOffers()),
+	}
+
+	if err := h.codec.writeStreamMessage(stream, resp); err != nil {
 		log.Warnf("failed to send QueryResponse message to peer: err=%s", err)
 	}
 }
 
-// Query queries the given peer for its offers.
-func (h *Host) Query(who peer.ID) (*QueryResponse, error) {
-	ctx, cancel := context.WithTimeout(h.ctx, connectionTimeout)
+// Query queries the given peer for its offers that match the filter, or all of its
+// offers if the filter is nil.
+func (h *Host) Query(who peer.ID, filter *types.OfferFilter) (*QueryResponse, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, h.dialTimeout)
 	defer cancel()
 
 	if err := h.h.Connect(ctx, peer.AddrInfo{ID: who}); err != nil {
 		return nil, err
 	}
 
-	stream, err := h.h.NewStream(ctx, who, queryProtocolID)
+	stream, err := h.openQueryStream(ctx, who, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+		return nil, err
 	}
 
 	log.Debugf("opened query stream: %s", stream.Conn())
@@ -52,14 +94,51 @@ func (h *Host) Query(who peer.ID) (*QueryResponse, error) {
 		_ = stream.Close()
 	}()
 
-	return receiveQueryResponse(stream)
+	resp, err := h.receiveQueryResponse(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	// makers of older versions don't filter their offers
+	resp.Offers = filter.Filter(resp.Offers)
+	return resp, nil
+}
+
+// openQueryStream opens a query stream with the peer, sending it the filter. If the
+// peer doesn't support filtered queries, an unfiltered query stream is opened.
+func (h *Host) openQueryStream(
+	ctx context.Context,
+	who peer.ID,
+	filter *types.OfferFilter,
+) (libp2pnetwork.Stream, error) {
+	stream, err := h.h.NewStream(ctx, who, filteredQueryProtocolID)
+	if err != nil {
+		log.Debugf("failed to open filtered query stream with peer, trying unfiltered query: %s", err)
+		stream, err = h.h.NewStream(ctx, who, queryProtocolID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+		}
+		return stream, nil
+	}
+
+	req := new(QueryRequest)
+	if filter != nil {
+		req.Filter = *filter
+	}
+
+	if err = h.codec.writeStreamMessage(stream, req); err != nil {
+		_ = stream.Close()
+		return nil, err
+	}
+
+	return stream, nil
 }
 
-func receiveQueryResponse(stream libp2pnetwork.Stream) (*QueryResponse, error) {
+func (h *Host) receiveQueryResponse(stream libp2pnetwork.Stream) (*QueryResponse, error) {
 	const queryResponseTimeout = time.Second * 15
 
 	select {
-	case msg := <-nextStreamMessage(stream, maxMessageSize):
+	case msg := <-h.codec.nextStreamMessage(stream, maxMessageSize):
 		if msg == nil {
 			return nil, errors.New("failed to read QueryResponse")
 		}
@@ -6,8 +6,10 @@ package net
 import (
 	"testing"
 
+	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/stretchr/testify/require"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 )
 
@@ -23,7 +25,44 @@ func TestHost_Query(t *testing.T) {
 	err = ha.h.Connect(ha.ctx, hb.h.AddrInfo())
 	require.NoError(t, err)
 
-	resp, err := ha.Query(hb.h.PeerID())
+	resp, err := ha.Query(hb.h.PeerID(), nil)
 	require.NoError(t, err)
 	require.Equal(t, []*types.Offer{}, resp.Offers)
 }
+
+func TestHost_Query_filter(t *testing.T) {
+	maker := newHost(t, basicTestConfig(t))
+	makerHandler := &mockOffersMakerHandler{mockMakerHandler: mockMakerHandler{t: t}}
+	maker.SetHandlers(makerHandler, &mockRelayHandler{t: t})
+	require.NoError(t, maker.Start())
+
+	taker := newHost(t, basicTestConfig(t))
+	require.NoError(t, taker.Start())
+
+	err := taker.h.Connect(taker.ctx, maker.h.AddrInfo())
+	require.NoError(t, err)
+
+	offer := newTestOffer()
+	makerHandler.setOffers(offer)
+
+	resp, err := taker.Query(maker.PeerID(), &types.OfferFilter{MaxAmount: coins.StrToDecimal("1")})
+	require.NoError(t, err)
+	require.Equal(t, []*types.Offer{offer}, resp.Offers)
+
+	resp, err = taker.Query(maker.PeerID(), &types.OfferFilter{MinAmount: coins.StrToDecimal("2")})
+	require.NoError(t, err)
+	require.Empty(t, resp.Offers)
+
+	// makers that don't support filtered queries are queried for all their offers,
+	// which are filtered by the taker
+	lh := maker.h.(*libp2pHost)
+	lh.h.RemoveStreamHandler(protocol.ID(lh.protocolID + filteredQueryProtocolID))
+
+	resp, err = taker.Query(maker.PeerID(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Offer{offer}, resp.Offers)
+
+	resp, err = taker.Query(maker.PeerID(), &types.OfferFilter{MinAmount: coins.StrToDecimal("2")})
+	require.NoError(t, err)
+	require.Empty(t, resp.Offers)
+}
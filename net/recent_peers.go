@@ -0,0 +1,226 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/libp2p/go-libp2p/core/event"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	recentPeersPrefix = "/swapd/recent-peers"
+	recentPeerExpiry  = time.Hour * 72
+	maxRecentPeers    = 20
+)
+
+// recentPeer is the record of a peer that we were connected to, which is kept in
+// the datastore so that we can reconnect to it after a restart. Without it, we
+// only know the bootnodes after a restart, and peers don't find us until our
+// namespaces are advertised again.
+type recentPeer struct {
+	LastSeen  time.Time `json:"lastSeen"`
+	Addrs     []string  `json:"addrs"`
+	Protocols []string  `json:"protocols"`
+}
+
+func recentPeerKey(p peer.ID) datastore.Key {
+	return datastore.NewKey(recentPeersPrefix).ChildString(p.String())
+}
+
+// watchRecentPeers records the peers that we connect to that speak our swap
+// protocols, once they have told us their listen addresses and protocols, and again
+// when we disconnect from them.
+func (lh *libp2pHost) watchRecentPeers() error {
+	sub, err := lh.h.EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
+	if err != nil {
+		return err
+	}
+
+	lh.h.Network().Notify(&libp2pnetwork.NotifyBundle{
+		DisconnectedF: func(_ libp2pnetwork.Network, c libp2pnetwork.Conn) {
+			lh.saveRecentPeer(c.RemotePeer())
+		},
+	})
+
+	go func() {
+		defer func() { _ = sub.Close() }()
+
+		for {
+			select {
+			case <-lh.ctx.Done():
+				return
+			case e, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				lh.saveRecentPeer(e.(event.EvtPeerIdentificationCompleted).Peer)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// saveRecentPeer records that we have just seen the peer, along with its
+// addresses and swap protocols in our peerstore. Peers that don't speak any of
+// our swap protocols, like other nodes of the public DHT, are not recorded.
+func (lh *libp2pHost) saveRecentPeer(p peer.ID) {
+	addrs := lh.h.Peerstore().Addrs(p)
+	if len(addrs) == 0 {
+		return
+	}
+
+	protocols := lh.swapProtocols(p)
+	if len(protocols) == 0 {
+		return
+	}
+
+	rp := &recentPeer{
+		LastSeen:  time.Now(),
+		Addrs:     make([]string, 0, len(addrs)),
+		Protocols: protocols,
+	}
+	for _, addr := range addrs {
+		rp.Addrs = append(rp.Addrs, addr.String())
+	}
+
+	data, err := json.Marshal(rp)
+	if err != nil {
+		log.Warnf("failed to encode recent peer %s: %s", p, err)
+		return
+	}
+
+	// the host's context is cancelled when we disconnect from our peers on shutdown
+	if err = lh.ds.Put(context.Background(), recentPeerKey(p), data); err != nil {
+		log.Debugf("failed to save recent peer %s: %s", p, err)
+	}
+}
+
+// swapProtocols returns the protocols of the peer in our peerstore that have our
+// protocol ID as prefix.
+func (lh *libp2pHost) swapProtocols(p peer.ID) []string {
+	peerProtocols, err := lh.h.Peerstore().GetProtocols(p)
+	if err != nil {
+		return nil
+	}
+
+	var protocols []string
+	for _, pid := range peerProtocols {
+		if strings.HasPrefix(string(pid), lh.protocolID) {
+			protocols = append(protocols, string(pid))
+		}
+	}
+	return protocols
+}
+
+// loadRecentPeers returns up to maxRecentPeers of the peers that we have seen
+// within recentPeerExpiry, the most recently seen first, and adds their swap
+// protocols to our peerstore. The records of the peers seen before that, and the
+// records that can't be decoded or have no swap protocols, are deleted.
+func (lh *libp2pHost) loadRecentPeers() ([]peer.AddrInfo, error) {
+	results, err := lh.ds.Query(lh.ctx, query.Query{Prefix: recentPeersPrefix})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := results.Rest()
+	if err != nil {
+		return nil, err
+	}
+
+	type seenPeer struct {
+		ai       peer.AddrInfo
+		lastSeen time.Time
+	}
+	var peers []seenPeer
+
+	for _, entry := range entries {
+		key := datastore.NewKey(entry.Key)
+
+		var rp recentPeer
+		id, decodeErr := peer.Decode(key.BaseNamespace())
+		if decodeErr == nil {
+			decodeErr = json.Unmarshal(entry.Value, &rp)
+		}
+		if decodeErr != nil || len(rp.Protocols) == 0 || time.Since(rp.LastSeen) > recentPeerExpiry {
+			if err = lh.ds.Delete(lh.ctx, key); err != nil {
+				log.Debugf("failed to delete recent peer %s: %s", key, err)
+			}
+			continue
+		}
+
+		if id == lh.h.ID() {
+			continue
+		}
+
+		ai := peer.AddrInfo{ID: id}
+		for _, addrStr := range rp.Addrs {
+			addr, parseErr := ma.NewMultiaddr(addrStr)
+			if parseErr != nil {
+				continue
+			}
+			ai.Addrs = append(ai.Addrs, addr)
+		}
+
+		if len(ai.Addrs) == 0 {
+			continue
+		}
+
+		protocols := make([]protocol.ID, 0, len(rp.Protocols))
+		for _, pid := range rp.Protocols {
+			protocols = append(protocols, protocol.ID(pid))
+		}
+		if err = lh.h.Peerstore().AddProtocols(id, protocols...); err != nil {
+			log.Debugf("failed to add protocols of recent peer %s: %s", id, err)
+		}
+
+		peers = append(peers, seenPeer{ai: ai, lastSeen: rp.LastSeen})
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].lastSeen.After(peers[j].lastSeen)
+	})
+
+	addrInfos := make([]peer.AddrInfo, 0, maxRecentPeers)
+	for i := 0; i < len(peers) && i < maxRecentPeers; i++ {
+		addrInfos = append(addrInfos, peers[i].ai)
+	}
+
+	return addrInfos, nil
+}
+
+// connectRecentPeers connects in the background to the peers that we have seen
+// recently, which likely include makers that we found before a restart.
+func (lh *libp2pHost) connectRecentPeers() {
+	recentPeers, err := lh.loadRecentPeers()
+	if err != nil {
+		log.Warnf("failed to load recent peers: %s", err)
+		return
+	}
+
+	for _, ai := range recentPeers {
+		if lh.h.Network().Connectedness(ai.ID) == libp2pnetwork.Connected {
+			continue
+		}
+
+		go func(ai peer.AddrInfo) {
+			ctx, cancel := context.WithTimeout(lh.ctx, lh.dialTimeout)
+			defer cancel()
+			if connectErr := lh.h.Connect(ctx, ai); connectErr != nil {
+				log.Debugf("failed to reconnect to recent peer %s: %s", ai.ID, connectErr)
+			}
+		}(ai)
+	}
+}
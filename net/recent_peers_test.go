@@ -0,0 +1,169 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/libp2p/go-libp2p"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func recentPeerIDs(t *testing.T, lh *libp2pHost) []peer.ID {
+	recentPeers, err := lh.loadRecentPeers()
+	require.NoError(t, err)
+
+	var ids []peer.ID
+	for _, ai := range recentPeers {
+		require.NotEmpty(t, ai.Addrs)
+		ids = append(ids, ai.ID)
+	}
+	return ids
+}
+
+func TestLibp2pHost_recentPeers(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+	lh := h2.h.(*libp2pHost)
+
+	err := h2.h.Connect(context.Background(), h1.AddrInfo())
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(recentPeerIDs(t, lh)) == 1
+	}, time.Second*5, time.Millisecond*50)
+	require.Equal(t, []peer.ID{h1.PeerID()}, recentPeerIDs(t, lh))
+
+	// the swap protocols of the peer are recorded
+	data, err := lh.ds.Get(context.Background(), recentPeerKey(h1.PeerID()))
+	require.NoError(t, err)
+	var rp recentPeer
+	require.NoError(t, json.Unmarshal(data, &rp))
+	require.Contains(t, rp.Protocols, "/testid"+queryProtocolID)
+	for _, pid := range rp.Protocols {
+		require.True(t, strings.HasPrefix(pid, "/testid"), pid)
+	}
+}
+
+func TestLibp2pHost_recentPeers_notSwapPeer(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+	lh := h2.h.(*libp2pHost)
+
+	// a libp2p node that doesn't speak the swap protocols, like a DHT node
+	other, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = other.Close() })
+
+	err = h2.h.Connect(context.Background(), peer.AddrInfo{ID: other.ID(), Addrs: other.Addrs()})
+	require.NoError(t, err)
+	err = h2.h.Connect(context.Background(), h1.AddrInfo())
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(recentPeerIDs(t, lh)) == 1
+	}, time.Second*5, time.Millisecond*50)
+	require.NoError(t, other.Close())
+
+	// disconnecting doesn't record the other node either
+	require.Eventually(t, func() bool {
+		return h2.h.Connectedness(other.ID()) != libp2pnetwork.Connected
+	}, time.Second*5, time.Millisecond*50)
+	require.Equal(t, []peer.ID{h1.PeerID()}, recentPeerIDs(t, lh))
+}
+
+func TestLibp2pHost_recentPeers_expired(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+	lh := h2.h.(*libp2pHost)
+
+	data, err := json.Marshal(&recentPeer{
+		LastSeen:  time.Now().Add(-recentPeerExpiry - time.Minute),
+		Addrs:     []string{h1.AddrInfo().Addrs[0].String()},
+		Protocols: []string{"/testid" + queryProtocolID},
+	})
+	require.NoError(t, err)
+	err = lh.ds.Put(context.Background(), recentPeerKey(h1.PeerID()), data)
+	require.NoError(t, err)
+
+	require.Empty(t, recentPeerIDs(t, lh))
+
+	// the expired record was deleted
+	has, err := lh.ds.Has(context.Background(), recentPeerKey(h1.PeerID()))
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+func TestLibp2pHost_recentPeers_undecodable(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+	lh := h2.h.(*libp2pHost)
+
+	valid, err := json.Marshal(&recentPeer{
+		LastSeen:  time.Now(),
+		Addrs:     []string{h1.AddrInfo().Addrs[0].String()},
+		Protocols: []string{"/testid" + queryProtocolID},
+	})
+	require.NoError(t, err)
+	noProtocols, err := json.Marshal(&recentPeer{
+		LastSeen: time.Now(),
+		Addrs:    []string{h1.AddrInfo().Addrs[0].String()},
+	})
+	require.NoError(t, err)
+
+	badPeerKey := datastore.NewKey(recentPeersPrefix).ChildString("not-a-peer-id")
+	badValueKey := recentPeerKey(h1.PeerID())
+	require.NoError(t, lh.ds.Put(context.Background(), badPeerKey, valid))
+	require.NoError(t, lh.ds.Put(context.Background(), badValueKey, []byte("{")))
+
+	require.Empty(t, recentPeerIDs(t, lh))
+	for _, key := range []datastore.Key{badPeerKey, badValueKey} {
+		exists, hasErr := lh.ds.Has(context.Background(), key)
+		require.NoError(t, hasErr)
+		require.False(t, exists, key)
+	}
+
+	// records from before the protocols were recorded are deleted too
+	require.NoError(t, lh.ds.Put(context.Background(), badValueKey, noProtocols))
+	require.Empty(t, recentPeerIDs(t, lh))
+	has, err := lh.ds.Has(context.Background(), badValueKey)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// a valid record adds the peer's protocols to the peerstore
+	require.NoError(t, lh.ds.Put(context.Background(), badValueKey, valid))
+	require.Equal(t, []peer.ID{h1.PeerID()}, recentPeerIDs(t, lh))
+	supported, err := lh.h.Peerstore().SupportsProtocols(h1.PeerID(), "/testid"+queryProtocolID)
+	require.NoError(t, err)
+	require.NotEmpty(t, supported)
+}
+
+func TestHost_reconnectRecentPeersAfterRestart(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+
+	cfg := basicTestConfig(t)
+	h2, err := NewHost(cfg)
+	require.NoError(t, err)
+
+	err = h2.h.Connect(context.Background(), h1.AddrInfo())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(recentPeerIDs(t, h2.h.(*libp2pHost))) == 1
+	}, time.Second*5, time.Millisecond*50)
+	require.NoError(t, h2.Stop())
+
+	// without bootnodes, the restarted host only finds h1 from its recent peers
+	h2 = newHost(t, cfg)
+	require.NoError(t, h2.Start())
+	require.Eventually(t, func() bool {
+		return h2.h.Connectedness(h1.PeerID()) == libp2pnetwork.Connected
+	}, time.Second*5, time.Millisecond*50)
+}
@@ -9,10 +9,11 @@ import (
 	"fmt"
 	"time"
 
-	p2pnet "github.com/athanorlabs/go-p2p-net"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/metrics"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
@@ -25,30 +26,68 @@ const (
 )
 
 // DiscoverRelayers returns the peer IDs of hosts that advertised their willingness to
-// relay claim transactions.
+// relay claim transactions. If the relay access list pins trusted relayers, they are
+// returned instead.
 func (h *Host) DiscoverRelayers() ([]peer.ID, error) {
 	const defaultDiscoverTime = time.Second * 3
+
+	if trusted := h.RelayAccessList().TrustedRelayers; len(trusted) > 0 {
+		return append([]peer.ID{}, trusted...), nil
+	}
+
 	return h.Discover(RelayerProvidesStr, defaultDiscoverTime)
 }
 
 func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 	defer func() { _ = stream.Close() }()
 
-	msg, err := readStreamMessage(stream, maxRelayMessageSize)
+	curPeer := stream.Conn().RemotePeer()
+
+	if err := h.relayLimiter.checkBanned(curPeer); err != nil {
+		log.Debugf("ignoring relay stream from %s: %s", curPeer, err)
+		return
+	}
+
+	msg, err := h.codec.readStreamMessage(stream, maxRelayMessageSize)
 	if err != nil {
 		log.Debugf("error reading RelayClaimRequest: %s", err)
+		h.reportInvalidMessage(curPeer, err)
 		return
 	}
 
-	curPeer := stream.Conn().RemotePeer()
+	switch ownerReq := msg.(type) {
+	case *RelayRefundRequest:
+		h.handleRelayOwnerRequest(stream, curPeer, "refund", ownerReq.Swap.Owner,
+			func(now time.Time) error { return checkRelayRefundRequest(ownerReq, now) },
+			func() (*RelayClaimResponse, error) { return h.relayHandler.HandleRelayRefundRequest(ownerReq) },
+		)
+		return
+	case *RelayNewSwapRequest:
+		h.handleRelayOwnerRequest(stream, curPeer, "new swap", ownerReq.Owner,
+			func(now time.Time) error { return checkRelayNewSwapRequest(ownerReq, now) },
+			func() (*RelayClaimResponse, error) { return h.relayHandler.HandleRelayNewSwapRequest(ownerReq) },
+		)
+		return
+	case *RelaySetReadyRequest:
+		h.handleRelayOwnerRequest(stream, curPeer, "set ready", ownerReq.Swap.Owner,
+			func(now time.Time) error { return checkRelaySetReadyRequest(ownerReq, now) },
+			func() (*RelayClaimResponse, error) { return h.relayHandler.HandleRelaySetReadyRequest(ownerReq) },
+		)
+		return
+	}
 
 	req, ok := msg.(*RelayClaimRequest)
 	if !ok {
 		log.Debugf("ignoring wrong message type=%s sent to relay stream from %s",
 			message.TypeToString(msg.Type()), curPeer)
+		h.relayLimiter.ban(curPeer)
+		h.reportWrongMessageType(curPeer, msg, stream.Protocol())
 		return
 	}
 
+	result := metrics.RelayResultRejected
+	defer func() { metrics.RelayRequest(result) }()
+
 	// Handle case where we are not a relayer, and the request didn't set the offerID
 	// to indicate that it make from a running swap partner.
 
@@ -64,7 +103,14 @@ func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 	//         whom we are performing the swap.
 	if req.OfferID == nil && !h.isRelayer {
 		return
-	} else if req.OfferID != nil {
+	} else if req.OfferID == nil {
+		// The access list only restricts open relaying, claims of our own swap's
+		// counterparty are always relayed
+		if err = h.RelayAccessList().CheckRelayRequest(curPeer, req.Swap.Claimer); err != nil {
+			log.Debugf("ignoring relay request from %s: %s", curPeer, err)
+			return
+		}
+	} else {
 		h.swapMu.RLock()
 		swap, ok := h.swaps[*req.OfferID]
 		h.swapMu.RUnlock()
@@ -77,15 +123,92 @@ func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 		}
 	}
 
+	// Validating the request costs eth_calls, so requests that can't be relayed,
+	// like ones with a zero fee or whose swap can no longer be claimed, are
+	// rejected first. They are honest mistakes as often as not, so the peer gets
+	// the reason instead of being banned. Then the request rates are limited.
+	if err = checkRelayClaimRequest(req, time.Now()); err != nil {
+		log.Debugf("rejecting relay request from %s: %s", curPeer, err)
+		if writeErr := h.codec.writeStreamMessage(stream, &RelayClaimResponse{Error: err.Error()}); writeErr != nil {
+			log.Debugf("failed to send RelayClaimResponse message to peer: %s", writeErr)
+		}
+		return
+	}
+
+	if err = h.relayLimiter.allow(curPeer); err != nil {
+		log.Debugf("dropping relay request from %s: %s", curPeer, err)
+		result = metrics.RelayResultRateLimited
+		return
+	}
+
 	resp, err := h.relayHandler.HandleRelayClaimRequest(req)
 	if err != nil {
 		log.Debugf("did not handle relay request: %s", err)
+		result = metrics.RelayResultFailed
 		return
 	}
 
+	result = metrics.RelayResultRelayed
+
 	log.Debugf("Relayed claim for %s with tx=%s", req.Swap.Claimer, resp.TxHash)
 
-	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
+	if err := h.codec.writeStreamMessage(stream, resp); err != nil {
+		log.Warnf("failed to send RelayClaimResponse message to peer: %s", err)
+		return
+	}
+}
+
+// handleRelayOwnerRequest relays a transaction of the swap owner, like the refund of
+// an XMR taker whose ETH account can't pay for the refund's gas. Requests of swap
+// owners are only relayed by open relayers, and are subject to the same access list
+// and rate limits as open relay claims. The kind of request is only used for
+// logging.
+func (h *Host) handleRelayOwnerRequest(
+	stream libp2pnetwork.Stream,
+	curPeer peer.ID,
+	kind string,
+	owner ethcommon.Address,
+	check func(now time.Time) error,
+	handle func() (*RelayClaimResponse, error),
+) {
+	if !h.isRelayer {
+		return
+	}
+
+	if err := h.RelayAccessList().CheckRelayRequest(curPeer, owner); err != nil {
+		log.Debugf("ignoring relay %s request from %s: %s", kind, curPeer, err)
+		return
+	}
+
+	result := metrics.RelayResultRejected
+	defer func() { metrics.RelayRequest(result) }()
+
+	if err := check(time.Now()); err != nil {
+		log.Debugf("rejecting relay %s request from %s: %s", kind, curPeer, err)
+		if writeErr := h.codec.writeStreamMessage(stream, &RelayClaimResponse{Error: err.Error()}); writeErr != nil {
+			log.Debugf("failed to send RelayClaimResponse message to peer: %s", writeErr)
+		}
+		return
+	}
+
+	if err := h.relayLimiter.allow(curPeer); err != nil {
+		log.Debugf("dropping relay %s request from %s: %s", kind, curPeer, err)
+		result = metrics.RelayResultRateLimited
+		return
+	}
+
+	resp, err := handle()
+	if err != nil {
+		log.Debugf("did not handle relay %s request: %s", kind, err)
+		result = metrics.RelayResultFailed
+		return
+	}
+
+	result = metrics.RelayResultRelayed
+
+	log.Debugf("Relayed %s for %s with tx=%s", kind, owner, resp.TxHash)
+
+	if err := h.codec.writeStreamMessage(stream, resp); err != nil {
 		log.Warnf("failed to send RelayClaimResponse message to peer: %s", err)
 		return
 	}
@@ -93,7 +216,33 @@ func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 
 // SubmitClaimToRelayer sends a request to relay a swap claim to a peer.
 func (h *Host) SubmitClaimToRelayer(relayerID peer.ID, request *RelayClaimRequest) (*RelayClaimResponse, error) {
-	ctx, cancel := context.WithTimeout(h.ctx, connectionTimeout)
+	return h.submitToRelayer(relayerID, request)
+}
+
+// SubmitRefundToRelayer sends a request to relay a swap refund to a peer.
+func (h *Host) SubmitRefundToRelayer(relayerID peer.ID, request *RelayRefundRequest) (*RelayClaimResponse, error) {
+	return h.submitToRelayer(relayerID, request)
+}
+
+// SubmitNewSwapToRelayer sends a request to relay the creation of a token swap to
+// a peer.
+func (h *Host) SubmitNewSwapToRelayer(relayerID peer.ID, request *RelayNewSwapRequest) (*RelayClaimResponse, error) {
+	return h.submitToRelayer(relayerID, request)
+}
+
+// SubmitSetReadyToRelayer sends a request to relay the setReady call of a token
+// swap to a peer.
+func (h *Host) SubmitSetReadyToRelayer(
+	relayerID peer.ID,
+	request *RelaySetReadyRequest,
+) (*RelayClaimResponse, error) {
+	return h.submitToRelayer(relayerID, request)
+}
+
+// submitToRelayer sends the relay request to a peer and returns the relayer's
+// response.
+func (h *Host) submitToRelayer(relayerID peer.ID, request Message) (*RelayClaimResponse, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, h.dialTimeout)
 	defer cancel()
 
 	if err := h.h.Connect(ctx, peer.AddrInfo{ID: relayerID}); err != nil {
@@ -108,22 +257,22 @@ func (h *Host) SubmitClaimToRelayer(relayerID peer.ID, request *RelayClaimReques
 	defer func() { _ = stream.Close() }()
 	log.Debugf("opened relay stream: %s", stream.Conn())
 
-	if err := p2pnet.WriteStreamMessage(stream, request, relayerID); err != nil {
-		log.Warnf("failed to send RelayClaimRequest to peer: err=%s", err)
+	if err := h.codec.writeStreamMessage(stream, request); err != nil {
+		log.Warnf("failed to send %s to peer: err=%s", message.TypeToString(request.Type()), err)
 		return nil, err
 	}
 
-	return receiveRelayClaimResponse(stream)
+	return h.receiveRelayClaimResponse(stream)
 }
 
-func receiveRelayClaimResponse(stream libp2pnetwork.Stream) (*RelayClaimResponse, error) {
+func (h *Host) receiveRelayClaimResponse(stream libp2pnetwork.Stream) (*RelayClaimResponse, error) {
 	// The timeout should be short enough, that the Maker can try multiple relayers
 	// before T1 expires even if the receiving node accepts the relay request and
 	// just sits on it without doing anything.
 	const relayResponseTimeout = time.Second * 45
 
 	select {
-	case msg := <-nextStreamMessage(stream, maxMessageSize):
+	case msg := <-h.codec.nextStreamMessage(stream, maxMessageSize):
 		if msg == nil {
 			return nil, errors.New("failed to read RelayClaimResponse")
 		}
@@ -135,6 +284,10 @@ func receiveRelayClaimResponse(stream libp2pnetwork.Stream) (*RelayClaimResponse
 				message.TypeToString(msg.Type()))
 		}
 
+		if resp.Error != "" {
+			return nil, fmt.Errorf("relayer rejected the request: %s", resp.Error)
+		}
+
 		return resp, nil
 	case <-time.After(relayResponseTimeout):
 		return nil, errors.New("timed out waiting for QueryResponse")
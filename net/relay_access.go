@@ -0,0 +1,65 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// ReadRelayAccessListFile reads a JSON relay access list. A missing file is read
+// as an empty list, so the file is created by the first SetRelayAccessList.
+func ReadRelayAccessListFile(path string) (*types.RelayAccessList, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return new(types.RelayAccessList), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	list := new(types.RelayAccessList)
+	if err = json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("invalid relay access list %q: %w", path, err)
+	}
+
+	return list, nil
+}
+
+func writeRelayAccessListFile(path string, list *types.RelayAccessList) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// RelayAccessList returns the list restricting whose claims we relay and which
+// relayers we submit our claims to.
+func (h *Host) RelayAccessList() *types.RelayAccessList {
+	h.relayAccessMu.RLock()
+	defer h.relayAccessMu.RUnlock()
+	return h.relayAccess
+}
+
+// SetRelayAccessList replaces the relay access list, saving it to the host's
+// relay access list file if one was configured.
+func (h *Host) SetRelayAccessList(list *types.RelayAccessList) error {
+	h.relayAccessMu.Lock()
+	defer h.relayAccessMu.Unlock()
+
+	if h.relayAccessFile != "" {
+		if err := writeRelayAccessListFile(h.relayAccessFile, list); err != nil {
+			return err
+		}
+	}
+
+	h.relayAccess = list
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"path"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestHost_SetRelayAccessList(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.RelayAccessListFile = path.Join(t.TempDir(), "relay-access.json")
+	h := newHost(t, cfg)
+
+	// a missing file is an empty list
+	require.Empty(t, h.RelayAccessList().TrustedRelayers)
+
+	relayerH := newHost(t, basicTestConfig(t))
+	list := &types.RelayAccessList{TrustedRelayers: []peer.ID{relayerH.PeerID()}}
+	require.NoError(t, h.SetRelayAccessList(list))
+
+	// trusted relayers replace the DHT-advertised relayers
+	relayers, err := h.DiscoverRelayers()
+	require.NoError(t, err)
+	require.Equal(t, []peer.ID{relayerH.PeerID()}, relayers)
+
+	saved, err := ReadRelayAccessListFile(cfg.RelayAccessListFile)
+	require.NoError(t, err)
+	require.Equal(t, list, saved)
+}
+
+func TestHost_SubmitClaimToRelayer_denied(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	require.NoError(t, hb.SetRelayAccessList(&types.RelayAccessList{DenyPeers: []peer.ID{ha.PeerID()}}))
+	_, err := ha.SubmitClaimToRelayer(hb.PeerID(), createTestClaimRequest())
+	require.Error(t, err)
+
+	require.NoError(t, hb.SetRelayAccessList(new(types.RelayAccessList)))
+	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), createTestClaimRequest())
+	require.NoError(t, err)
+}
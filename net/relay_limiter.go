@@ -0,0 +1,268 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+// maxTrackedRelayPeers is the number of peers whose relay request limits are kept
+// before idle peers are dropped.
+const maxTrackedRelayPeers = 1024
+
+var (
+	errRelayPeerBanned      = errors.New("peer is temporarily banned from relay requests")
+	errRelayPeerRateLimited = errors.New("peer exceeded its relay request rate")
+	errRelayRateLimited     = errors.New("relay request rate exceeded")
+)
+
+// RelayLimits bounds the rate of the relay claim requests that we validate, and
+// separately of the relay fee quote requests that we answer, as both cost eth_calls
+// to our ethereum endpoint. Peers that exceed their rate or send the wrong message
+// type are banned for BanDuration.
+type RelayLimits struct {
+	PerPeerPerMinute float64
+	PerPeerBurst     int
+	GlobalPerMinute  float64
+	GlobalBurst      int
+	BanDuration      time.Duration
+}
+
+// DefaultRelayLimits returns the default relay request limits.
+func DefaultRelayLimits() *RelayLimits {
+	return &RelayLimits{
+		PerPeerPerMinute: 6,
+		PerPeerBurst:     3,
+		GlobalPerMinute:  120,
+		GlobalBurst:      20,
+		BanDuration:      10 * time.Minute,
+	}
+}
+
+// tokenBucket allows bursts of up to burst events, refilled at rate events per
+// second.
+type tokenBucket struct {
+	tokens float64
+	burst  float64
+	rate   float64
+	last   time.Time
+}
+
+func newTokenBucket(perMinute float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		tokens: float64(burst),
+		burst:  float64(burst),
+		rate:   perMinute / 60,
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+func (b *tokenBucket) take(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type relayPeerLimit struct {
+	bucket      *tokenBucket
+	bannedUntil time.Time
+}
+
+// relayLimiter applies the RelayLimits to the relay claim requests of peers.
+type relayLimiter struct {
+	mu     sync.Mutex
+	limits *RelayLimits
+	global *tokenBucket
+	peers  map[peer.ID]*relayPeerLimit
+	now    func() time.Time
+}
+
+func newRelayLimiter(limits *RelayLimits) *relayLimiter {
+	return &relayLimiter{
+		limits: limits,
+		global: newTokenBucket(limits.GlobalPerMinute, limits.GlobalBurst, time.Now()),
+		peers:  make(map[peer.ID]*relayPeerLimit),
+		now:    time.Now,
+	}
+}
+
+func (l *relayLimiter) peerLimit(peerID peer.ID, now time.Time) *relayPeerLimit {
+	pl, ok := l.peers[peerID]
+	if ok {
+		return pl
+	}
+
+	if len(l.peers) >= maxTrackedRelayPeers {
+		l.dropIdlePeers(now)
+	}
+
+	pl = &relayPeerLimit{
+		bucket: newTokenBucket(l.limits.PerPeerPerMinute, l.limits.PerPeerBurst, now),
+	}
+	l.peers[peerID] = pl
+	return pl
+}
+
+// dropIdlePeers forgets the peers that are not banned and whose request budget is
+// full again, as they are indistinguishable from new peers.
+func (l *relayLimiter) dropIdlePeers(now time.Time) {
+	for peerID, pl := range l.peers {
+		if now.Before(pl.bannedUntil) {
+			continue
+		}
+		pl.bucket.refill(now)
+		if pl.bucket.tokens >= pl.bucket.burst {
+			delete(l.peers, peerID)
+		}
+	}
+}
+
+// checkBanned returns errRelayPeerBanned if the peer is banned.
+func (l *relayLimiter) checkBanned(peerID peer.ID) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	pl, ok := l.peers[peerID]
+	if ok && l.now().Before(pl.bannedUntil) {
+		return errRelayPeerBanned
+	}
+
+	return nil
+}
+
+// allow returns nil if the peer's request can be validated. A peer exceeding its
+// own rate is banned. Exceeding the global rate doesn't ban the peer, as it's not
+// attributable to one peer.
+func (l *relayLimiter) allow(peerID peer.ID) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	pl := l.peerLimit(peerID, now)
+	if now.Before(pl.bannedUntil) {
+		return errRelayPeerBanned
+	}
+
+	if !pl.bucket.take(now) {
+		pl.bannedUntil = now.Add(l.limits.BanDuration)
+		return errRelayPeerRateLimited
+	}
+
+	if !l.global.take(now) {
+		return errRelayRateLimited
+	}
+
+	return nil
+}
+
+// ban bans the peer for the ban duration.
+func (l *relayLimiter) ban(peerID peer.ID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.peerLimit(peerID, now).bannedUntil = now.Add(l.limits.BanDuration)
+}
+
+// checkRelayClaimRequest performs the validations of the relay claim request that
+// don't need the ethereum endpoint. The lengths of the secret and signature were
+// vetted when the request was deserialized. The fee of token swaps is in the token,
+// so it is compared with the swap value in either case.
+func checkRelayClaimRequest(req *RelayClaimRequest, now time.Time) error {
+	if req.Swap.Value == nil || req.FeeWei.Sign() <= 0 || req.FeeWei.Cmp(req.Swap.Value) >= 0 {
+		return errors.New("relayer fee must be positive and less than the swap value")
+	}
+
+	if req.Swap.Timeout1 == nil || !req.Swap.Timeout1.IsInt64() || req.Swap.Timeout1.Int64() <= now.Unix() {
+		return errors.New("swap can no longer be claimed")
+	}
+
+	return nil
+}
+
+// checkRelayRefundRequest performs the validations of the relay refund request
+// that don't need the ethereum endpoint, like checkRelayClaimRequest. The swap
+// can't be refunded between t0 and t1, whatever its stage.
+func checkRelayRefundRequest(req *RelayRefundRequest, now time.Time) error {
+	if req.Swap.Value == nil || req.FeeWei.Sign() <= 0 || req.FeeWei.Cmp(req.Swap.Value) >= 0 {
+		return errors.New("relayer fee must be positive and less than the swap value")
+	}
+
+	if req.Swap.Timeout0 == nil || req.Swap.Timeout1 == nil ||
+		!req.Swap.Timeout0.IsInt64() || !req.Swap.Timeout1.IsInt64() {
+		return errors.New("invalid swap timeouts")
+	}
+
+	if now.Unix() > req.Swap.Timeout0.Int64() && now.Unix() < req.Swap.Timeout1.Int64() {
+		return errors.New("swap can't be refunded until t1")
+	}
+
+	return nil
+}
+
+// checkRelayNewSwapRequest performs the validations of the relay new swap request
+// that don't need the ethereum endpoint. Only the new swap and set ready calls of
+// token swaps are relayed, as the relayer fee is paid in the token with the
+// owner's permit.
+func checkRelayNewSwapRequest(req *RelayNewSwapRequest, now time.Time) error {
+	if !types.EthAsset(req.Asset).IsToken() {
+		return errors.New("only token swaps can be created by a relayer")
+	}
+
+	if req.FeeWei.Sign() <= 0 || req.FeeWei.Cmp(req.Value) >= 0 {
+		return errors.New("relayer fee must be positive and less than the swap value")
+	}
+
+	if req.TimeoutDuration0.Sign() <= 0 || req.TimeoutDuration1.Sign() <= 0 {
+		return errors.New("invalid swap timeouts")
+	}
+
+	return checkRelayPermit(req.Permit, now)
+}
+
+// checkRelaySetReadyRequest performs the validations of the relay set ready
+// request that don't need the ethereum endpoint, like checkRelayNewSwapRequest.
+// The swap can only be set ready before t0.
+func checkRelaySetReadyRequest(req *RelaySetReadyRequest, now time.Time) error {
+	if !types.EthAsset(req.Swap.Asset).IsToken() {
+		return errors.New("only token swaps can be set ready by a relayer")
+	}
+
+	if req.Swap.Value == nil || req.FeeWei.Sign() <= 0 || req.FeeWei.Cmp(req.Swap.Value) >= 0 {
+		return errors.New("relayer fee must be positive and less than the swap value")
+	}
+
+	if req.Swap.Timeout0 == nil || !req.Swap.Timeout0.IsInt64() || req.Swap.Timeout0.Int64() <= now.Unix() {
+		return errors.New("swap can no longer be set ready")
+	}
+
+	return checkRelayPermit(req.Permit, now)
+}
+
+// checkRelayPermit checks that the permit paying the relayer fee hasn't expired.
+func checkRelayPermit(permit *contracts.SwapCreatorPermitSignature, now time.Time) error {
+	if !permit.Deadline.IsInt64() || permit.Deadline.Int64() <= now.Unix() {
+		return errors.New("permit has expired")
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	libp2ptest "github.com/libp2p/go-libp2p/core/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestRelayLimiter_perPeer(t *testing.T) {
+	limits := DefaultRelayLimits()
+	l := newRelayLimiter(limits)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	peerA, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+	peerB, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+
+	for i := 0; i < limits.PerPeerBurst; i++ {
+		require.NoError(t, l.allow(peerA))
+	}
+	require.ErrorIs(t, l.allow(peerA), errRelayPeerRateLimited)
+	require.ErrorIs(t, l.checkBanned(peerA), errRelayPeerBanned)
+
+	// other peers are not affected
+	require.NoError(t, l.allow(peerB))
+
+	// the ban outlasts the refill of the peer's budget
+	now = now.Add(limits.BanDuration - time.Second)
+	require.ErrorIs(t, l.allow(peerA), errRelayPeerBanned)
+
+	now = now.Add(time.Second)
+	require.NoError(t, l.checkBanned(peerA))
+	require.NoError(t, l.allow(peerA))
+}
+
+func TestRelayLimiter_global(t *testing.T) {
+	limits := &RelayLimits{
+		PerPeerPerMinute: 60,
+		PerPeerBurst:     10,
+		GlobalPerMinute:  60,
+		GlobalBurst:      2,
+		BanDuration:      time.Minute,
+	}
+	l := newRelayLimiter(limits)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	l.global.last = now
+
+	for i := 0; i < limits.GlobalBurst; i++ {
+		peerID, err := libp2ptest.RandPeerID()
+		require.NoError(t, err)
+		require.NoError(t, l.allow(peerID))
+	}
+
+	peerID, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+	require.ErrorIs(t, l.allow(peerID), errRelayRateLimited)
+	require.NoError(t, l.checkBanned(peerID))
+
+	// one request per second is refilled
+	now = now.Add(time.Second)
+	require.NoError(t, l.allow(peerID))
+}
+
+func TestRelayLimiter_ban(t *testing.T) {
+	l := newRelayLimiter(DefaultRelayLimits())
+
+	peerID, err := libp2ptest.RandPeerID()
+	require.NoError(t, err)
+
+	l.ban(peerID)
+	require.ErrorIs(t, l.checkBanned(peerID), errRelayPeerBanned)
+	require.ErrorIs(t, l.allow(peerID), errRelayPeerBanned)
+}
+
+func TestCheckRelayClaimRequest(t *testing.T) {
+	now := time.Now()
+	require.NoError(t, checkRelayClaimRequest(createTestClaimRequest(), now))
+
+	// token swaps pay the fee in the token
+	req := createTestClaimRequest()
+	req.Swap.Asset = ethcommon.Address{0x1}
+	require.NoError(t, checkRelayClaimRequest(req, now))
+
+	req = createTestClaimRequest()
+	req.FeeWei = new(big.Int).Set(req.Swap.Value)
+	require.ErrorContains(t, checkRelayClaimRequest(req, now), "less than the swap value")
+
+	req = createTestClaimRequest()
+	req.FeeWei = big.NewInt(0)
+	require.ErrorContains(t, checkRelayClaimRequest(req, now), "must be positive")
+
+	req = createTestClaimRequest()
+	require.ErrorContains(t, checkRelayClaimRequest(req, now.Add(2*time.Hour)), "no longer be claimed")
+}
+
+func TestCheckRelayRefundRequest(t *testing.T) {
+	now := time.Now()
+	require.NoError(t, checkRelayRefundRequest(createTestRefundRequest(), now))
+
+	// pending swaps can be refunded before t0
+	req := createTestRefundRequest()
+	req.Swap.Timeout0 = big.NewInt(now.Add(30 * time.Minute).Unix())
+	req.Swap.Timeout1 = big.NewInt(now.Add(60 * time.Minute).Unix())
+	require.NoError(t, checkRelayRefundRequest(req, now))
+	require.ErrorContains(t, checkRelayRefundRequest(req, now.Add(45*time.Minute)), "can't be refunded until t1")
+
+	req = createTestRefundRequest()
+	req.FeeWei = new(big.Int).Set(req.Swap.Value)
+	require.ErrorContains(t, checkRelayRefundRequest(req, now), "less than the swap value")
+
+	req = createTestRefundRequest()
+	req.FeeWei = big.NewInt(0)
+	require.ErrorContains(t, checkRelayRefundRequest(req, now), "must be positive")
+
+	req = createTestRefundRequest()
+	req.Swap.Timeout0 = nil
+	require.ErrorContains(t, checkRelayRefundRequest(req, now), "invalid swap timeouts")
+}
+
+func TestCheckRelayNewSwapRequest(t *testing.T) {
+	now := time.Now()
+	require.NoError(t, checkRelayNewSwapRequest(createTestNewSwapRequest(), now))
+
+	req := createTestNewSwapRequest()
+	req.Asset = ethcommon.Address(types.EthAssetETH)
+	require.ErrorContains(t, checkRelayNewSwapRequest(req, now), "only token swaps")
+
+	req = createTestNewSwapRequest()
+	req.FeeWei = new(big.Int).Set(req.Value)
+	require.ErrorContains(t, checkRelayNewSwapRequest(req, now), "less than the swap value")
+
+	req = createTestNewSwapRequest()
+	req.TimeoutDuration1 = big.NewInt(0)
+	require.ErrorContains(t, checkRelayNewSwapRequest(req, now), "invalid swap timeouts")
+
+	req = createTestNewSwapRequest()
+	require.ErrorContains(t, checkRelayNewSwapRequest(req, now.Add(2*time.Hour)), "permit has expired")
+}
+
+func TestCheckRelaySetReadyRequest(t *testing.T) {
+	now := time.Now()
+	require.NoError(t, checkRelaySetReadyRequest(createTestSetReadyRequest(), now))
+
+	req := createTestSetReadyRequest()
+	req.Swap.Asset = ethcommon.Address(types.EthAssetETH)
+	require.ErrorContains(t, checkRelaySetReadyRequest(req, now), "only token swaps")
+
+	req = createTestSetReadyRequest()
+	req.FeeWei = big.NewInt(0)
+	require.ErrorContains(t, checkRelaySetReadyRequest(req, now), "must be positive")
+
+	req = createTestSetReadyRequest()
+	require.ErrorContains(t, checkRelaySetReadyRequest(req, now.Add(45*time.Minute)), "no longer be set ready")
+
+	req = createTestSetReadyRequest()
+	req.Permit.Deadline = big.NewInt(now.Add(-time.Minute).Unix())
+	require.ErrorContains(t, checkRelaySetReadyRequest(req, now), "permit has expired")
+}
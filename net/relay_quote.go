@@ -0,0 +1,119 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	relayQuoteProtocolID = "/relay-quote/0"
+
+	// relayQuoteTimeout is short, as quotes are requested from all discovered
+	// relayers before the claim, and a relayer that is slow to quote is unlikely
+	// to be quick at relaying.
+	relayQuoteTimeout = time.Second * 5
+)
+
+func (h *Host) handleRelayQuoteStream(stream libp2pnetwork.Stream) {
+	defer func() { _ = stream.Close() }()
+
+	// Only nodes advertising in the DHT give quotes. The counterparty of a swap
+	// relays its own swap's claim for the fee the claimer offers.
+	if !h.isRelayer {
+		return
+	}
+
+	curPeer := stream.Conn().RemotePeer()
+
+	if err := h.relayQuoteLimiter.checkBanned(curPeer); err != nil {
+		log.Debugf("ignoring relay quote stream from %s: %s", curPeer, err)
+		return
+	}
+
+	msg, err := h.codec.readStreamMessage(stream, maxRelayMessageSize)
+	if err != nil {
+		log.Debugf("error reading RelayFeeQuoteRequest: %s", err)
+		h.reportInvalidMessage(curPeer, err)
+		return
+	}
+
+	req, ok := msg.(*RelayFeeQuoteRequest)
+	if !ok {
+		log.Debugf("ignoring wrong message type=%s sent to relay quote stream from %s",
+			message.TypeToString(msg.Type()), curPeer)
+		h.reportWrongMessageType(curPeer, msg, stream.Protocol())
+		return
+	}
+
+	// quoting costs eth_calls too, so quote requests have their own limits, which
+	// keep them from using up the budget of claim requests
+	if err = h.relayQuoteLimiter.allow(curPeer); err != nil {
+		log.Debugf("dropping relay quote request from %s: %s", curPeer, err)
+		return
+	}
+
+	resp, err := h.relayHandler.HandleRelayFeeQuoteRequest(req)
+	if err != nil {
+		log.Debugf("did not quote relay fee: %s", err)
+		return
+	}
+
+	if err := h.codec.writeStreamMessage(stream, resp); err != nil {
+		log.Warnf("failed to send RelayFeeQuote message to peer: %s", err)
+		return
+	}
+}
+
+// QueryRelayerFee asks a relayer for the fee it requires to relay a claim.
+func (h *Host) QueryRelayerFee(relayerID peer.ID, request *RelayFeeQuoteRequest) (*RelayFeeQuote, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, h.dialTimeout)
+	defer cancel()
+
+	if err := h.h.Connect(ctx, peer.AddrInfo{ID: relayerID}); err != nil {
+		return nil, err
+	}
+
+	stream, err := h.h.NewStream(ctx, relayerID, relayQuoteProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	defer func() { _ = stream.Close() }()
+
+	if err := h.codec.writeStreamMessage(stream, request); err != nil {
+		log.Warnf("failed to send RelayFeeQuoteRequest to peer: err=%s", err)
+		return nil, err
+	}
+
+	return h.receiveRelayFeeQuote(stream)
+}
+
+func (h *Host) receiveRelayFeeQuote(stream libp2pnetwork.Stream) (*RelayFeeQuote, error) {
+	select {
+	case msg := <-h.codec.nextStreamMessage(stream, maxRelayMessageSize):
+		if msg == nil {
+			return nil, errors.New("failed to read RelayFeeQuote")
+		}
+
+		resp, ok := msg.(*RelayFeeQuote)
+		if !ok {
+			return nil, fmt.Errorf("expected %s message but received %s",
+				message.TypeToString(message.RelayFeeQuoteType),
+				message.TypeToString(msg.Type()))
+		}
+
+		return resp, nil
+	case <-time.After(relayQuoteTimeout):
+		return nil, errors.New("timed out waiting for RelayFeeQuote")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func createTestFeeQuoteRequest() *RelayFeeQuoteRequest {
+	return &RelayFeeQuoteRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Asset:           ethcommon.Address(types.EthAssetETH),
+		Value:           big.NewInt(1e18),
+	}
+}
+
+func TestHost_QueryRelayerFee(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	// success path ha->hb, hb is a DHT relayer
+	quote, err := ha.QueryRelayerFee(hb.PeerID(), createTestFeeQuoteRequest())
+	require.NoError(t, err)
+	require.Equal(t, mockRelayFeeWei.String(), quote.FeeWei.String())
+	require.Equal(t, uint64(12), quote.ETASeconds)
+
+	// failure path hb->ha, ha is NOT a DHT relayer and gives no quotes
+	_, err = hb.QueryRelayerFee(ha.PeerID(), createTestFeeQuoteRequest())
+	require.ErrorContains(t, err, "failed to read RelayFeeQuote")
+}
+
+func TestHost_QueryRelayerFee_rateLimited(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	for i := 0; i < DefaultRelayLimits().PerPeerBurst; i++ {
+		_, err := ha.QueryRelayerFee(hb.PeerID(), createTestFeeQuoteRequest())
+		require.NoError(t, err)
+	}
+
+	// ha exceeded its quote rate and is banned from quote requests
+	_, err := ha.QueryRelayerFee(hb.PeerID(), createTestFeeQuoteRequest())
+	require.ErrorContains(t, err, "failed to read RelayFeeQuote")
+	require.ErrorIs(t, hb.relayQuoteLimiter.checkBanned(ha.PeerID()), errRelayPeerBanned)
+
+	// claim requests have their own limits
+	require.NoError(t, hb.relayLimiter.checkBanned(ha.PeerID()))
+}
@@ -74,11 +74,86 @@ func createTestClaimRequest() *message.RelayClaimRequest {
 		},
 		Secret:    secret[:],
 		Signature: sig[:],
+		FeeWei:    big.NewInt(1e16),
 	}
 
 	return req
 }
 
+func createTestRefundRequest() *message.RelayRefundRequest {
+	secret := [32]byte{0x1}
+	sig := [65]byte{0x1}
+
+	return &message.RelayRefundRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Swap: &contracts.SwapCreatorSwap{
+			Owner:        ethcommon.Address{0x1},
+			Claimer:      ethcommon.Address{0x1},
+			PubKeyClaim:  [32]byte{0x1},
+			PubKeyRefund: [32]byte{0x1},
+			Timeout0:     big.NewInt(time.Now().Add(-60 * time.Minute).Unix()),
+			Timeout1:     big.NewInt(time.Now().Add(-30 * time.Minute).Unix()),
+			Asset:        ethcommon.Address(types.EthAssetETH),
+			Value:        big.NewInt(1e18),
+			Nonce:        big.NewInt(1),
+		},
+		Secret:    secret[:],
+		Signature: sig[:],
+		FeeWei:    big.NewInt(1e16),
+	}
+}
+
+func createTestPermit() *contracts.SwapCreatorPermitSignature {
+	return &contracts.SwapCreatorPermitSignature{
+		Deadline: big.NewInt(time.Now().Add(time.Hour).Unix()),
+		V:        27,
+		R:        [32]byte{0x1},
+		S:        [32]byte{0x1},
+	}
+}
+
+func createTestNewSwapRequest() *message.RelayNewSwapRequest {
+	sig := [65]byte{0x1}
+
+	return &message.RelayNewSwapRequest{
+		SwapCreatorAddr:  ethcommon.Address{0x1},
+		Owner:            ethcommon.Address{0x1},
+		PubKeyClaim:      [32]byte{0x1},
+		PubKeyRefund:     [32]byte{0x1},
+		Claimer:          ethcommon.Address{0x1},
+		TimeoutDuration0: big.NewInt(3600),
+		TimeoutDuration1: big.NewInt(3600),
+		Asset:            ethcommon.Address{0x1},
+		Value:            big.NewInt(1e18),
+		Nonce:            big.NewInt(1),
+		Permit:           createTestPermit(),
+		Signature:        sig[:],
+		FeeWei:           big.NewInt(1e16),
+	}
+}
+
+func createTestSetReadyRequest() *message.RelaySetReadyRequest {
+	sig := [65]byte{0x1}
+
+	return &message.RelaySetReadyRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Swap: &contracts.SwapCreatorSwap{
+			Owner:        ethcommon.Address{0x1},
+			Claimer:      ethcommon.Address{0x1},
+			PubKeyClaim:  [32]byte{0x1},
+			PubKeyRefund: [32]byte{0x1},
+			Timeout0:     big.NewInt(time.Now().Add(30 * time.Minute).Unix()),
+			Timeout1:     big.NewInt(time.Now().Add(60 * time.Minute).Unix()),
+			Asset:        ethcommon.Address{0x1},
+			Value:        big.NewInt(1e18),
+			Nonce:        big.NewInt(1),
+		},
+		Permit:    createTestPermit(),
+		Signature: sig[:],
+		FeeWei:    big.NewInt(1e16),
+	}
+}
+
 func TestHost_SubmitClaimToRelayer_dhtRelayer(t *testing.T) {
 	ha, hb := twoHostRelayerSetup(t)
 
@@ -131,3 +206,80 @@ func TestHost_SubmitClaimToRelayer_fail(t *testing.T) {
 	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), req)
 	require.ErrorContains(t, err, "Field validation for 'Signature' failed on the 'len' tag")
 }
+
+func TestHost_SubmitClaimToRelayer_unrelayableRequest(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	req := createTestClaimRequest()
+	req.FeeWei = big.NewInt(0)
+	_, err := ha.SubmitClaimToRelayer(hb.PeerID(), req)
+	require.ErrorContains(t, err, "relayer rejected the request: relayer fee must be positive")
+
+	req = createTestClaimRequest()
+	req.Swap.Timeout1 = big.NewInt(time.Now().Add(-time.Minute).Unix())
+	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), req)
+	require.ErrorContains(t, err, "relayer rejected the request: swap can no longer be claimed")
+
+	// the rejected requests didn't get ha banned
+	resp, err := ha.SubmitClaimToRelayer(hb.PeerID(), createTestClaimRequest())
+	require.NoError(t, err)
+	require.Equal(t, mockEthTXHash.Hex(), resp.TxHash.Hex())
+}
+
+func TestHost_SubmitRefundToRelayer(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	// success path ha->hb, hb is a DHT relayer
+	resp, err := ha.SubmitRefundToRelayer(hb.PeerID(), createTestRefundRequest())
+	require.NoError(t, err)
+	require.Equal(t, mockEthTXHash.Hex(), resp.TxHash.Hex())
+
+	// failure path hb->ha, ha is NOT a DHT relayer, and only DHT relayers relay
+	// refunds
+	_, err = hb.SubmitRefundToRelayer(ha.PeerID(), createTestRefundRequest())
+	require.ErrorContains(t, err, "failed to read RelayClaimResponse")
+
+	// the swap can't be refunded between t0 and t1
+	req := createTestRefundRequest()
+	req.Swap.Timeout1 = big.NewInt(time.Now().Add(time.Hour).Unix())
+	_, err = ha.SubmitRefundToRelayer(hb.PeerID(), req)
+	require.ErrorContains(t, err, "swap can't be refunded until t1")
+}
+
+func TestHost_SubmitNewSwapToRelayer(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	// success path ha->hb, hb is a DHT relayer
+	resp, err := ha.SubmitNewSwapToRelayer(hb.PeerID(), createTestNewSwapRequest())
+	require.NoError(t, err)
+	require.Equal(t, mockEthTXHash.Hex(), resp.TxHash.Hex())
+
+	// failure path hb->ha, ha is NOT a DHT relayer
+	_, err = hb.SubmitNewSwapToRelayer(ha.PeerID(), createTestNewSwapRequest())
+	require.ErrorContains(t, err, "failed to read RelayClaimResponse")
+
+	// the permit paying the relayer fee must not have expired
+	req := createTestNewSwapRequest()
+	req.Permit.Deadline = big.NewInt(time.Now().Add(-time.Minute).Unix())
+	_, err = ha.SubmitNewSwapToRelayer(hb.PeerID(), req)
+	require.ErrorContains(t, err, "relayer rejected the request: permit has expired")
+}
+
+func TestHost_SubmitSetReadyToRelayer(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	// success path ha->hb, hb is a DHT relayer
+	resp, err := ha.SubmitSetReadyToRelayer(hb.PeerID(), createTestSetReadyRequest())
+	require.NoError(t, err)
+	require.Equal(t, mockEthTXHash.Hex(), resp.TxHash.Hex())
+
+	// failure path hb->ha, ha is NOT a DHT relayer
+	_, err = hb.SubmitSetReadyToRelayer(ha.PeerID(), createTestSetReadyRequest())
+	require.ErrorContains(t, err, "failed to read RelayClaimResponse")
+
+	// the swap can't be set ready after t0
+	req := createTestSetReadyRequest()
+	req.Swap.Timeout0 = big.NewInt(time.Now().Add(-time.Minute).Unix())
+	_, err = ha.SubmitSetReadyToRelayer(hb.PeerID(), req)
+	require.ErrorContains(t, err, "relayer rejected the request: swap can no longer be set ready")
+}
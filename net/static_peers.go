@@ -0,0 +1,135 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+const (
+	// staticPeerTag protects the connections to static peers from the connection
+	// manager.
+	staticPeerTag = "static-peer"
+
+	staticPeerMinBackoff = time.Second * 5
+	staticPeerMaxBackoff = time.Minute * 5
+
+	// staticPeerCheckInterval is how often we check that we are still connected to
+	// a static peer, in case we missed its disconnection.
+	staticPeerCheckInterval = time.Minute
+
+	// staticPeerAlertFailures is the number of failed connections to a static peer
+	// after which it is reported unreachable.
+	staticPeerAlertFailures = 3
+)
+
+// parseStaticPeers returns the peers of the addresses, which must have a peer ID.
+// The addresses of the same peer are merged.
+func parseStaticPeers(addrs []string) ([]peer.AddrInfo, error) {
+	maddrs := make([]ma.Multiaddr, 0, len(addrs))
+	for _, addr := range addrs {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid static peer address %q: %w", addr, err)
+		}
+		if _, err = maddr.ValueForProtocol(ma.P_P2P); err != nil {
+			return nil, fmt.Errorf("static peer address %q has no peer ID", addr)
+		}
+		maddrs = append(maddrs, maddr)
+	}
+
+	return peer.AddrInfosFromP2pAddrs(maddrs...)
+}
+
+// maintainStaticPeers keeps us connected to the static peers, which the connection
+// manager never disconnects.
+func (lh *libp2pHost) maintainStaticPeers() {
+	if len(lh.staticPeers) == 0 {
+		return
+	}
+
+	disconnected := make(map[peer.ID]chan struct{}, len(lh.staticPeers))
+	for _, ai := range lh.staticPeers {
+		disconnected[ai.ID] = make(chan struct{}, 1)
+	}
+
+	lh.h.Network().Notify(&libp2pnetwork.NotifyBundle{
+		DisconnectedF: func(_ libp2pnetwork.Network, c libp2pnetwork.Conn) {
+			ch, ok := disconnected[c.RemotePeer()]
+			if !ok {
+				return
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		},
+	})
+
+	for _, ai := range lh.staticPeers {
+		lh.h.Peerstore().AddAddrs(ai.ID, ai.Addrs, peerstore.PermanentAddrTTL)
+		lh.h.ConnManager().Protect(ai.ID, staticPeerTag)
+		go lh.maintainStaticPeer(ai, disconnected[ai.ID])
+	}
+}
+
+// maintainStaticPeer connects to the static peer whenever we are not connected to
+// it, backing off exponentially while it is unreachable. Once it failed to connect
+// staticPeerAlertFailures times, the peer is reported unreachable until we reconnect.
+func (lh *libp2pHost) maintainStaticPeer(ai peer.AddrInfo, disconnected <-chan struct{}) {
+	backoff := staticPeerMinBackoff
+	failures := 0
+
+	for {
+		wait := staticPeerCheckInterval
+
+		if lh.h.Network().Connectedness(ai.ID) != libp2pnetwork.Connected {
+			err := lh.connectStaticPeer(ai)
+			switch {
+			case err != nil:
+				failures++
+				if failures == staticPeerAlertFailures {
+					log.Warnf("static peer %s is unreachable after %d attempts: %s", ai.ID, failures, err)
+				} else {
+					log.Debugf("failed to connect to static peer %s: %s", ai.ID, err)
+				}
+				wait = backoff
+				backoff *= 2
+				if backoff > staticPeerMaxBackoff {
+					backoff = staticPeerMaxBackoff
+				}
+			case failures >= staticPeerAlertFailures:
+				log.Infof("reconnected to static peer %s", ai.ID)
+				fallthrough
+			default:
+				failures = 0
+				backoff = staticPeerMinBackoff
+			}
+		}
+
+		metrics.StaticPeerConnected(ai.ID.String(), failures == 0)
+
+		select {
+		case <-lh.ctx.Done():
+			return
+		case <-disconnected:
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (lh *libp2pHost) connectStaticPeer(ai peer.AddrInfo) error {
+	ctx, cancel := context.WithTimeout(lh.ctx, lh.dialTimeout)
+	defer cancel()
+	return lh.h.Connect(ctx, ai)
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStaticPeers(t *testing.T) {
+	const p = "12D3KooWGVzz2d2LSceVFFdqTYqmQXTqc5eWziw7PLRahCWGJhKB"
+	peers, err := parseStaticPeers([]string{
+		"/ip4/127.0.0.1/tcp/9900/p2p/" + p,
+		"/ip4/127.0.0.1/udp/9900/quic-v1/p2p/" + p,
+	})
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	require.Equal(t, p, peers[0].ID.String())
+	require.Len(t, peers[0].Addrs, 2)
+
+	_, err = parseStaticPeers([]string{"/ip4/127.0.0.1/tcp/9900"})
+	require.ErrorContains(t, err, "has no peer ID")
+
+	_, err = parseStaticPeers([]string{"not an address"})
+	require.Error(t, err)
+}
+
+func TestHost_staticPeers(t *testing.T) {
+	h2 := newHost(t, basicTestConfig(t))
+
+	cfg := basicTestConfig(t)
+	for _, addr := range h2.Addresses() {
+		cfg.StaticPeers = append(cfg.StaticPeers, addr.String())
+	}
+	h1 := newHost(t, cfg)
+	require.NoError(t, h1.Start())
+	lh := h1.h.(*libp2pHost)
+
+	isConnected := func() bool {
+		return lh.h.Network().Connectedness(h2.PeerID()) == libp2pnetwork.Connected
+	}
+	require.Eventually(t, isConnected, time.Second*5, time.Millisecond*50)
+	require.True(t, lh.h.ConnManager().IsProtected(h2.PeerID(), staticPeerTag))
+
+	// we reconnect to the static peer when disconnected
+	require.NoError(t, lh.h.Network().ClosePeer(h2.PeerID()))
+	require.Eventually(t, isConnected, time.Second*5, time.Millisecond*50)
+}
@@ -0,0 +1,261 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	libp2pmetrics "github.com/libp2p/go-libp2p/core/metrics"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+const (
+	// maxTrackedStatsPeers is the number of peers whose messages are counted, the
+	// messages of other peers are only counted in the protocol totals
+	maxTrackedStatsPeers = 1024
+
+	// the stats of peers that have been idle for statsIdleTime are dropped
+	statsIdleTime     = time.Hour
+	statsTrimInterval = time.Minute * 10
+)
+
+// BandwidthStats is the traffic on the streams with peers, in bytes, and its
+// current rate in bytes per second.
+type BandwidthStats struct {
+	BytesIn  int64
+	BytesOut int64
+	RateIn   float64
+	RateOut  float64
+}
+
+func newBandwidthStats(s libp2pmetrics.Stats) BandwidthStats {
+	return BandwidthStats{
+		BytesIn:  s.TotalIn,
+		BytesOut: s.TotalOut,
+		RateIn:   s.RateIn,
+		RateOut:  s.RateOut,
+	}
+}
+
+// ProtocolStats is the traffic and the number of messages received on the streams
+// of a protocol.
+type ProtocolStats struct {
+	Protocol        string
+	Bandwidth       BandwidthStats
+	Messages        uint64
+	InvalidMessages uint64
+}
+
+// PeerStats is the traffic with a peer and the number of messages that it sent us
+// on each protocol.
+type PeerStats struct {
+	PeerID          peer.ID
+	Bandwidth       BandwidthStats
+	Messages        map[string]uint64
+	InvalidMessages uint64
+}
+
+// Stats is the traffic and the number of received messages of the host, by protocol
+// and by peer.
+type Stats struct {
+	Total     BandwidthStats
+	Protocols []*ProtocolStats // sorted by protocol
+	Peers     []*PeerStats     // sorted by bytes received, highest first
+}
+
+type protocolMessages struct {
+	messages uint64
+	invalid  uint64
+}
+
+type peerMessages struct {
+	messages map[string]uint64
+	invalid  uint64
+	lastSeen time.Time
+}
+
+// netStats counts the bytes sent and received on the streams with peers, and the
+// messages that peers send us, so that peers abusing a protocol can be spotted.
+// The protocols are named without the host's protocol ID prefix.
+type netStats struct {
+	protocolPrefix string
+	bwc            *libp2pmetrics.BandwidthCounter
+
+	mu        sync.Mutex
+	protocols map[string]*protocolMessages
+	peers     map[peer.ID]*peerMessages
+}
+
+func newNetStats(protocolPrefix string) *netStats {
+	return &netStats{
+		protocolPrefix: protocolPrefix,
+		bwc:            libp2pmetrics.NewBandwidthCounter(),
+		protocols:      make(map[string]*protocolMessages),
+		peers:          make(map[peer.ID]*peerMessages),
+	}
+}
+
+func (s *netStats) protocolName(id protocol.ID) string {
+	return strings.TrimPrefix(string(id), s.protocolPrefix)
+}
+
+// recordMessage counts a message received from the peer on the protocol, which is
+// invalid if it could not be decoded.
+func (s *netStats) recordMessage(p peer.ID, id protocol.ID, valid bool) {
+	name := s.protocolName(id)
+	metrics.P2PMessageReceived(name, valid)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pm, ok := s.protocols[name]
+	if !ok {
+		pm = new(protocolMessages)
+		s.protocols[name] = pm
+	}
+	pm.messages++
+	if !valid {
+		pm.invalid++
+	}
+
+	now := time.Now()
+	peerMsgs, ok := s.peers[p]
+	if !ok {
+		if len(s.peers) >= maxTrackedStatsPeers {
+			s.trimIdlePeers(now.Add(-statsIdleTime))
+			if len(s.peers) >= maxTrackedStatsPeers {
+				return
+			}
+		}
+		peerMsgs = &peerMessages{messages: make(map[string]uint64)}
+		s.peers[p] = peerMsgs
+	}
+	peerMsgs.messages[name]++
+	if !valid {
+		peerMsgs.invalid++
+	}
+	peerMsgs.lastSeen = now
+}
+
+// trimIdlePeers drops the message counts of the peers that have not sent messages
+// since the time. It must be called with the lock held.
+func (s *netStats) trimIdlePeers(since time.Time) {
+	for p, pm := range s.peers {
+		if pm.lastSeen.Before(since) {
+			delete(s.peers, p)
+		}
+	}
+}
+
+// trimIdle periodically drops the stats of idle peers until the context is done.
+func (s *netStats) trimIdle(ctx context.Context) {
+	ticker := time.NewTicker(statsTrimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		since := time.Now().Add(-statsIdleTime)
+		s.bwc.TrimIdle(since)
+		s.mu.Lock()
+		s.trimIdlePeers(since)
+		s.mu.Unlock()
+	}
+}
+
+// stats returns the current traffic and message counts.
+func (s *netStats) stats() *Stats {
+	stats := &Stats{
+		Total: newBandwidthStats(s.bwc.GetBandwidthTotals()),
+	}
+
+	byProtocol := make(map[string]*ProtocolStats)
+	for id, bw := range s.bwc.GetBandwidthByProtocol() {
+		name := s.protocolName(id)
+		byProtocol[name] = &ProtocolStats{Protocol: name, Bandwidth: newBandwidthStats(bw)}
+	}
+
+	byPeer := make(map[peer.ID]*PeerStats)
+	for p, bw := range s.bwc.GetBandwidthByPeer() {
+		byPeer[p] = &PeerStats{PeerID: p, Bandwidth: newBandwidthStats(bw), Messages: map[string]uint64{}}
+	}
+
+	s.mu.Lock()
+	for name, pm := range s.protocols {
+		ps, ok := byProtocol[name]
+		if !ok {
+			ps = &ProtocolStats{Protocol: name}
+			byProtocol[name] = ps
+		}
+		ps.Messages = pm.messages
+		ps.InvalidMessages = pm.invalid
+	}
+
+	for p, pm := range s.peers {
+		ps, ok := byPeer[p]
+		if !ok {
+			ps = &PeerStats{PeerID: p, Messages: map[string]uint64{}}
+			byPeer[p] = ps
+		}
+		for name, count := range pm.messages {
+			ps.Messages[name] = count
+		}
+		ps.InvalidMessages = pm.invalid
+	}
+	s.mu.Unlock()
+
+	stats.Protocols = make([]*ProtocolStats, 0, len(byProtocol))
+	for _, ps := range byProtocol {
+		stats.Protocols = append(stats.Protocols, ps)
+	}
+	sort.Slice(stats.Protocols, func(i, j int) bool {
+		return stats.Protocols[i].Protocol < stats.Protocols[j].Protocol
+	})
+
+	stats.Peers = make([]*PeerStats, 0, len(byPeer))
+	for _, ps := range byPeer {
+		stats.Peers = append(stats.Peers, ps)
+	}
+	sort.Slice(stats.Peers, func(i, j int) bool {
+		a, b := stats.Peers[i], stats.Peers[j]
+		if a.Bandwidth.BytesIn != b.Bandwidth.BytesIn {
+			return a.Bandwidth.BytesIn > b.Bandwidth.BytesIn
+		}
+		return a.PeerID < b.PeerID
+	})
+
+	return stats
+}
+
+// bandwidthByProtocol returns the bytes received and sent on the streams of each
+// protocol, for the Prometheus metrics.
+func (s *netStats) bandwidthByProtocol() []metrics.ProtocolBandwidth {
+	byProtocol := s.bwc.GetBandwidthByProtocol()
+	bandwidth := make([]metrics.ProtocolBandwidth, 0, len(byProtocol))
+	for id, bw := range byProtocol {
+		bandwidth = append(bandwidth, metrics.ProtocolBandwidth{
+			Protocol: s.protocolName(id),
+			BytesIn:  bw.TotalIn,
+			BytesOut: bw.TotalOut,
+		})
+	}
+	return bandwidth
+}
+
+// Stats returns the traffic with peers and the number of messages that they sent
+// us, by protocol and by peer.
+func (h *Host) Stats() *Stats {
+	return h.stats.stats()
+}
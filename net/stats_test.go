@@ -0,0 +1,74 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetStats_recordMessage(t *testing.T) {
+	const prefix = "/atomic-swap/0.3/1"
+	s := newNetStats(prefix)
+	p1, p2 := peer.ID("peer1"), peer.ID("peer2")
+
+	s.recordMessage(p1, protocol.ID(prefix+queryProtocolID), true)
+	s.recordMessage(p1, protocol.ID(prefix+queryProtocolID), false)
+	s.recordMessage(p2, protocol.ID(prefix+relayProtocolID), true)
+
+	stats := s.stats()
+	require.Len(t, stats.Protocols, 2)
+	require.Equal(t, queryProtocolID, stats.Protocols[0].Protocol)
+	require.Equal(t, uint64(2), stats.Protocols[0].Messages)
+	require.Equal(t, uint64(1), stats.Protocols[0].InvalidMessages)
+	require.Equal(t, relayProtocolID, stats.Protocols[1].Protocol)
+	require.Equal(t, uint64(1), stats.Protocols[1].Messages)
+
+	require.Len(t, stats.Peers, 2)
+	require.Equal(t, p1, stats.Peers[0].PeerID)
+	require.Equal(t, map[string]uint64{queryProtocolID: 2}, stats.Peers[0].Messages)
+	require.Equal(t, uint64(1), stats.Peers[0].InvalidMessages)
+	require.Equal(t, p2, stats.Peers[1].PeerID)
+	require.Equal(t, map[string]uint64{relayProtocolID: 1}, stats.Peers[1].Messages)
+
+	// idle peers are dropped, but still counted in the protocol totals
+	s.peers[p1].lastSeen = time.Now().Add(-statsIdleTime - time.Minute)
+	s.trimIdlePeers(time.Now().Add(-statsIdleTime))
+	stats = s.stats()
+	require.Len(t, stats.Peers, 1)
+	require.Equal(t, p2, stats.Peers[0].PeerID)
+	require.Equal(t, uint64(2), stats.Protocols[0].Messages)
+}
+
+func TestHost_Stats(t *testing.T) {
+	h1 := newHost(t, basicTestConfig(t))
+	h2 := newHost(t, basicTestConfig(t))
+
+	err := h1.h.Connect(h1.ctx, h2.AddrInfo())
+	require.NoError(t, err)
+
+	_, err = h1.Query(h2.PeerID(), nil)
+	require.NoError(t, err)
+
+	// h1 received the QueryResponse, h2 received the QueryRequest
+	stats := h1.Stats()
+	require.Len(t, stats.Peers, 1)
+	require.Equal(t, h2.PeerID(), stats.Peers[0].PeerID)
+	require.Equal(t, map[string]uint64{filteredQueryProtocolID: 1}, stats.Peers[0].Messages)
+
+	// the bandwidth counter is updated every second
+	require.Eventually(t, func() bool {
+		stats = h1.Stats()
+		return stats.Peers[0].Bandwidth.BytesIn > 0 && stats.Total.BytesOut > 0
+	}, time.Second*5, time.Millisecond*100)
+
+	require.Eventually(t, func() bool {
+		stats = h2.Stats()
+		return len(stats.Peers) == 1 && stats.Peers[0].Messages[filteredQueryProtocolID] == 1
+	}, time.Second*5, time.Millisecond*50)
+}
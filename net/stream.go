@@ -0,0 +1,82 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+var errNilStream = errors.New("stream is nil")
+
+// writeMessage writes the message to the stream, prefixed with its 4-byte little
+// endian length.
+func writeMessage(s io.Writer, msg common.Message, peerID peer.ID) error {
+	msgBytes, err := msg.Encode()
+	if err != nil {
+		return err
+	}
+
+	if err = binary.Write(s, binary.LittleEndian, uint32(len(msgBytes))); err != nil {
+		return err
+	}
+
+	if _, err = s.Write(msgBytes); err != nil {
+		return err
+	}
+
+	log.Debugf("Sent message to peer=%s type=%s", peerID, message.TypeToString(msg.Type()))
+	return nil
+}
+
+// readMessage reads the 4-byte little endian length and the body of the next
+// message, returning the body. io.EOF is returned if the stream is closed before
+// any bytes are received, and io.ErrUnexpectedEOF if it is closed part way through
+// the message.
+func readMessage(s io.Reader, maxMessageSize uint32) ([]byte, error) {
+	if s == nil {
+		return nil, errNilStream
+	}
+
+	lenBuf := make([]byte, 4)
+	n, err := io.ReadFull(s, lenBuf)
+	if err != nil {
+		if isEOF(err) {
+			if n > 0 {
+				err = io.ErrUnexpectedEOF
+			} else {
+				err = io.EOF
+			}
+		}
+		return nil, err
+	}
+
+	msgLen := binary.LittleEndian.Uint32(lenBuf)
+	if msgLen > maxMessageSize {
+		return nil, fmt.Errorf("message size %d exceeds the maximum of %d", msgLen, maxMessageSize)
+	}
+
+	msgBytes := make([]byte, msgLen)
+	if _, err = io.ReadFull(s, msgBytes); err != nil {
+		if isEOF(err) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return msgBytes, nil
+}
+
+// isEOF returns whether the error means that the stream was closed or reset.
+func isEOF(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, libp2pnetwork.ErrReset)
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestWriteReadMessage(t *testing.T) {
+	msg := &QueryResponse{Offers: []*types.Offer{}}
+	msgBytes, err := msg.Encode()
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeMessage(buf, msg, ""))
+	require.Equal(t, 4+len(msgBytes), buf.Len())
+
+	readBytes, err := readMessage(buf, maxMessageSize)
+	require.NoError(t, err)
+	require.Equal(t, msgBytes, readBytes)
+
+	// the stream is closed between messages
+	_, err = readMessage(buf, maxMessageSize)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestReadMessage_errors(t *testing.T) {
+	msg := &QueryResponse{Offers: []*types.Offer{}}
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeMessage(buf, msg, ""))
+	encoded := buf.Bytes()
+
+	type entry struct {
+		name           string
+		stream         []byte
+		maxMessageSize uint32
+		expectedErr    error
+	}
+
+	testCases := []entry{
+		{
+			name:           "closed in the length",
+			stream:         encoded[:2],
+			maxMessageSize: maxMessageSize,
+			expectedErr:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:           "closed in the message",
+			stream:         encoded[:len(encoded)-1],
+			maxMessageSize: maxMessageSize,
+			expectedErr:    io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := readMessage(bytes.NewReader(tc.stream), tc.maxMessageSize)
+		require.ErrorIs(t, err, tc.expectedErr, tc.name)
+	}
+
+	_, err := readMessage(bytes.NewReader(encoded), uint32(len(encoded)-5))
+	require.ErrorContains(t, err, "exceeds the maximum")
+
+	_, err = readMessage(nil, maxMessageSize)
+	require.ErrorIs(t, err, errNilStream)
+}
@@ -17,12 +17,21 @@ type SwapState = common.SwapStateNet //nolint:revive
 
 //nolint:revive
 type (
-	MessageType        = byte
-	Message            = common.Message
-	QueryResponse      = message.QueryResponse
-	SendKeysMessage    = message.SendKeysMessage
-	RelayClaimRequest  = message.RelayClaimRequest
-	RelayClaimResponse = message.RelayClaimResponse
+	MessageType          = byte
+	Message              = common.Message
+	QueryRequest         = message.QueryRequest
+	QueryResponse        = message.QueryResponse
+	SendKeysMessage      = message.SendKeysMessage
+	RelayClaimRequest    = message.RelayClaimRequest
+	RelayClaimResponse   = message.RelayClaimResponse
+	RelayRefundRequest   = message.RelayRefundRequest
+	RelayNewSwapRequest  = message.RelayNewSwapRequest
+	RelaySetReadyRequest = message.RelaySetReadyRequest
+	RelayFeeQuoteRequest = message.RelayFeeQuoteRequest
+	RelayFeeQuote        = message.RelayFeeQuote
+	PeerExchange         = message.PeerExchange
+	OfferAnnouncement    = message.OfferAnnouncement
+	Capabilities         = message.Capabilities
 )
 
 // MakerHandler handles swap initiation messages and offer queries. It is
@@ -32,10 +41,14 @@ type MakerHandler interface {
 	HandleInitiateMessage(peerID peer.ID, msg *SendKeysMessage) (SwapState, Message, error)
 }
 
-// RelayHandler handles relay claim requests. It is implemented by
-// *backend.backend.
+// RelayHandler handles relay claim, refund, new swap and set ready requests, and
+// fee quote requests. It is implemented by *backend.backend.
 type RelayHandler interface {
 	HandleRelayClaimRequest(msg *RelayClaimRequest) (*RelayClaimResponse, error)
+	HandleRelayRefundRequest(msg *RelayRefundRequest) (*RelayClaimResponse, error)
+	HandleRelayNewSwapRequest(msg *RelayNewSwapRequest) (*RelayClaimResponse, error)
+	HandleRelaySetReadyRequest(msg *RelaySetReadyRequest) (*RelayClaimResponse, error)
+	HandleRelayFeeQuoteRequest(msg *RelayFeeQuoteRequest) (*RelayFeeQuote, error)
 }
 
 type swap struct {
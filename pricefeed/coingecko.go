@@ -0,0 +1,90 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pricefeed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// coinGeckoURL is the base URL of CoinGecko's public API
+const coinGeckoURL = "https://api.coingecko.com/api/v3"
+
+// coinGeckoIDs are CoinGecko's IDs of the base currencies of our pairs
+var coinGeckoIDs = map[string]string{
+	"XMR": "monero",
+	"ETH": "ethereum",
+}
+
+// coinGeckoPrice is a coin's entry in the response of CoinGecko's simple/price
+// endpoint, keyed by the lowercase quote currency.
+type coinGeckoPrice map[string]json.Number
+
+// CoinGeckoOracle is a price oracle of CoinGecko's prices, which are aggregated over
+// the exchanges that it tracks.
+type CoinGeckoOracle struct {
+	httpClient *http.Client
+	url        string
+}
+
+var _ coins.PriceOracle = (*CoinGeckoOracle)(nil)
+
+// NewCoinGeckoOracle returns a price oracle that queries CoinGecko's public API with
+// the HTTP client.
+func NewCoinGeckoOracle(httpClient *http.Client) *CoinGeckoOracle {
+	return &CoinGeckoOracle{
+		httpClient: httpClient,
+		url:        coinGeckoURL,
+	}
+}
+
+// GetRate returns CoinGecko's current rate of the pair.
+func (o *CoinGeckoOracle) GetRate(ctx context.Context, pair coins.Pair) (*coins.Rate, error) {
+	id, ok := coinGeckoIDs[pair.Base]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s on coingecko", coins.ErrUnsupportedPair, pair)
+	}
+
+	quote := strings.ToLower(pair.Quote)
+	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=%s&include_last_updated_at=true", o.url, id, quote)
+
+	res := make(map[string]coinGeckoPrice)
+	if err := getJSON(ctx, o.httpClient, url, &res); err != nil {
+		return nil, err
+	}
+
+	price, ok := res[id][quote]
+	if !ok {
+		return nil, fmt.Errorf("coingecko response is missing the %s price", pair)
+	}
+
+	rate, _, err := apd.NewFromString(price.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid coingecko %s price: %w", pair, err)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid coingecko %s price %s", pair, rate)
+	}
+
+	lastUpdatedAt, err := res[id]["last_updated_at"].Int64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid coingecko %s update time: %w", pair, err)
+	}
+
+	updatedAt := time.Unix(lastUpdatedAt, 0)
+	log.Debugf("coingecko %s: %s (%s)", pair, rate, updatedAt)
+	return &coins.Rate{
+		Pair:      pair,
+		Price:     rate,
+		UpdatedAt: updatedAt,
+	}, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pricefeed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// krakenURL is the base URL of Kraken's public REST API
+const krakenURL = "https://api.kraken.com/0/public"
+
+// krakenTickerResponse is the response of Kraken's Ticker endpoint. The result is
+// keyed by Kraken's name of the pair, eg. "XXMRZUSD" for "XMRUSD".
+type krakenTickerResponse struct {
+	Error  []string                 `json:"error"`
+	Result map[string]*krakenTicker `json:"result"`
+}
+
+type krakenTicker struct {
+	Ask []string `json:"a"` // price, whole lot volume, lot volume
+	Bid []string `json:"b"` // price, whole lot volume, lot volume
+}
+
+// KrakenOracle is a price oracle of the mid prices of Kraken's order books.
+type KrakenOracle struct {
+	httpClient *http.Client
+	url        string
+}
+
+var _ coins.PriceOracle = (*KrakenOracle)(nil)
+
+// NewKrakenOracle returns a price oracle that queries Kraken's public API with the
+// HTTP client.
+func NewKrakenOracle(httpClient *http.Client) *KrakenOracle {
+	return &KrakenOracle{
+		httpClient: httpClient,
+		url:        krakenURL,
+	}
+}
+
+// GetRate returns the mid price of the pair's order book on Kraken, which is the
+// average of the best ask and bid prices.
+func (o *KrakenOracle) GetRate(ctx context.Context, pair coins.Pair) (*coins.Rate, error) {
+	name := pair.Base + pair.Quote
+
+	res := new(krakenTickerResponse)
+	if err := getJSON(ctx, o.httpClient, o.url+"/Ticker?pair="+name, res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Error) > 0 {
+		if strings.HasPrefix(res.Error[0], "EQuery:Unknown asset pair") {
+			return nil, fmt.Errorf("%w: %s on kraken", coins.ErrUnsupportedPair, pair)
+		}
+		return nil, fmt.Errorf("kraken error: %s", strings.Join(res.Error, ", "))
+	}
+
+	if len(res.Result) != 1 {
+		return nil, fmt.Errorf("kraken response has %d tickers for %s", len(res.Result), pair)
+	}
+
+	var ticker *krakenTicker
+	for _, t := range res.Result {
+		ticker = t
+	}
+
+	if ticker == nil || len(ticker.Ask) == 0 || len(ticker.Bid) == 0 {
+		return nil, fmt.Errorf("kraken ticker of %s is missing the ask or bid price", pair)
+	}
+
+	ask, _, err := apd.NewFromString(ticker.Ask[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid kraken ask price of %s: %w", pair, err)
+	}
+	bid, _, err := apd.NewFromString(ticker.Bid[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid kraken bid price of %s: %w", pair, err)
+	}
+	if ask.Sign() <= 0 || bid.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid kraken prices of %s: ask %s, bid %s", pair, ask, bid)
+	}
+
+	mid := new(apd.Decimal)
+	decimalCtx := coins.DecimalCtx()
+	if _, err = decimalCtx.Add(mid, ask, bid); err != nil {
+		return nil, err
+	}
+	if _, err = decimalCtx.Quo(mid, mid, apd.New(2, 0)); err != nil {
+		return nil, err
+	}
+	_, _ = mid.Reduce(mid)
+
+	log.Debugf("kraken %s: %s (ask %s, bid %s)", pair, mid, ask, bid)
+
+	// the ticker is live, it has no update time
+	return &coins.Rate{
+		Pair:      pair,
+		Price:     mid,
+		UpdatedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pricefeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// Price sources that the exchange rate of pegged offers can be pegged to
+const (
+	SourceChainlink = "chainlink"
+	SourceCoinGecko = "coingecko"
+	SourceKraken    = "kraken"
+)
+
+const (
+	// DefaultMaxStaleness is how old the prices of a price source can be before
+	// they're no longer used for the exchange rate of pegged offers. Chainlink only
+	// updates its prices when they deviate or once a day, so pegging offers to it
+	// requires a higher maximum.
+	DefaultMaxStaleness = 10 * time.Minute
+
+	httpTimeout         = 15 * time.Second
+	maxHTTPResponseSize = 1 << 20
+)
+
+var errStalePrice = errors.New("price is stale")
+
+// ValidateSource returns an error if the source is not a supported price source.
+func ValidateSource(source string) error {
+	switch source {
+	case SourceChainlink, SourceCoinGecko, SourceKraken:
+		return nil
+	default:
+		return fmt.Errorf("unknown price source %q, supported sources are %s, %s and %s",
+			source, SourceChainlink, SourceCoinGecko, SourceKraken)
+	}
+}
+
+// Oracle resolves the exchange rates of pegged offers from the price oracles of the
+// price sources.
+type Oracle struct {
+	sources      map[string]coins.PriceOracle
+	maxStaleness time.Duration
+}
+
+// NewOracle returns an Oracle that queries chainlink with the ethereum client, and
+// the other sources through the optional proxy. Prices older than maxStaleness are
+// not used, DefaultMaxStaleness applies if it is zero.
+func NewOracle(ec *ethclient.Client, proxy *common.Proxy, maxStaleness time.Duration) *Oracle {
+	httpClient := &http.Client{Transport: proxy.HTTPTransport(), Timeout: httpTimeout}
+
+	sources := map[string]coins.PriceOracle{
+		SourceCoinGecko: NewCoinGeckoOracle(httpClient),
+		SourceKraken:    NewKrakenOracle(httpClient),
+	}
+	if ec != nil {
+		sources[SourceChainlink] = NewChainlinkOracle(ec)
+	}
+
+	return NewOracleWithSources(sources, maxStaleness)
+}
+
+// NewOracleWithSources returns an Oracle with the price oracles of the sources, eg.
+// mock oracles in tests.
+func NewOracleWithSources(sources map[string]coins.PriceOracle, maxStaleness time.Duration) *Oracle {
+	if maxStaleness == 0 {
+		maxStaleness = DefaultMaxStaleness
+	}
+
+	return &Oracle{
+		sources:      sources,
+		maxStaleness: maxStaleness,
+	}
+}
+
+// PriceOracle returns the price oracle of the source.
+func (o *Oracle) PriceOracle(source string) (coins.PriceOracle, error) {
+	oracle, ok := o.sources[source]
+	if !ok {
+		if err := ValidateSource(source); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("price source %s is not available", source)
+	}
+
+	return oracle, nil
+}
+
+// ExchangeRate returns the XMR/ETH market price of the source. It errors if either
+// of the source's prices is older than the maximum staleness.
+func (o *Oracle) ExchangeRate(ctx context.Context, source string) (*coins.ExchangeRate, error) {
+	oracle, err := o.PriceOracle(source)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, updatedAt, err := coins.OracleExchangeRate(ctx, oracle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices from %s: %w", source, err)
+	}
+
+	if age := time.Since(updatedAt); age > o.maxStaleness {
+		return nil, fmt.Errorf("%w: prices of %s were updated %s ago, the maximum is %s",
+			errStalePrice, source, age.Round(time.Second), o.maxStaleness)
+	}
+
+	return rate, nil
+}
+
+// PeggedExchangeRate returns the exchange rate of the peg, which is the XMR/ETH
+// market price of its source plus its markup.
+func (o *Oracle) PeggedExchangeRate(ctx context.Context, peg *types.OfferPeg) (*coins.ExchangeRate, error) {
+	rate, err := o.ExchangeRate(ctx, peg.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	return rate.WithMarkup(peg.Markup)
+}
+
+// getJSON decodes the JSON response of a GET request of the URL into the result.
+func getJSON(ctx context.Context, httpClient *http.Client, url string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxHTTPResponseSize)).Decode(result)
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pricefeed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// newTestServer returns the URL of an HTTP server that responds to each path and
+// query with the response.
+func newTestServer(t *testing.T, responses map[string]string) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, ok := responses[r.URL.Path+"?"+r.URL.RawQuery]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(res))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newMockOracle(xmrPrice string, ethPrice string, updatedAt time.Time) *coins.MockPriceOracle {
+	return &coins.MockPriceOracle{Rates: map[coins.Pair]*coins.Rate{
+		coins.PairXMRUSD: {Pair: coins.PairXMRUSD, Price: coins.StrToDecimal(xmrPrice), UpdatedAt: updatedAt},
+		coins.PairETHUSD: {Pair: coins.PairETHUSD, Price: coins.StrToDecimal(ethPrice), UpdatedAt: updatedAt},
+	}}
+}
+
+func TestOracle_PeggedExchangeRate(t *testing.T) {
+	o := NewOracleWithSources(map[string]coins.PriceOracle{
+		SourceKraken: newMockOracle("150.25", "2003.33", time.Now()),
+	}, 0)
+
+	rate, err := o.ExchangeRate(context.Background(), SourceKraken)
+	require.NoError(t, err)
+	require.Equal(t, "0.075", rate.String()) // 150.25 / 2003.33 = 0.0750001...
+
+	peg := &types.OfferPeg{Source: SourceKraken, Markup: apd.New(2, 0)}
+	rate, err = o.PeggedExchangeRate(context.Background(), peg)
+	require.NoError(t, err)
+	require.Equal(t, "0.0765", rate.String())
+
+	_, err = o.ExchangeRate(context.Background(), SourceCoinGecko)
+	require.ErrorContains(t, err, "price source coingecko is not available")
+
+	_, err = o.ExchangeRate(context.Background(), "binance")
+	require.ErrorContains(t, err, `unknown price source "binance"`)
+}
+
+func TestOracle_stale(t *testing.T) {
+	stale := time.Now().Add(-DefaultMaxStaleness - time.Minute)
+	o := NewOracleWithSources(map[string]coins.PriceOracle{
+		SourceChainlink: newMockOracle("150.25", "2003.33", stale),
+	}, 0)
+
+	_, err := o.ExchangeRate(context.Background(), SourceChainlink)
+	require.ErrorIs(t, err, errStalePrice)
+
+	// a higher maximum accepts the prices
+	o = NewOracleWithSources(o.sources, time.Hour)
+	_, err = o.ExchangeRate(context.Background(), SourceChainlink)
+	require.NoError(t, err)
+}
+
+func TestCoinGeckoOracle_GetRate(t *testing.T) {
+	now := time.Now().Unix()
+	o := NewCoinGeckoOracle(http.DefaultClient)
+	o.url = newTestServer(t, map[string]string{
+		"/simple/price?ids=monero&vs_currencies=usd&include_last_updated_at=true": fmt.Sprintf(
+			`{"monero": {"usd": 150.25, "last_updated_at": %d}}`, now),
+		"/simple/price?ids=ethereum&vs_currencies=usd&include_last_updated_at=true": fmt.Sprintf(
+			`{"ethereum": {"usd": 2003.33, "last_updated_at": %d}}`, now-60),
+	})
+
+	rate, err := o.GetRate(context.Background(), coins.PairXMRUSD)
+	require.NoError(t, err)
+	require.Equal(t, "150.25", rate.Price.String())
+	require.Equal(t, now, rate.UpdatedAt.Unix())
+
+	exchangeRate, updatedAt, err := coins.OracleExchangeRate(context.Background(), o)
+	require.NoError(t, err)
+	require.Equal(t, "0.075", exchangeRate.String())
+	require.Equal(t, now-60, updatedAt.Unix())
+
+	_, err = o.GetRate(context.Background(), coins.Pair{Base: "BTC", Quote: "USD"})
+	require.ErrorIs(t, err, coins.ErrUnsupportedPair)
+}
+
+func TestKrakenOracle_GetRate(t *testing.T) {
+	o := NewKrakenOracle(http.DefaultClient)
+	o.url = newTestServer(t, map[string]string{
+		"/Ticker?pair=XMRUSD": `{"error": [], "result": {"XXMRZUSD": {
+			"a": ["150.30000000", "1", "1.000"], "b": ["150.20000000", "3", "3.000"], "c": ["150.25", "0.1"]
+		}}}`,
+		"/Ticker?pair=ETHUSD": `{"error": [], "result": {"XETHZUSD": {
+			"a": ["2003.34", "1", "1.000"], "b": ["2003.32", "2", "2.000"]
+		}}}`,
+		"/Ticker?pair=XMREUR": `{"error": ["EQuery:Unknown asset pair"]}`,
+	})
+
+	rate, err := o.GetRate(context.Background(), coins.PairXMRUSD)
+	require.NoError(t, err)
+	require.Equal(t, "150.25", rate.Price.String())
+
+	rate, err = o.GetRate(context.Background(), coins.PairETHUSD)
+	require.NoError(t, err)
+	require.Equal(t, "2003.33", rate.Price.String())
+
+	_, err = o.GetRate(context.Background(), coins.Pair{Base: "XMR", Quote: "EUR"})
+	require.ErrorIs(t, err, coins.ErrUnsupportedPair)
+}
+
+func TestValidateSource(t *testing.T) {
+	for _, source := range []string{SourceChainlink, SourceCoinGecko, SourceKraken} {
+		require.NoError(t, ValidateSource(source))
+	}
+	require.ErrorContains(t, ValidateSource("binance"), `unknown price source "binance"`)
+}
@@ -2,12 +2,14 @@
 // SPDX-License-Identifier: LGPL-3.0-only
 
 // Package pricefeed implements routines to retrieve on-chain price feeds from chainlink's
-// decentralized oracle network.
+// decentralized oracle network, and the market prices of exchange-rate oracles that
+// pegged offers are priced with.
 package pricefeed
 
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/cockroachdb/apd/v3"
@@ -16,6 +18,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	logging "github.com/ipfs/go-log"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 )
@@ -108,6 +111,43 @@ func GetXMRUSDPrice(ctx context.Context, ec *ethclient.Client) (*PriceFeed, erro
 	return getChainlinkPriceFeed(ctx, chainlinkXMRToUSDProxy, ec)
 }
 
+// ChainlinkOracle is a price oracle of chainlink's on-chain price feeds.
+type ChainlinkOracle struct {
+	ec *ethclient.Client
+}
+
+var _ coins.PriceOracle = (*ChainlinkOracle)(nil)
+
+// NewChainlinkOracle returns a price oracle that reads chainlink's price feeds with
+// the ethereum client, as GetXMRUSDPrice and GetETHUSDPrice do.
+func NewChainlinkOracle(ec *ethclient.Client) *ChainlinkOracle {
+	return &ChainlinkOracle{ec: ec}
+}
+
+// GetRate returns the rate of the pair from its chainlink price feed.
+func (o *ChainlinkOracle) GetRate(ctx context.Context, pair coins.Pair) (*coins.Rate, error) {
+	var feed *PriceFeed
+	var err error
+
+	switch pair {
+	case coins.PairXMRUSD:
+		feed, err = GetXMRUSDPrice(ctx, o.ec)
+	case coins.PairETHUSD:
+		feed, err = GetETHUSDPrice(ctx, o.ec)
+	default:
+		return nil, fmt.Errorf("%w: %s on chainlink", coins.ErrUnsupportedPair, pair)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &coins.Rate{
+		Pair:      pair,
+		Price:     feed.Price,
+		UpdatedAt: feed.UpdatedAt,
+	}, nil
+}
+
 // getChainlinkPriceFeed retries the latest price feed data from the given contract address.
 func getChainlinkPriceFeed(ctx context.Context, feedAddress string, ec *ethclient.Client) (*PriceFeed, error) {
 	chainlinkPriceFeedProxy, err := contracts.NewAggregatorV3Interface(ethcommon.HexToAddress(feedAddress), ec)
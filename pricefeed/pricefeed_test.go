@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/tests"
 )
 
@@ -75,3 +76,19 @@ func TestGetXMRUSDPrice_dev(t *testing.T) {
 	assert.Equal(t, "XMR / USD (fake)", feed.Description)
 	assert.Equal(t, "123.12345678", feed.Price.String())
 }
+
+func TestChainlinkOracle_GetRate_dev(t *testing.T) {
+	ec, _ := tests.NewEthClient(t)
+	o := NewChainlinkOracle(ec)
+
+	rate, err := o.GetRate(context.Background(), coins.PairXMRUSD)
+	require.NoError(t, err)
+	assert.Equal(t, "123.12345678", rate.Price.String())
+
+	rate, err = o.GetRate(context.Background(), coins.PairETHUSD)
+	require.NoError(t, err)
+	assert.Equal(t, "1234.12345678", rate.Price.String())
+
+	_, err = o.GetRate(context.Background(), coins.Pair{Base: "XMR", Quote: "ETH"})
+	require.ErrorIs(t, err, coins.ErrUnsupportedPair)
+}
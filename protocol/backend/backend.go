@@ -10,9 +10,12 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/MarinX/monerorpc/wallet"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	logging "github.com/ipfs/go-log"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/common"
@@ -20,6 +23,7 @@ import (
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/db"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/erc4337"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
@@ -28,12 +32,22 @@ import (
 	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
+var log = logging.Logger("backend")
+
 // NetSender consists of Host methods invoked by the Maker/Taker
 type NetSender interface {
 	SendSwapMessage(common.Message, types.Hash) error
 	CloseProtocolStream(id types.Hash)
-	DiscoverRelayers() ([]peer.ID, error)                                                          // Only used by Maker
-	SubmitClaimToRelayer(peer.ID, *message.RelayClaimRequest) (*message.RelayClaimResponse, error) // Only used by Taker
+	DiscoverRelayers() ([]peer.ID, error)
+
+	// Only used by Taker
+	SubmitClaimToRelayer(peer.ID, *message.RelayClaimRequest) (*message.RelayClaimResponse, error)
+	SubmitRefundToRelayer(peer.ID, *message.RelayRefundRequest) (*message.RelayClaimResponse, error)
+	SubmitNewSwapToRelayer(peer.ID, *message.RelayNewSwapRequest) (*message.RelayClaimResponse, error)
+	SubmitSetReadyToRelayer(peer.ID, *message.RelaySetReadyRequest) (*message.RelayClaimResponse, error)
+
+	// Only used by Maker
+	QueryRelayerFee(peer.ID, *message.RelayFeeQuoteRequest) (*message.RelayFeeQuote, error)
 }
 
 // RecoveryDB is implemented by *db.RecoveryDB
@@ -51,6 +65,22 @@ type RecoveryDB interface {
 	DeleteSwap(id types.Hash) error
 }
 
+// ContractEventsDB is implemented by *db.Database
+type ContractEventsDB interface {
+	GetContractEvents(contractAddr ethcommon.Address, swapID *types.Hash) ([]*db.ContractEvent, error)
+}
+
+// RelayerStatsDB is implemented by *db.Database
+type RelayerStatsDB interface {
+	RecordRelayerOutcome(peerID peer.ID, outcome db.RelayerOutcome) error
+	GetRelayerStats(peerID peer.ID) (*db.RelayerStats, error)
+}
+
+// RelayedClaimsDB is implemented by *db.Database
+type RelayedClaimsDB interface {
+	PutRelayedClaim(claim *db.RelayedClaim) error
+}
+
 // Backend provides an interface for both the XMRTaker and XMRMaker into the Monero/Ethereum chains.
 // It also interfaces with the network layer.
 type Backend interface {
@@ -65,7 +95,15 @@ type Backend interface {
 
 	// helpers
 	NewSwapCreator(addr ethcommon.Address) (*contracts.SwapCreator, error)
+	ContractEvents(contractSwapID types.Hash) ([]*db.ContractEvent, error)
+	RecordRelayerOutcome(peerID peer.ID, outcome db.RelayerOutcome)
+	RelayerScore(peerID peer.ID) float64
+	UserOpAccount() *erc4337.ClaimAccount
 	HandleRelayClaimRequest(request *message.RelayClaimRequest) (*message.RelayClaimResponse, error)
+	HandleRelayRefundRequest(request *message.RelayRefundRequest) (*message.RelayClaimResponse, error)
+	HandleRelayNewSwapRequest(request *message.RelayNewSwapRequest) (*message.RelayClaimResponse, error)
+	HandleRelaySetReadyRequest(request *message.RelaySetReadyRequest) (*message.RelayClaimResponse, error)
+	HandleRelayFeeQuoteRequest(request *message.RelayFeeQuoteRequest) (*message.RelayFeeQuote, error)
 
 	// getters
 	Ctx() context.Context
@@ -74,12 +112,24 @@ type Backend interface {
 	SwapCreator() *contracts.SwapCreator
 	SwapCreatorAddr() ethcommon.Address
 	SwapTimeout() time.Duration
+	RelayerFee() *relayer.FeeConfig
+	DirectClaimFallback() *DirectClaimFallback
 	XMRDepositAddress(offerID *types.Hash) *mcrypto.Address
+	XMRPriority(offerID *types.Hash) wallet.Priority
+	Paused() bool
+
+	// profiles
+	WithProfile(name string) (Backend, error)
+	ProfileName() string
+	ProfileNames() []string
 
 	// setters
 	SetSwapTimeout(timeout time.Duration)
+	SetPaused(paused bool)
 	SetXMRDepositAddress(*mcrypto.Address, types.Hash)
 	ClearXMRDepositAddress(types.Hash)
+	SetXMRPriority(wallet.Priority, types.Hash)
+	ClearXMRPriority(types.Hash)
 }
 
 type backend struct {
@@ -87,11 +137,35 @@ type backend struct {
 	env         common.Environment
 	swapManager swap.Manager
 	recoveryDB  RecoveryDB
+	eventsDB    ContractEventsDB
+	relayerDB   RelayerStatsDB
+	relayedDB   RelayedClaimsDB
+
+	// ERC-4337 account used to submit relayed claims through a bundler, nil if
+	// not configured
+	userOpAccount *erc4337.ClaimAccount
+
+	// fee offered to relayers for our claims and required for claims we relay
+	relayerFee *relayer.FeeConfig
+
+	// reuses our recent fee quotes for identical quote requests
+	relayQuotes *relayer.QuoteCache
+
+	// batches the claims we relay into shared transactions, nil if not configured
+	claimBatcher *relayer.ClaimBatcher
+
+	// whether and when to claim directly when relaying our claim fails
+	directClaimFallback *DirectClaimFallback
 
 	// wallet/node endpoints
 	moneroWallet monero.WalletClient
 	ethClient    extethclient.EthClient
 
+	// named profiles whose wallets and accounts fund the offers and swaps that
+	// select them, instead of the default ones above
+	profiles     map[string]*profileBackend
+	profileNames []string
+
 	// Monero deposit address. When the XMR maker has noTransferBack set to
 	// false (default), claimed funds are swept into the primary XMR wallet
 	// address used by swapd. This sweep destination address can be overridden
@@ -100,11 +174,21 @@ type backend struct {
 	perSwapXMRDepositAddrRWMu sync.RWMutex
 	perSwapXMRDepositAddr     map[types.Hash]*mcrypto.Address
 
+	// Fee priority of our Monero lock and sweep transactions, which can be
+	// overridden on a per-swap basis in the map below, e.g. to confirm a lock
+	// faster when the swap is close to its timeout.
+	xmrPriority            wallet.Priority
+	perSwapXMRPriorityRWMu sync.RWMutex
+	perSwapXMRPriority     map[types.Hash]wallet.Priority
+
 	// swap contract
 	swapCreator     *contracts.SwapCreator
 	swapCreatorAddr ethcommon.Address
 	swapTimeout     time.Duration
 
+	// whether new swaps are refused, while ongoing swaps continue
+	paused atomic.Bool
+
 	// network interface
 	NetSender
 }
@@ -118,9 +202,36 @@ type Config struct {
 	SwapCreatorAddr ethcommon.Address
 	SwapManager     swap.Manager
 	RecoveryDB      RecoveryDB
+	ContractEvents  ContractEventsDB      // optional
+	RelayerStats    RelayerStatsDB        // optional
+	RelayedClaims   RelayedClaimsDB       // optional
+	UserOpAccount   *erc4337.ClaimAccount // optional
+	RelayerFee      *relayer.FeeConfig    // optional, relayer.DefaultFeeConfig() if nil
+	RelayerBatch    *relayer.BatchConfig  // optional, relayed claims are not batched if nil
+	DirectClaim     *DirectClaimFallback  // optional, DefaultDirectClaimFallback() if nil
+	XMRPriority     wallet.Priority       // optional, the wallet's default priority if zero
+	Profiles        []*Profile            // optional, named profiles besides the default
 	Net             NetSender
 }
 
+// DirectClaimFallback configures claiming a swap with our own transaction, paying
+// for the gas, when relaying the claim fails. The fallback is only used if our
+// balance covers the gas of the claim.
+type DirectClaimFallback struct {
+	Enabled bool
+
+	// RelayMargin is how long before the swap's timeout t1 that we stop trying
+	// other relayers, leaving time for the XMR taker and the direct claim. If
+	// zero, relaying stops when a tenth of the claim window remains.
+	RelayMargin time.Duration
+}
+
+// DefaultDirectClaimFallback returns the default direct claim fallback, which is
+// enabled.
+func DefaultDirectClaimFallback() *DirectClaimFallback {
+	return &DirectClaimFallback{Enabled: true}
+}
+
 // NewBackend returns a new Backend
 func NewBackend(cfg *Config) (Backend, error) {
 	if (cfg.SwapCreatorAddr == ethcommon.Address{}) {
@@ -132,7 +243,37 @@ func NewBackend(cfg *Config) (Backend, error) {
 		return nil, err
 	}
 
-	return &backend{
+	relayerFee := cfg.RelayerFee
+	if relayerFee == nil {
+		relayerFee = relayer.DefaultFeeConfig()
+	}
+	if err = relayerFee.Validate(); err != nil {
+		return nil, err
+	}
+
+	directClaim := cfg.DirectClaim
+	if directClaim == nil {
+		directClaim = DefaultDirectClaimFallback()
+	}
+	if directClaim.RelayMargin < 0 {
+		return nil, errors.New("direct claim relay margin cannot be negative")
+	}
+
+	var claimBatcher *relayer.ClaimBatcher
+	if cfg.RelayerBatch != nil {
+		claimBatcher, err = relayer.NewClaimBatcher(
+			cfg.Ctx,
+			cfg.EthereumClient,
+			cfg.SwapCreatorAddr,
+			relayerFee,
+			cfg.RelayerBatch,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b := &backend{
 		ctx:                   cfg.Ctx,
 		env:                   cfg.Environment,
 		moneroWallet:          cfg.MoneroClient,
@@ -143,8 +284,41 @@ func NewBackend(cfg *Config) (Backend, error) {
 		swapTimeout:           common.SwapTimeoutFromEnv(cfg.Environment),
 		NetSender:             cfg.Net,
 		perSwapXMRDepositAddr: make(map[types.Hash]*mcrypto.Address),
+		xmrPriority:           cfg.XMRPriority,
+		perSwapXMRPriority:    make(map[types.Hash]wallet.Priority),
 		recoveryDB:            cfg.RecoveryDB,
-	}, nil
+		eventsDB:              cfg.ContractEvents,
+		relayerDB:             cfg.RelayerStats,
+		relayedDB:             cfg.RelayedClaims,
+		userOpAccount:         cfg.UserOpAccount,
+		relayerFee:            relayerFee,
+		relayQuotes:           relayer.NewQuoteCache(relayer.DefaultQuoteCacheTTL),
+		claimBatcher:          claimBatcher,
+		directClaimFallback:   directClaim,
+		profiles:              make(map[string]*profileBackend),
+	}
+
+	for _, p := range cfg.Profiles {
+		if p.Name == "" {
+			return nil, errors.New("profile name cannot be empty")
+		}
+		if err = ValidateProfileName(p.Name); err != nil {
+			return nil, err
+		}
+		if _, ok := b.profiles[p.Name]; ok {
+			return nil, fmt.Errorf("duplicate profile %q", p.Name)
+		}
+
+		b.profiles[p.Name] = &profileBackend{
+			backend:   b,
+			name:      p.Name,
+			xmrClient: p.XMRClient,
+			ethClient: p.ETHClient,
+		}
+		b.profileNames = append(b.profileNames, p.Name)
+	}
+
+	return b, nil
 }
 
 func (b *backend) XMRClient() monero.WalletClient {
@@ -156,11 +330,19 @@ func (b *backend) ETHClient() extethclient.EthClient {
 }
 
 func (b *backend) NewTxSender(asset ethcommon.Address, erc20Contract *contracts.IERC20) (txsender.Sender, error) {
-	if !b.ethClient.HasPrivateKey() {
-		return txsender.NewExternalSender(b.ctx, b.env, b.ethClient.Raw(), b.swapCreatorAddr, asset)
+	return b.newTxSender(b.ethClient, asset, erc20Contract)
+}
+
+func (b *backend) newTxSender(
+	ec extethclient.EthClient,
+	asset ethcommon.Address,
+	erc20Contract *contracts.IERC20,
+) (txsender.Sender, error) {
+	if !ec.HasSigner() {
+		return txsender.NewExternalSender(b.ctx, b.env, ec.Raw(), b.swapCreatorAddr, asset)
 	}
 
-	return txsender.NewSenderWithPrivateKey(b.ctx, b.ETHClient(), b.swapCreatorAddr, b.swapCreator, erc20Contract), nil
+	return txsender.NewSenderWithPrivateKey(b.ctx, ec, b.swapCreatorAddr, b.swapCreator, erc20Contract), nil
 }
 
 func (b *backend) RecoveryDB() RecoveryDB {
@@ -191,31 +373,94 @@ func (b *backend) SwapTimeout() time.Duration {
 	return b.swapTimeout
 }
 
+// RelayerFee returns the relayer fee configuration
+func (b *backend) RelayerFee() *relayer.FeeConfig {
+	return b.relayerFee
+}
+
+func (b *backend) DirectClaimFallback() *DirectClaimFallback {
+	return b.directClaimFallback
+}
+
 // SetSwapTimeout sets the duration between the swap being initiated on-chain and the timeout t0,
 // and the duration between t0 and t1.
 func (b *backend) SetSwapTimeout(timeout time.Duration) {
 	b.swapTimeout = timeout
 }
 
+// Paused returns whether new swaps are refused.
+func (b *backend) Paused() bool {
+	return b.paused.Load()
+}
+
+// SetPaused sets whether new swaps are refused. Pausing doesn't affect ongoing swaps,
+// which continue until they complete.
+func (b *backend) SetPaused(paused bool) {
+	b.paused.Store(paused)
+}
+
 func (b *backend) NewSwapCreator(addr ethcommon.Address) (*contracts.SwapCreator, error) {
 	return contracts.NewSwapCreator(addr, b.ethClient.Raw())
 }
 
+func (b *backend) UserOpAccount() *erc4337.ClaimAccount {
+	return b.userOpAccount
+}
+
+// ContractEvents returns the indexed events of the swap with the given contract swap
+// ID on the swap creator contract, in the order they were emitted. Returns no events
+// if contract events are not being indexed.
+func (b *backend) ContractEvents(contractSwapID types.Hash) ([]*db.ContractEvent, error) {
+	if b.eventsDB == nil {
+		return nil, nil
+	}
+	return b.eventsDB.GetContractEvents(b.swapCreatorAddr, &contractSwapID)
+}
+
+// RecordRelayerOutcome records the outcome of a claim submitted to the relayer.
+// Errors are logged, as the stats only guide relayer selection.
+func (b *backend) RecordRelayerOutcome(peerID peer.ID, outcome db.RelayerOutcome) {
+	if b.relayerDB == nil {
+		return
+	}
+	if err := b.relayerDB.RecordRelayerOutcome(peerID, outcome); err != nil {
+		log.Warnf("failed to record relayer outcome %s for %s: %s", outcome, peerID, err)
+	}
+}
+
+// RelayerScore returns the estimated probability, based on its recorded outcomes,
+// that the relayer confirms our next claim.
+func (b *backend) RelayerScore(peerID peer.ID) float64 {
+	var stats *db.RelayerStats
+	if b.relayerDB != nil {
+		// stats are nil, scoring as a relayer without history, if none were recorded
+		stats, _ = b.relayerDB.GetRelayerStats(peerID)
+	}
+
+	return stats.Score()
+}
+
 // XMRDepositAddress returns the per-swap override deposit address, if a
 // per-swap address was set. Otherwise the primary swapd Monero wallet address
 // is returned.
 func (b *backend) XMRDepositAddress(offerID *types.Hash) *mcrypto.Address {
+	if addr := b.perSwapXMRDepositAddress(offerID); addr != nil {
+		return addr
+	}
+
+	return b.XMRClient().PrimaryAddress()
+}
+
+// perSwapXMRDepositAddress returns the per-swap override deposit address, or nil if
+// none was set.
+func (b *backend) perSwapXMRDepositAddress(offerID *types.Hash) *mcrypto.Address {
 	b.perSwapXMRDepositAddrRWMu.RLock()
 	defer b.perSwapXMRDepositAddrRWMu.RUnlock()
 
-	if offerID != nil {
-		addr, ok := b.perSwapXMRDepositAddr[*offerID]
-		if ok {
-			return addr
-		}
+	if offerID == nil {
+		return nil
 	}
-
-	return b.XMRClient().PrimaryAddress()
+	return b.perSwapXMRDepositAddr[*offerID]
 }
 
 // SetXMRDepositAddress sets a per-swap override deposit address to use when
@@ -236,6 +481,39 @@ func (b *backend) ClearXMRDepositAddress(offerID types.Hash) {
 	delete(b.perSwapXMRDepositAddr, offerID)
 }
 
+// XMRPriority returns the per-swap override fee priority of the Monero lock and
+// sweep transactions, if a per-swap priority was set. Otherwise the configured
+// priority is returned.
+func (b *backend) XMRPriority(offerID *types.Hash) wallet.Priority {
+	b.perSwapXMRPriorityRWMu.RLock()
+	defer b.perSwapXMRPriorityRWMu.RUnlock()
+
+	if offerID != nil {
+		priority, ok := b.perSwapXMRPriority[*offerID]
+		if ok {
+			return priority
+		}
+	}
+
+	return b.xmrPriority
+}
+
+// SetXMRPriority sets a per-swap override fee priority of the Monero lock and
+// sweep transactions of the swap that are sent after it is set.
+func (b *backend) SetXMRPriority(priority wallet.Priority, offerID types.Hash) {
+	b.perSwapXMRPriorityRWMu.Lock()
+	defer b.perSwapXMRPriorityRWMu.Unlock()
+	b.perSwapXMRPriority[offerID] = priority
+}
+
+// ClearXMRPriority clears the per-swap, override fee priority from the map if a
+// value was set.
+func (b *backend) ClearXMRPriority(offerID types.Hash) {
+	b.perSwapXMRPriorityRWMu.Lock()
+	defer b.perSwapXMRPriorityRWMu.Unlock()
+	delete(b.perSwapXMRPriority, offerID)
+}
+
 // HandleRelayClaimRequest validates and sends the transaction for a relay claim request
 func (b *backend) HandleRelayClaimRequest(request *message.RelayClaimRequest) (*message.RelayClaimResponse, error) {
 	// In the taker relay scenario, the net layer has already validated that we
@@ -254,10 +532,123 @@ func (b *backend) HandleRelayClaimRequest(request *message.RelayClaimRequest) (*
 		}
 	}
 
-	return relayer.ValidateAndSendTransaction(
+	var (
+		relayed *relayer.RelayedClaim
+		err     error
+	)
+
+	// Claims of our own swap's counterparty are not delayed by batching
+	if b.claimBatcher != nil && request.OfferID == nil {
+		relayed, err = b.claimBatcher.Submit(request)
+	} else {
+		relayed, err = relayer.ValidateAndSendTransaction(
+			b.Ctx(),
+			request,
+			b.ETHClient(),
+			b.SwapCreatorAddr(),
+			b.relayerFee,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b.recordRelayedClaim(relayed)
+
+	return &message.RelayClaimResponse{TxHash: relayed.TxHash}, nil
+}
+
+// HandleRelayRefundRequest validates and sends the transaction for a relay refund
+// request
+func (b *backend) HandleRelayRefundRequest(request *message.RelayRefundRequest) (*message.RelayClaimResponse, error) {
+	relayed, err := relayer.ValidateAndSendRefund(
 		b.Ctx(),
 		request,
 		b.ETHClient(),
 		b.SwapCreatorAddr(),
+		b.relayerFee,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	b.recordRelayedClaim(relayed)
+
+	return &message.RelayClaimResponse{TxHash: relayed.TxHash}, nil
+}
+
+// HandleRelayNewSwapRequest validates and sends the transaction for a relay new swap
+// request
+func (b *backend) HandleRelayNewSwapRequest(request *message.RelayNewSwapRequest) (*message.RelayClaimResponse, error) {
+	relayed, err := relayer.ValidateAndSendNewSwap(
+		b.Ctx(),
+		request,
+		b.ETHClient(),
+		b.SwapCreatorAddr(),
+		b.relayerFee,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	b.recordRelayedClaim(relayed)
+
+	return &message.RelayClaimResponse{TxHash: relayed.TxHash}, nil
+}
+
+// HandleRelaySetReadyRequest validates and sends the transaction for a relay set
+// ready request
+func (b *backend) HandleRelaySetReadyRequest(
+	request *message.RelaySetReadyRequest,
+) (*message.RelayClaimResponse, error) {
+	relayed, err := relayer.ValidateAndSendSetReady(
+		b.Ctx(),
+		request,
+		b.ETHClient(),
+		b.SwapCreatorAddr(),
+		b.relayerFee,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	b.recordRelayedClaim(relayed)
+
+	return &message.RelayClaimResponse{TxHash: relayed.TxHash}, nil
+}
+
+// recordRelayedClaim stores the earnings and gas cost of a claim, or another swap
+// transaction, that we relayed. Errors are logged, as the transaction was already
+// relayed.
+func (b *backend) recordRelayedClaim(relayed *relayer.RelayedClaim) {
+	if b.relayedDB == nil {
+		return
+	}
+
+	err := b.relayedDB.PutRelayedClaim(&db.RelayedClaim{
+		SwapID:     relayed.SwapID,
+		TxHash:     relayed.TxHash,
+		FeeAsset:   relayed.FeeAsset,
+		FeeWei:     relayed.FeeWei,
+		GasCostWei: relayed.GasCostWei,
+		Time:       time.Now(),
+	})
+	if err != nil {
+		log.Warnf("failed to record relayed claim of swap %s: %s", relayed.SwapID, err)
+	}
+}
+
+// HandleRelayFeeQuoteRequest returns the fee we require for relaying the claim of
+// a swap with the requested values. Recent quotes are reused for identical
+// requests.
+func (b *backend) HandleRelayFeeQuoteRequest(request *message.RelayFeeQuoteRequest) (*message.RelayFeeQuote, error) {
+	return b.relayQuotes.Quote(request, func() (*message.RelayFeeQuote, error) {
+		return relayer.QuoteFee(
+			b.Ctx(),
+			request,
+			b.ETHClient(),
+			b.SwapCreatorAddr(),
+			b.relayerFee,
+		)
+	})
 }
@@ -8,9 +8,11 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/tests"
 
+	"github.com/MarinX/monerorpc/wallet"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/require"
@@ -50,3 +52,21 @@ func TestWaitForReceipt(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, tx.Hash(), receipt.TxHash)
 }
+
+func TestBackend_XMRPriority(t *testing.T) {
+	b := &backend{
+		xmrPriority:        wallet.PriorityUnimportant,
+		perSwapXMRPriority: make(map[types.Hash]wallet.Priority),
+	}
+
+	id := types.Hash{1}
+	require.Equal(t, wallet.PriorityUnimportant, b.XMRPriority(nil))
+	require.Equal(t, wallet.PriorityUnimportant, b.XMRPriority(&id))
+
+	b.SetXMRPriority(wallet.PriorityElevated, id)
+	require.Equal(t, wallet.PriorityElevated, b.XMRPriority(&id))
+	require.Equal(t, wallet.PriorityUnimportant, b.XMRPriority(&types.Hash{2}))
+
+	b.ClearXMRPriority(id)
+	require.Equal(t, wallet.PriorityUnimportant, b.XMRPriority(&id))
+}
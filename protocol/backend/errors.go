@@ -9,4 +9,12 @@ import (
 
 var (
 	errNilSwapContractOrAddress = errors.New("must provide swap contract and address")
+
+	// ErrPaused is returned when a new swap is initiated or accepted while swapd is
+	// paused.
+	ErrPaused = errors.New("swapd is paused and not accepting new swaps")
+
+	// ErrUnknownProfile is returned when an offer or swap selects a profile that
+	// isn't configured.
+	ErrUnknownProfile = errors.New("unknown profile")
 )
@@ -0,0 +1,110 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package backend
+
+import (
+	"fmt"
+	"regexp"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/protocol/txsender"
+)
+
+// profileNameRegex matches the valid profile names, which are also the names of the
+// profiles' directories.
+var profileNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Profile is a named Monero wallet and Ethereum account, besides the default ones,
+// that offers and swaps can be funded from, so that the inventory of different
+// strategies is kept apart.
+type Profile struct {
+	Name      string
+	XMRClient monero.WalletClient
+	ETHClient extethclient.EthClient
+}
+
+// ValidateProfileName returns an error if the name isn't a valid profile name. The
+// empty name is the default profile.
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if !profileNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, expected up to 32 lowercase letters, digits, '-' or '_'", name)
+	}
+	return nil
+}
+
+// profileBackend is the backend of a swap funded from a named profile. It uses the
+// profile's wallet and account, and shares everything else with the default backend.
+type profileBackend struct {
+	*backend
+	name      string
+	xmrClient monero.WalletClient
+	ethClient extethclient.EthClient
+}
+
+// WithProfile returns the backend that uses the wallet and account of the named
+// profile, or the default backend if the name is empty.
+func (b *backend) WithProfile(name string) (Backend, error) {
+	if name == "" {
+		return b, nil
+	}
+
+	p, ok := b.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProfile, name)
+	}
+	return p, nil
+}
+
+// ProfileName returns the name of the backend's profile, which is empty for the
+// default profile.
+func (b *backend) ProfileName() string {
+	return ""
+}
+
+// ProfileNames returns the names of the profiles besides the default profile, in the
+// order that they were configured.
+func (b *backend) ProfileNames() []string {
+	return b.profileNames
+}
+
+func (p *profileBackend) XMRClient() monero.WalletClient {
+	return p.xmrClient
+}
+
+func (p *profileBackend) ETHClient() extethclient.EthClient {
+	return p.ethClient
+}
+
+func (p *profileBackend) ProfileName() string {
+	return p.name
+}
+
+func (p *profileBackend) NewTxSender(
+	asset ethcommon.Address,
+	erc20Contract *contracts.IERC20,
+) (txsender.Sender, error) {
+	return p.backend.newTxSender(p.ethClient, asset, erc20Contract)
+}
+
+func (p *profileBackend) NewSwapCreator(addr ethcommon.Address) (*contracts.SwapCreator, error) {
+	return contracts.NewSwapCreator(addr, p.ethClient.Raw())
+}
+
+// XMRDepositAddress returns the per-swap override deposit address, if a per-swap
+// address was set. Otherwise the primary address of the profile's wallet is returned.
+func (p *profileBackend) XMRDepositAddress(offerID *types.Hash) *mcrypto.Address {
+	if addr := p.backend.perSwapXMRDepositAddress(offerID); addr != nil {
+		return addr
+	}
+	return p.xmrClient.PrimaryAddress()
+}
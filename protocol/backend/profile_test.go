@@ -0,0 +1,40 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"", "alice", "market-maker_2", "0", strings.Repeat("a", 32)} {
+		require.NoError(t, ValidateProfileName(name), name)
+	}
+
+	for _, name := range []string{"Alice", "-alice", "_alice", "al ice", "../alice", strings.Repeat("a", 33)} {
+		require.Error(t, ValidateProfileName(name), name)
+	}
+}
+
+func TestBackend_WithProfile(t *testing.T) {
+	b := &backend{}
+	p := &profileBackend{backend: b, name: "alice"}
+	b.profiles = map[string]*profileBackend{"alice": p}
+	b.profileNames = []string{"alice"}
+
+	def, err := b.WithProfile("")
+	require.NoError(t, err)
+	require.Equal(t, "", def.ProfileName())
+
+	alice, err := b.WithProfile("alice")
+	require.NoError(t, err)
+	require.Equal(t, "alice", alice.ProfileName())
+	require.Equal(t, []string{"alice"}, alice.ProfileNames())
+
+	_, err = b.WithProfile("bob")
+	require.ErrorIs(t, err, ErrUnknownProfile)
+}
@@ -13,6 +13,7 @@ import (
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/monero"
 
+	"github.com/MarinX/monerorpc/wallet"
 	logging "github.com/ipfs/go-log"
 )
 
@@ -34,7 +35,9 @@ func GetClaimKeypair(
 }
 
 // ClaimMonero claims the XMR located in the wallet controlled by the private keypair `kpAB`.
-// If noTransferBack is unset, it sweeps the XMR to `depositAddr`.
+// If noTransferBack is unset, it sweeps the XMR to `depositAddr`, or to a new subaddress
+// of our wallet for the swap if `depositAddr` is our primary address, paying the fee of
+// the fee priority.
 func ClaimMonero(
 	ctx context.Context,
 	env common.Environment,
@@ -43,6 +46,7 @@ func ClaimMonero(
 	walletScanHeight uint64,
 	kpAB *mcrypto.PrivateKeyPair,
 	depositAddr *mcrypto.Address,
+	priority wallet.Priority,
 	noTransferBack bool,
 ) error {
 	conf := xmrClient.CreateWalletConf(fmt.Sprintf("swap-wallet-claim-%s", id))
@@ -59,6 +63,10 @@ func ClaimMonero(
 	}
 	defer abWalletCli.CloseAndRemoveWallet()
 
+	if depositAddr.Equal(xmrClient.PrimaryAddress()) {
+		depositAddr = swapSubaddress(xmrClient, id, depositAddr)
+	}
+
 	log.Infof("monero claimed in account %s; transferring to deposit account %s",
 		address, depositAddr)
 
@@ -72,7 +80,7 @@ func ClaimMonero(
 		return err
 	}
 
-	transfers, err := abWalletCli.SweepAll(ctx, depositAddr, 0, monero.SweepToSelfConfirmations)
+	transfers, err := abWalletCli.SweepAll(ctx, depositAddr, 0, monero.SweepToSelfConfirmations, priority)
 	if err != nil {
 		return fmt.Errorf("failed to send funds to deposit account: %w", err)
 	}
@@ -87,3 +95,17 @@ func ClaimMonero(
 
 	return nil
 }
+
+// swapSubaddress returns a new subaddress of our wallet labeled with the offer ID, so
+// that the swept XMR of each swap can be told apart in the wallet history and is not
+// linked to our primary address on chain. It returns the primary address if the
+// subaddress can't be created, as the XMR must be swept regardless.
+func swapSubaddress(xmrClient monero.WalletClient, id types.Hash, primaryAddr *mcrypto.Address) *mcrypto.Address {
+	subaddr, err := xmrClient.CreateSubaddress(fmt.Sprintf("swap-%s", id))
+	if err != nil {
+		log.Warnf("failed to create the subaddress of swap %s, using the primary address: %s", id, err)
+		return primaryAddr
+	}
+
+	return subaddr
+}
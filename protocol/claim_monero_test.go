@@ -13,6 +13,7 @@ import (
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/monero"
 
+	"github.com/MarinX/monerorpc/wallet"
 	logging "github.com/ipfs/go-log"
 	"github.com/stretchr/testify/require"
 )
@@ -52,6 +53,7 @@ func TestClaimMonero_NoTransferBack(t *testing.T) {
 		height,
 		kp,
 		nil, // deposit address can be nil, as noTransferBack is true
+		wallet.PriorityDefault,
 		true,
 	)
 	require.NoError(t, err)
@@ -92,6 +94,7 @@ func TestClaimMonero_WithTransferBack(t *testing.T) {
 		height,
 		kp,
 		depositAddr,
+		wallet.PriorityDefault,
 		false,
 	)
 	require.NoError(t, err)
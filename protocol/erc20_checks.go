@@ -0,0 +1,125 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+)
+
+// uncheckedERC20Expiry is how long a token whose transfer simulation could not be
+// performed is accepted without being checked again. The outcome of a performed
+// simulation is kept until the daemon exits.
+const uncheckedERC20Expiry = time.Hour
+
+// erc20Checks caches the outcome of the token checks of CheckEthAssetSupported, so
+// that the search for a holder of the token is not repeated for every offer.
+var erc20Checks = newERC20CheckCache()
+
+type erc20CheckResult struct {
+	err     error
+	expires time.Time // zero if the result does not expire
+}
+
+type erc20CheckCache struct {
+	mu      sync.Mutex
+	results map[ethcommon.Address]*erc20CheckResult
+}
+
+func newERC20CheckCache() *erc20CheckCache {
+	return &erc20CheckCache{
+		results: make(map[ethcommon.Address]*erc20CheckResult),
+	}
+}
+
+// get returns the cached outcome of the token's check, or nil if there is no
+// outcome that has not expired at the given time.
+func (c *erc20CheckCache) get(token ethcommon.Address, now time.Time) *erc20CheckResult {
+	result, ok := c.results[token]
+	if !ok || (!result.expires.IsZero() && !now.Before(result.expires)) {
+		return nil
+	}
+
+	return result
+}
+
+func (c *erc20CheckCache) put(token ethcommon.Address, err error, expires time.Time) {
+	c.results[token] = &erc20CheckResult{err: err, expires: expires}
+}
+
+// CheckEthAssetSupported returns an error if the asset is an ERC-20 token that the
+// SwapCreator contract can't safely hold. It is checked before making or taking an
+// offer, as the problem otherwise only shows when a claim or refund reverts.
+//
+// Tokens whose transfer and approve methods don't return a bool are refused, as the
+// contract requires the return value. Besides the return value check, a transfer from
+// a current holder of the token is simulated to detect fee-on-transfer and rebasing
+// tokens. With those, the contract ends up holding less than the swap value and the
+// claim fails. If the simulation can't be performed, because no holder was found or
+// the endpoint does not support eth_call state overrides, a warning is logged and the
+// token is accepted. The outcome is cached for each token.
+func CheckEthAssetSupported(ctx context.Context, ec extethclient.EthClient, asset types.EthAsset) error {
+	if asset.IsETH() {
+		return nil
+	}
+
+	// the lock is held during the check, so concurrent offers in the same token
+	// don't search for a holder in parallel
+	erc20Checks.mu.Lock()
+	defer erc20Checks.mu.Unlock()
+
+	if result := erc20Checks.get(asset.Address(), time.Now()); result != nil {
+		return result.err
+	}
+
+	ok, err := contracts.ERC20ReturnsBool(ctx, ec.Raw(), asset.Address(), ec.Address())
+	if err != nil {
+		return fmt.Errorf("failed to check token %s: %w", asset, err)
+	}
+
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrNonStandardERC20, asset)
+		erc20Checks.put(asset.Address(), err, time.Time{})
+		return err
+	}
+
+	checked, err := checkERC20Transfer(ctx, ec, asset)
+	if !checked {
+		erc20Checks.put(asset.Address(), nil, time.Now().Add(uncheckedERC20Expiry))
+		return nil
+	}
+
+	erc20Checks.put(asset.Address(), err, time.Time{})
+	return err
+}
+
+// checkERC20Transfer simulates a transfer of the token, returning whether the
+// simulation could be performed.
+func checkERC20Transfer(ctx context.Context, ec extethclient.EthClient, asset types.EthAsset) (bool, error) {
+	holder, amount, err := contracts.FindERC20Holder(ctx, ec.Raw(), asset.Address(), ec.Address())
+	if err != nil {
+		log.Warnf("unable to check token %s for transfer fees: %s", asset, err)
+		return false, nil
+	}
+
+	received, err := contracts.ERC20ReceivedAmount(ctx, ec.Raw(), asset.Address(), holder, amount)
+	if err != nil {
+		log.Warnf("unable to check token %s for transfer fees: %s", asset, err)
+		return false, nil
+	}
+
+	if received.Cmp(amount) != 0 {
+		return true, fmt.Errorf("%w: %s (sent %s, received %s)", ErrFeeOnTransferERC20, asset, amount, received)
+	}
+
+	return true, nil
+}
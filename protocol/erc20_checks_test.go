@@ -0,0 +1,42 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestERC20CheckCache(t *testing.T) {
+	cache := newERC20CheckCache()
+	now := time.Now()
+
+	checkedToken := ethcommon.Address{0x1}
+	feeToken := ethcommon.Address{0x2}
+	uncheckedToken := ethcommon.Address{0x3}
+
+	require.Nil(t, cache.get(checkedToken, now))
+
+	cache.put(checkedToken, nil, time.Time{})
+	cache.put(feeToken, ErrFeeOnTransferERC20, time.Time{})
+	cache.put(uncheckedToken, nil, now.Add(uncheckedERC20Expiry))
+
+	// the outcome of performed checks doesn't expire
+	later := now.Add(24 * time.Hour)
+	result := cache.get(checkedToken, later)
+	require.NotNil(t, result)
+	require.NoError(t, result.err)
+	result = cache.get(feeToken, later)
+	require.NotNil(t, result)
+	require.ErrorIs(t, result.err, ErrFeeOnTransferERC20)
+
+	// unchecked tokens are checked again once the outcome expires
+	result = cache.get(uncheckedToken, now.Add(uncheckedERC20Expiry-time.Second))
+	require.NotNil(t, result)
+	require.NoError(t, result.err)
+	require.Nil(t, cache.get(uncheckedToken, now.Add(uncheckedERC20Expiry)))
+}
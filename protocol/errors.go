@@ -11,8 +11,26 @@ var (
 	// ErrLogNotForUs is returned when a log is found that doesn't have the given contract swap ID.
 	ErrLogNotForUs = errors.New("found log that isn't for our swap")
 
-	errLogMissingParams    = errors.New("log didn't have enough topics")
-	errInvalidEventTopic   = errors.New("log did not have correct event as first topic")
-	errInvalidSecp256k1Key = errors.New("secp256k1 public key resulting from proof verification does not match key sent")
-	errInvalidEd25519Key   = errors.New("ed25519 public key resulting from proof verification does not match key sent")
+	// ErrNonStandardERC20 is returned for tokens whose transfer or approve methods
+	// don't return the bool value required by the ERC-20 standard.
+	ErrNonStandardERC20 = errors.New("token does not return a bool from transfer and approve, which is unsupported")
+
+	// ErrFeeOnTransferERC20 is returned for tokens where the amount received in a
+	// transfer differs from the amount sent, like fee-on-transfer and rebasing tokens.
+	ErrFeeOnTransferERC20 = errors.New("token transfers deliver less than the amount sent, which is unsupported")
+
+	// ErrProtocolAlreadyInProgress is returned when initiating a swap of an offer
+	// whose swap is already ongoing.
+	ErrProtocolAlreadyInProgress = errors.New("protocol already in progress")
+
+	// ErrInsufficientBalance is matched by the errors of swaps that can't be started
+	// because our balance is below the amount we would provide.
+	ErrInsufficientBalance = errors.New("insufficient balance")
+
+	errLogMissingParams       = errors.New("log didn't have enough topics")
+	errInvalidEventTopic      = errors.New("log did not have correct event as first topic")
+	errMissingEventSecret     = errors.New("contract event is missing its secret")
+	errCompletedWithoutSecret = errors.New("completed swap has no claim or refund log")
+	errInvalidSecp256k1Key    = errors.New("secp256k1 public key resulting from proof verification does not match key sent")
+	errInvalidEd25519Key      = errors.New("ed25519 public key resulting from proof verification does not match key sent")
 )
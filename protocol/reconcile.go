@@ -0,0 +1,141 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+)
+
+var (
+	claimedTopic  = common.GetTopic(common.ClaimedEventSignature)
+	refundedTopic = common.GetTopic(common.RefundedEventSignature)
+)
+
+// ContractSwapState is the state of a swap in the swap creator contract, used to
+// reconcile an ongoing swap found in the db on startup with what happened on-chain
+// while swapd was offline.
+type ContractSwapState struct {
+	// Stage is the contract stage of the swap, one of contracts.StageInvalid,
+	// StagePending, StageReady or StageCompleted.
+	Stage byte
+	// ClaimSecret is the secret revealed by the claim of a completed swap.
+	ClaimSecret *mcrypto.PrivateSpendKey
+	// RefundSecret is the secret revealed by the refund of a completed swap.
+	RefundSecret *mcrypto.PrivateSpendKey
+}
+
+// GetContractSwapState returns the contract stage of the swap and, if the swap was
+// completed, the secret revealed by its claim or refund. The secret comes from the
+// indexed contract events, or from the swap creator's logs since the swap started
+// when the events aren't indexed yet.
+func GetContractSwapState(b backend.Backend, ethSwapInfo *db.EthereumSwapInfo) (*ContractSwapState, error) {
+	contract, err := b.NewSwapCreator(ethSwapInfo.SwapCreatorAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	stage, err := contract.Swaps(b.ETHClient().CallOpts(b.Ctx()), ethSwapInfo.SwapID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract stage of swap %s: %w", ethSwapInfo.SwapID, err)
+	}
+
+	state := &ContractSwapState{Stage: stage}
+	if stage != contracts.StageCompleted {
+		return state, nil
+	}
+
+	events, err := b.ContractEvents(ethSwapInfo.SwapID)
+	if err != nil {
+		return nil, err
+	}
+
+	state.ClaimSecret, err = GetSecretFromContractEvents(events, db.ContractEventClaimed)
+	if err != nil {
+		return nil, err
+	}
+
+	state.RefundSecret, err = GetSecretFromContractEvents(events, db.ContractEventRefunded)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.ClaimSecret != nil || state.RefundSecret != nil {
+		return state, nil
+	}
+
+	// the indexer only indexes confirmed blocks, so it can lag behind the stage
+	filterQuery := ethereum.FilterQuery{
+		FromBlock: ethSwapInfo.StartNumber,
+		Addresses: []ethcommon.Address{ethSwapInfo.SwapCreatorAddr},
+		Topics:    [][]ethcommon.Hash{{claimedTopic, refundedTopic}, {ethSwapInfo.SwapID}},
+	}
+
+	logs, err := b.ETHClient().Raw().FilterLogs(b.Ctx(), filterQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs of swap %s: %w", ethSwapInfo.SwapID, err)
+	}
+
+	state.ClaimSecret, state.RefundSecret, err = getSecretsFromLogs(logs, ethSwapInfo.SwapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.ClaimSecret == nil && state.RefundSecret == nil {
+		return nil, fmt.Errorf("%w: contract swap ID: %s", errCompletedWithoutSecret, ethSwapInfo.SwapID)
+	}
+
+	return state, nil
+}
+
+// getSecretsFromLogs returns the secrets revealed by the Claimed or Refunded logs
+// of the given swap. Removed logs are skipped.
+func getSecretsFromLogs(
+	logs []ethtypes.Log,
+	swapID types.Hash,
+) (claimSecret *mcrypto.PrivateSpendKey, refundSecret *mcrypto.PrivateSpendKey, err error) {
+	for _, l := range logs {
+		l := l
+		if l.Removed || len(l.Topics) == 0 {
+			continue
+		}
+
+		topic := l.Topics[0]
+		if topic != claimedTopic && topic != refundedTopic {
+			continue
+		}
+
+		err = CheckSwapID(&l, topic, swapID)
+		if errors.Is(err, ErrLogNotForUs) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		secret, err := contracts.GetSecretFromLog(&l, topic)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get secret from log: %w", err)
+		}
+
+		if topic == claimedTopic {
+			claimSecret = secret
+		} else {
+			refundSecret = secret
+		}
+	}
+
+	return claimSecret, refundSecret, nil
+}
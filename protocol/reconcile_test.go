@@ -0,0 +1,64 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+func TestGetSecretsFromLogs(t *testing.T) {
+	claimKeys, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	refundKeys, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	swapID := types.Hash{0x1}
+	otherSwapID := types.Hash{0x2}
+	readyTopic := common.GetTopic(common.ReadyEventSignature)
+
+	// the contract logs the secret in big-endian order
+	claimSecret := ethcommon.Hash(common.Reverse(claimKeys.SpendKey().Bytes()))
+	refundSecret := ethcommon.Hash(common.Reverse(refundKeys.SpendKey().Bytes()))
+
+	logs := []ethtypes.Log{
+		{Topics: []ethcommon.Hash{readyTopic, swapID}},
+		{Topics: []ethcommon.Hash{claimedTopic, otherSwapID, refundSecret}},
+		{Topics: []ethcommon.Hash{refundedTopic, swapID, refundSecret}, Removed: true},
+	}
+
+	claimSk, refundSk, err := getSecretsFromLogs(logs, swapID)
+	require.NoError(t, err)
+	require.Nil(t, claimSk)
+	require.Nil(t, refundSk)
+
+	claimSk, refundSk, err = getSecretsFromLogs(
+		append(logs, ethtypes.Log{Topics: []ethcommon.Hash{claimedTopic, swapID, claimSecret}}),
+		swapID,
+	)
+	require.NoError(t, err)
+	require.Equal(t, claimKeys.SpendKey().Hex(), claimSk.Hex())
+	require.Nil(t, refundSk)
+
+	claimSk, refundSk, err = getSecretsFromLogs(
+		append(logs, ethtypes.Log{Topics: []ethcommon.Hash{refundedTopic, swapID, refundSecret}}),
+		swapID,
+	)
+	require.NoError(t, err)
+	require.Nil(t, claimSk)
+	require.Equal(t, refundKeys.SpendKey().Hex(), refundSk.Hex())
+
+	_, _, err = getSecretsFromLogs(
+		[]ethtypes.Log{{Topics: []ethcommon.Hash{claimedTopic, swapID, {}}}},
+		swapID,
+	)
+	require.ErrorContains(t, err, "got zero secret key from contract")
+}
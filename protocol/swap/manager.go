@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/metrics"
 
 	"github.com/ChainSafe/chaindb"
 )
 
-var errNoSwapWithID = errors.New("unable to find swap with given ID")
+// ErrNoSwapWithID is returned when there is no swap with the given offer ID.
+var ErrNoSwapWithID = errors.New("unable to find swap with given ID")
 
 // Manager tracks current and past swaps.
 type Manager interface {
@@ -28,6 +30,33 @@ type Manager interface {
 	CompleteOngoingSwap(info *Info) error
 }
 
+// EventListener is notified of the lifecycle events of swaps. It is called with the
+// manager's lock held, so it must not block or call the manager.
+type EventListener interface {
+	// SwapStarted is called when an ongoing swap is added.
+	SwapStarted(info *Info)
+	// SwapStatusChanged is called when an ongoing swap is written with a new
+	// status, and when it completes.
+	SwapStatusChanged(info *Info)
+}
+
+// EventListeners is an EventListener notifying each of its listeners in turn.
+type EventListeners []EventListener
+
+// SwapStarted implements EventListener.
+func (ls EventListeners) SwapStarted(info *Info) {
+	for _, l := range ls {
+		l.SwapStarted(info)
+	}
+}
+
+// SwapStatusChanged implements EventListener.
+func (ls EventListeners) SwapStatusChanged(info *Info) {
+	for _, l := range ls {
+		l.SwapStatusChanged(info)
+	}
+}
+
 // manager implements Manager.
 // Note that ongoing swaps are fully populated, but past swaps
 // are only stored in memory if they've completed during
@@ -35,8 +64,13 @@ type Manager interface {
 type manager struct {
 	db Database
 	sync.RWMutex
-	ongoing map[types.Hash]*Info
-	past    map[types.Hash]*Info
+	ongoing  map[types.Hash]*Info
+	past     map[types.Hash]*Info
+	listener EventListener // optional
+
+	// notified holds the last status of each ongoing swap that the listener was
+	// notified of
+	notified map[types.Hash]Status
 }
 
 var _ Manager = (*manager)(nil)
@@ -44,8 +78,10 @@ var _ Manager = (*manager)(nil)
 // NewManager returns a new Manager that uses the given database.
 // It loads all ongoing swaps into memory on construction.
 // Completed swaps are not loaded into memory.
-func NewManager(db Database) (Manager, error) {
+// The listener, if not nil, is notified of the lifecycle events of swaps.
+func NewManager(db Database, listener EventListener) (Manager, error) {
 	ongoing := make(map[types.Hash]*Info)
+	notified := make(map[types.Hash]Status)
 
 	stored, err := db.GetAllSwaps()
 	if err != nil {
@@ -58,12 +94,15 @@ func NewManager(db Database) (Manager, error) {
 		}
 
 		ongoing[s.OfferID] = s
+		notified[s.OfferID] = s.Status
 	}
 
 	return &manager{
-		db:      db,
-		ongoing: ongoing,
-		past:    make(map[types.Hash]*Info),
+		db:       db,
+		ongoing:  ongoing,
+		past:     make(map[types.Hash]*Info),
+		listener: listener,
+		notified: notified,
 	}, nil
 }
 
@@ -75,6 +114,11 @@ func (m *manager) AddSwap(info *Info) error {
 	switch info.Status.IsOngoing() {
 	case true:
 		m.ongoing[info.OfferID] = info
+		metrics.SwapStarted(info.Provides)
+		m.notified[info.OfferID] = info.Status
+		if m.listener != nil {
+			m.listener.SwapStarted(info)
+		}
 	default:
 		m.past[info.OfferID] = info
 	}
@@ -82,8 +126,19 @@ func (m *manager) AddSwap(info *Info) error {
 	return m.db.PutSwap(info)
 }
 
-// WriteSwapToDB writes the swap to the database.
+// WriteSwapToDB writes the swap to the database. The swaps are written after each
+// status change, so the listener is notified of the status if it is new.
 func (m *manager) WriteSwapToDB(info *Info) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if last, ok := m.notified[info.OfferID]; ok && last != info.Status {
+		m.notified[info.OfferID] = info.Status
+		if m.listener != nil {
+			m.listener.SwapStatusChanged(info)
+		}
+	}
+
 	return m.db.PutSwap(info)
 }
 
@@ -145,7 +200,7 @@ func (m *manager) GetOngoingSwap(id types.Hash) (Info, error) {
 	defer m.RUnlock()
 	s, has := m.ongoing[id]
 	if !has {
-		return Info{}, errNoSwapWithID
+		return Info{}, ErrNoSwapWithID
 	}
 
 	return *s, nil
@@ -172,7 +227,7 @@ func (m *manager) CompleteOngoingSwap(info *Info) error {
 	defer m.Unlock()
 	_, has := m.ongoing[info.OfferID]
 	if !has {
-		return errNoSwapWithID
+		return ErrNoSwapWithID
 	}
 
 	now := time.Now()
@@ -180,6 +235,11 @@ func (m *manager) CompleteOngoingSwap(info *Info) error {
 
 	m.past[info.OfferID] = info
 	delete(m.ongoing, info.OfferID)
+	delete(m.notified, info.OfferID)
+	metrics.SwapEnded(info.Provides, info.Status)
+	if m.listener != nil {
+		m.listener.SwapStatusChanged(info)
+	}
 
 	// re-write to db, as status has changed
 	return m.db.PutSwap(info)
@@ -188,7 +248,7 @@ func (m *manager) CompleteOngoingSwap(info *Info) error {
 func (m *manager) getSwapFromDB(id types.Hash) (*Info, error) {
 	s, err := m.db.GetSwap(id)
 	if errors.Is(chaindb.ErrKeyNotFound, err) {
-		return nil, errNoSwapWithID
+		return nil, ErrNoSwapWithID
 	}
 	if err != nil {
 		return nil, err
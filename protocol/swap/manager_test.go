@@ -23,7 +23,7 @@ func TestNewManager(t *testing.T) {
 
 	db.EXPECT().GetAllSwaps()
 
-	mgr, err := NewManager(db)
+	mgr, err := NewManager(db, nil)
 	require.NoError(t, err)
 	m := mgr.(*manager)
 
@@ -61,7 +61,7 @@ func TestNewManager(t *testing.T) {
 	require.NoError(t, err)
 
 	db.EXPECT().GetAllSwaps().Return([]*Info{infoA, infoB}, nil)
-	mgr, err = NewManager(db)
+	mgr, err = NewManager(db, nil)
 	require.NoError(t, err)
 	m = mgr.(*manager)
 	require.Equal(t, 1, len(m.ongoing))
@@ -75,7 +75,7 @@ func TestManager_AddSwap_Ongoing(t *testing.T) {
 
 	db.EXPECT().GetAllSwaps()
 
-	mgr, err := NewManager(db)
+	mgr, err := NewManager(db, nil)
 	m := mgr.(*manager)
 	require.NoError(t, err)
 	info := NewInfo(
@@ -124,7 +124,7 @@ func TestManager_AddSwap_Past(t *testing.T) {
 
 	db.EXPECT().GetAllSwaps()
 
-	m, err := NewManager(db)
+	m, err := NewManager(db, nil)
 	require.NoError(t, err)
 
 	info := &Info{
@@ -167,3 +167,59 @@ func TestManager_AddSwap_Past(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, len(ids))
 }
+
+type recordingListener struct {
+	events []string
+}
+
+func (l *recordingListener) SwapStarted(info *Info) {
+	l.events = append(l.events, "started:"+info.Status.String())
+}
+
+func (l *recordingListener) SwapStatusChanged(info *Info) {
+	l.events = append(l.events, info.Status.String())
+}
+
+func TestManager_EventListener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllSwaps()
+
+	listener := new(recordingListener)
+	m, err := NewManager(db, listener)
+	require.NoError(t, err)
+
+	info := &Info{
+		OfferID: types.Hash{1},
+		Status:  types.ExpectingKeys,
+	}
+
+	db.EXPECT().PutSwap(info).Times(4)
+	require.NoError(t, m.AddSwap(info))
+
+	// writes without a status change are not notified
+	require.NoError(t, m.WriteSwapToDB(info))
+	info.SetStatus(types.ETHLocked)
+	require.NoError(t, m.WriteSwapToDB(info))
+
+	info.SetStatus(types.CompletedRefund)
+	require.NoError(t, m.CompleteOngoingSwap(info))
+
+	require.Equal(t, []string{"started:ExpectingKeys", "ETHLocked", "Refunded"}, listener.events)
+}
+
+func TestEventListeners(t *testing.T) {
+	l1, l2 := new(recordingListener), new(recordingListener)
+	listeners := EventListeners{l1, l2}
+
+	info := &Info{Status: types.ExpectingKeys}
+	listeners.SwapStarted(info)
+	info.SetStatus(types.CompletedSuccess)
+	listeners.SwapStatusChanged(info)
+
+	expected := []string{"started:ExpectingKeys", "Success"}
+	require.Equal(t, expected, l1.events)
+	require.Equal(t, expected, l2.events)
+}
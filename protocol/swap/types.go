@@ -7,17 +7,26 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
+	"github.com/athanorlabs/atomic-swap/metrics"
 )
 
+// RefundBeforeT0Fraction is the fraction of the time between the start of a swap
+// and T0 that remains when the taker gives up waiting for the XMR to be locked and
+// refunds its ETH.
+const RefundBeforeT0Fraction = 0.15
+
 var (
 	// CurInfoVersion is the latest supported version of a serialised Info struct
 	CurInfoVersion, _ = semver.NewVersion("0.3.0")
@@ -29,6 +38,16 @@ type (
 	Status = types.Status //nolint:revive
 )
 
+// Stage is a stage of the protocol that a swap entered.
+type Stage struct {
+	Status    Status    `json:"status" validate:"required"`
+	EnteredAt time.Time `json:"enteredAt" validate:"required"`
+	// ETHTxHash and XMRTxID are our transactions that moved the swap to the stage,
+	// if any. The ETH transaction of a relayed claim is the relayer's.
+	ETHTxHash *ethcommon.Hash `json:"ethTxHash,omitempty"`
+	XMRTxID   string          `json:"xmrTxID,omitempty"`
+}
+
 // Info contains the details of the swap as well as its status.
 type Info struct {
 	Version        *semver.Version     `json:"version"`
@@ -61,8 +80,21 @@ type Info struct {
 	// (and after Timeout0), the ETH-taker is able to claim, but
 	// after this timeout, the ETH-taker can no longer claim, only
 	// the ETH-maker can refund.
-	Timeout1 *time.Time        `json:"timeout1,omitempty"`
+	Timeout1 *time.Time `json:"timeout1,omitempty"`
+	// GasPaid is the ETH paid for the gas of our transactions of the swap. It
+	// is not set if we sent no transactions, e.g. when our claim was relayed.
+	GasPaid *coins.WeiAmount `json:"gasPaid,omitempty"`
+	// Stages are the stages that the swap entered, from its first status to its
+	// current one. It is not set for swaps from before stages were recorded.
+	Stages []*Stage `json:"stages,omitempty"`
+	// Profile is the name of the profile whose wallet and account fund the swap,
+	// empty for the default profile.
+	Profile  string            `json:"profile,omitempty"`
 	statusCh chan types.Status `json:"-"`
+
+	// transactions that move the swap to its next stage
+	nextETHTxHash *ethcommon.Hash
+	nextXMRTxID   string
 }
 
 // NewInfo creates a new *Info from the given parameters.
@@ -93,6 +125,7 @@ func NewInfo(
 		statusCh:             statusCh,
 		StartTime:            time.Now(),
 	}
+	info.Stages = []*Stage{{Status: status, EnteredAt: info.StartTime}}
 	return info
 }
 
@@ -101,10 +134,83 @@ func (i *Info) StatusCh() chan types.Status {
 	return i.statusCh
 }
 
+// AddGasPaid adds the gas cost of our transaction with the receipt to GasPaid.
+func (i *Info) AddGasPaid(receipt *ethtypes.Receipt) {
+	if receipt.EffectiveGasPrice == nil {
+		return
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	if i.GasPaid != nil {
+		cost.Add(cost, i.GasPaid.BigInt())
+	}
+	i.GasPaid = coins.NewWeiAmount(cost)
+}
+
+// SetStageETHTx records the hash of the ETH transaction that moves the swap to its
+// next stage.
+func (i *Info) SetStageETHTx(hash ethcommon.Hash) {
+	i.nextETHTxHash = &hash
+}
+
+// SetStageXMRTx records the ID of the XMR transaction that moves the swap to its
+// next stage.
+func (i *Info) SetStageXMRTx(txID string) {
+	i.nextXMRTxID = txID
+}
+
 // SetStatus ...
 func (i *Info) SetStatus(s Status) {
+	metrics.SwapStageEnded(i.Status, i.LastStatusUpdateTime)
 	i.Status = s
 	i.LastStatusUpdateTime = time.Now()
+
+	i.Stages = append(i.Stages, &Stage{
+		Status:    s,
+		EnteredAt: i.LastStatusUpdateTime,
+		ETHTxHash: i.nextETHTxHash,
+		XMRTxID:   i.nextXMRTxID,
+	})
+	i.nextETHTxHash = nil
+	i.nextXMRTxID = ""
+}
+
+// RefundCausedByCounterparty returns whether the swap was refunded because the
+// counterparty failed to complete it, going by our role and the stage before the
+// refund:
+//   - as the maker, the taker refunded the ETH after we locked the XMR, instead of
+//     setting the contract to ready.
+//   - as the taker, the maker did not claim the ETH after we set the contract to
+//     ready, or did not lock the XMR by the time we gave up waiting for it near T0.
+//     An earlier refund of locked ETH was requested by us.
+//
+// It returns false for swaps that were not refunded, or whose stages were not
+// recorded.
+func (i *Info) RefundCausedByCounterparty() bool {
+	if i.Status != types.CompletedRefund || len(i.Stages) < 2 {
+		return false
+	}
+
+	refund := i.Stages[len(i.Stages)-1]
+	prevStatus := i.Stages[len(i.Stages)-2].Status
+
+	switch i.Provides {
+	case coins.ProvidesXMR:
+		return prevStatus == types.XMRLocked
+	case coins.ProvidesETH:
+		switch prevStatus {
+		case types.ContractReady:
+			return true
+		case types.ETHLocked:
+			if i.Timeout0 == nil || i.Timeout1 == nil {
+				return false
+			}
+			giveUpDelta := time.Duration(float64(i.Timeout1.Sub(*i.Timeout0)) * RefundBeforeT0Fraction)
+			return !refund.EnteredAt.Before(i.Timeout0.Add(-giveUpDelta))
+		}
+	}
+
+	return false
 }
 
 // UnmarshalInfo deserializes a JSON Info struct, checking the version for compatibility
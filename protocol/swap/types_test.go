@@ -5,10 +5,13 @@ package swap
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/require"
 
@@ -37,6 +40,7 @@ func Test_InfoMarshal(t *testing.T) {
 	err := info.StartTime.UnmarshalJSON([]byte("\"2023-02-20T17:29:43.471020297-05:00\""))
 	require.NoError(t, err)
 	info.LastStatusUpdateTime = info.StartTime
+	info.Stages[0].EnteredAt = info.StartTime
 
 	infoBytes, err := vjson.MarshalStruct(info)
 	require.NoError(t, err)
@@ -53,7 +57,13 @@ func Test_InfoMarshal(t *testing.T) {
 		"moneroStartHeight": 200,
 		"status": "Success",
 		"lastStatusUpdateTime": "2023-02-20T17:29:43.471020297-05:00",
-		"startTime": "2023-02-20T17:29:43.471020297-05:00"
+		"startTime": "2023-02-20T17:29:43.471020297-05:00",
+		"stages": [
+			{
+				"status": "Success",
+				"enteredAt": "2023-02-20T17:29:43.471020297-05:00"
+			}
+		]
 	}`
 	require.JSONEq(t, expectedJSON, string(infoBytes))
 }
@@ -72,3 +82,127 @@ func TestUnmarshalInfo_versionTooNew(t *testing.T) {
 	_, err := UnmarshalInfo([]byte(offerJSON))
 	require.ErrorContains(t, err, fmt.Sprintf("info version %q not supported", unsupportedVersion))
 }
+
+func TestInfo_AddGasPaid(t *testing.T) {
+	info := new(Info)
+	info.AddGasPaid(&ethtypes.Receipt{GasUsed: 21000, EffectiveGasPrice: big.NewInt(2)})
+	info.AddGasPaid(&ethtypes.Receipt{GasUsed: 50000, EffectiveGasPrice: big.NewInt(3)})
+	require.Equal(t, big.NewInt(192000), info.GasPaid.BigInt())
+
+	// receipts without a gas price don't change the gas paid
+	info.AddGasPaid(&ethtypes.Receipt{GasUsed: 50000})
+	require.Equal(t, big.NewInt(192000), info.GasPaid.BigInt())
+}
+
+func TestInfo_SetStatus_stages(t *testing.T) {
+	info := NewInfo(
+		testPeerID,
+		types.Hash{1},
+		coins.ProvidesETH,
+		apd.New(1, 0),
+		apd.New(10, 0),
+		coins.ToExchangeRate(apd.New(1, -1)), // 0.1
+		types.EthAssetETH,
+		types.ExpectingKeys,
+		100,
+		nil,
+	)
+	require.Len(t, info.Stages, 1)
+	require.Equal(t, types.ExpectingKeys, info.Stages[0].Status)
+	require.Equal(t, info.StartTime, info.Stages[0].EnteredAt)
+
+	txHash := ethcommon.Hash{2}
+	info.SetStageETHTx(txHash)
+	info.SetStatus(types.ETHLocked)
+	info.SetStageXMRTx("xmr-tx")
+	info.SetStatus(types.XMRLocked)
+	info.SetStatus(types.ContractReady)
+
+	require.Len(t, info.Stages, 4)
+	require.Equal(t, types.ETHLocked, info.Stages[1].Status)
+	require.Equal(t, &txHash, info.Stages[1].ETHTxHash)
+	require.Empty(t, info.Stages[1].XMRTxID)
+	require.Equal(t, info.LastStatusUpdateTime, info.Stages[3].EnteredAt)
+
+	// the transactions only belong to the stage that they moved the swap to
+	require.Nil(t, info.Stages[2].ETHTxHash)
+	require.Equal(t, "xmr-tx", info.Stages[2].XMRTxID)
+	require.Nil(t, info.Stages[3].ETHTxHash)
+	require.Empty(t, info.Stages[3].XMRTxID)
+}
+
+func TestInfo_RefundCausedByCounterparty(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	t0 := start.Add(time.Hour)
+	t1 := t0.Add(time.Hour)
+
+	newRefundedInfo := func(provides coins.ProvidesCoin, prevStatus types.Status, refundedAt time.Time) *Info {
+		return &Info{
+			Provides: provides,
+			Status:   types.CompletedRefund,
+			Timeout0: &t0,
+			Timeout1: &t1,
+			Stages: []*Stage{
+				{Status: types.ExpectingKeys, EnteredAt: start},
+				{Status: prevStatus, EnteredAt: start.Add(time.Minute)},
+				{Status: types.CompletedRefund, EnteredAt: refundedAt},
+			},
+		}
+	}
+
+	type testCase struct {
+		description string
+		info        *Info
+		expected    bool
+	}
+
+	testCases := []testCase{
+		{
+			description: "maker: the taker refunded after we locked the XMR",
+			info:        newRefundedInfo(coins.ProvidesXMR, types.XMRLocked, t0.Add(-time.Minute)),
+			expected:    true,
+		},
+		{
+			description: "taker: the maker did not claim after we set the contract to ready",
+			info:        newRefundedInfo(coins.ProvidesETH, types.ContractReady, t1.Add(time.Minute)),
+			expected:    true,
+		},
+		{
+			description: "taker: the maker did not lock the XMR before we gave up near T0",
+			info:        newRefundedInfo(coins.ProvidesETH, types.ETHLocked, t0.Add(-time.Minute)),
+			expected:    true,
+		},
+		{
+			description: "taker: we cancelled the swap long before T0",
+			info:        newRefundedInfo(coins.ProvidesETH, types.ETHLocked, start.Add(5*time.Minute)),
+			expected:    false,
+		},
+		{
+			description: "taker: refund without timeouts",
+			info: func() *Info {
+				info := newRefundedInfo(coins.ProvidesETH, types.ETHLocked, t0.Add(-time.Minute))
+				info.Timeout0, info.Timeout1 = nil, nil
+				return info
+			}(),
+			expected: false,
+		},
+		{
+			description: "completed swap",
+			info: func() *Info {
+				info := newRefundedInfo(coins.ProvidesXMR, types.XMRLocked, t0)
+				info.Status = types.CompletedSuccess
+				return info
+			}(),
+			expected: false,
+		},
+		{
+			description: "refund without recorded stages",
+			info:        &Info{Provides: coins.ProvidesXMR, Status: types.CompletedRefund},
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, tc.info.RefundCausedByCounterparty(), tc.description)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/metrics"
 )
 
 var (
@@ -93,25 +94,13 @@ func (s *privateKeySender) NewSwap(
 	// NewSwap which performs the transfer, needs to be inside the same wallet
 	// lock grab in case there are other simultaneous swaps happening with the
 	// same token.
+	var permitSig *contracts.SwapCreatorPermitSignature
 	if amount.IsToken() {
-		txOpts, err := s.ethClient.TxOpts(s.ctx)
+		var err error
+		permitSig, err = s.approve(amount)
 		if err != nil {
 			return nil, err
 		}
-
-		tx, err := s.erc20Contract.Approve(txOpts, s.swapCreatorAddr, value)
-		if err != nil {
-			return nil, fmt.Errorf("approve tx creation failed, %w", err)
-		}
-
-		receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
-		if err != nil {
-			return nil, fmt.Errorf("approve failed, %w", err)
-		}
-
-		log.Debugf("approve transaction included %s", common.ReceiptInfo(receipt))
-		log.Infof("%s %s approved for use by SwapCreator's new_swap",
-			amount.AsStandard().Text('f'), amount.StandardSymbol())
 	}
 
 	txOpts, err := s.ethClient.TxOpts(s.ctx)
@@ -124,8 +113,14 @@ func (s *privateKeySender) NewSwap(
 		txOpts.Value = value
 	}
 
-	tx, err := s.swapCreator.NewSwap(txOpts, pubKeyClaim, pubKeyRefund, claimer, timeoutDuration, timeoutDuration,
-		amount.TokenAddress(), value, nonce)
+	var tx *ethtypes.Transaction
+	if permitSig != nil {
+		tx, err = s.swapCreator.NewSwapWithPermit(txOpts, pubKeyClaim, pubKeyRefund, claimer, timeoutDuration,
+			timeoutDuration, amount.TokenAddress(), value, nonce, *permitSig)
+	} else {
+		tx, err = s.swapCreator.NewSwap(txOpts, pubKeyClaim, pubKeyRefund, claimer, timeoutDuration,
+			timeoutDuration, amount.TokenAddress(), value, nonce)
+	}
 	if err != nil {
 		err = fmt.Errorf("new_swap tx creation failed, %w", err)
 		return nil, err
@@ -137,6 +132,92 @@ func (s *privateKeySender) NewSwap(
 		return nil, err
 	}
 
+	metrics.GasSpent("new_swap", receipt)
+	return receipt, nil
+}
+
+// approve authorizes the SwapCreator contract to transfer the token amount. When
+// the token supports EIP-2612, a permit that expires after contracts.PermitValidity
+// is signed and returned, to be submitted with the new_swap transaction, so no
+// approve transaction is needed. If the deployed SwapCreator contract predates
+// newSwapWithPermit, or the permit can't be signed for any reason, an approve
+// transaction is sent instead. Nothing is needed if the existing allowance, for
+// example one set with personal_approveToken, suffices.
+func (s *privateKeySender) approve(amount coins.EthAssetAmount) (*contracts.SwapCreatorPermitSignature, error) {
+	value := amount.BigInt()
+
+	allowance, err := s.erc20Contract.Allowance(s.ethClient.CallOpts(s.ctx), s.ethClient.Address(), s.swapCreatorAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if allowance.Cmp(value) >= 0 {
+		log.Infof("existing allowance of SwapCreator covers %s %s",
+			amount.AsStandard().Text('f'), amount.StandardSymbol())
+		return nil, nil
+	}
+
+	hasPermitMethod, err := contracts.SwapCreatorHasMethod(s.ctx, s.ethClient.Raw(), s.swapCreatorAddr,
+		contracts.NewSwapWithPermitMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasPermitMethod {
+		permitSig, signErr := contracts.SignPermit(s.ctx, s.ethClient.Raw(), s.ethClient.Signer(),
+			amount.TokenAddress(), s.swapCreatorAddr, value)
+		if signErr == nil {
+			log.Infof("signed permit for SwapCreator's new_swap to transfer %s %s",
+				amount.AsStandard().Text('f'), amount.StandardSymbol())
+			return permitSig, nil
+		}
+
+		log.Debugf("unable to sign permit for %s, falling back to approve: %s", amount.StandardSymbol(), signErr)
+	} else {
+		log.Debugf("SwapCreator %s does not support permits, approving %s", s.swapCreatorAddr, amount.StandardSymbol())
+	}
+
+	receipt, err := s.approveWithTx(amount.TokenAddress(), value)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("approve transaction included %s", common.ReceiptInfo(receipt))
+	log.Infof("%s %s approved for use by SwapCreator's new_swap",
+		amount.AsStandard().Text('f'), amount.StandardSymbol())
+	return nil, nil
+}
+
+func (s *privateKeySender) approveWithTx(token ethcommon.Address, value *big.Int) (*ethtypes.Receipt, error) {
+	err := contracts.CheckERC20Call(
+		s.ctx,
+		s.ethClient.Raw(),
+		token,
+		s.ethClient.Address(),
+		"approve",
+		s.swapCreatorAddr,
+		value,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	txOpts, err := s.ethClient.TxOpts(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.erc20Contract.Approve(txOpts, s.swapCreatorAddr, value)
+	if err != nil {
+		return nil, fmt.Errorf("approve tx creation failed, %w", err)
+	}
+
+	receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("approve failed, %w", err)
+	}
+
+	metrics.GasSpent("approve", receipt)
 	return receipt, nil
 }
 
@@ -160,6 +241,7 @@ func (s *privateKeySender) SetReady(swap *contracts.SwapCreatorSwap) (*ethtypes.
 		return nil, err
 	}
 
+	metrics.GasSpent("set_ready", receipt)
 	return receipt, nil
 }
 
@@ -186,6 +268,7 @@ func (s *privateKeySender) Claim(
 		return nil, err
 	}
 
+	metrics.GasSpent("claim", receipt)
 	return receipt, nil
 }
 
@@ -212,5 +295,6 @@ func (s *privateKeySender) Refund(
 		return nil, err
 	}
 
+	metrics.GasSpent("refund", receipt)
 	return receipt, nil
 }
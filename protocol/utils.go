@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
@@ -45,3 +48,31 @@ func CheckSwapID(log *ethtypes.Log, eventNameTopic [32]byte, contractSwapID type
 
 	return nil
 }
+
+// GetSecretFromContractEvents returns the secret revealed by the first event of the
+// given type (Claimed or Refunded) in the indexed contract events of a swap. It
+// returns nil if there is no event of that type.
+func GetSecretFromContractEvents(
+	events []*db.ContractEvent,
+	eventType db.ContractEventType,
+) (*mcrypto.PrivateSpendKey, error) {
+	for _, event := range events {
+		if event.Type != eventType {
+			continue
+		}
+
+		if event.Secret == nil || *event.Secret == (types.Hash{}) {
+			return nil, errMissingEventSecret
+		}
+
+		return mcrypto.NewPrivateSpendKey(common.Reverse(event.Secret[:]))
+	}
+
+	return nil, nil
+}
+
+// XMRLockProofMessage returns the message that the proof of a swap's XMR lock
+// transaction signs, so that the proof can't be reused for another swap.
+func XMRLockProofMessage(offerID types.Hash) string {
+	return fmt.Sprintf("atomic-swap XMR lock %s", offerID)
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
+)
+
+func TestGetSecretFromContractEvents(t *testing.T) {
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	// the contract stores the secret in big-endian order
+	secret := types.Hash(common.Reverse(kp.SpendKey().Bytes()))
+
+	events := []*db.ContractEvent{
+		{Type: db.ContractEventNew},
+		{Type: db.ContractEventReady},
+		{Type: db.ContractEventClaimed, Secret: &secret},
+	}
+
+	sk, err := GetSecretFromContractEvents(events, db.ContractEventRefunded)
+	require.NoError(t, err)
+	require.Nil(t, sk)
+
+	sk, err = GetSecretFromContractEvents(events, db.ContractEventClaimed)
+	require.NoError(t, err)
+	require.Equal(t, kp.SpendKey().Hex(), sk.Hex())
+
+	events[2].Secret = nil
+	_, err = GetSecretFromContractEvents(events, db.ContractEventClaimed)
+	require.ErrorIs(t, err, errMissingEventSecret)
+}
@@ -4,35 +4,74 @@
 package xmrmaker
 
 import (
+	"fmt"
+
+	"github.com/cockroachdb/apd/v3"
+
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/monero"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 )
 
-// MakeOffer makes a new swap offer.
+// MakeOffer makes a new swap offer, funded from the named profile, or from the
+// default profile if the name is empty.
 func (inst *Instance) MakeOffer(
 	o *types.Offer,
 	useRelayer bool,
+	profile string,
 ) (*types.OfferExtra, error) {
+	b, err := inst.backend.WithProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// a view-only wallet can't lock the monero of a swap
+	if b.XMRClient().IsViewOnly() {
+		return nil, monero.ErrViewOnlyWallet
+	}
+
 	// get monero balance
-	balance, err := inst.backend.XMRClient().GetBalance(0)
+	balance, err := b.XMRClient().GetBalance(0)
 	if err != nil {
 		return nil, err
 	}
 
 	unlockedBalance := coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero()
 	if unlockedBalance.Cmp(o.MaxAmount) <= 0 {
-		return nil, errUnlockedBalanceTooLow{o.MaxAmount, unlockedBalance}
+		lockedBalance := coins.NewPiconeroAmount(balance.Balance - balance.UnlockedBalance).AsMonero()
+		return nil, errUnlockedBalanceTooLow{o.MaxAmount, unlockedBalance, lockedBalance, balance.BlocksToUnlock}
+	}
+
+	if err = checkLockInputs(b.XMRClient(), o.MaxAmount); err != nil {
+		return nil, err
 	}
 
+	// the relayer fees of token swaps are paid in the token, converted at our rate
 	if useRelayer && o.EthAsset.IsToken() {
-		return nil, errRelayingWithNonEthAsset
+		if _, ok := b.RelayerFee().TokenRates[o.EthAsset.Address()]; !ok {
+			return nil, fmt.Errorf("%w %s", errNoRelayerTokenFeeRate, o.EthAsset)
+		}
+	}
+
+	err = pcommon.CheckEthAssetSupported(inst.backend.Ctx(), inst.backend.ETHClient(), o.EthAsset)
+	if err != nil {
+		return nil, err
 	}
 
-	extra, err := inst.offerManager.AddOffer(o, useRelayer)
+	extra, err := inst.offerManager.AddOffer(o, useRelayer, profile)
 	if err != nil {
 		return nil, err
 	}
 
+	// the offers are reloaded from the database with the default profile, so the
+	// profile of the offer is stored with its extra data
+	if profile != "" {
+		if err = inst.backend.RecoveryDB().PutSwapRelayerInfo(o.ID, extra); err != nil {
+			return nil, err
+		}
+	}
+
 	inst.net.Advertise()
 	log.Infof("created new offer: %v", o)
 	return extra, nil
@@ -50,3 +89,30 @@ func (inst *Instance) ClearOffers(offerIDs []types.Hash) error {
 	}
 	return inst.offerManager.ClearOfferIDs(offerIDs)
 }
+
+// checkLockInputs checks that the lock of the amount doesn't spend so many outputs of
+// a fragmented wallet that the lock transaction fails, and warns if the outputs should
+// be consolidated before the lock, which otherwise pays a high fee.
+func checkLockInputs(xmrClient monero.WalletClient, amount *apd.Decimal) error {
+	plan, err := xmrClient.PlanConsolidation(coins.MoneroToPiconero(amount))
+	if err != nil {
+		return err
+	}
+
+	var sweepBelow *apd.Decimal
+	if plan.Recommended {
+		sweepBelow = coins.NewPiconeroAmount(plan.SweepBelow).AsMonero()
+	}
+
+	if plan.InputsNeeded > monero.MaxTransferInputs {
+		return errTooManyLockInputs{amount, plan.InputsNeeded, sweepBelow}
+	}
+
+	if plan.Recommended {
+		log.Warnf("Locking %s XMR would spend at least %d outputs, consider consolidating the %d outputs "+
+			"below %s XMR with `swapcli sweep-xmr --below-amount %s`",
+			amount, plan.InputsNeeded, plan.SweepOutputs, sweepBelow, sweepBelow)
+	}
+
+	return nil
+}
@@ -26,10 +26,23 @@ func (s *swapState) checkContract(txHash ethcommon.Hash) error {
 		return fmt.Errorf("failed to get newSwap transaction %s by hash: %w", txHash, err)
 	}
 
-	if tx.To() == nil || *(tx.To()) != s.swapCreatorAddr {
+	if tx.To() == nil {
 		return errInvalidETHLockedTransaction
 	}
 
+	// The token swap of an XMR taker without ETH is created by a relayer's
+	// transaction to the forwarder trusted by the swap creator contract
+	if *(tx.To()) != s.swapCreatorAddr {
+		forwarderAddr, forwarderErr := s.SwapCreator().TrustedForwarder(s.ETHClient().CallOpts(s.ctx))
+		if forwarderErr != nil {
+			return forwarderErr
+		}
+
+		if *(tx.To()) != forwarderAddr {
+			return errInvalidETHLockedTransaction
+		}
+	}
+
 	receipt, err := s.ETHClient().WaitForReceipt(s.ctx, txHash)
 	if err != nil {
 		return fmt.Errorf("failed to get receipt for New transaction: %w", err)
@@ -45,8 +58,13 @@ func (s *swapState) checkContract(txHash ethcommon.Hash) error {
 		return errCannotFindNewLog
 	}
 
+	// a relayed transaction also has logs of the token and forwarder contracts
 	var event *contracts.SwapCreatorNew
+	err = errCannotFindNewLog
 	for _, log := range receipt.Logs {
+		if log.Address != s.swapCreatorAddr {
+			continue
+		}
 		event, err = s.SwapCreator().ParseNew(*log)
 		if err == nil {
 			break
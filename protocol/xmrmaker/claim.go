@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -14,9 +15,13 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	"github.com/athanorlabs/atomic-swap/relayer"
@@ -42,14 +47,17 @@ func (s *swapState) claimFunds() (*ethtypes.Receipt, error) {
 	var receipt *ethtypes.Receipt
 
 	// call swap.Swap.Claim() w/ b.privkeys.sk, revealing XMRMaker's secret spend key
-	if s.offerExtra.UseRelayer || weiBalance.Decimal().IsZero() {
+	if s.offerExtra.UseRelayer || !s.canPayClaimGas(weiBalance) {
 		// relayer fee was set or we had insufficient funds to claim without a relayer
-		// TODO: Sufficient funds check above should be more specific
 		receipt, err = s.claimWithRelay()
 		if err != nil {
-			return nil, fmt.Errorf("failed to claim using relayers: %w", err)
+			receipt, err = s.claimDirectlyAfterRelayFailure(fmt.Errorf("failed to claim using relayers: %w", err))
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			log.Infof("claim transaction was relayed: %s", common.ReceiptInfo(receipt))
 		}
-		log.Infof("claim transaction was relayed: %s", common.ReceiptInfo(receipt))
 	} else {
 		// claim and wait for tx to be included
 		sc := s.getSecret()
@@ -58,6 +66,7 @@ func (s *swapState) claimFunds() (*ethtypes.Receipt, error) {
 			return nil, err
 		}
 		log.Infof("claim transaction %s", common.ReceiptInfo(receipt))
+		s.info.AddGasPaid(receipt)
 	}
 	if err != nil {
 		return nil, err
@@ -78,9 +87,99 @@ func (s *swapState) claimFunds() (*ethtypes.Receipt, error) {
 		log.Infof("balance after claim: %s %s", balance.AsStandardString(), balance.StandardSymbol())
 	}
 
+	s.info.SetStageETHTx(receipt.TxHash)
 	return receipt, nil
 }
 
+// claimDirectlyAfterRelayFailure claims the swap with our own transaction after
+// relaying the claim failed, if the direct claim fallback is enabled and our
+// balance covers the gas of the claim. Otherwise, the relay error is returned.
+func (s *swapState) claimDirectlyAfterRelayFailure(relayErr error) (*ethtypes.Receipt, error) {
+	if !s.Backend.DirectClaimFallback().Enabled {
+		return nil, relayErr
+	}
+
+	gasCost, err := s.estimateClaimGasCost()
+	if err != nil {
+		return nil, fmt.Errorf("%w, and estimating the gas of a direct claim failed: %s", relayErr, err)
+	}
+
+	balance, err := s.ETHClient().Balance(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance.BigInt().Cmp(gasCost) < 0 {
+		return nil, fmt.Errorf("%w, and balance %s ETH is under the %s ETH gas cost of a direct claim",
+			relayErr, balance.AsEtherString(), coins.FmtWeiAsETH(gasCost))
+	}
+
+	log.Warnf("%s, claiming directly at a gas cost of about %s ETH", relayErr, coins.FmtWeiAsETH(gasCost))
+
+	receipt, err := s.sender.Claim(s.contractSwap, s.getSecret())
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("claim transaction %s", common.ReceiptInfo(receipt))
+	s.info.AddGasPaid(receipt)
+
+	return receipt, nil
+}
+
+// canPayClaimGas returns whether our balance covers the gas cost of claiming the
+// swap with our own transaction. The claim is the only transaction of the XMR
+// maker, so a maker without enough ETH for it can swap by relaying the claim. If
+// the gas cost can't be estimated, the balance is assumed to not cover it.
+func (s *swapState) canPayClaimGas(balance *coins.WeiAmount) bool {
+	if balance.Decimal().IsZero() {
+		return false
+	}
+
+	gasCost, err := s.estimateClaimGasCost()
+	if err != nil {
+		log.Warnf("failed to estimate the gas of a direct claim, relaying the claim: %s", err)
+		return false
+	}
+
+	if balance.BigInt().Cmp(gasCost) < 0 {
+		log.Infof("balance %s ETH is under the %s ETH gas cost of a direct claim, relaying the claim",
+			balance.AsEtherString(), coins.FmtWeiAsETH(gasCost))
+		return false
+	}
+
+	return true
+}
+
+// estimateClaimGasCost returns the estimated gas cost in wei of claiming the swap
+// with our own transaction at the current gas price.
+func (s *swapState) estimateClaimGasCost() (*big.Int, error) {
+	swapCreatorABI, err := contracts.SwapCreatorMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	callData, err := swapCreatorABI.Pack("claim", *s.contractSwap, s.getSecret())
+	if err != nil {
+		return nil, err
+	}
+
+	gas, err := s.ETHClient().Raw().EstimateGas(s.ctx, ethereum.CallMsg{
+		From: s.ETHClient().Address(),
+		To:   &s.swapCreatorAddr,
+		Data: callData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice, err := s.ETHClient().SuggestGasPrice(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas)), nil
+}
+
 // relayClaimWithXMRTaker relays the claim to the swap's XMR taker, who should
 // process the claim even if they are not relaying claims for everyone.
 func (s *swapState) relayClaimWithXMRTaker(request *message.RelayClaimRequest) (*ethtypes.Receipt, error) {
@@ -110,54 +209,265 @@ func (s *swapState) relayClaimWithXMRTaker(request *message.RelayClaimRequest) (
 	return receipt, nil
 }
 
-// claimWithAdvertisedRelayers relays the claim to nodes that advertise
-// themselves as relayers in the DHT until the claim succeeds, all relayers have
-// been tried, or the context is cancelled.
-func (s *swapState) claimWithAdvertisedRelayers(request *message.RelayClaimRequest) (*ethtypes.Receipt, error) {
+// relayerRetryInterval is the time between rounds of relaying the claim with the
+// relayers advertising in the DHT, when every relayer of a round failed.
+const relayerRetryInterval = 30 * time.Second
+
+// relayerFailoverDeadline returns the time after which we stop failing over to
+// other DHT-advertised relayers, leaving the margin before the XMR taker can refund
+// at t1 for the XMR taker and direct claim fallbacks. A zero margin leaves the
+// last tenth of the claim window.
+func relayerFailoverDeadline(t0, t1 time.Time, margin time.Duration) time.Time {
+	if margin > 0 {
+		return t1.Add(-margin)
+	}
+	return t1.Add(-t1.Sub(t0) / 10)
+}
+
+// claimWithAdvertisedRelayers relays the claim with the relayers advertising in the
+// DHT, in rounds, until the claim is confirmed, the failover deadline passes, or the
+// context is cancelled. Each round asks the relayers for their fee, then relays the
+// claim to the relayers whose fee does not exceed the fee of the passed request, in
+// order of their fee relative to their score, failing over to the next relayer when
+// a relayer rejects the claim or does not get it confirmed in time. The passed
+// request is re-signed with each relayer's quoted fee, and with a fresh forwarder
+// nonce once a relayer has sent a transaction for it.
+func (s *swapState) claimWithAdvertisedRelayers(
+	forwarderAddr ethcommon.Address,
+	request *message.RelayClaimRequest,
+) (*ethtypes.Receipt, error) {
+	deadline := relayerFailoverDeadline(s.t0, s.t1, s.Backend.DirectClaimFallback().RelayMargin)
+
+	var (
+		// transactions sent by relayers that failed or were not confirmed in
+		// time, which could still be included
+		sentTxs []ethcommon.Hash
+		lastErr error
+	)
+
+	for round := 1; time.Now().Before(deadline); round++ {
+		if round > 1 {
+			log.Infof("retrying to relay claim in %s (round %d)", relayerRetryInterval, round)
+			if err := common.SleepWithContext(s.ctx, relayerRetryInterval); err != nil {
+				return nil, err
+			}
+		}
+
+		quotes, err := s.quoteAdvertisedRelayers(request.FeeWei)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		for _, q := range quotes {
+			if receipt := s.findConfirmedClaim(sentTxs); receipt != nil {
+				log.Infof("earlier relayed claim included and validated %s", common.ReceiptInfo(receipt))
+				return receipt, nil
+			}
+
+			if !time.Now().Before(deadline) {
+				break
+			}
+
+			// a relayer's transaction uses up the forwarder nonce of the request,
+			// even if the claim failed, so the next request is signed anew
+			fresh := len(sentTxs) > 0
+			relayerRequest, err := s.requestWithFee(forwarderAddr, request, q.quote.FeeWei, fresh)
+			if err != nil {
+				return nil, err
+			}
+
+			receipt, txHash, err := s.claimWithAdvertisedRelayer(q, relayerRequest)
+			if err == nil {
+				return receipt, nil
+			}
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			if txHash != nil {
+				sentTxs = append(sentTxs, *txHash)
+			}
+			lastErr = err
+		}
+	}
+
+	if receipt := s.findConfirmedClaim(sentTxs); receipt != nil {
+		log.Infof("earlier relayed claim included and validated %s", common.ReceiptInfo(receipt))
+		return receipt, nil
+	}
+
+	return nil, fmt.Errorf("failed to relay claim with any non-counterparty relayer before %s: %w",
+		deadline.Format(common.TimeFmtSecs), lastErr)
+}
+
+// quoteAdvertisedRelayers discovers the relayers advertising in the DHT, other
+// than our swap counterparty, and returns their quotes that don't exceed maxFee,
+// in the order they should be tried.
+func (s *swapState) quoteAdvertisedRelayers(maxFee *big.Int) ([]*relayerQuote, error) {
 	relayers, err := s.Backend.DiscoverRelayers()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(relayers) == 0 {
-		return nil, errors.New("no relayers found to submit claim to")
-	}
-	log.Debugf("Found %d relayers to submit claim to", len(relayers))
+	candidates := make([]peer.ID, 0, len(relayers))
 	for _, relayerPeerID := range relayers {
 		if relayerPeerID == s.info.PeerID {
 			log.Debugf("skipping DHT-advertised relayer that is our swap counterparty")
 			continue
 		}
-		log.Debugf("submitting claim to relayer with peer ID %s", relayerPeerID)
-		resp, err := s.Backend.SubmitClaimToRelayer(relayerPeerID, request)
+		candidates = append(candidates, relayerPeerID)
+	}
+
+	if len(candidates) == 0 {
+		return nil, errors.New("no relayers found to submit claim to")
+	}
+	log.Debugf("Found %d relayers to request fee quotes from", len(candidates))
+
+	quotes := s.queryRelayerQuotes(candidates, maxFee)
+	if len(quotes) == 0 {
+		return nil, fmt.Errorf("no relayer quoted a fee of at most %s",
+			relayer.FmtAssetFee(types.EthAsset(s.contractSwap.Asset), maxFee))
+	}
+
+	return quotes, nil
+}
+
+// claimWithAdvertisedRelayer submits the claim to the relayer and waits for the
+// relayer's transaction to claim the swap. If the relayer responded with a
+// transaction, its hash is returned along with any error.
+func (s *swapState) claimWithAdvertisedRelayer(
+	q *relayerQuote,
+	request *message.RelayClaimRequest,
+) (*ethtypes.Receipt, *ethcommon.Hash, error) {
+	log.Debugf("submitting claim to relayer with peer ID %s, fee %s and score %.2f",
+		q.peerID, relayer.FmtAssetFee(types.EthAsset(s.contractSwap.Asset), request.FeeWei), q.score)
+	resp, err := s.Backend.SubmitClaimToRelayer(q.peerID, request)
+	if err != nil {
+		log.Warnf("failed to submit tx to relayer: %s", err)
+		s.Backend.RecordRelayerOutcome(q.peerID, db.RelayerOutcomeFailed)
+		return nil, nil, err
+	}
+	s.Backend.RecordRelayerOutcome(q.peerID, db.RelayerOutcomeAccepted)
+
+	receipt, err := waitForClaimReceipt(
+		s.ctx,
+		s.ETHClient().Raw(),
+		resp.TxHash,
+		s.swapCreatorAddr,
+		s.contractSwapID,
+		s.getSecret(),
+	)
+	s.recordClaimReceiptOutcome(q.peerID, err)
+	if err != nil {
+		log.Warnf("failed to get receipt of relayer's tx: %s", err)
+		return nil, &resp.TxHash, err
+	}
+
+	log.Infof("DHT relayer's claim included and validated %s", common.ReceiptInfo(receipt))
+
+	return receipt, &resp.TxHash, nil
+}
+
+// findConfirmedClaim returns the receipt of the first of the transactions that
+// was included and claimed the swap, or nil if none did. Transactions that we
+// gave up waiting for can still be included while we fail over to other relayers.
+func (s *swapState) findConfirmedClaim(txHashes []ethcommon.Hash) *ethtypes.Receipt {
+	for _, txHash := range txHashes {
+		receipt, err := s.ETHClient().Raw().TransactionReceipt(s.ctx, txHash)
 		if err != nil {
-			log.Warnf("failed to submit tx to relayer: %s", err)
 			continue
 		}
 
-		receipt, err := waitForClaimReceipt(
-			s.ctx,
-			s.ETHClient().Raw(),
-			resp.TxHash,
-			s.swapCreatorAddr,
-			s.contractSwapID,
-			s.getSecret(),
-		)
-		if err != nil {
-			log.Warnf("failed to get receipt of relayer's tx: %s", err)
-			continue
+		if checkClaimReceipt(receipt, s.swapCreatorAddr, s.contractSwapID, s.getSecret()) == nil {
+			return receipt
 		}
+	}
 
-		log.Infof("DHT relayer's claim included and validated %s", common.ReceiptInfo(receipt))
+	return nil
+}
 
-		return receipt, nil
+// recordClaimReceiptOutcome records the outcome of waiting for the relayer's
+// claim transaction, given the error returned by waitForClaimReceipt.
+func (s *swapState) recordClaimReceiptOutcome(relayerPeerID peer.ID, err error) {
+	switch {
+	case err == nil:
+		s.Backend.RecordRelayerOutcome(relayerPeerID, db.RelayerOutcomeSubmitted)
+		s.Backend.RecordRelayerOutcome(relayerPeerID, db.RelayerOutcomeConfirmed)
+	case errors.Is(err, ethereum.NotFound):
+		s.Backend.RecordRelayerOutcome(relayerPeerID, db.RelayerOutcomeFailed)
+	case errors.Is(err, errRelayedTransactionTimeout):
+		s.Backend.RecordRelayerOutcome(relayerPeerID, db.RelayerOutcomeSubmitted)
+		s.Backend.RecordRelayerOutcome(relayerPeerID, db.RelayerOutcomeTimedOut)
+	case errors.Is(err, context.Canceled):
+		// we are shutting down, which says nothing about the relayer
+	default:
+		s.Backend.RecordRelayerOutcome(relayerPeerID, db.RelayerOutcomeSubmitted)
+		s.Backend.RecordRelayerOutcome(relayerPeerID, db.RelayerOutcomeFailed)
+	}
+}
+
+// requestWithFee returns the request if it already pays the fee, otherwise a copy
+// of the request signed with the fee. If fresh is set, the copy is always created,
+// with the current forwarder nonce.
+func (s *swapState) requestWithFee(
+	forwarderAddr ethcommon.Address,
+	request *message.RelayClaimRequest,
+	feeWei *big.Int,
+	fresh bool,
+) (*message.RelayClaimRequest, error) {
+	if !fresh && request.FeeWei.Cmp(feeWei) == 0 {
+		return request, nil
 	}
 
-	return nil, errors.New("failed to relay claim with any non-counterparty relayer")
+	secret := s.getSecret()
+
+	return relayer.CreateRelayClaimRequest(
+		s.ctx,
+		s.ETHClient().Signer(),
+		s.ETHClient().Raw(),
+		s.swapCreatorAddr,
+		forwarderAddr,
+		s.contractSwap,
+		&secret,
+		feeWei,
+	)
+}
+
+// claimWithUserOperation submits the relay claim request as an ERC-4337 user
+// operation through the configured bundler.
+func (s *swapState) claimWithUserOperation(
+	forwarderAddr ethcommon.Address,
+	request *message.RelayClaimRequest,
+) (*ethtypes.Receipt, error) {
+	opReceipt, err := relayer.ClaimWithUserOperation(
+		s.ctx,
+		s.Backend.UserOpAccount(),
+		s.ETHClient().Raw(),
+		forwarderAddr,
+		request,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the operation succeeds even if the forwarded claim failed, and the Claimed
+	// log is not the first log of the transaction
+	receipt := opReceipt.Receipt
+	for _, l := range receipt.Logs {
+		if checkClaimedLog(l, s.swapCreatorAddr, s.contractSwapID, s.getSecret()) == nil {
+			log.Infof("claim user operation %s included %s", opReceipt.UserOpHash, common.ReceiptInfo(receipt))
+			return receipt, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user operation %s did not claim the swap (tx=%s)", opReceipt.UserOpHash, receipt.TxHash)
 }
 
-// claimWithRelay first tries to relay sequentially with all relayers
-// advertising in the DHT that are not the XMR taker and, if that fails, falls
+// claimWithRelay first tries to submit the claim as an ERC-4337 user operation, if
+// a bundler is configured, then tries to relay sequentially with the relayers
+// advertising in the DHT that are not the XMR taker and quote a fee no higher
+// than our configured relayer fee, failing over between them until the swap's
+// timeout nears. If that fails, it falls
 // back to the XMR taker who, if using our software, will act as a relayer of
 // last resort for their own swap, even if they are not performing relay
 // operations more generally. Note that the receipt returned is for a
@@ -168,25 +478,47 @@ func (s *swapState) claimWithRelay() (*ethtypes.Receipt, error) {
 		return nil, err
 	}
 
+	// the fee of token swaps is paid in the token, so we can claim without ETH
+	fee, err := s.RelayerFee().AssetFee(s.ctx, s.ETHClient().Raw(),
+		types.EthAsset(s.contractSwap.Asset), s.contractSwap.Value)
+	if err != nil {
+		return nil, err
+	}
+
 	secret := s.getSecret()
 
 	request, err := relayer.CreateRelayClaimRequest(
 		s.ctx,
-		s.ETHClient().PrivateKey(),
+		s.ETHClient().Signer(),
 		s.ETHClient().Raw(),
 		s.swapCreatorAddr,
 		forwarderAddr,
 		s.contractSwap,
 		&secret,
+		fee,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	receipt, err := s.claimWithAdvertisedRelayers(request)
+	if s.Backend.UserOpAccount() != nil {
+		receipt, err := s.claimWithUserOperation(forwarderAddr, request) //nolint:govet
+		if err == nil {
+			return receipt, nil
+		}
+		log.Warnf("failed to claim with an ERC-4337 user operation: %s", err)
+	}
+
+	receipt, err := s.claimWithAdvertisedRelayers(forwarderAddr, request)
 	if err != nil {
 		log.Warnf("failed to relay with DHT-advertised relayers: %s", err)
 		log.Infof("falling back to swap counterparty as relayer")
+
+		// relayers that failed may have used up the request's forwarder nonce
+		request, err = s.requestWithFee(forwarderAddr, request, request.FeeWei, true)
+		if err != nil {
+			return nil, err
+		}
 		return s.relayClaimWithXMRTaker(request)
 	}
 	return receipt, nil
@@ -220,8 +552,8 @@ func waitForClaimReceipt(
 
 		_, isPending, err := ec.TransactionByHash(ctx, txHash)
 		if err != nil {
-			// allow up to 5 NotFound errors, in case there's some network problems
-			if errors.Is(err, ethereum.NotFound) && notFoundCount >= maxNotFound {
+			// allow up to maxNotFound NotFound errors, in case there's some network problems
+			if errors.Is(err, ethereum.NotFound) && notFoundCount < maxNotFound {
 				notFoundCount++
 				continue
 			}
@@ -250,17 +582,42 @@ func waitForClaimReceipt(
 		return nil, err
 	}
 
+	if err = checkClaimReceipt(receipt, contractAddr, contractSwapID, secret); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// checkClaimReceipt returns an error if the receipt is not of a successful
+// transaction with the Claimed log of the swap.
+func checkClaimReceipt(
+	receipt *ethtypes.Receipt,
+	contractAddr ethcommon.Address,
+	contractSwapID [32]byte,
+	secret [32]byte,
+) error {
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		return fmt.Errorf("relayer's claim transaction failed (tx=%s block=%d)",
+			receipt.TxHash, receipt.BlockNumber)
+	}
+
 	if len(receipt.Logs) == 0 {
-		return nil, fmt.Errorf("relayer's claim transaction had no logs (tx=%s block=%d)",
-			txHash, receipt.BlockNumber)
+		return fmt.Errorf("relayer's claim transaction had no logs (tx=%s block=%d)",
+			receipt.TxHash, receipt.BlockNumber)
 	}
 
-	if err = checkClaimedLog(receipt.Logs[0], contractAddr, contractSwapID, secret); err != nil {
-		return nil, fmt.Errorf("relayer's claim had logs error (tx=%s block=%d): %w",
-			txHash, receipt.BlockNumber, err)
+	// relayers can batch several claims in one transaction, so the Claimed log
+	// of our swap is not necessarily the first log
+	var err error
+	for _, l := range receipt.Logs {
+		if err = checkClaimedLog(l, contractAddr, contractSwapID, secret); err == nil {
+			return nil
+		}
 	}
 
-	return receipt, nil
+	return fmt.Errorf("relayer's claim had logs error (tx=%s block=%d): %w",
+		receipt.TxHash, receipt.BlockNumber, err)
 }
 
 func checkClaimedLog(log *ethtypes.Log, contractAddr ethcommon.Address, contractSwapID, secret [32]byte) error {
@@ -0,0 +1,49 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayerFailoverDeadline(t *testing.T) {
+	t0 := time.Now()
+	t1 := t0.Add(time.Hour)
+	require.Equal(t, t1.Add(-6*time.Minute), relayerFailoverDeadline(t0, t1, 0))
+	require.Equal(t, t1.Add(-15*time.Minute), relayerFailoverDeadline(t0, t1, 15*time.Minute))
+}
+
+func TestCheckClaimReceipt(t *testing.T) {
+	contractAddr := ethcommon.Address{0x1}
+	swapID := [32]byte{0x2}
+	secret := [32]byte{0x3}
+
+	otherClaim := &ethtypes.Log{
+		Address: contractAddr,
+		Topics:  []ethcommon.Hash{claimedTopic, {0x4}, {0x5}},
+	}
+	ourClaim := &ethtypes.Log{
+		Address: contractAddr,
+		Topics:  []ethcommon.Hash{claimedTopic, swapID, secret},
+	}
+
+	// our Claimed log doesn't have to come first in a batch transaction
+	receipt := &ethtypes.Receipt{
+		Status: ethtypes.ReceiptStatusSuccessful,
+		Logs:   []*ethtypes.Log{otherClaim, ourClaim},
+	}
+	require.NoError(t, checkClaimReceipt(receipt, contractAddr, swapID, secret))
+
+	receipt.Logs = []*ethtypes.Log{otherClaim}
+	require.ErrorIs(t, checkClaimReceipt(receipt, contractAddr, swapID, secret), errClaimedLogWrongSwapID)
+
+	receipt.Logs = []*ethtypes.Log{ourClaim}
+	receipt.Status = ethtypes.ReceiptStatusFailed
+	require.ErrorContains(t, checkClaimReceipt(receipt, contractAddr, swapID, secret), "transaction failed")
+}
@@ -8,6 +8,9 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 )
 
 var (
@@ -30,13 +33,15 @@ var (
 	errClaimedLogWrongEvent          = errors.New("log did not have the Claimed event as its first topic")
 	errClaimedLogWrongSwapID         = errors.New("log did not have the correct swap ID as its second topic")
 	errClaimedLogWrongSecret         = errors.New("log did not have the correct secret as its third topic")
-	errRelayingWithNonEthAsset       = errors.New("relayers with ERC20 token swaps are not currently supported")
+	errNoRelayerTokenFeeRate         = errors.New("relaying token swaps requires a relayer fee rate for the token")
 
 	// protocol initiation errors
-	errSwapDoesNotExist          = errors.New("contract swap ID does not exist")
-	errProtocolAlreadyInProgress = errors.New("protocol already in progress")
-	errOfferIDNotSet             = errors.New("offer ID was not set")
-	errInvalidStageForRecovery   = errors.New("cannot create ongoing swap state if stage is not XMRLocked")
+	errSwapDoesNotExist         = errors.New("contract swap ID does not exist")
+	errOfferIDNotSet            = errors.New("offer ID was not set")
+	errInvalidStageForRecovery  = errors.New("cannot create ongoing swap state if stage is not XMRLocked")
+	errPegRateNotSet            = errors.New("taker did not send the exchange rate of the pegged offer")
+	errNoPriceOracle            = errors.New("pegged offers are not supported without a price oracle")
+	errMarketMakerNoPriceOracle = errors.New("the market maker requires a price oracle")
 )
 
 type errBalanceTooLow struct {
@@ -51,6 +56,10 @@ func (e errBalanceTooLow) Error() string {
 	)
 }
 
+func (e errBalanceTooLow) Is(target error) bool {
+	return target == pcommon.ErrInsufficientBalance
+}
+
 type errAmountProvidedTooLow struct {
 	providedAmount *apd.Decimal
 	minAmount      *apd.Decimal
@@ -78,11 +87,74 @@ func (e errAmountProvidedTooHigh) Error() string {
 type errUnlockedBalanceTooLow struct {
 	maxOfferAmount  *apd.Decimal
 	unlockedBalance *apd.Decimal
+	lockedBalance   *apd.Decimal
+	blocksToUnlock  uint64
 }
 
 func (e errUnlockedBalanceTooLow) Error() string {
-	return fmt.Sprintf("balance %s XMR is too low for maximum offer amount of %s XMR",
+	msg := fmt.Sprintf("unlocked balance %s XMR is too low for maximum offer amount of %s XMR",
 		e.unlockedBalance.String(),
 		e.maxOfferAmount.String(),
 	)
+	if !e.lockedBalance.IsZero() {
+		msg += fmt.Sprintf(", %s XMR is still locked for up to %d blocks",
+			e.lockedBalance.String(),
+			e.blocksToUnlock,
+		)
+	}
+	return msg
+}
+
+type errTooManyLockInputs struct {
+	maxOfferAmount *apd.Decimal
+	inputsNeeded   int
+	sweepBelow     *apd.Decimal // nil if the outputs can't be consolidated below an amount
+}
+
+func (e errTooManyLockInputs) Error() string {
+	msg := fmt.Sprintf("locking the maximum offer amount of %s XMR would spend at least %d outputs, "+
+		"consolidate them first with personal_sweepXMR",
+		e.maxOfferAmount.String(),
+		e.inputsNeeded,
+	)
+	if e.sweepBelow != nil {
+		msg += fmt.Sprintf(" and a belowAmount of %s XMR", e.sweepBelow.String())
+	}
+	return msg
+}
+
+type errPegRateTooLow struct {
+	takerRate   *coins.ExchangeRate
+	currentRate *coins.ExchangeRate
+}
+
+func (e errPegRateTooLow) Error() string {
+	return fmt.Sprintf("exchange rate %s of the taker is below the current exchange rate %s of the pegged offer",
+		e.takerRate,
+		e.currentRate,
+	)
+}
+
+type errMarketMakerInventoryLow struct {
+	availableAmount *apd.Decimal
+	minAmount       *apd.Decimal
+}
+
+func (e errMarketMakerInventoryLow) Error() string {
+	return fmt.Sprintf("unlocked balance above the reserve of %s XMR is below the minimum offer amount of %s XMR",
+		e.availableAmount,
+		e.minAmount,
+	)
+}
+
+type errMarketMakerGasLow struct {
+	ethBalance    *apd.Decimal
+	minETHBalance *apd.Decimal
+}
+
+func (e errMarketMakerGasLow) Error() string {
+	return fmt.Sprintf("ETH balance of %s is below the minimum of %s ETH for gas",
+		e.ethBalance,
+		e.minETHBalance,
+	)
 }
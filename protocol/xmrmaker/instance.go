@@ -8,11 +8,13 @@ import (
 	"sync"
 
 	"github.com/MarinX/monerorpc/wallet"
+	"github.com/cockroachdb/apd/v3"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
@@ -39,6 +41,11 @@ type Instance struct {
 	net Host
 
 	offerManager *offers.Manager
+	priceOracle  *pricefeed.Oracle // nil if offers can't be pegged
+
+	// pegMaxDeviation is the percent by which takers' exchange rates of pegged
+	// offers can be below their current exchange rate
+	pegMaxDeviation *apd.Decimal
 
 	swapMu     sync.Mutex // synchronises access to swapStates
 	swapStates map[types.Hash]*swapState
@@ -52,11 +59,26 @@ type Config struct {
 	WalletFile, WalletPassword string
 	ExternalSender             bool
 	Network                    Host
+	PriceOracle                *pricefeed.Oracle  // resolves the exchange rates of pegged offers
+	MarketMaker                *MarketMakerConfig // optional, requires PriceOracle
+	PegMaxDeviation            *apd.Decimal       // percent, DefaultPegMaxDeviation if nil
 }
 
 // NewInstance returns a new *xmrmaker.Instance.
 // It accepts an endpoint to a monero-wallet-rpc instance where account 0 contains XMRMaker's XMR.
 func NewInstance(cfg *Config) (*Instance, error) {
+	if cfg.MarketMaker != nil && cfg.PriceOracle == nil {
+		return nil, errMarketMakerNoPriceOracle
+	}
+
+	pegMaxDeviation := cfg.PegMaxDeviation
+	if pegMaxDeviation == nil {
+		pegMaxDeviation = apd.New(DefaultPegMaxDeviation, 0)
+	}
+	if err := ValidatePegMaxDeviation(pegMaxDeviation); err != nil {
+		return nil, err
+	}
+
 	om, err := offers.NewManager(cfg.DataDir, cfg.Database)
 	if err != nil {
 		return nil, err
@@ -73,6 +95,19 @@ func NewInstance(cfg *Config) (*Instance, error) {
 		offerManager: om,
 		swapStates:   make(map[types.Hash]*swapState),
 		net:          cfg.Network,
+		priceOracle:  cfg.PriceOracle,
+
+		pegMaxDeviation: pegMaxDeviation,
+	}
+
+	inst.restoreOfferProfiles()
+
+	if inst.priceOracle != nil {
+		go inst.runPegRefresher()
+	}
+
+	if cfg.MarketMaker != nil {
+		go newMarketMaker(inst, cfg.MarketMaker).run()
 	}
 
 	err = inst.checkForOngoingSwaps()
@@ -83,6 +118,26 @@ func NewInstance(cfg *Config) (*Instance, error) {
 	return inst, nil
 }
 
+// restoreOfferProfiles sets the profiles of the offers loaded from the database, and
+// whether the market maker made them, which are stored with the offers' extra data
+// when the offers are made.
+func (inst *Instance) restoreOfferProfiles() {
+	for _, offer := range inst.offerManager.GetOffers() {
+		stored, err := inst.backend.RecoveryDB().GetSwapRelayerInfo(offer.ID)
+		if err != nil {
+			// the offer was made with the default profile
+			continue
+		}
+
+		_, extra, err := inst.offerManager.GetOffer(offer.ID)
+		if err != nil {
+			continue
+		}
+		extra.Profile = stored.Profile
+		extra.MarketMaker = stored.MarketMaker
+	}
+}
+
 func (inst *Instance) checkForOngoingSwaps() error {
 	swaps, err := inst.backend.SwapManager().GetOngoingSwaps()
 	if err != nil {
@@ -143,12 +198,37 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		return fmt.Errorf("failed to get offer for ongoing swap, offer ID %s: %s", s.OfferID, err)
 	}
 
+	b, err := inst.backend.WithProfile(s.Profile)
+	if err != nil {
+		return fmt.Errorf("failed to get profile of ongoing swap, offer ID %s: %w", s.OfferID, err)
+	}
+
 	ethSwapInfo, err := inst.backend.RecoveryDB().GetContractSwapInfo(s.OfferID)
 	if err != nil {
 		return fmt.Errorf("failed to get contract info for ongoing swap from db with offer ID %s: %s",
 			s.OfferID, err)
 	}
 
+	// the swap may have been claimed or refunded, or reached a timeout, while we
+	// were offline
+	state, action, err := reconcileOngoingSwap(b, ethSwapInfo)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ongoing swap %s with the contract: %w", s.OfferID, err)
+	}
+
+	log.Infof("reconciled ongoing swap %s with the contract, action: %s", s.OfferID, action)
+
+	switch action {
+	case recoveryCompleted:
+		return completeSwap(s, b, inst.offerManager)
+	case recoveryReclaimXMR:
+		// the XMR taker refunded, revealing their secret, so we can reclaim the XMR we locked
+		if err = inst.backend.RecoveryDB().PutCounterpartySwapPrivateKey(s.OfferID, state.RefundSecret); err != nil {
+			return err
+		}
+		return inst.completeSwap(s, state.RefundSecret)
+	}
+
 	sk, err := inst.backend.RecoveryDB().GetSwapPrivateKey(s.OfferID)
 	if err != nil {
 		return fmt.Errorf("failed to get private key for ongoing swap from db with offer ID %s: %s",
@@ -166,15 +246,17 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		// then no relayer was set for this swap.
 		relayerInfo = &types.OfferExtra{}
 	}
+	relayerInfo.Profile = s.Profile
 
 	ss, err := newSwapStateFromOngoing(
-		inst.backend,
+		b,
 		offer,
 		relayerInfo,
 		inst.offerManager,
 		ethSwapInfo,
 		s,
 		kp,
+		action,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create new swap state for ongoing swap, offer id %s: %w", s.OfferID, err)
@@ -204,6 +286,11 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 // set when the swap was started). It will also only only recover to the primary wallet
 // address, not whatever address was used when the swap was started.
 func (inst *Instance) completeSwap(s *swap.Info, skA *mcrypto.PrivateSpendKey) error {
+	b, err := inst.backend.WithProfile(s.Profile)
+	if err != nil {
+		return err
+	}
+
 	// fetch our swap private spend key
 	skB, err := inst.backend.RecoveryDB().GetSwapPrivateKey(s.OfferID)
 	if err != nil {
@@ -229,13 +316,14 @@ func (inst *Instance) completeSwap(s *swap.Info, skA *mcrypto.PrivateSpendKey) e
 	)
 
 	err = pcommon.ClaimMonero(
-		inst.backend.Ctx(),
-		inst.backend.Env(),
+		b.Ctx(),
+		b.Env(),
 		s.OfferID,
-		inst.backend.XMRClient(),
+		b.XMRClient(),
 		s.MoneroStartHeight,
 		kpAB,
-		inst.backend.XMRClient().PrimaryAddress(),
+		b.XMRClient().PrimaryAddress(),
+		b.XMRPriority(&s.OfferID),
 		false, // always sweep back to our primary address
 	)
 	if err != nil {
@@ -259,20 +347,25 @@ func (inst *Instance) GetOngoingSwapState(id types.Hash) common.SwapState {
 	return inst.swapStates[id]
 }
 
-// GetMoneroBalance returns the primary wallet address, and current balance of the user's monero
-// wallet.
-func (inst *Instance) GetMoneroBalance() (*mcrypto.Address, *wallet.GetBalanceResponse, error) {
-	addrResp, err := inst.backend.XMRClient().GetAddress(0)
+// GetMoneroBalance returns the primary wallet address, and current balance of the monero
+// wallet of the named profile, or of the user's monero wallet if the name is empty.
+func (inst *Instance) GetMoneroBalance(profile string) (*mcrypto.Address, *wallet.GetBalanceResponse, error) {
+	b, err := inst.backend.WithProfile(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addrResp, err := b.XMRClient().GetAddress(0)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	addr, err := mcrypto.NewAddress(addrResp.Address, inst.backend.Env())
+	addr, err := mcrypto.NewAddress(addrResp.Address, b.Env())
 	if err != nil {
 		return nil, nil, err
 	}
 
-	balanceResp, err := inst.backend.XMRClient().GetBalance(0)
+	balanceResp, err := b.XMRClient().GetBalance(0)
 	if err != nil {
 		return nil, nil, err
 	}
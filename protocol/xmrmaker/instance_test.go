@@ -66,6 +66,28 @@ func (n *mockNet) SubmitClaimToRelayer(_ peer.ID, _ *message.RelayClaimRequest)
 	return new(message.RelayClaimResponse), nil
 }
 
+func (n *mockNet) SubmitRefundToRelayer(_ peer.ID, _ *message.RelayRefundRequest) (*message.RelayClaimResponse, error) {
+	return new(message.RelayClaimResponse), nil
+}
+
+func (n *mockNet) SubmitNewSwapToRelayer(
+	_ peer.ID,
+	_ *message.RelayNewSwapRequest,
+) (*message.RelayClaimResponse, error) {
+	return new(message.RelayClaimResponse), nil
+}
+
+func (n *mockNet) SubmitSetReadyToRelayer(
+	_ peer.ID,
+	_ *message.RelaySetReadyRequest,
+) (*message.RelayClaimResponse, error) {
+	return new(message.RelayClaimResponse), nil
+}
+
+func (n *mockNet) QueryRelayerFee(_ peer.ID, _ *message.RelayFeeQuoteRequest) (*message.RelayFeeQuote, error) {
+	return new(message.RelayFeeQuote), nil
+}
+
 func (n *mockNet) CloseProtocolStream(_ types.Hash) {}
 
 func newSwapManager(t *testing.T) pswap.Manager {
@@ -75,7 +97,7 @@ func newSwapManager(t *testing.T) pswap.Manager {
 	db.EXPECT().GetAllSwaps()
 	db.EXPECT().PutSwap(gomock.Any()).AnyTimes()
 
-	sm, err := pswap.NewManager(db)
+	sm, err := pswap.NewManager(db, nil)
 	require.NoError(t, err)
 	return sm
 }
@@ -184,7 +206,7 @@ func TestInstance_createOngoingSwap(t *testing.T) {
 	offer := types.NewOffer(coins.ProvidesXMR, one, one, rate, types.EthAssetETH)
 
 	offerDB.EXPECT().PutOffer(offer).Return(nil)
-	_, err = inst.offerManager.AddOffer(offer, false)
+	_, err = inst.offerManager.AddOffer(offer, false, "")
 	require.NoError(t, err)
 
 	s := &pswap.Info{
@@ -0,0 +1,361 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+const (
+	// MaxMarketMakerOffers is the maximum number of offers that the market maker
+	// keeps open.
+	MaxMarketMakerOffers = 10
+
+	// DefaultMarketMakerInterval is the default interval at which the market maker
+	// reprices its offers.
+	DefaultMarketMakerInterval = 5 * time.Minute
+
+	// marketMakerCheckInterval is how often the market maker replaces its taken
+	// offers and checks its inventory and gas balance, which is also the minimum
+	// repricing interval.
+	marketMakerCheckInterval = 30 * time.Second
+)
+
+// MarketMakerConfig configures the market maker, which keeps a target number of XMR
+// offers open at the market exchange rate of a price source plus a spread. The
+// offers have fixed exchange rates, which are replaced by new offers when they are
+// repriced, so takers of all versions can take them. The available XMR is split
+// between the offers, so that they can all be taken at once.
+type MarketMakerConfig struct {
+	Offers          int          // target number of open offers
+	MinAmount       *apd.Decimal // XMR
+	MaxAmount       *apd.Decimal // XMR, lowered to the offers' share of the available XMR
+	Spread          *apd.Decimal // percent above the market exchange rate
+	PriceSource     string
+	RepriceInterval time.Duration
+	// XMRReserve is the unlocked XMR that is never offered, which pays for the fee
+	// of the lock transaction. Quoting pauses when the unlocked balance above it is
+	// below MinAmount.
+	XMRReserve *apd.Decimal
+	// MinETHBalance is the ETH balance below which quoting pauses, as claiming our
+	// swaps' ETH needs gas unless a relayer claims it. Zero disables the limit.
+	MinETHBalance *apd.Decimal
+	UseRelayer    bool
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c *MarketMakerConfig) Validate() error {
+	if c.Offers < 1 || c.Offers > MaxMarketMakerOffers {
+		return fmt.Errorf("offers must be between 1 and %d", MaxMarketMakerOffers)
+	}
+
+	if err := coins.ValidateXMRAmount("minAmount", c.MinAmount); err != nil {
+		return err
+	}
+	if err := coins.ValidateXMRAmount("maxAmount", c.MaxAmount); err != nil {
+		return err
+	}
+	if c.MinAmount.Cmp(c.MaxAmount) > 0 {
+		return errors.New("minAmount is greater than maxAmount")
+	}
+
+	if c.Spread == nil || c.Spread.Negative {
+		return errors.New("spread cannot be negative")
+	}
+
+	if err := pricefeed.ValidateSource(c.PriceSource); err != nil {
+		return err
+	}
+
+	if c.RepriceInterval < marketMakerCheckInterval {
+		return fmt.Errorf("repriceInterval must be at least %s", marketMakerCheckInterval)
+	}
+
+	if err := coins.ValidateXMRAmount("xmrReserve", c.XMRReserve); err != nil {
+		return err
+	}
+
+	if c.MinETHBalance == nil || c.MinETHBalance.Negative {
+		return errors.New("minETHBalance cannot be negative")
+	}
+
+	return nil
+}
+
+// offerMaxAmount returns the maximum amount and the number of the market maker's
+// offers with the unlocked XMR balance, the XMR reserved by our swaps that haven't
+// locked it yet and the ETH balance, or the limit that pauses quoting. The offers
+// can be taken concurrently, so the available XMR is split between them, and fewer
+// offers are made when a share would be below the minimum amount.
+func (c *MarketMakerConfig) offerMaxAmount(
+	unlockedXMR *apd.Decimal,
+	reservedXMR *apd.Decimal,
+	ethBalance *apd.Decimal,
+) (*apd.Decimal, int, error) {
+	if ethBalance.Cmp(c.MinETHBalance) < 0 {
+		return nil, 0, errMarketMakerGasLow{ethBalance, c.MinETHBalance}
+	}
+
+	available := new(apd.Decimal)
+	if _, err := coins.DecimalCtx().Sub(available, unlockedXMR, c.XMRReserve); err != nil {
+		return nil, 0, err
+	}
+	if _, err := coins.DecimalCtx().Sub(available, available, reservedXMR); err != nil {
+		return nil, 0, err
+	}
+
+	if available.Cmp(c.MinAmount) < 0 {
+		return nil, 0, errMarketMakerInventoryLow{available, c.MinAmount}
+	}
+
+	availablePiconero, err := coins.MoneroToPiconero(available).Uint64()
+	if err != nil {
+		return nil, 0, err
+	}
+	minPiconero, err := coins.MoneroToPiconero(c.MinAmount).Uint64()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	numOffers := uint64(c.Offers)
+	if n := availablePiconero / minPiconero; n < numOffers {
+		numOffers = n
+	}
+
+	maxAmount := coins.NewPiconeroAmount(availablePiconero / numOffers).AsMonero()
+	if maxAmount.Cmp(c.MaxAmount) > 0 {
+		maxAmount.Set(c.MaxAmount)
+	}
+
+	_, _ = maxAmount.Reduce(maxAmount)
+	return maxAmount, int(numOffers), nil
+}
+
+// reservedXMR returns the XMR provided by the ongoing swaps of the default profile
+// that haven't locked it yet, which is still included in the unlocked balance.
+func reservedXMR(swaps []*pswap.Info) (*apd.Decimal, error) {
+	reserved := new(apd.Decimal)
+	for _, s := range swaps {
+		if s.Provides != coins.ProvidesXMR || s.Profile != "" {
+			continue
+		}
+
+		if s.Status != types.ExpectingKeys && s.Status != types.KeysExchanged {
+			continue
+		}
+
+		if _, err := coins.DecimalCtx().Add(reserved, reserved, s.ProvidedAmount); err != nil {
+			return nil, err
+		}
+	}
+
+	return reserved, nil
+}
+
+// marketMaker keeps the offers of its configuration open. It is only accessed by
+// its run goroutine.
+type marketMaker struct {
+	inst *Instance
+	conf *MarketMakerConfig
+
+	offerIDs   map[types.Hash]struct{} // our open offers
+	rate       *coins.ExchangeRate     // exchange rate of our open offers, nil if unknown
+	maxAmount  *apd.Decimal            // max amount of our open offers, nil if unknown
+	numOffers  int                     // number of offers that the available XMR is split between
+	repricedAt time.Time
+	paused     error // the limit that paused quoting, nil while quoting
+}
+
+// newMarketMaker returns the market maker of the instance, which takes over the
+// offers that it made before swapd restarted.
+func newMarketMaker(inst *Instance, conf *MarketMakerConfig) *marketMaker {
+	m := &marketMaker{
+		inst:     inst,
+		conf:     conf,
+		offerIDs: make(map[types.Hash]struct{}),
+	}
+
+	for _, offer := range inst.offerManager.GetOffers() {
+		_, extra, err := inst.offerManager.GetOffer(offer.ID)
+		if err == nil && extra.MarketMaker {
+			m.offerIDs[offer.ID] = struct{}{}
+		}
+	}
+
+	return m
+}
+
+// run keeps our offers open until swapd exits.
+func (m *marketMaker) run() {
+	ctx := m.inst.backend.Ctx()
+
+	log.Infof("Market maker started, keeping %d offers of %s to %s XMR open at the %s rate plus %s%%",
+		m.conf.Offers, m.conf.MinAmount, m.conf.MaxAmount, m.conf.PriceSource, m.conf.Spread)
+
+	for {
+		m.update(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(marketMakerCheckInterval):
+		}
+	}
+}
+
+// update removes our offers that were taken, pauses or resumes quoting depending on
+// the limits, reprices our offers when they are due, and makes new offers until
+// the target number of offers is open.
+func (m *marketMaker) update(ctx context.Context) {
+	for id := range m.offerIDs {
+		if _, _, err := m.inst.offerManager.GetOffer(id); err != nil {
+			delete(m.offerIDs, id)
+		}
+	}
+
+	maxAmount, numOffers, err := m.offerMaxAmount(ctx)
+	if err != nil {
+		m.pause(err)
+		return
+	}
+
+	rate := m.rate
+	if rate == nil || time.Since(m.repricedAt) >= m.conf.RepriceInterval {
+		rate, err = m.quoteRate(ctx)
+		if err != nil {
+			m.pause(err)
+			return
+		}
+		m.repricedAt = time.Now()
+	}
+
+	if m.paused != nil {
+		log.Infof("Market maker resumed quoting")
+		m.paused = nil
+	}
+
+	if m.rate == nil || rate.Decimal().Cmp(m.rate.Decimal()) != 0 ||
+		maxAmount.Cmp(m.maxAmount) != 0 || numOffers != m.numOffers {
+		if len(m.offerIDs) > 0 {
+			log.Infof("Market maker repricing its offers to %s ETH/XMR, %d offers up to %s XMR",
+				rate, numOffers, maxAmount)
+		}
+		m.clearOffers()
+		m.rate = rate
+		m.maxAmount = maxAmount
+		m.numOffers = numOffers
+	}
+
+	for len(m.offerIDs) < m.numOffers {
+		if err = m.makeOffer(); err != nil {
+			log.Warnf("Market maker failed to make an offer: %s", err)
+			return
+		}
+	}
+}
+
+// offerMaxAmount returns the maximum amount and the number of our offers with the
+// current balances of the default profile and our ongoing swaps, or the limit that
+// pauses quoting.
+func (m *marketMaker) offerMaxAmount(ctx context.Context) (*apd.Decimal, int, error) {
+	balance, err := m.inst.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get the XMR balance: %w", err)
+	}
+
+	ethBalance, err := m.inst.backend.ETHClient().Balance(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get the ETH balance: %w", err)
+	}
+
+	swaps, err := m.inst.backend.SwapManager().GetOngoingSwaps()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get the ongoing swaps: %w", err)
+	}
+
+	reserved, err := reservedXMR(swaps)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	unlockedXMR := coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero()
+	return m.conf.offerMaxAmount(unlockedXMR, reserved, ethBalance.AsEther())
+}
+
+// quoteRate returns the market exchange rate of the price source plus the spread.
+func (m *marketMaker) quoteRate(ctx context.Context) (*coins.ExchangeRate, error) {
+	marketRate, err := m.inst.priceOracle.ExchangeRate(ctx, m.conf.PriceSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return marketRate.WithMarkup(m.conf.Spread)
+}
+
+// makeOffer makes one of our offers, marking it as the market maker's so that it is
+// taken over after a restart.
+func (m *marketMaker) makeOffer() error {
+	offer := types.NewOffer(
+		coins.ProvidesXMR,
+		new(apd.Decimal).Set(m.conf.MinAmount),
+		new(apd.Decimal).Set(m.maxAmount),
+		m.rate,
+		types.EthAssetETH,
+	)
+
+	extra, err := m.inst.MakeOffer(offer, m.conf.UseRelayer, "")
+	if err != nil {
+		return err
+	}
+
+	extra.MarketMaker = true
+	if err = m.inst.backend.RecoveryDB().PutSwapRelayerInfo(offer.ID, extra); err != nil {
+		return err
+	}
+
+	m.offerIDs[offer.ID] = struct{}{}
+	return nil
+}
+
+// pause clears our offers until the limit that paused quoting is no longer breached.
+func (m *marketMaker) pause(reason error) {
+	if m.paused == nil {
+		log.Warnf("Market maker paused quoting: %s", reason)
+	}
+	m.paused = reason
+
+	m.clearOffers()
+	m.rate = nil
+	m.maxAmount = nil
+	m.numOffers = 0
+}
+
+// clearOffers clears our open offers.
+func (m *marketMaker) clearOffers() {
+	if len(m.offerIDs) == 0 {
+		return
+	}
+
+	ids := make([]types.Hash, 0, len(m.offerIDs))
+	for id := range m.offerIDs {
+		ids = append(ids, id)
+	}
+
+	if err := m.inst.offerManager.ClearOfferIDs(ids); err != nil {
+		log.Warnf("Market maker failed to clear its offers: %s", err)
+		return
+	}
+
+	m.offerIDs = make(map[types.Hash]struct{})
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func newTestMarketMakerConfig() *MarketMakerConfig {
+	return &MarketMakerConfig{
+		Offers:          2,
+		MinAmount:       apd.New(1, -1),
+		MaxAmount:       apd.New(2, 0),
+		Spread:          apd.New(15, -1),
+		PriceSource:     pricefeed.SourceKraken,
+		RepriceInterval: DefaultMarketMakerInterval,
+		XMRReserve:      apd.New(1, -2),
+		MinETHBalance:   apd.New(5, -2),
+	}
+}
+
+func TestMarketMakerConfig_Validate(t *testing.T) {
+	require.NoError(t, newTestMarketMakerConfig().Validate())
+
+	conf := newTestMarketMakerConfig()
+	conf.Offers = MaxMarketMakerOffers + 1
+	require.ErrorContains(t, conf.Validate(), "offers must be between 1 and 10")
+
+	conf = newTestMarketMakerConfig()
+	conf.MinAmount = apd.New(3, 0)
+	require.ErrorContains(t, conf.Validate(), "minAmount is greater than maxAmount")
+
+	conf = newTestMarketMakerConfig()
+	conf.Spread = apd.New(-1, 0)
+	require.ErrorContains(t, conf.Validate(), "spread cannot be negative")
+
+	conf = newTestMarketMakerConfig()
+	conf.PriceSource = "binance"
+	require.Error(t, conf.Validate())
+
+	conf = newTestMarketMakerConfig()
+	conf.RepriceInterval = time.Second
+	require.ErrorContains(t, conf.Validate(), "repriceInterval must be at least 30s")
+
+	conf = newTestMarketMakerConfig()
+	conf.XMRReserve = apd.New(0, 0)
+	require.ErrorContains(t, conf.Validate(), `"xmrReserve" must be non-zero`)
+
+	// the gas balance limit is optional
+	conf = newTestMarketMakerConfig()
+	conf.MinETHBalance = apd.New(0, 0)
+	require.NoError(t, conf.Validate())
+}
+
+func TestMarketMakerConfig_offerMaxAmount(t *testing.T) {
+	conf := newTestMarketMakerConfig()
+	ethBalance := apd.New(1, 0)
+	noneReserved := new(apd.Decimal)
+
+	maxAmount, numOffers, err := conf.offerMaxAmount(apd.New(10, 0), noneReserved, ethBalance)
+	require.NoError(t, err)
+	require.Equal(t, "2", maxAmount.String())
+	require.Equal(t, 2, numOffers)
+
+	// the max amount is lowered to each offer's share of the available XMR
+	maxAmount, numOffers, err = conf.offerMaxAmount(apd.New(151, -2), noneReserved, ethBalance)
+	require.NoError(t, err)
+	require.Equal(t, "0.75", maxAmount.String())
+	require.Equal(t, 2, numOffers)
+
+	// fewer offers are made when a share would be below the min amount
+	maxAmount, numOffers, err = conf.offerMaxAmount(apd.New(16, -2), noneReserved, ethBalance)
+	require.NoError(t, err)
+	require.Equal(t, "0.15", maxAmount.String())
+	require.Equal(t, 1, numOffers)
+
+	// the XMR of swaps that haven't locked it yet isn't available
+	maxAmount, numOffers, err = conf.offerMaxAmount(apd.New(151, -2), apd.New(5, -1), ethBalance)
+	require.NoError(t, err)
+	require.Equal(t, "0.5", maxAmount.String())
+	require.Equal(t, 2, numOffers)
+
+	_, _, err = conf.offerMaxAmount(apd.New(1, -1), noneReserved, ethBalance)
+	require.IsType(t, errMarketMakerInventoryLow{}, err)
+	require.ErrorContains(t, err, "reserve of 0.09 XMR is below the minimum offer amount of 0.1 XMR")
+
+	_, _, err = conf.offerMaxAmount(apd.New(10, 0), apd.New(99, -1), ethBalance)
+	require.IsType(t, errMarketMakerInventoryLow{}, err)
+
+	_, _, err = conf.offerMaxAmount(apd.New(10, 0), noneReserved, apd.New(1, -2))
+	require.IsType(t, errMarketMakerGasLow{}, err)
+}
+
+func TestMarketMakerConfig_offerMaxAmount_concurrentTakes(t *testing.T) {
+	conf := newTestMarketMakerConfig()
+	conf.Offers = 3
+	ethBalance := apd.New(1, 0)
+	unlockedXMR := apd.New(101, -2) // 1 XMR above the reserve
+
+	maxAmount, numOffers, err := conf.offerMaxAmount(unlockedXMR, new(apd.Decimal), ethBalance)
+	require.NoError(t, err)
+	require.Equal(t, 3, numOffers)
+
+	// every offer is taken at its max amount before any XMR is locked
+	var swaps []*pswap.Info
+	for i := 0; i < numOffers; i++ {
+		swaps = append(swaps, &pswap.Info{
+			Provides:       coins.ProvidesXMR,
+			ProvidedAmount: maxAmount,
+			Status:         types.KeysExchanged,
+		})
+	}
+
+	reserved, err := reservedXMR(swaps)
+	require.NoError(t, err)
+	require.LessOrEqual(t, reserved.Cmp(apd.New(1, 0)), 0)
+
+	// nothing is left to offer until the swaps complete
+	_, _, err = conf.offerMaxAmount(unlockedXMR, reserved, ethBalance)
+	require.IsType(t, errMarketMakerInventoryLow{}, err)
+}
+
+func TestReservedXMR(t *testing.T) {
+	swaps := []*pswap.Info{
+		{Provides: coins.ProvidesXMR, ProvidedAmount: apd.New(1, 0), Status: types.ExpectingKeys},
+		{Provides: coins.ProvidesXMR, ProvidedAmount: apd.New(2, 0), Status: types.KeysExchanged},
+		// the XMR was locked, so the unlocked balance doesn't include it
+		{Provides: coins.ProvidesXMR, ProvidedAmount: apd.New(4, 0), Status: types.XMRLocked},
+		// swaps of other profiles are funded by other wallets
+		{Provides: coins.ProvidesXMR, ProvidedAmount: apd.New(8, 0), Status: types.KeysExchanged, Profile: "alice"},
+		{Provides: coins.ProvidesETH, ProvidedAmount: apd.New(16, 0), Status: types.ExpectingKeys},
+	}
+
+	reserved, err := reservedXMR(swaps)
+	require.NoError(t, err)
+	require.Equal(t, "3", reserved.String())
+}
+
+func TestMarketMaker_quoteRate(t *testing.T) {
+	now := time.Now()
+	oracle := &coins.MockPriceOracle{Rates: map[coins.Pair]*coins.Rate{
+		coins.PairXMRUSD: {Pair: coins.PairXMRUSD, Price: apd.New(150, 0), UpdatedAt: now},
+		coins.PairETHUSD: {Pair: coins.PairETHUSD, Price: apd.New(2000, 0), UpdatedAt: now},
+	}}
+
+	m := &marketMaker{
+		inst: &Instance{
+			priceOracle: pricefeed.NewOracleWithSources(map[string]coins.PriceOracle{
+				pricefeed.SourceKraken: oracle,
+			}, 0),
+		},
+		conf: newTestMarketMakerConfig(),
+	}
+
+	// 0.075 plus the spread of 1.5%
+	rate, err := m.quoteRate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "0.076125", rate.String())
+
+	// stale prices can't be quoted
+	oracle.Rates[coins.PairXMRUSD].UpdatedAt = now.Add(-time.Hour)
+	_, err = m.quoteRate(context.Background())
+	require.Error(t, err)
+}
@@ -213,5 +213,6 @@ func (s *swapState) handleSendKeysMessage(msg *message.SendKeysMessage) error {
 		return err
 	}
 
+	s.xmrtakerAcceptsLockProof = msg.XMRLockProof
 	return s.setXMRTakerKeys(msg.PublicSpendKey, msg.PrivateViewKey, verifyResult.Secp256k1PublicKey)
 }
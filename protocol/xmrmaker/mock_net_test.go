@@ -9,7 +9,10 @@ import (
 	reflect "reflect"
 	time "time"
 
+	net "github.com/athanorlabs/atomic-swap/net"
 	gomock "github.com/golang/mock/gomock"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	crypto "github.com/libp2p/go-libp2p/core/crypto"
 	network "github.com/libp2p/go-libp2p/core/network"
 	peer "github.com/libp2p/go-libp2p/core/peer"
 	protocol "github.com/libp2p/go-libp2p/core/protocol"
@@ -79,6 +82,34 @@ func (mr *MockP2pHostMockRecorder) Advertise() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Advertise", reflect.TypeOf((*MockP2pHost)(nil).Advertise))
 }
 
+// BanPeer mocks base method.
+func (m *MockP2pHost) BanPeer(arg0 peer.ID, arg1 time.Duration, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanPeer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BanPeer indicates an expected call of BanPeer.
+func (mr *MockP2pHostMockRecorder) BanPeer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanPeer", reflect.TypeOf((*MockP2pHost)(nil).BanPeer), arg0, arg1, arg2)
+}
+
+// BannedPeers mocks base method.
+func (m *MockP2pHost) BannedPeers() []*net.BannedPeer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BannedPeers")
+	ret0, _ := ret[0].([]*net.BannedPeer)
+	return ret0
+}
+
+// BannedPeers indicates an expected call of BannedPeers.
+func (mr *MockP2pHostMockRecorder) BannedPeers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BannedPeers", reflect.TypeOf((*MockP2pHost)(nil).BannedPeers))
+}
+
 // Connect mocks base method.
 func (m *MockP2pHost) Connect(arg0 context.Context, arg1 peer.AddrInfo) error {
 	m.ctrl.T.Helper()
@@ -136,6 +167,35 @@ func (mr *MockP2pHostMockRecorder) Discover(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discover", reflect.TypeOf((*MockP2pHost)(nil).Discover), arg0, arg1)
 }
 
+// JoinTopic mocks base method.
+func (m *MockP2pHost) JoinTopic(arg0 string, arg1 pubsub.ValidatorEx) (*pubsub.Topic, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinTopic", arg0, arg1)
+	ret0, _ := ret[0].(*pubsub.Topic)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JoinTopic indicates an expected call of JoinTopic.
+func (mr *MockP2pHostMockRecorder) JoinTopic(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinTopic", reflect.TypeOf((*MockP2pHost)(nil).JoinTopic), arg0, arg1)
+}
+
+// NATStatus mocks base method.
+func (m *MockP2pHost) NATStatus() *net.NATStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NATStatus")
+	ret0, _ := ret[0].(*net.NATStatus)
+	return ret0
+}
+
+// NATStatus indicates an expected call of NATStatus.
+func (mr *MockP2pHostMockRecorder) NATStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NATStatus", reflect.TypeOf((*MockP2pHost)(nil).NATStatus))
+}
+
 // NewStream mocks base method.
 func (m *MockP2pHost) NewStream(arg0 context.Context, arg1 peer.ID, arg2 protocol.ID) (network.Stream, error) {
 	m.ctrl.T.Helper()
@@ -165,6 +225,44 @@ func (mr *MockP2pHostMockRecorder) PeerID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerID", reflect.TypeOf((*MockP2pHost)(nil).PeerID))
 }
 
+// PrivKey mocks base method.
+func (m *MockP2pHost) PrivKey() crypto.PrivKey {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrivKey")
+	ret0, _ := ret[0].(crypto.PrivKey)
+	return ret0
+}
+
+// PrivKey indicates an expected call of PrivKey.
+func (mr *MockP2pHostMockRecorder) PrivKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrivKey", reflect.TypeOf((*MockP2pHost)(nil).PrivKey))
+}
+
+// ProtectPeer mocks base method.
+func (m *MockP2pHost) ProtectPeer(arg0 peer.ID, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ProtectPeer", arg0, arg1)
+}
+
+// ProtectPeer indicates an expected call of ProtectPeer.
+func (mr *MockP2pHostMockRecorder) ProtectPeer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProtectPeer", reflect.TypeOf((*MockP2pHost)(nil).ProtectPeer), arg0, arg1)
+}
+
+// ReportMisbehavior mocks base method.
+func (m *MockP2pHost) ReportMisbehavior(arg0 peer.ID, arg1 int, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportMisbehavior", arg0, arg1, arg2)
+}
+
+// ReportMisbehavior indicates an expected call of ReportMisbehavior.
+func (mr *MockP2pHostMockRecorder) ReportMisbehavior(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportMisbehavior", reflect.TypeOf((*MockP2pHost)(nil).ReportMisbehavior), arg0, arg1, arg2)
+}
+
 // SetStreamHandler mocks base method.
 func (m *MockP2pHost) SetStreamHandler(arg0 string, arg1 func(network.Stream)) {
 	m.ctrl.T.Helper()
@@ -204,3 +302,31 @@ func (mr *MockP2pHostMockRecorder) Stop() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockP2pHost)(nil).Stop))
 }
+
+// UnbanPeer mocks base method.
+func (m *MockP2pHost) UnbanPeer(arg0 peer.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanPeer", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanPeer indicates an expected call of UnbanPeer.
+func (mr *MockP2pHostMockRecorder) UnbanPeer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanPeer", reflect.TypeOf((*MockP2pHost)(nil).UnbanPeer), arg0)
+}
+
+// UnprotectPeer mocks base method.
+func (m *MockP2pHost) UnprotectPeer(arg0 peer.ID, arg1 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnprotectPeer", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// UnprotectPeer indicates an expected call of UnprotectPeer.
+func (mr *MockP2pHostMockRecorder) UnprotectPeer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnprotectPeer", reflect.TypeOf((*MockP2pHost)(nil).UnprotectPeer), arg0, arg1)
+}
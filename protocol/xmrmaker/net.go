@@ -12,6 +12,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
 
 	"github.com/fatih/color"
 )
@@ -29,10 +30,15 @@ func (inst *Instance) initiate(
 	desiredAmount coins.EthAssetAmount,
 ) (*swapState, error) {
 	if inst.swapStates[offer.ID] != nil {
-		return nil, errProtocolAlreadyInProgress
+		return nil, pcommon.ErrProtocolAlreadyInProgress
 	}
 
-	balance, err := inst.backend.XMRClient().GetBalance(0)
+	b, err := inst.backend.WithProfile(offerExtra.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := b.XMRClient().GetBalance(0)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +60,7 @@ func (inst *Instance) initiate(
 	}
 
 	s, err := newSwapStateFromStart(
-		inst.backend,
+		b,
 		takerPeerID,
 		offer,
 		offerExtra,
@@ -103,6 +109,10 @@ func (inst *Instance) HandleInitiateMessage(
 	)
 	log.Info(str)
 
+	if inst.backend.Paused() {
+		return nil, nil, backend.ErrPaused
+	}
+
 	// get offer and determine expected amount
 	if types.IsHashZero(msg.OfferID) {
 		return nil, nil, errOfferIDNotSet
@@ -120,6 +130,15 @@ func (inst *Instance) HandleInitiateMessage(
 		return nil, nil, err
 	}
 
+	// the exchange rate of pegged offers is resolved now, the taker must take them
+	// at about the same rate
+	if offer.Peg != nil {
+		offer, err = inst.pegTakenOffer(offer, msg.ExchangeRate)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	providedAmount, err := offer.ExchangeRate.ToXMR(msg.ProvidedAmount)
 	if err != nil {
 		return nil, nil, err
@@ -20,7 +20,8 @@ const statusChSize = 6 // the max number of stages a swap can potentially go thr
 var (
 	log = logging.Logger("offers")
 
-	errOfferDoesNotExist = errors.New("offer with given ID does not exist")
+	// ErrOfferDoesNotExist is returned when we have no offer with the given ID.
+	ErrOfferDoesNotExist = errors.New("offer with given ID does not exist")
 )
 
 // Manager synchronises access to the offers map.
@@ -76,16 +77,19 @@ func (m *Manager) GetOffer(id types.Hash) (*types.Offer, *types.OfferExtra, erro
 
 	offer, has := m.offers[id]
 	if !has {
-		return nil, nil, errOfferDoesNotExist
+		return nil, nil, ErrOfferDoesNotExist
 	}
 
 	return offer.offer, offer.extra, nil
 }
 
-// AddOffer adds a new offer to the manager and returns its OffersExtra data
+// AddOffer adds a new offer to the manager and returns its OffersExtra data. The
+// profile is the name of the profile that funds the offer's swap, empty for the
+// default profile.
 func (m *Manager) AddOffer(
 	offer *types.Offer,
 	useRelayer bool,
+	profile string,
 ) (*types.OfferExtra, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -104,6 +108,7 @@ func (m *Manager) AddOffer(
 	extra := &types.OfferExtra{
 		StatusCh:   make(chan types.Status, statusChSize),
 		UseRelayer: useRelayer,
+		Profile:    profile,
 	}
 
 	m.offers[id] = &offerWithExtra{
@@ -114,6 +119,22 @@ func (m *Manager) AddOffer(
 	return extra, nil
 }
 
+// UpdateOffer replaces the managed offer with the same ID by the updated offer, eg.
+// with the current exchange rate of a pegged offer. The database keeps the offer
+// that was added. It returns ErrOfferDoesNotExist if the offer is no longer managed.
+func (m *Manager) UpdateOffer(updated *types.Offer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offer, has := m.offers[updated.ID]
+	if !has {
+		return ErrOfferDoesNotExist
+	}
+
+	offer.offer = updated
+	return nil
+}
+
 // TakeOffer returns any offer with the matching id and removes the offer from the cache,
 // but leaves it in the database (unlike the Clear/DeleteOffer methods.)
 // Nil for both values is returned when the passed offer id is not currently managed.
@@ -123,7 +144,7 @@ func (m *Manager) TakeOffer(id types.Hash) (*types.Offer, *types.OfferExtra, err
 
 	offer, has := m.offers[id]
 	if !has {
-		return nil, nil, errOfferDoesNotExist
+		return nil, nil, ErrOfferDoesNotExist
 	}
 
 	delete(m.offers, id)
@@ -41,7 +41,7 @@ func Test_Manager(t *testing.T) {
 			types.EthAssetETH,
 		)
 		db.EXPECT().PutOffer(offer)
-		offerExtra, err := mgr.AddOffer(offer, false)
+		offerExtra, err := mgr.AddOffer(offer, false, "")
 		require.NoError(t, err)
 		require.NotNil(t, offerExtra)
 	}
@@ -86,7 +86,7 @@ func Test_Manager_NoErrorDeletingOfferNotOnDisk(t *testing.T) {
 		coins.ToExchangeRate(coins.StrToDecimal("0.1")),
 		types.EthAssetETH,
 	)
-	offerExtra, err := mgr.AddOffer(offer, false)
+	offerExtra, err := mgr.AddOffer(offer, false, "")
 	require.NoError(t, err)
 	require.NotNil(t, offerExtra)
 
@@ -115,9 +115,41 @@ func Test_Manager_NoErrorDeletingOfferNotOnDisk(t *testing.T) {
 
 	// Getting the offer fails
 	_, _, err = mgr.GetOffer(offer.ID)
-	require.ErrorIs(t, err, errOfferDoesNotExist)
+	require.ErrorIs(t, err, ErrOfferDoesNotExist)
 
 	// Double deletion is not an error
 	err = mgr.DeleteOffer(offer.ID)
 	require.NoError(t, err)
 }
+
+func Test_Manager_UpdateOffer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	offer := types.NewPeggedOffer(
+		coins.ProvidesXMR,
+		coins.StrToDecimal("1"),
+		coins.StrToDecimal("2"),
+		coins.StrToExchangeRate("0.075"),
+		&types.OfferPeg{Source: "kraken", Markup: coins.StrToDecimal("1")},
+	)
+
+	updated := offer.WithExchangeRate(coins.StrToExchangeRate("0.08"))
+	require.ErrorIs(t, mgr.UpdateOffer(updated), ErrOfferDoesNotExist)
+
+	db.EXPECT().PutOffer(offer)
+	_, err = mgr.AddOffer(offer, false, "")
+	require.NoError(t, err)
+
+	// the updated offer is returned, but the database is not updated
+	require.NoError(t, mgr.UpdateOffer(updated))
+	res, _, err := mgr.GetOffer(offer.ID)
+	require.NoError(t, err)
+	require.Equal(t, "0.08", res.ExchangeRate.String())
+	require.Equal(t, "0.075", offer.ExchangeRate.String())
+}
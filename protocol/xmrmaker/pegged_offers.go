@@ -0,0 +1,151 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+)
+
+const (
+	// pegRefreshInterval is how often the exchange rates of our pegged offers are
+	// updated to the market price of their price source.
+	pegRefreshInterval = time.Minute
+
+	// DefaultPegMaxDeviation is the default percent by which the exchange rate that
+	// a taker takes a pegged offer at can be below the offer's exchange rate at take
+	// time, as the taker got the exchange rate from an earlier refresh.
+	DefaultPegMaxDeviation = 1
+)
+
+// ValidatePegMaxDeviation returns an error if the percent is not usable as the
+// maximum deviation of the exchange rates that pegged offers are taken at.
+func ValidatePegMaxDeviation(deviation *apd.Decimal) error {
+	if deviation == nil || deviation.Negative || deviation.Form != apd.Finite {
+		return errors.New("peg max deviation must be a non-negative percent")
+	}
+	if deviation.Cmp(apd.New(100, 0)) >= 0 {
+		return errors.New("peg max deviation must be below 100 percent")
+	}
+	return nil
+}
+
+// PeggedExchangeRate returns the current exchange rate of the peg, which is the
+// market price of its price source plus its markup.
+func (inst *Instance) PeggedExchangeRate(peg *types.OfferPeg) (*coins.ExchangeRate, error) {
+	if inst.priceOracle == nil {
+		return nil, errNoPriceOracle
+	}
+
+	if err := pricefeed.ValidateSource(peg.Source); err != nil {
+		return nil, err
+	}
+
+	return inst.priceOracle.PeggedExchangeRate(inst.backend.Ctx(), peg)
+}
+
+// runPegRefresher keeps the exchange rates of our pegged offers at the market price
+// of their price source until swapd exits.
+func (inst *Instance) runPegRefresher() {
+	ctx := inst.backend.Ctx()
+
+	for {
+		inst.refreshPeggedOffers()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pegRefreshInterval):
+		}
+	}
+}
+
+// refreshPeggedOffers updates the exchange rates of our pegged offers. Offers whose
+// price source fails keep their previous exchange rate, but they can't be taken
+// until the source is available again, as the exchange rate is resolved at take
+// time.
+func (inst *Instance) refreshPeggedOffers() {
+	ctx := inst.backend.Ctx()
+
+	// the market prices by source, so that each source is only queried once
+	marketRates := make(map[string]*coins.ExchangeRate)
+
+	for _, offer := range inst.offerManager.GetOffers() {
+		if offer.Peg == nil {
+			continue
+		}
+
+		marketRate, ok := marketRates[offer.Peg.Source]
+		if !ok {
+			var err error
+			marketRate, err = inst.priceOracle.ExchangeRate(ctx, offer.Peg.Source)
+			if err != nil {
+				log.Warnf("Failed to update the exchange rate of pegged offer %s: %s", offer.ID, err)
+				continue
+			}
+			marketRates[offer.Peg.Source] = marketRate
+		}
+
+		rate, err := marketRate.WithMarkup(offer.Peg.Markup)
+		if err != nil {
+			log.Warnf("Failed to update the exchange rate of pegged offer %s: %s", offer.ID, err)
+			continue
+		}
+
+		if rate.Decimal().Cmp(offer.ExchangeRate.Decimal()) == 0 {
+			continue
+		}
+
+		// the offer is not updated if it was taken in the meantime
+		if err = inst.offerManager.UpdateOffer(offer.WithExchangeRate(rate)); err == nil {
+			log.Debugf("updated the exchange rate of pegged offer %s (%s) from %s to %s",
+				offer.ID, offer.Peg, offer.ExchangeRate, rate)
+		}
+	}
+}
+
+// pegTakenOffer returns a copy of the pegged offer with the exchange rate that the
+// taker takes it at. It errors if the exchange rate can't be resolved at take time,
+// or if the taker's exchange rate is too far below it.
+func (inst *Instance) pegTakenOffer(offer *types.Offer, takerRate *coins.ExchangeRate) (*types.Offer, error) {
+	if takerRate == nil {
+		return nil, errPegRateNotSet
+	}
+
+	currentRate, err := inst.PeggedExchangeRate(offer.Peg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the exchange rate of pegged offer %s: %w", offer.ID, err)
+	}
+
+	if err = inst.checkPegTakerRate(takerRate, currentRate); err != nil {
+		return nil, err
+	}
+
+	log.Infof("pegged offer %s (%s) is taken at exchange rate %s, the current rate is %s",
+		offer.ID, offer.Peg, takerRate, currentRate)
+	return offer.WithExchangeRate(takerRate), nil
+}
+
+// checkPegTakerRate returns an error if the taker's exchange rate of a pegged offer
+// is below its current exchange rate by more than the maximum deviation.
+func (inst *Instance) checkPegTakerRate(takerRate *coins.ExchangeRate, currentRate *coins.ExchangeRate) error {
+	negDeviation := new(apd.Decimal).Neg(inst.pegMaxDeviation)
+	minRate, err := currentRate.WithMarkup(negDeviation)
+	if err != nil {
+		return err
+	}
+
+	if takerRate.Decimal().Cmp(minRate.Decimal()) < 0 {
+		return errPegRateTooLow{takerRate, currentRate}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestValidatePegMaxDeviation(t *testing.T) {
+	require.NoError(t, ValidatePegMaxDeviation(apd.New(0, 0)))
+	require.NoError(t, ValidatePegMaxDeviation(apd.New(25, -1)))
+	require.Error(t, ValidatePegMaxDeviation(nil))
+	require.Error(t, ValidatePegMaxDeviation(apd.New(-1, 0)))
+	require.Error(t, ValidatePegMaxDeviation(apd.New(100, 0)))
+}
+
+func TestInstance_checkPegTakerRate(t *testing.T) {
+	currentRate := coins.ToExchangeRate(apd.New(1, -1))
+	inst := &Instance{pegMaxDeviation: apd.New(DefaultPegMaxDeviation, 0)}
+
+	require.NoError(t, inst.checkPegTakerRate(coins.ToExchangeRate(apd.New(1, -1)), currentRate))
+	require.NoError(t, inst.checkPegTakerRate(coins.ToExchangeRate(apd.New(99, -3)), currentRate))
+
+	err := inst.checkPegTakerRate(coins.ToExchangeRate(apd.New(98, -3)), currentRate)
+	require.IsType(t, errPegRateTooLow{}, err)
+
+	// a larger deviation accepts the same rate
+	inst.pegMaxDeviation = apd.New(25, -1)
+	require.NoError(t, inst.checkPegTakerRate(coins.ToExchangeRate(apd.New(98, -3)), currentRate))
+
+	// without a deviation, the taker's rate can't be below the current rate
+	inst.pegMaxDeviation = apd.New(0, 0)
+	err = inst.checkPegTakerRate(coins.ToExchangeRate(apd.New(999, -4)), currentRate)
+	require.IsType(t, errPegRateTooLow{}, err)
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/db"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+)
+
+// recoveryAction is what to do with an ongoing swap found in the db on startup,
+// given the state of the swap in the contract.
+type recoveryAction byte
+
+const (
+	// recoveryCompleted marks the swap as completed, as we claimed the ETH
+	// asset before exiting.
+	recoveryCompleted recoveryAction = iota
+	// recoveryReclaimXMR reclaims the XMR with the secret revealed by the XMR
+	// taker's refund.
+	recoveryReclaimXMR
+	// recoveryClaim resumes the swap, claiming the ETH asset when the contract
+	// is set to ready or at t0.
+	recoveryClaim
+	// recoveryWaitForRefund resumes the swap after t1, when we can't claim
+	// anymore, to reclaim the XMR once the XMR taker refunds.
+	recoveryWaitForRefund
+)
+
+func (a recoveryAction) String() string {
+	switch a {
+	case recoveryCompleted:
+		return "completed"
+	case recoveryReclaimXMR:
+		return "reclaim XMR"
+	case recoveryClaim:
+		return "claim"
+	case recoveryWaitForRefund:
+		return "wait for refund"
+	default:
+		return "unknown"
+	}
+}
+
+// getRecoveryAction returns what to do with an ongoing swap with the given
+// contract state and t1 timeout, at the given time of the latest block. Before
+// t1, the resumed swap claims when the contract is set to ready or at t0.
+func getRecoveryAction(state *pcommon.ContractSwapState, t1 time.Time, now time.Time) (recoveryAction, error) {
+	switch state.Stage {
+	case contracts.StageInvalid:
+		// our XMR is locked, so the swap can't be aborted
+		return 0, errSwapDoesNotExist
+	case contracts.StageCompleted:
+		if state.RefundSecret != nil {
+			return recoveryReclaimXMR, nil
+		}
+		return recoveryCompleted, nil
+	case contracts.StagePending, contracts.StageReady:
+		if !now.Before(t1) {
+			return recoveryWaitForRefund, nil
+		}
+		return recoveryClaim, nil
+	default:
+		return 0, fmt.Errorf("unhandled contract stage %d", state.Stage)
+	}
+}
+
+// reconcileOngoingSwap returns the contract state of an ongoing swap found in the
+// db on startup, and what to do with the swap.
+func reconcileOngoingSwap(
+	b backend.Backend,
+	ethSwapInfo *db.EthereumSwapInfo,
+) (*pcommon.ContractSwapState, recoveryAction, error) {
+	state, err := pcommon.GetContractSwapState(b, ethSwapInfo)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ts, err := b.ETHClient().LatestBlockTimestamp(b.Ctx())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	action, err := getRecoveryAction(state, time.Unix(ethSwapInfo.Swap.Timeout1.Int64(), 0), ts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: contract swap ID: %s", err, ethSwapInfo.SwapID)
+	}
+
+	return state, action, nil
+}
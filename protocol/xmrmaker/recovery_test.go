@@ -0,0 +1,82 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+)
+
+func TestGetRecoveryAction(t *testing.T) {
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	secret := kp.SpendKey()
+
+	t1 := time.Now()
+	beforeT1 := t1.Add(-time.Minute)
+	afterT1 := t1.Add(time.Minute)
+
+	type entry struct {
+		name   string
+		state  *pcommon.ContractSwapState
+		now    time.Time
+		action recoveryAction
+	}
+	testEntries := []entry{
+		{
+			name:   "claimed",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageCompleted, ClaimSecret: secret},
+			now:    beforeT1,
+			action: recoveryCompleted,
+		},
+		{
+			name:   "refunded",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageCompleted, RefundSecret: secret},
+			now:    afterT1,
+			action: recoveryReclaimXMR,
+		},
+		{
+			name:   "pending before t1",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StagePending},
+			now:    beforeT1,
+			action: recoveryClaim,
+		},
+		{
+			name:   "ready before t1",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageReady},
+			now:    beforeT1,
+			action: recoveryClaim,
+		},
+		{
+			name:   "pending at t1",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StagePending},
+			now:    t1,
+			action: recoveryWaitForRefund,
+		},
+		{
+			name:   "ready after t1",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageReady},
+			now:    afterT1,
+			action: recoveryWaitForRefund,
+		},
+	}
+
+	for _, e := range testEntries {
+		action, err := getRecoveryAction(e.state, t1, e.now)
+		require.NoError(t, err, e.name)
+		require.Equal(t, e.action, action, e.name)
+	}
+
+	_, err = getRecoveryAction(&pcommon.ContractSwapState{Stage: contracts.StageInvalid}, t1, beforeT1)
+	require.ErrorIs(t, err, errSwapDoesNotExist)
+
+	_, err = getRecoveryAction(&pcommon.ContractSwapState{Stage: 4}, t1, beforeT1)
+	require.ErrorContains(t, err, "unhandled contract stage 4")
+}
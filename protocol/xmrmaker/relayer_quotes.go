@@ -0,0 +1,102 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+	"github.com/athanorlabs/atomic-swap/relayer"
+)
+
+// relayerQuote is a fee quote received from a relayer, along with the relayer's
+// score from its recorded outcomes.
+type relayerQuote struct {
+	peerID peer.ID
+	quote  *message.RelayFeeQuote
+	score  float64
+}
+
+// queryRelayerQuotes asks the relayers for their fee to relay our claim, in
+// parallel, and returns the quotes that don't exceed maxFee, ordered by their
+// fee relative to the relayer's score. Relayers that fail to respond are left out.
+func (s *swapState) queryRelayerQuotes(relayers []peer.ID, maxFee *big.Int) []*relayerQuote {
+	req := &message.RelayFeeQuoteRequest{
+		SwapCreatorAddr: s.swapCreatorAddr,
+		Asset:           s.contractSwap.Asset,
+		Value:           s.contractSwap.Value,
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		quotes []*relayerQuote
+	)
+
+	for _, relayerPeerID := range relayers {
+		wg.Add(1)
+		go func(relayerPeerID peer.ID) {
+			defer wg.Done()
+
+			quote, err := s.Backend.QueryRelayerFee(relayerPeerID, req)
+			if err != nil {
+				log.Debugf("relayer %s did not quote a fee: %s", relayerPeerID, err)
+				return
+			}
+
+			log.Debugf("relayer %s quoted a fee of %s with an ETA of %ds",
+				relayerPeerID, relayer.FmtAssetFee(types.EthAsset(req.Asset), quote.FeeWei), quote.ETASeconds)
+
+			mu.Lock()
+			quotes = append(quotes, &relayerQuote{
+				peerID: relayerPeerID,
+				quote:  quote,
+				score:  s.Backend.RelayerScore(relayerPeerID),
+			})
+			mu.Unlock()
+		}(relayerPeerID)
+	}
+
+	wg.Wait()
+
+	return selectRelayerQuotes(quotes, maxFee)
+}
+
+// selectRelayerQuotes drops the quotes above maxFee and orders the rest by the
+// fee divided by the relayer's score, the expected fee per confirmed claim, so a
+// cheap relayer that often fails comes after a reliable one. Ties are ordered by
+// score, then by ETA.
+func selectRelayerQuotes(quotes []*relayerQuote, maxFee *big.Int) []*relayerQuote {
+	selected := make([]*relayerQuote, 0, len(quotes))
+	for _, q := range quotes {
+		if q.quote.FeeWei.Sign() < 0 || q.quote.FeeWei.Cmp(maxFee) > 0 {
+			continue
+		}
+		selected = append(selected, q)
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		qi, qj := selected[i], selected[j]
+
+		// fee_i / score_i < fee_j / score_j, without dividing
+		costI := new(big.Float).Mul(new(big.Float).SetInt(qi.quote.FeeWei), big.NewFloat(qj.score))
+		costJ := new(big.Float).Mul(new(big.Float).SetInt(qj.quote.FeeWei), big.NewFloat(qi.score))
+		if c := costI.Cmp(costJ); c != 0 {
+			return c < 0
+		}
+
+		if qi.score != qj.score {
+			return qi.score > qj.score
+		}
+
+		return qi.quote.ETASeconds < qj.quote.ETASeconds
+	})
+
+	return selected
+}
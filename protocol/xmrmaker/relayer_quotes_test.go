@@ -0,0 +1,62 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+func TestSelectRelayerQuotes(t *testing.T) {
+	newQuote := func(id string, fee int64, eta uint64) *relayerQuote {
+		return &relayerQuote{
+			peerID: peer.ID(id),
+			quote:  &message.RelayFeeQuote{FeeWei: big.NewInt(fee), ETASeconds: eta},
+			score:  0.5,
+		}
+	}
+
+	quotes := []*relayerQuote{
+		newQuote("expensive", 9e15, 12),
+		newQuote("slow", 3e15, 60),
+		newQuote("fast", 3e15, 12),
+		newQuote("cheapest", 2e15, 24),
+		newQuote("too-expensive", 1e16, 12),
+	}
+
+	selected := selectRelayerQuotes(quotes, big.NewInt(9e15))
+	require.Len(t, selected, 4)
+
+	var order []peer.ID
+	for _, q := range selected {
+		order = append(order, q.peerID)
+	}
+	require.Equal(t, []peer.ID{"cheapest", "fast", "slow", "expensive"}, order)
+}
+
+func TestSelectRelayerQuotes_score(t *testing.T) {
+	unreliable := &relayerQuote{
+		peerID: "unreliable",
+		quote:  &message.RelayFeeQuote{FeeWei: big.NewInt(2e15)},
+		score:  0.1,
+	}
+	reliable := &relayerQuote{
+		peerID: "reliable",
+		quote:  &message.RelayFeeQuote{FeeWei: big.NewInt(3e15)},
+		score:  0.9,
+	}
+	unknown := &relayerQuote{
+		peerID: "unknown",
+		quote:  &message.RelayFeeQuote{FeeWei: big.NewInt(3e15)},
+		score:  0.5,
+	}
+
+	selected := selectRelayerQuotes([]*relayerQuote{unreliable, unknown, reliable}, big.NewInt(9e15))
+	require.Equal(t, []*relayerQuote{reliable, unknown, unreliable}, selected)
+}
@@ -7,13 +7,11 @@ package xmrmaker
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/cockroachdb/apd/v3"
-	"github.com/ethereum/go-ethereum"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/fatih/color"
@@ -74,6 +72,7 @@ type swapState struct {
 	xmrtakerPrivateViewKey     *mcrypto.PrivateViewKey
 	xmrtakerSecp256K1PublicKey *secp256k1.PublicKey
 	moneroStartHeight          uint64 // height of the monero blockchain when the swap is started
+	xmrtakerAcceptsLockProof   bool   // whether XMRTaker accepts a NotifyXMRLock message
 
 	// tracks the state of the swap
 	nextExpectedEvent EventType
@@ -147,6 +146,7 @@ func newSwapStateFromStart(
 		moneroStartHeight,
 		offerExtra.StatusCh,
 	)
+	info.Profile = b.ProfileName()
 
 	if err = b.SwapManager().AddSwap(info); err != nil {
 		return nil, err
@@ -174,76 +174,6 @@ func newSwapStateFromStart(
 	return s, nil
 }
 
-// checkIfAlreadyClaimed returns true if the ETH has already been
-// claimed by us, false otherwise.
-func checkIfAlreadyClaimed(
-	b backend.Backend,
-	ethSwapInfo *db.EthereumSwapInfo,
-) (bool, error) {
-	// check if swap actually completed and we didn't realize for some reason
-	// this could happen if we restart from an ongoing swap
-	contract, err := contracts.NewSwapCreator(ethSwapInfo.SwapCreatorAddr, b.ETHClient().Raw())
-	if err != nil {
-		return false, err
-	}
-
-	stage, err := contract.Swaps(b.ETHClient().CallOpts(b.Ctx()), ethSwapInfo.SwapID)
-	if err != nil {
-		return false, err
-	}
-
-	switch stage {
-	case contracts.StageInvalid:
-		// this should never happen
-		return false, fmt.Errorf("%w: contract swap ID: %s", errSwapDoesNotExist, ethSwapInfo.SwapID)
-	case contracts.StageCompleted:
-		// check if we already claimed, or if the swap was refunded
-	case contracts.StagePending, contracts.StageReady:
-		return false, nil
-	default:
-		panic("Unhandled stage value")
-	}
-
-	filterQuery := ethereum.FilterQuery{
-		FromBlock: ethSwapInfo.StartNumber,
-		Addresses: []ethcommon.Address{ethSwapInfo.SwapCreatorAddr},
-	}
-
-	claimedTopic := common.GetTopic(common.ClaimedEventSignature)
-
-	// let's see if we have logs
-	logs, err := b.ETHClient().Raw().FilterLogs(b.Ctx(), filterQuery)
-	if err != nil {
-		return false, fmt.Errorf("failed to filter logs for topic %s: %s", claimedTopic, err)
-	}
-
-	log.Debugf("filtered for logs from block %s to head", filterQuery.FromBlock)
-
-	var foundClaimed bool
-	for _, l := range logs {
-		l := l
-		if l.Topics[0] != claimedTopic {
-			continue
-		}
-
-		if l.Removed {
-			log.Debugf("found removed log: tx hash %s", l.TxHash)
-			continue
-		}
-
-		err = pcommon.CheckSwapID(&l, claimedTopic, ethSwapInfo.SwapID)
-		if errors.Is(err, pcommon.ErrLogNotForUs) {
-			continue
-		}
-
-		log.Infof("found Claimed log in block %d", l.BlockNumber)
-		foundClaimed = true
-		break
-	}
-
-	return foundClaimed, nil
-}
-
 // completeSwap marks the swap as completed and deletes it from the db.
 func completeSwap(info *swap.Info, b backend.Backend, om *offers.Manager) error {
 	// set swap to completed
@@ -278,23 +208,8 @@ func newSwapStateFromOngoing(
 	ethSwapInfo *db.EthereumSwapInfo,
 	info *pswap.Info,
 	sk *mcrypto.PrivateKeyPair,
+	action recoveryAction,
 ) (*swapState, error) {
-	alreadyClaimed, err := checkIfAlreadyClaimed(b, ethSwapInfo)
-	if err != nil {
-		return nil, err
-	}
-
-	if alreadyClaimed {
-		err = completeSwap(info, b, om)
-		if err != nil {
-			return nil, fmt.Errorf("failed to complete swap: %w", err)
-		}
-
-		// although this doesn't look like an error, we need to return an error
-		// so the caller knows the swap is completed
-		return nil, errors.New("swap was already completed successfully")
-	}
-
 	// TODO: do we want to support the case where the ETH has been locked,
 	// but we haven't locked yet?
 	if info.Status != types.XMRLocked {
@@ -319,6 +234,16 @@ func newSwapStateFromOngoing(
 	s.pubkeys = sk.PublicKeyPair()
 	s.contractSwapID = ethSwapInfo.SwapID
 	s.contractSwap = ethSwapInfo.Swap
+
+	switch action {
+	case recoveryClaim:
+		// claim at t0 if the XMR taker doesn't set the contract to ready before
+		go s.runT0ExpirationHandler()
+	case recoveryWaitForRefund:
+		log.Warnf("t1 of swap %s has passed, waiting for the XMR taker to refund to reclaim the XMR",
+			info.OfferID)
+	}
+
 	return s, nil
 }
 
@@ -470,7 +395,7 @@ func (s *swapState) exit() error {
 
 		if s.info.Status != types.CompletedSuccess && s.offer.IsSet() {
 			// re-add offer, as it wasn't taken successfully
-			_, err = s.offerManager.AddOffer(s.offer, s.offerExtra.UseRelayer)
+			_, err = s.offerManager.AddOffer(s.offer, s.offerExtra.UseRelayer, s.offerExtra.Profile)
 			if err != nil {
 				log.Warnf("failed to re-add offer %s: %s", s.offer.ID, err)
 			}
@@ -488,6 +413,8 @@ func (s *swapState) exit() error {
 			log.Warnf("failed to delete temporary swap info %s from db: %s", s.offer.ID, err)
 		}
 
+		s.ClearXMRPriority(s.offer.ID)
+
 		// Stop all per-swap goroutines
 		s.cancel()
 		close(s.done)
@@ -564,14 +491,16 @@ func (s *swapState) reclaimMonero(skA *mcrypto.PrivateSpendKey) error {
 		s.xmrtakerPrivateViewKey, s.privkeys.ViewKey(),
 	)
 
+	id := s.OfferID()
 	return pcommon.ClaimMonero(
 		s.ctx,
 		s.Env(),
-		s.OfferID(),
+		id,
 		s.XMRClient(),
 		s.moneroStartHeight,
 		kpAB,
 		s.XMRClient().PrimaryAddress(),
+		s.XMRPriority(&id),
 		false, // always sweep back to our primary address
 	)
 }
@@ -655,14 +584,40 @@ func (s *swapState) lockFunds(amount *coins.PiconeroAmount) error {
 	log.Debug("total XMR balance: ", coins.FmtPiconeroAsXMR(balance.Balance))
 	log.Info("unlocked XMR balance: ", coins.FmtPiconeroAsXMR(balance.UnlockedBalance))
 
+	id := s.OfferID()
+	priority := s.XMRPriority(&id)
 	log.Infof("Starting lock of %s XMR in address %s", amount.AsMoneroString(), swapDestAddr)
-	transfer, err := s.XMRClient().Transfer(s.ctx, swapDestAddr, 0, amount, monero.MinSpendConfirmations)
+	transfer, err := s.XMRClient().Transfer(s.ctx, swapDestAddr, 0, amount, monero.MinSpendConfirmations, priority)
 	if err != nil {
 		return err
 	}
 
 	log.Infof("Successfully locked XMR funds: txID=%s address=%s block=%d",
 		transfer.TxID, swapDestAddr, transfer.Height)
+	s.info.SetStageXMRTx(transfer.TxID)
 	s.fundsLocked = true
+
+	if s.xmrtakerAcceptsLockProof {
+		s.notifyXMRLock(transfer.TxID, swapDestAddr)
+	}
 	return nil
 }
+
+// notifyXMRLock sends XMRTaker the proof of our lock transaction, so that she can
+// verify the lock without scanning for it. XMRTaker falls back to scanning if the
+// proof doesn't arrive, so failures are only logged.
+func (s *swapState) notifyXMRLock(txID string, swapDestAddr *mcrypto.Address) {
+	proof, err := s.XMRClient().GetTxProof(txID, swapDestAddr, pcommon.XMRLockProofMessage(s.OfferID()))
+	if err != nil {
+		log.Warnf("failed to get the proof of the XMR lock, XMR taker has to scan for it: %s", err)
+		return
+	}
+
+	msg := &message.NotifyXMRLock{
+		TxID:    txID,
+		TxProof: proof,
+	}
+	if err = s.SendSwapMessage(msg, s.OfferID()); err != nil {
+		log.Warnf("failed to send the proof of the XMR lock, XMR taker has to scan for it: %s", err)
+	}
+}
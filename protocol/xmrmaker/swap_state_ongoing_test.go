@@ -54,6 +54,7 @@ func TestSwapStateOngoing_ClaimFunds(t *testing.T) {
 		ethSwapInfo,
 		swapState.info,
 		swapState.privkeys,
+		recoveryClaim,
 	)
 	require.NoError(t, err)
 
@@ -131,6 +132,7 @@ func TestSwapStateOngoing_Refund(t *testing.T) {
 		ethSwapInfo,
 		s.info,
 		s.privkeys,
+		recoveryClaim,
 	)
 	require.NoError(t, err)
 
@@ -109,6 +109,7 @@ func (s *swapState) claimMonero(skB *mcrypto.PrivateSpendKey) (*mcrypto.Address,
 		s.walletScanHeight,
 		kpAB,
 		depositAddr,
+		s.XMRPriority(&id),
 		s.noTransferBack,
 	)
 	if err != nil {
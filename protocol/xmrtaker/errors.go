@@ -8,6 +8,8 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/apd/v3"
+
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 )
 
 var (
@@ -25,10 +27,11 @@ var (
 	errCounterpartyKeysNotSet  = errors.New("counterparty's keys aren't set")
 	errSwapInstantiationNoLogs = errors.New("expected 1 log, got 0")
 	errSwapCompleted           = errors.New("swap is already completed")
+	errInvalidXMRLockProof     = errors.New("proof of the XMR lock transaction is invalid")
 
 	// initiation errors
-	errProtocolAlreadyInProgress = errors.New("protocol already in progress")
-	errInvalidStageForRecovery   = errors.New("cannot create ongoing swap state if stage is not ETHLocked or ContractReady") //nolint:lll
+	errETHNodeSyncing          = errors.New("ongoing swap not found in the contract while the ethereum node is syncing")
+	errInvalidStageForRecovery = errors.New("cannot create ongoing swap state if stage is not ETHLocked or ContractReady") //nolint:lll
 )
 
 type errAssetBalanceTooLow struct {
@@ -44,6 +47,10 @@ func (e errAssetBalanceTooLow) Error() string {
 	)
 }
 
+func (e errAssetBalanceTooLow) Is(target error) bool {
+	return target == pcommon.ErrInsufficientBalance
+}
+
 func errContractAddrMismatch(addr string) error {
 	//nolint:lll
 	return fmt.Errorf("cannot recover from swap where contract address is not the one loaded at start-up; please restart with --contract-address=%s", addr)
@@ -4,8 +4,10 @@
 package xmrtaker
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	logging "github.com/ipfs/go-log"
 
@@ -19,6 +21,10 @@ import (
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
 )
 
+// ongoingSwapRetryInterval is how often the recovery of an ongoing swap is retried
+// while our ethereum node is syncing
+const ongoingSwapRetryInterval = time.Minute
+
 var (
 	log = logging.Logger("xmrtaker")
 )
@@ -85,6 +91,11 @@ func (inst *Instance) checkForOngoingSwaps() error {
 		}
 
 		err = inst.createOngoingSwap(s)
+		if errors.Is(err, errETHNodeSyncing) {
+			log.Warnf("%s, retrying in %s", err, ongoingSwapRetryInterval)
+			go inst.retryOngoingSwap(s)
+			continue
+		}
 		if err != nil {
 			log.Errorf("%s", err)
 			continue
@@ -94,6 +105,28 @@ func (inst *Instance) checkForOngoingSwaps() error {
 	return nil
 }
 
+// retryOngoingSwap creates the ongoing swap state, retrying every
+// ongoingSwapRetryInterval while our ethereum node is syncing.
+func (inst *Instance) retryOngoingSwap(s *swap.Info) {
+	for {
+		select {
+		case <-inst.backend.Ctx().Done():
+			return
+		case <-time.After(ongoingSwapRetryInterval):
+		}
+
+		err := inst.createOngoingSwap(s)
+		if errors.Is(err, errETHNodeSyncing) {
+			log.Debugf("%s, retrying in %s", err, ongoingSwapRetryInterval)
+			continue
+		}
+		if err != nil {
+			log.Errorf("%s", err)
+		}
+		return
+	}
+}
+
 func (inst *Instance) abortOngoingSwap(s *swap.Info) error {
 	// set status to aborted, delete info from recovery db
 	s.Status = types.CompletedAbort
@@ -120,6 +153,43 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		return fmt.Errorf("failed to get contract info for ongoing swap from db with offer id %s: %w", s.OfferID, err)
 	}
 
+	b, err := inst.backend.WithProfile(s.Profile)
+	if err != nil {
+		return fmt.Errorf("failed to get profile of ongoing swap, offer id %s: %w", s.OfferID, err)
+	}
+
+	// the swap may have been claimed or refunded, or reached a timeout, while we
+	// were offline
+	state, action, err := reconcileOngoingSwap(b, ethSwapInfo)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ongoing swap %s with the contract: %w", s.OfferID, err)
+	}
+
+	log.Infof("reconciled ongoing swap %s with the contract, action: %s", s.OfferID, action)
+
+	switch action {
+	case recoveryRefunded:
+		// we refunded, but exited before the swap was marked as completed
+		s.Status = types.CompletedRefund
+		if err = inst.backend.SwapManager().CompleteOngoingSwap(s); err != nil {
+			return fmt.Errorf("failed to mark swap %s as completed: %w", s.OfferID, err)
+		}
+		return inst.backend.RecoveryDB().DeleteSwap(s.OfferID)
+	case recoveryClaimXMR:
+		// the XMR maker claimed the ETH while we were offline, revealing their secret
+		if err = inst.backend.RecoveryDB().PutCounterpartySwapPrivateKey(s.OfferID, state.ClaimSecret); err != nil {
+			return err
+		}
+		return inst.completeSwap(s, state.ClaimSecret)
+	}
+
+	if status := action.status(); status != types.UnknownStatus && status != s.Status {
+		s.SetStatus(status)
+		if err = inst.backend.SwapManager().WriteSwapToDB(s); err != nil {
+			return err
+		}
+	}
+
 	sk, err := inst.backend.RecoveryDB().GetSwapPrivateKey(s.OfferID)
 	if err != nil {
 		return fmt.Errorf("failed to get private key for ongoing swap from db with offer id %s: %w",
@@ -134,11 +204,12 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 	inst.swapMu.Lock()
 	defer inst.swapMu.Unlock()
 	ss, err := newSwapStateFromOngoing(
-		inst.backend,
+		b,
 		s,
 		inst.noTransferBack,
 		ethSwapInfo,
 		kp,
+		action,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create new swap state for ongoing swap, offer id %s: %w", s.OfferID, err)
@@ -166,6 +237,11 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 // was set when the swap was started). It will also only only recover to the primary
 // wallet address, not whatever address was used when the swap was started.
 func (inst *Instance) completeSwap(s *swap.Info, skB *mcrypto.PrivateSpendKey) error {
+	b, err := inst.backend.WithProfile(s.Profile)
+	if err != nil {
+		return err
+	}
+
 	// fetch our swap private spend key
 	skA, err := inst.backend.RecoveryDB().GetSwapPrivateKey(s.OfferID)
 	if err != nil {
@@ -190,13 +266,14 @@ func (inst *Instance) completeSwap(s *swap.Info, skB *mcrypto.PrivateSpendKey) e
 	)
 
 	err = pcommon.ClaimMonero(
-		inst.backend.Ctx(),
-		inst.backend.Env(),
+		b.Ctx(),
+		b.Env(),
 		s.OfferID,
-		inst.backend.XMRClient(),
+		b.XMRClient(),
 		s.MoneroStartHeight,
 		kpAB,
-		inst.backend.XMRClient().PrimaryAddress(),
+		b.XMRClient().PrimaryAddress(),
+		b.XMRPriority(&s.OfferID),
 		inst.noTransferBack,
 	)
 	if err != nil {
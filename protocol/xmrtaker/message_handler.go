@@ -11,12 +11,14 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/fatih/color"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
 // HandleProtocolMessage is called by the network to handle an incoming message.
@@ -31,6 +33,9 @@ func (s *swapState) HandleProtocolMessage(msg common.Message) error {
 		if err != nil {
 			return err
 		}
+	case *message.NotifyXMRLock:
+		// an invalid proof doesn't end the swap, as we also scan for the lock
+		s.handleXMRLockProof(msg)
 	default:
 		return errUnexpectedMessageType
 	}
@@ -172,6 +177,9 @@ func (s *swapState) checkForXMRLock() {
 		select {
 		case <-s.ctx.Done():
 			return
+		case <-s.xmrLockedCh:
+			// the lock was verified with XMRMaker's proof of it
+			return
 		case <-timer.C:
 			balance, err := abViewCli.GetBalance(0)
 			if err != nil {
@@ -196,6 +204,72 @@ func (s *swapState) checkForXMRLock() {
 	}
 }
 
+// handleXMRLockProof handles the XMR lock if XMRMaker's proof of the lock transaction
+// shows that the expected amount was sent to the swap's address, without waiting for
+// checkForXMRLock to find the lock. If the proof is invalid, checkForXMRLock keeps
+// scanning for the lock.
+func (s *swapState) handleXMRLockProof(msg *message.NotifyXMRLock) {
+	select {
+	case <-s.xmrLockedCh:
+		log.Debugf("ignoring proof of the XMR lock, as the lock was already found")
+		return
+	default:
+	}
+
+	if err := s.checkXMRLockProof(msg); err != nil {
+		log.Warnf("failed to verify the proof of the XMR lock, scanning for the lock instead: %s", err)
+		return
+	}
+
+	log.Infof("verified the proof of the XMR lock, txID=%s", msg.TxID)
+	event := newEventXMRLocked()
+	s.eventCh <- event
+	if err := <-event.errCh; err != nil {
+		log.Errorf("eventXMRLocked errored: %s", err)
+	}
+}
+
+// checkXMRLockProof checks that the lock transaction of the proof sent at least the
+// expected amount to the swap's address, and that the amount is spendable.
+func (s *swapState) checkXMRLockProof(msg *message.NotifyXMRLock) error {
+	lockedAddr, _ := s.expectedXMRLockAccount()
+	res, err := s.XMRClient().CheckTxProof(
+		msg.TxID,
+		lockedAddr,
+		pcommon.XMRLockProofMessage(s.OfferID()),
+		msg.TxProof,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !res.Good {
+		return errInvalidXMRLockProof
+	}
+
+	if res.InPool || res.Confirmations < monero.MinSpendConfirmations {
+		return fmt.Errorf("lock transaction has %d confirmations, %d are required",
+			res.Confirmations, monero.MinSpendConfirmations)
+	}
+
+	if s.expectedPiconeroAmount().CmpU64(res.Received) > 0 {
+		return fmt.Errorf("lock transaction sent %s XMR, expected %s XMR",
+			coins.FmtPiconeroAsXMR(res.Received), s.expectedPiconeroAmount().AsMoneroString())
+	}
+
+	// the proof doesn't cover the unlock time, with which the outputs could be locked
+	// until after the swap's timeouts
+	unlockTime, err := s.XMRClient().GetTxUnlockTime(msg.TxID)
+	if err != nil {
+		return err
+	}
+	if unlockTime != 0 {
+		return fmt.Errorf("lock transaction has the unlock time %d", unlockTime)
+	}
+
+	return nil
+}
+
 func (s *swapState) runT0ExpirationHandler() {
 	defer log.Debugf("returning from runT0ExpirationHandler")
 
@@ -208,7 +282,7 @@ func (s *swapState) runT0ExpirationHandler() {
 	// may be reasonable even with large timeouts on production networks, but more
 	// research is needed.
 	t0Delta := s.t1.Sub(s.t0) // time between swap start and T0 is equal to T1-T0
-	deltaBeforeT0ToGiveUp := time.Duration(float64(t0Delta) * 0.15)
+	deltaBeforeT0ToGiveUp := time.Duration(float64(t0Delta) * pswap.RefundBeforeT0Fraction)
 	deltaUntilGiveUp := time.Until(s.t0) - deltaBeforeT0ToGiveUp
 	giveUpAndRefundTimer := time.NewTimer(deltaUntilGiveUp)
 	defer giveUpAndRefundTimer.Stop() // don't wait for the timeout to garbage collect
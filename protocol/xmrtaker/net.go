@@ -11,6 +11,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
 
 	"github.com/fatih/color"
 )
@@ -21,13 +22,24 @@ func (inst *Instance) Provides() coins.ProvidesCoin {
 }
 
 // InitiateProtocol is called when an RPC call is made from the user to initiate a swap.
-// The input units are ether that we will provide.
+// The input units are ether that we will provide. The swap is funded from the named
+// profile, or from the default profile if the name is empty.
 func (inst *Instance) InitiateProtocol(
 	makerPeerID peer.ID,
 	providesAmount *apd.Decimal,
 	offer *types.Offer,
+	profile string,
 ) (common.SwapState, error) {
-	err := coins.ValidatePositive("providesAmount", coins.NumEtherDecimals, providesAmount)
+	if inst.backend.Paused() {
+		return nil, backend.ErrPaused
+	}
+
+	b, err := inst.backend.WithProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = offer.EthAsset.ValidateAmount("providesAmount", providesAmount)
 	if err != nil {
 		return nil, err
 	}
@@ -45,9 +57,14 @@ func (inst *Instance) InitiateProtocol(
 		return nil, errAmountProvidedTooHigh{providesAmount, offer.MaxAmount}
 	}
 
+	err = pcommon.CheckEthAssetSupported(b.Ctx(), b.ETHClient(), offer.EthAsset)
+	if err != nil {
+		return nil, err
+	}
+
 	providedAmount, err := pcommon.GetEthAssetAmount(
-		inst.backend.Ctx(),
-		inst.backend.ETHClient(),
+		b.Ctx(),
+		b.ETHClient(),
 		providesAmount,
 		offer.EthAsset,
 	)
@@ -55,7 +72,7 @@ func (inst *Instance) InitiateProtocol(
 		return nil, err
 	}
 
-	state, err := inst.initiate(makerPeerID, providedAmount, coins.MoneroToPiconero(expectedAmount),
+	state, err := inst.initiate(b, makerPeerID, providedAmount, coins.MoneroToPiconero(expectedAmount),
 		offer.ExchangeRate, offer.EthAsset, offer.ID)
 	if err != nil {
 		return nil, err
@@ -65,6 +82,7 @@ func (inst *Instance) InitiateProtocol(
 }
 
 func (inst *Instance) initiate(
+	b backend.Backend,
 	makerPeerID peer.ID,
 	providesAmount coins.EthAssetAmount,
 	expectedAmount *coins.PiconeroAmount,
@@ -76,10 +94,10 @@ func (inst *Instance) initiate(
 	defer inst.swapMu.Unlock()
 
 	if inst.swapStates[offerID] != nil {
-		return nil, errProtocolAlreadyInProgress
+		return nil, pcommon.ErrProtocolAlreadyInProgress
 	}
 
-	ethBalance, err := inst.backend.ETHClient().Balance(inst.backend.Ctx())
+	ethBalance, err := b.ETHClient().Balance(b.Ctx())
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +105,7 @@ func (inst *Instance) initiate(
 	// Ensure the user's balance is strictly greater than the amount they will provide
 	if ethAsset.IsETH() && ethBalance.Cmp(providesAmount.(*coins.WeiAmount)) <= 0 {
 		log.Warnf("Account %s needs additional funds for swap balance=%s ETH providesAmount=%s ETH",
-			inst.backend.ETHClient().Address(), ethBalance.AsEtherString(), providesAmount.AsStandard())
+			b.ETHClient().Address(), ethBalance.AsEtherString(), providesAmount.AsStandard())
 		return nil, errAssetBalanceTooLow{
 			providedAmount: providesAmount.AsStandard(),
 			balance:        ethBalance.AsEther(),
@@ -96,7 +114,7 @@ func (inst *Instance) initiate(
 	}
 
 	if ethAsset.IsToken() {
-		tokenBalance, err := inst.backend.ETHClient().ERC20Balance(inst.backend.Ctx(), ethAsset.Address()) //nolint:govet
+		tokenBalance, err := b.ETHClient().ERC20Balance(b.Ctx(), ethAsset.Address()) //nolint:govet
 		if err != nil {
 			return nil, err
 		}
@@ -111,7 +129,7 @@ func (inst *Instance) initiate(
 	}
 
 	s, err := newSwapStateFromStart(
-		inst.backend,
+		b,
 		makerPeerID,
 		offerID,
 		inst.noTransferBack,
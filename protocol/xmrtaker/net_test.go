@@ -40,7 +40,7 @@ func initiate(
 		coins.ToExchangeRate(apd.New(1, 0)),
 		types.EthAssetETH,
 	)
-	s, err := xmrtaker.InitiateProtocol(testPeerID, providesAmount, offer)
+	s, err := xmrtaker.InitiateProtocol(testPeerID, providesAmount, offer, "")
 	return offer, s, err
 }
 
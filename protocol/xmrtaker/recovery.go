@@ -0,0 +1,145 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrtaker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+)
+
+// recoveryAction is what to do with an ongoing swap found in the db on startup,
+// given the state of the swap in the contract.
+type recoveryAction byte
+
+const (
+	// recoveryResume resumes a swap that our synced node doesn't find in the
+	// contract with its stored status. The node may lag behind the chain, and our
+	// ETH may be locked, so the swap is never aborted.
+	recoveryResume recoveryAction = iota
+	// recoveryRefunded marks the swap as refunded, as we refunded before exiting.
+	recoveryRefunded
+	// recoveryClaimXMR claims the XMR with the secret revealed by the XMR
+	// maker's claim.
+	recoveryClaimXMR
+	// recoveryWaitForXMRLock resumes a pending swap before t0, waiting for the
+	// XMR lock to set the contract to ready, or refunding if it isn't locked
+	// before t0.
+	recoveryWaitForXMRLock
+	// recoveryWaitForClaim resumes a swap that is ready, or pending after t0,
+	// waiting for the XMR maker's claim until t1, when we refund.
+	recoveryWaitForClaim
+	// recoveryRefund resumes the swap to refund it, as t1 has passed.
+	recoveryRefund
+)
+
+func (a recoveryAction) String() string {
+	switch a {
+	case recoveryResume:
+		return "resume"
+	case recoveryRefunded:
+		return "refunded"
+	case recoveryClaimXMR:
+		return "claim XMR"
+	case recoveryWaitForXMRLock:
+		return "wait for XMR lock"
+	case recoveryWaitForClaim:
+		return "wait for claim"
+	case recoveryRefund:
+		return "refund"
+	default:
+		return "unknown"
+	}
+}
+
+// status returns the status of a swap resumed with the action.
+func (a recoveryAction) status() types.Status {
+	switch a {
+	case recoveryWaitForXMRLock:
+		return types.ETHLocked
+	case recoveryWaitForClaim:
+		return types.ContractReady
+	default:
+		return types.UnknownStatus
+	}
+}
+
+// getRecoveryAction returns what to do with an ongoing swap with the given
+// contract state and timeouts, at the given time of the latest block.
+func getRecoveryAction(
+	state *pcommon.ContractSwapState,
+	t0 time.Time,
+	t1 time.Time,
+	now time.Time,
+) (recoveryAction, error) {
+	switch state.Stage {
+	case contracts.StageInvalid:
+		return recoveryResume, nil
+	case contracts.StageCompleted:
+		if state.RefundSecret != nil {
+			return recoveryRefunded, nil
+		}
+		return recoveryClaimXMR, nil
+	case contracts.StagePending, contracts.StageReady:
+		// the contract can be refunded after t1 whatever its stage, and claimed
+		// between t0 and t1 even if it isn't ready
+		if !now.Before(t1) {
+			return recoveryRefund, nil
+		}
+		if state.Stage == contracts.StageReady || !now.Before(t0) {
+			return recoveryWaitForClaim, nil
+		}
+		return recoveryWaitForXMRLock, nil
+	default:
+		return 0, fmt.Errorf("unhandled contract stage %d", state.Stage)
+	}
+}
+
+// reconcileOngoingSwap returns the contract state of an ongoing swap found in the
+// db on startup, and what to do with the swap. If the swap isn't in the contract
+// while our ethereum node is syncing, errETHNodeSyncing is returned, and the swap
+// should be reconciled again once the node has synced.
+func reconcileOngoingSwap(
+	b backend.Backend,
+	ethSwapInfo *db.EthereumSwapInfo,
+) (*pcommon.ContractSwapState, recoveryAction, error) {
+	state, err := pcommon.GetContractSwapState(b, ethSwapInfo)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if state.Stage == contracts.StageInvalid {
+		progress, syncErr := b.ETHClient().Raw().SyncProgress(b.Ctx())
+		if syncErr != nil {
+			return nil, 0, syncErr
+		}
+
+		if progress != nil {
+			return nil, 0, fmt.Errorf("%w (block %d of %d): contract swap ID: %s",
+				errETHNodeSyncing, progress.CurrentBlock, progress.HighestBlock, ethSwapInfo.SwapID)
+		}
+	}
+
+	ts, err := b.ETHClient().LatestBlockTimestamp(b.Ctx())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	action, err := getRecoveryAction(
+		state,
+		time.Unix(ethSwapInfo.Swap.Timeout0.Int64(), 0),
+		time.Unix(ethSwapInfo.Swap.Timeout1.Int64(), 0),
+		ts,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: contract swap ID: %s", err, ethSwapInfo.SwapID)
+	}
+
+	return state, action, nil
+}
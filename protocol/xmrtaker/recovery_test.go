@@ -0,0 +1,107 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrtaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+)
+
+func TestGetRecoveryAction(t *testing.T) {
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	secret := kp.SpendKey()
+
+	t0 := time.Now()
+	t1 := t0.Add(time.Hour)
+	beforeT0 := t0.Add(-time.Minute)
+	betweenT0AndT1 := t0.Add(time.Minute)
+	afterT1 := t1.Add(time.Minute)
+
+	type entry struct {
+		name   string
+		state  *pcommon.ContractSwapState
+		now    time.Time
+		action recoveryAction
+	}
+	testEntries := []entry{
+		{
+			name:   "swap doesn't exist",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageInvalid},
+			now:    afterT1,
+			action: recoveryResume,
+		},
+		{
+			name:   "refunded",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageCompleted, RefundSecret: secret},
+			now:    beforeT0,
+			action: recoveryRefunded,
+		},
+		{
+			name:   "claimed",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageCompleted, ClaimSecret: secret},
+			now:    betweenT0AndT1,
+			action: recoveryClaimXMR,
+		},
+		{
+			name:   "pending before t0",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StagePending},
+			now:    beforeT0,
+			action: recoveryWaitForXMRLock,
+		},
+		{
+			name:   "pending at t0",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StagePending},
+			now:    t0,
+			action: recoveryWaitForClaim,
+		},
+		{
+			name:   "ready before t0",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageReady},
+			now:    beforeT0,
+			action: recoveryWaitForClaim,
+		},
+		{
+			name:   "ready between t0 and t1",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageReady},
+			now:    betweenT0AndT1,
+			action: recoveryWaitForClaim,
+		},
+		{
+			name:   "pending after t1",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StagePending},
+			now:    afterT1,
+			action: recoveryRefund,
+		},
+		{
+			name:   "ready at t1",
+			state:  &pcommon.ContractSwapState{Stage: contracts.StageReady},
+			now:    t1,
+			action: recoveryRefund,
+		},
+	}
+
+	for _, e := range testEntries {
+		action, err := getRecoveryAction(e.state, t0, t1, e.now)
+		require.NoError(t, err, e.name)
+		require.Equal(t, e.action, action, e.name)
+	}
+
+	_, err = getRecoveryAction(&pcommon.ContractSwapState{Stage: 4}, t0, t1, beforeT0)
+	require.ErrorContains(t, err, "unhandled contract stage 4")
+}
+
+func TestRecoveryAction_status(t *testing.T) {
+	require.Equal(t, types.ETHLocked, recoveryWaitForXMRLock.status())
+	require.Equal(t, types.ContractReady, recoveryWaitForClaim.status())
+	require.Equal(t, types.UnknownStatus, recoveryRefund.status())
+	require.Equal(t, types.UnknownStatus, recoveryResume.status())
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrtaker
+
+import (
+	"math/big"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/relayer"
+)
+
+var refundedTopic = common.GetTopic(common.RefundedEventSignature)
+
+// canPayRefundGas returns whether our balance covers the gas cost of refunding
+// the swap with our own transaction. Refunds of ETH swaps can be relayed too, as
+// the relayer fee is paid out of the refunded asset.
+func (s *swapState) canPayRefundGas() bool {
+	return s.canPayGas("refund", contracts.RefundRelayerMethod, func() (uint64, error) {
+		return s.estimateGas("refund", *s.contractSwap, s.getSecret())
+	})
+}
+
+// refundWithRelay relays the refund with our configured relayer fee, which is paid
+// out of the refunded asset.
+func (s *swapState) refundWithRelay() (*ethtypes.Receipt, error) {
+	fee, forwarderAddr, err := s.relayerFeeAndForwarder(s.contractSwap.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.relayWithRelayers("refund", func(relayerPeerID peer.ID) (*ethtypes.Receipt, error) {
+		return s.refundWithRelayer(relayerPeerID, forwarderAddr, fee)
+	})
+}
+
+// refundWithRelayer submits the refund to the relayer and waits for the relayer's
+// transaction to refund the swap.
+func (s *swapState) refundWithRelayer(
+	relayerPeerID peer.ID,
+	forwarderAddr ethcommon.Address,
+	feeWei *big.Int,
+) (*ethtypes.Receipt, error) {
+	secret := s.getSecret()
+
+	request, err := relayer.CreateRelayRefundRequest(
+		s.ctx,
+		s.ETHClient().Signer(),
+		s.ETHClient().Raw(),
+		s.SwapCreatorAddr(),
+		forwarderAddr,
+		s.contractSwap,
+		&secret,
+		feeWei,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.Backend.SubmitRefundToRelayer(relayerPeerID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.waitForRelayedReceipt(resp.TxHash, refundedTopic, "refund the swap")
+}
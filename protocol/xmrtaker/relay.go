@@ -0,0 +1,294 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrtaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/relayer"
+)
+
+// directNewSwapGas is the worst case gas of creating a token swap with our own
+// newSwapWithPermit transaction. The gas can't be estimated before the permit is
+// signed, so this upper bound decides whether our balance can pay for it.
+const directNewSwapGas = 200000
+
+var readyTopic = common.GetTopic(common.ReadyEventSignature)
+
+// canPayGas returns whether our balance covers the gas cost of the swap
+// transaction, whose gas is returned by estimateGas, when sent by us. Swaps signed
+// by an external signer always send their own transactions, as only swapd's own
+// key can sign relay requests, as do swaps in SwapCreator contracts deployed
+// without relayMethod. If the gas cost can't be estimated, the balance is assumed
+// to cover it, so the transaction fails with the reason.
+func (s *swapState) canPayGas(kind string, relayMethod string, estimateGas func() (uint64, error)) bool {
+	if !s.ETHClient().HasSigner() {
+		return true
+	}
+
+	hasRelayMethod, err := contracts.SwapCreatorHasMethod(s.ctx, s.ETHClient().Raw(), s.SwapCreatorAddr(),
+		relayMethod)
+	if err != nil {
+		log.Warnf("failed to check the SwapCreator contract for relayed %s calls, sending directly: %s", kind, err)
+		return true
+	}
+
+	if !hasRelayMethod {
+		return true
+	}
+
+	balance, err := s.ETHClient().Balance(s.ctx)
+	if err != nil {
+		log.Warnf("failed to get balance, sending %s directly: %s", kind, err)
+		return true
+	}
+
+	gas, err := estimateGas()
+	if err != nil {
+		log.Warnf("failed to estimate the gas of a direct %s: %s", kind, err)
+		return true
+	}
+
+	gasPrice, err := s.ETHClient().SuggestGasPrice(s.ctx)
+	if err != nil {
+		log.Warnf("failed to get the gas price of a direct %s: %s", kind, err)
+		return true
+	}
+
+	gasCost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
+	if balance.BigInt().Cmp(gasCost) < 0 {
+		log.Infof("balance %s ETH is under the %s ETH gas cost of a direct %s, relaying the %s",
+			balance.AsEtherString(), coins.FmtWeiAsETH(gasCost), kind, kind)
+		return false
+	}
+
+	return true
+}
+
+// estimateGas returns the estimated gas of calling the SwapCreator method with our
+// own transaction.
+func (s *swapState) estimateGas(method string, args ...interface{}) (uint64, error) {
+	callData, err := contracts.SwapCreatorParsedABI.Pack(method, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	swapCreatorAddr := s.SwapCreatorAddr()
+	return s.ETHClient().Raw().EstimateGas(s.ctx, ethereum.CallMsg{
+		From: s.ETHClient().Address(),
+		To:   &swapCreatorAddr,
+		Data: callData,
+	})
+}
+
+// canPayNewSwapGas returns whether our balance covers the gas cost of creating the
+// swap with our own transaction. Only token swaps can be created by a relayer, as
+// the relayer fee is paid in the token.
+func (s *swapState) canPayNewSwapGas() bool {
+	if !s.info.EthAsset.IsToken() {
+		return true
+	}
+
+	return s.canPayGas("new swap", contracts.NewSwapRelayerMethod, func() (uint64, error) {
+		return directNewSwapGas, nil
+	})
+}
+
+// canPaySetReadyGas returns whether our balance covers the gas cost of setting the
+// token swap ready with our own transaction.
+func (s *swapState) canPaySetReadyGas() bool {
+	if !s.info.EthAsset.IsToken() {
+		return true
+	}
+
+	return s.canPayGas("set ready", contracts.SetReadyRelayerMethod, func() (uint64, error) {
+		return s.estimateGas("setReady", *s.contractSwap)
+	})
+}
+
+// relayWithRelayers relays a swap transaction with the relayers advertising in the
+// DHT, other than our swap counterparty, trying each of them in turn with relay
+// until one of them sends the transaction. The forwarder nonce is used up by a
+// failed relayed transaction, so relay signs the request anew for each relayer.
+// Note that the receipt returned is for a transaction created by the remote
+// relayer, not by us.
+func (s *swapState) relayWithRelayers(
+	kind string,
+	relay func(relayerPeerID peer.ID) (*ethtypes.Receipt, error),
+) (*ethtypes.Receipt, error) {
+	relayers, err := s.Backend.DiscoverRelayers()
+	if err != nil {
+		return nil, err
+	}
+
+	lastErr := fmt.Errorf("no relayers found to submit %s to", kind)
+
+	for _, relayerPeerID := range relayers {
+		if relayerPeerID == s.info.PeerID {
+			continue
+		}
+
+		receipt, err := relay(relayerPeerID)
+		if err == nil {
+			return receipt, nil
+		}
+		if errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+		log.Warnf("failed to relay %s with relayer %s: %s", kind, relayerPeerID, err)
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("failed to relay %s: %w", kind, lastErr)
+}
+
+// relayerFeeAndForwarder returns our configured relayer fee for the swap value,
+// paid in the swap's asset, and the forwarder trusted by the SwapCreator contract.
+func (s *swapState) relayerFeeAndForwarder(value *big.Int) (*big.Int, ethcommon.Address, error) {
+	forwarderAddr, err := s.SwapCreator().TrustedForwarder(&bind.CallOpts{Context: s.ctx})
+	if err != nil {
+		return nil, ethcommon.Address{}, err
+	}
+
+	fee, err := s.RelayerFee().AssetFee(s.ctx, s.ETHClient().Raw(), s.info.EthAsset, value)
+	if err != nil {
+		return nil, ethcommon.Address{}, err
+	}
+
+	return fee, forwarderAddr, nil
+}
+
+// newSwapWithRelay relays the creation of the token swap with our relayer fee,
+// which is paid out of our token balance on top of the swap value.
+func (s *swapState) newSwapWithRelay(
+	pubKeyClaim [32]byte,
+	pubKeyRefund [32]byte,
+	timeoutDuration *big.Int,
+	nonce *big.Int,
+) (*ethtypes.Receipt, error) {
+	value := s.providedAmount.BigInt()
+	fee, forwarderAddr, err := s.relayerFeeAndForwarder(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.relayWithRelayers("new swap", func(relayerPeerID peer.ID) (*ethtypes.Receipt, error) {
+		request, err := relayer.CreateRelayNewSwapRequest(
+			s.ctx,
+			s.ETHClient().Signer(),
+			s.ETHClient().Raw(),
+			s.SwapCreatorAddr(),
+			forwarderAddr,
+			pubKeyClaim,
+			pubKeyRefund,
+			s.xmrmakerAddress,
+			timeoutDuration,
+			s.info.EthAsset,
+			value,
+			nonce,
+			fee,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.Backend.SubmitNewSwapToRelayer(relayerPeerID, request)
+		if err != nil {
+			return nil, err
+		}
+
+		receipt, err := block.WaitForReceipt(s.ctx, s.ETHClient().Raw(), resp.TxHash)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = s.newSwapLog(receipt); err != nil {
+			return nil, fmt.Errorf("relayer's transaction did not create the swap (tx=%s)", receipt.TxHash)
+		}
+
+		return receipt, nil
+	})
+}
+
+// setReadyWithRelay relays the setReady call of the token swap with our relayer
+// fee, which is paid out of our token balance.
+func (s *swapState) setReadyWithRelay() (*ethtypes.Receipt, error) {
+	fee, forwarderAddr, err := s.relayerFeeAndForwarder(s.contractSwap.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.relayWithRelayers("set ready", func(relayerPeerID peer.ID) (*ethtypes.Receipt, error) {
+		request, err := relayer.CreateRelaySetReadyRequest(
+			s.ctx,
+			s.ETHClient().Signer(),
+			s.ETHClient().Raw(),
+			s.SwapCreatorAddr(),
+			forwarderAddr,
+			s.contractSwap,
+			fee,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.Backend.SubmitSetReadyToRelayer(relayerPeerID, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.waitForRelayedReceipt(resp.TxHash, readyTopic, "set the swap ready")
+	})
+}
+
+// newSwapLog returns the New log of the SwapCreator contract in the receipt.
+func (s *swapState) newSwapLog(receipt *ethtypes.Receipt) (*ethtypes.Log, error) {
+	newTopic := contracts.SwapCreatorParsedABI.Events["New"].ID
+	for _, l := range receipt.Logs {
+		if l.Address == s.SwapCreatorAddr() && len(l.Topics) > 0 && l.Topics[0] == newTopic {
+			return l, nil
+		}
+	}
+
+	return nil, errSwapInstantiationNoLogs
+}
+
+// waitForRelayedReceipt waits for the relayer's transaction and returns its
+// receipt if it emitted the SwapCreator event with the topic for our swap.
+// action describes the expected effect of the transaction for the error.
+func (s *swapState) waitForRelayedReceipt(
+	txHash ethcommon.Hash,
+	topic ethcommon.Hash,
+	action string,
+) (*ethtypes.Receipt, error) {
+	receipt, err := block.WaitForReceipt(s.ctx, s.ETHClient().Raw(), txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range receipt.Logs {
+		if l.Address != s.SwapCreatorAddr() {
+			continue
+		}
+		if pcommon.CheckSwapID(l, topic, s.contractSwapID) == nil {
+			return receipt, nil
+		}
+	}
+
+	return nil, fmt.Errorf("relayer's transaction did not %s (tx=%s)", action, receipt.TxHash)
+}
@@ -135,6 +135,7 @@ func newSwapStateFromStart(
 		moneroStartNumber,
 		statusCh,
 	)
+	info.Profile = b.ProfileName()
 	if err = b.SwapManager().AddSwap(info); err != nil {
 		return nil, err
 	}
@@ -150,6 +151,11 @@ func newSwapStateFromStart(
 		return nil, err
 	}
 
+	if err = s.generateAndSetKeys(); err != nil {
+		s.cancel()
+		return nil, err
+	}
+
 	statusCh <- stage
 	return s, nil
 }
@@ -160,6 +166,7 @@ func newSwapStateFromOngoing(
 	noTransferBack bool,
 	ethSwapInfo *db.EthereumSwapInfo,
 	sk *mcrypto.PrivateKeyPair,
+	action recoveryAction,
 ) (*swapState, error) {
 	if info.Status != types.ETHLocked && info.Status != types.ContractReady {
 		return nil, errInvalidStageForRecovery
@@ -193,9 +200,16 @@ func newSwapStateFromOngoing(
 	s.xmrmakerPublicSpendKey = makerSk
 	s.xmrmakerPrivateViewKey = makerVk
 
-	if info.Status == types.ETHLocked {
+	switch action {
+	case recoveryWaitForXMRLock:
 		go s.checkForXMRLock()
+		go s.runT0ExpirationHandler()
+	case recoveryWaitForClaim:
+		go s.runT1ExpirationHandler()
+	case recoveryRefund:
+		go s.handleT1Expired()
 	}
+
 	return s, nil
 }
 
@@ -211,7 +225,7 @@ func newSwapState(
 	// decision and set it back to `false`, because an external signer (UI) must
 	// be used, which will prompt the user to set their XMR address for funds to
 	// be transferred-back to.
-	if !b.ETHClient().HasPrivateKey() {
+	if !b.ETHClient().HasSigner() {
 		noTransferBack = false // front-end must set final deposit address
 	}
 
@@ -294,11 +308,6 @@ func newSwapState(
 		statusCh:          info.StatusCh(),
 	}
 
-	if err := s.generateAndSetKeys(); err != nil {
-		cancel()
-		return nil, err
-	}
-
 	go s.runHandleEvents()
 	go s.runContractEventWatcher()
 	return s, nil
@@ -311,6 +320,7 @@ func (s *swapState) SendKeysMessage() common.Message {
 		PrivateViewKey:     s.privkeys.ViewKey(),
 		DLEqProof:          s.dleqProof.Proof(),
 		Secp256k1PublicKey: s.secp256k1Pub,
+		XMRLockProof:       true,
 	}
 }
 
@@ -353,6 +363,8 @@ func (s *swapState) exit() error {
 			log.Warnf("failed to delete temporary swap info %s from db: %s", s.OfferID(), err)
 		}
 
+		s.ClearXMRPriority(s.OfferID())
+
 		// Stop all per-swap goroutines
 		s.cancel()
 		close(s.done)
@@ -523,10 +535,14 @@ func (s *swapState) generateAndSetKeys() error {
 
 // getSecret secrets returns the current secret scalar used to unlock funds from the contract.
 func (s *swapState) getSecret() [32]byte {
-	secret := s.dleqProof.Secret()
-	var sc [32]byte
-	copy(sc[:], secret[:])
-	return sc
+	if s.dleqProof != nil {
+		return s.dleqProof.Secret()
+	}
+
+	// the swap was recovered from the db, which only stores our private spend key
+	var secret [32]byte
+	copy(secret[:], common.Reverse(s.privkeys.SpendKeyBytes()))
+	return secret
 }
 
 // setXMRMakerKeys sets XMRMaker's public spend key (to be stored in the contract) and XMRMaker's
@@ -543,6 +559,8 @@ func (s *swapState) setXMRMakerKeys(
 }
 
 // lockAsset calls the Swap contract function new_swap and locks `amount` ether in it.
+// If our balance can't pay for the gas of a token swap, its creation is relayed for
+// a fee paid in the token.
 func (s *swapState) lockAsset() (*ethtypes.Receipt, error) {
 	if s.xmrmakerPublicSpendKey == nil || s.xmrmakerPrivateViewKey == nil {
 		panic(errCounterpartyKeysNotSet)
@@ -551,47 +569,53 @@ func (s *swapState) lockAsset() (*ethtypes.Receipt, error) {
 	cmtXMRTaker := s.secp256k1Pub.Keccak256()
 	cmtXMRMaker := s.xmrmakerSecp256k1PublicKey.Keccak256()
 	providedAmt := s.providedAmount
+	timeoutDuration := big.NewInt(int64(s.SwapTimeout().Seconds()))
 
 	log.Debugf("locking %s %s in contract", providedAmt.AsStandard(), providedAmt.StandardSymbol())
 
 	nonce := generateNonce()
-	receipt, err := s.sender.NewSwap(
-		cmtXMRMaker,
-		cmtXMRTaker,
-		s.xmrmakerAddress,
-		big.NewInt(int64(s.SwapTimeout().Seconds())),
-		nonce,
-		providedAmt,
+	relayed := !s.canPayNewSwapGas()
+
+	var (
+		receipt *ethtypes.Receipt
+		err     error
 	)
+	if relayed {
+		receipt, err = s.newSwapWithRelay(cmtXMRMaker, cmtXMRTaker, timeoutDuration, nonce)
+	} else {
+		receipt, err = s.sender.NewSwap(
+			cmtXMRMaker,
+			cmtXMRTaker,
+			s.xmrmakerAddress,
+			timeoutDuration,
+			nonce,
+			providedAmt,
+		)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to instantiate swap on-chain: %w", err)
 	}
 
-	log.Infof("instantiated swap on-chain: amount=%s asset=%s %s",
-		s.providedAmount, s.info.EthAsset, common.ReceiptInfo(receipt))
-
-	if len(receipt.Logs) == 0 {
-		return nil, errSwapInstantiationNoLogs
+	log.Infof("instantiated swap on-chain: amount=%s asset=%s relayed=%t %s",
+		s.providedAmount, s.info.EthAsset, relayed, common.ReceiptInfo(receipt))
+	if !relayed {
+		s.info.AddGasPaid(receipt)
 	}
+	s.info.SetStageETHTx(receipt.TxHash)
 
-	for _, rLog := range receipt.Logs {
-		s.contractSwapID, err = contracts.GetIDFromLog(rLog)
-		if err == nil {
-			break
-		}
+	// The relayer's transaction has logs of the token and forwarder contracts too,
+	// so only the SwapCreator's New log is used.
+	newLog, err := s.newSwapLog(receipt)
+	if err != nil {
+		return nil, err
 	}
+
+	s.contractSwapID, err = contracts.GetIDFromLog(newLog)
 	if err != nil {
 		return nil, fmt.Errorf("swap ID not found in transaction receipt's logs: %w", err)
 	}
 
-	var t0 *big.Int
-	var t1 *big.Int
-	for _, log := range receipt.Logs {
-		t0, t1, err = contracts.GetTimeoutsFromLog(log)
-		if err == nil {
-			break
-		}
-	}
+	t0, t1, err := contracts.GetTimeoutsFromLog(newLog)
 	if err != nil {
 		return nil, fmt.Errorf("timeouts not found in transaction receipt's logs: %w", err)
 	}
@@ -611,6 +635,11 @@ func (s *swapState) lockAsset() (*ethtypes.Receipt, error) {
 		Nonce:        nonce,
 	}
 
+	if relayed && s.contractSwap.SwapID() != s.contractSwapID {
+		return nil, fmt.Errorf("relayer's transaction created swap %s instead of %s (tx=%s)",
+			s.contractSwapID, s.contractSwap.SwapID(), receipt.TxHash)
+	}
+
 	ethInfo := &db.EthereumSwapInfo{
 		StartNumber:     receipt.BlockNumber,
 		SwapID:          s.contractSwapID,
@@ -639,6 +668,19 @@ func (s *swapState) ready() error {
 		return fmt.Errorf("cannot set contract to ready when swap stage is %s", contracts.StageToString(stage))
 	}
 
+	if !s.canPaySetReadyGas() {
+		relayedReceipt, relayErr := s.setReadyWithRelay()
+		if relayErr != nil {
+			// XMRMaker can still claim after t0 without the swap being set ready
+			log.Warnf("failed to relay set ready, XMRMaker can claim after t0: %s", relayErr)
+			return nil
+		}
+
+		log.Infof("contract set to ready by relayer %s", common.ReceiptInfo(relayedReceipt))
+		s.info.SetStageETHTx(relayedReceipt.TxHash)
+		return nil
+	}
+
 	receipt, err := s.sender.SetReady(s.contractSwap)
 	if err != nil {
 		if strings.Contains(err.Error(), revertSwapCompleted) && !s.info.Status.IsOngoing() {
@@ -648,6 +690,8 @@ func (s *swapState) ready() error {
 	}
 
 	log.Infof("contract set to ready %s", common.ReceiptInfo(receipt))
+	s.info.AddGasPaid(receipt)
+	s.info.SetStageETHTx(receipt.TxHash)
 
 	return nil
 }
@@ -655,15 +699,30 @@ func (s *swapState) ready() error {
 // refund calls the Refund() method in the Swap contract, revealing XMRTaker's secret
 // and returns to her the ether in the contract.
 // If time t_1 passes and Claim() has not been called, XMRTaker should call Refund().
+// If her balance can't pay for the refund's gas, the refund is relayed for a fee.
 func (s *swapState) refund() (*ethtypes.Receipt, error) {
 	sc := s.getSecret()
 
+	if !s.canPayRefundGas() {
+		receipt, err := s.refundWithRelay()
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("refund transaction was relayed: %s", common.ReceiptInfo(receipt))
+		s.info.SetStageETHTx(receipt.TxHash)
+
+		s.clearNextExpectedEvent(types.CompletedRefund)
+		return receipt, nil
+	}
+
 	log.Infof("attempting to call Refund()...")
 	receipt, err := s.sender.Refund(s.contractSwap, sc)
 	if err != nil {
 		return nil, err
 	}
 	log.Infof("refund succeeded %s", common.ReceiptInfo(receipt))
+	s.info.AddGasPaid(receipt)
+	s.info.SetStageETHTx(receipt.TxHash)
 
 	s.clearNextExpectedEvent(types.CompletedRefund)
 	return receipt, nil
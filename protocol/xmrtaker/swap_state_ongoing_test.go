@@ -71,6 +71,7 @@ func TestSwapStateOngoing_handleEvent_EventXMRLocked(t *testing.T) {
 		s.noTransferBack,
 		ethInfo,
 		s.privkeys,
+		recoveryWaitForXMRLock,
 	)
 	require.NoError(t, err)
 	require.Equal(t, EventXMRLockedType, ss.nextExpectedEvent)
@@ -98,6 +99,7 @@ func TestSwapStateOngoing_handleEvent_EventETHClaimed(t *testing.T) {
 		s.noTransferBack,
 		ethInfo,
 		s.privkeys,
+		recoveryWaitForXMRLock,
 	)
 	require.NoError(t, err)
 	require.Equal(t, EventXMRLockedType, ss.nextExpectedEvent)
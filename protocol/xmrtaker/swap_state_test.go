@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/MarinX/monerorpc/wallet"
 	"github.com/cockroachdb/apd/v3"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -69,6 +70,28 @@ func (n *mockNet) SubmitClaimToRelayer(_ peer.ID, _ *message.RelayClaimRequest)
 	return new(message.RelayClaimResponse), nil
 }
 
+func (n *mockNet) SubmitRefundToRelayer(_ peer.ID, _ *message.RelayRefundRequest) (*message.RelayClaimResponse, error) {
+	return new(message.RelayClaimResponse), nil
+}
+
+func (n *mockNet) SubmitNewSwapToRelayer(
+	_ peer.ID,
+	_ *message.RelayNewSwapRequest,
+) (*message.RelayClaimResponse, error) {
+	return new(message.RelayClaimResponse), nil
+}
+
+func (n *mockNet) SubmitSetReadyToRelayer(
+	_ peer.ID,
+	_ *message.RelaySetReadyRequest,
+) (*message.RelayClaimResponse, error) {
+	return new(message.RelayClaimResponse), nil
+}
+
+func (n *mockNet) QueryRelayerFee(_ peer.ID, _ *message.RelayFeeQuoteRequest) (*message.RelayFeeQuote, error) {
+	return new(message.RelayFeeQuote), nil
+}
+
 func (n *mockNet) CloseProtocolStream(_ types.Hash) {}
 
 func newSwapManager(t *testing.T) pswap.Manager {
@@ -78,7 +101,7 @@ func newSwapManager(t *testing.T) pswap.Manager {
 	db.EXPECT().GetAllSwaps()
 	db.EXPECT().PutSwap(gomock.Any()).AnyTimes()
 
-	sm, err := pswap.NewManager(db)
+	sm, err := pswap.NewManager(db, nil)
 	require.NoError(t, err)
 	return sm
 }
@@ -278,7 +301,7 @@ func lockXMRFunds(
 	amount *coins.PiconeroAmount,
 ) {
 	monero.MineMinXMRBalance(t, wc, amount)
-	_, err := wc.Transfer(ctx, destAddr, 0, amount, monero.MinSpendConfirmations)
+	_, err := wc.Transfer(ctx, destAddr, 0, amount, monero.MinSpendConfirmations, wallet.PriorityDefault)
 	require.NoError(t, err)
 }
 
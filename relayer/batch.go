@@ -0,0 +1,381 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/metrics"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	// DefaultBatchWindow is the default time that a claim waits for other claims
+	// to share its transaction. Together with the time to include the batch, it
+	// must stay well under the claimer's timeout for the relay response.
+	DefaultBatchWindow = 5 * time.Second
+
+	// DefaultMaxBatchSize is the default maximum number of claims in a batch.
+	DefaultMaxBatchSize = 10
+)
+
+var (
+	errClaimFailedInBatch = errors.New("relayed claim failed on batch simulation")
+	errClaimNonceInBatch  = errors.New("claimer already has a claim with this forwarder nonce in the batch")
+)
+
+// BatchConfig holds the settings of a ClaimBatcher.
+type BatchConfig struct {
+	// Window is the time that the first claim of a batch waits for more claims
+	// before the batch is sent.
+	Window time.Duration
+
+	// MaxSize is the number of claims at which a batch is sent without waiting
+	// for the window to end.
+	MaxSize int
+}
+
+// ClaimBatcher aggregates the relayed claims that arrive within a short window into
+// one Multicall3 transaction, so the transaction base cost is paid once per batch
+// instead of once per claim. Each claim still pays its own relayer fee, as
+// claimRelayer pays the fee to tx.origin. A claimer can have several claims in a
+// batch by signing them with consecutive forwarder nonces, starting with its
+// current nonce. They are executed in the order of their nonces.
+type ClaimBatcher struct {
+	ctx             context.Context
+	ec              extethclient.EthClient
+	multicall       *contracts.Multicall3
+	swapCreatorAddr ethcommon.Address
+	feeConfig       *FeeConfig
+	conf            *BatchConfig
+
+	mu         sync.Mutex
+	pending    []*batchEntry
+	flushTimer *time.Timer
+}
+
+type batchEntry struct {
+	claim  *preparedClaim
+	gas    uint64
+	result chan *batchResult
+}
+
+type batchResult struct {
+	claim *RelayedClaim
+	err   error
+}
+
+// NewClaimBatcher returns a ClaimBatcher for relaying claims with our fee
+// configuration. It returns an error if Multicall3 is not deployed on the chain.
+func NewClaimBatcher(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	swapCreatorAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+	conf *BatchConfig,
+) (*ClaimBatcher, error) {
+	if conf.Window <= 0 || conf.MaxSize < 2 {
+		return nil, fmt.Errorf("invalid claim batch window %s or size %d", conf.Window, conf.MaxSize)
+	}
+
+	deployed, err := contracts.Multicall3Deployed(ctx, ec.Raw(), contracts.Multicall3Address)
+	if err != nil {
+		return nil, err
+	}
+	if !deployed {
+		return nil, fmt.Errorf("Multicall3 is not deployed at %s, claims cannot be batched", //nolint:revive
+			contracts.Multicall3Address)
+	}
+
+	multicall, err := contracts.NewMulticall3(contracts.Multicall3Address, ec.Raw())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClaimBatcher{
+		ctx:             ctx,
+		ec:              ec,
+		multicall:       multicall,
+		swapCreatorAddr: swapCreatorAddr,
+		feeConfig:       feeConfig,
+		conf:            conf,
+	}, nil
+}
+
+// Submit validates the claim request and adds it to the current batch. It returns
+// once the batch transaction is included, with the claim's share of its gas cost.
+func (b *ClaimBatcher) Submit(req *message.RelayClaimRequest) (*RelayedClaim, error) {
+	claim, err := prepareClaim(b.ctx, req, b.ec, b.swapCreatorAddr, b.feeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &batchEntry{
+		claim:  claim,
+		result: make(chan *batchResult, 1),
+	}
+
+	// A claim following the claimer's pending claims can't be simulated on its own,
+	// as the forwarder only accepts the claimer's current nonce, so it is only
+	// simulated as part of the batch.
+	queued, err := b.enqueueFollowing(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if !queued {
+		txOpts := &bind.TransactOpts{From: b.ec.Address()}
+		if err = simulateExecute(b.ctx, b.ec, &claim.forwarderAddr, txOpts, claim.callData); err != nil {
+			return nil, err
+		}
+
+		entry.gas, err = b.ec.Raw().EstimateGas(b.ctx, ethereum.CallMsg{
+			From: b.ec.Address(),
+			To:   &claim.forwarderAddr,
+			Data: claim.callData,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err = b.enqueue(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	select {
+	case res := <-entry.result:
+		return res.claim, res.err
+	case <-b.ctx.Done():
+		return nil, b.ctx.Err()
+	}
+}
+
+// enqueue adds the claim, which has the claimer's current forwarder nonce, to the
+// batch. It returns errClaimNonceInBatch if the claimer already has a claim in the
+// batch, which has the same nonce.
+func (b *ClaimBatcher) enqueue(entry *batchEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.lastPendingOf(entry.claim) != nil {
+		return fmt.Errorf("%w (nonce %s)", errClaimNonceInBatch, entry.claim.forwarderReq.Nonce)
+	}
+
+	b.addPending(entry)
+	return nil
+}
+
+// enqueueFollowing adds the claim to the batch if the claimer already has claims in
+// it, with the forwarder nonce following the nonce of the claimer's last claim. It
+// returns false if the claimer has no claims in the batch, and an error if the claim
+// is not signed with the following nonce.
+func (b *ClaimBatcher) enqueueFollowing(entry *batchEntry) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	last := b.lastPendingOf(entry.claim)
+	if last == nil {
+		return false, nil
+	}
+
+	nonce := new(big.Int).Add(last.claim.forwarderReq.Nonce, big.NewInt(1))
+	claim, err := entry.claim.withNonce(nonce)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", errClaimNonceInBatch, err)
+	}
+
+	// the claim's gas can't be estimated until the claimer's previous claims are
+	// executed, so its worst case is used
+	entry.claim = claim
+	_, entry.gas = claimGas(claim.request.Swap)
+
+	b.addPending(entry)
+	return true, nil
+}
+
+// lastPendingOf returns the last pending claim of the claim's claimer through the
+// same forwarder, or nil if there is none. The caller must hold the lock.
+func (b *ClaimBatcher) lastPendingOf(claim *preparedClaim) *batchEntry {
+	for i := len(b.pending) - 1; i >= 0; i-- {
+		pending := b.pending[i].claim
+		if pending.forwarderAddr == claim.forwarderAddr && pending.forwarderReq.From == claim.forwarderReq.From {
+			return b.pending[i]
+		}
+	}
+
+	return nil
+}
+
+// addPending adds the entry to the current batch, sending the batch if it is full.
+// The caller must hold the lock.
+func (b *ClaimBatcher) addPending(entry *batchEntry) {
+	b.pending = append(b.pending, entry)
+
+	if len(b.pending) >= b.conf.MaxSize {
+		go b.send(b.takePending())
+		return
+	}
+
+	if b.flushTimer == nil {
+		b.flushTimer = time.AfterFunc(b.conf.Window, b.flush)
+	}
+}
+
+func (b *ClaimBatcher) flush() {
+	b.mu.Lock()
+	batch := b.takePending()
+	b.mu.Unlock()
+
+	b.send(batch)
+}
+
+// takePending returns the pending claims and starts a new batch. The caller must
+// hold the lock.
+func (b *ClaimBatcher) takePending() []*batchEntry {
+	if b.flushTimer != nil {
+		b.flushTimer.Stop()
+		b.flushTimer = nil
+	}
+
+	batch := b.pending
+	b.pending = nil
+	return batch
+}
+
+func (b *ClaimBatcher) send(batch []*batchEntry) {
+	if len(batch) == 0 {
+		return
+	}
+
+	included, claims, err := b.sendBatch(batch)
+	if err != nil {
+		log.Warnf("failed to relay batch of %d claims: %s", len(included), err)
+		b.failAll(included, err)
+		return
+	}
+
+	for i, entry := range included {
+		log.Infof("relayed claim of swap %s in batch tx %s, fee %s, gas cost share %s ETH",
+			claims[i].SwapID, claims[i].TxHash,
+			FmtAssetFee(claims[i].FeeAsset, claims[i].FeeWei), coins.FmtWeiAsETH(claims[i].GasCostWei))
+		entry.result <- &batchResult{claim: claims[i]}
+	}
+}
+
+// sendBatch sends the claims of the batch that succeed in a simulation of the batch
+// in one transaction and waits for its receipt. Claims that fail the simulation get
+// their error result here. The included claims are returned with their share of the
+// gas cost, or with the error that prevented their inclusion.
+func (b *ClaimBatcher) sendBatch(batch []*batchEntry) ([]*batchEntry, []*RelayedClaim, error) {
+	calls := make([]contracts.Multicall3Call, len(batch))
+	for i, entry := range batch {
+		calls[i] = contracts.Multicall3Call{
+			Target:       entry.claim.forwarderAddr,
+			AllowFailure: true,
+			CallData:     entry.claim.callData,
+		}
+	}
+
+	// Lock the wallet's nonce until we get a receipt
+	b.ec.Lock()
+	defer b.ec.Unlock()
+
+	// Claims that were valid when submitted can fail now, if the swap was claimed
+	// in the meantime, so they are simulated again as part of the batch.
+	results, err := b.multicall.SimulateAggregate3(&bind.CallOpts{Context: b.ctx, From: b.ec.Address()}, calls)
+	if err != nil {
+		return batch, nil, err
+	}
+
+	var (
+		included      []*batchEntry
+		includedCalls []contracts.Multicall3Call
+		totalGas      uint64
+	)
+	for i, res := range results {
+		success := res.Success
+		if success {
+			success, err = unpackExecuteSuccess(res.ReturnData)
+			if err != nil {
+				success = false
+			}
+		}
+
+		if !success {
+			batch[i].result <- &batchResult{err: errClaimFailedInBatch}
+			continue
+		}
+
+		included = append(included, batch[i])
+		includedCalls = append(includedCalls, calls[i])
+		totalGas += batch[i].gas
+	}
+
+	if len(included) == 0 {
+		return nil, nil, nil
+	}
+
+	gasPrice, err := checkForMinClaimBalance(b.ctx, b.ec, totalGas)
+	if err != nil {
+		return included, nil, err
+	}
+
+	txOpts, err := b.ec.TxOpts(b.ctx)
+	if err != nil {
+		return included, nil, err
+	}
+	txOpts.GasPrice = gasPrice
+
+	tx, err := b.multicall.Aggregate3(txOpts, includedCalls)
+	if err != nil {
+		return included, nil, err
+	}
+
+	receipt, err := block.WaitForReceipt(b.ctx, b.ec.Raw(), tx.Hash())
+	if err != nil {
+		return included, nil, err
+	}
+
+	log.Infof("relayed batch of %d claims %s", len(included), common.ReceiptInfo(receipt))
+	metrics.GasSpent("relay_claim_batch", receipt)
+
+	gasCost := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	claims := make([]*RelayedClaim, len(included))
+	for i, entry := range included {
+		share := new(big.Int).Mul(gasCost, new(big.Int).SetUint64(entry.gas))
+		share.Quo(share, new(big.Int).SetUint64(totalGas))
+
+		claims[i] = &RelayedClaim{
+			SwapID:     entry.claim.request.Swap.SwapID(),
+			TxHash:     tx.Hash(),
+			FeeAsset:   types.EthAsset(entry.claim.request.Swap.Asset),
+			FeeWei:     entry.claim.request.FeeWei,
+			GasCostWei: share,
+		}
+	}
+
+	return included, claims, nil
+}
+
+func (b *ClaimBatcher) failAll(entries []*batchEntry, err error) {
+	for _, entry := range entries {
+		entry.result <- &batchResult{err: err}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+func TestNewClaimBatcher_invalidConfig(t *testing.T) {
+	for _, conf := range []*BatchConfig{
+		{Window: 0, MaxSize: DefaultMaxBatchSize},
+		{Window: DefaultBatchWindow, MaxSize: 1},
+		{Window: -time.Second, MaxSize: DefaultMaxBatchSize},
+	} {
+		_, err := NewClaimBatcher(context.Background(), nil, ethcommon.Address{}, DefaultFeeConfig(), conf)
+		require.ErrorContains(t, err, "invalid claim batch")
+	}
+}
+
+// newTestPreparedClaim returns the claim of a swap of the claimer, signed with the
+// given forwarder nonce, as prepareClaim creates it when the claimer's current
+// nonce is currentNonce.
+func newTestPreparedClaim(t *testing.T, claimerKey *ecdsa.PrivateKey, signedNonce, currentNonce int64) *preparedClaim {
+	swapCreatorAddr := ethcommon.Address{0x1}
+	forwarderAddr := ethcommon.Address{0x2}
+	chainID := big.NewInt(1337)
+	secret := [32]byte{0x3}
+	fee := DefaultMinFeeWei
+
+	swap := &contracts.SwapCreatorSwap{
+		Owner:    ethcommon.Address{0x4},
+		Claimer:  crypto.PubkeyToAddress(claimerKey.PublicKey),
+		Timeout0: big.NewInt(time.Now().Add(time.Hour).Unix()),
+		Timeout1: big.NewInt(time.Now().Add(2 * time.Hour).Unix()),
+		Asset:    types.EthAssetETH.Address(),
+		Value:    big.NewInt(1e18),
+		Nonce:    big.NewInt(signedNonce), // distinct swaps
+	}
+
+	signedReq, err := createForwarderRequest(big.NewInt(signedNonce), swapCreatorAddr, swap, &secret, fee)
+	require.NoError(t, err)
+	typedData := forwardRequestTypedData(chainID, forwarderAddr, &contracts.ForwarderV2Domain, signedReq)
+	sig, err := extethclient.NewPrivateKeySigner(claimerKey).SignTypedData(typedData)
+	require.NoError(t, err)
+
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	require.NoError(t, err)
+
+	forwarderReq, err := createForwarderRequest(big.NewInt(currentNonce), swapCreatorAddr, swap, &secret, fee)
+	require.NoError(t, err)
+
+	return &preparedClaim{
+		request: &message.RelayClaimRequest{
+			SwapCreatorAddr: swapCreatorAddr,
+			Swap:            swap,
+			Secret:          secret[:],
+			Signature:       sig,
+			FeeWei:          fee,
+		},
+		forwarderAddr:   forwarderAddr,
+		forwarderReq:    forwarderReq,
+		domainSeparator: [32]byte(domainSeparator),
+	}
+}
+
+func TestPreparedClaim_withNonce(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	claim := newTestPreparedClaim(t, key, 3, 2)
+
+	next, err := claim.withNonce(big.NewInt(3))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), next.forwarderReq.Nonce.Int64())
+	require.NotEmpty(t, next.callData)
+	require.Equal(t, int64(2), claim.forwarderReq.Nonce.Int64()) // not modified
+
+	_, err = claim.withNonce(big.NewInt(4))
+	require.ErrorContains(t, err, "not signed with forwarder nonce 4")
+}
+
+func TestClaimBatcher_claimerNonces(t *testing.T) {
+	b := &ClaimBatcher{
+		conf: &BatchConfig{Window: time.Hour, MaxSize: DefaultMaxBatchSize},
+	}
+	defer func() {
+		b.mu.Lock()
+		b.takePending()
+		b.mu.Unlock()
+	}()
+
+	newEntry := func(claim *preparedClaim) *batchEntry {
+		return &batchEntry{claim: claim, result: make(chan *batchResult, 1)}
+	}
+
+	claimerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	// the claimer's current nonce is 5, its first claim is queued after being
+	// simulated at that nonce
+	first := newEntry(newTestPreparedClaim(t, claimerKey, 5, 5))
+	queued, err := b.enqueueFollowing(first)
+	require.NoError(t, err)
+	require.False(t, queued)
+	require.NoError(t, b.enqueue(first))
+
+	// another claimer's claims don't affect the claimer's nonces
+	other := newEntry(newTestPreparedClaim(t, otherKey, 0, 0))
+	queued, err = b.enqueueFollowing(other)
+	require.NoError(t, err)
+	require.False(t, queued)
+	require.NoError(t, b.enqueue(other))
+
+	// the claimer's next claims get the following nonces
+	second := newEntry(newTestPreparedClaim(t, claimerKey, 6, 5))
+	queued, err = b.enqueueFollowing(second)
+	require.NoError(t, err)
+	require.True(t, queued)
+	require.Equal(t, int64(6), second.claim.forwarderReq.Nonce.Int64())
+
+	third := newEntry(newTestPreparedClaim(t, claimerKey, 7, 5))
+	queued, err = b.enqueueFollowing(third)
+	require.NoError(t, err)
+	require.True(t, queued)
+	require.Equal(t, int64(7), third.claim.forwarderReq.Nonce.Int64())
+	_, forwarderGas := claimGas(third.claim.request.Swap)
+	require.Equal(t, forwarderGas, third.gas)
+
+	// a claim signed with a nonce that is already used in the batch is rejected
+	// instead of failing in the batch
+	reused := newEntry(newTestPreparedClaim(t, claimerKey, 5, 5))
+	_, err = b.enqueueFollowing(reused)
+	require.ErrorIs(t, err, errClaimNonceInBatch)
+	require.ErrorIs(t, b.enqueue(reused), errClaimNonceInBatch)
+
+	// so is a claim that skips a nonce
+	skipping := newEntry(newTestPreparedClaim(t, claimerKey, 9, 5))
+	_, err = b.enqueueFollowing(skipping)
+	require.ErrorIs(t, err, errClaimNonceInBatch)
+
+	// the claims are executed in the order of their nonces
+	require.Equal(t, []*batchEntry{first, other, second, third}, b.pending)
+}
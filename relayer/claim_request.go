@@ -2,55 +2,69 @@
 // SPDX-License-Identifier: LGPL-3.0-only
 
 // Package relayer provides libraries for creating and validating relay requests and responses.
+//
+// Claims and refunds can be relayed, so an XMR holder without ETH can swap into
+// ETH or tokens, and an XMR taker whose ETH was spent on the swap can still get
+// its refund, paying the relayer fee out of the swap's funds. The new swap and
+// set ready calls of token swaps can be relayed too, so a token holder without
+// ETH can swap into XMR, paying the relayer fee in the token with a permit.
 package relayer
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"fmt"
 	"math/big"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	logging "github.com/ipfs/go-log"
 
-	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 const (
 	relayedClaimGas   = 70000  // worst case gas usage for the claimRelayer swapFactory call
 	forwarderClaimGas = 156000 // worst case gas usage when using forwarder to claim
-)
 
-// FeeWei and FeeEth are the fixed 0.009 ETH fee for using a swap relayer to claim.
-var (
-	FeeWei = big.NewInt(9e15)
-	FeeEth = coins.NewWeiAmount(FeeWei).AsEther()
+	// tokenClaimExtraGas is the worst case gas that claimRelayer uses for a token
+	// swap on top of an ETH swap, for the two token transfers
+	tokenClaimExtraGas = 60000
 )
 
 var log = logging.Logger("relayer")
 
 // CreateRelayClaimRequest fills and returns a RelayClaimRequest ready for
-// submission to a relayer.
+// submission to a relayer. The forwarder request, which pays feeWei to the
+// relayer, is signed by the claimer. For token swaps, feeWei is in base units of
+// the token.
 func CreateRelayClaimRequest(
 	ctx context.Context,
-	claimerEthKey *ecdsa.PrivateKey,
+	claimer extethclient.Signer,
 	ec *ethclient.Client,
 	swapCreatorAddr ethcommon.Address,
 	forwarderAddr ethcommon.Address,
 	swap *contracts.SwapCreatorSwap,
 	secret *[32]byte,
+	feeWei *big.Int,
 ) (*message.RelayClaimRequest, error) {
+	if feeWei.Cmp(swap.Value) >= 0 {
+		asset := types.EthAsset(swap.Asset)
+		return nil, fmt.Errorf("swap value of %s is too low to support %s relayer fee",
+			FmtAssetFee(asset, swap.Value), FmtAssetFee(asset, feeWei))
+	}
 
 	signature, err := createForwarderSignature(
 		ctx,
-		claimerEthKey,
+		claimer,
 		ec,
 		swapCreatorAddr,
 		forwarderAddr,
 		swap,
 		secret,
+		feeWei,
 	)
 	if err != nil {
 		return nil, err
@@ -62,5 +76,15 @@ func CreateRelayClaimRequest(
 		Swap:            swap,
 		Secret:          secret[:],
 		Signature:       signature,
+		FeeWei:          feeWei,
 	}, nil
 }
+
+// claimGas returns the gas limit of the forwarded claimRelayer call of the swap,
+// and the gas limit of the forwarder transaction executing it.
+func claimGas(swap *contracts.SwapCreatorSwap) (relayed int64, forwarder uint64) {
+	if types.EthAsset(swap.Asset).IsToken() {
+		return relayedClaimGas + tokenClaimExtraGas, forwarderClaimGas + tokenClaimExtraGas
+	}
+	return relayedClaimGas, forwarderClaimGas
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/tests"
 )
 
@@ -66,12 +67,14 @@ func TestCreateRelayClaimRequest(t *testing.T) {
 
 	// success path
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr, forwarderAddr, swap, &secret,
+		DefaultFeeConfig().Fee(swap.Value))
 	require.NoError(t, err)
 	require.NotNil(t, req)
 
 	// change the ethkey to not match the claimer address to trigger the error path
 	ethKey = tests.GetTakerTestKey(t)
-	_, err = CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret)
+	_, err = CreateRelayClaimRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr, forwarderAddr, swap, &secret,
+		DefaultFeeConfig().Fee(swap.Value))
 	require.ErrorContains(t, err, "signing key does not match claimer")
 }
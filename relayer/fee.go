@@ -0,0 +1,104 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+const (
+	// DefaultFeeBasisPoints is the default relayer fee of 1% of the swap value
+	DefaultFeeBasisPoints = 100
+
+	// MaxFeeBasisPoints caps the configurable fee at 10% of the swap value
+	MaxFeeBasisPoints = 1000
+
+	basisPointsPerUnit = 10000
+)
+
+var (
+	// DefaultMinFeeWei is the default lower bound of the relayer fee (0.001 ETH),
+	// so small swaps still cover the relayer's gas costs.
+	DefaultMinFeeWei = big.NewInt(1e15)
+
+	// DefaultMaxFeeWei is the default upper bound of the relayer fee (0.009 ETH),
+	// so large swaps don't pay more than the claim is worth to the relayer.
+	DefaultMaxFeeWei = big.NewInt(9e15)
+
+	errFeeBasisPointsTooHigh = fmt.Errorf("relayer fee cannot exceed %d basis points", MaxFeeBasisPoints)
+	errFeeBoundsInverted     = errors.New("minimum relayer fee is above the maximum relayer fee")
+)
+
+// FeeConfig is a relayer fee expressed in basis points (hundredths of a percent)
+// of the swap value, bounded by a minimum and maximum fee in wei. When claiming, it
+// determines the fee we offer to relayers. When relaying, it determines the fee we
+// require from claimers. Fees of token swaps are paid in the token, converted at
+// the token's rate in TokenRates.
+type FeeConfig struct {
+	BasisPoints uint32
+	MinWei      *big.Int
+	MaxWei      *big.Int
+	TokenRates  TokenFeeRates
+}
+
+// DefaultFeeConfig returns the default relayer fee configuration.
+func DefaultFeeConfig() *FeeConfig {
+	return &FeeConfig{
+		BasisPoints: DefaultFeeBasisPoints,
+		MinWei:      new(big.Int).Set(DefaultMinFeeWei),
+		MaxWei:      new(big.Int).Set(DefaultMaxFeeWei),
+	}
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c *FeeConfig) Validate() error {
+	if c.BasisPoints > MaxFeeBasisPoints {
+		return errFeeBasisPointsTooHigh
+	}
+
+	if c.MinWei.Sign() < 0 || c.MaxWei.Sign() < 0 {
+		return errors.New("relayer fee bounds cannot be negative")
+	}
+
+	if c.MinWei.Cmp(c.MaxWei) > 0 {
+		return errFeeBoundsInverted
+	}
+
+	for token, rate := range c.TokenRates {
+		if _, err := tokenBaseUnitsPerWei(rate, 0); err != nil {
+			return fmt.Errorf("token %s: %w", token, err)
+		}
+	}
+
+	return nil
+}
+
+// Fee returns the relayer fee in wei for a swap of the given value in wei.
+func (c *FeeConfig) Fee(swapValue *big.Int) *big.Int {
+	fee := new(big.Int).Mul(swapValue, big.NewInt(int64(c.BasisPoints)))
+	fee.Quo(fee, big.NewInt(basisPointsPerUnit))
+
+	if fee.Cmp(c.MinWei) < 0 {
+		fee.Set(c.MinWei)
+	}
+
+	if fee.Cmp(c.MaxWei) > 0 {
+		fee.Set(c.MaxWei)
+	}
+
+	return fee
+}
+
+// String returns a human-readable description of the fee configuration.
+func (c *FeeConfig) String() string {
+	return fmt.Sprintf("%s%% (min %s ETH, max %s ETH)",
+		new(big.Rat).SetFrac64(int64(c.BasisPoints), 100).FloatString(2),
+		coins.FmtWeiAsETH(c.MinWei),
+		coins.FmtWeiAsETH(c.MaxWei),
+	)
+}
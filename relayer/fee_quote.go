@@ -0,0 +1,114 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+// blockTimeSampleSize is the number of recent blocks used to estimate the
+// chain's block time.
+const blockTimeSampleSize = 20
+
+// QuoteFee returns our fee quote for relaying the claim of a swap with the
+// requested values. The fee of token swaps is quoted in base units of the token.
+// Requests for claims that ValidateAndSendTransaction would reject regardless of
+// the fee are refused.
+func QuoteFee(
+	ctx context.Context,
+	req *message.RelayFeeQuoteRequest,
+	ec extethclient.EthClient,
+	ourSwapCreatorAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+) (*message.RelayFeeQuote, error) {
+	if req.SwapCreatorAddr != ourSwapCreatorAddr {
+		_, err := contracts.CheckSwapCreatorContractCode(ctx, ec.Raw(), req.SwapCreatorAddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	asset := types.EthAsset(req.Asset)
+	fee, err := feeConfig.AssetFee(ctx, ec.Raw(), asset, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if fee.Cmp(req.Value) >= 0 {
+		return nil, fmt.Errorf("swap value of %s is too low to support %s relayer fee",
+			FmtAssetFee(asset, req.Value), FmtAssetFee(asset, fee))
+	}
+
+	eta, err := estimateClaimETA(ctx, ec.Raw(), ec.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	return &message.RelayFeeQuote{
+		FeeWei:     fee,
+		ETASeconds: uint64(eta / time.Second),
+	}, nil
+}
+
+// estimateClaimETA estimates the time until a claim that we submit now is
+// included. Our pending transactions are included first, so each of them adds a
+// block to the estimate.
+func estimateClaimETA(ctx context.Context, ec *ethclient.Client, relayerAddr ethcommon.Address) (time.Duration, error) {
+	blockTime, err := averageBlockTime(ctx, ec)
+	if err != nil {
+		return 0, err
+	}
+
+	pendingNonce, err := ec.PendingNonceAt(ctx, relayerAddr)
+	if err != nil {
+		return 0, err
+	}
+
+	nonce, err := ec.NonceAt(ctx, relayerAddr, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var queued uint64
+	if pendingNonce > nonce {
+		queued = pendingNonce - nonce
+	}
+
+	return time.Duration(queued+1) * blockTime, nil
+}
+
+// averageBlockTime returns the average time between the latest blocks.
+func averageBlockTime(ctx context.Context, ec *ethclient.Client) (time.Duration, error) {
+	latest, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	sampleSize := int64(blockTimeSampleSize)
+	if latest.Number.Int64() < sampleSize {
+		sampleSize = latest.Number.Int64()
+	}
+	if sampleSize == 0 {
+		return 0, nil
+	}
+
+	earlier, err := ec.HeaderByNumber(ctx, new(big.Int).Sub(latest.Number, big.NewInt(sampleSize)))
+	if err != nil {
+		return 0, err
+	}
+
+	elapsed := time.Duration(latest.Time-earlier.Time) * time.Second
+	return elapsed / time.Duration(sampleSize), nil
+}
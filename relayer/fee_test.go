@@ -0,0 +1,36 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeConfig_Fee(t *testing.T) {
+	conf := DefaultFeeConfig()
+
+	// 1% of 0.05 ETH is below the minimum fee
+	require.Equal(t, DefaultMinFeeWei, conf.Fee(big.NewInt(5e16)))
+
+	// 1% of 0.5 ETH is within the bounds
+	require.Equal(t, big.NewInt(5e15), conf.Fee(big.NewInt(5e17)))
+
+	// 1% of 10 ETH is above the maximum fee
+	require.Equal(t, DefaultMaxFeeWei, conf.Fee(new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))))
+}
+
+func TestFeeConfig_Validate(t *testing.T) {
+	require.NoError(t, DefaultFeeConfig().Validate())
+
+	conf := DefaultFeeConfig()
+	conf.BasisPoints = MaxFeeBasisPoints + 1
+	require.ErrorIs(t, conf.Validate(), errFeeBasisPointsTooHigh)
+
+	conf = DefaultFeeConfig()
+	conf.MinWei = new(big.Int).Add(conf.MaxWei, big.NewInt(1))
+	require.ErrorIs(t, conf.Validate(), errFeeBoundsInverted)
+}
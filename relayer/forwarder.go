@@ -5,62 +5,81 @@ package relayer
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 
-	rcommon "github.com/athanorlabs/go-relayer/common"
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 func createForwarderSignature(
 	ctx context.Context,
-	claimerEthKey *ecdsa.PrivateKey,
+	claimer extethclient.Signer,
 	ec *ethclient.Client,
 	swapCreatorAddr ethcommon.Address,
 	forwarderAddr ethcommon.Address,
 	swap *contracts.SwapCreatorSwap,
 	secret *[32]byte,
+	feeWei *big.Int,
 ) ([]byte, error) {
 
-	if swap.Claimer != ethcrypto.PubkeyToAddress(claimerEthKey.PublicKey) {
+	if swap.Claimer != claimer.Address() {
 		return nil, fmt.Errorf("signing key does not match claimer %s", swap.Claimer)
 	}
 
-	forwarder, domainSeparator, err := getForwarderAndDomainSeparator(ctx, ec, forwarderAddr)
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createForwarderRequest(nonce, swapCreatorAddr, swap, secret, feeWei)
+	}
+	return signForwardRequest(ctx, claimer, ec, forwarderAddr, newRequest)
+}
+
+// signForwardRequest signs the forward request that newRequest creates with the
+// signer's current forwarder nonce.
+func signForwardRequest(
+	ctx context.Context,
+	signer extethclient.Signer,
+	ec *ethclient.Client,
+	forwarderAddr ethcommon.Address,
+	newRequest func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error),
+) ([]byte, error) {
+	chainID, err := ec.ChainID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	nonce, err := forwarder.GetNonce(&bind.CallOpts{Context: ctx}, swap.Claimer)
+	forwarder, err := gsnforwarder.NewForwarder(forwarderAddr, ec)
 	if err != nil {
 		return nil, err
 	}
 
-	forwarderReq, err := createForwarderRequest(
-		nonce,
-		swapCreatorAddr,
-		swap,
-		secret,
-	)
+	nonce, err := forwarder.GetNonce(&bind.CallOpts{Context: ctx}, signer.Address())
 	if err != nil {
 		return nil, err
 	}
 
-	digest, err := rcommon.GetForwardRequestDigestToSign(forwarderReq, *domainSeparator, nil)
+	domain, _, err := contracts.RegisteredForwarderDomain(ctx, ec, forwarderAddr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get forward request digest: %w", err)
+		return nil, err
+	}
+
+	forwarderReq, err := newRequest(nonce)
+	if err != nil {
+		return nil, err
 	}
 
-	signature, err := rcommon.NewKeyFromPrivateKey(claimerEthKey).Sign(digest)
+	signature, err := signer.SignTypedData(forwardRequestTypedData(chainID, forwarderAddr, domain, forwarderReq))
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign forward request digest: %w", err)
+		return nil, fmt.Errorf("failed to sign forward request: %w", err)
 	}
 
 	return signature, nil
@@ -72,18 +91,20 @@ func createForwarderRequest(
 	swapCreatorAddr ethcommon.Address,
 	swap *contracts.SwapCreatorSwap,
 	secret *[32]byte,
+	feeWei *big.Int,
 ) (*gsnforwarder.IForwarderForwardRequest, error) {
 
-	calldata, err := getClaimRelayerTxCalldata(FeeWei, swap, secret)
+	calldata, err := getClaimRelayerTxCalldata(feeWei, swap, secret)
 	if err != nil {
 		return nil, err
 	}
 
+	gas, _ := claimGas(swap)
 	req := &gsnforwarder.IForwarderForwardRequest{
 		From:           swap.Claimer,
 		To:             swapCreatorAddr,
 		Value:          big.NewInt(0),
-		Gas:            big.NewInt(relayedClaimGas),
+		Gas:            big.NewInt(gas),
 		Nonce:          nonce,
 		Data:           calldata,
 		ValidUntilTime: big.NewInt(0),
@@ -92,32 +113,195 @@ func createForwarderRequest(
 	return req, nil
 }
 
+// forwardRequestTypedData returns the EIP-712 typed data of the forward request
+// for the given forwarder and its registered domain. Its hash is the same digest that the forwarder contract
+// verifies the signature against. Integer values are encoded as decimal strings so
+// they survive the JSON encoding used by external signers.
+func forwardRequestTypedData(
+	chainID *big.Int,
+	forwarderAddr ethcommon.Address,
+	domain *contracts.ForwarderDomain,
+	req *gsnforwarder.IForwarderForwardRequest,
+) *apitypes.TypedData {
+	return &apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"ForwardRequest": {
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "gas", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "validUntilTime", Type: "uint256"},
+			},
+		},
+		PrimaryType: "ForwardRequest",
+		Domain: apitypes.TypedDataDomain{
+			Name:              domain.Name,
+			Version:           domain.Version,
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: forwarderAddr.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"from":           req.From.Hex(),
+			"to":             req.To.Hex(),
+			"value":          req.Value.String(),
+			"gas":            req.Gas.String(),
+			"nonce":          req.Nonce.String(),
+			"data":           hexutil.Encode(req.Data),
+			"validUntilTime": req.ValidUntilTime.String(),
+		},
+	}
+}
+
+// forwardRequestSigner returns the address that signed the forward request, given
+// the separator of the EIP-712 domain that the request was signed against.
+func forwardRequestSigner(
+	domainSeparator [32]byte,
+	req *gsnforwarder.IForwarderForwardRequest,
+	sig []byte,
+) (ethcommon.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return ethcommon.Address{}, fmt.Errorf("invalid forward request signature length %d", len(sig))
+	}
+
+	// the domain is only needed for its separator, which we already have
+	typedData := forwardRequestTypedData(big.NewInt(0), ethcommon.Address{}, &contracts.ForwarderDomain{}, req)
+	structHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	digest := crypto.Keccak256([]byte("\x19\x01"), domainSeparator[:], structHash)
+
+	// Solidity's ecrecover takes a V value of 27 or 28, SigToPub takes 0 or 1
+	recoverable := make([]byte, len(sig))
+	copy(recoverable, sig)
+	if recoverable[crypto.RecoveryIDOffset] >= 27 {
+		recoverable[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(digest, recoverable)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
 // getClaimRelayerTxCalldata returns the call data to be used when invoking the
 // claimRelayer method on the SwapCreator contract.
 func getClaimRelayerTxCalldata(feeWei *big.Int, swap *contracts.SwapCreatorSwap, secret *[32]byte) ([]byte, error) {
 	return contracts.SwapCreatorParsedABI.Pack("claimRelayer", *swap, *secret, feeWei)
 }
 
+// createRefundForwarderRequest creates the forwarder request of a relayed refund,
+// which the swap owner signs the digest of.
+func createRefundForwarderRequest(
+	nonce *big.Int,
+	swapCreatorAddr ethcommon.Address,
+	swap *contracts.SwapCreatorSwap,
+	secret *[32]byte,
+	feeWei *big.Int,
+) (*gsnforwarder.IForwarderForwardRequest, error) {
+	calldata, err := contracts.SwapCreatorParsedABI.Pack("refundRelayer", *swap, *secret, feeWei)
+	if err != nil {
+		return nil, err
+	}
+
+	gas, _ := refundGas(swap)
+	return &gsnforwarder.IForwarderForwardRequest{
+		From:           swap.Owner,
+		To:             swapCreatorAddr,
+		Value:          big.NewInt(0),
+		Gas:            big.NewInt(gas),
+		Nonce:          nonce,
+		Data:           calldata,
+		ValidUntilTime: big.NewInt(0),
+	}, nil
+}
+
+// getForwarderAndDomainSeparator returns the forwarder binding and the separator of
+// the EIP-712 domain that forward requests to the forwarder are signed against.
 func getForwarderAndDomainSeparator(
 	ctx context.Context,
 	ec *ethclient.Client,
 	forwarderAddr ethcommon.Address,
 ) (*gsnforwarder.Forwarder, *[32]byte, error) {
-	chainID, err := ec.ChainID(ctx)
+	forwarder, err := gsnforwarder.NewForwarder(forwarderAddr, ec)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	forwarder, err := gsnforwarder.NewForwarder(forwarderAddr, ec)
+	_, domainSeparator, err := contracts.RegisteredForwarderDomain(ctx, ec, forwarderAddr)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	domainSeparator, err := rcommon.GetEIP712DomainSeparator(gsnforwarder.DefaultName,
-		gsnforwarder.DefaultVersion, chainID, forwarderAddr)
+	return forwarder, &domainSeparator, nil
+}
+
+// createNewSwapForwarderRequest creates the forwarder request of a relayed new
+// swap, which the swap owner signs the digest of.
+func createNewSwapForwarderRequest(
+	nonce *big.Int,
+	req *message.RelayNewSwapRequest,
+) (*gsnforwarder.IForwarderForwardRequest, error) {
+	calldata, err := contracts.SwapCreatorParsedABI.Pack(
+		contracts.NewSwapRelayerMethod,
+		req.PubKeyClaim,
+		req.PubKeyRefund,
+		req.Claimer,
+		req.TimeoutDuration0,
+		req.TimeoutDuration1,
+		req.Asset,
+		req.Value,
+		req.Nonce,
+		req.FeeWei,
+		*req.Permit,
+	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get EIP712 domain separator: %w", err)
+		return nil, err
 	}
 
-	return forwarder, &domainSeparator, nil
+	return &gsnforwarder.IForwarderForwardRequest{
+		From:           req.Owner,
+		To:             req.SwapCreatorAddr,
+		Value:          big.NewInt(0),
+		Gas:            big.NewInt(relayedNewSwapGas),
+		Nonce:          nonce,
+		Data:           calldata,
+		ValidUntilTime: big.NewInt(0),
+	}, nil
+}
+
+// createSetReadyForwarderRequest creates the forwarder request of a relayed
+// setReady call, which the swap owner signs the digest of.
+func createSetReadyForwarderRequest(
+	nonce *big.Int,
+	swapCreatorAddr ethcommon.Address,
+	swap *contracts.SwapCreatorSwap,
+	feeWei *big.Int,
+	permit *contracts.SwapCreatorPermitSignature,
+) (*gsnforwarder.IForwarderForwardRequest, error) {
+	calldata, err := contracts.SwapCreatorParsedABI.Pack(contracts.SetReadyRelayerMethod, *swap, feeWei, *permit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gsnforwarder.IForwarderForwardRequest{
+		From:           swap.Owner,
+		To:             swapCreatorAddr,
+		Value:          big.NewInt(0),
+		Gas:            big.NewInt(relayedSetReadyGas),
+		Nonce:          nonce,
+		Data:           calldata,
+		ValidUntilTime: big.NewInt(0),
+	}, nil
 }
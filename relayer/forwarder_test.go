@@ -0,0 +1,69 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"math/big"
+	"testing"
+
+	rcommon "github.com/athanorlabs/go-relayer/common"
+	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+func TestForwardRequestTypedData(t *testing.T) {
+	chainID := big.NewInt(1337)
+	forwarderAddr := ethcommon.Address{0x1}
+
+	req := &gsnforwarder.IForwarderForwardRequest{
+		From:           ethcommon.Address{0x2},
+		To:             ethcommon.Address{0x3},
+		Value:          big.NewInt(0),
+		Gas:            big.NewInt(relayedClaimGas),
+		Nonce:          big.NewInt(7),
+		Data:           []byte{0xde, 0xad, 0xbe, 0xef},
+		ValidUntilTime: big.NewInt(0),
+	}
+
+	// the typed data hash must match the digest the forwarder contract verifies
+	for _, domain := range []contracts.ForwarderDomain{contracts.ForwarderV2Domain, contracts.ForwarderV3Domain} {
+		domain := domain
+		domainSeparator, err := rcommon.GetEIP712DomainSeparator(domain.Name, domain.Version, chainID, forwarderAddr)
+		require.NoError(t, err)
+		expected, err := rcommon.GetForwardRequestDigestToSign(req, domainSeparator, nil)
+		require.NoError(t, err)
+
+		digest, _, err := apitypes.TypedDataAndHash(*forwardRequestTypedData(chainID, forwarderAddr, &domain, req))
+		require.NoError(t, err)
+		require.Equal(t, expected[:], digest, domain.Name)
+	}
+}
+
+func TestCreateRefundForwarderRequest(t *testing.T) {
+	swapCreatorAddr := ethcommon.Address{0x1}
+	swap := createTestSwap(ethcommon.Address{0x2})
+	secret := [32]byte{0x3}
+	fee := big.NewInt(1e15)
+
+	req, err := createRefundForwarderRequest(big.NewInt(7), swapCreatorAddr, swap, &secret, fee)
+	require.NoError(t, err)
+
+	// the refund is signed by the swap owner, not the claimer
+	require.Equal(t, swap.Owner, req.From)
+	require.Equal(t, swapCreatorAddr, req.To)
+	require.Equal(t, big.NewInt(7), req.Nonce)
+
+	method, err := contracts.SwapCreatorParsedABI.MethodById(req.Data)
+	require.NoError(t, err)
+	require.Equal(t, "refundRelayer", method.Name)
+
+	args, err := method.Inputs.Unpack(req.Data[4:])
+	require.NoError(t, err)
+	require.Equal(t, secret, args[1])
+	require.Equal(t, fee, args[2])
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	relayedNewSwapGas   = 240000 // worst case gas usage for the newSwapRelayer swapCreator call
+	forwarderNewSwapGas = 326000 // worst case gas usage when using forwarder to create a new swap
+)
+
+// errRelayedETHSwap is returned for relay requests of swap transactions whose
+// relayer fee is paid out of the owner's token balance, when the swap is an ETH
+// swap. An owner without ETH can't lock ETH in a swap.
+var errRelayedETHSwap = errors.New("only token swaps can have their new swap and set ready calls relayed")
+
+// CreateRelayNewSwapRequest fills and returns a RelayNewSwapRequest ready for
+// submission to a relayer, to create a token swap for an owner without ETH to pay
+// for gas. The owner signs a permit allowing the swap creator contract to transfer
+// the swap value plus feeWei, in base units of the token, and the forwarder
+// request which pays feeWei to the relayer.
+func CreateRelayNewSwapRequest(
+	ctx context.Context,
+	owner extethclient.Signer,
+	ec *ethclient.Client,
+	swapCreatorAddr ethcommon.Address,
+	forwarderAddr ethcommon.Address,
+	pubKeyClaim [32]byte,
+	pubKeyRefund [32]byte,
+	claimer ethcommon.Address,
+	timeoutDuration *big.Int,
+	asset types.EthAsset,
+	value *big.Int,
+	nonce *big.Int,
+	feeWei *big.Int,
+) (*message.RelayNewSwapRequest, error) {
+	if !asset.IsToken() {
+		return nil, errRelayedETHSwap
+	}
+
+	if feeWei.Cmp(value) >= 0 {
+		return nil, fmt.Errorf("swap value of %s is too low to support %s relayer fee",
+			FmtAssetFee(asset, value), FmtAssetFee(asset, feeWei))
+	}
+
+	permit, err := contracts.SignPermit(ctx, ec, owner, asset.Address(), swapCreatorAddr,
+		new(big.Int).Add(value, feeWei))
+	if err != nil {
+		return nil, err
+	}
+
+	req := &message.RelayNewSwapRequest{
+		SwapCreatorAddr:  swapCreatorAddr,
+		Owner:            owner.Address(),
+		PubKeyClaim:      pubKeyClaim,
+		PubKeyRefund:     pubKeyRefund,
+		Claimer:          claimer,
+		TimeoutDuration0: timeoutDuration,
+		TimeoutDuration1: timeoutDuration,
+		Asset:            asset.Address(),
+		Value:            value,
+		Nonce:            nonce,
+		Permit:           permit,
+		FeeWei:           feeWei,
+	}
+
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createNewSwapForwarderRequest(nonce, req)
+	}
+	req.Signature, err = signForwardRequest(ctx, owner, ec, forwarderAddr, newRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
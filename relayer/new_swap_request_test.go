@@ -0,0 +1,134 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net/message"
+	"github.com/athanorlabs/atomic-swap/tests"
+)
+
+// deployTestToken deploys a token owned by the key. The test token does not
+// implement EIP-2612 permits.
+func deployTestToken(t *testing.T, ec *ethclient.Client, key *ecdsa.PrivateKey) types.EthAsset {
+	ctx := context.Background()
+
+	chainID, err := ec.ChainID(ctx)
+	require.NoError(t, err)
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	require.NoError(t, err)
+
+	owner := crypto.PubkeyToAddress(*key.Public().(*ecdsa.PublicKey))
+	_, tx, _, err := contracts.DeployTestERC20(txOpts, ec, "TestERC20", "TEST", 18, owner, big.NewInt(1e18))
+	require.NoError(t, err)
+	addr, err := bind.WaitDeployed(ctx, ec, tx)
+	require.NoError(t, err)
+
+	return types.EthAsset(addr)
+}
+
+// tokenFeeConfig returns the default fee configuration with a fee rate for the
+// token.
+func tokenFeeConfig(asset types.EthAsset) *FeeConfig {
+	conf := DefaultFeeConfig()
+	conf.TokenRates = TokenFeeRates{asset.Address(): coins.StrToDecimal("2000")}
+	return conf
+}
+
+// testPermit returns a permit for requests whose relayed transaction is never
+// sent, as the test token does not implement EIP-2612 permits.
+func testPermit() *contracts.SwapCreatorPermitSignature {
+	return &contracts.SwapCreatorPermitSignature{
+		Deadline: big.NewInt(time.Now().Add(contracts.PermitValidity).Unix()),
+		V:        27,
+		R:        [32]byte{0x1},
+		S:        [32]byte{0x2},
+	}
+}
+
+// createTestNewSwapRequest returns a new swap request of the token swap signed by
+// the owner key, with the relayer fee of our fee configuration.
+func createTestNewSwapRequest(
+	t *testing.T,
+	ec *ethclient.Client,
+	ownerKey *ecdsa.PrivateKey,
+	swapCreatorAddr ethcommon.Address,
+	forwarderAddr ethcommon.Address,
+	asset types.EthAsset,
+) *message.RelayNewSwapRequest {
+	ctx := context.Background()
+	value := big.NewInt(1e18)
+
+	fee, err := tokenFeeConfig(asset).AssetFee(ctx, ec, asset, value)
+	require.NoError(t, err)
+
+	req := &message.RelayNewSwapRequest{
+		SwapCreatorAddr:  swapCreatorAddr,
+		Owner:            crypto.PubkeyToAddress(*ownerKey.Public().(*ecdsa.PublicKey)),
+		PubKeyClaim:      [32]byte{0x1},
+		PubKeyRefund:     [32]byte{0x2},
+		Claimer:          ethcommon.Address{0x3},
+		TimeoutDuration0: big.NewInt(3600),
+		TimeoutDuration1: big.NewInt(3600),
+		Asset:            asset.Address(),
+		Value:            value,
+		Nonce:            big.NewInt(1),
+		Permit:           testPermit(),
+		FeeWei:           fee,
+	}
+
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createNewSwapForwarderRequest(nonce, req)
+	}
+	req.Signature, err = signForwardRequest(ctx, extethclient.NewPrivateKeySigner(ownerKey), ec, forwarderAddr,
+		newRequest)
+	require.NoError(t, err)
+
+	return req
+}
+
+func TestCreateRelayNewSwapRequest(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec, _ := tests.NewEthClient(t)
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
+	signer := extethclient.NewPrivateKeySigner(ethKey)
+	token := deployTestToken(t, ec, ethKey)
+	value := big.NewInt(1e18)
+	fee := big.NewInt(1e15)
+
+	createRequest := func(asset types.EthAsset, feeWei *big.Int) error {
+		_, err := CreateRelayNewSwapRequest(ctx, signer, ec, swapCreatorAddr, forwarderAddr, [32]byte{0x1},
+			[32]byte{0x2}, ethcommon.Address{0x3}, big.NewInt(3600), asset, value, big.NewInt(1), feeWei)
+		return err
+	}
+
+	// an owner without ETH can't lock ETH in a swap
+	err := createRequest(types.EthAssetETH, fee)
+	require.ErrorIs(t, err, errRelayedETHSwap)
+
+	// the fee must be less than the swap value
+	err = createRequest(token, value)
+	require.ErrorContains(t, err, "is too low to support")
+
+	// the relayer fee is transferred with a permit
+	err = createRequest(token, fee)
+	require.ErrorContains(t, err, "token does not support EIP-2612 permit")
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	// DefaultQuoteCacheTTL is how long a fee quote is reused for identical quote
+	// requests. Fees only depend on the request and our configuration, but the
+	// ETA follows the chain, so quotes are only reused briefly.
+	DefaultQuoteCacheTTL = 15 * time.Second
+
+	// maxCachedQuotes bounds the cache, as the requested values are chosen by
+	// peers.
+	maxCachedQuotes = 256
+)
+
+// quoteKey identifies the quote requests that get the same quote.
+type quoteKey struct {
+	swapCreatorAddr ethcommon.Address
+	asset           ethcommon.Address
+	value           string
+}
+
+type cachedQuote struct {
+	quote   *message.RelayFeeQuote
+	expires time.Time
+}
+
+// QuoteCache reuses our recent fee quotes for identical quote requests, so that
+// takers polling us for quotes don't cost an eth_call each.
+type QuoteCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	quotes map[quoteKey]*cachedQuote
+	now    func() time.Time // replaced in tests
+}
+
+// NewQuoteCache returns a QuoteCache that reuses quotes for the given duration.
+func NewQuoteCache(ttl time.Duration) *QuoteCache {
+	return &QuoteCache{
+		ttl:    ttl,
+		quotes: make(map[quoteKey]*cachedQuote),
+		now:    time.Now,
+	}
+}
+
+// Quote returns the cached quote for the request if it hasn't expired, otherwise
+// the quote of quoteFn, which is cached if quoteFn succeeds. Failed quotes are not
+// cached, as the failure may be transient.
+func (c *QuoteCache) Quote(
+	req *message.RelayFeeQuoteRequest,
+	quoteFn func() (*message.RelayFeeQuote, error),
+) (*message.RelayFeeQuote, error) {
+	key := quoteKey{
+		swapCreatorAddr: req.SwapCreatorAddr,
+		asset:           req.Asset,
+		value:           req.Value.String(),
+	}
+
+	c.mu.Lock()
+	cached, ok := c.quotes[key]
+	if ok && c.now().Before(cached.expires) {
+		c.mu.Unlock()
+		return copyQuote(cached.quote), nil
+	}
+	c.mu.Unlock()
+
+	quote, err := quoteFn()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.quotes) >= maxCachedQuotes {
+		c.dropExpired(now)
+	}
+	if len(c.quotes) < maxCachedQuotes {
+		c.quotes[key] = &cachedQuote{quote: copyQuote(quote), expires: now.Add(c.ttl)}
+	}
+
+	return quote, nil
+}
+
+// dropExpired removes the expired quotes. The caller must hold the mutex.
+func (c *QuoteCache) dropExpired(now time.Time) {
+	for key, cached := range c.quotes {
+		if !now.Before(cached.expires) {
+			delete(c.quotes, key)
+		}
+	}
+}
+
+func copyQuote(quote *message.RelayFeeQuote) *message.RelayFeeQuote {
+	return &message.RelayFeeQuote{
+		FeeWei:     new(big.Int).Set(quote.FeeWei),
+		ETASeconds: quote.ETASeconds,
+	}
+}
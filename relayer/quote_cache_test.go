@@ -0,0 +1,123 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+func TestQuoteCache_Quote(t *testing.T) {
+	now := time.Now()
+	c := NewQuoteCache(DefaultQuoteCacheTTL)
+	c.now = func() time.Time { return now }
+
+	calls := 0
+	quoteFn := func() (*message.RelayFeeQuote, error) {
+		calls++
+		return &message.RelayFeeQuote{FeeWei: big.NewInt(int64(calls)), ETASeconds: 12}, nil
+	}
+
+	req := &message.RelayFeeQuoteRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Value:           big.NewInt(1e18),
+	}
+
+	quote, err := c.Quote(req, quoteFn)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), quote.FeeWei.Int64())
+
+	// modifying the returned quote doesn't modify the cached one
+	quote.FeeWei.SetInt64(100)
+
+	// identical requests reuse the quote until it expires
+	now = now.Add(DefaultQuoteCacheTTL - time.Second)
+	quote, err = c.Quote(&message.RelayFeeQuoteRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Value:           big.NewInt(1e18),
+	}, quoteFn)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), quote.FeeWei.Int64())
+	require.Equal(t, 1, calls)
+
+	// a different value is quoted separately
+	quote, err = c.Quote(&message.RelayFeeQuoteRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Value:           big.NewInt(2e18),
+	}, quoteFn)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), quote.FeeWei.Int64())
+
+	// so is a different asset
+	quote, err = c.Quote(&message.RelayFeeQuoteRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Asset:           ethcommon.Address{0x2},
+		Value:           big.NewInt(1e18),
+	}, quoteFn)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), quote.FeeWei.Int64())
+
+	// the expired quote is replaced
+	now = now.Add(time.Second)
+	quote, err = c.Quote(req, quoteFn)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), quote.FeeWei.Int64())
+	require.Equal(t, 4, calls)
+}
+
+func TestQuoteCache_Quote_failureNotCached(t *testing.T) {
+	c := NewQuoteCache(DefaultQuoteCacheTTL)
+
+	req := &message.RelayFeeQuoteRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Value:           big.NewInt(1e18),
+	}
+
+	errQuote := errors.New("endpoint unavailable")
+	_, err := c.Quote(req, func() (*message.RelayFeeQuote, error) {
+		return nil, errQuote
+	})
+	require.ErrorIs(t, err, errQuote)
+
+	quote, err := c.Quote(req, func() (*message.RelayFeeQuote, error) {
+		return &message.RelayFeeQuote{FeeWei: big.NewInt(1)}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), quote.FeeWei.Int64())
+}
+
+func TestQuoteCache_Quote_bounded(t *testing.T) {
+	now := time.Now()
+	c := NewQuoteCache(DefaultQuoteCacheTTL)
+	c.now = func() time.Time { return now }
+
+	quoteFn := func() (*message.RelayFeeQuote, error) {
+		return &message.RelayFeeQuote{FeeWei: big.NewInt(1)}, nil
+	}
+
+	for i := 0; i < maxCachedQuotes+10; i++ {
+		_, err := c.Quote(&message.RelayFeeQuoteRequest{
+			SwapCreatorAddr: ethcommon.Address{0x1},
+			Value:           big.NewInt(int64(i + 1)),
+		}, quoteFn)
+		require.NoError(t, err)
+	}
+	require.Len(t, c.quotes, maxCachedQuotes)
+
+	// the expired quotes make room for new ones
+	now = now.Add(DefaultQuoteCacheTTL)
+	_, err := c.Quote(&message.RelayFeeQuoteRequest{
+		SwapCreatorAddr: ethcommon.Address{0x1},
+		Value:           big.NewInt(1),
+	}, quoteFn)
+	require.NoError(t, err)
+	require.Len(t, c.quotes, 1)
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+// CreateRelayRefundRequest fills and returns a RelayRefundRequest ready for
+// submission to a relayer. The forwarder request, which pays feeWei to the
+// relayer, is signed by the swap owner. For token swaps, feeWei is in base units
+// of the token.
+func CreateRelayRefundRequest(
+	ctx context.Context,
+	owner extethclient.Signer,
+	ec *ethclient.Client,
+	swapCreatorAddr ethcommon.Address,
+	forwarderAddr ethcommon.Address,
+	swap *contracts.SwapCreatorSwap,
+	secret *[32]byte,
+	feeWei *big.Int,
+) (*message.RelayRefundRequest, error) {
+	if feeWei.Cmp(swap.Value) >= 0 {
+		asset := types.EthAsset(swap.Asset)
+		return nil, fmt.Errorf("swap value of %s is too low to support %s relayer fee",
+			FmtAssetFee(asset, swap.Value), FmtAssetFee(asset, feeWei))
+	}
+
+	if swap.Owner != owner.Address() {
+		return nil, fmt.Errorf("signing key does not match swap owner %s", swap.Owner)
+	}
+
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createRefundForwarderRequest(nonce, swapCreatorAddr, swap, secret, feeWei)
+	}
+	signature, err := signForwardRequest(ctx, owner, ec, forwarderAddr, newRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &message.RelayRefundRequest{
+		SwapCreatorAddr: swapCreatorAddr,
+		Swap:            swap,
+		Secret:          secret[:],
+		Signature:       signature,
+		FeeWei:          feeWei,
+	}, nil
+}
+
+// refundGas returns the gas limit of the forwarded refundRelayer call of the swap,
+// and the gas limit of the forwarder transaction executing it. A relayed refund
+// does the same checks and transfers as a relayed claim.
+func refundGas(swap *contracts.SwapCreatorSwap) (relayed int64, forwarder uint64) {
+	return claimGas(swap)
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/tests"
+)
+
+func createTestRefundSwap(t *testing.T, ownerKey *ecdsa.PrivateKey) *contracts.SwapCreatorSwap {
+	swap := createTestSwap(crypto.PubkeyToAddress(*tests.GetMakerTestKey(t).Public().(*ecdsa.PublicKey)))
+	swap.Owner = crypto.PubkeyToAddress(*ownerKey.Public().(*ecdsa.PublicKey))
+	return swap
+}
+
+func TestCreateRelayRefundRequest(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec, _ := tests.NewEthClient(t)
+	secret := [32]byte{0x1}
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
+
+	// success path
+	swap := createTestRefundSwap(t, ethKey)
+	req, err := CreateRelayRefundRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr, forwarderAddr,
+		swap, &secret, DefaultFeeConfig().Fee(swap.Value))
+	require.NoError(t, err)
+	require.NotNil(t, req)
+
+	// change the ethkey to not match the owner address to trigger the error path
+	ethKey = tests.GetMakerTestKey(t)
+	_, err = CreateRelayRefundRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr, forwarderAddr,
+		swap, &secret, DefaultFeeConfig().Fee(swap.Value))
+	require.ErrorContains(t, err, "signing key does not match swap owner")
+
+	// the fee must be less than the swap value
+	_, err = CreateRelayRefundRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr, forwarderAddr,
+		swap, &secret, swap.Value)
+	require.ErrorContains(t, err, "is too low to support")
+}
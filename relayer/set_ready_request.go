@@ -0,0 +1,74 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	relayedSetReadyGas   = 140000 // worst case gas usage for the setReadyRelayer swapCreator call
+	forwarderSetReadyGas = 226000 // worst case gas usage when using forwarder to set a swap ready
+)
+
+// CreateRelaySetReadyRequest fills and returns a RelaySetReadyRequest ready for
+// submission to a relayer, to set the token swap ready for an owner without ETH
+// to pay for gas. The owner signs a permit allowing the swap creator contract to
+// transfer feeWei, in base units of the token, and the forwarder request which
+// pays feeWei to the relayer.
+func CreateRelaySetReadyRequest(
+	ctx context.Context,
+	owner extethclient.Signer,
+	ec *ethclient.Client,
+	swapCreatorAddr ethcommon.Address,
+	forwarderAddr ethcommon.Address,
+	swap *contracts.SwapCreatorSwap,
+	feeWei *big.Int,
+) (*message.RelaySetReadyRequest, error) {
+	asset := types.EthAsset(swap.Asset)
+	if !asset.IsToken() {
+		return nil, errRelayedETHSwap
+	}
+
+	if feeWei.Cmp(swap.Value) >= 0 {
+		return nil, fmt.Errorf("swap value of %s is too low to support %s relayer fee",
+			FmtAssetFee(asset, swap.Value), FmtAssetFee(asset, feeWei))
+	}
+
+	if swap.Owner != owner.Address() {
+		return nil, fmt.Errorf("signing key does not match swap owner %s", swap.Owner)
+	}
+
+	permit, err := contracts.SignPermit(ctx, ec, owner, swap.Asset, swapCreatorAddr, feeWei)
+	if err != nil {
+		return nil, err
+	}
+
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createSetReadyForwarderRequest(nonce, swapCreatorAddr, swap, feeWei, permit)
+	}
+	signature, err := signForwardRequest(ctx, owner, ec, forwarderAddr, newRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &message.RelaySetReadyRequest{
+		SwapCreatorAddr: swapCreatorAddr,
+		Swap:            swap,
+		Permit:          permit,
+		Signature:       signature,
+		FeeWei:          feeWei,
+	}, nil
+}
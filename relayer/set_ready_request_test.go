@@ -0,0 +1,88 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net/message"
+	"github.com/athanorlabs/atomic-swap/tests"
+)
+
+// createTestSetReadyRequest returns a set ready request of a token swap owned by
+// the owner key, signed by the owner key, with the relayer fee of our fee
+// configuration.
+func createTestSetReadyRequest(
+	t *testing.T,
+	ec *ethclient.Client,
+	ownerKey *ecdsa.PrivateKey,
+	swapCreatorAddr ethcommon.Address,
+	forwarderAddr ethcommon.Address,
+	asset types.EthAsset,
+) *message.RelaySetReadyRequest {
+	ctx := context.Background()
+
+	swap := createTestRefundSwap(t, ownerKey)
+	swap.Asset = asset.Address()
+
+	fee, err := tokenFeeConfig(asset).AssetFee(ctx, ec, asset, swap.Value)
+	require.NoError(t, err)
+
+	req := &message.RelaySetReadyRequest{
+		SwapCreatorAddr: swapCreatorAddr,
+		Swap:            swap,
+		Permit:          testPermit(),
+		FeeWei:          fee,
+	}
+
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createSetReadyForwarderRequest(nonce, swapCreatorAddr, swap, fee, req.Permit)
+	}
+	req.Signature, err = signForwardRequest(ctx, extethclient.NewPrivateKeySigner(ownerKey), ec, forwarderAddr,
+		newRequest)
+	require.NoError(t, err)
+
+	return req
+}
+
+func TestCreateRelaySetReadyRequest(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec, _ := tests.NewEthClient(t)
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
+	token := deployTestToken(t, ec, ethKey)
+	fee := big.NewInt(1e15)
+
+	// an owner without ETH can't have locked ETH in a swap
+	swap := createTestRefundSwap(t, ethKey)
+	_, err := CreateRelaySetReadyRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr,
+		forwarderAddr, swap, fee)
+	require.ErrorIs(t, err, errRelayedETHSwap)
+
+	// the fee must be less than the swap value
+	swap.Asset = token.Address()
+	_, err = CreateRelaySetReadyRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr,
+		forwarderAddr, swap, swap.Value)
+	require.ErrorContains(t, err, "is too low to support")
+
+	// only the swap owner can set the swap ready
+	_, err = CreateRelaySetReadyRequest(ctx, extethclient.NewPrivateKeySigner(tests.GetMakerTestKey(t)), ec,
+		swapCreatorAddr, forwarderAddr, swap, fee)
+	require.ErrorContains(t, err, "signing key does not match swap owner")
+
+	// the relayer fee is transferred with a permit
+	_, err = CreateRelaySetReadyRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr,
+		forwarderAddr, swap, fee)
+	require.ErrorContains(t, err, "token does not support EIP-2612 permit")
+}
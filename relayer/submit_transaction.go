@@ -13,58 +13,308 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/metrics"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
-// ValidateAndSendTransaction sends the relayed transaction to the network if it validates successfully.
+var (
+	errRefundRelayerUnsupported   = errors.New("SwapCreator contract does not support relayed refunds")
+	errNewSwapRelayerUnsupported  = errors.New("SwapCreator contract does not support relayed new swaps")
+	errSetReadyRelayerUnsupported = errors.New("SwapCreator contract does not support relayed set ready calls")
+)
+
+// RelayedClaim is the accounting of a claim, or another swap transaction like a
+// refund, that we relayed.
+type RelayedClaim struct {
+	SwapID types.Hash
+	TxHash ethcommon.Hash
+
+	// FeeAsset is the asset that the relayer fee is paid in, which is the swap's
+	// asset.
+	FeeAsset types.EthAsset
+
+	// FeeWei is the relayer fee paid by the claim, in base units of FeeAsset.
+	FeeWei *big.Int
+
+	// GasCostWei is the gas cost of the claim's transaction. For batched claims,
+	// it is the claim's share of the batch transaction's gas cost, proportional
+	// to the claim's estimated gas.
+	GasCostWei *big.Int
+}
+
+// ValidateAndSendTransaction sends the relayed transaction to the network if it
+// validates successfully, including that the request's fee satisfies our fee
+// configuration.
 func ValidateAndSendTransaction(
 	ctx context.Context,
 	req *message.RelayClaimRequest,
 	ec extethclient.EthClient,
 	ourSFContractAddr ethcommon.Address,
-) (*message.RelayClaimResponse, error) {
+	feeConfig *FeeConfig,
+) (*RelayedClaim, error) {
+	claim, err := prepareClaim(ctx, req, ec, ourSFContractAddr, feeConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	err := validateClaimRequest(ctx, req, ec.Raw(), ourSFContractAddr)
+	execute := func(txOpts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return claim.forwarder.Execute(
+			txOpts,
+			*claim.forwarderReq,
+			claim.domainSeparator,
+			gsnforwarder.ForwardRequestTypehash,
+			nil,
+			req.Signature,
+		)
+	}
+
+	_, gas := claimGas(req.Swap)
+	receipt, err := sendForwardRequest(ctx, ec, gas, claim.forwarderAddr, claim.callData, execute)
 	if err != nil {
 		return nil, err
 	}
 
-	reqSwapCreator, err := contracts.NewSwapCreator(req.SwapCreatorAddr, ec.Raw())
+	log.Infof("relayed claim %s", common.ReceiptInfo(receipt))
+	metrics.GasSpent("relay_claim", receipt)
+
+	return newRelayedClaim(req.Swap, req.FeeWei, receipt), nil
+}
+
+// ValidateAndSendRefund sends the relayed refund transaction to the network if
+// it validates successfully, including that the request's fee satisfies our fee
+// configuration. Refunds can only be relayed to SwapCreator contracts that were
+// deployed with refundRelayer.
+func ValidateAndSendRefund(
+	ctx context.Context,
+	req *message.RelayRefundRequest,
+	ec extethclient.EthClient,
+	ourSFContractAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+) (*RelayedClaim, error) {
+	err := checkSwapCreatorMethod(ctx, ec.Raw(), req.SwapCreatorAddr, contracts.RefundRelayerMethod,
+		errRefundRelayerUnsupported)
 	if err != nil {
 		return nil, err
 	}
 
-	reqForwarderAddr, err := reqSwapCreator.TrustedForwarder(&bind.CallOpts{Context: ctx})
+	err = validateRefundRequest(ctx, req, ec.Raw(), ourSFContractAddr, feeConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	reqForwarder, domainSeparator, err := getForwarderAndDomainSeparator(ctx, ec.Raw(), reqForwarderAddr)
+	// The size of request.Secret was vetted when it was deserialized
+	secret := (*[32]byte)(req.Secret)
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createRefundForwarderRequest(nonce, req.SwapCreatorAddr, req.Swap, secret, req.FeeWei)
+	}
+
+	_, gas := refundGas(req.Swap)
+	receipt, err := sendOwnerRequest(ctx, ec, req.SwapCreatorAddr, req.Swap.Owner, req.Signature, gas, newRequest)
 	if err != nil {
 		return nil, err
 	}
 
-	nonce, err := reqForwarder.GetNonce(&bind.CallOpts{Context: ctx}, req.Swap.Claimer)
+	log.Infof("relayed refund %s", common.ReceiptInfo(receipt))
+	metrics.GasSpent("relay_refund", receipt)
+
+	return newRelayedClaim(req.Swap, req.FeeWei, receipt), nil
+}
+
+// ValidateAndSendNewSwap sends the relayed transaction creating the token swap of
+// the request to the network if it validates successfully, including that the
+// request's fee satisfies our fee configuration. New swaps can only be relayed to
+// SwapCreator contracts that were deployed with newSwapRelayer.
+func ValidateAndSendNewSwap(
+	ctx context.Context,
+	req *message.RelayNewSwapRequest,
+	ec extethclient.EthClient,
+	ourSFContractAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+) (*RelayedClaim, error) {
+	err := checkSwapCreatorMethod(ctx, ec.Raw(), req.SwapCreatorAddr, contracts.NewSwapRelayerMethod,
+		errNewSwapRelayerUnsupported)
 	if err != nil {
 		return nil, err
 	}
 
-	// The size of request.Secret was vetted when it was deserialized
-	secret := (*[32]byte)(req.Secret)
+	err = validateNewSwapRequest(ctx, req, ec.Raw(), ourSFContractAddr, feeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createNewSwapForwarderRequest(nonce, req)
+	}
+
+	receipt, err := sendOwnerRequest(ctx, ec, req.SwapCreatorAddr, req.Owner, req.Signature, forwarderNewSwapGas,
+		newRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("relayed new swap %s", common.ReceiptInfo(receipt))
+	metrics.GasSpent("relay_new_swap", receipt)
+
+	relayed := &RelayedClaim{
+		TxHash:     receipt.TxHash,
+		FeeAsset:   types.EthAsset(req.Asset),
+		FeeWei:     req.FeeWei,
+		GasCostWei: new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice),
+	}
+
+	// the swap ID is only known once the swap's timeouts are set by the contract
+	newTopic := contracts.SwapCreatorParsedABI.Events["New"].ID
+	for _, l := range receipt.Logs {
+		if l.Address != req.SwapCreatorAddr || len(l.Topics) == 0 || l.Topics[0] != newTopic {
+			continue
+		}
+		if relayed.SwapID, err = contracts.GetIDFromLog(l); err != nil {
+			log.Warnf("failed to get the swap ID of relayed new swap %s: %s", receipt.TxHash, err)
+		}
+		break
+	}
+
+	return relayed, nil
+}
+
+// ValidateAndSendSetReady sends the relayed setReady transaction of the token
+// swap to the network if it validates successfully, including that the request's
+// fee satisfies our fee configuration. The call can only be relayed to
+// SwapCreator contracts that were deployed with setReadyRelayer.
+func ValidateAndSendSetReady(
+	ctx context.Context,
+	req *message.RelaySetReadyRequest,
+	ec extethclient.EthClient,
+	ourSFContractAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+) (*RelayedClaim, error) {
+	err := checkSwapCreatorMethod(ctx, ec.Raw(), req.SwapCreatorAddr, contracts.SetReadyRelayerMethod,
+		errSetReadyRelayerUnsupported)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateSetReadyRequest(ctx, req, ec.Raw(), ourSFContractAddr, feeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createSetReadyForwarderRequest(nonce, req.SwapCreatorAddr, req.Swap, req.FeeWei, req.Permit)
+	}
+
+	receipt, err := sendOwnerRequest(ctx, ec, req.SwapCreatorAddr, req.Swap.Owner, req.Signature,
+		forwarderSetReadyGas, newRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("relayed set ready %s", common.ReceiptInfo(receipt))
+	metrics.GasSpent("relay_set_ready", receipt)
+
+	return newRelayedClaim(req.Swap, req.FeeWei, receipt), nil
+}
+
+// checkSwapCreatorMethod returns unsupportedErr if the SwapCreator contract at the
+// address was deployed without the relayer method.
+func checkSwapCreatorMethod(
+	ctx context.Context,
+	ec *ethclient.Client,
+	swapCreatorAddr ethcommon.Address,
+	method string,
+	unsupportedErr error,
+) error {
+	hasMethod, err := contracts.SwapCreatorHasMethod(ctx, ec, swapCreatorAddr, method)
+	if err != nil {
+		return err
+	}
+
+	if !hasMethod {
+		return fmt.Errorf("%w: %s", unsupportedErr, swapCreatorAddr)
+	}
+
+	return nil
+}
 
-	forwarderReq, err := createForwarderRequest(nonce, req.SwapCreatorAddr, req.Swap, secret)
+// sendOwnerRequest sends the forwarder transaction executing the validated forward
+// request that newRequest creates with the swap owner's current nonce in the
+// forwarder trusted by the swap creator contract.
+func sendOwnerRequest(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	swapCreatorAddr ethcommon.Address,
+	owner ethcommon.Address,
+	signature []byte,
+	gas uint64,
+	newRequest func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error),
+) (*ethtypes.Receipt, error) {
+	forwarderAddr, forwarder, domainSeparator, err := getRequestForwarder(ctx, ec.Raw(), swapCreatorAddr)
 	if err != nil {
 		return nil, err
 	}
 
-	gasPrice, err := checkForMinClaimBalance(ctx, ec)
+	nonce, err := forwarder.GetNonce(&bind.CallOpts{Context: ctx}, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	forwarderReq, err := newRequest(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	callData, err := packExecute(*forwarderReq, *domainSeparator, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	execute := func(txOpts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return forwarder.Execute(
+			txOpts,
+			*forwarderReq,
+			*domainSeparator,
+			gsnforwarder.ForwardRequestTypehash,
+			nil,
+			signature,
+		)
+	}
+
+	return sendForwardRequest(ctx, ec, gas, forwarderAddr, callData, execute)
+}
+
+// newRelayedClaim returns the accounting of the relayed transaction of the swap
+// with the given receipt.
+func newRelayedClaim(swap *contracts.SwapCreatorSwap, feeWei *big.Int, receipt *ethtypes.Receipt) *RelayedClaim {
+	return &RelayedClaim{
+		SwapID:     swap.SwapID(),
+		TxHash:     receipt.TxHash,
+		FeeAsset:   types.EthAsset(swap.Asset),
+		FeeWei:     feeWei,
+		GasCostWei: new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice),
+	}
+}
+
+// sendForwardRequest simulates the packed call of the forwarder's execute method
+// and, if the forwarded call succeeds, sends it with execute and waits for its
+// receipt.
+func sendForwardRequest(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	gas uint64,
+	forwarderAddr ethcommon.Address,
+	callData []byte,
+	execute func(txOpts *bind.TransactOpts) (*ethtypes.Transaction, error),
+) (*ethtypes.Receipt, error) {
+	gasPrice, err := checkForMinClaimBalance(ctx, ec, gas)
 	if err != nil {
 		return nil, err
 	}
@@ -79,44 +329,133 @@ func ValidateAndSendTransaction(
 	}
 	txOpts.GasPrice = gasPrice
 
-	err = simulateExecute(
-		ctx,
-		ec,
-		&reqForwarderAddr,
-		txOpts,
-		*forwarderReq,
-		*domainSeparator,
-		req.Signature,
-	)
+	err = simulateExecute(ctx, ec, &forwarderAddr, txOpts, callData)
 	if err != nil {
 		return nil, err
 	}
 
-	tx, err := reqForwarder.Execute(
-		txOpts,
-		*forwarderReq,
-		*domainSeparator,
-		gsnforwarder.ForwardRequestTypehash,
-		nil,
-		req.Signature,
-	)
+	tx, err := execute(txOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	receipt, err := block.WaitForReceipt(ctx, ec.Raw(), tx.Hash())
+	return block.WaitForReceipt(ctx, ec.Raw(), tx.Hash())
+}
+
+// preparedClaim is a validated relay claim request along with the forwarder call
+// that executes it.
+type preparedClaim struct {
+	request         *message.RelayClaimRequest
+	forwarderAddr   ethcommon.Address
+	forwarder       *gsnforwarder.Forwarder
+	forwarderReq    *gsnforwarder.IForwarderForwardRequest
+	domainSeparator [32]byte
+
+	// callData is the packed call of the forwarder's execute method
+	callData []byte
+}
+
+// withNonce returns a copy of the claim whose forward request has the given nonce,
+// instead of the claimer's current nonce. It returns an error if the request's
+// signature is not for that nonce.
+func (c *preparedClaim) withNonce(nonce *big.Int) (*preparedClaim, error) {
+	forwarderReq := *c.forwarderReq
+	forwarderReq.Nonce = nonce
+
+	signer, err := forwardRequestSigner(c.domainSeparator, &forwarderReq, c.request.Signature)
 	if err != nil {
 		return nil, err
 	}
+	if signer != forwarderReq.From {
+		return nil, fmt.Errorf("claim request is not signed with forwarder nonce %s", nonce)
+	}
 
-	log.Infof("relayed claim %s", common.ReceiptInfo(receipt))
+	callData, err := packExecute(forwarderReq, c.domainSeparator, c.request.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	claim := *c
+	claim.forwarderReq = &forwarderReq
+	claim.callData = callData
+	return &claim, nil
+}
+
+// prepareClaim validates the relay claim request and creates the forwarder call
+// that executes it.
+func prepareClaim(
+	ctx context.Context,
+	req *message.RelayClaimRequest,
+	ec extethclient.EthClient,
+	ourSFContractAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+) (*preparedClaim, error) {
+	err := validateClaimRequest(ctx, req, ec.Raw(), ourSFContractAddr, feeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	reqForwarderAddr, reqForwarder, domainSeparator, err := getRequestForwarder(ctx, ec.Raw(), req.SwapCreatorAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := reqForwarder.GetNonce(&bind.CallOpts{Context: ctx}, req.Swap.Claimer)
+	if err != nil {
+		return nil, err
+	}
+
+	// The size of request.Secret was vetted when it was deserialized
+	secret := (*[32]byte)(req.Secret)
+
+	forwarderReq, err := createForwarderRequest(nonce, req.SwapCreatorAddr, req.Swap, secret, req.FeeWei)
+	if err != nil {
+		return nil, err
+	}
+
+	callData, err := packExecute(*forwarderReq, *domainSeparator, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &preparedClaim{
+		request:         req,
+		forwarderAddr:   reqForwarderAddr,
+		forwarder:       reqForwarder,
+		forwarderReq:    forwarderReq,
+		domainSeparator: *domainSeparator,
+		callData:        callData,
+	}, nil
+}
+
+// getRequestForwarder returns the address, binding and domain separator of the
+// forwarder trusted by the swap creator contract of a relay request.
+func getRequestForwarder(
+	ctx context.Context,
+	ec *ethclient.Client,
+	swapCreatorAddr ethcommon.Address,
+) (ethcommon.Address, *gsnforwarder.Forwarder, *[32]byte, error) {
+	swapCreator, err := contracts.NewSwapCreator(swapCreatorAddr, ec)
+	if err != nil {
+		return ethcommon.Address{}, nil, nil, err
+	}
 
-	return &message.RelayClaimResponse{TxHash: tx.Hash()}, nil
+	forwarderAddr, err := swapCreator.TrustedForwarder(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return ethcommon.Address{}, nil, nil, err
+	}
+
+	forwarder, domainSeparator, err := getForwarderAndDomainSeparator(ctx, ec, forwarderAddr)
+	if err != nil {
+		return ethcommon.Address{}, nil, nil, err
+	}
+
+	return forwarderAddr, forwarder, domainSeparator, nil
 }
 
-// checkForMinClaimBalance verifies that we have enough gas to relay a claim and
-// returns the gas price that was used for the calculation.
-func checkForMinClaimBalance(ctx context.Context, ec extethclient.EthClient) (*big.Int, error) {
+// checkForMinClaimBalance verifies that we have enough ETH to pay for the given
+// amount of gas and returns the gas price that was used for the calculation.
+func checkForMinClaimBalance(ctx context.Context, ec extethclient.EthClient, gas uint64) (*big.Int, error) {
 	balance, err := ec.Balance(ctx)
 	if err != nil {
 		return nil, err
@@ -127,7 +466,7 @@ func checkForMinClaimBalance(ctx context.Context, ec extethclient.EthClient) (*b
 		return nil, err
 	}
 
-	txCost := new(big.Int).Mul(gasPrice, big.NewInt(forwarderClaimGas))
+	txCost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
 	if balance.BigInt().Cmp(txCost) < 0 {
 		return nil, fmt.Errorf("balance %s ETH is under the minimum %s ETH to relay claim",
 			balance.AsEtherString(), coins.FmtWeiAsETH(txCost))
@@ -136,24 +475,19 @@ func checkForMinClaimBalance(ctx context.Context, ec extethclient.EthClient) (*b
 	return gasPrice, nil
 }
 
-// simulateExecute calls the forwarder's execute method (defined in Forwarder.sol)
-// with CallContract which executes the method call without mining it into the blockchain.
-// https://pkg.go.dev/github.com/ethereum/go-ethereum/ethclient#Client.CallContract
-func simulateExecute(
-	ctx context.Context,
-	ec extethclient.EthClient,
-	reqForwarderAddr *ethcommon.Address,
-	txOpts *bind.TransactOpts,
+// packExecute packs the call of the forwarder's execute method (defined in
+// Forwarder.sol) with the forward request and its signature.
+func packExecute(
 	forwarderReq gsnforwarder.IForwarderForwardRequest,
 	domainSeparator [32]byte,
 	sig []byte,
-) error {
+) ([]byte, error) {
 	forwarderABI, err := gsnforwarder.ForwarderMetaData.GetAbi()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Pack the "execute" method call
-	packed, err := forwarderABI.Pack(
+
+	return forwarderABI.Pack(
 		"execute",
 		forwarderReq,
 		domainSeparator,
@@ -161,10 +495,39 @@ func simulateExecute(
 		[]byte{},
 		sig,
 	)
+}
+
+// unpackExecuteSuccess returns whether the forwarded call succeeded, given the
+// return data of the forwarder's execute method.
+func unpackExecuteSuccess(data []byte) (bool, error) {
+	forwarderABI, err := gsnforwarder.ForwarderMetaData.GetAbi()
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	response := struct {
+		Success bool
+		Ret     []byte
+	}{Success: false, Ret: []byte{}}
+
+	err = forwarderABI.UnpackIntoInterface(&response, "execute", data)
+	if err != nil {
+		return false, err
 	}
 
+	return response.Success, nil
+}
+
+// simulateExecute calls the forwarder's execute method (defined in Forwarder.sol)
+// with CallContract which executes the method call without mining it into the blockchain.
+// https://pkg.go.dev/github.com/ethereum/go-ethereum/ethclient#Client.CallContract
+func simulateExecute(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	reqForwarderAddr *ethcommon.Address,
+	txOpts *bind.TransactOpts,
+	callData []byte,
+) error {
 	callMessage := ethereum.CallMsg{
 		From:       txOpts.From,
 		To:         reqForwarderAddr,
@@ -173,8 +536,8 @@ func simulateExecute(
 		GasFeeCap:  txOpts.GasFeeCap,
 		GasTipCap:  txOpts.GasTipCap,
 		Value:      txOpts.Value,
-		Data:       packed,
-		AccessList: []types.AccessTuple{},
+		Data:       callData,
+		AccessList: []ethtypes.AccessTuple{},
 	}
 
 	// Call the "execute" method
@@ -183,18 +546,12 @@ func simulateExecute(
 		return err
 	}
 
-	// Unpack the response data
-	response := struct {
-		Success bool
-		Ret     []byte
-	}{Success: false, Ret: []byte{}}
-
-	err = forwarderABI.UnpackIntoInterface(&response, "execute", data)
+	success, err := unpackExecuteSuccess(data)
 	if err != nil {
 		return err
 	}
 
-	if !response.Success {
+	if !success {
 		return errors.New("relayed transaction failed on simulation")
 	}
 
@@ -92,15 +92,19 @@ func Test_ValidateAndSendTransaction(t *testing.T) {
 	secret := proof.Secret()
 
 	// now let's try to claim
-	req, err := CreateRelayClaimRequest(ctx, sk, ec.Raw(), swapCreatorAddr, forwarderAddr, swap, &secret)
+	fee := DefaultFeeConfig().Fee(swap.Value)
+	req, err := CreateRelayClaimRequest(ctx, ec.Signer(), ec.Raw(), swapCreatorAddr, forwarderAddr, swap, &secret, fee)
 	require.NoError(t, err)
 
-	resp, err := ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr)
+	relayed, err := ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
 	require.NoError(t, err)
+	require.Equal(t, fee, relayed.FeeWei)
 
-	receipt, err = block.WaitForReceipt(ctx, ec.Raw(), resp.TxHash)
+	receipt, err = block.WaitForReceipt(ctx, ec.Raw(), relayed.TxHash)
 	require.NoError(t, err)
 	t.Logf("gas cost to call Claim via relayer: %d", receipt.GasUsed)
+	require.Equal(t, new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice),
+		relayed.GasCostWei)
 
 	// expected 1 Claimed log (ERC20 swaps have 3, but we don't support relaying with ERC20 swaps)
 	require.Equal(t, 1, len(receipt.Logs))
@@ -111,9 +115,54 @@ func Test_ValidateAndSendTransaction(t *testing.T) {
 
 	// Now lets try to claim a second time and verify that we fail on the simulated
 	// execution.
-	req, err = CreateRelayClaimRequest(ctx, sk, ec.Raw(), swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err = CreateRelayClaimRequest(ctx, ec.Signer(), ec.Raw(), swapCreatorAddr, forwarderAddr, swap, &secret, fee)
 	require.NoError(t, err)
 
-	_, err = ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr)
+	_, err = ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
 	require.ErrorContains(t, err, "relayed transaction failed on simulation")
 }
+
+func Test_ValidateAndSendRefund_unsupported(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec := extethclient.CreateTestClient(t, ethKey)
+	secret := [32]byte{0x1}
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec.Raw(), ethKey)
+
+	swap := createTestRefundSwap(t, ethKey)
+	req, err := CreateRelayRefundRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec.Raw(), swapCreatorAddr,
+		forwarderAddr, swap, &secret, DefaultFeeConfig().Fee(swap.Value))
+	require.NoError(t, err)
+
+	// the SwapCreator bytecode that we deploy predates refundRelayer
+	_, err = ValidateAndSendRefund(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
+	require.ErrorIs(t, err, errRefundRelayerUnsupported)
+}
+
+func Test_ValidateAndSendNewSwap_unsupported(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec := extethclient.CreateTestClient(t, ethKey)
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec.Raw(), ethKey)
+	token := deployTestToken(t, ec.Raw(), ethKey)
+
+	req := createTestNewSwapRequest(t, ec.Raw(), ethKey, swapCreatorAddr, forwarderAddr, token)
+
+	// the SwapCreator bytecode that we deploy predates newSwapRelayer
+	_, err := ValidateAndSendNewSwap(ctx, req, ec, swapCreatorAddr, tokenFeeConfig(token))
+	require.ErrorIs(t, err, errNewSwapRelayerUnsupported)
+}
+
+func Test_ValidateAndSendSetReady_unsupported(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec := extethclient.CreateTestClient(t, ethKey)
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec.Raw(), ethKey)
+	token := deployTestToken(t, ec.Raw(), ethKey)
+
+	req := createTestSetReadyRequest(t, ec.Raw(), ethKey, swapCreatorAddr, forwarderAddr, token)
+
+	// the SwapCreator bytecode that we deploy predates setReadyRelayer
+	_, err := ValidateAndSendSetReady(ctx, req, ec, swapCreatorAddr, tokenFeeConfig(token))
+	require.ErrorIs(t, err, errSetReadyRelayerUnsupported)
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+var (
+	errNoTokenFeeRate      = errors.New("no relayer fee rate configured for token")
+	errInvalidTokenFeeRate = errors.New("invalid relayer fee rate")
+)
+
+// TokenFeeRates holds the exchange rates, in standard token units per ETH, at which
+// relayer fees of token swaps are paid in the swapped token. SwapCreator's
+// claimRelayer pays the fee of a token swap out of the claimed tokens, so a claimer
+// without ETH can have its claim relayed. A relayer requires the fee of its
+// configuration converted at its rate, and a claimer offers the fee of its
+// configuration converted at its own rate, accepting relayer quotes up to that fee.
+type TokenFeeRates map[ethcommon.Address]*apd.Decimal
+
+// tokenBaseUnitsPerWei returns the number of base units of the token that the rate
+// values one wei at. The rate must be positive.
+func tokenBaseUnitsPerWei(tokensPerETH *apd.Decimal, decimals uint8) (*big.Rat, error) {
+	if tokensPerETH == nil || tokensPerETH.Form != apd.Finite || tokensPerETH.Sign() <= 0 {
+		return nil, fmt.Errorf("%w %v, must be positive", errInvalidTokenFeeRate, tokensPerETH)
+	}
+
+	rate, ok := new(big.Rat).SetString(tokensPerETH.Text('f'))
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errInvalidTokenFeeRate, tokensPerETH)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	rate.Mul(rate, new(big.Rat).SetFrac(scale, big.NewInt(1e18)))
+	return rate, nil
+}
+
+// TokenFee returns the relayer fee, in base units of the token, for a swap of the
+// given value in base units of the token. The fee is the configured share of the
+// swap value, bounded by the configured minimum and maximum fees in ETH converted at
+// the token's rate.
+func (c *FeeConfig) TokenFee(value *big.Int, token *coins.ERC20TokenInfo) (*big.Int, error) {
+	tokensPerETH, ok := c.TokenRates[token.Address]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errNoTokenFeeRate, token.Address)
+	}
+
+	rate, err := tokenBaseUnitsPerWei(tokensPerETH, token.NumDecimals)
+	if err != nil {
+		return nil, fmt.Errorf("token %s: %w", token.Address, err)
+	}
+	toTokens := func(wei *big.Int) *big.Int {
+		r := new(big.Rat).Mul(new(big.Rat).SetInt(wei), rate)
+		return new(big.Int).Quo(r.Num(), r.Denom())
+	}
+
+	fee := new(big.Int).Mul(value, big.NewInt(int64(c.BasisPoints)))
+	fee.Quo(fee, big.NewInt(basisPointsPerUnit))
+
+	if minFee := toTokens(c.MinWei); fee.Cmp(minFee) < 0 {
+		fee = minFee
+	}
+
+	if maxFee := toTokens(c.MaxWei); fee.Cmp(maxFee) > 0 {
+		fee = maxFee
+	}
+
+	return fee, nil
+}
+
+// AssetFee returns the relayer fee for a swap of the given value of the asset, in
+// wei for ETH swaps, and in base units of the token for token swaps.
+func (c *FeeConfig) AssetFee(
+	ctx context.Context,
+	ec *ethclient.Client,
+	asset types.EthAsset,
+	value *big.Int,
+) (*big.Int, error) {
+	if asset == types.EthAssetETH {
+		return c.Fee(value), nil
+	}
+
+	if _, ok := c.TokenRates[asset.Address()]; !ok {
+		return nil, fmt.Errorf("%w %s", errNoTokenFeeRate, asset)
+	}
+
+	token, err := contracts.NewIERC20(asset.Address(), ec)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, err := token.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decimals of token %s: %w", asset, err)
+	}
+
+	return c.TokenFee(value, coins.NewERC20TokenInfo(asset.Address(), decimals, "", ""))
+}
+
+// FmtAssetFee formats a relayer fee of the asset for logs and errors.
+func FmtAssetFee(asset types.EthAsset, fee *big.Int) string {
+	if asset == types.EthAssetETH {
+		return coins.FmtWeiAsETH(fee) + " ETH"
+	}
+	return fmt.Sprintf("%s base units of token %s", fee, asset)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestFeeConfig_TokenFee(t *testing.T) {
+	usdc := coins.NewERC20TokenInfo(ethcommon.Address{0x1}, 6, "USD Coin", "USDC")
+
+	conf := DefaultFeeConfig()
+	conf.TokenRates = TokenFeeRates{usdc.Address: coins.StrToDecimal("2000")}
+
+	// 1% of 10 USDC is below the minimum fee of 0.001 ETH, 2 USDC
+	fee, err := conf.TokenFee(big.NewInt(10e6), usdc)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(2e6), fee)
+
+	// 1% of 500 USDC is within the bounds
+	fee, err = conf.TokenFee(big.NewInt(500e6), usdc)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5e6), fee)
+
+	// 1% of 5000 USDC is above the maximum fee of 0.009 ETH, 18 USDC
+	fee, err = conf.TokenFee(big.NewInt(5000e6), usdc)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(18e6), fee)
+
+	dai := coins.NewERC20TokenInfo(ethcommon.Address{0x2}, 18, "Dai Stablecoin", "DAI")
+	_, err = conf.TokenFee(big.NewInt(1e18), dai)
+	require.ErrorIs(t, err, errNoTokenFeeRate)
+
+	// invalid rates are errors, not panics
+	for _, rate := range []*apd.Decimal{coins.StrToDecimal("0"), coins.StrToDecimal("-1"), {Form: apd.NaN}, nil} {
+		conf.TokenRates = TokenFeeRates{usdc.Address: rate}
+		_, err = conf.TokenFee(big.NewInt(10e6), usdc)
+		require.ErrorIs(t, err, errInvalidTokenFeeRate)
+		require.ErrorContains(t, conf.Validate(), "must be positive")
+	}
+}
+
+func TestFeeConfig_Validate_tokenRates(t *testing.T) {
+	conf := DefaultFeeConfig()
+	conf.TokenRates = TokenFeeRates{{0x1}: coins.StrToDecimal("0")}
+	require.ErrorContains(t, conf.Validate(), "must be positive")
+}
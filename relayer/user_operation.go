@@ -0,0 +1,53 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/ethereum/erc4337"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+// ClaimWithUserOperation submits a signed relay claim request as an ERC-4337 user
+// operation, instead of sending it to a relayer. The claim account validates the
+// operation with the swap secret and the claimer's signature of the forward
+// request, so the claimer doesn't need ETH or an account of its own. The claimed
+// funds are sent to the claimer and the relayer fee goes to the bundler that
+// includes the operation.
+func ClaimWithUserOperation(
+	ctx context.Context,
+	account *erc4337.ClaimAccount,
+	ec *ethclient.Client,
+	forwarderAddr ethcommon.Address,
+	request *message.RelayClaimRequest,
+) (*erc4337.UserOperationReceipt, error) {
+	forwarder, domainSeparator, err := getForwarderAndDomainSeparator(ctx, ec, forwarderAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := forwarder.GetNonce(&bind.CallOpts{Context: ctx}, request.Swap.Claimer)
+	if err != nil {
+		return nil, err
+	}
+
+	// the same forward request that the claimer signed in CreateRelayClaimRequest
+	gas, _ := claimGas(request.Swap)
+
+	return account.Claim(ctx, &erc4337.Claim{
+		Swap:            *request.Swap,
+		Secret:          *(*[32]byte)(request.Secret),
+		Fee:             request.FeeWei,
+		Gas:             big.NewInt(gas),
+		Nonce:           nonce,
+		DomainSeparator: *domainSeparator,
+		Signature:       request.Signature,
+	})
+}
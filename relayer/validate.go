@@ -5,14 +5,15 @@ package relayer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 
-	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/net/message"
@@ -23,8 +24,9 @@ func validateClaimRequest(
 	request *message.RelayClaimRequest,
 	ec *ethclient.Client,
 	ourSFContractAddr ethcommon.Address,
+	feeConfig *FeeConfig,
 ) error {
-	err := validateClaimValues(ctx, request, ec, ourSFContractAddr)
+	err := validateClaimValues(ctx, request, ec, ourSFContractAddr, feeConfig)
 	if err != nil {
 		return err
 	}
@@ -34,14 +36,19 @@ func validateClaimRequest(
 
 // validateClaimValues validates the non-signature aspects of the claim request:
 //  1. the claim request's swap creator and forwarder contract bytecode matches ours
-//  2. the swap is for ETH and not an ERC20 token
-//  3. the swap value is strictly greater than the relayer fee
+//  2. the swap value is strictly greater than the relayer fee
+//  3. the relayer fee is at least the fee our configuration requires for the swap
+//     value, which for token swaps is paid in the token at our configured rate
 //  4. TODO: Validate that the swap exists and is in a claimable state?
+//
+// The counterparty of a token swap accepts any fee if it has no rate for the
+// token, as it wants its swap to complete.
 func validateClaimValues(
 	ctx context.Context,
 	request *message.RelayClaimRequest,
 	ec *ethclient.Client,
 	ourSwapCreatorAddr ethcommon.Address,
+	feeConfig *FeeConfig,
 ) error {
 	isTakerRelay := request.OfferID != nil
 
@@ -59,15 +66,38 @@ func validateClaimValues(
 		}
 	}
 
-	asset := types.EthAsset(request.Swap.Asset)
-	if asset != types.EthAssetETH {
-		return fmt.Errorf("relaying for ETH Asset %s is not supported", asset)
+	err := validateRelayerFee(ctx, ec, feeConfig, types.EthAsset(request.Swap.Asset), request.Swap.Value,
+		request.FeeWei)
+	if isTakerRelay && errors.Is(err, errNoTokenFeeRate) {
+		return nil
 	}
+	return err
+}
 
+// validateRelayerFee validates that the relayer fee is strictly less than the swap
+// value, and at least the fee our configuration requires for the swap value.
+func validateRelayerFee(
+	ctx context.Context,
+	ec *ethclient.Client,
+	feeConfig *FeeConfig,
+	asset types.EthAsset,
+	value *big.Int,
+	feeWei *big.Int,
+) error {
 	// The relayer fee must be strictly less than the swap value
-	if FeeWei.Cmp(request.Swap.Value) >= 0 {
-		return fmt.Errorf("swap value of %s ETH is too low to support %s ETH relayer fee",
-			coins.FmtWeiAsETH(request.Swap.Value), coins.FmtWeiAsETH(FeeWei))
+	if feeWei.Cmp(value) >= 0 {
+		return fmt.Errorf("swap value of %s is too low to support %s relayer fee",
+			FmtAssetFee(asset, value), FmtAssetFee(asset, feeWei))
+	}
+
+	requiredFee, err := feeConfig.AssetFee(ctx, ec, asset, value)
+	if err != nil {
+		return err
+	}
+
+	if feeWei.Cmp(requiredFee) < 0 {
+		return fmt.Errorf("relayer fee of %s is below the required %s",
+			FmtAssetFee(asset, feeWei), FmtAssetFee(asset, requiredFee))
 	}
 
 	return nil
@@ -80,39 +110,152 @@ func validateClaimSignature(
 	ec *ethclient.Client,
 	request *message.RelayClaimRequest,
 ) error {
-	callOpts := &bind.CallOpts{
-		Context: ctx,
-		From:    ethcommon.Address{0xFF}, // can be any value but zero, which will validate all signatures
+	secret := (*[32]byte)(request.Secret)
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createForwarderRequest(nonce, request.SwapCreatorAddr, request.Swap, secret, request.FeeWei)
 	}
 
-	swapCreator, err := contracts.NewSwapCreator(request.SwapCreatorAddr, ec)
+	return verifyForwardRequest(ctx, ec, request.SwapCreatorAddr, request.Swap.Claimer, request.Signature, newRequest)
+}
+
+// validateRefundRequest validates a relay refund request like a claim request
+// to an open relayer: the swap creator contract must have the same bytecode as
+// ours, the fee must satisfy our fee configuration, and the forward request must
+// be signed by the swap owner.
+func validateRefundRequest(
+	ctx context.Context,
+	request *message.RelayRefundRequest,
+	ec *ethclient.Client,
+	ourSwapCreatorAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+) error {
+	err := validateSwapCreatorCode(ctx, ec, request.SwapCreatorAddr, ourSwapCreatorAddr)
 	if err != nil {
 		return err
 	}
 
-	forwarderAddr, err := swapCreator.TrustedForwarder(&bind.CallOpts{Context: ctx})
+	err = validateRelayerFee(ctx, ec, feeConfig, types.EthAsset(request.Swap.Asset), request.Swap.Value,
+		request.FeeWei)
 	if err != nil {
 		return err
 	}
 
-	forwarder, domainSeparator, err := getForwarderAndDomainSeparator(ctx, ec, forwarderAddr)
+	secret := (*[32]byte)(request.Secret)
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createRefundForwarderRequest(nonce, request.SwapCreatorAddr, request.Swap, secret, request.FeeWei)
+	}
+
+	return verifyForwardRequest(ctx, ec, request.SwapCreatorAddr, request.Swap.Owner, request.Signature, newRequest)
+}
+
+// validateNewSwapRequest validates a relay new swap request like a refund request,
+// with the additional requirement that the swap is a token swap, as the relayer fee
+// is paid out of the owner's token balance.
+func validateNewSwapRequest(
+	ctx context.Context,
+	request *message.RelayNewSwapRequest,
+	ec *ethclient.Client,
+	ourSwapCreatorAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+) error {
+	asset := types.EthAsset(request.Asset)
+	if !asset.IsToken() {
+		return errRelayedETHSwap
+	}
+
+	err := validateSwapCreatorCode(ctx, ec, request.SwapCreatorAddr, ourSwapCreatorAddr)
 	if err != nil {
 		return err
 	}
 
-	nonce, err := forwarder.GetNonce(callOpts, request.Swap.Claimer)
+	err = validateRelayerFee(ctx, ec, feeConfig, asset, request.Value, request.FeeWei)
 	if err != nil {
 		return err
 	}
 
-	secret := (*[32]byte)(request.Secret)
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createNewSwapForwarderRequest(nonce, request)
+	}
 
-	forwarderRequest, err := createForwarderRequest(
-		nonce,
-		request.SwapCreatorAddr,
-		request.Swap,
-		secret,
-	)
+	return verifyForwardRequest(ctx, ec, request.SwapCreatorAddr, request.Owner, request.Signature, newRequest)
+}
+
+// validateSetReadyRequest validates a relay set ready request like a new swap
+// request.
+func validateSetReadyRequest(
+	ctx context.Context,
+	request *message.RelaySetReadyRequest,
+	ec *ethclient.Client,
+	ourSwapCreatorAddr ethcommon.Address,
+	feeConfig *FeeConfig,
+) error {
+	asset := types.EthAsset(request.Swap.Asset)
+	if !asset.IsToken() {
+		return errRelayedETHSwap
+	}
+
+	err := validateSwapCreatorCode(ctx, ec, request.SwapCreatorAddr, ourSwapCreatorAddr)
+	if err != nil {
+		return err
+	}
+
+	err = validateRelayerFee(ctx, ec, feeConfig, asset, request.Swap.Value, request.FeeWei)
+	if err != nil {
+		return err
+	}
+
+	newRequest := func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error) {
+		return createSetReadyForwarderRequest(nonce, request.SwapCreatorAddr, request.Swap, request.FeeWei,
+			request.Permit)
+	}
+
+	return verifyForwardRequest(ctx, ec, request.SwapCreatorAddr, request.Swap.Owner, request.Signature, newRequest)
+}
+
+// validateSwapCreatorCode validates that the swap creator contract of a request,
+// if it is not at the same address as our own, has the same bytecode as ours,
+// along with its forwarder.
+func validateSwapCreatorCode(
+	ctx context.Context,
+	ec *ethclient.Client,
+	swapCreatorAddr ethcommon.Address,
+	ourSwapCreatorAddr ethcommon.Address,
+) error {
+	if swapCreatorAddr == ourSwapCreatorAddr {
+		return nil
+	}
+
+	_, err := contracts.CheckSwapCreatorContractCode(ctx, ec, swapCreatorAddr)
+	return err
+}
+
+// verifyForwardRequest verifies the signature of the forward request that
+// newRequest creates with the signer's current nonce in the forwarder trusted by
+// the swap creator contract.
+func verifyForwardRequest(
+	ctx context.Context,
+	ec *ethclient.Client,
+	swapCreatorAddr ethcommon.Address,
+	signer ethcommon.Address,
+	signature []byte,
+	newRequest func(nonce *big.Int) (*gsnforwarder.IForwarderForwardRequest, error),
+) error {
+	callOpts := &bind.CallOpts{
+		Context: ctx,
+		From:    ethcommon.Address{0xFF}, // can be any value but zero, which will validate all signatures
+	}
+
+	_, forwarder, domainSeparator, err := getRequestForwarder(ctx, ec, swapCreatorAddr)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := forwarder.GetNonce(callOpts, signer)
+	if err != nil {
+		return err
+	}
+
+	forwarderRequest, err := newRequest(nonce)
 	if err != nil {
 		return err
 	}
@@ -123,7 +266,7 @@ func validateClaimSignature(
 		*domainSeparator,
 		gsnforwarder.ForwardRequestTypehash,
 		nil,
-		request.Signature,
+		signature,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to verify signature: %w", err)
@@ -6,7 +6,6 @@ package relayer
 import (
 	"context"
 	"crypto/ecdsa"
-	"fmt"
 	"math/big"
 	"testing"
 
@@ -16,6 +15,7 @@ import (
 
 	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	"github.com/athanorlabs/atomic-swap/tests"
 )
@@ -29,23 +29,44 @@ func TestValidateRelayerFee(t *testing.T) {
 	type testCase struct {
 		description string
 		value       *big.Int
+		fee         *big.Int
 		expectErr   string
 	}
 
 	testCases := []testCase{
 		{
 			description: "swap value equal to relayer fee",
-			value:       FeeWei,
+			value:       big.NewInt(9e15),
+			fee:         big.NewInt(9e15),
 			expectErr:   "swap value of 0.009 ETH is too low to support 0.009 ETH relayer fee",
 		},
 		{
 			description: "swap value less than relayer fee",
-			value:       new(big.Int).Sub(FeeWei, big.NewInt(1e15)),
+			value:       big.NewInt(8e15),
+			fee:         big.NewInt(9e15),
 			expectErr:   "swap value of 0.008 ETH is too low to support 0.009 ETH relayer fee",
 		},
 		{
-			description: "swap value larger than min fee",
-			value:       new(big.Int).Add(FeeWei, big.NewInt(1e15)),
+			description: "relayer fee below the configured percentage",
+			value:       big.NewInt(5e17),
+			fee:         big.NewInt(4e15),
+			expectErr:   "relayer fee of 0.004 ETH is below the required 0.005 ETH",
+		},
+		{
+			description: "relayer fee below the configured minimum",
+			value:       big.NewInt(1e16),
+			fee:         big.NewInt(1e14),
+			expectErr:   "relayer fee of 0.0001 ETH is below the required 0.001 ETH",
+		},
+		{
+			description: "relayer fee matching the configured percentage",
+			value:       big.NewInt(5e17),
+			fee:         big.NewInt(5e15),
+		},
+		{
+			description: "relayer fee capped at the configured maximum",
+			value:       big.NewInt(1e18),
+			fee:         big.NewInt(9e15),
 		},
 	}
 
@@ -66,9 +87,10 @@ func TestValidateRelayerFee(t *testing.T) {
 			SwapCreatorAddr: swapCreatorAddr,
 			Swap:            swap,
 			Secret:          make([]byte, 32),
+			FeeWei:          tc.fee,
 		}
 
-		err := validateClaimValues(ctx, request, ec, swapCreatorAddr)
+		err := validateClaimValues(ctx, request, ec, swapCreatorAddr, DefaultFeeConfig())
 		if tc.expectErr != "" {
 			require.ErrorContains(t, err, tc.expectErr, tc.description)
 		} else {
@@ -92,7 +114,7 @@ func Test_validateClaimValues_takerClaim_contractAddressNotEqualFail(t *testing.
 		Swap:            new(contracts.SwapCreatorSwap), // test fails before we validate this
 	}
 
-	err := validateClaimValues(context.Background(), request, nil, swapCreatorAddrOurs)
+	err := validateClaimValues(context.Background(), request, nil, swapCreatorAddrOurs, DefaultFeeConfig())
 	require.ErrorContains(t, err, "taker claim swap creator mismatch")
 }
 
@@ -111,7 +133,7 @@ func Test_validateClaimValues_dhtClaim_contractAddressNotEqual(t *testing.T) {
 		Swap:            new(contracts.SwapCreatorSwap), // test fails before we validate this
 	}
 
-	err := validateClaimValues(context.Background(), request, ec, swapCreatorAddr)
+	err := validateClaimValues(context.Background(), request, ec, swapCreatorAddr, DefaultFeeConfig())
 	require.ErrorContains(t, err, "contract address does not contain correct SwapCreator code")
 }
 
@@ -124,7 +146,8 @@ func Test_validateSignature(t *testing.T) {
 	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
 
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr, forwarderAddr, swap, &secret,
+		DefaultFeeConfig().Fee(swap.Value))
 	require.NoError(t, err)
 
 	// success path
@@ -146,16 +169,129 @@ func Test_validateClaimRequest(t *testing.T) {
 	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
 
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr, forwarderAddr, swap, &secret,
+		DefaultFeeConfig().Fee(swap.Value))
 	require.NoError(t, err)
 
 	// success path
-	err = validateClaimRequest(ctx, req, ec, swapCreatorAddr)
+	err = validateClaimRequest(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
 	require.NoError(t, err)
 
-	// test failure path by passing a non-eth asset
+	// test failure path by passing a token without a configured fee rate
 	asset := ethcommon.Address{0x1}
 	req.Swap.Asset = asset
-	err = validateClaimRequest(ctx, req, ec, swapCreatorAddr)
-	require.ErrorContains(t, err, fmt.Sprintf("relaying for ETH Asset %s is not supported", types.EthAsset(asset)))
+	err = validateClaimRequest(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
+	require.ErrorIs(t, err, errNoTokenFeeRate)
+}
+
+func Test_validateRefundRequest(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec, _ := tests.NewEthClient(t)
+	secret := [32]byte{0x1}
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
+
+	swap := createTestRefundSwap(t, ethKey)
+	req, err := CreateRelayRefundRequest(ctx, extethclient.NewPrivateKeySigner(ethKey), ec, swapCreatorAddr, forwarderAddr,
+		swap, &secret, DefaultFeeConfig().Fee(swap.Value))
+	require.NoError(t, err)
+
+	// success path
+	err = validateRefundRequest(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
+	require.NoError(t, err)
+
+	// a refund request is not a valid claim request of the swap claimer
+	claimReq := &message.RelayClaimRequest{
+		SwapCreatorAddr: req.SwapCreatorAddr,
+		Swap:            req.Swap,
+		Secret:          req.Secret,
+		Signature:       req.Signature,
+		FeeWei:          req.FeeWei,
+	}
+	err = validateClaimSignature(ctx, ec, claimReq)
+	require.ErrorContains(t, err, "failed to verify signature")
+
+	// failure path (tamper with an arbitrary byte of the signature)
+	req.Signature[10]++
+	err = validateRefundRequest(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
+	require.ErrorContains(t, err, "failed to verify signature")
+	req.Signature[10]--
+
+	// the fee must satisfy our fee configuration
+	req.FeeWei = new(big.Int).Sub(req.FeeWei, big.NewInt(1))
+	err = validateRefundRequest(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
+	require.ErrorContains(t, err, "is below the required")
+
+	// the swap creator contract must have the same bytecode as ours
+	req.SwapCreatorAddr = forwarderAddr
+	err = validateRefundRequest(ctx, req, ec, swapCreatorAddr, DefaultFeeConfig())
+	require.ErrorContains(t, err, "contract address does not contain correct SwapCreator code")
+}
+
+func Test_validateNewSwapRequest(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec, _ := tests.NewEthClient(t)
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
+	token := deployTestToken(t, ec, ethKey)
+	feeConfig := tokenFeeConfig(token)
+
+	req := createTestNewSwapRequest(t, ec, ethKey, swapCreatorAddr, forwarderAddr, token)
+
+	// success path
+	err := validateNewSwapRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.NoError(t, err)
+
+	// failure path (tamper with an arbitrary byte of the signature)
+	req.Signature[10]++
+	err = validateNewSwapRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.ErrorContains(t, err, "failed to verify signature")
+	req.Signature[10]--
+
+	// the signature covers the swap parameters
+	req.Claimer = ethcommon.Address{0x4}
+	err = validateNewSwapRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.ErrorContains(t, err, "failed to verify signature")
+	req.Claimer = ethcommon.Address{0x3}
+
+	// the fee must satisfy our fee configuration
+	req.FeeWei = new(big.Int).Sub(req.FeeWei, big.NewInt(1))
+	err = validateNewSwapRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.ErrorContains(t, err, "is below the required")
+
+	// only token swaps are relayed
+	req.Asset = ethcommon.Address(types.EthAssetETH)
+	err = validateNewSwapRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.ErrorIs(t, err, errRelayedETHSwap)
+}
+
+func Test_validateSetReadyRequest(t *testing.T) {
+	ctx := context.Background()
+	ethKey := tests.GetTakerTestKey(t)
+	ec, _ := tests.NewEthClient(t)
+	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
+	token := deployTestToken(t, ec, ethKey)
+	feeConfig := tokenFeeConfig(token)
+
+	req := createTestSetReadyRequest(t, ec, ethKey, swapCreatorAddr, forwarderAddr, token)
+
+	// success path
+	err := validateSetReadyRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.NoError(t, err)
+
+	// failure path (tamper with an arbitrary byte of the signature)
+	req.Signature[10]++
+	err = validateSetReadyRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.ErrorContains(t, err, "failed to verify signature")
+	req.Signature[10]--
+
+	// the fee must satisfy our fee configuration
+	req.FeeWei = new(big.Int).Sub(req.FeeWei, big.NewInt(1))
+	err = validateSetReadyRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.ErrorContains(t, err, "is below the required")
+
+	// the swap creator contract must have the same bytecode as ours
+	req.SwapCreatorAddr = forwarderAddr
+	err = validateSetReadyRequest(ctx, req, ec, swapCreatorAddr, feeConfig)
+	require.ErrorContains(t, err, "contract address does not contain correct SwapCreator code")
 }
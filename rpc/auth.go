@@ -0,0 +1,290 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gorilla/rpc/v2"
+)
+
+// AuthScope is the access level granted by an RPC auth token. Each scope includes
+// the methods of the scopes below it.
+type AuthScope int
+
+const (
+	// ScopeRead allows the methods that only read the state of swapd.
+	ScopeRead AuthScope = iota
+	// ScopePersonal additionally allows making, taking and cancelling swaps, and
+	// moving funds.
+	ScopePersonal
+	// ScopeAdmin allows all methods, including shutting down swapd, reading swap
+	// secrets and changing the daemon's configuration.
+	ScopeAdmin
+)
+
+var (
+	errUnauthorized = errors.New("missing or invalid auth token")
+	errForbidden    = errors.New("auth token scope does not allow method")
+)
+
+// String returns the name of the scope.
+func (s AuthScope) String() string {
+	switch s {
+	case ScopeRead:
+		return "read"
+	case ScopePersonal:
+		return "personal"
+	case ScopeAdmin:
+		return "admin"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s AuthScope) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *AuthScope) UnmarshalText(data []byte) error {
+	switch string(data) {
+	case "read":
+		*s = ScopeRead
+	case "personal":
+		*s = ScopePersonal
+	case "admin":
+		*s = ScopeAdmin
+	default:
+		return fmt.Errorf("unknown auth scope %q", string(data))
+	}
+	return nil
+}
+
+// AuthToken is a bearer token granting access to the RPC methods of its scope.
+type AuthToken struct {
+	Token string    `json:"token"`
+	Scope AuthScope `json:"scope"`
+}
+
+// ReadAuthTokensFile reads the JSON list of RPC auth tokens.
+func ReadAuthTokensFile(path string) ([]*AuthToken, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []*AuthToken
+	if err = json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("invalid auth tokens file %q: %w", path, err)
+	}
+
+	for _, t := range tokens {
+		if t.Token == "" {
+			return nil, fmt.Errorf("auth tokens file %q has an empty token", path)
+		}
+	}
+
+	return tokens, nil
+}
+
+// readMethods are the methods allowed by ScopeRead.
+var readMethods = map[string]struct{}{
+	"daemon_version":             {},
+	"daemon_status":              {},
+	"daemon_subscribeMoneroSync": {},
+	"net_addresses":              {},
+	"net_peers":                  {},
+	"net_natStatus":              {},
+	"net_queryAll":               {},
+	"net_discover":               {},
+	"net_discoverRelayers":       {},
+	"net_relayerStats":           {},
+	"net_queryPeer":              {},
+	"net_peerReputation":         {},
+	"net_listBanned":             {},
+	"net_stats":                  {},
+	"net_quote":                  {},
+	"net_subscribeOffers":        {},
+	"personal_getSwapTimeout":    {},
+	"personal_tokenInfo":         {},
+	"personal_supportedTokens":   {},
+	"personal_balances":          {},
+	"personal_profiles":          {},
+	"personal_incomingTransfers": {},
+	"personal_planConsolidation": {},
+	"personal_moneroNodeStatus":  {},
+	"personal_tokenAllowance":    {},
+	"personal_subscribeBalances": {},
+	"relayer_stats":              {},
+	"relayer_getAccessList":      {},
+	"swap_getPast":               {},
+	"swap_getOngoing":            {},
+	"swap_getStatus":             {},
+	"swap_getOffers":             {},
+	"swap_suggestedExchangeRate": {},
+	"swap_getContractEvents":     {},
+	"swap_export":                {},
+	"swap_estimateFees":          {},
+	"swap_subscribeStatus":       {},
+}
+
+// personalMethods are the methods allowed by ScopePersonal in addition to the
+// read methods.
+var personalMethods = map[string]struct{}{
+	"net_makeOffer":                 {},
+	"net_takeOffer":                 {},
+	"net_takeOfferSync":             {},
+	"net_makeOfferAndSubscribe":     {},
+	"net_takeOfferAndSubscribe":     {},
+	"personal_approveToken":         {},
+	"personal_revokeTokenAllowance": {},
+	"swap_clearOffers":              {},
+	"swap_cancel":                   {},
+	"swap_setXMRPriority":           {},
+	"signer_subscribe":              {},
+}
+
+// RequiredScope returns the scope needed to call the method. Methods that are not
+// listed as read or personal methods need ScopeAdmin, so new methods are only
+// available to admins until they are classified. The methods of ScopeRead don't
+// change the daemon's state.
+func RequiredScope(method string) AuthScope {
+	if _, ok := readMethods[method]; ok {
+		return ScopeRead
+	}
+	if _, ok := personalMethods[method]; ok {
+		return ScopePersonal
+	}
+	return ScopeAdmin
+}
+
+// checkMethodScope returns an error if the scope doesn't allow calling the method.
+func checkMethodScope(scope AuthScope, method string) error {
+	if required := RequiredScope(method); scope < required {
+		return fmt.Errorf("%w %s, which requires the %s scope", errForbidden, method, required)
+	}
+	return nil
+}
+
+// jsonRPCMethodName converts the "service.Method" name of a registered method back
+// to the "service_method" name that clients call it by.
+func jsonRPCMethodName(method string) string {
+	service, name, ok := strings.Cut(method, ".")
+	if !ok {
+		return method
+	}
+
+	r, n := utf8.DecodeRuneInString(name)
+	return fmt.Sprintf("%s_%s%s", service, string(unicode.ToLower(r)), name[n:])
+}
+
+type authScopeKey struct{}
+
+// authScopeFromContext returns the scope of the request's auth token. Requests
+// served without authentication have ScopeAdmin.
+func authScopeFromContext(ctx context.Context) AuthScope {
+	scope, ok := ctx.Value(authScopeKey{}).(AuthScope)
+	if !ok {
+		return ScopeAdmin
+	}
+	return scope
+}
+
+// authenticator checks the bearer tokens of requests against the configured auth
+// tokens. Authentication is disabled when no tokens are configured.
+type authenticator struct {
+	tokens []*AuthToken
+}
+
+func newAuthenticator(tokens []*AuthToken) *authenticator {
+	return &authenticator{tokens: tokens}
+}
+
+func (a *authenticator) enabled() bool {
+	return len(a.tokens) > 0
+}
+
+// requestToken returns the bearer token of the Authorization header. Browsers
+// can't set headers on websocket connections, so the token can also be passed as
+// the "token" query parameter.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// scopeOf returns the scope of the request's auth token.
+func (a *authenticator) scopeOf(r *http.Request) (AuthScope, error) {
+	token := requestToken(r)
+	if token == "" {
+		return 0, errUnauthorized
+	}
+
+	// The tokens are compared in constant time, and all of them are compared, so
+	// the response time doesn't tell how much of a token was guessed
+	found := false
+	var scope AuthScope
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t.Token)) == 1 && !found {
+			found = true
+			scope = t.Scope
+		}
+	}
+
+	if !found {
+		return 0, errUnauthorized
+	}
+
+	return scope, nil
+}
+
+// middleware rejects requests without a valid auth token, and adds the scope of
+// the token to the context of the others.
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	if !a.enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope, err := a.scopeOf(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authScopeKey{}, scope)))
+	})
+}
+
+// requireScope returns the handler rejecting requests whose auth token doesn't
+// have the scope.
+func requireScope(scope AuthScope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authScopeFromContext(r.Context()) < scope {
+			http.Error(w, fmt.Sprintf("auth token scope does not allow %s, which requires the %s scope",
+				r.URL.Path, scope), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateMethodScope is the JSON-RPC server's request validation function,
+// rejecting calls of methods that the request's auth token doesn't allow.
+func validateMethodScope(info *rpc.RequestInfo, _ any) error {
+	return checkMethodScope(authScopeFromContext(info.Request.Context()), jsonRPCMethodName(info.Method))
+}
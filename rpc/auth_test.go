@@ -0,0 +1,90 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRPCMethodName(t *testing.T) {
+	require.Equal(t, "daemon_shutdown", jsonRPCMethodName("daemon.Shutdown"))
+	require.Equal(t, "net_queryAll", jsonRPCMethodName("net.QueryAll"))
+}
+
+func TestCheckMethodScope(t *testing.T) {
+	require.NoError(t, checkMethodScope(ScopeRead, "swap_getOngoing"))
+	require.ErrorIs(t, checkMethodScope(ScopeRead, "net_makeOffer"), errForbidden)
+	require.NoError(t, checkMethodScope(ScopePersonal, "net_makeOffer"))
+	require.ErrorIs(t, checkMethodScope(ScopePersonal, "daemon_shutdown"), errForbidden)
+	require.NoError(t, checkMethodScope(ScopeAdmin, "daemon_shutdown"))
+
+	// unclassified methods need the admin scope
+	require.ErrorIs(t, checkMethodScope(ScopePersonal, "personal_newMethod"), errForbidden)
+}
+
+func TestReadAuthTokensFile(t *testing.T) {
+	file := path.Join(t.TempDir(), "tokens.json")
+	err := os.WriteFile(file, []byte(`[{"token":"abc","scope":"read"},{"token":"xyz","scope":"admin"}]`), 0600)
+	require.NoError(t, err)
+
+	tokens, err := ReadAuthTokensFile(file)
+	require.NoError(t, err)
+	require.Equal(t, []*AuthToken{{Token: "abc", Scope: ScopeRead}, {Token: "xyz", Scope: ScopeAdmin}}, tokens)
+
+	err = os.WriteFile(file, []byte(`[{"token":"abc","scope":"root"}]`), 0600)
+	require.NoError(t, err)
+	_, err = ReadAuthTokensFile(file)
+	require.ErrorContains(t, err, `unknown auth scope "root"`)
+}
+
+func TestAuthenticator_middleware(t *testing.T) {
+	auth := newAuthenticator([]*AuthToken{{Token: "reader", Scope: ScopeRead}})
+
+	var scope AuthScope
+	handler := auth.middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		scope = authScopeFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Bearer reader")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, ScopeRead, scope)
+
+	// websocket clients in browsers pass the token as a query parameter
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws?token=reader", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuthenticator_disabled(t *testing.T) {
+	auth := newAuthenticator(nil)
+
+	var scope AuthScope
+	handler := auth.middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		scope = authScopeFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, ScopeAdmin, scope)
+}
@@ -0,0 +1,189 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/websocket"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+const (
+	defaultBalancesInterval = 30 * time.Second
+	minBalancesInterval     = 5 * time.Second
+)
+
+// Assets of balance events that aren't tokens
+const (
+	balanceAssetETH = "ETH"
+	balanceAssetXMR = "XMR"
+)
+
+// balances holds the balances of the assets of a balance subscription, in standard
+// units, by asset.
+type balances map[string]*apd.Decimal
+
+// balanceThresholds returns the thresholds of the subscription by asset, after
+// validating them.
+func balanceThresholds(params *rpctypes.SubscribeBalancesRequest) (balances, error) {
+	thresholds := make(balances)
+	add := func(name string, asset string, threshold *apd.Decimal) error {
+		if threshold == nil {
+			return nil
+		}
+		if err := coins.ValidatePositive(name, coins.NumEtherDecimals, threshold); err != nil {
+			return err
+		}
+		thresholds[asset] = threshold
+		return nil
+	}
+
+	if err := add("ethThreshold", balanceAssetETH, params.ETHThreshold); err != nil {
+		return nil, err
+	}
+	if err := add("xmrThreshold", balanceAssetXMR, params.XMRThreshold); err != nil {
+		return nil, err
+	}
+
+	tracked := make(map[ethcommon.Address]struct{}, len(params.TokenAddrs))
+	for _, addr := range params.TokenAddrs {
+		tracked[addr] = struct{}{}
+	}
+
+	for addr, threshold := range params.TokenThresholds {
+		if _, ok := tracked[addr]; !ok {
+			return nil, fmt.Errorf("threshold of token %s that is not tracked", addr)
+		}
+		if err := add("tokenThresholds", addr.Hex(), threshold); err != nil {
+			return nil, err
+		}
+	}
+
+	return thresholds, nil
+}
+
+// queryBalances returns the current balances of ETH, XMR and the tokens.
+func (s *wsServer) queryBalances(ctx context.Context, tokenAddrs []ethcommon.Address) (balances, error) {
+	ec := s.backend.ETHClient()
+
+	weiBalance, err := ec.Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	xmrBalance, err := s.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		return nil, err
+	}
+
+	next := balances{
+		balanceAssetETH: weiBalance.AsEther(),
+		balanceAssetXMR: coins.NewPiconeroAmount(xmrBalance.Balance).AsMonero(),
+	}
+
+	for _, addr := range tokenAddrs {
+		var tokenBalance *coins.ERC20TokenAmount
+		tokenBalance, err = ec.ERC20Balance(ctx, addr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get balance for %s: %w", addr, err)
+		}
+		next[addr.Hex()] = tokenBalance.AsStandard()
+	}
+
+	return next, nil
+}
+
+// diffBalances returns the events of the change from the previous to the next
+// balances, sorted by asset. A balance below its threshold is only reported as low
+// if the previous balance wasn't.
+func diffBalances(prev, next, thresholds balances) []*rpctypes.BalanceEvent {
+	var events []*rpctypes.BalanceEvent
+	for asset, balance := range next {
+		previous, ok := prev[asset]
+		if ok && previous.Cmp(balance) == 0 {
+			continue
+		}
+
+		events = append(events, &rpctypes.BalanceEvent{
+			Type:     rpctypes.BalanceChanged,
+			Asset:    asset,
+			Balance:  balance,
+			Previous: previous,
+		})
+
+		threshold, ok := thresholds[asset]
+		if !ok || balance.Cmp(threshold) >= 0 {
+			continue
+		}
+		if previous != nil && previous.Cmp(threshold) < 0 {
+			continue
+		}
+
+		events = append(events, &rpctypes.BalanceEvent{
+			Type:      rpctypes.BalanceLow,
+			Asset:     asset,
+			Balance:   balance,
+			Previous:  previous,
+			Threshold: threshold,
+		})
+	}
+
+	// The change of an asset goes before its low balance event
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Asset < events[j].Asset
+	})
+
+	return events
+}
+
+// subscribeBalances writes the changes of our ETH, XMR and token balances to the
+// connection each time they are checked, starting with all balances of the first
+// check, and the balances that drop below their thresholds.
+// example: `{"jsonrpc":"2.0", "method":"personal_subscribeBalances", "params": {"interval": 10}, "id": 0}`
+func (s *wsServer) subscribeBalances(ctx context.Context, conn *websocket.Conn,
+	params *rpctypes.SubscribeBalancesRequest) error {
+	interval := time.Duration(params.Interval) * time.Second
+	switch {
+	case params.Interval == 0:
+		interval = defaultBalancesInterval
+	case interval < minBalancesInterval:
+		return fmt.Errorf("balances interval must be at least %s", minBalancesInterval)
+	}
+
+	thresholds, err := balanceThresholds(params)
+	if err != nil {
+		return err
+	}
+
+	tokenAddrs := uniqueTokenAddrs(params.TokenAddrs)
+
+	var prev, next balances
+	for {
+		next, err = s.queryBalances(ctx, tokenAddrs)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range diffBalances(prev, next, thresholds) {
+			if err = writeResponse(conn, event); err != nil {
+				return err
+			}
+		}
+		prev = next
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
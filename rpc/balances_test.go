@@ -0,0 +1,76 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+func TestDiffBalances(t *testing.T) {
+	thresholds := balances{balanceAssetETH: apd.New(1, 0)}
+	first := balances{
+		balanceAssetETH: apd.New(2, 0),
+		balanceAssetXMR: apd.New(5, 0),
+	}
+
+	// every balance is reported as changed by the first check
+	events := diffBalances(nil, first, thresholds)
+	require.Len(t, events, 2)
+	require.Equal(t, rpctypes.BalanceChanged, events[0].Type)
+	require.Equal(t, balanceAssetETH, events[0].Asset)
+	require.Nil(t, events[0].Previous)
+	require.Equal(t, balanceAssetXMR, events[1].Asset)
+	require.Empty(t, diffBalances(first, first, thresholds))
+
+	// an equal balance of a different exponent is unchanged
+	second := balances{
+		balanceAssetETH: apd.New(5, -1),
+		balanceAssetXMR: apd.New(50, -1),
+	}
+	expected := []*rpctypes.BalanceEvent{
+		{Type: rpctypes.BalanceChanged, Asset: balanceAssetETH, Balance: second[balanceAssetETH],
+			Previous: first[balanceAssetETH]},
+		{Type: rpctypes.BalanceLow, Asset: balanceAssetETH, Balance: second[balanceAssetETH],
+			Previous: first[balanceAssetETH], Threshold: thresholds[balanceAssetETH]},
+	}
+	require.Equal(t, expected, diffBalances(first, second, thresholds))
+
+	// a balance that stays low is not reported as low again
+	third := balances{
+		balanceAssetETH: apd.New(4, -1),
+		balanceAssetXMR: second[balanceAssetXMR],
+	}
+	events = diffBalances(second, third, thresholds)
+	require.Len(t, events, 1)
+	require.Equal(t, rpctypes.BalanceChanged, events[0].Type)
+}
+
+func TestBalanceThresholds(t *testing.T) {
+	token := ethcommon.Address{1}
+	params := &rpctypes.SubscribeBalancesRequest{
+		TokenAddrs:      []ethcommon.Address{token},
+		XMRThreshold:    apd.New(1, 0),
+		TokenThresholds: map[ethcommon.Address]*apd.Decimal{token: apd.New(100, 0)},
+	}
+
+	thresholds, err := balanceThresholds(params)
+	require.NoError(t, err)
+	require.Len(t, thresholds, 2)
+	require.Zero(t, thresholds[balanceAssetXMR].Cmp(apd.New(1, 0)))
+	require.Zero(t, thresholds[token.Hex()].Cmp(apd.New(100, 0)))
+
+	params.TokenThresholds[ethcommon.Address{2}] = apd.New(1, 0)
+	_, err = balanceThresholds(params)
+	require.ErrorContains(t, err, "is not tracked")
+
+	params = &rpctypes.SubscribeBalancesRequest{ETHThreshold: apd.New(0, 0)}
+	_, err = balanceThresholds(params)
+	require.ErrorContains(t, err, `"ethThreshold" must be non-zero`)
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// BanPeer bans the peer for the duration, or permanently if the duration is zero.
+// We close our connections to the peer, and refuse new ones, until it is unbanned.
+func (s *NetService) BanPeer(_ *http.Request, req *rpctypes.BanPeerRequest, _ *interface{}) error {
+	return s.net.BanPeer(req.PeerID, time.Duration(req.Duration)*time.Second, req.Reason)
+}
+
+// UnbanPeer removes the ban of the peer, if it is banned.
+func (s *NetService) UnbanPeer(_ *http.Request, req *rpctypes.UnbanPeerRequest, _ *interface{}) error {
+	return s.net.UnbanPeer(req.PeerID)
+}
+
+// ListBanned returns the banned peers, including the ones that were banned
+// automatically for misbehaving.
+func (s *NetService) ListBanned(_ *http.Request, _ *interface{}, resp *rpctypes.ListBannedResponse) error {
+	banned := s.net.BannedPeers()
+	resp.Peers = make([]*rpctypes.BannedPeer, 0, len(banned))
+	for _, bp := range banned {
+		resp.Peers = append(resp.Peers, &rpctypes.BannedPeer{
+			PeerID: bp.PeerID,
+			Until:  bp.Until,
+			Reason: bp.Reason,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+func TestNet_BanPeer(t *testing.T) {
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, nil, nil, nil, false)
+
+	other := peer.ID("other")
+	err := ns.BanPeer(nil, &rpctypes.BanPeerRequest{PeerID: testPeerID, Reason: "spam"}, nil)
+	require.NoError(t, err)
+	err = ns.BanPeer(nil, &rpctypes.BanPeerRequest{PeerID: other, Duration: 60}, nil)
+	require.NoError(t, err)
+
+	resp := new(rpctypes.ListBannedResponse)
+	require.NoError(t, ns.ListBanned(nil, nil, resp))
+	require.Len(t, resp.Peers, 2)
+	require.Equal(t, testPeerID, resp.Peers[0].PeerID)
+	require.Nil(t, resp.Peers[0].Until)
+	require.Equal(t, "spam", resp.Peers[0].Reason)
+	require.Equal(t, other, resp.Peers[1].PeerID)
+	require.NotNil(t, resp.Peers[1].Until)
+	require.WithinDuration(t, time.Now().Add(time.Minute), *resp.Peers[1].Until, time.Second*5)
+
+	err = ns.UnbanPeer(nil, &rpctypes.UnbanPeerRequest{PeerID: testPeerID}, nil)
+	require.NoError(t, err)
+
+	resp = new(rpctypes.ListBannedResponse)
+	require.NoError(t, ns.ListBanned(nil, nil, resp))
+	require.Len(t, resp.Peers, 1)
+	require.Equal(t, other, resp.Peers[0].PeerID)
+}
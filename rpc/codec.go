@@ -25,7 +25,7 @@ func NewCodec() *Codec {
 // NewRequest ...
 func (c *Codec) NewRequest(req *http.Request) rpc.CodecRequest {
 	outer := &CodecRequest{}
-	inner := json2.NewCodec().NewRequest(req)
+	inner := json2.NewCustomCodecWithErrorMapper(rpc.DefaultEncoderSelector, mapError).NewRequest(req)
 	outer.CodecRequest = inner.(*json2.CodecRequest)
 	return outer
 }
@@ -0,0 +1,60 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/handlers"
+)
+
+// corsAllowAll is the origin that allows requests from any origin.
+const corsAllowAll = "*"
+
+// CORSConfig is the cross-origin resource sharing policy of the RPC server, which
+// decides which web frontends can call swapd from a browser.
+type CORSConfig struct {
+	// AllowedOrigins are the origins, like "https://swap.example.org", whose pages
+	// may call the HTTP endpoints and open websockets. "*" allows all origins.
+	AllowedOrigins []string
+	// AllowedMethods are the HTTP methods that the pages may use.
+	AllowedMethods []string
+}
+
+// DefaultCORSConfig returns the default policy, which allows all origins.
+func DefaultCORSConfig() *CORSConfig {
+	return &CORSConfig{
+		AllowedOrigins: []string{corsAllowAll},
+		AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "OPTIONS"},
+	}
+}
+
+// handler returns the handler adding the policy's CORS headers to the responses
+// of the handler.
+func (c *CORSConfig) handler(h http.Handler) http.Handler {
+	headersOk := handlers.AllowedHeaders([]string{"content-type", "authorization", "username", "password"})
+	methodsOk := handlers.AllowedMethods(c.AllowedMethods)
+	originsOk := handlers.AllowedOrigins(c.AllowedOrigins)
+	return handlers.CORS(headersOk, methodsOk, originsOk)(h)
+}
+
+// checkOrigin returns whether a websocket connection may be opened from the
+// request's origin. Browsers don't apply CORS to websockets, so the policy is
+// enforced on the upgrade request. Clients that are not browsers send no origin
+// and are always allowed.
+func (c *CORSConfig) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == corsAllowAll || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
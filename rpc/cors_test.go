@@ -0,0 +1,56 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSConfig_checkOrigin(t *testing.T) {
+	newRequest := func(origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+
+	cors := &CORSConfig{AllowedOrigins: []string{"https://swap.example.org"}}
+	require.True(t, cors.checkOrigin(newRequest("https://swap.example.org")))
+	require.True(t, cors.checkOrigin(newRequest("HTTPS://Swap.Example.org")))
+	require.False(t, cors.checkOrigin(newRequest("https://evil.example.org")))
+	require.True(t, cors.checkOrigin(newRequest(""))) // not a browser
+
+	require.True(t, DefaultCORSConfig().checkOrigin(newRequest("https://evil.example.org")))
+}
+
+func TestCORSConfig_handler(t *testing.T) {
+	cors := &CORSConfig{
+		AllowedOrigins: []string{"https://swap.example.org"},
+		AllowedMethods: []string{http.MethodPost},
+	}
+	h := cors.handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for origin, allowed := range map[string]bool{
+		"https://swap.example.org": true,
+		"https://evil.example.org": false,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if allowed {
+			require.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"))
+		} else {
+			require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		}
+	}
+}
@@ -1,30 +1,166 @@
 package rpc
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 
 	"github.com/athanorlabs/atomic-swap/cliutil"
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
-// DaemonService handles RPC requests for swapd version, administration and (in the future) status requests.
+const (
+	defaultDrainTimeout = 30 * time.Minute
+	drainPollInterval   = 5 * time.Second
+)
+
+// DaemonService handles RPC requests for swapd version, administration and status requests.
 type DaemonService struct {
+	ctx        context.Context
 	stopServer func()
 	pb         ProtocolBackend
+	net        Net
+	xmrmaker   XMRMaker
+
+	// drainDeadline is when a draining shutdown stops waiting for swaps, zero if
+	// swapd is not draining
+	drainMu       sync.Mutex
+	drainDeadline time.Time
 }
 
 // NewDaemonService ...
-func NewDaemonService(stopServer func(), pb ProtocolBackend) *DaemonService {
-	return &DaemonService{stopServer, pb}
+func NewDaemonService(
+	ctx context.Context,
+	stopServer func(),
+	pb ProtocolBackend,
+	net Net,
+	xmrmaker XMRMaker,
+) *DaemonService {
+	return &DaemonService{
+		ctx:        ctx,
+		stopServer: stopServer,
+		pb:         pb,
+		net:        net,
+		xmrmaker:   xmrmaker,
+	}
+}
+
+// ShutdownRequest ...
+type ShutdownRequest struct {
+	// Drain, if set, refuses new swaps and waits for the ongoing swaps to reach a
+	// checkpoint before shutting down
+	Drain bool `json:"drain"`
+	// Timeout is the maximum number of seconds to wait for the ongoing swaps when
+	// draining, 30 minutes if zero
+	Timeout uint64 `json:"timeout"`
+}
+
+// Shutdown swapd. A draining shutdown returns immediately, shutting swapd down in
+// the background once every ongoing swap has locked its funds or completed, or when
+// the timeout expires. Swaps with locked funds are stored in the recovery database and
+// resumed when swapd restarts, while swaps that haven't locked funds are aborted on
+// restart. The progress of the drain is reported by daemon_status.
+func (s *DaemonService) Shutdown(_ *http.Request, req *ShutdownRequest, _ *any) error {
+	if req == nil || !req.Drain || s.pb == nil {
+		s.stopServer()
+		return nil
+	}
+
+	timeout := time.Duration(req.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = defaultDrainTimeout
+	}
+
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	if !s.drainDeadline.IsZero() {
+		return nil // already draining
+	}
+
+	s.pb.SetPaused(true)
+	s.drainDeadline = time.Now().Add(timeout)
+	log.Infof("draining, shutting down when ongoing swaps have locked funds or in %s", timeout)
+	go s.drain(s.drainDeadline)
+	return nil
 }
 
-// Shutdown swapd
-func (s *DaemonService) Shutdown(_ *http.Request, _ *any, _ *any) error {
-	s.stopServer()
+// drain shuts swapd down once no ongoing swap is waiting to lock funds, or when
+// the deadline passes.
+func (s *DaemonService) drain(deadline time.Time) {
+	for {
+		ongoing, err := s.pb.SwapManager().GetOngoingSwaps()
+		pending := unlockedSwaps(ongoing)
+		switch {
+		case err != nil:
+			log.Warnf("failed to get ongoing swaps while draining: %s", err)
+		case len(pending) == 0:
+			log.Info("drained, shutting down")
+			s.stopServer()
+			return
+		}
+
+		if time.Now().After(deadline) {
+			for _, info := range pending {
+				log.Warnf("shutting down with swap %s at status %s", info.OfferID, info.Status)
+			}
+			s.stopServer()
+			return
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// unlockedSwaps returns the ongoing swaps that haven't locked funds yet, which a
+// draining shutdown waits for.
+func unlockedSwaps(ongoing []*swap.Info) []*swap.Info {
+	var unlocked []*swap.Info
+	for _, info := range ongoing {
+		if info.Status == types.ExpectingKeys || info.Status == types.KeysExchanged {
+			unlocked = append(unlocked, info)
+		}
+	}
+	return unlocked
+}
+
+// Pause stops swapd from taking offers and accepting takes of its offers, while
+// ongoing swaps continue until they complete.
+func (s *DaemonService) Pause(_ *http.Request, _ *any, _ *any) error {
+	if s.pb == nil {
+		return errUnsupportedForBootnode
+	}
+
+	s.pb.SetPaused(true)
+	log.Info("paused, not accepting new swaps")
+	return nil
+}
+
+// Resume lets swapd take offers and accept takes of its offers again after a pause.
+// A draining shutdown can't be resumed.
+func (s *DaemonService) Resume(_ *http.Request, _ *any, _ *any) error {
+	if s.pb == nil {
+		return errUnsupportedForBootnode
+	}
+
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	if !s.drainDeadline.IsZero() {
+		return errDraining
+	}
+
+	s.pb.SetPaused(false)
+	log.Info("resumed accepting new swaps")
 	return nil
 }
 
@@ -44,3 +180,103 @@ func (s *DaemonService) Version(_ *http.Request, _ *any, resp *VersionResponse)
 	resp.SwapCreatorAddr = s.pb.SwapCreatorAddr()
 	return nil
 }
+
+// StatusResponse ...
+type StatusResponse struct {
+	EthHeight          uint64 `json:"ethHeight"`
+	EthHighestBlock    uint64 `json:"ethHighestBlock"`
+	EthSynced          bool   `json:"ethSynced"`
+	MoneroWalletHeight uint64 `json:"moneroWalletHeight"`
+	MoneroDaemonHeight uint64 `json:"moneroDaemonHeight"`
+	MoneroSynced       bool   `json:"moneroSynced"`
+	Peers              int    `json:"peers"`
+	OngoingSwaps       int    `json:"ongoingSwaps"`
+	OpenOffers         int    `json:"openOffers"`
+	Paused             bool   `json:"paused"`
+	Ready              bool   `json:"ready"`
+
+	// progress of the monero wallet's scan since it fell behind, and its estimated
+	// remaining time, which is unset if it is synced or not known yet
+	MoneroSyncPercent    float64 `json:"moneroSyncPercent"`
+	MoneroSyncETASeconds uint64  `json:"moneroSyncEtaSeconds,omitempty"`
+
+	// set while a draining shutdown is waiting for ongoing swaps
+	Draining      bool       `json:"draining"`
+	DrainPending  int        `json:"drainPending,omitempty"`
+	DrainDeadline *time.Time `json:"drainDeadline,omitempty"`
+}
+
+// Status returns the sync state of the ethereum and monero endpoints, the number of
+// connected peers, ongoing swaps and open offers, whether the daemon is paused, and
+// whether it is ready to accept swaps. A bootnode only reports its peers and is never
+// ready.
+func (s *DaemonService) Status(r *http.Request, _ *any, resp *StatusResponse) error {
+	if s.net != nil {
+		resp.Peers = len(s.net.ConnectedPeers())
+	}
+
+	if s.pb == nil {
+		return nil
+	}
+
+	if err := s.setEthStatus(r.Context(), resp); err != nil {
+		return fmt.Errorf("failed to get ethereum sync status: %w", err)
+	}
+
+	progress, err := s.pb.XMRClient().GetSyncProgress()
+	if err != nil {
+		return fmt.Errorf("failed to get monero sync status: %w", err)
+	}
+	resp.MoneroWalletHeight = progress.WalletHeight
+	resp.MoneroDaemonHeight = progress.DaemonHeight
+	resp.MoneroSynced = progress.Synced
+	resp.MoneroSyncPercent = progress.Percent
+	resp.MoneroSyncETASeconds = uint64(progress.ETA.Seconds())
+
+	ongoing, err := s.pb.SwapManager().GetOngoingSwaps()
+	if err != nil {
+		return err
+	}
+	resp.OngoingSwaps = len(ongoing)
+	resp.Paused = s.pb.Paused()
+
+	s.drainMu.Lock()
+	deadline := s.drainDeadline
+	s.drainMu.Unlock()
+	if !deadline.IsZero() {
+		resp.Draining = true
+		resp.DrainPending = len(unlockedSwaps(ongoing))
+		resp.DrainDeadline = &deadline
+	}
+
+	if s.xmrmaker != nil {
+		resp.OpenOffers = len(s.xmrmaker.GetOffers())
+	}
+
+	resp.Ready = resp.EthSynced && resp.MoneroSynced && resp.Peers > 0
+	return nil
+}
+
+func (s *DaemonService) setEthStatus(ctx context.Context, resp *StatusResponse) error {
+	ec := s.pb.ETHClient().Raw()
+
+	height, err := ec.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	// SyncProgress is nil when the node is not syncing
+	progress, err := ec.SyncProgress(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp.EthHeight = height
+	resp.EthHighestBlock = height
+	resp.EthSynced = progress == nil
+	if progress != nil {
+		resp.EthHighestBlock = progress.HighestBlock
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+type mockConnectedNet struct {
+	mockNet
+	peers []string
+}
+
+func (m *mockConnectedNet) ConnectedPeers() []string {
+	return m.peers
+}
+
+func TestDaemon_Status_bootnode(t *testing.T) {
+	net := &mockConnectedNet{peers: []string{"/ip4/127.0.0.1/tcp/9900", "/ip4/127.0.0.1/tcp/9901"}}
+	s := NewDaemonService(context.Background(), func() {}, nil, net, nil)
+
+	resp := new(StatusResponse)
+	err := s.Status(httptest.NewRequest("POST", "/", nil), nil, resp)
+	require.NoError(t, err)
+	require.Equal(t, 2, resp.Peers)
+	require.False(t, resp.Ready)
+}
+
+func TestDaemon_PauseResume(t *testing.T) {
+	pb := newMockProtocolBackend()
+	s := NewDaemonService(context.Background(), func() {}, pb, new(mockNet), nil)
+
+	require.NoError(t, s.Pause(nil, nil, nil))
+	require.True(t, pb.Paused())
+
+	require.NoError(t, s.Resume(nil, nil, nil))
+	require.False(t, pb.Paused())
+}
+
+func TestDaemon_Pause_bootnode(t *testing.T) {
+	s := NewDaemonService(context.Background(), func() {}, nil, new(mockNet), nil)
+	require.ErrorIs(t, s.Pause(nil, nil, nil), errUnsupportedForBootnode)
+	require.ErrorIs(t, s.Resume(nil, nil, nil), errUnsupportedForBootnode)
+}
+
+func TestDaemon_Shutdown_drain(t *testing.T) {
+	stopped := make(chan struct{})
+	pb := newMockProtocolBackend()
+	s := NewDaemonService(context.Background(), func() { close(stopped) }, pb, new(mockNet), nil)
+
+	err := s.Shutdown(nil, &ShutdownRequest{Drain: true, Timeout: 60}, nil)
+	require.NoError(t, err)
+	require.True(t, pb.Paused())
+	require.ErrorIs(t, s.Resume(nil, nil, nil), errDraining)
+
+	// there are no ongoing swaps, so the drain shuts down right away
+	select {
+	case <-stopped:
+	case <-time.After(testTimeout):
+		t.Fatal("drain did not shut down")
+	}
+}
+
+func TestUnlockedSwaps(t *testing.T) {
+	ongoing := []*swap.Info{
+		{OfferID: types.Hash{1}, Status: types.ExpectingKeys},
+		{OfferID: types.Hash{2}, Status: types.KeysExchanged},
+		{OfferID: types.Hash{3}, Status: types.ETHLocked},
+		{OfferID: types.Hash{4}, Status: types.XMRLocked},
+	}
+
+	unlocked := unlockedSwaps(ongoing)
+	require.Equal(t, ongoing[:2], unlocked)
+}
@@ -5,15 +5,91 @@ package rpc
 
 import (
 	"errors"
+	"strings"
+
+	"github.com/gorilla/rpc/v2/json2"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker/offers"
 )
 
 var (
+	// daemon_ errors
+	errDraining = errors.New("swapd is draining for shutdown")
+
 	// net_ errors
 	errNoOfferWithID          = errors.New("peer does not have offer with given ID")
 	errUnsupportedForBootnode = errors.New("unsupported for bootnode")
+	errExchangeRateNotSet     = errors.New(`either "exchangeRate" or "peg" must be set`)
+	errExchangeRateAndPeg     = errors.New(`"exchangeRate" and "peg" cannot both be set`)
+	errPegWithToken           = errors.New("only offers for ETH can be pegged")
+
+	// personal_ errors
+	errNoEthKeyFile           = errors.New("swapd is not using an ethereum key file")
+	errOngoingSwaps           = errors.New("cannot restore the ethereum key while swaps are ongoing")
+	errOngoingSwapsXMRRestore = errors.New("cannot restore the monero wallet while swaps are ongoing")
+	errNoEthSigner            = errors.New("swapd cannot sign ethereum transactions when using an external signer")
+	errOngoingSwapsWithdrawal = errors.New("cannot withdraw or sweep all funds while swaps are ongoing")
+	errNoTokenInfoCache       = errors.New("token metadata is not cached")
+	errAlreadyUnlocked        = errors.New("the monero wallet is already unlocked")
+
+	// swap_ errors
+	errContractEventsNotIndexed = errors.New("contract events are not indexed")
 
 	// ws errors
 	errUnimplemented       = errors.New("unimplemented")
 	errInvalidMethod       = errors.New("invalid method")
 	errNamespaceNotEnabled = errors.New("namespace not enabled")
 )
+
+// insufficientFundsMessages are parts of the messages of the errors of the ethereum
+// node and of monero-wallet-rpc when the balance doesn't cover a transfer, which we
+// only get as text.
+var insufficientFundsMessages = []string{
+	"insufficient funds",
+	"not enough money",
+	"not enough unlocked money",
+}
+
+// errorCode returns the JSON-RPC error code of the error of a method, so clients
+// can handle the errors they expect without matching their messages.
+func errorCode(err error) rpctypes.ErrCode {
+	switch {
+	case errors.Is(err, errNoOfferWithID), errors.Is(err, offers.ErrOfferDoesNotExist):
+		return rpctypes.ErrCodeOfferNotFound
+	case errors.Is(err, swap.ErrNoSwapWithID):
+		return rpctypes.ErrCodeSwapNotFound
+	case errors.Is(err, errOngoingSwaps), errors.Is(err, errOngoingSwapsWithdrawal),
+		errors.Is(err, errOngoingSwapsXMRRestore),
+		errors.Is(err, protocol.ErrProtocolAlreadyInProgress), errors.Is(err, net.ErrSwapAlreadyInProgress):
+		return rpctypes.ErrCodeSwapOngoing
+	case errors.Is(err, protocol.ErrInsufficientBalance):
+		return rpctypes.ErrCodeInsufficientBalance
+	case errors.Is(err, backend.ErrPaused):
+		return rpctypes.ErrCodePaused
+	case errors.Is(err, errDraining):
+		return rpctypes.ErrCodeDraining
+	}
+
+	msg := err.Error()
+	for _, part := range insufficientFundsMessages {
+		if strings.Contains(msg, part) {
+			return rpctypes.ErrCodeInsufficientBalance
+		}
+	}
+
+	return rpctypes.ErrCodeServer
+}
+
+// mapError is the error mapper of the JSON-RPC codec, which sets the code of the
+// errors returned by methods.
+func mapError(err error) error {
+	return &json2.Error{
+		Code:    json2.ErrorCode(errorCode(err)),
+		Message: err.Error(),
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gorilla/rpc/v2/json2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected rpctypes.ErrCode
+	}{
+		{errNoOfferWithID, rpctypes.ErrCodeOfferNotFound},
+		{fmt.Errorf("failed to get ongoing swap: %w", swap.ErrNoSwapWithID), rpctypes.ErrCodeSwapNotFound},
+		{errOngoingSwapsWithdrawal, rpctypes.ErrCodeSwapOngoing},
+		{fmt.Errorf("failed to take offer: %w", protocol.ErrInsufficientBalance), rpctypes.ErrCodeInsufficientBalance},
+		{errors.New("insufficient funds for gas * price + value"), rpctypes.ErrCodeInsufficientBalance},
+		{errors.New("not enough unlocked money"), rpctypes.ErrCodeInsufficientBalance},
+		{backend.ErrPaused, rpctypes.ErrCodePaused},
+		{errDraining, rpctypes.ErrCodeDraining},
+		{errNoEthSigner, rpctypes.ErrCodeServer},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, errorCode(tc.err), tc.err.Error())
+	}
+}
+
+func TestMapError(t *testing.T) {
+	err := mapError(errDraining)
+	require.Equal(t, &json2.Error{Code: -32006, Message: errDraining.Error()}, err)
+}
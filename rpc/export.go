@@ -0,0 +1,169 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// Formats of swap_export
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+// exportCSVHeader is the header row of CSV exports, in the order of the columns
+// written by ExportedSwap.csvRecord.
+var exportCSVHeader = []string{
+	"id",
+	"startTime",
+	"endTime",
+	"status",
+	"peerID",
+	"provided",
+	"ethAsset",
+	"xmrAmount",
+	"ethAmount",
+	"exchangeRate",
+	"gasPaid",
+}
+
+// ExportedSwap is a past swap returned by swap_export.
+type ExportedSwap struct {
+	ID        types.Hash         `json:"id" validate:"required"`
+	StartTime time.Time          `json:"startTime" validate:"required"`
+	EndTime   *time.Time         `json:"endTime"`
+	Status    types.Status       `json:"status" validate:"required"`
+	PeerID    peer.ID            `json:"peerID" validate:"required"`
+	Provided  coins.ProvidesCoin `json:"provided" validate:"required"`
+	EthAsset  types.EthAsset     `json:"ethAsset"`
+	// XMRAmount and ETHAmount are the amounts of the swap on both chains, the ETH
+	// amount being in the standard units of the ETH asset.
+	XMRAmount    *apd.Decimal        `json:"xmrAmount" validate:"required"`
+	ETHAmount    *apd.Decimal        `json:"ethAmount" validate:"required"`
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+	// GasPaid is the ETH that we paid for the gas of our transactions of the swap.
+	GasPaid *apd.Decimal `json:"gasPaid"`
+}
+
+// ExportRequest ...
+type ExportRequest struct {
+	// Format is "json" or "csv", "json" if empty
+	Format string `json:"format"`
+	PastSwapFilter
+}
+
+// ExportResponse has the swaps of a JSON export, or the CSV document of a CSV export.
+type ExportResponse struct {
+	Swaps []*ExportedSwap `json:"swaps,omitempty" validate:"dive,required"`
+	CSV   string          `json:"csv,omitempty"`
+}
+
+// Export returns the past swaps selected by the filter, which completed, were
+// refunded or aborted, with their amounts on both chains and the gas that we paid,
+// for accounting and tax reporting. The swaps are sorted from oldest to newest.
+func (s *SwapService) Export(_ *http.Request, req *ExportRequest, resp *ExportResponse) error {
+	if req.Format != "" && req.Format != ExportFormatJSON && req.Format != ExportFormatCSV {
+		return fmt.Errorf("unsupported export format %q, must be %q or %q",
+			req.Format, ExportFormatJSON, ExportFormatCSV)
+	}
+
+	past, err := s.pastSwaps(&req.PastSwapFilter)
+	if err != nil {
+		return err
+	}
+
+	swaps := make([]*ExportedSwap, len(past))
+	for i, info := range past {
+		swaps[i] = newExportedSwap(info)
+	}
+
+	if req.Format != ExportFormatCSV {
+		resp.Swaps = swaps
+		return nil
+	}
+
+	resp.CSV, err = exportCSV(swaps)
+	return err
+}
+
+func newExportedSwap(info *swap.Info) *ExportedSwap {
+	exported := &ExportedSwap{
+		ID:           info.OfferID,
+		StartTime:    info.StartTime,
+		EndTime:      info.EndTime,
+		Status:       info.Status,
+		PeerID:       info.PeerID,
+		Provided:     info.Provides,
+		EthAsset:     info.EthAsset,
+		ExchangeRate: info.ExchangeRate,
+	}
+
+	if info.Provides == coins.ProvidesXMR {
+		exported.XMRAmount, exported.ETHAmount = info.ProvidedAmount, info.ExpectedAmount
+	} else {
+		exported.XMRAmount, exported.ETHAmount = info.ExpectedAmount, info.ProvidedAmount
+	}
+
+	if info.GasPaid != nil {
+		exported.GasPaid = info.GasPaid.AsEther()
+	}
+
+	return exported
+}
+
+// csvRecord returns the CSV row of the swap, with the columns of exportCSVHeader.
+func (e *ExportedSwap) csvRecord() []string {
+	endTime := ""
+	if e.EndTime != nil {
+		endTime = e.EndTime.UTC().Format(time.RFC3339)
+	}
+
+	gasPaid := ""
+	if e.GasPaid != nil {
+		gasPaid = e.GasPaid.Text('f')
+	}
+
+	return []string{
+		e.ID.String(),
+		e.StartTime.UTC().Format(time.RFC3339),
+		endTime,
+		e.Status.String(),
+		e.PeerID.String(),
+		string(e.Provided),
+		e.EthAsset.String(),
+		e.XMRAmount.Text('f'),
+		e.ETHAmount.Text('f'),
+		e.ExchangeRate.String(),
+		gasPaid,
+	}
+}
+
+func exportCSV(swaps []*ExportedSwap) (string, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+
+	if err := w.Write(exportCSVHeader); err != nil {
+		return "", err
+	}
+	for _, s := range swaps {
+		if err := w.Write(s.csvRecord()); err != nil {
+			return "", err
+		}
+	}
+
+	w.Flush()
+	return buf.String(), w.Error()
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+type mockPastSwapManager struct {
+	mockSwapManager
+	past map[types.Hash]*swap.Info
+}
+
+func (m *mockPastSwapManager) GetPastIDs() ([]types.Hash, error) {
+	ids := make([]types.Hash, 0, len(m.past))
+	for id := range m.past {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (m *mockPastSwapManager) GetPastSwap(id types.Hash) (*swap.Info, error) {
+	return m.past[id], nil
+}
+
+func newTestPastSwaps() map[types.Hash]*swap.Info {
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	sold := swap.NewInfo(testPeerID, types.Hash{1}, coins.ProvidesXMR,
+		apd.New(2, 0), apd.New(1, -1), coins.ToExchangeRate(apd.New(5, -2)),
+		types.EthAssetETH, types.CompletedSuccess, 1, nil)
+	sold.StartTime = start
+	sold.EndTime = &end
+
+	bought := swap.NewInfo(testPeerID, types.Hash{2}, coins.ProvidesETH,
+		apd.New(1, -1), apd.New(2, 0), coins.ToExchangeRate(apd.New(5, -2)),
+		types.EthAssetETH, types.CompletedRefund, 1, nil)
+	bought.StartTime = start.Add(-time.Hour)
+	bought.GasPaid = coins.NewWeiAmount(big.NewInt(2e15))
+
+	return map[types.Hash]*swap.Info{sold.OfferID: sold, bought.OfferID: bought}
+}
+
+func TestSwapService_Export_json(t *testing.T) {
+	sm := &mockPastSwapManager{past: newTestPastSwaps()}
+	s := NewSwapService(context.Background(), sm, nil, nil, nil, nil, nil, nil)
+
+	resp := new(ExportResponse)
+	err := s.Export(nil, &ExportRequest{}, resp)
+	require.NoError(t, err)
+	require.Empty(t, resp.CSV)
+	require.Len(t, resp.Swaps, 2)
+
+	// sorted from oldest to newest, with the amounts on both chains
+	bought, sold := resp.Swaps[0], resp.Swaps[1]
+	require.Equal(t, types.Hash{2}, bought.ID)
+	require.Equal(t, "2", bought.XMRAmount.String())
+	require.Equal(t, "0.1", bought.ETHAmount.String())
+	require.Equal(t, "0.002", bought.GasPaid.String())
+	require.Equal(t, types.Hash{1}, sold.ID)
+	require.Equal(t, "2", sold.XMRAmount.String())
+	require.Equal(t, "0.1", sold.ETHAmount.String())
+	require.Nil(t, sold.GasPaid)
+}
+
+func TestSwapService_Export_csv(t *testing.T) {
+	sm := &mockPastSwapManager{past: newTestPastSwaps()}
+	s := NewSwapService(context.Background(), sm, nil, nil, nil, nil, nil, nil)
+
+	resp := new(ExportResponse)
+	err := s.Export(nil, &ExportRequest{Format: ExportFormatCSV}, resp)
+	require.NoError(t, err)
+	require.Empty(t, resp.Swaps)
+
+	peerID := testPeerID.String()
+	expected := "id,startTime,endTime,status,peerID,provided,ethAsset,xmrAmount,ethAmount,exchangeRate,gasPaid\n" +
+		types.Hash{2}.String() + ",2023-03-01T11:00:00Z,,Refunded," + peerID + ",ETH,ETH,2,0.1,0.05,0.002\n" +
+		types.Hash{1}.String() + ",2023-03-01T12:00:00Z,2023-03-01T13:00:00Z,Success," + peerID + ",XMR,ETH,2,0.1,0.05,\n"
+	require.Equal(t, expected, resp.CSV)
+}
+
+func TestSwapService_Export_badFormat(t *testing.T) {
+	s := NewSwapService(context.Background(), new(mockPastSwapManager), nil, nil, nil, nil, nil, nil)
+	err := s.Export(nil, &ExportRequest{Format: "xml"}, new(ExportResponse))
+	require.ErrorContains(t, err, `unsupported export format "xml"`)
+}
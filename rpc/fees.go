@@ -0,0 +1,270 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+)
+
+// Worst case gas usage of the transactions of a swap, with some margin over the
+// usage measured when this was written (newSwap: 53787, setReady: 34452, claim:
+// about 50000).
+const (
+	approveGas   = 50000
+	newSwapGas   = 60000
+	setReadyGas  = 40000
+	claimGas     = 60000
+	tokenTxExtra = 30000 // extra gas of newSwap and claim for the token transfer
+)
+
+// Names of the transactions of EstimatedTx
+const (
+	TxApprove  = "approve"
+	TxNewSwap  = "newSwap"
+	TxSetReady = "setReady"
+	TxClaim    = "claim"
+)
+
+// EstimatedTx is an ETH transaction that we send in a swap, with its estimated cost.
+type EstimatedTx struct {
+	Name string       `json:"name" validate:"required"`
+	Gas  uint64       `json:"gas" validate:"required"`
+	Cost *apd.Decimal `json:"cost" validate:"required"` // in ETH
+}
+
+// EstimateFeesRequest ...
+type EstimateFeesRequest struct {
+	// Provides is the coin that we provide in the swap
+	Provides coins.ProvidesCoin `json:"provides" validate:"required"`
+	EthAsset types.EthAsset     `json:"ethAsset"`
+	// Amount is the amount of the ETH asset of the swap, in its standard units
+	Amount *apd.Decimal `json:"amount" validate:"required"`
+	// UseRelayer estimates the claim of a swap providing XMR as relayed, even if our
+	// ETH balance covers its gas
+	UseRelayer bool `json:"useRelayer"`
+}
+
+// EstimateFeesResponse ...
+type EstimateFeesResponse struct {
+	GasPrice     *coins.WeiAmount `json:"gasPrice" validate:"required"`
+	Transactions []*EstimatedTx   `json:"transactions" validate:"dive,required"`
+	TotalGasCost *apd.Decimal     `json:"totalGasCost" validate:"required"` // in ETH
+	XMRFee       *apd.Decimal     `json:"xmrFee" validate:"required"`
+	// RelayerFee is set when the claim is relayed, in standard units of the ETH asset
+	RelayerFee *apd.Decimal `json:"relayerFee,omitempty"`
+}
+
+// EstimateFees returns the estimated costs of a swap that completes: the gas of
+// every ETH transaction that we send at the current gas price, the fee of our XMR
+// transaction, and the relayer fee if our claim is relayed. Providing ETH, we send
+// the approval of a token (unless the existing allowance covers the amount), the
+// swap creation and setReady, and the XMR transaction sweeps the swap wallet to our
+// primary wallet. Providing XMR, we send the claim, which is relayed if it is
+// requested or if our ETH balance doesn't cover its gas, and the XMR transaction is
+// the lock transfer. The refund of a failed swap is not included.
+func (s *SwapService) EstimateFees(_ *http.Request, req *EstimateFeesRequest, resp *EstimateFeesResponse) error {
+	if req.Provides != coins.ProvidesXMR && req.Provides != coins.ProvidesETH {
+		return fmt.Errorf("unsupported provided coin %q", req.Provides)
+	}
+	if err := req.EthAsset.ValidateAmount("amount", req.Amount); err != nil {
+		return err
+	}
+
+	token, value, err := ethAssetValue(s.ctx, s.backend.ETHClient(), req.EthAsset, req.Amount)
+	if err != nil {
+		return err
+	}
+
+	gasPrice, err := s.backend.ETHClient().SuggestGasPrice(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	var txNames []string
+	if req.Provides == coins.ProvidesETH {
+		txNames, err = takerTxNames(s.ctx, s.backend, token, value)
+	} else {
+		txNames, resp.RelayerFee, err = s.makerTxNames(req, token, value, gasPrice)
+	}
+	if err != nil {
+		return err
+	}
+
+	xmrFee, err := s.backend.XMRClient().EstimateTransferFee()
+	if err != nil {
+		return fmt.Errorf("failed to estimate the XMR transaction fee: %w", err)
+	}
+
+	resp.GasPrice = coins.NewWeiAmount(gasPrice)
+	resp.Transactions, resp.TotalGasCost = estimateTxs(txNames, token != nil, gasPrice)
+	resp.XMRFee = xmrFee.AsMonero()
+	return nil
+}
+
+// ethAssetValue returns the token of the ETH asset, nil for ETH, and the amount of
+// the asset in its base units.
+func ethAssetValue(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	asset types.EthAsset,
+	amount *apd.Decimal,
+) (*coins.ERC20TokenInfo, *big.Int, error) {
+	if !asset.IsToken() {
+		return nil, coins.EtherToWei(amount).BigInt(), nil
+	}
+
+	token, err := ec.ERC20Info(ctx, asset.Address())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return token, coins.NewERC20TokenAmountFromDecimals(amount, token).BigInt(), nil
+}
+
+// takerTxNames returns the transactions that we send providing ETH.
+func takerTxNames(
+	ctx context.Context,
+	pb ProtocolBackend,
+	token *coins.ERC20TokenInfo,
+	value *big.Int,
+) ([]string, error) {
+	if token == nil {
+		return []string{TxNewSwap, TxSetReady}, nil
+	}
+
+	needsApproval, err := needsApproval(ctx, pb, token, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !needsApproval {
+		return []string{TxNewSwap, TxSetReady}, nil
+	}
+	return []string{TxApprove, TxNewSwap, TxSetReady}, nil
+}
+
+// makerTxNames returns the transactions that we send providing XMR, and the relayer
+// fee in standard units of the ETH asset if the claim is relayed.
+func (s *SwapService) makerTxNames(
+	req *EstimateFeesRequest,
+	token *coins.ERC20TokenInfo,
+	value *big.Int,
+	gasPrice *big.Int,
+) ([]string, *apd.Decimal, error) {
+	relayed, err := s.claimIsRelayed(req.UseRelayer, estimatedTxCost(claimTxGas(token != nil), gasPrice))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !relayed {
+		return []string{TxClaim}, nil, nil
+	}
+
+	fee, err := s.backend.RelayerFee().AssetFee(s.ctx, s.backend.ETHClient().Raw(), req.EthAsset, value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token != nil {
+		return nil, coins.NewERC20TokenAmountFromBigInt(fee, token).AsStandard(), nil
+	}
+	return nil, coins.NewWeiAmount(fee).AsEther(), nil
+}
+
+// needsApproval returns whether new_swap needs an approval of the token amount,
+// because the existing allowance of SwapCreator doesn't cover it.
+func needsApproval(
+	ctx context.Context,
+	pb ProtocolBackend,
+	token *coins.ERC20TokenInfo,
+	value *big.Int,
+) (bool, error) {
+	ec := pb.ETHClient()
+
+	tokenContract, err := contracts.NewIERC20(token.Address, ec.Raw())
+	if err != nil {
+		return false, err
+	}
+
+	allowance, err := tokenContract.Allowance(ec.CallOpts(ctx), ec.Address(), pb.SwapCreatorAddr())
+	if err != nil {
+		return false, err
+	}
+
+	return allowance.Cmp(value) < 0, nil
+}
+
+// claimIsRelayed returns whether the XMR maker's claim is relayed, like in
+// claimFunds: when requested, or when our balance doesn't cover the claim's gas cost.
+func (s *SwapService) claimIsRelayed(useRelayer bool, claimCost *big.Int) (bool, error) {
+	if useRelayer {
+		return true, nil
+	}
+
+	balance, err := s.backend.ETHClient().Balance(s.ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return balance.BigInt().Cmp(claimCost) < 0, nil
+}
+
+// estimateTxs returns the named transactions with their estimated costs at the gas
+// price, and their total cost in ETH.
+func estimateTxs(names []string, isToken bool, gasPrice *big.Int) ([]*EstimatedTx, *apd.Decimal) {
+	txs := make([]*EstimatedTx, 0, len(names))
+	total := new(big.Int)
+	for _, name := range names {
+		gas := txGas(name, isToken)
+		cost := estimatedTxCost(gas, gasPrice)
+		total.Add(total, cost)
+		txs = append(txs, &EstimatedTx{
+			Name: name,
+			Gas:  gas,
+			Cost: coins.NewWeiAmount(cost).AsEther(),
+		})
+	}
+
+	return txs, coins.NewWeiAmount(total).AsEther()
+}
+
+// txGas returns the worst case gas usage of the named transaction.
+func txGas(name string, isToken bool) uint64 {
+	switch name {
+	case TxApprove:
+		return approveGas
+	case TxNewSwap:
+		if isToken {
+			return newSwapGas + tokenTxExtra
+		}
+		return newSwapGas
+	case TxSetReady:
+		return setReadyGas
+	case TxClaim:
+		return claimTxGas(isToken)
+	default:
+		panic(fmt.Sprintf("unknown transaction %q", name))
+	}
+}
+
+func claimTxGas(isToken bool) uint64 {
+	if isToken {
+		return claimGas + tokenTxExtra
+	}
+	return claimGas
+}
+
+func estimatedTxCost(gas uint64, gasPrice *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestEstimateTxs(t *testing.T) {
+	gasPrice := big.NewInt(1e9) // 1 gwei
+
+	txs, total := estimateTxs([]string{TxNewSwap, TxSetReady}, false, gasPrice)
+	require.Len(t, txs, 2)
+	require.Equal(t, TxNewSwap, txs[0].Name)
+	require.Equal(t, uint64(newSwapGas), txs[0].Gas)
+	require.Equal(t, "0.00006", txs[0].Cost.Text('f'))
+	require.Equal(t, uint64(setReadyGas), txs[1].Gas)
+	require.Equal(t, "0.0001", total.Text('f'))
+
+	// token swaps transfer the token in newSwap and claim
+	txs, total = estimateTxs([]string{TxApprove, TxNewSwap, TxSetReady}, true, gasPrice)
+	require.Len(t, txs, 3)
+	require.Equal(t, uint64(newSwapGas+tokenTxExtra), txs[1].Gas)
+	require.Equal(t, "0.00018", total.Text('f'))
+
+	txs, _ = estimateTxs([]string{TxClaim}, true, gasPrice)
+	require.Equal(t, uint64(claimGas+tokenTxExtra), txs[0].Gas)
+}
+
+func TestSwapService_EstimateFees_invalid(t *testing.T) {
+	s := NewSwapService(context.Background(), new(mockSwapManager), nil, nil, nil, nil, nil, nil)
+
+	err := s.EstimateFees(nil, &EstimateFeesRequest{
+		Provides: coins.ProvidesCoin("BTC"),
+		Amount:   apd.New(1, 0),
+	}, new(EstimateFeesResponse))
+	require.ErrorContains(t, err, `unsupported provided coin "BTC"`)
+
+	err = s.EstimateFees(nil, &EstimateFeesRequest{
+		Provides: coins.ProvidesXMR,
+		Amount:   apd.New(0, 0),
+	}, new(EstimateFeesResponse))
+	require.ErrorContains(t, err, `"amount" must be non-zero`)
+
+	err = s.EstimateFees(nil, &EstimateFeesRequest{
+		Provides: coins.ProvidesETH,
+		Amount:   apd.New(5, 17),
+	}, new(EstimateFeesResponse))
+	require.ErrorContains(t, err, "the amount is in ETH, not wei")
+}
+
+func TestSwapService_SuggestedExchangeRate(t *testing.T) {
+	now := time.Now()
+	oracle := &coins.MockPriceOracle{Rates: map[coins.Pair]*coins.Rate{
+		coins.PairXMRUSD: {Pair: coins.PairXMRUSD, Price: apd.New(150, 0), UpdatedAt: now},
+		coins.PairETHUSD: {Pair: coins.PairETHUSD, Price: apd.New(2000, 0), UpdatedAt: now.Add(-time.Minute)},
+	}}
+	s := NewSwapService(context.Background(), new(mockSwapManager), nil, nil, nil, nil, nil, oracle)
+
+	resp := new(SuggestedExchangeRateResponse)
+	require.NoError(t, s.SuggestedExchangeRate(nil, nil, resp))
+	require.Equal(t, "0.075", resp.ExchangeRate.String())
+	require.Equal(t, "150", resp.XMRPrice.String())
+	require.Equal(t, now.Add(-time.Minute), resp.ETHUpdatedAt)
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// healthzHandler answers liveness probes. Answering at all shows that the daemon's
+// RPC server is up, so it always reports OK.
+func healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	writeProbeResponse(w, http.StatusOK, "ok")
+}
+
+// readyzHandler returns the handler answering readiness probes. The daemon is ready
+// once its ethereum endpoint is reachable and synced, its monero wallet is synced
+// with the monero daemon, and it is connected to p2p peers. A bootnode has no
+// endpoints, so it is ready as soon as it is serving.
+func readyzHandler(s *DaemonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.pb == nil {
+			writeProbeResponse(w, http.StatusOK, "ok")
+			return
+		}
+
+		status := new(StatusResponse)
+		if err := s.Status(r, nil, status); err != nil {
+			writeProbeResponse(w, http.StatusServiceUnavailable, fmt.Sprintf("not ready: %s", err))
+			return
+		}
+
+		if !status.Ready {
+			writeProbeResponse(w, http.StatusServiceUnavailable, "not ready: "+notReadyReasons(status))
+			return
+		}
+
+		writeProbeResponse(w, http.StatusOK, "ok")
+	}
+}
+
+// notReadyReasons describes why the daemon with the status is not ready.
+func notReadyReasons(status *StatusResponse) string {
+	var reasons []string
+	if !status.EthSynced {
+		reasons = append(reasons, fmt.Sprintf("ethereum endpoint is syncing (block %d of %d)",
+			status.EthHeight, status.EthHighestBlock))
+	}
+	if !status.MoneroSynced {
+		reasons = append(reasons, fmt.Sprintf("monero wallet is syncing (block %d of %d)",
+			status.MoneroWalletHeight, status.MoneroDaemonHeight))
+	}
+	if status.Peers == 0 {
+		reasons = append(reasons, "no p2p peers")
+	}
+	return strings.Join(reasons, ", ")
+}
+
+func writeProbeResponse(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintln(w, msg)
+}
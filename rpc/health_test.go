@@ -0,0 +1,39 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	healthzHandler(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "ok\n", rec.Body.String())
+}
+
+func TestReadyz_bootnode(t *testing.T) {
+	s := NewDaemonService(context.Background(), func() {}, nil, &mockConnectedNet{}, nil)
+
+	rec := httptest.NewRecorder()
+	readyzHandler(s)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestNotReadyReasons(t *testing.T) {
+	status := &StatusResponse{
+		EthHeight:          90,
+		EthHighestBlock:    100,
+		MoneroWalletHeight: 1000,
+		MoneroDaemonHeight: 1000,
+		MoneroSynced:       true,
+	}
+	require.Equal(t, "ethereum endpoint is syncing (block 90 of 100), no p2p peers", notReadyReasons(status))
+}
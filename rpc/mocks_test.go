@@ -4,11 +4,13 @@
 package rpc
 
 import (
+	"errors"
 	"time"
 
 	"github.com/MarinX/monerorpc/wallet"
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	libp2ptest "github.com/libp2p/go-libp2p/core/test"
 	ma "github.com/multiformats/go-multiaddr"
@@ -18,9 +20,13 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/net/message"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
 //
@@ -28,7 +34,16 @@ import (
 //
 
 type mockNet struct {
-	peerID peer.ID
+	peerID   peer.ID
+	gossiped map[peer.ID][]*types.Offer
+	banned   []*net.BannedPeer
+	stats    *net.Stats
+
+	discovered    []peer.ID
+	relayerQuotes map[peer.ID]*message.RelayFeeQuote
+	relayerDelay  map[peer.ID]time.Duration
+
+	capabilities *net.Capabilities
 }
 
 func (*mockNet) Addresses() []ma.Multiaddr {
@@ -50,14 +65,31 @@ func (*mockNet) ConnectedPeers() []string {
 	panic("not implemented")
 }
 
-func (*mockNet) Discover(_ string, _ time.Duration) ([]peer.ID, error) {
-	return nil, nil
+func (*mockNet) NATStatus() *net.NATStatus {
+	return &net.NATStatus{Reachability: libp2pnetwork.ReachabilityPrivate}
 }
 
-func (*mockNet) Query(_ peer.ID) (*message.QueryResponse, error) {
+func (m *mockNet) Discover(_ string, _ time.Duration) ([]peer.ID, error) {
+	return m.discovered, nil
+}
+
+func (*mockNet) Query(_ peer.ID, _ *types.OfferFilter) (*message.QueryResponse, error) {
 	return &message.QueryResponse{Offers: []*types.Offer{{ID: testSwapID}}}, nil
 }
 
+func (m *mockNet) GossipedOffers() map[peer.ID][]*types.Offer {
+	return m.gossiped
+}
+
+func (m *mockNet) QueryRelayerFee(who peer.ID, _ *message.RelayFeeQuoteRequest) (*message.RelayFeeQuote, error) {
+	time.Sleep(m.relayerDelay[who])
+	quote, ok := m.relayerQuotes[who]
+	if !ok {
+		return nil, errors.New("relayer did not respond")
+	}
+	return quote, nil
+}
+
 func (*mockNet) Initiate(_ peer.AddrInfo, _ common.Message, _ common.SwapStateNet) error {
 	return nil
 }
@@ -66,6 +98,38 @@ func (*mockNet) CloseProtocolStream(_ types.Hash) {
 	panic("not implemented")
 }
 
+func (m *mockNet) BanPeer(p peer.ID, duration time.Duration, reason string) error {
+	bp := &net.BannedPeer{PeerID: p, Reason: reason}
+	if duration > 0 {
+		until := time.Now().Add(duration)
+		bp.Until = &until
+	}
+	m.banned = append(m.banned, bp)
+	return nil
+}
+
+func (m *mockNet) UnbanPeer(p peer.ID) error {
+	for i, bp := range m.banned {
+		if bp.PeerID == p {
+			m.banned = append(m.banned[:i], m.banned[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockNet) BannedPeers() []*net.BannedPeer {
+	return m.banned
+}
+
+func (m *mockNet) Stats() *net.Stats {
+	return m.stats
+}
+
+func (m *mockNet) PeerCapabilities(_ peer.ID) (*net.Capabilities, error) {
+	return m.capabilities, nil
+}
+
 type mockSwapManager struct{}
 
 func (*mockSwapManager) WriteSwapToDB(_ *swap.Info) error {
@@ -121,7 +185,7 @@ func (*mockXMRTaker) GetOngoingSwapState(_ types.Hash) common.SwapState {
 	return new(mockSwapState)
 }
 
-func (*mockXMRTaker) InitiateProtocol(_ peer.ID, _ *apd.Decimal, _ *types.Offer) (common.SwapState, error) {
+func (*mockXMRTaker) InitiateProtocol(_ peer.ID, _ *apd.Decimal, _ *types.Offer, _ string) (common.SwapState, error) {
 	return new(mockSwapState), nil
 }
 
@@ -147,7 +211,7 @@ func (m *mockXMRMaker) GetOngoingSwapState(_ types.Hash) common.SwapState {
 	panic("not implemented")
 }
 
-func (*mockXMRMaker) MakeOffer(_ *types.Offer, _ bool) (*types.OfferExtra, error) {
+func (*mockXMRMaker) MakeOffer(_ *types.Offer, _ bool, _ string) (*types.OfferExtra, error) {
 	offerExtra := &types.OfferExtra{
 		StatusCh: make(chan types.Status, 1),
 	}
@@ -155,6 +219,10 @@ func (*mockXMRMaker) MakeOffer(_ *types.Offer, _ bool) (*types.OfferExtra, error
 	return offerExtra, nil
 }
 
+func (*mockXMRMaker) PeggedExchangeRate(_ *types.OfferPeg) (*coins.ExchangeRate, error) {
+	return coins.StrToExchangeRate("0.075"), nil
+}
+
 func (*mockXMRMaker) GetOffers() []*types.Offer {
 	panic("not implemented")
 }
@@ -163,7 +231,7 @@ func (*mockXMRMaker) ClearOffers(_ []types.Hash) error {
 	panic("not implemented")
 }
 
-func (*mockXMRMaker) GetMoneroBalance() (*mcrypto.Address, *wallet.GetBalanceResponse, error) {
+func (*mockXMRMaker) GetMoneroBalance(_ string) (*mcrypto.Address, *wallet.GetBalanceResponse, error) {
 	panic("not implemented")
 }
 
@@ -186,7 +254,8 @@ func (*mockSwapState) OfferID() types.Hash {
 }
 
 type mockProtocolBackend struct {
-	sm *mockSwapManager
+	sm     *mockSwapManager
+	paused bool
 }
 
 func newMockProtocolBackend() *mockProtocolBackend {
@@ -207,6 +276,14 @@ func (*mockProtocolBackend) SwapTimeout() time.Duration {
 	panic("not implemented")
 }
 
+func (b *mockProtocolBackend) SetPaused(paused bool) {
+	b.paused = paused
+}
+
+func (b *mockProtocolBackend) Paused() bool {
+	return b.paused
+}
+
 func (b *mockProtocolBackend) SwapManager() swap.Manager {
 	return b.sm
 }
@@ -219,10 +296,30 @@ func (*mockProtocolBackend) ClearXMRDepositAddress(types.Hash) {
 	panic("not implemented")
 }
 
+func (*mockProtocolBackend) SetXMRPriority(wallet.Priority, types.Hash) {
+	panic("not implemented")
+}
+
 func (*mockProtocolBackend) ETHClient() extethclient.EthClient {
 	panic("not implemented")
 }
 
-func (*mockProtocolBackend) SwapCreatorAddr() ethcommon.Address {
+func (*mockProtocolBackend) XMRClient() monero.WalletClient {
 	panic("not implemented")
 }
+
+func (*mockProtocolBackend) RelayerFee() *relayer.FeeConfig {
+	return relayer.DefaultFeeConfig()
+}
+
+func (*mockProtocolBackend) WithProfile(_ string) (backend.Backend, error) {
+	panic("not implemented")
+}
+
+func (*mockProtocolBackend) ProfileNames() []string {
+	return nil
+}
+
+func (*mockProtocolBackend) SwapCreatorAddr() ethcommon.Address {
+	return ethcommon.Address{0x1}
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/monero"
+)
+
+const defaultMoneroSyncInterval = 5 * time.Second
+
+// moneroSyncEvent returns the websocket event of the wallet's sync progress.
+func moneroSyncEvent(p *monero.SyncProgress) *rpctypes.MoneroSyncEvent {
+	return &rpctypes.MoneroSyncEvent{
+		WalletHeight: p.WalletHeight,
+		DaemonHeight: p.DaemonHeight,
+		Synced:       p.Synced,
+		Percent:      p.Percent,
+		ETASeconds:   uint64(p.ETA.Seconds()),
+	}
+}
+
+// subscribeMoneroSync writes the sync progress of the monero wallet to the connection
+// each time that it changes, starting with the current progress, so that frontends
+// can show the wallet catching up with the daemon.
+// example: `{"jsonrpc":"2.0", "method":"daemon_subscribeMoneroSync", "params": {"interval": 5}, "id": 0}`
+func (s *wsServer) subscribeMoneroSync(ctx context.Context, conn *websocket.Conn,
+	params *rpctypes.SubscribeMoneroSyncRequest) error {
+	interval := time.Duration(params.Interval) * time.Second
+	if interval == 0 {
+		interval = defaultMoneroSyncInterval
+	}
+
+	var prev *rpctypes.MoneroSyncEvent
+	for {
+		progress, err := s.backend.XMRClient().GetSyncProgress()
+		if err != nil {
+			return fmt.Errorf("failed to get monero sync status: %w", err)
+		}
+
+		next := moneroSyncEvent(progress)
+		if prev == nil || *next != *prev {
+			if err = writeResponse(conn, next); err != nil {
+				return err
+			}
+		}
+		prev = next
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -4,11 +4,16 @@
 package rpc
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 	ma "github.com/multiformats/go-multiaddr"
 
@@ -16,38 +21,73 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+	"github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 const defaultSearchTime = time.Second * 12
 
+// relayerQuoteTimeout is how long net_discoverRelayers waits for the fee quote of
+// each relayer.
+var relayerQuoteTimeout = time.Second * 10
+
 // Net contains the network-related functions required by the rpc service.
 type Net interface {
 	PeerID() peer.ID
 	ConnectedPeers() []string
 	Addresses() []ma.Multiaddr
+	NATStatus() *net.NATStatus
 	Discover(provides string, searchTime time.Duration) ([]peer.ID, error)
-	Query(who peer.ID) (*message.QueryResponse, error)
+	Query(who peer.ID, filter *types.OfferFilter) (*message.QueryResponse, error)
+	GossipedOffers() map[peer.ID][]*types.Offer
+	QueryRelayerFee(who peer.ID, req *message.RelayFeeQuoteRequest) (*message.RelayFeeQuote, error)
 	Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s common.SwapStateNet) error
 	CloseProtocolStream(types.Hash)
+	BanPeer(p peer.ID, duration time.Duration, reason string) error
+	UnbanPeer(p peer.ID) error
+	BannedPeers() []*net.BannedPeer
+	Stats() *net.Stats
+	PeerCapabilities(who peer.ID) (*net.Capabilities, error)
+}
+
+// RelayerStatsDB contains the methods for retrieving the recorded outcomes of
+// claims submitted to relayers from the database.
+type RelayerStatsDB interface {
+	GetAllRelayerStats() ([]*db.RelayerStats, error)
 }
 
 // NetService is the RPC service prefixed by net_.
 type NetService struct {
+	ctx        context.Context
 	net        Net
 	xmrtaker   XMRTaker
 	xmrmaker   XMRMaker
 	sm         SwapManager
+	pb         ProtocolBackend
+	relayerDB  RelayerStatsDB
 	isBootnode bool
 }
 
 // NewNetService ...
-func NewNetService(net Net, xmrtaker XMRTaker, xmrmaker XMRMaker, sm SwapManager, isBootnode bool) *NetService {
+func NewNetService(
+	ctx context.Context,
+	net Net,
+	xmrtaker XMRTaker,
+	xmrmaker XMRMaker,
+	sm SwapManager,
+	pb ProtocolBackend,
+	relayerDB RelayerStatsDB,
+	isBootnode bool,
+) *NetService {
 	return &NetService{
+		ctx:        ctx,
 		net:        net,
 		xmrtaker:   xmrtaker,
 		xmrmaker:   xmrmaker,
 		sm:         sm,
+		pb:         pb,
+		relayerDB:  relayerDB,
 		isBootnode: isBootnode,
 	}
 }
@@ -72,13 +112,31 @@ func (s *NetService) Peers(_ *http.Request, _ *interface{}, resp *rpctypes.Peers
 	return nil
 }
 
+// NatStatus returns whether peers can dial us directly, as found by AutoNAT, and the
+// relay addresses that peers reach us at if they can't.
+func (s *NetService) NatStatus(_ *http.Request, _ *interface{}, resp *rpctypes.NATStatusResponse) error {
+	status := s.net.NATStatus()
+	resp.Reachability = strings.ToLower(status.Reachability.String())
+	resp.RelayAddrs = make([]string, 0, len(status.RelayAddrs))
+	for _, a := range status.RelayAddrs {
+		resp.RelayAddrs = append(resp.RelayAddrs, a.String())
+	}
+	return nil
+}
+
 // QueryAll discovers peers who provide a certain coin and queries all of them for their current offers.
+// The peers are returned with the outcomes of our past swaps with them.
 func (s *NetService) QueryAll(_ *http.Request, req *rpctypes.QueryAllRequest, resp *rpctypes.QueryAllResponse) error {
 	if s.isBootnode {
 		return errUnsupportedForBootnode
 	}
 
-	peerIDs, err := s.discover(req)
+	peerIDs, gossiped, err := s.discoverMakers(&req.DiscoverRequest, &req.OfferFilter)
+	if err != nil {
+		return err
+	}
+
+	reputations, err := s.peerReputations()
 	if err != nil {
 		return err
 	}
@@ -86,14 +144,18 @@ func (s *NetService) QueryAll(_ *http.Request, req *rpctypes.QueryAllRequest, re
 	resp.PeersWithOffers = make([]*rpctypes.PeerWithOffers, len(peerIDs))
 	for i, p := range peerIDs {
 		resp.PeersWithOffers[i] = &rpctypes.PeerWithOffers{
-			PeerID: p,
+			PeerID:     p,
+			Reputation: new(rpctypes.PeerReputation),
+		}
+		if rep, ok := reputations[p]; ok {
+			resp.PeersWithOffers[i].Reputation = rep
 		}
-		msg, err := s.net.Query(p)
+		offers, err := s.peerOffers(p, gossiped, &req.OfferFilter)
 		if err != nil {
 			log.Debugf("Failed to query peer ID %s", p)
 			continue
 		}
-		resp.PeersWithOffers[i].Offers = msg.Offers
+		resp.PeersWithOffers[i].Offers = offers
 	}
 
 	return nil
@@ -112,6 +174,65 @@ func (s *NetService) discover(req *rpctypes.DiscoverRequest) ([]peer.ID, error)
 	return s.net.Discover(req.Provides, searchTime)
 }
 
+// discoverMakers returns the peers found in the DHT that provide the coin, followed
+// by the makers that published offers of the coin matching the filter on the offers
+// topic but were not found in the DHT. The offers published by the makers are
+// returned with them.
+func (s *NetService) discoverMakers(
+	req *rpctypes.DiscoverRequest,
+	filter *types.OfferFilter,
+) ([]peer.ID, map[peer.ID][]*types.Offer, error) {
+	peerIDs, err := s.discover(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make(map[peer.ID]bool, len(peerIDs))
+	for _, p := range peerIDs {
+		found[p] = true
+	}
+
+	gossiped := s.net.GossipedOffers()
+	var gossipedPeerIDs []peer.ID
+	for p, offers := range gossiped {
+		if found[p] {
+			continue
+		}
+		for _, offer := range offers {
+			if (req.Provides == "" || string(offer.Provides) == req.Provides) && filter.Matches(offer) {
+				gossipedPeerIDs = append(gossipedPeerIDs, p)
+				break
+			}
+		}
+	}
+
+	sort.Slice(gossipedPeerIDs, func(i, j int) bool {
+		return gossipedPeerIDs[i] < gossipedPeerIDs[j]
+	})
+
+	return append(peerIDs, gossipedPeerIDs...), gossiped, nil
+}
+
+// peerOffers returns the offers matching the filter that the peer published on the
+// offers topic, if it did, so that makers are not queried by every taker.
+// Otherwise, the peer is queried for its offers matching the filter.
+func (s *NetService) peerOffers(
+	p peer.ID,
+	gossiped map[peer.ID][]*types.Offer,
+	filter *types.OfferFilter,
+) ([]*types.Offer, error) {
+	if offers, ok := gossiped[p]; ok {
+		return filter.Filter(offers), nil
+	}
+
+	msg, err := s.net.Query(p, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return msg.Offers, nil
+}
+
 // Discover discovers peers over the network that provide a certain coin up for `SearchTime` duration of time.
 func (s *NetService) Discover(_ *http.Request, req *rpctypes.DiscoverRequest, resp *rpctypes.DiscoverResponse) error {
 	searchTime, err := time.ParseDuration(fmt.Sprintf("%ds", req.SearchTime))
@@ -131,6 +252,125 @@ func (s *NetService) Discover(_ *http.Request, req *rpctypes.DiscoverRequest, re
 	return nil
 }
 
+// DiscoverRelayers discovers peers that advertise themselves as relayers in the DHT
+// and asks each of them for the fee they require to relay a claim.
+func (s *NetService) DiscoverRelayers(
+	_ *http.Request,
+	req *rpctypes.DiscoverRelayersRequest,
+	resp *rpctypes.DiscoverRelayersResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	peerIDs, err := s.discover(&rpctypes.DiscoverRequest{
+		Provides:   net.RelayerProvidesStr,
+		SearchTime: req.SearchTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	value := apd.New(1, 0)
+	if req.Value != nil {
+		value = req.Value
+	}
+
+	quoteReq := &message.RelayFeeQuoteRequest{
+		SwapCreatorAddr: s.pb.SwapCreatorAddr(),
+		Asset:           ethcommon.Address(types.EthAssetETH),
+		Value:           coins.EtherToWei(value).BigInt(),
+	}
+
+	// the relayers are queried in parallel, so a slow or unresponsive relayer only
+	// delays the response by relayerQuoteTimeout
+	var wg sync.WaitGroup
+	resp.Relayers = make([]*rpctypes.RelayerInfo, len(peerIDs))
+	for i, p := range peerIDs {
+		resp.Relayers[i] = &rpctypes.RelayerInfo{
+			PeerID: p,
+		}
+
+		wg.Add(1)
+		go func(info *rpctypes.RelayerInfo) {
+			defer wg.Done()
+			quote := s.queryRelayerFee(info.PeerID, quoteReq)
+			if quote != nil {
+				info.Fee = coins.NewWeiAmount(quote.FeeWei).AsEther()
+				info.ETASeconds = quote.ETASeconds
+			}
+		}(resp.Relayers[i])
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// queryRelayerFee asks the relayer for its fee quote, returning nil if it fails to
+// respond within relayerQuoteTimeout.
+func (s *NetService) queryRelayerFee(relayerID peer.ID, req *message.RelayFeeQuoteRequest) *message.RelayFeeQuote {
+	// buffered, so the query's goroutine can exit after we stop waiting
+	quoteCh := make(chan *message.RelayFeeQuote, 1)
+	go func() {
+		quote, err := s.net.QueryRelayerFee(relayerID, req)
+		if err != nil {
+			log.Debugf("Failed to get fee quote from relayer %s: %s", relayerID, err)
+		}
+		quoteCh <- quote
+	}()
+
+	select {
+	case quote := <-quoteCh:
+		return quote
+	case <-time.After(relayerQuoteTimeout):
+		log.Debugf("Timed out waiting for the fee quote of relayer %s", relayerID)
+		return nil
+	case <-s.ctx.Done():
+		return nil
+	}
+}
+
+// RelayerStats returns the recorded outcomes of the claims that we submitted to
+// relayers, along with each relayer's score, best score first.
+func (s *NetService) RelayerStats(
+	_ *http.Request,
+	_ *interface{},
+	resp *rpctypes.RelayerStatsResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	resp.Relayers = []*rpctypes.RelayerStats{}
+	if s.relayerDB == nil {
+		return nil
+	}
+
+	all, err := s.relayerDB.GetAllRelayerStats()
+	if err != nil {
+		return err
+	}
+
+	for _, stats := range all {
+		resp.Relayers = append(resp.Relayers, &rpctypes.RelayerStats{
+			PeerID:      stats.PeerID,
+			Accepted:    stats.Accepted,
+			Submitted:   stats.Submitted,
+			Confirmed:   stats.Confirmed,
+			Failed:      stats.Failed,
+			TimedOut:    stats.TimedOut,
+			LastUpdated: stats.LastUpdated,
+			Score:       stats.Score(),
+		})
+	}
+
+	sort.SliceStable(resp.Relayers, func(i, j int) bool {
+		return resp.Relayers[i].Score > resp.Relayers[j].Score
+	})
+
+	return nil
+}
+
 // QueryPeer queries a peer for the coins they provide, their maximum amounts, and desired exchange rate.
 func (s *NetService) QueryPeer(
 	_ *http.Request,
@@ -141,7 +381,7 @@ func (s *NetService) QueryPeer(
 		return errUnsupportedForBootnode
 	}
 
-	msg, err := s.net.Query(req.PeerID)
+	msg, err := s.net.Query(req.PeerID, &req.OfferFilter)
 	if err != nil {
 		return err
 	}
@@ -160,7 +400,7 @@ func (s *NetService) TakeOffer(
 		return errUnsupportedForBootnode
 	}
 
-	_, err := s.takeOffer(req.PeerID, req.OfferID, req.ProvidesAmount)
+	_, err := s.takeOffer(req.PeerID, req.OfferID, req.ProvidesAmount, req.Profile)
 	if err != nil {
 		return err
 	}
@@ -168,27 +408,27 @@ func (s *NetService) TakeOffer(
 	return nil
 }
 
-func (s *NetService) takeOffer(makerPeerID peer.ID, offerID types.Hash, providesAmount *apd.Decimal) (
-	<-chan types.Status,
-	error,
-) {
-	queryResp, err := s.net.Query(makerPeerID)
+func (s *NetService) takeOffer(
+	makerPeerID peer.ID,
+	offerID types.Hash,
+	providesAmount *apd.Decimal,
+	profile string,
+) (<-chan types.Status, error) {
+	offer, err := s.queryOffer(makerPeerID, offerID)
 	if err != nil {
 		return nil, err
 	}
 
-	var offer *types.Offer
-	for _, maybeOffer := range queryResp.Offers {
-		if offerID == maybeOffer.ID {
-			offer = maybeOffer
-			break
-		}
+	// fail before starting the swap if the maker can't do it
+	caps, err := s.net.PeerCapabilities(makerPeerID)
+	if err != nil {
+		return nil, err
 	}
-	if offer == nil {
-		return nil, errNoOfferWithID
+	if err = net.CheckSwapCapabilities(caps, offer); err != nil {
+		return nil, err
 	}
 
-	swapState, err := s.xmrtaker.InitiateProtocol(makerPeerID, providesAmount, offer)
+	swapState, err := s.xmrtaker.InitiateProtocol(makerPeerID, providesAmount, offer, profile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate protocol: %w", err)
 	}
@@ -196,6 +436,9 @@ func (s *NetService) takeOffer(makerPeerID peer.ID, offerID types.Hash, provides
 	skm := swapState.SendKeysMessage().(*message.SendKeysMessage)
 	skm.OfferID = offerID
 	skm.ProvidedAmount = providesAmount
+	if offer.Peg != nil {
+		skm.ExchangeRate = offer.ExchangeRate
+	}
 
 	if err = s.net.Initiate(peer.AddrInfo{ID: makerPeerID}, skm, swapState); err != nil {
 		if err = swapState.Exit(); err != nil {
@@ -212,6 +455,22 @@ func (s *NetService) takeOffer(makerPeerID peer.ID, offerID types.Hash, provides
 	return info.StatusCh(), nil
 }
 
+// queryOffer queries the peer for its offer with the given ID.
+func (s *NetService) queryOffer(makerPeerID peer.ID, offerID types.Hash) (*types.Offer, error) {
+	queryResp, err := s.net.Query(makerPeerID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, offer := range queryResp.Offers {
+		if offerID == offer.ID {
+			return offer, nil
+		}
+	}
+
+	return nil, errNoOfferWithID
+}
+
 // TakeOfferSyncResponse ...
 type TakeOfferSyncResponse struct {
 	Status types.Status `json:"status" validate:"required"`
@@ -228,7 +487,7 @@ func (s *NetService) TakeOfferSync(
 		return errUnsupportedForBootnode
 	}
 
-	if _, err := s.takeOffer(req.PeerID, req.OfferID, req.ProvidesAmount); err != nil {
+	if _, err := s.takeOffer(req.PeerID, req.OfferID, req.ProvidesAmount, req.Profile); err != nil {
 		return err
 	}
 
@@ -272,21 +531,58 @@ func (s *NetService) MakeOffer(
 }
 
 func (s *NetService) makeOffer(req *rpctypes.MakeOfferRequest) (*rpctypes.MakeOfferResponse, *types.OfferExtra, error) {
-	offer := types.NewOffer(
-		coins.ProvidesXMR,
-		req.MinAmount,
-		req.MaxAmount,
-		req.ExchangeRate,
-		req.EthAsset,
-	)
+	offer, err := s.newOffer(req)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	offerExtra, err := s.xmrmaker.MakeOffer(offer, req.UseRelayer)
+	offerExtra, err := s.xmrmaker.MakeOffer(offer, req.UseRelayer, req.Profile)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	return &rpctypes.MakeOfferResponse{
-		PeerID:  s.net.PeerID(),
-		OfferID: offer.ID,
+		PeerID:       s.net.PeerID(),
+		OfferID:      offer.ID,
+		ExchangeRate: offer.ExchangeRate,
 	}, offerExtra, nil
 }
+
+// newOffer returns the offer of the request, whose exchange rate is either fixed or
+// pegged to a price source.
+func (s *NetService) newOffer(req *rpctypes.MakeOfferRequest) (*types.Offer, error) {
+	if req.Peg == nil {
+		if req.ExchangeRate == nil {
+			return nil, errExchangeRateNotSet
+		}
+
+		return types.NewOffer(
+			coins.ProvidesXMR,
+			req.MinAmount,
+			req.MaxAmount,
+			req.ExchangeRate,
+			req.EthAsset,
+		), nil
+	}
+
+	if req.ExchangeRate != nil {
+		return nil, errExchangeRateAndPeg
+	}
+
+	if req.EthAsset.IsToken() {
+		return nil, errPegWithToken
+	}
+
+	rate, err := s.xmrmaker.PeggedExchangeRate(req.Peg)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewPeggedOffer(
+		coins.ProvidesXMR,
+		req.MinAmount,
+		req.MaxAmount,
+		rate,
+		req.Peg,
+	), nil
+}
@@ -4,17 +4,27 @@
 package rpc
 
 import (
+	"context"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+	libp2ptest "github.com/libp2p/go-libp2p/core/test"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/net/message"
 
 	"github.com/stretchr/testify/require"
 )
 
 func TestNet_Discover(t *testing.T) {
-	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), false)
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
 
 	req := &rpctypes.DiscoverRequest{
 		Provides: "",
@@ -27,8 +37,18 @@ func TestNet_Discover(t *testing.T) {
 	require.Equal(t, 0, len(resp.PeerIDs))
 }
 
+func TestNet_NatStatus(t *testing.T) {
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
+
+	resp := new(rpctypes.NATStatusResponse)
+	err := ns.NatStatus(nil, nil, resp)
+	require.NoError(t, err)
+	require.Equal(t, "private", resp.Reachability)
+	require.Empty(t, resp.RelayAddrs)
+}
+
 func TestNet_Query(t *testing.T) {
-	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), false)
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
 
 	req := &rpctypes.QueryPeerRequest{
 		PeerID: "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
@@ -42,7 +62,7 @@ func TestNet_Query(t *testing.T) {
 }
 
 func TestNet_TakeOffer(t *testing.T) {
-	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), false)
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
 
 	req := &rpctypes.TakeOfferRequest{
 		PeerID:         "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
@@ -54,8 +74,22 @@ func TestNet_TakeOffer(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestNet_TakeOffer_incompatibleMaker(t *testing.T) {
+	mn := &mockNet{capabilities: &net.Capabilities{Version: "v1.0.0", Protocols: []string{"/swap/1"}}}
+	ns := NewNetService(context.Background(), mn, new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
+
+	req := &rpctypes.TakeOfferRequest{
+		PeerID:         "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
+		OfferID:        testSwapID,
+		ProvidesAmount: apd.New(1, 0),
+	}
+
+	err := ns.TakeOffer(nil, req, nil)
+	require.ErrorContains(t, err, "peer runs swapd v1.0.0, which supports none of our swap protocols")
+}
+
 func TestNet_TakeOfferSync(t *testing.T) {
-	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), false)
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
 
 	req := &rpctypes.TakeOfferRequest{
 		PeerID:         "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
@@ -68,3 +102,102 @@ func TestNet_TakeOfferSync(t *testing.T) {
 	err := ns.TakeOfferSync(nil, req, resp)
 	require.NoError(t, err)
 }
+
+type mockRelayerStatsDB struct {
+	stats []*db.RelayerStats
+}
+
+func (m *mockRelayerStatsDB) GetAllRelayerStats() ([]*db.RelayerStats, error) {
+	return m.stats, nil
+}
+
+func TestNet_RelayerStats(t *testing.T) {
+	relayerDB := &mockRelayerStatsDB{
+		stats: []*db.RelayerStats{
+			{PeerID: "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5", Failed: 2},
+			{PeerID: "12D3KooWHLUrLnJtUbaGzTSi6azZavKhNgUZTtSiUZ9Uy12v1eZ7", Confirmed: 2},
+		},
+	}
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil,
+		relayerDB, false)
+
+	resp := new(rpctypes.RelayerStatsResponse)
+	err := ns.RelayerStats(nil, nil, resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Relayers, 2)
+
+	// best score first
+	require.Equal(t, relayerDB.stats[1].PeerID, resp.Relayers[0].PeerID)
+	require.Equal(t, uint64(2), resp.Relayers[0].Confirmed)
+	require.Equal(t, 0.75, resp.Relayers[0].Score)
+	require.Equal(t, 0.25, resp.Relayers[1].Score)
+}
+
+func TestNet_DiscoverRelayers(t *testing.T) {
+	defaultTimeout := relayerQuoteTimeout
+	relayerQuoteTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { relayerQuoteTimeout = defaultTimeout })
+
+	var relayers []peer.ID
+	for i := 0; i < 4; i++ {
+		relayerID, err := libp2ptest.RandPeerID()
+		require.NoError(t, err)
+		relayers = append(relayers, relayerID)
+	}
+
+	mn := &mockNet{
+		discovered: relayers,
+		relayerQuotes: map[peer.ID]*message.RelayFeeQuote{
+			relayers[0]: {FeeWei: big.NewInt(1e15), ETASeconds: 30},
+			relayers[1]: {FeeWei: big.NewInt(2e15), ETASeconds: 60},
+			relayers[2]: {FeeWei: big.NewInt(1e14), ETASeconds: 10},
+			// relayers[3] fails to respond
+		},
+		relayerDelay: map[peer.ID]time.Duration{
+			relayers[0]: 100 * time.Millisecond,
+			relayers[1]: 100 * time.Millisecond,
+			relayers[2]: time.Second, // exceeds the timeout
+		},
+	}
+	ns := NewNetService(context.Background(), mn, new(mockXMRTaker), nil, new(mockSwapManager),
+		newMockProtocolBackend(), nil, false)
+
+	start := time.Now()
+	resp := new(rpctypes.DiscoverRelayersResponse)
+	err := ns.DiscoverRelayers(nil, new(rpctypes.DiscoverRelayersRequest), resp)
+	require.NoError(t, err)
+
+	// the relayers are queried concurrently, so the response takes about one timeout
+	require.Less(t, time.Since(start), 800*time.Millisecond)
+
+	require.Len(t, resp.Relayers, 4)
+	for i, info := range resp.Relayers {
+		require.Equal(t, relayers[i], info.PeerID)
+	}
+	require.Zero(t, resp.Relayers[0].Fee.Cmp(coins.StrToDecimal("0.001")))
+	require.Equal(t, uint64(30), resp.Relayers[0].ETASeconds)
+	require.Zero(t, resp.Relayers[1].Fee.Cmp(coins.StrToDecimal("0.002")))
+	require.Nil(t, resp.Relayers[2].Fee) // timed out
+	require.Nil(t, resp.Relayers[3].Fee) // failed
+}
+
+func TestNet_MakeOffer_peg(t *testing.T) {
+	ns := NewNetService(context.Background(), new(mockNet), nil, new(mockXMRMaker), new(mockSwapManager), nil, nil, false)
+
+	req := &rpctypes.MakeOfferRequest{
+		MinAmount: apd.New(1, 0),
+		MaxAmount: apd.New(2, 0),
+		Peg:       &types.OfferPeg{Source: "kraken", Markup: apd.New(2, 0)},
+	}
+
+	resp := new(rpctypes.MakeOfferResponse)
+	require.NoError(t, ns.MakeOffer(nil, req, resp))
+	require.Equal(t, "0.075", resp.ExchangeRate.String())
+
+	req.ExchangeRate = coins.StrToExchangeRate("0.08")
+	require.ErrorIs(t, ns.MakeOffer(nil, req, resp), errExchangeRateAndPeg)
+
+	req.Peg = nil
+	req.ExchangeRate = nil
+	require.ErrorIs(t, ns.MakeOffer(nil, req, resp), errExchangeRateNotSet)
+}
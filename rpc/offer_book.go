@@ -0,0 +1,145 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const (
+	defaultOfferBookInterval = 30 * time.Second
+	minOfferBookInterval     = 5 * time.Second
+)
+
+// offerBook holds the offers of the peers on the network, by peer and offer ID.
+type offerBook map[peer.ID]map[types.Hash]*types.Offer
+
+// queryOfferBook discovers the peers providing the coin and gets their offers
+// matching the filter, querying the ones that did not publish their offers on the
+// offers topic. Peers that fail to answer the query keep their offers of the
+// previous book, so they don't seem to remove and re-add their offers.
+func (s *NetService) queryOfferBook(
+	req *rpctypes.DiscoverRequest,
+	filter *types.OfferFilter,
+	prev offerBook,
+) (offerBook, error) {
+	peerIDs, gossiped, err := s.discoverMakers(req, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	book := make(offerBook, len(peerIDs))
+	for _, p := range peerIDs {
+		peerOffers, err := s.peerOffers(p, gossiped, filter)
+		if err != nil {
+			log.Debugf("Failed to query peer ID %s", p)
+			if offers, ok := prev[p]; ok {
+				book[p] = offers
+			}
+			continue
+		}
+
+		offers := make(map[types.Hash]*types.Offer, len(peerOffers))
+		for _, offer := range peerOffers {
+			offers[offer.ID] = offer
+		}
+		book[p] = offers
+	}
+
+	return book, nil
+}
+
+// diffOfferBooks returns the changes from the previous to the next offer book, the
+// removed offers first, each sorted by peer and offer ID.
+func diffOfferBooks(prev, next offerBook) []*rpctypes.OfferBookUpdate {
+	var updates []*rpctypes.OfferBookUpdate
+	add := func(typ rpctypes.OfferUpdateType, peerID peer.ID, offer *types.Offer) {
+		updates = append(updates, &rpctypes.OfferBookUpdate{Type: typ, PeerID: peerID, Offer: offer})
+	}
+
+	for peerID, offers := range next {
+		for id, offer := range offers {
+			if _, ok := prev[peerID][id]; !ok {
+				add(rpctypes.OfferAdded, peerID, offer)
+			}
+		}
+	}
+
+	for peerID, offers := range prev {
+		for id, offer := range offers {
+			if _, ok := next[peerID][id]; !ok {
+				add(rpctypes.OfferRemoved, peerID, offer)
+			}
+		}
+	}
+
+	// Removals go first, so a changed offer is never listed twice by a client
+	// applying the updates in order
+	sort.Slice(updates, func(i, j int) bool {
+		a, b := updates[i], updates[j]
+		if a.Type != b.Type {
+			return a.Type == rpctypes.OfferRemoved
+		}
+		if a.PeerID != b.PeerID {
+			return a.PeerID < b.PeerID
+		}
+		return bytes.Compare(a.Offer.ID[:], b.Offer.ID[:]) < 0
+	})
+
+	return updates
+}
+
+// subscribeOffers writes the changes of the offer book to the connection each time
+// it is refreshed, starting with all offers of the first refresh as added offers.
+// example: `{"jsonrpc":"2.0", "method":"net_subscribeOffers", "params": {"interval": 10}, "id": 0}`
+func (s *wsServer) subscribeOffers(ctx context.Context, conn *websocket.Conn,
+	params *rpctypes.SubscribeOffersRequest) error {
+	if s.ns.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	interval := time.Duration(params.Interval) * time.Second
+	switch {
+	case params.Interval == 0:
+		interval = defaultOfferBookInterval
+	case interval < minOfferBookInterval:
+		return fmt.Errorf("offer book interval must be at least %s", minOfferBookInterval)
+	}
+
+	req := &rpctypes.DiscoverRequest{
+		Provides:   params.Provides,
+		SearchTime: params.SearchTime,
+	}
+
+	var book offerBook
+	for {
+		next, err := s.ns.queryOfferBook(req, &params.OfferFilter, book)
+		if err != nil {
+			return err
+		}
+
+		for _, update := range diffOfferBooks(book, next) {
+			if err = writeResponse(conn, update); err != nil {
+				return err
+			}
+		}
+		book = next
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
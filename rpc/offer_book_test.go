@@ -0,0 +1,80 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestDiffOfferBooks(t *testing.T) {
+	peerA := peer.ID("a")
+	peerB := peer.ID("b")
+	offer1 := &types.Offer{ID: types.Hash{1}}
+	offer2 := &types.Offer{ID: types.Hash{2}}
+	offer3 := &types.Offer{ID: types.Hash{3}}
+
+	prev := offerBook{
+		peerA: {offer1.ID: offer1, offer2.ID: offer2},
+	}
+	next := offerBook{
+		peerA: {offer2.ID: offer2, offer3.ID: offer3},
+		peerB: {offer1.ID: offer1},
+	}
+
+	expected := []*rpctypes.OfferBookUpdate{
+		{Type: rpctypes.OfferRemoved, PeerID: peerA, Offer: offer1},
+		{Type: rpctypes.OfferAdded, PeerID: peerA, Offer: offer3},
+		{Type: rpctypes.OfferAdded, PeerID: peerB, Offer: offer1},
+	}
+	require.Equal(t, expected, diffOfferBooks(prev, next))
+
+	// every offer is added to an empty book, and nothing changes without changes
+	require.Len(t, diffOfferBooks(nil, next), 3)
+	require.Empty(t, diffOfferBooks(next, next))
+}
+
+func TestNet_queryOfferBook(t *testing.T) {
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
+
+	// the mock network doesn't discover any peers
+	book, err := ns.queryOfferBook(&rpctypes.DiscoverRequest{}, nil, offerBook{
+		testPeerID: {testSwapID: &types.Offer{ID: testSwapID}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, book)
+}
+
+func TestNet_queryOfferBook_gossiped(t *testing.T) {
+	gossipedOffer := &types.Offer{ID: types.Hash{1}, Provides: coins.ProvidesXMR}
+	mockNet := &mockNet{
+		gossiped: map[peer.ID][]*types.Offer{testPeerID: {gossipedOffer}},
+	}
+	ns := NewNetService(context.Background(), mockNet, new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
+
+	// the maker is found by gossip, without being discovered or queried
+	book, err := ns.queryOfferBook(&rpctypes.DiscoverRequest{Provides: string(coins.ProvidesXMR)}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, offerBook{testPeerID: {gossipedOffer.ID: gossipedOffer}}, book)
+
+	// makers without offers of the coin are left out
+	book, err = ns.queryOfferBook(&rpctypes.DiscoverRequest{Provides: string(coins.ProvidesETH)}, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, book)
+
+	// as are makers without offers matching the filter
+	token := types.EthAsset(ethcommon.Address{0x1})
+	filter := &types.OfferFilter{EthAsset: &token}
+	book, err = ns.queryOfferBook(&rpctypes.DiscoverRequest{Provides: string(coins.ProvidesXMR)}, filter, nil)
+	require.NoError(t, err)
+	require.Empty(t, book)
+}
@@ -0,0 +1,328 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/athanorlabs/atomic-swap/cliutil"
+)
+
+// OpenRPCPath is the path at which the server serves the OpenRPC document of its
+// methods.
+const OpenRPCPath = "/openrpc.json"
+
+// openRPCVersion is the version of the OpenRPC specification of the document.
+const openRPCVersion = "1.2.6"
+
+var (
+	typeOfError           = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfRequest         = reflect.TypeOf((*http.Request)(nil))
+	typeOfBigInt          = reflect.TypeOf(big.Int{})
+	typeOfJSONMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeOfJSONUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	typeOfTextMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	typeOfTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// OpenRPCDocument is an OpenRPC document describing the JSON-RPC methods of swapd,
+// from which clients in other languages can be generated. See
+// https://spec.open-rpc.org.
+type OpenRPCDocument struct {
+	OpenRPC    string            `json:"openrpc"`
+	Info       OpenRPCInfo       `json:"info"`
+	Methods    []*OpenRPCMethod  `json:"methods"`
+	Components OpenRPCComponents `json:"components"`
+}
+
+// OpenRPCInfo is the metadata of the API.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCMethod describes a JSON-RPC method. The params are passed by name, as the
+// fields of the request object.
+type OpenRPCMethod struct {
+	Name           string                      `json:"name"`
+	ParamStructure string                      `json:"paramStructure"`
+	Params         []*OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor   `json:"result"`
+	AuthScope      AuthScope                   `json:"x-auth-scope"`
+}
+
+// OpenRPCContentDescriptor describes a param or the result of a method.
+type OpenRPCContentDescriptor struct {
+	Name     string         `json:"name"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenRPCSchema `json:"schema"`
+}
+
+// OpenRPCComponents holds the schemas of the named types, which are referenced by
+// the schemas of the methods.
+type OpenRPCComponents struct {
+	Schemas map[string]*OpenRPCSchema `json:"schemas"`
+}
+
+// OpenRPCSchema is the JSON schema of a value.
+type OpenRPCSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *OpenRPCSchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenRPCSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *OpenRPCSchema            `json:"additionalProperties,omitempty"`
+}
+
+// openRPCService is a service registered with the JSON-RPC server.
+type openRPCService struct {
+	name     string
+	receiver any
+}
+
+// newOpenRPCDocument returns the OpenRPC document of the methods of the services.
+// The methods are found the way the JSON-RPC server finds them, and the schemas of
+// their params and results are derived from the json and validate tags of the
+// request and response types.
+func newOpenRPCDocument(services []*openRPCService) *OpenRPCDocument {
+	g := &openRPCGenerator{schemas: make(map[string]*OpenRPCSchema)}
+	doc := &OpenRPCDocument{
+		OpenRPC: openRPCVersion,
+		Info: OpenRPCInfo{
+			Title:   "swapd JSON-RPC API",
+			Version: cliutil.GetVersion(),
+		},
+		Methods:    []*OpenRPCMethod{},
+		Components: OpenRPCComponents{Schemas: g.schemas},
+	}
+
+	for _, service := range services {
+		rcvrType := reflect.TypeOf(service.receiver)
+		for i := 0; i < rcvrType.NumMethod(); i++ {
+			method := rcvrType.Method(i)
+			if !isRPCMethod(method) {
+				continue
+			}
+
+			name := jsonRPCMethodName(service.name + "." + method.Name)
+			doc.Methods = append(doc.Methods, &OpenRPCMethod{
+				Name:           name,
+				ParamStructure: "by-name",
+				Params:         g.params(method.Type.In(2).Elem()),
+				Result: &OpenRPCContentDescriptor{
+					Name:   "result",
+					Schema: g.resultSchema(method.Type.In(3).Elem()),
+				},
+				AuthScope: RequiredScope(name),
+			})
+		}
+	}
+
+	sort.Slice(doc.Methods, func(i, j int) bool {
+		return doc.Methods[i].Name < doc.Methods[j].Name
+	})
+
+	return doc
+}
+
+// isRPCMethod returns whether the method has the signature of the methods that the
+// JSON-RPC server registers.
+func isRPCMethod(method reflect.Method) bool {
+	mtype := method.Type
+	return method.IsExported() &&
+		mtype.NumIn() == 4 &&
+		mtype.In(1) == typeOfRequest &&
+		mtype.In(2).Kind() == reflect.Pointer &&
+		mtype.In(3).Kind() == reflect.Pointer &&
+		mtype.NumOut() == 1 &&
+		mtype.Out(0) == typeOfError
+}
+
+// openRPCGenerator derives the schemas of types, adding the schemas of named
+// structs to the components.
+type openRPCGenerator struct {
+	schemas map[string]*OpenRPCSchema
+}
+
+// params returns the by-name params of a method taking the request type. Methods
+// taking no request have an interface request type.
+func (g *openRPCGenerator) params(reqType reflect.Type) []*OpenRPCContentDescriptor {
+	params := []*OpenRPCContentDescriptor{}
+	if reqType.Kind() != reflect.Struct {
+		return params
+	}
+
+	schema := g.structSchema(reqType)
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		params = append(params, &OpenRPCContentDescriptor{
+			Name:     name,
+			Required: required[name],
+			Schema:   schema.Properties[name],
+		})
+	}
+
+	return params
+}
+
+// resultSchema returns the schema of the result of a method with the response
+// type. Methods without a response have an interface response type, and return null.
+func (g *openRPCGenerator) resultSchema(respType reflect.Type) *OpenRPCSchema {
+	if respType.Kind() == reflect.Interface {
+		return &OpenRPCSchema{Type: "null"}
+	}
+	return g.schema(respType)
+}
+
+func implementsAny(t reflect.Type, ifaces ...reflect.Type) bool {
+	for _, iface := range ifaces {
+		if t.Implements(iface) || reflect.PointerTo(t).Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *openRPCGenerator) schema(t reflect.Type) *OpenRPCSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Types with their own encoding, like hashes, addresses and decimals, are
+	// encoded as strings, except big integers
+	switch {
+	case t == typeOfBigInt:
+		return &OpenRPCSchema{Type: "integer"}
+	case implementsAny(t, typeOfTextMarshaler, typeOfTextUnmarshaler):
+		return &OpenRPCSchema{Type: "string"}
+	case implementsAny(t, typeOfJSONMarshaler, typeOfJSONUnmarshaler):
+		return &OpenRPCSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenRPCSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &OpenRPCSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenRPCSchema{Type: "number"}
+	case reflect.String:
+		return &OpenRPCSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenRPCSchema{Type: "string", Format: "byte"}
+		}
+		return &OpenRPCSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenRPCSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.structRef(t)
+	default:
+		return &OpenRPCSchema{}
+	}
+}
+
+// structRef adds the schema of the named struct to the components, if it's not
+// there yet, and returns a reference to it.
+func (g *openRPCGenerator) structRef(t reflect.Type) *OpenRPCSchema {
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+	ref := &OpenRPCSchema{Ref: "#/components/schemas/" + name}
+	if _, ok := g.schemas[name]; ok {
+		return ref
+	}
+
+	// The placeholder ends the recursion of self-referencing types
+	g.schemas[name] = &OpenRPCSchema{}
+	g.schemas[name] = g.structSchema(t)
+	return ref
+}
+
+func (g *openRPCGenerator) structSchema(t reflect.Type) *OpenRPCSchema {
+	schema := &OpenRPCSchema{
+		Type:       "object",
+		Properties: make(map[string]*OpenRPCSchema),
+	}
+	g.addFields(schema, t)
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// addFields adds the JSON encoded fields of the struct, including the fields of its
+// embedded structs, to the schema.
+func (g *openRPCGenerator) addFields(schema *OpenRPCSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			g.addFields(schema, fieldType)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = g.schema(field.Type)
+		if isRequiredField(field) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// isRequiredField returns whether the field's validate tag requires it. Rules after
+// "dive" apply to the elements of the field, not to the field.
+func isRequiredField(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		switch rule {
+		case "dive":
+			return false
+		case "required":
+			return true
+		}
+	}
+	return false
+}
+
+// openRPCHandler returns the handler serving the OpenRPC document.
+func openRPCHandler(doc *OpenRPCDocument) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(doc); err != nil {
+			log.Warnf("failed to write OpenRPC document: %s", err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOpenRPCDocument(t *testing.T) {
+	doc := newOpenRPCDocument([]*openRPCService{
+		{name: DaemonNamespace, receiver: &DaemonService{}},
+		{name: SwapNamespace, receiver: &SwapService{}},
+	})
+
+	methods := make(map[string]*OpenRPCMethod)
+	for _, m := range doc.Methods {
+		methods[m.Name] = m
+	}
+
+	// methods without a request take no params and methods without a response
+	// return null
+	require.Contains(t, methods, "daemon_shutdown")
+	require.Empty(t, methods["daemon_shutdown"].Params)
+	require.Equal(t, "null", methods["daemon_shutdown"].Result.Schema.Type)
+	require.Equal(t, ScopeAdmin, methods["daemon_shutdown"].AuthScope)
+
+	getStatus := methods["swap_getStatus"]
+	require.NotNil(t, getStatus)
+	require.Equal(t, ScopeRead, getStatus.AuthScope)
+	require.Len(t, getStatus.Params, 1)
+	require.Equal(t, "id", getStatus.Params[0].Name)
+	require.True(t, getStatus.Params[0].Required)
+	require.Equal(t, "string", getStatus.Params[0].Schema.Type)
+
+	require.Equal(t, "#/components/schemas/rpc.GetStatusResponse", getStatus.Result.Schema.Ref)
+	resp := doc.Components.Schemas["rpc.GetStatusResponse"]
+	require.Equal(t, []string{"info", "startTime", "status"}, resp.Required)
+	require.Equal(t, "string", resp.Properties["startTime"].Type)
+
+	// unexported helpers with other signatures are not methods
+	require.NotContains(t, methods, "daemon_setEthStatus")
+
+	_, err := json.Marshal(doc)
+	require.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pastSwapCursor is the position of a past swap in the order of swap_getPast, by
+// start time and then by ID. Clients get it as an opaque string.
+type pastSwapCursor struct {
+	startTime int64 // in nanoseconds since the Unix epoch
+	id        types.Hash
+}
+
+func newPastSwapCursor(info *swap.Info) *pastSwapCursor {
+	return &pastSwapCursor{
+		startTime: info.StartTime.UnixNano(),
+		id:        info.OfferID,
+	}
+}
+
+// decodePastSwapCursor decodes a cursor returned by String.
+func decodePastSwapCursor(s string) (*pastSwapCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) != 8+len(types.Hash{}) {
+		return nil, errInvalidCursor
+	}
+
+	c := &pastSwapCursor{startTime: int64(binary.BigEndian.Uint64(data[:8]))}
+	copy(c.id[:], data[8:])
+	return c, nil
+}
+
+// String returns the encoding of the cursor that clients pass back.
+func (c *pastSwapCursor) String() string {
+	data := binary.BigEndian.AppendUint64(nil, uint64(c.startTime))
+	data = append(data, c.id[:]...)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// before returns whether the cursor's position comes before the swap.
+func (c *pastSwapCursor) before(info *swap.Info) bool {
+	startTime := info.StartTime.UnixNano()
+	if c.startTime != startTime {
+		return c.startTime < startTime
+	}
+	return bytes.Compare(c.id[:], info.OfferID[:]) < 0
+}
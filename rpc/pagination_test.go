@@ -0,0 +1,105 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestPastSwapCursor(t *testing.T) {
+	c := &pastSwapCursor{startTime: time.Now().UnixNano(), id: types.Hash{1, 2, 3}}
+	decoded, err := decodePastSwapCursor(c.String())
+	require.NoError(t, err)
+	require.Equal(t, c, decoded)
+
+	_, err = decodePastSwapCursor("not a cursor")
+	require.ErrorIs(t, err, errInvalidCursor)
+}
+
+func newTestPastSwapsForPages() map[types.Hash]*swap.Info {
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := make(map[types.Hash]*swap.Info)
+	for i := 0; i < 5; i++ {
+		status := types.CompletedSuccess
+		if i%2 == 1 {
+			status = types.CompletedRefund
+		}
+
+		info := swap.NewInfo(testPeerID, types.Hash{byte(i + 1)}, coins.ProvidesXMR,
+			apd.New(int64(i+1), 0), apd.New(int64(i+1), -1), coins.ToExchangeRate(apd.New(1, -1)),
+			types.EthAssetETH, status, 1, nil)
+		info.StartTime = start.Add(time.Duration(i) * time.Hour)
+		past[info.OfferID] = info
+	}
+	return past
+}
+
+func TestSwapService_GetPast_pages(t *testing.T) {
+	sm := &mockPastSwapManager{past: newTestPastSwapsForPages()}
+	s := NewSwapService(context.Background(), sm, nil, nil, nil, nil, nil, nil)
+
+	var ids []types.Hash
+	req := &GetPastRequest{Limit: 2}
+	for pages := 1; ; pages++ {
+		resp := new(GetPastResponse)
+		require.NoError(t, s.GetPast(nil, req, resp))
+		require.LessOrEqual(t, len(resp.Swaps), 2)
+		for _, ps := range resp.Swaps {
+			ids = append(ids, ps.ID)
+		}
+
+		if resp.NextCursor == "" {
+			require.Equal(t, 3, pages)
+			break
+		}
+		req.Cursor = resp.NextCursor
+	}
+
+	require.Equal(t, []types.Hash{{1}, {2}, {3}, {4}, {5}}, ids)
+}
+
+func TestSwapService_GetPast_filter(t *testing.T) {
+	sm := &mockPastSwapManager{past: newTestPastSwapsForPages()}
+	s := NewSwapService(context.Background(), sm, nil, nil, nil, nil, nil, nil)
+
+	status := types.CompletedSuccess
+	since := time.Date(2023, 3, 1, 13, 0, 0, 0, time.UTC)
+	req := &GetPastRequest{
+		PastSwapFilter: PastSwapFilter{
+			Status: &status,
+			Since:  &since,
+		},
+	}
+
+	resp := new(GetPastResponse)
+	require.NoError(t, s.GetPast(nil, req, resp))
+	require.Len(t, resp.Swaps, 2)
+	require.Equal(t, types.Hash{3}, resp.Swaps[0].ID)
+	require.Equal(t, types.Hash{5}, resp.Swaps[1].ID)
+	require.Empty(t, resp.NextCursor)
+
+	// the min amount is in XMR, and the until time is exclusive
+	until := time.Date(2023, 3, 1, 15, 0, 0, 0, time.UTC)
+	req = &GetPastRequest{
+		PastSwapFilter: PastSwapFilter{
+			Until:     &until,
+			MinAmount: apd.New(2, 0),
+		},
+	}
+
+	resp = new(GetPastResponse)
+	require.NoError(t, s.GetPast(nil, req, resp))
+	require.Len(t, resp.Swaps, 2)
+	require.Equal(t, types.Hash{2}, resp.Swaps[0].ID)
+	require.Equal(t, types.Hash{3}, resp.Swaps[1].ID)
+}
@@ -6,26 +6,59 @@ package rpc
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"net/http"
 	"time"
 
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/cliutil"
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/metrics"
+	"github.com/athanorlabs/atomic-swap/monero"
 )
 
+// TokenInfoCacheDB contains the methods for invalidating the cached metadata of
+// tokens in the database.
+type TokenInfoCacheDB interface {
+	DeleteTokenInfo(chainID *big.Int, tokenAddr ethcommon.Address) (bool, error)
+	ClearTokenInfo() (int, error)
+}
+
 // PersonalService handles private keys and wallets.
 type PersonalService struct {
-	ctx      context.Context
-	xmrmaker XMRMaker
-	pb       ProtocolBackend
+	ctx                 context.Context
+	xmrmaker            XMRMaker
+	pb                  ProtocolBackend
+	ethKeyFile          string
+	ethKeystorePassword string
+	tokenRegistry       *coins.TokenRegistry
+	tokenInfoCache      TokenInfoCacheDB
 }
 
 // NewPersonalService ...
-func NewPersonalService(ctx context.Context, xmrmaker XMRMaker, pb ProtocolBackend) *PersonalService {
+func NewPersonalService(
+	ctx context.Context,
+	xmrmaker XMRMaker,
+	pb ProtocolBackend,
+	ethKeyFile string,
+	ethKeystorePassword string,
+	tokenRegistry *coins.TokenRegistry,
+	tokenInfoCache TokenInfoCacheDB,
+) *PersonalService {
 	return &PersonalService{
-		ctx:      ctx,
-		xmrmaker: xmrmaker,
-		pb:       pb,
+		ctx:                 ctx,
+		xmrmaker:            xmrmaker,
+		pb:                  pb,
+		ethKeyFile:          ethKeyFile,
+		ethKeystorePassword: ethKeystorePassword,
+		tokenRegistry:       tokenRegistry,
+		tokenInfoCache:      tokenInfoCache,
 	}
 }
 
@@ -69,36 +102,124 @@ func (s *PersonalService) TokenInfo(
 	req *rpctypes.TokenInfoRequest,
 	resp *rpctypes.TokenInfoResponse,
 ) error {
-	tokenInfo, err := s.pb.ETHClient().ERC20Info(s.ctx, req.TokenAddr)
+	ec := s.pb.ETHClient()
+	tokenInfo, err := ec.ERC20Info(s.ctx, req.TokenAddr)
 	if err != nil {
 		return err
 	}
 
-	*resp = *tokenInfo
+	resp.ERC20TokenInfo = tokenInfo
+
+	// the probe is only informational, so a token that can't be probed is not flagged
+	returnsBool, err := contracts.ERC20ReturnsBool(s.ctx, ec.Raw(), req.TokenAddr, ec.Address())
+	if err != nil {
+		log.Warnf("unable to check if token %s returns a bool from transfer and approve: %s",
+			tokenInfo.SanitizedSymbol(), err)
+		return nil
+	}
+
+	resp.NonStandard = !returnsBool
+	return nil
+}
+
+// ClearTokenInfoCacheRequest ...
+type ClearTokenInfoCacheRequest struct {
+	// TokenAddr is the token whose cached metadata is removed, all tokens if not set
+	TokenAddr *ethcommon.Address `json:"tokenAddr,omitempty"`
+}
+
+// ClearTokenInfoCacheResponse ...
+type ClearTokenInfoCacheResponse struct {
+	Removed int `json:"removed"`
+}
+
+// ClearTokenInfoCache removes the cached metadata of a token, or of all tokens, so it
+// is looked up on chain again the next time that it is needed. Token metadata doesn't
+// change, except for upgradeable token contracts.
+func (s *PersonalService) ClearTokenInfoCache(
+	_ *http.Request,
+	req *ClearTokenInfoCacheRequest,
+	resp *ClearTokenInfoCacheResponse,
+) error {
+	if s.tokenInfoCache == nil {
+		return errNoTokenInfoCache
+	}
+
+	if req.TokenAddr == nil {
+		removed, err := s.tokenInfoCache.ClearTokenInfo()
+		if err != nil {
+			return err
+		}
+		resp.Removed = removed
+		return nil
+	}
+
+	deleted, err := s.tokenInfoCache.DeleteTokenInfo(s.pb.ETHClient().ChainID(), *req.TokenAddr)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		resp.Removed = 1
+	}
+	return nil
+}
+
+// SupportedTokensResponse ...
+type SupportedTokensResponse struct {
+	Tokens []*coins.RegistryToken `json:"tokens" validate:"dive,required"`
+}
+
+// SupportedTokens returns the curated list of tokens of swapd's ethereum chain. It
+// allows frontends to populate token pickers without looking up each token on chain.
+func (s *PersonalService) SupportedTokens(
+	_ *http.Request,
+	_ *interface{},
+	resp *SupportedTokensResponse,
+) error {
+	resp.Tokens = []*coins.RegistryToken{}
+	if s.tokenRegistry != nil {
+		resp.Tokens = s.tokenRegistry.Tokens()
+	}
 	return nil
 }
 
 // Balances returns combined information of both the Monero and Ethereum account addresses
-// and balances.
+// and balances, with the balances of the requested tokens in the order of the request.
+// A token that is requested more than once is only included once.
 func (s *PersonalService) Balances(
 	_ *http.Request,
 	req *rpctypes.BalancesRequest, // optional, can be nil
 	resp *rpctypes.BalancesResponse,
 ) error {
-	mAddr, mBal, err := s.xmrmaker.GetMoneroBalance()
+	profile := ""
+	if req != nil {
+		profile = req.Profile
+	}
+
+	b, err := s.pb.WithProfile(profile)
 	if err != nil {
 		return err
 	}
 
-	eBal, err := s.pb.ETHClient().Balance(s.ctx)
+	mAddr, mBal, err := s.xmrmaker.GetMoneroBalance(profile)
+	if err != nil {
+		return err
+	}
+
+	lockedOutputs, err := b.XMRClient().GetLockedOutputs()
+	if err != nil {
+		return err
+	}
+
+	ec := b.ETHClient()
+	eBal, err := ec.Balance(s.ctx)
 	if err != nil {
 		return err
 	}
 
 	var tokenBalances []*coins.ERC20TokenAmount
 	if req != nil {
-		ec := s.pb.ETHClient()
-		for _, tokenAddr := range req.TokenAddrs {
+		for _, tokenAddr := range uniqueTokenAddrs(req.TokenAddrs) {
 			balance, err := ec.ERC20Balance(s.ctx, tokenAddr)
 			if err != nil {
 				return fmt.Errorf("unable to get balance for %s: %w", tokenAddr, err)
@@ -112,10 +233,379 @@ func (s *PersonalService) Balances(
 		MoneroAddress:           mAddr,
 		PiconeroBalance:         coins.NewPiconeroAmount(mBal.Balance),
 		PiconeroUnlockedBalance: coins.NewPiconeroAmount(mBal.UnlockedBalance),
+		PiconeroLockedBalance:   coins.NewPiconeroAmount(lockedBalance(mBal)),
 		BlocksToUnlock:          mBal.BlocksToUnlock,
-		EthAddress:              s.pb.ETHClient().Address(),
+		LockedOutputs:           toRPCLockedOutputs(lockedOutputs),
+		EthAddress:              ec.Address(),
 		WeiBalance:              eBal,
 		TokenBalances:           tokenBalances,
+		ViewOnly:                b.XMRClient().IsViewOnly(),
+	}
+	return nil
+}
+
+// Profiles returns the named profiles whose wallets and accounts offers and swaps
+// can be funded from, besides the default ones.
+func (s *PersonalService) Profiles(
+	_ *http.Request,
+	_ *interface{},
+	resp *rpctypes.ProfilesResponse,
+) error {
+	resp.Profiles = []*rpctypes.Profile{}
+	for _, name := range s.pb.ProfileNames() {
+		b, err := s.pb.WithProfile(name)
+		if err != nil {
+			return err
+		}
+
+		resp.Profiles = append(resp.Profiles, &rpctypes.Profile{
+			Name:          name,
+			MoneroAddress: b.XMRClient().PrimaryAddress(),
+			EthAddress:    b.ETHClient().Address(),
+		})
+	}
+	return nil
+}
+
+// lockedBalance returns the part of the balance that can't be spent yet.
+func lockedBalance(bal *wallet.GetBalanceResponse) uint64 {
+	if bal.UnlockedBalance > bal.Balance {
+		return 0
+	}
+	return bal.Balance - bal.UnlockedBalance
+}
+
+func toRPCLockedOutputs(outputs []*monero.LockedOutput) []*rpctypes.LockedOutput {
+	locked := make([]*rpctypes.LockedOutput, 0, len(outputs))
+	for _, o := range outputs {
+		locked = append(locked, &rpctypes.LockedOutput{
+			TxID:           o.TxID,
+			Amount:         coins.NewPiconeroAmount(o.Amount),
+			Height:         o.Height,
+			BlocksToUnlock: o.BlocksToUnlock,
+		})
+	}
+	return locked
+}
+
+// IncomingTransfers returns the incoming transfers of the primary monero wallet from
+// the given block height. The transfers that are in the transaction pool come last.
+func (s *PersonalService) IncomingTransfers(
+	_ *http.Request,
+	req *rpctypes.IncomingTransfersRequest,
+	resp *rpctypes.IncomingTransfersResponse,
+) error {
+	transfers, err := s.pb.XMRClient().GetIncomingTransfers(req.MinHeight)
+	if err != nil {
+		return err
+	}
+
+	resp.Transfers = make([]*rpctypes.IncomingTransfer, 0, len(transfers))
+	for _, t := range transfers {
+		resp.Transfers = append(resp.Transfers, &rpctypes.IncomingTransfer{
+			TxID:          t.TxID,
+			Amount:        coins.NewPiconeroAmount(t.Amount),
+			Height:        t.Height,
+			Confirmations: t.Confirmations,
+			Timestamp:     t.Timestamp,
+		})
+	}
+
+	return nil
+}
+
+// uniqueTokenAddrs returns the token addresses without duplicates, keeping the first
+// occurrence of each.
+func uniqueTokenAddrs(tokenAddrs []ethcommon.Address) []ethcommon.Address {
+	seen := make(map[ethcommon.Address]struct{}, len(tokenAddrs))
+	unique := make([]ethcommon.Address, 0, len(tokenAddrs))
+	for _, addr := range tokenAddrs {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		unique = append(unique, addr)
+	}
+	return unique
+}
+
+// RestoreEthKeyRequest ...
+type RestoreEthKeyRequest struct {
+	Mnemonic string `json:"mnemonic" validate:"required"`
+}
+
+// RestoreEthKeyResponse ...
+type RestoreEthKeyResponse struct {
+	EthAddress      ethcommon.Address `json:"ethAddress" validate:"required"`
+	RestartRequired bool              `json:"restartRequired"`
+}
+
+// RestoreEthKey recreates swapd's ethereum key file from the key's BIP-39 mnemonic.
+// A different key that was in the file is kept in a backup file. The restored key
+// is only used after swapd is restarted.
+func (s *PersonalService) RestoreEthKey(
+	_ *http.Request,
+	req *RestoreEthKeyRequest,
+	resp *RestoreEthKeyResponse,
+) error {
+	if s.ethKeyFile == "" {
+		return errNoEthKeyFile
+	}
+
+	// the key of an ongoing swap has to stay available until the swap completes
+	ongoing, err := s.pb.SwapManager().GetOngoingSwaps()
+	if err != nil {
+		return err
+	}
+	if len(ongoing) > 0 {
+		return errOngoingSwaps
+	}
+
+	addr, err := cliutil.RestoreEthKeyFile(s.ethKeyFile, req.Mnemonic, s.ethKeystorePassword)
+	if err != nil {
+		return err
+	}
+
+	resp.EthAddress = addr
+	resp.RestartRequired = addr != s.pb.ETHClient().Address()
+	return nil
+}
+
+// MoneroNode is the health of a monerod node as of its last check.
+type MoneroNode struct {
+	Host      string     `json:"host" validate:"required"`
+	Port      uint       `json:"port" validate:"required"`
+	Rank      int        `json:"rank" validate:"required"`
+	Current   bool       `json:"current"`
+	Healthy   bool       `json:"healthy"`
+	Height    uint64     `json:"height,omitempty"`
+	LatencyMs int64      `json:"latencyMs,omitempty"`
+	Version   string     `json:"version,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Checked   *time.Time `json:"checked,omitempty"`
+}
+
+// MoneroNodeStatusResponse ...
+type MoneroNodeStatusResponse struct {
+	Nodes []*MoneroNode `json:"nodes" validate:"dive,required"`
+}
+
+// MoneroNodeStatus returns the health of the configured monerod nodes, ranked by
+// height freshness and latency, and which of them the monero wallets are using. The
+// nodes are checked periodically, and the wallets switch to a better node if theirs
+// becomes unhealthy or slow.
+func (s *PersonalService) MoneroNodeStatus(_ *http.Request, _ *any, resp *MoneroNodeStatusResponse) error {
+	resp.Nodes = []*MoneroNode{}
+	for _, h := range s.pb.XMRClient().MoneroNodes() {
+		node := &MoneroNode{
+			Host:    h.Node.Host,
+			Port:    h.Node.Port,
+			Rank:    h.Rank,
+			Current: h.Current,
+		}
+		if !h.Checked.IsZero() {
+			checked := h.Checked
+			node.Healthy = h.Err == nil
+			node.Height = h.Height
+			node.LatencyMs = h.Latency.Milliseconds()
+			node.Version = h.Version
+			node.Checked = &checked
+			if h.Err != nil {
+				node.Error = h.Err.Error()
+			}
+		}
+		resp.Nodes = append(resp.Nodes, node)
+	}
+
+	return nil
+}
+
+// RestoreXMRWalletRequest ...
+type RestoreXMRWalletRequest struct {
+	Seed          string `json:"seed" validate:"required"`
+	RestoreHeight uint64 `json:"restoreHeight"`
+}
+
+// RestoreXMRWalletResponse ...
+type RestoreXMRWalletResponse struct {
+	Address *mcrypto.Address `json:"address" validate:"required"`
+}
+
+// RestoreXMRWallet recreates swapd's monero wallet from its 25 word mnemonic seed,
+// scanning from the restore height. The replaced wallet files are kept as backups.
+func (s *PersonalService) RestoreXMRWallet(
+	_ *http.Request,
+	req *RestoreXMRWalletRequest,
+	resp *RestoreXMRWalletResponse,
+) error {
+	// the wallet of an ongoing swap's funds has to stay open until the swap completes
+	ongoing, err := s.pb.SwapManager().GetOngoingSwaps()
+	if err != nil {
+		return err
+	}
+	if len(ongoing) > 0 {
+		return errOngoingSwapsXMRRestore
+	}
+
+	address, err := s.pb.XMRClient().RestoreFromSeed(req.Seed, req.RestoreHeight)
+	if err != nil {
+		return err
+	}
+
+	resp.Address = address
+	return nil
+}
+
+// TokenAllowanceRequest ...
+type TokenAllowanceRequest struct {
+	TokenAddr ethcommon.Address `json:"tokenAddr" validate:"required"`
+}
+
+// TokenAllowanceResponse ...
+type TokenAllowanceResponse struct {
+	Spender   ethcommon.Address       `json:"spender" validate:"required"`
+	Allowance *coins.ERC20TokenAmount `json:"allowance" validate:"required"`
+}
+
+// TokenAllowance returns the amount of the token that the SwapCreator contract is
+// allowed to transfer from swapd's ethereum account.
+func (s *PersonalService) TokenAllowance(
+	_ *http.Request,
+	req *TokenAllowanceRequest,
+	resp *TokenAllowanceResponse,
+) error {
+	allowance, err := s.tokenAllowance(req.TokenAddr)
+	if err != nil {
+		return err
+	}
+
+	resp.Spender = s.pb.SwapCreatorAddr()
+	resp.Allowance = allowance
+	return nil
+}
+
+// ApproveTokenRequest ...
+type ApproveTokenRequest struct {
+	TokenAddr ethcommon.Address `json:"tokenAddr" validate:"required"`
+	Amount    *apd.Decimal      `json:"amount" validate:"required"` // in standard units
+}
+
+// ApproveTokenResponse ...
+type ApproveTokenResponse struct {
+	TxHash    ethcommon.Hash          `json:"txHash" validate:"required"`
+	Allowance *coins.ERC20TokenAmount `json:"allowance" validate:"required"`
+}
+
+// ApproveToken sets the amount of the token that the SwapCreator contract is allowed
+// to transfer from swapd's ethereum account. Swaps funded from a sufficient allowance
+// don't need their own approve transaction.
+func (s *PersonalService) ApproveToken(
+	_ *http.Request,
+	req *ApproveTokenRequest,
+	resp *ApproveTokenResponse,
+) error {
+	tokenInfo, err := s.pb.ETHClient().ERC20Info(s.ctx, req.TokenAddr)
+	if err != nil {
+		return err
+	}
+
+	amount := coins.NewERC20TokenAmountFromDecimals(req.Amount, tokenInfo)
+	txHash, err := s.approveToken(req.TokenAddr, amount.BigInt())
+	if err != nil {
+		return err
+	}
+
+	allowance, err := s.tokenAllowance(req.TokenAddr)
+	if err != nil {
+		return err
+	}
+
+	resp.TxHash = txHash
+	resp.Allowance = allowance
+	return nil
+}
+
+// RevokeTokenAllowanceRequest ...
+type RevokeTokenAllowanceRequest struct {
+	TokenAddr ethcommon.Address `json:"tokenAddr" validate:"required"`
+}
+
+// RevokeTokenAllowanceResponse ...
+type RevokeTokenAllowanceResponse struct {
+	TxHash ethcommon.Hash `json:"txHash" validate:"required"`
+}
+
+// RevokeTokenAllowance sets the token allowance of the SwapCreator contract to zero.
+func (s *PersonalService) RevokeTokenAllowance(
+	_ *http.Request,
+	req *RevokeTokenAllowanceRequest,
+	resp *RevokeTokenAllowanceResponse,
+) error {
+	txHash, err := s.approveToken(req.TokenAddr, big.NewInt(0))
+	if err != nil {
+		return err
 	}
+
+	resp.TxHash = txHash
 	return nil
 }
+
+func (s *PersonalService) tokenAllowance(tokenAddr ethcommon.Address) (*coins.ERC20TokenAmount, error) {
+	ec := s.pb.ETHClient()
+
+	tokenInfo, err := ec.ERC20Info(s.ctx, tokenAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenContract, err := contracts.NewIERC20(tokenAddr, ec.Raw())
+	if err != nil {
+		return nil, err
+	}
+
+	allowance, err := tokenContract.Allowance(ec.CallOpts(s.ctx), ec.Address(), s.pb.SwapCreatorAddr())
+	if err != nil {
+		return nil, err
+	}
+
+	return coins.NewERC20TokenAmountFromBigInt(allowance, tokenInfo), nil
+}
+
+func (s *PersonalService) approveToken(tokenAddr ethcommon.Address, amount *big.Int) (ethcommon.Hash, error) {
+	ec := s.pb.ETHClient()
+	if !ec.HasSigner() {
+		return ethcommon.Hash{}, errNoEthSigner
+	}
+
+	tokenContract, err := contracts.NewIERC20(tokenAddr, ec.Raw())
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	// the wallet lock keeps the approval from racing with a swap's own approval
+	ec.Lock()
+	defer ec.Unlock()
+
+	err = contracts.CheckERC20Call(s.ctx, ec.Raw(), tokenAddr, ec.Address(), "approve", s.pb.SwapCreatorAddr(), amount)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	txOpts, err := ec.TxOpts(s.ctx)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	tx, err := tokenContract.Approve(txOpts, s.pb.SwapCreatorAddr(), amount)
+	if err != nil {
+		return ethcommon.Hash{}, fmt.Errorf("approve tx creation failed, %w", err)
+	}
+
+	receipt, err := ec.WaitForReceipt(s.ctx, tx.Hash())
+	if err != nil {
+		return ethcommon.Hash{}, fmt.Errorf("approve failed, %w", err)
+	}
+
+	metrics.GasSpent("approve", receipt)
+	return receipt.TxHash, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTokenInfoCache struct {
+	numTokens int
+}
+
+func (*mockTokenInfoCache) DeleteTokenInfo(_ *big.Int, _ ethcommon.Address) (bool, error) {
+	panic("not implemented")
+}
+
+func (c *mockTokenInfoCache) ClearTokenInfo() (int, error) {
+	removed := c.numTokens
+	c.numTokens = 0
+	return removed, nil
+}
+
+func TestUniqueTokenAddrs(t *testing.T) {
+	token1 := ethcommon.Address{1}
+	token2 := ethcommon.Address{2}
+
+	unique := uniqueTokenAddrs([]ethcommon.Address{token2, token1, token2, token1})
+	require.Equal(t, []ethcommon.Address{token2, token1}, unique)
+
+	require.Empty(t, uniqueTokenAddrs(nil))
+}
+
+func TestPersonalService_ClearTokenInfoCache(t *testing.T) {
+	cache := &mockTokenInfoCache{numTokens: 3}
+	s := NewPersonalService(context.Background(), nil, newMockProtocolBackend(), "", "", nil, cache)
+
+	resp := new(ClearTokenInfoCacheResponse)
+	require.NoError(t, s.ClearTokenInfoCache(nil, new(ClearTokenInfoCacheRequest), resp))
+	require.Equal(t, 3, resp.Removed)
+
+	s = NewPersonalService(context.Background(), nil, newMockProtocolBackend(), "", "", nil, nil)
+	err := s.ClearTokenInfoCache(nil, new(ClearTokenInfoCacheRequest), resp)
+	require.ErrorIs(t, err, errNoTokenInfoCache)
+}
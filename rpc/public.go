@@ -0,0 +1,58 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/rpc/v2"
+)
+
+var errNotPublicMethod = errors.New("method is not served on the public endpoint")
+
+// publicMethods are the only methods served on the public listener. They expose the
+// daemon's version and status, our offers and our past swaps, and none of them can
+// move funds or change the daemon's state.
+var publicMethods = map[string]struct{}{
+	"daemon_version": {},
+	"daemon_status":  {},
+	"swap_getOffers": {},
+	"swap_getPast":   {},
+}
+
+type publicRequestKey struct{}
+
+// publicOnly marks the requests as received on the public listener.
+func publicOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), publicRequestKey{}, true)))
+	})
+}
+
+func isPublicRequest(ctx context.Context) bool {
+	public, _ := ctx.Value(publicRequestKey{}).(bool)
+	return public
+}
+
+// checkPublicMethod returns an error if the method is not served on the public
+// listener.
+func checkPublicMethod(method string) error {
+	if _, ok := publicMethods[method]; !ok {
+		return fmt.Errorf("%w: %s", errNotPublicMethod, method)
+	}
+	return nil
+}
+
+// validateRequest is the JSON-RPC server's request validation function. Requests
+// on the public listener can only call the public methods, and the others can call
+// the methods that their auth token allows.
+func validateRequest(info *rpc.RequestInfo, args any) error {
+	if isPublicRequest(info.Request.Context()) {
+		return checkPublicMethod(jsonRPCMethodName(info.Method))
+	}
+	return validateMethodScope(info, args)
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/rpc/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequest_public(t *testing.T) {
+	var req *http.Request
+	publicOnly(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		req = r
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+
+	require.NoError(t, validateRequest(&rpc.RequestInfo{Method: "daemon.Status", Request: req}, nil))
+	require.NoError(t, validateRequest(&rpc.RequestInfo{Method: "swap.GetOffers", Request: req}, nil))
+	err := validateRequest(&rpc.RequestInfo{Method: "swap.GetOngoing", Request: req}, nil)
+	require.ErrorIs(t, err, errNotPublicMethod)
+	err = validateRequest(&rpc.RequestInfo{Method: "net.MakeOffer", Request: req}, nil)
+	require.ErrorIs(t, err, errNotPublicMethod)
+
+	// requests on the other listeners are only limited by their auth scope
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	require.NoError(t, validateRequest(&rpc.RequestInfo{Method: "swap.GetOngoing", Request: req}, nil))
+}
+
+func TestPublicMethods_areReadMethods(t *testing.T) {
+	for method := range publicMethods {
+		require.NoError(t, checkMethodScope(ScopeRead, method))
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// quoteValidity is the time that a quote is valid for. The gas price and XMR fee
+// estimates of a quote get stale after it.
+const quoteValidity = time.Minute
+
+// QuoteRequest ...
+type QuoteRequest struct {
+	PeerID         peer.ID      `json:"peerID" validate:"required"`
+	OfferID        types.Hash   `json:"offerID" validate:"required"`
+	ProvidesAmount *apd.Decimal `json:"providesAmount" validate:"required"` // eth asset amount
+}
+
+// QuoteResponse is what taking an offer with the provided amount costs, and the XMR
+// that we receive for it.
+type QuoteResponse struct {
+	EthAsset       types.EthAsset      `json:"ethAsset"`
+	ProvidedAmount *apd.Decimal        `json:"providedAmount" validate:"required"`
+	ExchangeRate   *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+	// ExpectedAmount is the XMR that the maker locks, and NetAmount is the XMR that we
+	// receive in our primary wallet, after the fee of sweeping the swap wallet.
+	ExpectedAmount *apd.Decimal   `json:"expectedAmount" validate:"required"`
+	XMRFee         *apd.Decimal   `json:"xmrFee" validate:"required"`
+	NetAmount      *apd.Decimal   `json:"netAmount" validate:"required"`
+	Transactions   []*EstimatedTx `json:"transactions" validate:"dive,required"`
+	GasCost        *apd.Decimal   `json:"gasCost" validate:"required"` // in ETH
+	ValidUntil     time.Time      `json:"validUntil" validate:"required"`
+}
+
+// Quote returns what taking the peer's offer with the provided amount costs, and the
+// XMR that we receive after the network fees. The maker pays the relayer fee of its
+// claim out of the ETH asset, so it doesn't change our amounts. The quote doesn't
+// reserve the offer, which the maker can remove or change at any time.
+func (s *NetService) Quote(_ *http.Request, req *QuoteRequest, resp *QuoteResponse) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	offer, err := s.queryOffer(req.PeerID, req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	err = offer.EthAsset.ValidateAmount("providesAmount", req.ProvidesAmount)
+	if err != nil {
+		return err
+	}
+
+	expectedAmount, err := offer.ExchangeRate.ToXMR(req.ProvidesAmount)
+	if err != nil {
+		return err
+	}
+
+	if expectedAmount.Cmp(offer.MinAmount) < 0 || expectedAmount.Cmp(offer.MaxAmount) > 0 {
+		return fmt.Errorf("%s XMR for the provided amount is outside of the offer's range of %s to %s XMR",
+			expectedAmount.Text('f'), offer.MinAmount.Text('f'), offer.MaxAmount.Text('f'))
+	}
+
+	ec := s.pb.ETHClient()
+	token, value, err := ethAssetValue(s.ctx, ec, offer.EthAsset, req.ProvidesAmount)
+	if err != nil {
+		return err
+	}
+
+	gasPrice, err := ec.SuggestGasPrice(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	txNames, err := takerTxNames(s.ctx, s.pb, token, value)
+	if err != nil {
+		return err
+	}
+
+	xmrFee, err := s.pb.XMRClient().EstimateTransferFee()
+	if err != nil {
+		return fmt.Errorf("failed to estimate the XMR transaction fee: %w", err)
+	}
+
+	netAmount := new(apd.Decimal)
+	if _, err = coins.DecimalCtx().Sub(netAmount, expectedAmount, xmrFee.AsMonero()); err != nil {
+		return err
+	}
+	if netAmount.Sign() <= 0 {
+		return fmt.Errorf("%s XMR for the provided amount doesn't cover the XMR fee of %s XMR",
+			expectedAmount.Text('f'), xmrFee.AsMoneroString())
+	}
+
+	resp.EthAsset = offer.EthAsset
+	resp.ProvidedAmount = req.ProvidesAmount
+	resp.ExchangeRate = offer.ExchangeRate
+	resp.ExpectedAmount = expectedAmount
+	resp.XMRFee = xmrFee.AsMonero()
+	resp.NetAmount = netAmount
+	resp.Transactions, resp.GasCost = estimateTxs(txNames, token != nil, gasPrice)
+	resp.ValidUntil = time.Now().Add(quoteValidity)
+	return nil
+}
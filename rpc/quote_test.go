@@ -0,0 +1,35 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestNet_Quote_errors(t *testing.T) {
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
+
+	req := &QuoteRequest{
+		PeerID:         testPeerID,
+		OfferID:        types.Hash{0xff},
+		ProvidesAmount: apd.New(1, 0),
+	}
+	err := ns.Quote(nil, req, new(QuoteResponse))
+	require.ErrorIs(t, err, errNoOfferWithID)
+
+	req.OfferID = testSwapID
+	req.ProvidesAmount = apd.New(-1, 0)
+	err = ns.Quote(nil, req, new(QuoteResponse))
+	require.ErrorContains(t, err, "providesAmount")
+
+	bootnode := NewNetService(context.Background(), new(mockNet), nil, nil, nil, nil, nil, true)
+	err = bootnode.Quote(nil, req, new(QuoteResponse))
+	require.ErrorIs(t, err, errUnsupportedForBootnode)
+}
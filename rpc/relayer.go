@@ -0,0 +1,156 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"math/big"
+	"net/http"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+)
+
+// RelayedClaimsDB contains the methods for retrieving the claims that we relayed
+// for other nodes.
+type RelayedClaimsDB interface {
+	GetAllRelayedClaims() ([]*db.RelayedClaim, error)
+}
+
+// RelayAccess contains the methods for managing the relay access list.
+type RelayAccess interface {
+	RelayAccessList() *types.RelayAccessList
+	SetRelayAccessList(list *types.RelayAccessList) error
+}
+
+// RelayerService handles the RPC methods for nodes relaying claims for others,
+// and for choosing the relayers of our own claims.
+type RelayerService struct {
+	relayedDB RelayedClaimsDB
+	access    RelayAccess
+}
+
+// NewRelayerService creates a new relayer service.
+func NewRelayerService(relayedDB RelayedClaimsDB, access RelayAccess) *RelayerService {
+	return &RelayerService{
+		relayedDB: relayedDB,
+		access:    access,
+	}
+}
+
+// earningsCounter sums the fees and gas costs of relayed claims.
+type earningsCounter struct {
+	claims    uint64
+	feesWei   *big.Int
+	tokenFees map[ethcommon.Address]*big.Int
+	gasWei    *big.Int
+}
+
+func newEarningsCounter() *earningsCounter {
+	return &earningsCounter{
+		feesWei: new(big.Int),
+		gasWei:  new(big.Int),
+	}
+}
+
+func (c *earningsCounter) add(claim *db.RelayedClaim) {
+	c.claims++
+	c.gasWei.Add(c.gasWei, claim.GasCostWei)
+
+	if claim.FeeAsset.IsETH() {
+		c.feesWei.Add(c.feesWei, claim.FeeWei)
+		return
+	}
+
+	if c.tokenFees == nil {
+		c.tokenFees = make(map[ethcommon.Address]*big.Int)
+	}
+	token := claim.FeeAsset.Address()
+	if _, ok := c.tokenFees[token]; !ok {
+		c.tokenFees[token] = new(big.Int)
+	}
+	c.tokenFees[token].Add(c.tokenFees[token], claim.FeeWei)
+}
+
+func (c *earningsCounter) earnings() rpctypes.RelayerEarnings {
+	return rpctypes.RelayerEarnings{
+		ClaimsRelayed:   c.claims,
+		FeesEarned:      coins.NewWeiAmount(c.feesWei).AsEther(),
+		TokenFeesEarned: c.tokenFees,
+		GasSpent:        coins.NewWeiAmount(c.gasWei).AsEther(),
+		NetProfit:       coins.NewWeiAmount(new(big.Int).Sub(c.feesWei, c.gasWei)).AsEther(),
+	}
+}
+
+// Stats returns the number of claims that we relayed, the fees we earned and the
+// gas we spent relaying them, in total and per day (UTC), oldest day first.
+func (s *RelayerService) Stats(
+	_ *http.Request,
+	_ *interface{},
+	resp *rpctypes.RelayerEarningsResponse,
+) error {
+	var claims []*db.RelayedClaim
+	if s.relayedDB != nil {
+		var err error
+		claims, err = s.relayedDB.GetAllRelayedClaims()
+		if err != nil {
+			return err
+		}
+	}
+
+	total := newEarningsCounter()
+	var (
+		dates []string
+		days  = make(map[string]*earningsCounter)
+	)
+
+	// claims are sorted by time, so the days are too
+	for _, claim := range claims {
+		total.add(claim)
+
+		date := claim.Time.UTC().Format("2006-01-02")
+		day, ok := days[date]
+		if !ok {
+			day = newEarningsCounter()
+			days[date] = day
+			dates = append(dates, date)
+		}
+		day.add(claim)
+	}
+
+	totalEarnings := total.earnings()
+	resp.Total = &totalEarnings
+	resp.Days = make([]*rpctypes.RelayerDailyEarnings, len(dates))
+	for i, date := range dates {
+		resp.Days[i] = &rpctypes.RelayerDailyEarnings{
+			Date:            date,
+			RelayerEarnings: days[date].earnings(),
+		}
+	}
+
+	return nil
+}
+
+// GetAccessList returns the relay access list.
+func (s *RelayerService) GetAccessList(
+	_ *http.Request,
+	_ *interface{},
+	resp *types.RelayAccessList,
+) error {
+	*resp = *s.access.RelayAccessList()
+	return nil
+}
+
+// SetAccessList replaces the relay access list. The list is saved if swapd was
+// started with a relay access list file.
+func (s *RelayerService) SetAccessList(
+	_ *http.Request,
+	req *types.RelayAccessList,
+	_ *interface{},
+) error {
+	return s.access.SetRelayAccessList(req)
+}
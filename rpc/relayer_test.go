@@ -0,0 +1,68 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+)
+
+type mockRelayedClaimsDB struct {
+	claims []*db.RelayedClaim
+}
+
+func (m *mockRelayedClaimsDB) GetAllRelayedClaims() ([]*db.RelayedClaim, error) {
+	return m.claims, nil
+}
+
+func TestRelayer_Stats(t *testing.T) {
+	day1 := time.Date(2023, 5, 1, 23, 0, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Hour)
+	token := ethcommon.Address{0x1}
+
+	relayedDB := &mockRelayedClaimsDB{
+		claims: []*db.RelayedClaim{
+			{FeeWei: big.NewInt(1e15), GasCostWei: big.NewInt(2e15), Time: day1},
+			{FeeWei: big.NewInt(9e15), GasCostWei: big.NewInt(1e15), Time: day2},
+			{FeeWei: big.NewInt(3e15), GasCostWei: big.NewInt(1e15), Time: day2},
+			{FeeAsset: types.EthAsset(token), FeeWei: big.NewInt(5e6), GasCostWei: big.NewInt(1e15), Time: day2},
+		},
+	}
+
+	resp := new(rpctypes.RelayerEarningsResponse)
+	err := NewRelayerService(relayedDB, nil).Stats(nil, nil, resp)
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(4), resp.Total.ClaimsRelayed)
+	require.Equal(t, "0.013", resp.Total.FeesEarned.Text('f'))
+	require.Equal(t, big.NewInt(5e6), resp.Total.TokenFeesEarned[token])
+	require.Equal(t, "0.005", resp.Total.GasSpent.Text('f'))
+	require.Equal(t, "0.008", resp.Total.NetProfit.Text('f'))
+
+	require.Len(t, resp.Days, 2)
+	require.Equal(t, "2023-05-01", resp.Days[0].Date)
+	require.Equal(t, uint64(1), resp.Days[0].ClaimsRelayed)
+	require.Equal(t, "-0.001", resp.Days[0].NetProfit.Text('f'))
+	require.Equal(t, "2023-05-02", resp.Days[1].Date)
+	require.Equal(t, uint64(3), resp.Days[1].ClaimsRelayed)
+	require.Equal(t, "0.009", resp.Days[1].NetProfit.Text('f'))
+	require.Empty(t, resp.Days[0].TokenFeesEarned)
+}
+
+func TestRelayer_Stats_noDB(t *testing.T) {
+	resp := new(rpctypes.RelayerEarningsResponse)
+	err := NewRelayerService(nil, nil).Stats(nil, nil, resp)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), resp.Total.ClaimsRelayed)
+	require.Equal(t, "0", resp.Total.NetProfit.Text('f'))
+	require.Empty(t, resp.Days)
+}
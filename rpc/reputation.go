@@ -0,0 +1,90 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// PeerReputation returns the outcomes of our past swaps with the peer.
+func (s *NetService) PeerReputation(
+	_ *http.Request,
+	req *rpctypes.PeerReputationRequest,
+	resp *rpctypes.PeerReputationResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	reputations, err := s.peerReputations()
+	if err != nil {
+		return err
+	}
+
+	if rep, ok := reputations[req.PeerID]; ok {
+		*resp = *rep
+	}
+	return nil
+}
+
+// peerReputations returns the reputations of the peers that we swapped with,
+// computed from our past swaps.
+func (s *NetService) peerReputations() (map[peer.ID]*rpctypes.PeerReputation, error) {
+	ids, err := s.sm.GetPastIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	reputations := make(map[peer.ID]*rpctypes.PeerReputation)
+	for _, id := range ids {
+		info, err := s.sm.GetPastSwap(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get past swap %s: %w", id, err)
+		}
+
+		rep, ok := reputations[info.PeerID]
+		if !ok {
+			rep = new(rpctypes.PeerReputation)
+			reputations[info.PeerID] = rep
+		}
+		addSwapOutcome(rep, info)
+	}
+
+	return reputations, nil
+}
+
+// addSwapOutcome counts the outcome of the past swap in the reputation of its
+// counterparty. Aborted swaps are not counted, as no funds were locked.
+func addSwapOutcome(rep *rpctypes.PeerReputation, info *swap.Info) {
+	switch info.Status {
+	case types.CompletedSuccess:
+		rep.Completed++
+	case types.CompletedRefund:
+		// As the taker, we lock our ETH first, and only set the contract to ready
+		// once the maker locked their XMR. Swaps from before stages were recorded
+		// can't be told apart, so they count as refunds.
+		if info.Provides == coins.ProvidesETH && len(info.Stages) > 0 && !hasStage(info, types.ContractReady) {
+			rep.AbortedAfterLock++
+		} else {
+			rep.Refunded++
+		}
+	}
+}
+
+func hasStage(info *swap.Info, status types.Status) bool {
+	for _, stage := range info.Stages {
+		if stage.Status == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func newTestTakerSwap(id types.Hash, peerID peer.ID, stages ...types.Status) *swap.Info {
+	one := apd.New(1, 0)
+	info := swap.NewInfo(peerID, id, coins.ProvidesETH, one, one, coins.ToExchangeRate(one),
+		types.EthAssetETH, types.ExpectingKeys, 1, nil)
+	for _, status := range stages {
+		info.Stages = append(info.Stages, &swap.Stage{Status: status, EnteredAt: time.Now()})
+		info.Status = status
+	}
+	return info
+}
+
+func TestNet_PeerReputation(t *testing.T) {
+	other := peer.ID("other")
+	past := map[types.Hash]*swap.Info{}
+	for _, info := range []*swap.Info{
+		newTestTakerSwap(types.Hash{1}, testPeerID, types.ETHLocked, types.ContractReady, types.CompletedSuccess),
+		newTestTakerSwap(types.Hash{2}, testPeerID, types.ETHLocked, types.ContractReady, types.CompletedSuccess),
+		// the maker never locked their XMR
+		newTestTakerSwap(types.Hash{3}, testPeerID, types.ETHLocked, types.CompletedRefund),
+		// the maker never claimed the ETH
+		newTestTakerSwap(types.Hash{4}, testPeerID, types.ETHLocked, types.ContractReady, types.CompletedRefund),
+		// no funds were locked
+		newTestTakerSwap(types.Hash{5}, testPeerID, types.CompletedAbort),
+		newTestTakerSwap(types.Hash{6}, other, types.ETHLocked, types.ContractReady, types.CompletedSuccess),
+	} {
+		past[info.OfferID] = info
+	}
+
+	sm := &mockPastSwapManager{past: past}
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, sm, nil, nil, false)
+
+	resp := new(rpctypes.PeerReputationResponse)
+	err := ns.PeerReputation(nil, &rpctypes.PeerReputationRequest{PeerID: testPeerID}, resp)
+	require.NoError(t, err)
+	require.Equal(t, &rpctypes.PeerReputation{Completed: 2, AbortedAfterLock: 1, Refunded: 1}, resp)
+
+	// peers without past swaps have no track record
+	resp = new(rpctypes.PeerReputationResponse)
+	err = ns.PeerReputation(nil, &rpctypes.PeerReputationRequest{PeerID: peer.ID("unknown")}, resp)
+	require.NoError(t, err)
+	require.Equal(t, &rpctypes.PeerReputation{}, resp)
+}
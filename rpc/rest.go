@@ -0,0 +1,178 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/gorilla/mux"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+// maxRESTRequestSize is the maximum size of the body of a REST request.
+const maxRESTRequestSize = 1 << 20
+
+// RESTSwapResponse is the response of GET /swaps/{id}. Only one of the fields is
+// set, depending on whether the swap is ongoing.
+type RESTSwapResponse struct {
+	Ongoing *OngoingSwap `json:"ongoing,omitempty"`
+	Past    *PastSwap    `json:"past,omitempty"`
+}
+
+// RESTTakeOfferRequest is the body of POST /swaps/{offerID}/take.
+type RESTTakeOfferRequest struct {
+	PeerID         peer.ID      `json:"peerID" validate:"required"`
+	ProvidesAmount *apd.Decimal `json:"providesAmount" validate:"required"` // eth asset amount
+	Profile        string       `json:"profile,omitempty"`                  // funds the swap, the default if empty
+}
+
+// RESTTakeOfferResponse is the response of POST /swaps/{offerID}/take. The ID is
+// the ID of the swap, whose progress is returned by GET /swaps/{id}.
+type RESTTakeOfferResponse struct {
+	ID types.Hash `json:"id" validate:"required"`
+}
+
+// restErrorResponse is the body of the responses of failed REST requests.
+type restErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// restGateway maps the REST routes onto the methods of the JSON-RPC services, for
+// scripts and webhooks that don't speak JSON-RPC.
+type restGateway struct {
+	swap *SwapService
+	net  *NetService
+}
+
+// register adds the routes of the enabled services to the router. Reading requires
+// the read scope, and making and taking offers the personal scope.
+func (g *restGateway) register(r *mux.Router, auth *authenticator) {
+	route := func(path string, method string, scope AuthScope, handler http.HandlerFunc) {
+		r.Handle(path, auth.middleware(requireScope(scope, handler))).Methods(method)
+	}
+
+	if g.swap != nil {
+		route("/offers", http.MethodGet, ScopeRead, g.getOffers)
+		route("/swaps/{id}", http.MethodGet, ScopeRead, g.getSwap)
+	}
+
+	if g.net != nil {
+		route("/offers", http.MethodPost, ScopePersonal, g.makeOffer)
+		route("/swaps/{offerID}/take", http.MethodPost, ScopePersonal, g.takeOffer)
+	}
+}
+
+func (g *restGateway) getOffers(w http.ResponseWriter, r *http.Request) {
+	resp := new(GetOffersResponse)
+	if err := g.swap.GetOffers(r, nil, resp); err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	writeRESTResponse(w, http.StatusOK, resp)
+}
+
+func (g *restGateway) getSwap(w http.ResponseWriter, r *http.Request) {
+	id, err := types.HexToHash(mux.Vars(r)["id"])
+	if err != nil {
+		writeRESTResponse(w, http.StatusBadRequest, &restErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ongoing := new(GetOngoingResponse)
+	if err = g.swap.GetOngoing(r, &GetOngoingRequest{OfferID: &id}, ongoing); err == nil {
+		writeRESTResponse(w, http.StatusOK, &RESTSwapResponse{Ongoing: ongoing.Swaps[0]})
+		return
+	}
+
+	past := new(GetPastResponse)
+	if err = g.swap.GetPast(r, &GetPastRequest{OfferID: &id}, past); err != nil {
+		writeRESTResponse(w, http.StatusNotFound, &restErrorResponse{Error: "no swap with ID " + id.String()})
+		return
+	}
+
+	writeRESTResponse(w, http.StatusOK, &RESTSwapResponse{Past: past.Swaps[0]})
+}
+
+func (g *restGateway) makeOffer(w http.ResponseWriter, r *http.Request) {
+	req := new(rpctypes.MakeOfferRequest)
+	if !readRESTRequest(w, r, req) {
+		return
+	}
+
+	resp := new(rpctypes.MakeOfferResponse)
+	if err := g.net.MakeOffer(r, req, resp); err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	writeRESTResponse(w, http.StatusCreated, resp)
+}
+
+func (g *restGateway) takeOffer(w http.ResponseWriter, r *http.Request) {
+	offerID, err := types.HexToHash(mux.Vars(r)["offerID"])
+	if err != nil {
+		writeRESTResponse(w, http.StatusBadRequest, &restErrorResponse{Error: err.Error()})
+		return
+	}
+
+	req := new(RESTTakeOfferRequest)
+	if !readRESTRequest(w, r, req) {
+		return
+	}
+
+	err = g.net.TakeOffer(r, &rpctypes.TakeOfferRequest{
+		PeerID:         req.PeerID,
+		OfferID:        offerID,
+		ProvidesAmount: req.ProvidesAmount,
+		Profile:        req.Profile,
+	}, nil)
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+
+	// The swap is ongoing, so it is accepted but not completed
+	writeRESTResponse(w, http.StatusAccepted, &RESTTakeOfferResponse{ID: offerID})
+}
+
+// readRESTRequest reads and validates the JSON body of the request. It writes the
+// error response and returns false if the body is invalid.
+func readRESTRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRESTRequestSize))
+	if err == nil {
+		err = vjson.UnmarshalStruct(data, req)
+	}
+	if err != nil {
+		writeRESTResponse(w, http.StatusBadRequest, &restErrorResponse{Error: "invalid request: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeRESTError writes the error response of a failed service method.
+func writeRESTError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, errNoOfferWithID):
+		status = http.StatusNotFound
+	case errors.Is(err, errUnsupportedForBootnode):
+		status = http.StatusNotImplemented
+	}
+	writeRESTResponse(w, status, &restErrorResponse{Error: err.Error()})
+}
+
+func writeRESTResponse(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Warnf("failed to write REST response: %s", err)
+	}
+}
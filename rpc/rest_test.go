@@ -0,0 +1,59 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRESTRouter() *mux.Router {
+	ns := NewNetService(context.Background(), new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), nil, nil, false)
+	r := mux.NewRouter()
+	auth := newAuthenticator([]*AuthToken{
+		{Token: "reader", Scope: ScopeRead},
+		{Token: "personal", Scope: ScopePersonal},
+	})
+	(&restGateway{net: ns}).register(r, auth)
+	return r
+}
+
+func TestRESTGateway_takeOffer(t *testing.T) {
+	r := newTestRESTRouter()
+
+	post := func(path string, token string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	path := "/swaps/" + testSwapID.Hex() + "/take"
+	body := `{"peerID":"12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5","providesAmount":"1"}`
+
+	rec := post(path, "personal", body)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	resp := new(RESTTakeOfferResponse)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+	require.Equal(t, testSwapID, resp.ID)
+
+	// taking offers requires the personal scope
+	rec = post(path, "reader", body)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = post("/swaps/0x1234/take", "personal", body)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = post(path, "personal", `{"peerID":"12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "invalid request")
+}
@@ -8,6 +8,7 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -17,7 +18,6 @@ import (
 	"github.com/MarinX/monerorpc/wallet"
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/rpc/v2"
 	logging "github.com/ipfs/go-log"
@@ -28,8 +28,12 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/metrics"
+	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
 const (
@@ -37,29 +41,74 @@ const (
 	DatabaseNamespace = "database" //nolint:revive
 	NetNamespace      = "net"      //nolint:revive
 	PersonalName      = "personal" //nolint:revive
+	RelayerNamespace  = "relayer"  //nolint:revive
 	SwapNamespace     = "swap"     //nolint:revive
+	WebhookNamespace  = "webhook"  //nolint:revive
 )
 
 var log = logging.Logger("rpc")
 
 // Server represents the JSON-RPC server
 type Server struct {
-	ctx        context.Context
-	listener   net.Listener
-	httpServer *http.Server
+	ctx          context.Context
+	listener     net.Listener // nil if the server only serves on the unix socket
+	unixListener net.Listener // nil if the server doesn't serve on a unix socket
+	httpServer   *http.Server
+	tls          bool
+
+	// publicListener and publicServer serve the public methods, they are nil if
+	// no public address is configured
+	publicListener net.Listener
+	publicServer   *http.Server
 }
 
 // Config ...
 type Config struct {
 	Ctx             context.Context
-	Address         string // "IP:port"
+	Address         string // "IP:port", or empty to only serve on UnixSocket
+	PublicAddress   string // optional "IP:port" serving only the public, read-only methods
 	Net             Net
 	XMRTaker        XMRTaker
 	XMRMaker        XMRMaker
 	ProtocolBackend ProtocolBackend
 	RecoveryDB      RecoveryDB
+	ContractEvents  ContractEventsDB
+	RelayerStats    RelayerStatsDB
+	RelayedClaims   RelayedClaimsDB
+	TokenInfoCache  TokenInfoCacheDB
+	RelayAccess     RelayAccess
+	Webhooks        Webhooks // optional, the webhook namespace is not served if nil
 	Namespaces      map[string]struct{}
 	IsBootnodeOnly  bool
+
+	// EthKeyFile is the file that swapd loaded its ethereum key from. It is empty
+	// when the key is held by a hardware wallet or an external signer.
+	EthKeyFile          string
+	EthKeystorePassword string
+
+	TokenRegistry *coins.TokenRegistry
+
+	// PriceOracle is the source of the prices of swap_suggestedExchangeRate,
+	// chainlink's price feeds if nil.
+	PriceOracle coins.PriceOracle
+
+	// TLSCertFile and TLSKeyFile, if set, are the certificate and key with which
+	// the server serves HTTPS and WSS instead of cleartext.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// UnixSocket, if set, is the path of a unix socket on which the server also
+	// serves, without TLS. Only the user running swapd can connect to it.
+	UnixSocket string
+
+	// CORS is the policy deciding which web frontends can call the server from a
+	// browser, DefaultCORSConfig() if nil.
+	CORS *CORSConfig
+
+	// AuthTokens are the bearer tokens that requests must present, with the
+	// scopes of the methods that they may call. Requests are not authenticated
+	// when it is empty.
+	AuthTokens []*AuthToken
 }
 
 // AllNamespaces returns a map with all RPC namespaces set for usage in the config.
@@ -69,7 +118,9 @@ func AllNamespaces() map[string]struct{} {
 		DatabaseNamespace: {},
 		NetNamespace:      {},
 		PersonalName:      {},
+		RelayerNamespace:  {},
 		SwapNamespace:     {},
+		WebhookNamespace:  {},
 	}
 }
 
@@ -77,9 +128,19 @@ func AllNamespaces() map[string]struct{} {
 func NewServer(cfg *Config) (*Server, error) {
 	rpcServer := rpc.NewServer()
 	rpcServer.RegisterCodec(NewCodec(), "application/json")
+	rpcServer.RegisterValidateRequestFunc(validateRequest)
 
 	serverCtx, serverCancel := context.WithCancel(cfg.Ctx)
-	err := rpcServer.RegisterService(NewDaemonService(serverCancel, cfg.ProtocolBackend), "daemon")
+	// The registered services are kept to describe their methods in the OpenRPC
+	// document
+	var services []*openRPCService
+	registerService := func(receiver any, name string) error {
+		services = append(services, &openRPCService{name: name, receiver: receiver})
+		return rpcServer.RegisterService(receiver, name)
+	}
+
+	daemonService := NewDaemonService(serverCtx, serverCancel, cfg.ProtocolBackend, cfg.Net, cfg.XMRMaker)
+	err := registerService(daemonService, "daemon")
 	if err != nil {
 		return nil, err
 	}
@@ -89,30 +150,60 @@ func NewServer(cfg *Config) (*Server, error) {
 		swapManager = cfg.ProtocolBackend.SwapManager()
 	}
 
-	var netService *NetService
+	var (
+		netService  *NetService
+		swapService *SwapService
+	)
 	for ns := range cfg.Namespaces {
 		switch ns {
 		case DaemonNamespace:
 			continue
 		case DatabaseNamespace:
-			err = rpcServer.RegisterService(NewDatabaseService(cfg.RecoveryDB), DatabaseNamespace)
+			err = registerService(NewDatabaseService(cfg.RecoveryDB), DatabaseNamespace)
 		case NetNamespace:
-			netService = NewNetService(cfg.Net, cfg.XMRTaker, cfg.XMRMaker, swapManager, cfg.IsBootnodeOnly)
-			err = rpcServer.RegisterService(netService, NetNamespace)
+			netService = NewNetService(
+				serverCtx,
+				cfg.Net,
+				cfg.XMRTaker,
+				cfg.XMRMaker,
+				swapManager,
+				cfg.ProtocolBackend,
+				cfg.RelayerStats,
+				cfg.IsBootnodeOnly,
+			)
+			err = registerService(netService, NetNamespace)
 		case PersonalName:
-			err = rpcServer.RegisterService(NewPersonalService(serverCtx, cfg.XMRMaker, cfg.ProtocolBackend), PersonalName)
-		case SwapNamespace:
-			err = rpcServer.RegisterService(
-				NewSwapService(
+			err = registerService(
+				NewPersonalService(
 					serverCtx,
-					swapManager,
-					cfg.XMRTaker,
 					cfg.XMRMaker,
-					cfg.Net,
 					cfg.ProtocolBackend,
+					cfg.EthKeyFile,
+					cfg.EthKeystorePassword,
+					cfg.TokenRegistry,
+					cfg.TokenInfoCache,
 				),
-				SwapNamespace,
+				PersonalName,
 			)
+		case RelayerNamespace:
+			err = registerService(NewRelayerService(cfg.RelayedClaims, cfg.RelayAccess), RelayerNamespace)
+		case SwapNamespace:
+			swapService = NewSwapService(
+				serverCtx,
+				swapManager,
+				cfg.XMRTaker,
+				cfg.XMRMaker,
+				cfg.Net,
+				cfg.ProtocolBackend,
+				cfg.ContractEvents,
+				cfg.PriceOracle,
+			)
+			err = registerService(swapService, SwapNamespace)
+		case WebhookNamespace:
+			if cfg.Webhooks == nil {
+				continue
+			}
+			err = registerService(NewWebhookService(cfg.Webhooks), WebhookNamespace)
 		default:
 			err = fmt.Errorf("unknown namespace %s", ns)
 		}
@@ -122,45 +213,143 @@ func NewServer(cfg *Config) (*Server, error) {
 		return nil, err
 	}
 
-	wsServer := newWsServer(serverCtx, swapManager, netService, cfg.ProtocolBackend, cfg.XMRTaker)
+	cors := cfg.CORS
+	if cors == nil {
+		cors = DefaultCORSConfig()
+	}
 
-	lc := net.ListenConfig{}
-	ln, err := lc.Listen(serverCtx, "tcp", cfg.Address)
-	if err != nil {
+	wsServer := newWsServer(serverCtx, swapManager, netService, cfg.ProtocolBackend, cfg.XMRTaker, cors)
+
+	if cfg.Address == "" && cfg.UnixSocket == "" {
 		serverCancel()
-		return nil, err
+		return nil, errors.New("RPC server needs an address or a unix socket to listen on")
+	}
+
+	var ln net.Listener
+	if cfg.Address != "" {
+		ln, err = listenTCP(serverCtx, cfg.Address, cfg)
+		if err != nil {
+			serverCancel()
+			return nil, err
+		}
 	}
 
+	var unixLn net.Listener
+	if cfg.UnixSocket != "" {
+		unixLn, err = listenUnix(serverCtx, cfg.UnixSocket)
+		if err != nil {
+			if ln != nil {
+				_ = ln.Close()
+			}
+			serverCancel()
+			return nil, err
+		}
+	}
+
+	// The health probes and the OpenRPC document are served without authentication
+	openRPC := openRPCHandler(newOpenRPCDocument(services))
+	auth := newAuthenticator(cfg.AuthTokens)
 	r := mux.NewRouter()
-	r.Handle("/", rpcServer)
-	r.Handle("/ws", wsServer)
+	r.Handle("/", auth.middleware(rpcServer))
+	r.Handle("/ws", auth.middleware(wsServer))
+	r.Handle("/metrics", auth.middleware(requireScope(ScopeRead, metrics.Handler())))
+	r.HandleFunc("/healthz", healthzHandler)
+	r.HandleFunc("/readyz", readyzHandler(daemonService))
+	r.HandleFunc(OpenRPCPath, openRPC)
+	rest := &restGateway{swap: swapService, net: netService}
+	rest.register(r, auth)
 
-	headersOk := handlers.AllowedHeaders([]string{"content-type", "username", "password"})
-	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
-	originsOk := handlers.AllowedOrigins([]string{"*"})
 	server := &http.Server{
-		Addr:              ln.Addr().String(),
 		ReadHeaderTimeout: time.Second,
-		Handler:           handlers.CORS(headersOk, methodsOk, originsOk)(r),
+		Handler:           cors.handler(r),
 		BaseContext: func(listener net.Listener) context.Context {
 			return serverCtx
 		},
 	}
 
-	return &Server{
-		ctx:        serverCtx,
-		listener:   ln,
-		httpServer: server,
-	}, nil
+	if ln != nil {
+		server.Addr = ln.Addr().String()
+	}
+
+	s := &Server{
+		ctx:          serverCtx,
+		listener:     ln,
+		unixListener: unixLn,
+		httpServer:   server,
+		tls:          cfg.TLSCertFile != "",
+	}
+
+	if cfg.PublicAddress != "" {
+		s.publicListener, err = listenTCP(serverCtx, cfg.PublicAddress, cfg)
+		if err != nil {
+			s.closeListeners()
+			serverCancel()
+			return nil, err
+		}
+
+		// The public listener is not authenticated, as it only serves the public
+		// methods, and it doesn't serve websockets or metrics
+		pr := mux.NewRouter()
+		pr.Handle("/", publicOnly(rpcServer))
+		pr.HandleFunc("/healthz", healthzHandler)
+		pr.HandleFunc("/readyz", readyzHandler(daemonService))
+
+		s.publicServer = &http.Server{
+			Addr:              s.publicListener.Addr().String(),
+			ReadHeaderTimeout: time.Second,
+			Handler:           cors.handler(pr),
+			BaseContext: func(listener net.Listener) context.Context {
+				return serverCtx
+			},
+		}
+	}
+
+	return s, nil
+}
+
+// closeListeners closes the listeners of a server that failed to be created.
+func (s *Server) closeListeners() {
+	for _, ln := range []net.Listener{s.listener, s.unixListener, s.publicListener} {
+		if ln != nil {
+			_ = ln.Close()
+		}
+	}
+}
+
+// listenTCP listens on the address, with TLS if a certificate is configured.
+func listenTCP(ctx context.Context, address string, cfg *Config) (net.Listener, error) {
+	lc := net.ListenConfig{}
+	ln, err := lc.Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.TLSCertFile == "" {
+		return ln, nil
+	}
+
+	tlsConfig, err := serverTLSConfig(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+
+	return tls.NewListener(ln, tlsConfig), nil
 }
 
-// HttpURL returns the URL used for HTTP requests
+// HttpURL returns the URL used for HTTP requests over TCP
 func (s *Server) HttpURL() string { //nolint:revive
+	if s.tls {
+		return fmt.Sprintf("https://%s", s.httpServer.Addr)
+	}
 	return fmt.Sprintf("http://%s", s.httpServer.Addr)
 }
 
-// WsURL returns the URL used for websocket requests
+// WsURL returns the URL used for websocket requests over TCP
 func (s *Server) WsURL() string {
+	if s.tls {
+		return fmt.Sprintf("wss://%s/ws", s.httpServer.Addr)
+	}
 	return fmt.Sprintf("ws://%s/ws", s.httpServer.Addr)
 }
 
@@ -170,21 +359,36 @@ func (s *Server) Start() error {
 		return s.ctx.Err()
 	}
 
-	log.Infof("Starting RPC server on %s", s.HttpURL())
-	log.Infof("Starting websockets server on %s", s.WsURL())
+	var listeners []net.Listener
+	if s.listener != nil {
+		log.Infof("Starting RPC server on %s", s.HttpURL())
+		log.Infof("Starting websockets server on %s", s.WsURL())
+		listeners = append(listeners, s.listener)
+	}
+	if s.unixListener != nil {
+		log.Infof("Starting RPC and websockets server on unix socket %s", s.unixListener.Addr())
+		listeners = append(listeners, s.unixListener)
+	}
 
-	serverErr := make(chan error, 1)
-	go func() {
-		// Serve never returns nil. It returns http.ErrServerClosed if it was terminated
-		// by the Shutdown.
-		serverErr <- s.httpServer.Serve(s.listener)
-	}()
+	serverErr := make(chan error, len(listeners)+1)
+	serve := func(server *http.Server, ln net.Listener) {
+		// Serve never returns nil. It returns http.ErrServerClosed if it was
+		// terminated by the Shutdown.
+		serverErr <- server.Serve(ln)
+	}
+	for _, ln := range listeners {
+		go serve(s.httpServer, ln)
+	}
+	if s.publicServer != nil {
+		log.Infof("Starting public read-only RPC server on %s", s.publicListener.Addr())
+		go serve(s.publicServer, s.publicListener)
+	}
 
 	select {
 	case <-s.ctx.Done():
 		// Shutdown below is passed a closed context, which means it will shut down
 		// immediately without servicing already connected clients.
-		err := s.httpServer.Shutdown(s.ctx)
+		err := s.Stop()
 		if err != nil && !errors.Is(err, context.Canceled) {
 			log.Warnf("http server shutdown errored: %s", err)
 		}
@@ -193,6 +397,11 @@ func (s *Server) Start() error {
 	case err := <-serverErr:
 		if !errors.Is(err, http.ErrServerClosed) {
 			log.Errorf("RPC server failed: %s", err)
+			// stop serving on the other listeners too
+			_ = s.httpServer.Close()
+			if s.publicServer != nil {
+				_ = s.publicServer.Close()
+			}
 		} else {
 			log.Info("RPC server shut down")
 		}
@@ -204,6 +413,12 @@ func (s *Server) Start() error {
 // graceful shutdown happens where existing connections are serviced until disconnected.
 // If the context is cancelled, the shutdown is immediate.
 func (s *Server) Stop() error {
+	if s.publicServer != nil {
+		if err := s.publicServer.Shutdown(s.ctx); err != nil {
+			_ = s.httpServer.Shutdown(s.ctx)
+			return err
+		}
+	}
 	return s.httpServer.Shutdown(s.ctx)
 }
 
@@ -218,27 +433,40 @@ type ProtocolBackend interface {
 	Env() common.Environment
 	SetSwapTimeout(timeout time.Duration)
 	SwapTimeout() time.Duration
+	SetPaused(paused bool)
+	Paused() bool
 	SwapManager() swap.Manager
 	SwapCreatorAddr() ethcommon.Address
 	SetXMRDepositAddress(*mcrypto.Address, types.Hash)
 	ClearXMRDepositAddress(types.Hash)
+	SetXMRPriority(wallet.Priority, types.Hash)
 	ETHClient() extethclient.EthClient
+	XMRClient() monero.WalletClient
+	RelayerFee() *relayer.FeeConfig
+	WithProfile(name string) (backend.Backend, error)
+	ProfileNames() []string
 }
 
 // XMRTaker ...
 type XMRTaker interface {
 	Protocol
-	InitiateProtocol(peerID peer.ID, providesAmount *apd.Decimal, offer *types.Offer) (common.SwapState, error)
+	InitiateProtocol(
+		peerID peer.ID,
+		providesAmount *apd.Decimal,
+		offer *types.Offer,
+		profile string,
+	) (common.SwapState, error)
 	ExternalSender(offerID types.Hash) (*txsender.ExternalSender, error)
 }
 
 // XMRMaker ...
 type XMRMaker interface {
 	Protocol
-	MakeOffer(offer *types.Offer, useRelayer bool) (*types.OfferExtra, error)
+	MakeOffer(offer *types.Offer, useRelayer bool, profile string) (*types.OfferExtra, error)
+	PeggedExchangeRate(peg *types.OfferPeg) (*coins.ExchangeRate, error)
 	GetOffers() []*types.Offer
 	ClearOffers([]types.Hash) error
-	GetMoneroBalance() (*mcrypto.Address, *wallet.GetBalanceResponse, error)
+	GetMoneroBalance(profile string) (*mcrypto.Address, *wallet.GetBalanceResponse, error)
 }
 
 // SwapManager ...
@@ -0,0 +1,51 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/net"
+)
+
+func newBandwidthStats(bw net.BandwidthStats) rpctypes.BandwidthStats {
+	return rpctypes.BandwidthStats{
+		BytesIn:  bw.BytesIn,
+		BytesOut: bw.BytesOut,
+		RateIn:   bw.RateIn,
+		RateOut:  bw.RateOut,
+	}
+}
+
+// Stats returns the traffic with peers and the number of messages that they sent
+// us, by protocol and by peer. Peers are sorted by the bytes that they sent us,
+// highest first, to spot peers abusing the offer query or relay protocols.
+func (s *NetService) Stats(_ *http.Request, _ *interface{}, resp *rpctypes.NetStatsResponse) error {
+	stats := s.net.Stats()
+
+	resp.Total = newBandwidthStats(stats.Total)
+
+	resp.Protocols = make([]*rpctypes.ProtocolStats, 0, len(stats.Protocols))
+	for _, ps := range stats.Protocols {
+		resp.Protocols = append(resp.Protocols, &rpctypes.ProtocolStats{
+			Protocol:        ps.Protocol,
+			Bandwidth:       newBandwidthStats(ps.Bandwidth),
+			Messages:        ps.Messages,
+			InvalidMessages: ps.InvalidMessages,
+		})
+	}
+
+	resp.Peers = make([]*rpctypes.PeerStats, 0, len(stats.Peers))
+	for _, ps := range stats.Peers {
+		resp.Peers = append(resp.Peers, &rpctypes.PeerStats{
+			PeerID:          ps.PeerID,
+			Bandwidth:       newBandwidthStats(ps.Bandwidth),
+			Messages:        ps.Messages,
+			InvalidMessages: ps.InvalidMessages,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/net"
+)
+
+func TestNet_Stats(t *testing.T) {
+	mn := &mockNet{
+		stats: &net.Stats{
+			Total: net.BandwidthStats{BytesIn: 300, BytesOut: 200},
+			Protocols: []*net.ProtocolStats{{
+				Protocol:        "/query/0",
+				Bandwidth:       net.BandwidthStats{BytesIn: 300, BytesOut: 200},
+				Messages:        3,
+				InvalidMessages: 1,
+			}},
+			Peers: []*net.PeerStats{{
+				PeerID:          testPeerID,
+				Bandwidth:       net.BandwidthStats{BytesIn: 300, BytesOut: 200, RateIn: 1.5},
+				Messages:        map[string]uint64{"/query/0": 3},
+				InvalidMessages: 1,
+			}},
+		},
+	}
+	ns := NewNetService(context.Background(), mn, new(mockXMRTaker), nil, nil, nil, nil, false)
+
+	resp := new(rpctypes.NetStatsResponse)
+	require.NoError(t, ns.Stats(nil, nil, resp))
+	require.Equal(t, int64(300), resp.Total.BytesIn)
+	require.Equal(t, int64(200), resp.Total.BytesOut)
+	require.Len(t, resp.Protocols, 1)
+	require.Equal(t, "/query/0", resp.Protocols[0].Protocol)
+	require.Equal(t, uint64(3), resp.Protocols[0].Messages)
+	require.Equal(t, uint64(1), resp.Protocols[0].InvalidMessages)
+	require.Len(t, resp.Peers, 1)
+	require.Equal(t, testPeerID, resp.Peers[0].PeerID)
+	require.Equal(t, 1.5, resp.Peers[0].Bandwidth.RateIn)
+	require.Equal(t, map[string]uint64{"/query/0": 3}, resp.Peers[0].Messages)
+}
@@ -11,15 +11,24 @@ import (
 	"time"
 
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/pricefeed"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
+// ContractEventsDB contains the methods for retrieving indexed swap contract events
+// from the database.
+type ContractEventsDB interface {
+	GetContractEvents(contractAddr ethcommon.Address, swapID *types.Hash) ([]*db.ContractEvent, error)
+}
+
 // SwapService handles information about ongoing or past swaps.
 type SwapService struct {
 	ctx      context.Context
@@ -28,6 +37,10 @@ type SwapService struct {
 	xmrmaker XMRMaker
 	net      Net
 	backend  ProtocolBackend
+	events   ContractEventsDB
+
+	// priceOracle is the source of the suggested exchange rate, chainlink if nil
+	priceOracle coins.PriceOracle
 }
 
 // NewSwapService ...
@@ -38,14 +51,18 @@ func NewSwapService(
 	xmrmaker XMRMaker,
 	net Net,
 	b ProtocolBackend,
+	events ContractEventsDB,
+	priceOracle coins.PriceOracle,
 ) *SwapService {
 	return &SwapService{
-		ctx:      ctx,
-		sm:       sm,
-		xmrtaker: xmrtaker,
-		xmrmaker: xmrmaker,
-		net:      net,
-		backend:  b,
+		ctx:         ctx,
+		sm:          sm,
+		xmrtaker:    xmrtaker,
+		xmrmaker:    xmrmaker,
+		net:         net,
+		backend:     b,
+		events:      events,
+		priceOracle: priceOracle,
 	}
 }
 
@@ -60,34 +77,84 @@ type PastSwap struct {
 	Status         types.Status        `json:"status" validate:"required"`
 	StartTime      time.Time           `json:"startTime" validate:"required"`
 	EndTime        *time.Time          `json:"endTime"`
+	Stages         []*swap.Stage       `json:"stages" validate:"dive,required"`
+	Profile        string              `json:"profile,omitempty"`
+}
+
+// PastSwapFilter selects past swaps. Unset fields match all swaps.
+type PastSwapFilter struct {
+	Status    *types.Status   `json:"status,omitempty"`
+	Since     *time.Time      `json:"since,omitempty"` // inclusive, compared to the start time
+	Until     *time.Time      `json:"until,omitempty"` // exclusive, compared to the start time
+	EthAsset  *types.EthAsset `json:"ethAsset,omitempty"`
+	PeerID    peer.ID         `json:"peerID,omitempty"`
+	MinAmount *apd.Decimal    `json:"minAmount,omitempty"` // in XMR
+}
+
+// matches returns whether the filter selects the swap.
+func (f *PastSwapFilter) matches(info *swap.Info) bool {
+	xmrAmount := info.ProvidedAmount
+	if info.Provides != coins.ProvidesXMR {
+		xmrAmount = info.ExpectedAmount
+	}
+
+	return (f.Status == nil || info.Status == *f.Status) &&
+		(f.Since == nil || !info.StartTime.Before(*f.Since)) &&
+		(f.Until == nil || info.StartTime.Before(*f.Until)) &&
+		(f.EthAsset == nil || info.EthAsset == *f.EthAsset) &&
+		(f.PeerID == "" || info.PeerID == f.PeerID) &&
+		(f.MinAmount == nil || xmrAmount.Cmp(f.MinAmount) >= 0)
 }
 
 // GetPastRequest ...
 type GetPastRequest struct {
 	OfferID *types.Hash `json:"offerID,omitempty"`
+
+	// The filter and pagination are ignored if an offer ID is provided
+	PastSwapFilter
+	// Limit is the maximum number of swaps returned, all swaps if zero
+	Limit uint64 `json:"limit,omitempty"`
+	// Cursor is the nextCursor of the previous page
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // GetPastResponse ...
 type GetPastResponse struct {
 	Swaps []*PastSwap `json:"swaps" validate:"dive,required"`
+	// NextCursor is set if there are more swaps after the returned swaps
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // GetPast returns information about a past swap given its ID.
-// If no ID is provided, all past swaps are returned.
+// If no ID is provided, the past swaps selected by the filter are returned, one
+// page at a time if a limit is provided.
 // It sorts them in order from oldest to newest.
 func (s *SwapService) GetPast(_ *http.Request, req *GetPastRequest, resp *GetPastResponse) error {
 	var swaps []*swap.Info
 
 	if req.OfferID == nil {
-		ids, err := s.sm.GetPastIDs()
+		var after *pastSwapCursor
+		if req.Cursor != "" {
+			cursor, err := decodePastSwapCursor(req.Cursor)
+			if err != nil {
+				return err
+			}
+			after = cursor
+		}
+
+		past, err := s.pastSwaps(&req.PastSwapFilter)
 		if err != nil {
 			return err
 		}
 
-		for _, id := range ids {
-			info, err := s.sm.GetPastSwap(id)
-			if err != nil {
-				return fmt.Errorf("failed to get past swap %s: %w", id, err)
+		for _, info := range past {
+			if after != nil && !after.before(info) {
+				continue
+			}
+
+			if req.Limit != 0 && uint64(len(swaps)) == req.Limit {
+				resp.NextCursor = newPastSwapCursor(swaps[len(swaps)-1]).String()
+				break
 			}
 
 			swaps = append(swaps, info)
@@ -113,14 +180,39 @@ func (s *SwapService) GetPast(_ *http.Request, req *GetPastRequest, resp *GetPas
 			Status:         info.Status,
 			StartTime:      info.StartTime,
 			EndTime:        info.EndTime,
+			Stages:         info.Stages,
+			Profile:        info.Profile,
 		}
 	}
 
-	sort.Slice(resp.Swaps, func(i, j int) bool {
-		return resp.Swaps[i].StartTime.UnixNano() < resp.Swaps[j].StartTime.UnixNano()
+	return nil
+}
+
+// pastSwaps returns the past swaps selected by the filter, sorted from oldest to
+// newest, and by ID for swaps with the same start time.
+func (s *SwapService) pastSwaps(filter *PastSwapFilter) ([]*swap.Info, error) {
+	ids, err := s.sm.GetPastIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var swaps []*swap.Info
+	for _, id := range ids {
+		info, err := s.sm.GetPastSwap(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get past swap %s: %w", id, err)
+		}
+
+		if filter.matches(info) {
+			swaps = append(swaps, info)
+		}
+	}
+
+	sort.Slice(swaps, func(i, j int) bool {
+		return newPastSwapCursor(swaps[i]).before(swaps[j])
 	})
 
-	return nil
+	return swaps, nil
 }
 
 // OngoingSwap represents an ongoing swap returned by swap_getOngoing.
@@ -137,6 +229,8 @@ type OngoingSwap struct {
 	Timeout0                  *time.Time          `json:"timeout0"`
 	Timeout1                  *time.Time          `json:"timeout1"`
 	EstimatedTimeToCompletion time.Duration       `json:"estimatedTimeToCompletion" validate:"required"`
+	Stages                    []*swap.Stage       `json:"stages" validate:"dive,required"`
+	Profile                   string              `json:"profile,omitempty"`
 }
 
 // GetOngoingRequest ...
@@ -186,6 +280,8 @@ func (s *SwapService) GetOngoing(_ *http.Request, req *GetOngoingRequest, resp *
 		swap.StartTime = info.StartTime
 		swap.Timeout0 = info.Timeout0
 		swap.Timeout1 = info.Timeout1
+		swap.Stages = info.Stages
+		swap.Profile = info.Profile
 		swap.EstimatedTimeToCompletion, err = estimatedTimeToCompletion(env, info.Status, info.LastStatusUpdateTime)
 		if err != nil {
 			return fmt.Errorf("failed to estimate time to completion for swap %s: %w", info.OfferID, err)
@@ -300,6 +396,29 @@ func (s *SwapService) Cancel(_ *http.Request, req *CancelRequest, resp *CancelRe
 	return nil
 }
 
+// SetXMRPriorityRequest ...
+type SetXMRPriorityRequest struct {
+	OfferID  types.Hash `json:"offerID" validate:"required"`
+	Priority string     `json:"priority" validate:"required"` // unimportant, normal, elevated or priority
+}
+
+// SetXMRPriority overrides the fee priority of the Monero lock and sweep
+// transactions of the ongoing swap that have not been sent yet, e.g. to confirm
+// the lock faster when the swap is close to its timeout.
+func (s *SwapService) SetXMRPriority(_ *http.Request, req *SetXMRPriorityRequest, _ *interface{}) error {
+	if _, err := s.sm.GetOngoingSwap(req.OfferID); err != nil {
+		return fmt.Errorf("failed to get ongoing swap: %w", err)
+	}
+
+	priority, err := monero.ParsePriority(req.Priority)
+	if err != nil {
+		return err
+	}
+
+	s.backend.SetXMRPriority(priority, req.OfferID)
+	return nil
+}
+
 // SuggestedExchangeRateResponse ...
 type SuggestedExchangeRateResponse struct {
 	ETHUpdatedAt time.Time           `json:"ethUpdatedAt" validate:"required"`
@@ -309,35 +428,77 @@ type SuggestedExchangeRateResponse struct {
 	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
 }
 
-// SuggestedExchangeRate returns the current mainnet exchange rate, expressed as the XMR/ETH price.
+// SuggestedExchangeRate returns the current mainnet exchange rate, expressed as the XMR/ETH price,
+// from the price oracle of swapd's --price-source.
 func (s *SwapService) SuggestedExchangeRate(_ *http.Request, _ *interface{}, resp *SuggestedExchangeRateResponse) error { //nolint:lll
-	ec := s.backend.ETHClient().Raw()
+	oracle := s.priceOracle
+	if oracle == nil {
+		oracle = pricefeed.NewChainlinkOracle(s.backend.ETHClient().Raw())
+	}
 
-	xmrFeed, err := pricefeed.GetXMRUSDPrice(s.ctx, ec)
+	xmrRate, err := oracle.GetRate(s.ctx, coins.PairXMRUSD)
 	if err != nil {
 		return err
 	}
 
-	ethFeed, err := pricefeed.GetETHUSDPrice(s.ctx, ec)
+	ethRate, err := oracle.GetRate(s.ctx, coins.PairETHUSD)
 	if err != nil {
 		return err
 	}
 
-	exchangeRate, err := coins.CalcExchangeRate(xmrFeed.Price, ethFeed.Price)
+	exchangeRate, err := coins.CalcExchangeRate(xmrRate.Price, ethRate.Price)
 	if err != nil {
 		return err
 	}
 
-	resp.XMRUpdatedAt = xmrFeed.UpdatedAt
-	resp.XMRPrice = xmrFeed.Price
+	resp.XMRUpdatedAt = xmrRate.UpdatedAt
+	resp.XMRPrice = xmrRate.Price
 
-	resp.ETHUpdatedAt = ethFeed.UpdatedAt
-	resp.ETHPrice = ethFeed.Price
+	resp.ETHUpdatedAt = ethRate.UpdatedAt
+	resp.ETHPrice = ethRate.Price
 
 	resp.ExchangeRate = exchangeRate
 	return nil
 }
 
+// GetContractEventsRequest ...
+type GetContractEventsRequest struct {
+	SwapID *types.Hash `json:"swapID,omitempty"`
+}
+
+// GetContractEventsResponse ...
+type GetContractEventsResponse struct {
+	SwapCreatorAddr ethcommon.Address   `json:"swapCreatorAddr" validate:"required"`
+	Events          []*db.ContractEvent `json:"events" validate:"dive,required"`
+}
+
+// GetContractEvents returns the indexed events of the swap creator contract. If a
+// swap ID (the contract's swap ID, not the offer ID) is provided, only the events
+// of that swap are returned.
+func (s *SwapService) GetContractEvents(
+	_ *http.Request,
+	req *GetContractEventsRequest,
+	resp *GetContractEventsResponse,
+) error {
+	if s.events == nil {
+		return errContractEventsNotIndexed
+	}
+
+	contractAddr := s.backend.SwapCreatorAddr()
+	events, err := s.events.GetContractEvents(contractAddr, req.SwapID)
+	if err != nil {
+		return err
+	}
+
+	resp.SwapCreatorAddr = contractAddr
+	resp.Events = events
+	if resp.Events == nil {
+		resp.Events = []*db.ContractEvent{}
+	}
+
+	return nil
+}
+
 // estimatedTimeToCompletion returns the estimated time for the swap to complete
 // in the optimistic case based on the given status and the time the status was updated.
 func estimatedTimeToCompletion(
@@ -0,0 +1,178 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+// selfSignedCertValidity is how long a generated self-signed certificate is valid.
+const selfSignedCertValidity = 5 * 365 * 24 * time.Hour
+
+// serverTLSConfig returns the TLS configuration serving the certificate and key
+// files.
+func serverTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load RPC TLS certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// EnsureSelfSignedCert generates a self-signed certificate for the local host and
+// the extra hostnames and IP addresses, and its key. An existing certificate is kept
+// unless its subject alternative names differ from the requested ones. Clients verify
+// the connection by trusting the certificate file.
+func EnsureSelfSignedCert(certFile, keyFile string, extraHosts []string) error {
+	dnsNames, ipAddrs := selfSignedCertSANs(extraHosts)
+
+	existing, err := readCertFile(certFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if existing != nil {
+		if sameSANs(existing, dnsNames, ipAddrs) {
+			return nil
+		}
+		log.Infof("hosts of the self-signed RPC TLS certificate changed, regenerating %s", certFile)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"swapd"}, CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              dnsNames,
+		IPAddresses:           ipAddrs,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	// The key is written first, so an existing certificate always has its key
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err = os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	if err = os.WriteFile(certFile, certPEM, 0644); err != nil { //nolint:gosec
+		return err
+	}
+
+	log.Infof("generated self-signed RPC TLS certificate %s", certFile)
+	return nil
+}
+
+// selfSignedCertSANs returns the DNS names and IP addresses of a self-signed
+// certificate for the local host and the extra hosts.
+func selfSignedCertSANs(extraHosts []string) ([]string, []net.IP) {
+	dnsNames := []string{"localhost"}
+	ipAddrs := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+
+	for _, host := range extraHosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !containsIP(ipAddrs, ip) {
+				ipAddrs = append(ipAddrs, ip)
+			}
+		} else if !containsString(dnsNames, host) {
+			dnsNames = append(dnsNames, host)
+		}
+	}
+
+	return dnsNames, ipAddrs
+}
+
+// readCertFile reads the first certificate of a PEM file.
+func readCertFile(certFile string) (*x509.Certificate, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s is not a PEM certificate", certFile)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// sameSANs returns true if the certificate has exactly the DNS names and IP
+// addresses, in any order.
+func sameSANs(cert *x509.Certificate, dnsNames []string, ipAddrs []net.IP) bool {
+	if len(cert.DNSNames) != len(dnsNames) || len(cert.IPAddresses) != len(ipAddrs) {
+		return false
+	}
+
+	for _, name := range dnsNames {
+		if !containsString(cert.DNSNames, name) {
+			return false
+		}
+	}
+
+	for _, ip := range ipAddrs {
+		if !containsIP(cert.IPAddresses, ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, i := range ips {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
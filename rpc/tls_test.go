@@ -0,0 +1,77 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"crypto/x509"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureSelfSignedCert(t *testing.T) {
+	dir := t.TempDir()
+	certFile := path.Join(dir, "cert.pem")
+	keyFile := path.Join(dir, "key.pem")
+
+	require.NoError(t, EnsureSelfSignedCert(certFile, keyFile, nil))
+	certPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+
+	// an existing certificate is kept
+	require.NoError(t, EnsureSelfSignedCert(certFile, keyFile, nil))
+	certPEM2, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	require.Equal(t, certPEM, certPEM2)
+
+	tlsConfig, err := serverTLSConfig(certFile, keyFile)
+	require.NoError(t, err)
+	require.Len(t, tlsConfig.Certificates, 1)
+
+	// clients trusting the certificate file can verify the local host addresses
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(certPEM))
+	cert, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		_, err = cert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		require.NoError(t, err)
+	}
+}
+
+func TestEnsureSelfSignedCert_extraHosts(t *testing.T) {
+	dir := t.TempDir()
+	certFile := path.Join(dir, "cert.pem")
+	keyFile := path.Join(dir, "key.pem")
+	extraHosts := []string{"swapd.example.com", "192.168.1.10"}
+
+	require.NoError(t, EnsureSelfSignedCert(certFile, keyFile, extraHosts))
+	cert, err := readCertFile(certFile)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	for _, host := range []string{"localhost", "127.0.0.1", "swapd.example.com", "192.168.1.10"} {
+		_, err = cert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		require.NoError(t, err, host)
+	}
+
+	// the same hosts in a different order keep the certificate
+	require.NoError(t, EnsureSelfSignedCert(certFile, keyFile, []string{"192.168.1.10", "swapd.example.com"}))
+	cert2, err := readCertFile(certFile)
+	require.NoError(t, err)
+	require.Equal(t, cert.SerialNumber, cert2.SerialNumber)
+
+	// changed hosts regenerate it
+	require.NoError(t, EnsureSelfSignedCert(certFile, keyFile, []string{"swapd.example.com"}))
+	cert3, err := readCertFile(certFile)
+	require.NoError(t, err)
+	require.NotEqual(t, cert.SerialNumber, cert3.SerialNumber)
+	require.Len(t, cert3.IPAddresses, 2) // only the loopback addresses
+
+	// the regenerated certificate matches the new key
+	_, err = serverTLSConfig(certFile, keyFile)
+	require.NoError(t, err)
+}
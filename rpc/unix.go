@@ -0,0 +1,61 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"time"
+)
+
+// unixSocketMode only allows the user running swapd to connect to the socket.
+const unixSocketMode = 0600
+
+// listenUnix listens on the unix socket at the path, which only the user running
+// swapd can connect to. A socket left behind by a previous swapd that didn't shut
+// down cleanly is replaced, but not one that another process is listening on.
+func listenUnix(ctx context.Context, socketPath string) (net.Listener, error) {
+	if err := removeStaleSocket(socketPath); err != nil {
+		return nil, err
+	}
+
+	lc := net.ListenConfig{}
+	ln, err := lc.Listen(ctx, "unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.Chmod(socketPath, unixSocketMode); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+
+	return ln, nil
+}
+
+func removeStaleSocket(socketPath string) error {
+	info, err := os.Lstat(socketPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%q exists and is not a unix socket", socketPath)
+	}
+
+	conn, err := net.DialTimeout("unix", socketPath, time.Second)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("unix socket %q is in use by another process", socketPath)
+	}
+
+	return os.Remove(socketPath)
+}
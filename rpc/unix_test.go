@@ -0,0 +1,48 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	ctx := context.Background()
+	socketPath := path.Join(t.TempDir(), "rpc.sock")
+
+	ln, err := listenUnix(ctx, socketPath)
+	require.NoError(t, err)
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(unixSocketMode), info.Mode().Perm())
+
+	// a socket that another process is listening on is not replaced
+	_, err = listenUnix(ctx, socketPath)
+	require.ErrorContains(t, err, "in use")
+	require.NoError(t, ln.Close())
+
+	// other files are not replaced
+	require.NoError(t, os.WriteFile(socketPath, nil, 0600))
+	_, err = listenUnix(ctx, socketPath)
+	require.ErrorContains(t, err, "not a unix socket")
+	require.NoError(t, os.Remove(socketPath))
+
+	// a socket left behind by a listener that didn't clean up is replaced
+	ln, err = listenUnix(ctx, socketPath)
+	require.NoError(t, err)
+	ln.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+	_, err = os.Stat(socketPath)
+	require.NoError(t, err)
+
+	ln, err = listenUnix(ctx, socketPath)
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+}
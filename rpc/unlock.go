@@ -0,0 +1,161 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/rpc/v2"
+)
+
+// unlockShutdownTimeout bounds the time to send the personal_unlock response before
+// the unlock server shuts down.
+const unlockShutdownTimeout = 5 * time.Second
+
+// UnlockConfig is the configuration of the RPC server that swapd serves while its
+// Monero wallet is locked. The server only serves personal_unlock, with the address,
+// TLS, unix socket, CORS and authentication settings of the full RPC server.
+type UnlockConfig struct {
+	Ctx         context.Context
+	Address     string // "IP:port", or empty to only serve on UnixSocket
+	UnixSocket  string
+	TLSCertFile string
+	TLSKeyFile  string
+	CORS        *CORSConfig
+	AuthTokens  []*AuthToken
+
+	// Unlock opens the wallet with the password of a personal_unlock request,
+	// returning an error if the password is wrong.
+	Unlock func(password string) error
+}
+
+// UnlockService serves personal_unlock until the Monero wallet is unlocked.
+type UnlockService struct {
+	mu       sync.Mutex
+	unlock   func(password string) error
+	unlocked chan struct{}
+}
+
+// NewUnlockService ...
+func NewUnlockService(unlock func(password string) error) *UnlockService {
+	return &UnlockService{
+		unlock:   unlock,
+		unlocked: make(chan struct{}),
+	}
+}
+
+// UnlockRequest ...
+type UnlockRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// Unlock opens the Monero wallet with its password, after which swapd starts.
+func (s *UnlockService) Unlock(_ *http.Request, req *UnlockRequest, _ *interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.unlocked:
+		return errAlreadyUnlocked
+	default:
+	}
+
+	if err := s.unlock(req.Password); err != nil {
+		log.Warnf("Failed to unlock the monero wallet: %s", err)
+		return fmt.Errorf("failed to unlock the monero wallet: %w", err)
+	}
+
+	close(s.unlocked)
+	return nil
+}
+
+// WaitForUnlock serves personal_unlock until a request unlocks the Monero wallet,
+// returning once the response is sent and the server is shut down, so that the
+// full RPC server can listen on the same address.
+func WaitForUnlock(cfg *UnlockConfig) error {
+	if cfg.Address == "" && cfg.UnixSocket == "" {
+		return errors.New("RPC server needs an address or a unix socket to listen on")
+	}
+
+	ctx, cancel := context.WithCancel(cfg.Ctx)
+	defer cancel()
+
+	service := NewUnlockService(cfg.Unlock)
+	rpcServer := rpc.NewServer()
+	rpcServer.RegisterCodec(NewCodec(), "application/json")
+	rpcServer.RegisterValidateRequestFunc(validateRequest)
+	if err := rpcServer.RegisterService(service, PersonalName); err != nil {
+		return err
+	}
+
+	var listeners []net.Listener
+	defer func() {
+		for _, ln := range listeners {
+			_ = ln.Close()
+		}
+	}()
+	if cfg.Address != "" {
+		ln, err := listenTCP(ctx, cfg.Address, &Config{TLSCertFile: cfg.TLSCertFile, TLSKeyFile: cfg.TLSKeyFile})
+		if err != nil {
+			return err
+		}
+		listeners = append(listeners, ln)
+	}
+	if cfg.UnixSocket != "" {
+		ln, err := listenUnix(ctx, cfg.UnixSocket)
+		if err != nil {
+			return err
+		}
+		listeners = append(listeners, ln)
+	}
+
+	cors := cfg.CORS
+	if cors == nil {
+		cors = DefaultCORSConfig()
+	}
+
+	auth := newAuthenticator(cfg.AuthTokens)
+	r := mux.NewRouter()
+	r.Handle("/", auth.middleware(rpcServer))
+	r.HandleFunc("/healthz", healthzHandler)
+
+	server := &http.Server{
+		ReadHeaderTimeout: time.Second,
+		Handler:           cors.handler(r),
+		BaseContext: func(listener net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	serverErr := make(chan error, len(listeners))
+	for _, ln := range listeners {
+		log.Infof("Monero wallet is locked, waiting for personal_unlock on %s", ln.Addr())
+		go func(ln net.Listener) {
+			serverErr <- server.Serve(ln)
+		}(ln)
+	}
+
+	select {
+	case <-service.unlocked:
+		log.Info("Monero wallet unlocked")
+	case <-ctx.Done():
+		_ = server.Close()
+		return ctx.Err()
+	case err := <-serverErr:
+		_ = server.Close()
+		return err
+	}
+
+	// Shutdown waits for the response of the personal_unlock request to be sent
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), unlockShutdownTimeout)
+	defer shutdownCancel()
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestUnlockService(t *testing.T) {
+	s := NewUnlockService(func(password string) error {
+		if password != "secret" {
+			return errors.New("invalid password")
+		}
+		return nil
+	})
+
+	err := s.Unlock(nil, &UnlockRequest{Password: "wrong"}, nil)
+	require.ErrorContains(t, err, "invalid password")
+
+	err = s.Unlock(nil, &UnlockRequest{Password: "secret"}, nil)
+	require.NoError(t, err)
+
+	err = s.Unlock(nil, &UnlockRequest{Password: "secret"}, nil)
+	require.ErrorIs(t, err, errAlreadyUnlocked)
+}
+
+func TestWaitForUnlock(t *testing.T) {
+	port, err := common.GetFreeTCPPort()
+	require.NoError(t, err)
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- WaitForUnlock(&UnlockConfig{
+			Ctx:     ctx,
+			Address: address,
+			Unlock: func(password string) error {
+				if password != "secret" {
+					return errors.New("invalid password")
+				}
+				return nil
+			},
+		})
+	}()
+
+	unlock := func(password string) map[string]any {
+		body, marshalErr := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"method":  "personal_unlock",
+			"params":  &UnlockRequest{Password: password},
+			"id":      0,
+		})
+		require.NoError(t, marshalErr)
+
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			var postErr error
+			resp, postErr = http.Post("http://"+address, "application/json", bytes.NewReader(body))
+			return postErr == nil
+		}, 5*time.Second, 50*time.Millisecond)
+		defer func() { _ = resp.Body.Close() }()
+
+		result := make(map[string]any)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return result
+	}
+
+	// the server keeps waiting after a wrong password
+	require.NotNil(t, unlock("wrong")["error"])
+	require.Nil(t, unlock("secret")["error"])
+
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("server did not shut down after the wallet was unlocked")
+	}
+
+	// the full RPC server can listen on the address
+	_, err = listenTCP(ctx, address, &Config{})
+	require.NoError(t, err)
+}
+
+func TestWaitForUnlock_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	err := WaitForUnlock(&UnlockConfig{
+		Ctx:     ctx,
+		Address: "127.0.0.1:0",
+		Unlock:  func(string) error { return nil },
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+
+	"github.com/athanorlabs/atomic-swap/webhook"
+)
+
+// Webhooks is the registry of the webhooks that swap lifecycle events are
+// delivered to.
+type Webhooks interface {
+	Add(hook *webhook.Webhook) error
+	Remove(hookURL string) error
+	Webhooks() []*webhook.Webhook
+}
+
+// WebhookService handles the RPC methods registering the webhooks of swap
+// lifecycle events.
+type WebhookService struct {
+	hooks Webhooks
+}
+
+// NewWebhookService creates a new webhook service.
+func NewWebhookService(hooks Webhooks) *WebhookService {
+	return &WebhookService{hooks: hooks}
+}
+
+// RemoveWebhookRequest ...
+type RemoveWebhookRequest struct {
+	URL string `json:"url" validate:"required"`
+}
+
+// ListWebhooksResponse ...
+type ListWebhooksResponse struct {
+	Webhooks []*webhook.Webhook `json:"webhooks" validate:"dive,required"`
+}
+
+// Add registers a webhook, replacing any webhook with the same URL. Webhooks
+// registered over RPC are kept until swapd exits.
+func (s *WebhookService) Add(_ *http.Request, req *webhook.Webhook, _ *any) error {
+	return s.hooks.Add(req)
+}
+
+// Remove unregisters the webhook with the URL.
+func (s *WebhookService) Remove(_ *http.Request, req *RemoveWebhookRequest, _ *any) error {
+	return s.hooks.Remove(req.URL)
+}
+
+// List returns the registered webhooks, without their secrets.
+func (s *WebhookService) List(_ *http.Request, _ *any, resp *ListWebhooksResponse) error {
+	resp.Webhooks = s.hooks.Webhooks()
+	return nil
+}
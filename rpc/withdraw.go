@@ -0,0 +1,427 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+// FeePriority is the fee priority of a withdrawal. Higher priorities pay higher fees
+// to be included sooner.
+type FeePriority string
+
+// Fee priorities of withdrawals. An empty priority is the normal priority.
+const (
+	FeePriorityLow    FeePriority = "low"
+	FeePriorityNormal FeePriority = "normal"
+	FeePriorityHigh   FeePriority = "high"
+)
+
+// gasPrice returns the gas price of the priority, a percentage of the suggested gas
+// price.
+func (p FeePriority) gasPrice(suggested *big.Int) (*big.Int, error) {
+	var percent int64
+	switch p {
+	case FeePriorityLow:
+		percent = 90
+	case "", FeePriorityNormal:
+		percent = 100
+	case FeePriorityHigh:
+		percent = 150
+	default:
+		return nil, fmt.Errorf("unsupported fee priority %q", p)
+	}
+
+	gasPrice := new(big.Int).Mul(suggested, big.NewInt(percent))
+	return gasPrice.Quo(gasPrice, big.NewInt(100)), nil
+}
+
+// moneroPriority returns the monero-wallet-rpc priority of the priority. The normal
+// priority lets the wallet pick the priority from the transaction backlog.
+func (p FeePriority) moneroPriority() (wallet.Priority, error) {
+	switch p {
+	case FeePriorityLow:
+		return wallet.PriorityUnimportant, nil
+	case "", FeePriorityNormal:
+		return wallet.PriorityDefault, nil
+	case FeePriorityHigh:
+		return wallet.PriorityElevated, nil
+	default:
+		return 0, fmt.Errorf("unsupported fee priority %q", p)
+	}
+}
+
+// validateWithdrawalAmount returns an error unless exactly one of the amount and the
+// all flag is set, or the set amount is invalid.
+func validateWithdrawalAmount(
+	amount *apd.Decimal,
+	all bool,
+	validateAmount func(jsonFieldName string, amount *apd.Decimal) error,
+) error {
+	if all {
+		if amount != nil {
+			return errors.New("amount cannot be set when withdrawing all funds")
+		}
+		return nil
+	}
+
+	return validateAmount("amount", amount)
+}
+
+// TransferETHRequest ...
+type TransferETHRequest struct {
+	To ethcommon.Address `json:"to" validate:"required"`
+	// Amount is in standard units of ETH or of the token, and is not set if All is
+	Amount    *apd.Decimal       `json:"amount,omitempty"`
+	All       bool               `json:"all,omitempty"`
+	TokenAddr *ethcommon.Address `json:"tokenAddr,omitempty"`
+	Priority  FeePriority        `json:"priority,omitempty"`
+}
+
+// TransferETHResponse ...
+type TransferETHResponse struct {
+	TxHash ethcommon.Hash `json:"txHash" validate:"required"`
+	Amount *apd.Decimal   `json:"amount" validate:"required"` // in standard units
+}
+
+// TransferETH withdraws ETH, or a token if a token address is set, from swapd's
+// ethereum account, and waits for the transaction to be included. The whole ETH
+// balance can't be withdrawn while swaps are ongoing, as they need ETH for gas.
+func (s *PersonalService) TransferETH(_ *http.Request, req *TransferETHRequest, resp *TransferETHResponse) error {
+	ec := s.pb.ETHClient()
+	if !ec.HasSigner() {
+		return errNoEthSigner
+	}
+
+	asset := types.EthAssetETH
+	if req.TokenAddr != nil {
+		asset = types.EthAsset(*req.TokenAddr)
+	}
+	if err := validateWithdrawalAmount(req.Amount, req.All, asset.ValidateAmount); err != nil {
+		return err
+	}
+
+	suggestedGasPrice, err := ec.SuggestGasPrice(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	gasPrice, err := req.Priority.gasPrice(suggestedGasPrice)
+	if err != nil {
+		return err
+	}
+
+	if req.TokenAddr != nil {
+		return s.transferToken(req, gasPrice, resp)
+	}
+
+	var amount *coins.WeiAmount
+	if req.All {
+		if err = s.checkNoOngoingSwaps(); err != nil {
+			return err
+		}
+	} else {
+		amount = coins.EtherToWei(req.Amount)
+	}
+
+	receipt, sent, err := ec.TransferETH(s.ctx, req.To, amount, gasPrice)
+	if err != nil {
+		return err
+	}
+	metrics.GasSpent("transfer", receipt)
+
+	resp.TxHash = receipt.TxHash
+	resp.Amount = sent.AsEther()
+	return nil
+}
+
+func (s *PersonalService) transferToken(
+	req *TransferETHRequest,
+	gasPrice *big.Int,
+	resp *TransferETHResponse,
+) error {
+	ec := s.pb.ETHClient()
+
+	var amount *coins.ERC20TokenAmount
+	if req.All {
+		balance, err := ec.ERC20Balance(s.ctx, *req.TokenAddr)
+		if err != nil {
+			return err
+		}
+		amount = balance
+	} else {
+		tokenInfo, err := ec.ERC20Info(s.ctx, *req.TokenAddr)
+		if err != nil {
+			return err
+		}
+		if err = coins.ValidatePositive("amount", tokenInfo.NumDecimals, req.Amount); err != nil {
+			return err
+		}
+		amount = coins.NewERC20TokenAmountFromDecimals(req.Amount, tokenInfo)
+	}
+
+	if amount.BigInt().Sign() == 0 {
+		return fmt.Errorf("no balance of token %s to withdraw", req.TokenAddr)
+	}
+
+	tokenContract, err := contracts.NewIERC20(*req.TokenAddr, ec.Raw())
+	if err != nil {
+		return err
+	}
+
+	err = contracts.CheckERC20Call(s.ctx, ec.Raw(), *req.TokenAddr, ec.Address(), "transfer", req.To, amount.BigInt())
+	if err != nil {
+		return err
+	}
+
+	receipt, err := s.sendTokenTransfer(tokenContract, req.To, amount.BigInt(), gasPrice)
+	if err != nil {
+		return err
+	}
+	metrics.GasSpent("transfer", receipt)
+
+	resp.TxHash = receipt.TxHash
+	resp.Amount = amount.AsStandard()
+	return nil
+}
+
+func (s *PersonalService) sendTokenTransfer(
+	tokenContract *contracts.IERC20,
+	to ethcommon.Address,
+	amount *big.Int,
+	gasPrice *big.Int,
+) (*ethtypes.Receipt, error) {
+	ec := s.pb.ETHClient()
+
+	// the wallet lock keeps the transfer from racing with a swap's transactions
+	ec.Lock()
+	defer ec.Unlock()
+
+	txOpts, err := ec.TxOpts(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	txOpts.GasPrice = gasPrice
+
+	tx, err := tokenContract.Transfer(txOpts, to, amount)
+	if err != nil {
+		return nil, fmt.Errorf("transfer tx creation failed, %w", err)
+	}
+
+	receipt, err := ec.WaitForReceipt(s.ctx, tx.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("transfer failed, %w", err)
+	}
+
+	return receipt, nil
+}
+
+// TransferXMRRequest ...
+type TransferXMRRequest struct {
+	To *mcrypto.Address `json:"to" validate:"required"`
+	// Amount is in XMR, and is not set if All is
+	Amount   *apd.Decimal `json:"amount,omitempty"`
+	All      bool         `json:"all,omitempty"`
+	Priority FeePriority  `json:"priority,omitempty"`
+}
+
+// TransferXMRResponse ...
+type TransferXMRResponse struct {
+	TxIDs  []string     `json:"txIDs" validate:"required"`
+	Amount *apd.Decimal `json:"amount" validate:"required"` // in XMR
+	Fee    *apd.Decimal `json:"fee" validate:"required"`    // in XMR
+}
+
+// TransferXMR withdraws XMR from swapd's primary monero account. It returns once the
+// transactions are submitted, without waiting for confirmations. Withdrawing all
+// funds sweeps the unlocked balance, and is refused while swaps are ongoing.
+func (s *PersonalService) TransferXMR(_ *http.Request, req *TransferXMRRequest, resp *TransferXMRResponse) error {
+	if err := req.To.ValidateEnv(s.pb.Env()); err != nil {
+		return err
+	}
+
+	if err := validateWithdrawalAmount(req.Amount, req.All, coins.ValidateXMRAmount); err != nil {
+		return err
+	}
+
+	priority, err := req.Priority.moneroPriority()
+	if err != nil {
+		return err
+	}
+
+	var amount *coins.PiconeroAmount
+	if req.All {
+		if err = s.checkNoOngoingSwaps(); err != nil {
+			return err
+		}
+	} else {
+		amount = coins.MoneroToPiconero(req.Amount)
+	}
+
+	withdrawal, err := s.pb.XMRClient().Withdraw(req.To, amount, priority)
+	if err != nil {
+		return err
+	}
+
+	resp.TxIDs = withdrawal.TxIDs
+	resp.Amount = withdrawal.Amount.AsMonero()
+	resp.Fee = withdrawal.Fee.AsMonero()
+	return nil
+}
+
+// SweepXMRRequest ...
+type SweepXMRRequest struct {
+	// To is our primary address if not set, which consolidates our outputs
+	To *mcrypto.Address `json:"to,omitempty"`
+	// BelowAmount limits the sweep to outputs of smaller amounts, in XMR
+	BelowAmount *apd.Decimal `json:"belowAmount,omitempty"`
+	Priority    FeePriority  `json:"priority,omitempty"`
+	Profile     string       `json:"profile,omitempty"` // the default wallet if empty
+}
+
+// SweepXMRResponse ...
+type SweepXMRResponse struct {
+	To     *mcrypto.Address `json:"to" validate:"required"`
+	TxIDs  []string         `json:"txIDs" validate:"required"`
+	Amount *apd.Decimal     `json:"amount" validate:"required"` // in XMR
+	Fee    *apd.Decimal     `json:"fee" validate:"required"`    // in XMR
+}
+
+// SweepXMR sweeps the unlocked outputs of swapd's primary monero account to an
+// address, by default our own primary address. Makers use it to consolidate the many
+// small outputs of claimed swaps into a few larger ones before funding large offers,
+// which would otherwise spend many inputs with a high fee. The swept funds are locked
+// until the sweep transactions unlock, so it is refused while swaps are ongoing.
+func (s *PersonalService) SweepXMR(_ *http.Request, req *SweepXMRRequest, resp *SweepXMRResponse) error {
+	if req.To != nil {
+		if err := req.To.ValidateEnv(s.pb.Env()); err != nil {
+			return err
+		}
+	}
+
+	var belowAmount *coins.PiconeroAmount
+	if req.BelowAmount != nil {
+		err := coins.ValidateXMRAmount("belowAmount", req.BelowAmount)
+		if err != nil {
+			return err
+		}
+		belowAmount = coins.MoneroToPiconero(req.BelowAmount)
+	}
+
+	priority, err := req.Priority.moneroPriority()
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkNoOngoingSwaps(); err != nil {
+		return err
+	}
+
+	b, err := s.pb.WithProfile(req.Profile)
+	if err != nil {
+		return err
+	}
+
+	xmrClient := b.XMRClient()
+	to := req.To
+	if to == nil {
+		to = xmrClient.PrimaryAddress()
+	}
+
+	sweep, err := xmrClient.SweepUnlocked(to, belowAmount, priority)
+	if err != nil {
+		return err
+	}
+
+	resp.To = to
+	resp.TxIDs = sweep.TxIDs
+	resp.Amount = sweep.Amount.AsMonero()
+	resp.Fee = sweep.Fee.AsMonero()
+	return nil
+}
+
+// PlanConsolidationRequest ...
+type PlanConsolidationRequest struct {
+	Amount  *apd.Decimal `json:"amount" validate:"required"` // in XMR
+	Profile string       `json:"profile,omitempty"`          // the default wallet if empty
+}
+
+// PlanConsolidationResponse ...
+type PlanConsolidationResponse struct {
+	UnlockedOutputs int          `json:"unlockedOutputs"`
+	UnlockedBalance *apd.Decimal `json:"unlockedBalance" validate:"required"` // in XMR
+	Sufficient      bool         `json:"sufficient"`
+	InputsNeeded    int          `json:"inputsNeeded"`
+	Recommended     bool         `json:"recommended"`
+	SweepBelow      *apd.Decimal `json:"sweepBelow,omitempty"` // in XMR
+	SweepOutputs    int          `json:"sweepOutputs"`
+	SweepAmount     *apd.Decimal `json:"sweepAmount,omitempty"` // in XMR
+}
+
+// PlanConsolidation returns how many unlocked outputs of swapd's primary monero
+// account the lock of an amount would spend, and if they are too many, the outputs
+// that should be consolidated beforehand with personal_sweepXMR and the plan's
+// sweepBelow as its belowAmount.
+func (s *PersonalService) PlanConsolidation(
+	_ *http.Request,
+	req *PlanConsolidationRequest,
+	resp *PlanConsolidationResponse,
+) error {
+	err := coins.ValidateXMRAmount("amount", req.Amount)
+	if err != nil {
+		return err
+	}
+
+	b, err := s.pb.WithProfile(req.Profile)
+	if err != nil {
+		return err
+	}
+
+	plan, err := b.XMRClient().PlanConsolidation(coins.MoneroToPiconero(req.Amount))
+	if err != nil {
+		return err
+	}
+
+	*resp = PlanConsolidationResponse{
+		UnlockedOutputs: plan.UnlockedOutputs,
+		UnlockedBalance: coins.NewPiconeroAmount(plan.UnlockedBalance).AsMonero(),
+		Sufficient:      plan.Sufficient(),
+		InputsNeeded:    plan.InputsNeeded,
+		Recommended:     plan.Recommended,
+		SweepOutputs:    plan.SweepOutputs,
+	}
+	if plan.Recommended {
+		resp.SweepBelow = coins.NewPiconeroAmount(plan.SweepBelow).AsMonero()
+		resp.SweepAmount = coins.NewPiconeroAmount(plan.SweepAmount).AsMonero()
+	}
+	return nil
+}
+
+// checkNoOngoingSwaps returns errOngoingSwapsWithdrawal if swaps are ongoing, as
+// withdrawing or sweeping all funds would leave them without the funds they need.
+func (s *PersonalService) checkNoOngoingSwaps() error {
+	ongoing, err := s.pb.SwapManager().GetOngoingSwaps()
+	if err != nil {
+		return err
+	}
+
+	if len(ongoing) > 0 {
+		return errOngoingSwapsWithdrawal
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestFeePriority_gasPrice(t *testing.T) {
+	suggested := big.NewInt(20e9)
+
+	for priority, expected := range map[FeePriority]int64{
+		FeePriorityLow:    18e9,
+		"":                20e9,
+		FeePriorityNormal: 20e9,
+		FeePriorityHigh:   30e9,
+	} {
+		gasPrice, err := priority.gasPrice(suggested)
+		require.NoError(t, err)
+		require.Equal(t, expected, gasPrice.Int64(), priority)
+	}
+
+	_, err := FeePriority("urgent").gasPrice(suggested)
+	require.ErrorContains(t, err, `unsupported fee priority "urgent"`)
+}
+
+func TestFeePriority_moneroPriority(t *testing.T) {
+	priority, err := FeePriorityLow.moneroPriority()
+	require.NoError(t, err)
+	require.Equal(t, wallet.PriorityUnimportant, priority)
+
+	priority, err = FeePriority("").moneroPriority()
+	require.NoError(t, err)
+	require.Equal(t, wallet.PriorityDefault, priority)
+
+	priority, err = FeePriorityHigh.moneroPriority()
+	require.NoError(t, err)
+	require.Equal(t, wallet.PriorityElevated, priority)
+
+	_, err = FeePriority("urgent").moneroPriority()
+	require.Error(t, err)
+}
+
+func TestValidateWithdrawalAmount(t *testing.T) {
+	require.NoError(t, validateWithdrawalAmount(apd.New(15, -1), false, coins.ValidateXMRAmount))
+	require.NoError(t, validateWithdrawalAmount(nil, true, coins.ValidateXMRAmount))
+
+	err := validateWithdrawalAmount(apd.New(1, 0), true, coins.ValidateXMRAmount)
+	require.ErrorContains(t, err, "amount cannot be set when withdrawing all funds")
+
+	require.Error(t, validateWithdrawalAmount(nil, false, coins.ValidateXMRAmount))
+	require.Error(t, validateWithdrawalAmount(apd.New(0, 0), false, coins.ValidateXMRAmount))
+	require.Error(t, validateWithdrawalAmount(apd.New(1, -13), false, coins.ValidateXMRAmount))
+
+	// piconero amounts submitted as XMR are rejected
+	err = validateWithdrawalAmount(apd.New(15, 20), false, coins.ValidateXMRAmount)
+	require.ErrorContains(t, err, "the amount is in XMR, not piconero")
+}
+
+func TestPersonalService_SweepXMR_invalid(t *testing.T) {
+	s := NewPersonalService(context.Background(), nil, newMockProtocolBackend(), "", "", nil, nil)
+
+	err := s.SweepXMR(nil, &SweepXMRRequest{BelowAmount: apd.New(0, 0)}, new(SweepXMRResponse))
+	require.ErrorContains(t, err, `"belowAmount" must be non-zero`)
+
+	err = s.SweepXMR(nil, &SweepXMRRequest{Priority: "urgent"}, new(SweepXMRResponse))
+	require.ErrorContains(t, err, `unsupported fee priority "urgent"`)
+}
+
+func TestPersonalService_PlanConsolidation_invalid(t *testing.T) {
+	s := NewPersonalService(context.Background(), nil, newMockProtocolBackend(), "", "", nil, nil)
+
+	err := s.PlanConsolidation(nil, &PlanConsolidationRequest{Amount: apd.New(0, 0)}, new(PlanConsolidationResponse))
+	require.ErrorContains(t, err, `"amount" must be non-zero`)
+}
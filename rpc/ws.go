@@ -19,30 +19,26 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: checkOriginFunc,
-}
-
-func checkOriginFunc(_ *http.Request) bool {
-	return true
-}
-
 type wsServer struct {
-	ctx     context.Context
-	sm      SwapManager
-	ns      *NetService
-	backend ProtocolBackend
-	taker   XMRTaker
+	ctx      context.Context
+	sm       SwapManager
+	ns       *NetService
+	backend  ProtocolBackend
+	taker    XMRTaker
+	upgrader websocket.Upgrader
 }
 
 func newWsServer(ctx context.Context, sm SwapManager, ns *NetService, backend ProtocolBackend,
-	taker XMRTaker) *wsServer {
+	taker XMRTaker, cors *CORSConfig) *wsServer {
 	s := &wsServer{
 		ctx:     ctx,
 		sm:      sm,
 		ns:      ns,
 		backend: backend,
 		taker:   taker,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: cors.checkOrigin,
+		},
 	}
 
 	return s
@@ -50,7 +46,7 @@ func newWsServer(ctx context.Context, sm SwapManager, ns *NetService, backend Pr
 
 // ServeHTTP ...
 func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Warnf("failed to update connection to websockets: %s", err)
 		return
@@ -58,6 +54,8 @@ func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	defer func() { _ = conn.Close() }()
 
+	scope := authScopeFromContext(r.Context())
+
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
@@ -73,6 +71,11 @@ func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 
 		log.Debugf("received message over websockets: %s", message)
+		if err = checkMethodScope(scope, req.Method); err != nil {
+			_ = writeError(conn, err)
+			continue
+		}
+
 		err = s.handleRequest(conn, req)
 		if err != nil {
 			_ = writeError(conn, err)
@@ -125,6 +128,17 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 		}
 
 		return writeResponse(conn, resp)
+	case rpctypes.SubscribeOffers:
+		if s.ns == nil {
+			return errNamespaceNotEnabled
+		}
+
+		params := new(rpctypes.SubscribeOffersRequest)
+		if err := vjson.UnmarshalStruct(req.Params, params); err != nil {
+			return fmt.Errorf("failed to unmarshal parameters: %w", err)
+		}
+
+		return s.subscribeOffers(s.ctx, conn, params)
 	case rpctypes.SubscribeSwapStatus:
 		params := new(rpctypes.SubscribeSwapStatusRequest)
 		if err := vjson.UnmarshalStruct(req.Params, params); err != nil {
@@ -132,6 +146,20 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 		}
 
 		return s.subscribeSwapStatus(s.ctx, conn, params.OfferID)
+	case rpctypes.SubscribeBalances:
+		params := new(rpctypes.SubscribeBalancesRequest)
+		if err := vjson.UnmarshalStruct(req.Params, params); err != nil {
+			return fmt.Errorf("failed to unmarshal parameters: %w", err)
+		}
+
+		return s.subscribeBalances(s.ctx, conn, params)
+	case rpctypes.SubscribeMoneroSync:
+		params := new(rpctypes.SubscribeMoneroSyncRequest)
+		if err := vjson.UnmarshalStruct(req.Params, params); err != nil {
+			return fmt.Errorf("failed to unmarshal parameters: %w", err)
+		}
+
+		return s.subscribeMoneroSync(s.ctx, conn, params)
 	case rpctypes.SubscribeTakeOffer:
 		if s.ns == nil {
 			return errNamespaceNotEnabled
@@ -142,7 +170,7 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 			return fmt.Errorf("failed to unmarshal parameters: %w", err)
 		}
 
-		ch, err := s.ns.takeOffer(params.PeerID, params.OfferID, params.ProvidesAmount)
+		ch, err := s.ns.takeOffer(params.PeerID, params.OfferID, params.ProvidesAmount, params.Profile)
 		if err != nil {
 			return err
 		}
@@ -163,7 +191,7 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 			return err
 		}
 
-		return s.subscribeMakeOffer(s.ctx, conn, offerResp.OfferID, offerExtra)
+		return s.subscribeMakeOffer(s.ctx, conn, offerResp, offerExtra)
 	default:
 		return errInvalidMethod
 	}
@@ -267,13 +295,8 @@ func (s *wsServer) subscribeTakeOffer(ctx context.Context, conn *websocket.Conn,
 }
 
 func (s *wsServer) subscribeMakeOffer(ctx context.Context, conn *websocket.Conn,
-	offerID types.Hash, offerExtra *types.OfferExtra) error {
-	resp := &rpctypes.MakeOfferResponse{
-		PeerID:  s.ns.net.PeerID(),
-		OfferID: offerID,
-	}
-
-	if err := writeResponse(conn, resp); err != nil {
+	offerResp *rpctypes.MakeOfferResponse, offerExtra *types.OfferExtra) error {
+	if err := writeResponse(conn, offerResp); err != nil {
 		return err
 	}
 
@@ -372,7 +395,8 @@ func writeError(conn *websocket.Conn, err error) error {
 	resp := &rpctypes.Response{
 		Version: rpctypes.DefaultJSONRPCVersion,
 		Error: &rpctypes.Error{
-			Message: err.Error(),
+			Message:   err.Error(),
+			ErrorCode: errorCode(err),
 		},
 	}
 
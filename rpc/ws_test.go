@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/rpcclient/wsclient"
 )
@@ -118,3 +119,116 @@ func TestSubscribeTakeOffer(t *testing.T) {
 		t.Fatal("test timed out")
 	}
 }
+
+func TestSubscribeSwapStatus_stream(t *testing.T) {
+	s := newServer(t)
+
+	stream, err := wsclient.SubscribeSwapStatus(s.ctx, s.WsURL(), nil, testSwapID)
+	require.NoError(t, err)
+
+	select {
+	case status := <-stream.Status():
+		require.Equal(t, types.CompletedSuccess, status)
+	case <-time.After(testTimeout):
+		t.Fatal("test timed out")
+	}
+
+	// the stream ends once the swap exits
+	_, ok := <-stream.Status()
+	require.False(t, ok)
+	require.NoError(t, stream.Err())
+}
+
+func TestSubscribeTakeOffer_stream(t *testing.T) {
+	s := newServer(t)
+
+	stream, err := wsclient.SubscribeTakeOffer(s.ctx, s.WsURL(), nil, testPeerID, testSwapID, apd.New(1, 0))
+	require.NoError(t, err)
+
+	select {
+	case status := <-stream.Status():
+		require.Equal(t, types.CompletedSuccess, status)
+	case <-time.After(testTimeout):
+		t.Fatal("test timed out")
+	}
+
+	_, ok := <-stream.Status()
+	require.False(t, ok)
+	require.NoError(t, stream.Err())
+}
+
+// recordingConn is a websocket client middleware that records the methods called and
+// the number of messages read.
+type recordingConn struct {
+	wsclient.Conn
+	methods  []string
+	messages int
+}
+
+func (c *recordingConn) WriteRequest(ctx context.Context, req *rpctypes.Request) error {
+	c.methods = append(c.methods, req.Method)
+	return c.Conn.WriteRequest(ctx, req)
+}
+
+func (c *recordingConn) ReadMessage(ctx context.Context) ([]byte, error) {
+	message, err := c.Conn.ReadMessage(ctx)
+	if err == nil {
+		c.messages++
+	}
+	return message, err
+}
+
+func TestWsClient_middleware(t *testing.T) {
+	s := newServer(t)
+
+	recorder := new(recordingConn)
+	c, err := wsclient.NewWsClientWithOptions(s.ctx, s.WsURL(), &wsclient.Options{
+		Middleware: []wsclient.Middleware{func(next wsclient.Conn) wsclient.Conn {
+			recorder.Conn = next
+			return recorder
+		}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	ch, err := c.SubscribeSwapStatus(testSwapID)
+	require.NoError(t, err)
+
+	select {
+	case status := <-ch:
+		require.Equal(t, types.CompletedSuccess, status)
+	case <-time.After(testTimeout):
+		t.Fatal("test timed out")
+	}
+
+	require.Equal(t, []string{rpctypes.SubscribeSwapStatus}, recorder.methods)
+	require.Equal(t, 1, recorder.messages)
+}
+
+func TestWsClient_WithContext(t *testing.T) {
+	s := newServer(t)
+
+	c, err := wsclient.NewWsClient(s.ctx, s.WsURL())
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	// a call with a done context is not sent
+	doneCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.WithContext(doneCtx).Discover("", 0)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// a subscription ends when its context is done, without the server sending
+	// anything, as the mock network's offer book is empty
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := c.WithContext(ctx).SubscribeOffers("", 0, 5, nil)
+	require.NoError(t, err)
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		require.False(t, ok)
+	case <-time.After(testTimeout):
+		t.Fatal("test timed out")
+	}
+}
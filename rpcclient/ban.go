@@ -0,0 +1,54 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// BanPeer calls net_banPeer to ban the peer for the duration in seconds, or
+// permanently if the duration is zero.
+func (c *Client) BanPeer(who peer.ID, duration uint64, reason string) error {
+	const (
+		method = "net_banPeer"
+	)
+
+	req := &rpctypes.BanPeerRequest{
+		PeerID:   who,
+		Duration: duration,
+		Reason:   reason,
+	}
+
+	return c.Post(method, req, nil)
+}
+
+// UnbanPeer calls net_unbanPeer.
+func (c *Client) UnbanPeer(who peer.ID) error {
+	const (
+		method = "net_unbanPeer"
+	)
+
+	req := &rpctypes.UnbanPeerRequest{
+		PeerID: who,
+	}
+
+	return c.Post(method, req, nil)
+}
+
+// ListBanned calls net_listBanned to get the banned peers.
+func (c *Client) ListBanned() (*rpctypes.ListBannedResponse, error) {
+	const (
+		method = "net_listBanned"
+	)
+
+	res := &rpctypes.ListBannedResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
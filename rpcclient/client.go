@@ -8,9 +8,14 @@ package rpcclient
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/rpc/v2/json2"
@@ -35,55 +40,193 @@ var (
 
 // Client primarily exists to be a JSON-RPC client to swapd instances, but it can be used
 // to POST JSON-RPC requests to any JSON-RPC server. Its current use case assumes swapd is
-// running on the local host of a single use system. TLS and authentication are not
-// currently supported.
+// running on the local host of a single use system.
 type Client struct {
-	ctx      context.Context
-	endpoint string
+	ctx        context.Context
+	endpoint   string
+	authToken  string
+	httpClient *http.Client
+	retry      *RetryPolicy
+	invoke     CallFunc
+	profile    string
+}
+
+// Options are the optional settings of a Client.
+type Options struct {
+	// AuthToken is the bearer token that authenticates requests to swapd. No token is
+	// sent if it is empty.
+	AuthToken string
+
+	// TLSConfig is used to verify https and wss endpoints. The system's trusted
+	// certificates are used if it is nil.
+	TLSConfig *tls.Config
+
+	// UnixSocket, if set, is the path of swapd's unix socket, which the client
+	// connects to whatever the host of the endpoint is.
+	UnixSocket string
+
+	// Retry, if set, retries calls that fail with a transient error. Calls are not
+	// retried if it is nil.
+	Retry *RetryPolicy
+
+	// Middleware wraps each call, the first middleware being the outermost.
+	Middleware []Middleware
+
+	// Profile, if set, is the profile of swapd that funds the offers made and taken
+	// with the client, instead of the default profile.
+	Profile string
 }
 
 // NewClient creates a new JSON-RPC client for the specified endpoint. The passed context
-// is used for the full lifetime of the client.
+// is used for the full lifetime of the client, unless calls are made with a client
+// returned by WithContext.
 func NewClient(ctx context.Context, endpoint string) *Client {
-	return &Client{
-		ctx:      ctx,
-		endpoint: endpoint,
+	return NewClientWithOptions(ctx, endpoint, nil)
+}
+
+// NewClientWithOptions creates a new JSON-RPC client for the specified endpoint with the
+// optional settings. The passed context is used for the full lifetime of the client.
+func NewClientWithOptions(ctx context.Context, endpoint string, opts *Options) *Client {
+	c := &Client{
+		ctx:        ctx,
+		endpoint:   endpoint,
+		httpClient: httpClient,
+	}
+	c.invoke = c.send
+
+	if opts == nil {
+		return c
+	}
+
+	c.authToken = opts.AuthToken
+	c.retry = opts.Retry
+	c.profile = opts.Profile
+	c.invoke = chainMiddleware(opts.Middleware, c.send)
+	if opts.TLSConfig == nil && opts.UnixSocket == "" {
+		return c
+	}
+
+	t := transport.Clone()
+	t.TLSClientConfig = opts.TLSConfig
+	if opts.UnixSocket != "" {
+		t.DialContext = unixSocketDialer(opts.UnixSocket)
+	}
+	c.httpClient = &http.Client{
+		Transport: t,
+		Timeout:   httpClientTimeout,
+	}
+
+	return c
+}
+
+// WithContext returns a copy of the client whose calls use the passed context instead
+// of the client's context, so a caller can apply a timeout or cancellation to the
+// calls made with the copy. For example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+//	defer cancel()
+//	resp, err := c.WithContext(ctx).Balances(nil)
+func (c *Client) WithContext(ctx context.Context) *Client {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+// unixSocketDialer returns a dial function that connects to the unix socket at the
+// path, whatever the requested address is.
+func unixSocketDialer(socketPath string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socketPath)
 	}
 }
 
 // Post makes a JSON-RPC call to the client's endpoint, serializing any passed request
 // object and deserializing any passed response object from the POST response body. Nil
 // can be passed as the request or response when no data needs to be serialized or
-// deserialized respectively.
+// deserialized respectively. The call is cancelled when the client's context is done.
+// Calls that fail with a transient error are retried if the client has a RetryPolicy.
 func (c *Client) Post(method string, request any, response any) error {
-	data, err := json2.EncodeClientRequest(method, request)
+	call := &Call{
+		Method:  method,
+		Request: request,
+		Header:  http.Header{"Content-Type": []string{contentTypeJSON}},
+	}
+	if c.authToken != "" {
+		call.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+
+	return c.invoke(c.ctx, call, response)
+}
+
+// send is the CallFunc wrapped by the client's middleware, which posts the call until
+// it succeeds or can't be retried.
+func (c *Client) send(ctx context.Context, call *Call, response any) error {
+	data, err := json2.EncodeClientRequest(call.Method, call.Request)
 	if err != nil {
 		return err
 	}
 
-	httpReq, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+	for retry := 0; ; retry++ {
+		err = c.post(ctx, call, data, response)
+		if err == nil || c.retry == nil || retry >= c.retry.MaxRetries || !c.retry.canRetry(call.Method, err) {
+			return err
+		}
+
+		if sleepErr := sleepContext(ctx, c.retry.backoff(retry)); sleepErr != nil {
+			return err
+		}
 	}
-	httpReq.Header.Set("Content-Type", contentTypeJSON)
+}
+
+// post makes a single attempt of the call with the encoded request.
+func (c *Client) post(ctx context.Context, call *Call, data []byte, response any) error {
+	method := call.Method
 
-	ctx, cancel := context.WithTimeout(c.ctx, callTimeout)
+	timeoutCtx, cancel := context.WithTimeout(ctx, callTimeout)
 	defer cancel()
-	httpReq = httpReq.WithContext(ctx)
 
-	httpResp, err := httpClient.Do(httpReq)
+	httpReq, err := http.NewRequestWithContext(timeoutCtx, http.MethodPost, c.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header = call.Header.Clone()
+
+	httpResp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("failed to post %q request: %w", method, err)
+		return newTransportError(ctx, fmt.Errorf("failed to post %q request: %w", method, err))
 	}
 
 	defer func() { _ = httpResp.Body.Close() }()
 
-	if response == nil {
-		return nil
+	// Authentication failures are answered with plain text instead of a JSON-RPC
+	// error
+	if httpResp.StatusCode == http.StatusUnauthorized || httpResp.StatusCode == http.StatusForbidden {
+		body, _ := io.ReadAll(httpResp.Body)
+		return fmt.Errorf("%q request rejected: %s", method, strings.TrimSpace(string(body)))
+	}
+
+	// Server errors, like those of a restarting swapd behind a proxy, aren't JSON-RPC
+	// responses either
+	if httpResp.StatusCode >= http.StatusInternalServerError {
+		return &transientError{
+			err:  fmt.Errorf("%q request failed with status %q", method, httpResp.Status),
+			sent: true,
+		}
+	}
+
+	// The result of a call without a response object is discarded, but its error
+	// is still returned
+	discardResult := response == nil
+	if discardResult {
+		response = new(json.RawMessage)
 	}
 
 	if err = json2.DecodeClientResponse(httpResp.Body, response); err != nil {
-		return fmt.Errorf("failed to read %q response: %w", method, err)
+		if discardResult && errors.Is(err, json2.ErrNullResult) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %q response: %w", method, newError(err))
 	}
 
 	return nil
@@ -15,6 +15,36 @@ func (c *Client) Shutdown() error {
 	return nil
 }
 
+// ShutdownDrain refuses new swaps and shuts swapd down once the ongoing swaps have
+// locked funds or completed, waiting at most timeoutSeconds, or the server's default
+// if zero
+func (c *Client) ShutdownDrain(timeoutSeconds uint64) error {
+	const (
+		method = "daemon_shutdown"
+	)
+	req := &rpc.ShutdownRequest{
+		Drain:   true,
+		Timeout: timeoutSeconds,
+	}
+	return c.Post(method, req, nil)
+}
+
+// Pause stops swapd from starting new swaps, while ongoing swaps continue
+func (c *Client) Pause() error {
+	const (
+		method = "daemon_pause"
+	)
+	return c.Post(method, nil, nil)
+}
+
+// Resume lets swapd start new swaps again after a pause
+func (c *Client) Resume() error {
+	const (
+		method = "daemon_resume"
+	)
+	return c.Post(method, nil, nil)
+}
+
 // Version returns version & misc info about swapd and its dependencies
 func (c *Client) Version() (*rpc.VersionResponse, error) {
 	const (
@@ -26,3 +56,16 @@ func (c *Client) Version() (*rpc.VersionResponse, error) {
 	}
 	return resp, nil
 }
+
+// Status returns the sync state of swapd's endpoints and whether it is ready to
+// accept swaps
+func (c *Client) Status() (*rpc.StatusResponse, error) {
+	const (
+		method = "daemon_status"
+	)
+	resp := &rpc.StatusResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
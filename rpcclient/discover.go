@@ -4,10 +4,12 @@
 package rpcclient
 
 import (
+	"github.com/cockroachdb/apd/v3"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
 )
 
 // Discover calls net_discover.
@@ -29,15 +31,25 @@ func (c *Client) Discover(provides string, searchTime uint64) ([]peer.ID, error)
 	return res.PeerIDs, nil
 }
 
-// QueryAll calls net_queryAll.
-func (c *Client) QueryAll(provides coins.ProvidesCoin, searchTime uint64) ([]*rpctypes.PeerWithOffers, error) {
+// QueryAll calls net_queryAll. Only the offers matching the filter are returned,
+// unless the filter is nil.
+func (c *Client) QueryAll(
+	provides coins.ProvidesCoin,
+	searchTime uint64,
+	filter *types.OfferFilter,
+) ([]*rpctypes.PeerWithOffers, error) {
 	const (
 		method = "net_queryAll"
 	)
 
 	req := &rpctypes.QueryAllRequest{
-		Provides:   string(provides),
-		SearchTime: searchTime,
+		DiscoverRequest: rpctypes.DiscoverRequest{
+			Provides:   string(provides),
+			SearchTime: searchTime,
+		},
+	}
+	if filter != nil {
+		req.OfferFilter = *filter
 	}
 	res := &rpctypes.QueryAllResponse{}
 
@@ -47,3 +59,37 @@ func (c *Client) QueryAll(provides coins.ProvidesCoin, searchTime uint64) ([]*rp
 
 	return res.PeersWithOffers, nil
 }
+
+// DiscoverRelayers calls net_discoverRelayers.
+func (c *Client) DiscoverRelayers(value *apd.Decimal, searchTime uint64) ([]*rpctypes.RelayerInfo, error) {
+	const (
+		method = "net_discoverRelayers"
+	)
+
+	req := &rpctypes.DiscoverRelayersRequest{
+		Value:      value,
+		SearchTime: searchTime,
+	}
+	res := &rpctypes.DiscoverRelayersResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res.Relayers, nil
+}
+
+// RelayerStats calls net_relayerStats.
+func (c *Client) RelayerStats() ([]*rpctypes.RelayerStats, error) {
+	const (
+		method = "net_relayerStats"
+	)
+
+	res := &rpctypes.RelayerStatsResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res.Relayers, nil
+}
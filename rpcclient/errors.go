@@ -0,0 +1,70 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"errors"
+
+	"github.com/gorilla/rpc/v2/json2"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// Errors of swapd's methods that callers can check for with errors.Is. They match
+// the *Error returned by a call when swapd answers with their error code.
+var (
+	ErrOfferNotFound       = errors.New("offer not found")
+	ErrSwapNotFound        = errors.New("swap not found")
+	ErrSwapOngoing         = errors.New("swap is ongoing")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrPaused              = errors.New("swapd is paused")
+	ErrDraining            = errors.New("swapd is draining for shutdown")
+)
+
+var errorsByCode = map[rpctypes.ErrCode]error{
+	rpctypes.ErrCodeOfferNotFound:       ErrOfferNotFound,
+	rpctypes.ErrCodeSwapNotFound:        ErrSwapNotFound,
+	rpctypes.ErrCodeSwapOngoing:         ErrSwapOngoing,
+	rpctypes.ErrCodeInsufficientBalance: ErrInsufficientBalance,
+	rpctypes.ErrCodePaused:              ErrPaused,
+	rpctypes.ErrCodeDraining:            ErrDraining,
+}
+
+// Error is an error answered by swapd to a call. For example, callers can check
+// whether an offer was already taken with:
+//
+//	if errors.Is(err, rpcclient.ErrOfferNotFound) {
+//		...
+//	}
+type Error struct {
+	Code    rpctypes.ErrCode
+	Message string
+	Data    any
+}
+
+// Error returns the message of the error.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is returns whether the target is the sentinel error of the error's code.
+func (e *Error) Is(target error) bool {
+	sentinel, ok := errorsByCode[e.Code]
+	return ok && target == sentinel
+}
+
+// newError returns the *Error of a JSON-RPC error answered by swapd, or the error
+// itself if it isn't one.
+func newError(err error) error {
+	var jsonErr *json2.Error
+	if !errors.As(err, &jsonErr) {
+		return err
+	}
+
+	return &Error{
+		Code:    rpctypes.ErrCode(jsonErr.Code),
+		Message: jsonErr.Message,
+		Data:    jsonErr.Data,
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newErrorServer returns a server that answers every request with the JSON-RPC error.
+func newErrorServer(t *testing.T, jsonErr string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","error":` + jsonErr + `,"id":0}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Post_typedError(t *testing.T) {
+	server := newErrorServer(t, `{"code":-32001,"message":"peer does not have offer with given ID"}`)
+	c := NewClient(context.Background(), server.URL)
+
+	err := c.Post("net_takeOffer", nil, &struct{}{})
+	require.ErrorIs(t, err, ErrOfferNotFound)
+	require.False(t, errors.Is(err, ErrSwapNotFound))
+	require.ErrorContains(t, err, "peer does not have offer with given ID")
+
+	var rpcErr *Error
+	require.ErrorAs(t, err, &rpcErr)
+	require.Equal(t, "peer does not have offer with given ID", rpcErr.Message)
+
+	// the error of a call without a response object is returned too
+	require.ErrorIs(t, c.Post("net_takeOffer", nil, nil), ErrOfferNotFound)
+}
+
+func TestClient_Post_untypedError(t *testing.T) {
+	server := newErrorServer(t, `{"code":-32000,"message":"something failed"}`)
+	c := NewClient(context.Background(), server.URL)
+
+	err := c.Post("daemon_version", nil, &struct{}{})
+	require.ErrorContains(t, err, "something failed")
+	for _, sentinel := range errorsByCode {
+		require.False(t, errors.Is(err, sentinel))
+	}
+}
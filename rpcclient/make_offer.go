@@ -28,6 +28,34 @@ func (c *Client) MakeOffer(
 		ExchangeRate: exchangeRate,
 		EthAsset:     ethAsset,
 		UseRelayer:   useRelayer,
+		Profile:      c.profile,
+	}
+	res := &rpctypes.MakeOfferResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// MakePeggedOffer calls net_makeOffer with an exchange rate that is pegged to the
+// market price of a price source.
+func (c *Client) MakePeggedOffer(
+	min, max *apd.Decimal,
+	peg *types.OfferPeg,
+	useRelayer bool,
+) (*rpctypes.MakeOfferResponse, error) {
+	const (
+		method = "net_makeOffer"
+	)
+
+	req := &rpctypes.MakeOfferRequest{
+		MinAmount:  min,
+		MaxAmount:  max,
+		UseRelayer: useRelayer,
+		Profile:    c.profile,
+		Peg:        peg,
 	}
 	res := &rpctypes.MakeOfferResponse{}
 
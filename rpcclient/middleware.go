@@ -0,0 +1,46 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// Call is a JSON-RPC call made by a Client.
+type Call struct {
+	Method string
+	// Request is the request object of the call, which is serialized after the
+	// middleware ran.
+	Request any
+	// Header is the header of the HTTP request, which middleware can add headers to.
+	Header http.Header
+}
+
+// CallFunc makes a call, deserializing its result into the response object, which can
+// be nil.
+type CallFunc func(ctx context.Context, call *Call, response any) error
+
+// Middleware wraps the calls of a Client, to observe or decorate them. The next
+// CallFunc makes the call, including its retries. For example, a middleware logging
+// the duration of calls:
+//
+//	func logCalls(next rpcclient.CallFunc) rpcclient.CallFunc {
+//		return func(ctx context.Context, call *rpcclient.Call, response any) error {
+//			start := time.Now()
+//			err := next(ctx, call, response)
+//			log.Printf("%s took %s, err=%v", call.Method, time.Since(start), err)
+//			return err
+//		}
+//	}
+type Middleware func(next CallFunc) CallFunc
+
+// chainMiddleware returns the CallFunc of the middleware wrapping the call function,
+// the first middleware being the outermost.
+func chainMiddleware(middleware []Middleware, call CallFunc) CallFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		call = middleware[i](call)
+	}
+	return call
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Post_middleware(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":{},"id":0}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next CallFunc) CallFunc {
+			return func(ctx context.Context, call *Call, response any) error {
+				calls = append(calls, name+" "+call.Method)
+				return next(ctx, call, response)
+			}
+		}
+	}
+	setHeader := func(next CallFunc) CallFunc {
+		return func(ctx context.Context, call *Call, response any) error {
+			call.Header.Set("X-Client", "test")
+			return next(ctx, call, response)
+		}
+	}
+
+	c := NewClientWithOptions(context.Background(), server.URL, &Options{
+		AuthToken:  "token",
+		Middleware: []Middleware{record("outer"), setHeader, record("inner")},
+	})
+	require.NoError(t, c.Post("daemon_version", nil, &struct{}{}))
+	require.Equal(t, []string{"outer daemon_version", "inner daemon_version"}, calls)
+
+	header := <-headers
+	require.Equal(t, "test", header.Get("X-Client"))
+	require.Equal(t, "Bearer token", header.Get("Authorization"))
+}
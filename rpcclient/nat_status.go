@@ -0,0 +1,24 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// NATStatus calls net_natStatus to get whether a swapd instance is publicly
+// reachable, and its relay addresses if it is not.
+func (c *Client) NATStatus() (*rpctypes.NATStatusResponse, error) {
+	const (
+		method = "net_natStatus"
+	)
+
+	res := &rpctypes.NATStatusResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
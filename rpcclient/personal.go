@@ -4,9 +4,9 @@
 package rpcclient
 
 import (
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 
-	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/rpc"
 )
@@ -43,12 +43,11 @@ func (c *Client) GetSwapTimeout() (*rpc.GetSwapTimeoutResponse, error) {
 }
 
 // TokenInfo calls personal_tokenInfo
-func (c *Client) TokenInfo(tokenAddr ethcommon.Address) (*coins.ERC20TokenInfo, error) {
+func (c *Client) TokenInfo(tokenAddr ethcommon.Address) (*rpctypes.TokenInfoResponse, error) {
 	const (
 		method = "personal_tokenInfo"
 	)
 
-	// Note: coins.ERC20TokenInfo and rpctypes.TokenInfoRequest are aliases
 	request := &rpctypes.TokenInfoRequest{TokenAddr: tokenAddr}
 	tokenInfo := new(rpctypes.TokenInfoResponse)
 
@@ -59,6 +58,20 @@ func (c *Client) TokenInfo(tokenAddr ethcommon.Address) (*coins.ERC20TokenInfo,
 	return tokenInfo, nil
 }
 
+// SupportedTokens calls personal_supportedTokens.
+func (c *Client) SupportedTokens() (*rpc.SupportedTokensResponse, error) {
+	const (
+		method = "personal_supportedTokens"
+	)
+
+	resp := &rpc.SupportedTokensResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 // Balances calls personal_balances.
 func (c *Client) Balances(request *rpctypes.BalancesRequest) (*rpctypes.BalancesResponse, error) {
 	const (
@@ -72,3 +85,232 @@ func (c *Client) Balances(request *rpctypes.BalancesRequest) (*rpctypes.Balances
 
 	return balances, nil
 }
+
+// Profiles calls personal_profiles.
+func (c *Client) Profiles() ([]*rpctypes.Profile, error) {
+	const (
+		method = "personal_profiles"
+	)
+
+	resp := &rpctypes.ProfilesResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Profiles, nil
+}
+
+// IncomingTransfers calls personal_incomingTransfers.
+func (c *Client) IncomingTransfers(minHeight uint64) ([]*rpctypes.IncomingTransfer, error) {
+	const (
+		method = "personal_incomingTransfers"
+	)
+
+	req := &rpctypes.IncomingTransfersRequest{
+		MinHeight: minHeight,
+	}
+	resp := &rpctypes.IncomingTransfersResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Transfers, nil
+}
+
+// RestoreEthKey calls personal_restoreEthKey.
+func (c *Client) RestoreEthKey(mnemonic string) (*rpc.RestoreEthKeyResponse, error) {
+	const (
+		method = "personal_restoreEthKey"
+	)
+
+	req := &rpc.RestoreEthKeyRequest{
+		Mnemonic: mnemonic,
+	}
+	resp := &rpc.RestoreEthKeyResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// MoneroNodeStatus calls personal_moneroNodeStatus.
+func (c *Client) MoneroNodeStatus() (*rpc.MoneroNodeStatusResponse, error) {
+	const (
+		method = "personal_moneroNodeStatus"
+	)
+
+	resp := &rpc.MoneroNodeStatusResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// RestoreXMRWallet calls personal_restoreXMRWallet.
+func (c *Client) RestoreXMRWallet(seed string, restoreHeight uint64) (*rpc.RestoreXMRWalletResponse, error) {
+	const (
+		method = "personal_restoreXMRWallet"
+	)
+
+	req := &rpc.RestoreXMRWalletRequest{
+		Seed:          seed,
+		RestoreHeight: restoreHeight,
+	}
+	resp := &rpc.RestoreXMRWalletResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Unlock calls personal_unlock.
+func (c *Client) Unlock(password string) error {
+	const (
+		method = "personal_unlock"
+	)
+
+	req := &rpc.UnlockRequest{
+		Password: password,
+	}
+
+	return c.Post(method, req, nil)
+}
+
+// TokenAllowance calls personal_tokenAllowance.
+func (c *Client) TokenAllowance(tokenAddr ethcommon.Address) (*rpc.TokenAllowanceResponse, error) {
+	const (
+		method = "personal_tokenAllowance"
+	)
+
+	req := &rpc.TokenAllowanceRequest{
+		TokenAddr: tokenAddr,
+	}
+	resp := &rpc.TokenAllowanceResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ApproveToken calls personal_approveToken.
+func (c *Client) ApproveToken(tokenAddr ethcommon.Address, amount *apd.Decimal) (*rpc.ApproveTokenResponse, error) {
+	const (
+		method = "personal_approveToken"
+	)
+
+	req := &rpc.ApproveTokenRequest{
+		TokenAddr: tokenAddr,
+		Amount:    amount,
+	}
+	resp := &rpc.ApproveTokenResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// RevokeTokenAllowance calls personal_revokeTokenAllowance.
+func (c *Client) RevokeTokenAllowance(tokenAddr ethcommon.Address) (*rpc.RevokeTokenAllowanceResponse, error) {
+	const (
+		method = "personal_revokeTokenAllowance"
+	)
+
+	req := &rpc.RevokeTokenAllowanceRequest{
+		TokenAddr: tokenAddr,
+	}
+	resp := &rpc.RevokeTokenAllowanceResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// TransferETH calls personal_transferETH.
+func (c *Client) TransferETH(req *rpc.TransferETHRequest) (*rpc.TransferETHResponse, error) {
+	const (
+		method = "personal_transferETH"
+	)
+
+	resp := &rpc.TransferETHResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// TransferXMR calls personal_transferXMR.
+func (c *Client) TransferXMR(req *rpc.TransferXMRRequest) (*rpc.TransferXMRResponse, error) {
+	const (
+		method = "personal_transferXMR"
+	)
+
+	resp := &rpc.TransferXMRResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// SweepXMR calls personal_sweepXMR.
+func (c *Client) SweepXMR(req *rpc.SweepXMRRequest) (*rpc.SweepXMRResponse, error) {
+	const (
+		method = "personal_sweepXMR"
+	)
+
+	resp := &rpc.SweepXMRResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// PlanConsolidation calls personal_planConsolidation.
+func (c *Client) PlanConsolidation(req *rpc.PlanConsolidationRequest) (*rpc.PlanConsolidationResponse, error) {
+	const (
+		method = "personal_planConsolidation"
+	)
+
+	resp := &rpc.PlanConsolidationResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ClearTokenInfoCache calls personal_clearTokenInfoCache.
+func (c *Client) ClearTokenInfoCache(tokenAddr *ethcommon.Address) (*rpc.ClearTokenInfoCacheResponse, error) {
+	const (
+		method = "personal_clearTokenInfoCache"
+	)
+
+	req := &rpc.ClearTokenInfoCacheRequest{
+		TokenAddr: tokenAddr,
+	}
+	resp := &rpc.ClearTokenInfoCacheResponse{}
+
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
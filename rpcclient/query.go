@@ -7,10 +7,12 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
 )
 
-// Query calls net_query.
-func (c *Client) Query(who peer.ID) (*rpctypes.QueryPeerResponse, error) {
+// Query calls net_query. Only the offers matching the filter are returned, unless
+// the filter is nil.
+func (c *Client) Query(who peer.ID, filter *types.OfferFilter) (*rpctypes.QueryPeerResponse, error) {
 	const (
 		method = "net_queryPeer"
 	)
@@ -18,6 +20,9 @@ func (c *Client) Query(who peer.ID) (*rpctypes.QueryPeerResponse, error) {
 	req := &rpctypes.QueryPeerRequest{
 		PeerID: who,
 	}
+	if filter != nil {
+		req.OfferFilter = *filter
+	}
 	res := &rpctypes.QueryPeerResponse{}
 
 	if err := c.Post(method, req, res); err != nil {
@@ -26,3 +31,21 @@ func (c *Client) Query(who peer.ID) (*rpctypes.QueryPeerResponse, error) {
 
 	return res, nil
 }
+
+// PeerReputation calls net_peerReputation.
+func (c *Client) PeerReputation(who peer.ID) (*rpctypes.PeerReputation, error) {
+	const (
+		method = "net_peerReputation"
+	)
+
+	req := &rpctypes.PeerReputationRequest{
+		PeerID: who,
+	}
+	res := &rpctypes.PeerReputationResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
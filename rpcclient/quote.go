@@ -0,0 +1,32 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+// Quote calls net_quote.
+func (c *Client) Quote(maker peer.ID, offerID types.Hash, providesAmount *apd.Decimal) (*rpc.QuoteResponse, error) {
+	const (
+		method = "net_quote"
+	)
+
+	req := &rpc.QuoteRequest{
+		PeerID:         maker,
+		OfferID:        offerID,
+		ProvidesAmount: providesAmount,
+	}
+	res := &rpc.QuoteResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
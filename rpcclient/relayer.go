@@ -0,0 +1,52 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// RelayerEarnings calls relayer_stats.
+func (c *Client) RelayerEarnings() (*rpctypes.RelayerEarningsResponse, error) {
+	const (
+		method = "relayer_stats"
+	)
+
+	res := &rpctypes.RelayerEarningsResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RelayAccessList calls relayer_getAccessList.
+func (c *Client) RelayAccessList() (*types.RelayAccessList, error) {
+	const (
+		method = "relayer_getAccessList"
+	)
+
+	res := &types.RelayAccessList{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SetRelayAccessList calls relayer_setAccessList.
+func (c *Client) SetRelayAccessList(list *types.RelayAccessList) error {
+	const (
+		method = "relayer_setAccessList"
+	)
+
+	if err := c.Post(method, list, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
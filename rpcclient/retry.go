@@ -0,0 +1,108 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+const (
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// RetryPolicy configures the automatic retries of calls that fail with a transient
+// error: a transport error, like a refused or reset connection, or a 5xx response.
+// Calls of methods that change swapd's state are only retried if the request never
+// reached swapd, as retrying them could apply the change twice, unless RetryMutating
+// is set.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a failed call is retried
+	MaxRetries int
+
+	// InitialBackoff is the wait before the first retry, which doubles before each
+	// following retry up to MaxBackoff. They default to 500ms and 10s.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// RetryMutating also retries the calls of methods that change swapd's state after
+	// failures where the request may have reached swapd.
+	RetryMutating bool
+}
+
+// backoff returns the wait before the retry, counting from zero.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	for i := 0; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// canRetry returns whether the failed call of the method can be retried.
+func (p *RetryPolicy) canRetry(method string, err error) bool {
+	var tErr *transientError
+	if !errors.As(err, &tErr) {
+		return false
+	}
+
+	return !tErr.sent || p.RetryMutating || rpc.RequiredScope(method) == rpc.ScopeRead
+}
+
+// transientError is the error of a call that may succeed if it is retried.
+type transientError struct {
+	err  error
+	sent bool // whether the request may have reached the server
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// newTransportError returns the error of a request that failed without a response,
+// which is transient unless the call was cancelled.
+func newTransportError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	// the request was never sent if the connection couldn't be established
+	var opErr *net.OpError
+	sent := !errors.As(err, &opErr) || opErr.Op != "dial"
+
+	return &transientError{err: err, sent: sent}
+}
+
+// sleepContext waits for the duration, or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
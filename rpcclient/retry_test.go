@@ -0,0 +1,80 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer returns a server that answers the first failures requests with a
+// 503 status, and the following ones with an empty JSON-RPC result.
+func newFlakyServer(t *testing.T, failures int32) (*httptest.Server, *atomic.Int32) {
+	requests := new(atomic.Int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":{},"id":0}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestClient_Post_retry(t *testing.T) {
+	server, requests := newFlakyServer(t, 2)
+	c := NewClientWithOptions(context.Background(), server.URL, &Options{
+		Retry: &RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond},
+	})
+
+	require.NoError(t, c.Post("daemon_version", nil, &struct{}{}))
+	require.Equal(t, int32(3), requests.Load())
+}
+
+func TestClient_Post_retryExhausted(t *testing.T) {
+	server, requests := newFlakyServer(t, 3)
+	c := NewClientWithOptions(context.Background(), server.URL, &Options{
+		Retry: &RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond},
+	})
+
+	err := c.Post("daemon_version", nil, &struct{}{})
+	require.ErrorContains(t, err, "503")
+	require.Equal(t, int32(2), requests.Load())
+}
+
+func TestClient_Post_noRetryOfMutatingCall(t *testing.T) {
+	server, requests := newFlakyServer(t, 1)
+	policy := &RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}
+	c := NewClientWithOptions(context.Background(), server.URL, &Options{Retry: policy})
+
+	// the request reached the server, so the offer may have been made
+	require.Error(t, c.Post("net_makeOffer", nil, &struct{}{}))
+	require.Equal(t, int32(1), requests.Load())
+
+	policy.RetryMutating = true
+	requests.Store(0)
+	require.NoError(t, c.Post("net_makeOffer", nil, &struct{}{}))
+	require.Equal(t, int32(2), requests.Load())
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	require.Equal(t, time.Second, p.backoff(0))
+	require.Equal(t, 2*time.Second, p.backoff(1))
+	require.Equal(t, 4*time.Second, p.backoff(2))
+	require.Equal(t, 5*time.Second, p.backoff(3))
+	require.Equal(t, 5*time.Second, p.backoff(100))
+
+	p = new(RetryPolicy)
+	require.Equal(t, defaultInitialBackoff, p.backoff(0))
+	require.Equal(t, defaultMaxBackoff, p.backoff(100))
+}
@@ -0,0 +1,24 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// NetStats calls net_stats to get the traffic with peers and the number of messages
+// that they sent, by protocol and by peer.
+func (c *Client) NetStats() (*rpctypes.NetStatsResponse, error) {
+	const (
+		method = "net_stats"
+	)
+
+	res := &rpctypes.NetStatsResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
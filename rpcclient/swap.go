@@ -95,3 +95,85 @@ func (c *Client) SuggestedExchangeRate() (*rpc.SuggestedExchangeRateResponse, er
 
 	return res, nil
 }
+
+// GetContractEvents calls swap_getContractEvents
+func (c *Client) GetContractEvents(swapID *types.Hash) (*rpc.GetContractEventsResponse, error) {
+	const (
+		method = "swap_getContractEvents"
+	)
+
+	req := &rpc.GetContractEventsRequest{
+		SwapID: swapID,
+	}
+	res := &rpc.GetContractEventsResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetPastSwaps calls swap_getPast with a filter and pagination, returning the
+// page of past swaps after the request's cursor
+func (c *Client) GetPastSwaps(req *rpc.GetPastRequest) (*rpc.GetPastResponse, error) {
+	const (
+		method = "swap_getPast"
+	)
+
+	res := &rpc.GetPastResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ExportSwaps calls swap_export
+func (c *Client) ExportSwaps(format string) (*rpc.ExportResponse, error) {
+	const (
+		method = "swap_export"
+	)
+
+	req := &rpc.ExportRequest{
+		Format: format,
+	}
+
+	res := &rpc.ExportResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// EstimateFees calls swap_estimateFees
+func (c *Client) EstimateFees(req *rpc.EstimateFeesRequest) (*rpc.EstimateFeesResponse, error) {
+	const (
+		method = "swap_estimateFees"
+	)
+
+	res := &rpc.EstimateFeesResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SetXMRPriority calls swap_setXMRPriority
+func (c *Client) SetXMRPriority(offerID types.Hash, priority string) error {
+	const (
+		method = "swap_setXMRPriority"
+	)
+
+	req := &rpc.SetXMRPriorityRequest{
+		OfferID:  offerID,
+		Priority: priority,
+	}
+
+	return c.Post(method, req, nil)
+}
@@ -21,6 +21,7 @@ func (c *Client) TakeOffer(peerID peer.ID, offerID types.Hash, providesAmount *a
 		PeerID:         peerID,
 		OfferID:        offerID,
 		ProvidesAmount: providesAmount,
+		Profile:        c.profile,
 	}
 
 	if err := c.Post(method, req, nil); err != nil {
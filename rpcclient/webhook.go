@@ -0,0 +1,54 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/rpc"
+	"github.com/athanorlabs/atomic-swap/webhook"
+)
+
+// AddWebhook calls webhook_add.
+func (c *Client) AddWebhook(hook *webhook.Webhook) error {
+	const (
+		method = "webhook_add"
+	)
+
+	if err := c.Post(method, hook, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveWebhook calls webhook_remove.
+func (c *Client) RemoveWebhook(hookURL string) error {
+	const (
+		method = "webhook_remove"
+	)
+
+	req := &rpc.RemoveWebhookRequest{
+		URL: hookURL,
+	}
+
+	if err := c.Post(method, req, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Webhooks calls webhook_list.
+func (c *Client) Webhooks() ([]*webhook.Webhook, error) {
+	const (
+		method = "webhook_list"
+	)
+
+	res := &rpc.ListWebhooksResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res.Webhooks, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wsclient
+
+import (
+	"context"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// Conn sends the requests of a websocket client and reads the messages that swapd
+// sends back, which are the responses and the updates of subscriptions.
+type Conn interface {
+	WriteRequest(ctx context.Context, req *rpctypes.Request) error
+	ReadMessage(ctx context.Context) ([]byte, error)
+}
+
+// Middleware wraps the connection of a websocket client, to observe or decorate its
+// traffic. Middleware that only handles one direction can embed the next Conn. For
+// example, a middleware logging the methods called:
+//
+//	type logRequests struct {
+//		wsclient.Conn
+//	}
+//
+//	func (c *logRequests) WriteRequest(ctx context.Context, req *rpctypes.Request) error {
+//		log.Printf("calling %s", req.Method)
+//		return c.Conn.WriteRequest(ctx, req)
+//	}
+//
+//	opts.Middleware = []wsclient.Middleware{func(next wsclient.Conn) wsclient.Conn {
+//		return &logRequests{next}
+//	}}
+type Middleware func(next Conn) Conn
+
+// chainMiddleware returns the Conn of the middleware wrapping the connection, the
+// first middleware being the outermost.
+func chainMiddleware(middleware []Middleware, conn Conn) Conn {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		conn = middleware[i](conn)
+	}
+	return conn
+}
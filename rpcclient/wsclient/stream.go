@@ -0,0 +1,243 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const (
+	defaultMaxReconnects    = 5
+	defaultReconnectBackoff = time.Second
+	maxReconnectBackoff     = 30 * time.Second
+)
+
+// StreamOptions are the optional settings of a StatusStream.
+type StreamOptions struct {
+	Options
+
+	// MaxReconnects is the number of consecutive failed attempts to reconnect after
+	// which the stream ends. It defaults to 5.
+	MaxReconnects int
+
+	// ReconnectBackoff is the wait before the first attempt to reconnect, which
+	// doubles before each following attempt up to 30s. It defaults to 1s.
+	ReconnectBackoff time.Duration
+}
+
+// StatusStream delivers the status updates of a swap. Unlike the subscriptions of
+// WsClient, which end when their connection is lost, a stream reconnects to swapd and
+// resubscribes to the swap's status, so a swapd restart or a network hiccup doesn't
+// end it. The statuses of a swap that changes while the stream is reconnecting are
+// missed, except for the swap's exit status.
+type StatusStream struct {
+	ctx      context.Context
+	endpoint string
+	opts     StreamOptions
+	offerID  types.Hash
+	statusCh chan types.Status
+	err      error
+}
+
+// SubscribeSwapStatus returns a stream of the status updates of the swap of the
+// offer, which ends once the swap exits. If there is no swap with the given ID, the
+// stream ends with an error.
+func SubscribeSwapStatus(
+	ctx context.Context,
+	endpoint string,
+	opts *StreamOptions,
+	offerID types.Hash,
+) (*StatusStream, error) {
+	s := newStatusStream(ctx, endpoint, opts, offerID)
+
+	c, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.writeSubscribeSwapStatus(offerID); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	go s.run(c, nil)
+	return s, nil
+}
+
+// SubscribeTakeOffer takes the offer and returns a stream of the status updates of
+// its swap. Errors taking the offer are returned. The offer is never taken again
+// after reconnecting, the stream only resubscribes to the status of its swap.
+func SubscribeTakeOffer(
+	ctx context.Context,
+	endpoint string,
+	opts *StreamOptions,
+	peerID peer.ID,
+	offerID types.Hash,
+	providesAmount *apd.Decimal,
+) (*StatusStream, error) {
+	s := newStatusStream(ctx, endpoint, opts, offerID)
+
+	c, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := c.takeOffer(peerID, offerID, providesAmount)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	go s.run(c, &status)
+	return s, nil
+}
+
+func newStatusStream(ctx context.Context, endpoint string, opts *StreamOptions, offerID types.Hash) *StatusStream {
+	s := &StatusStream{
+		ctx:      ctx,
+		endpoint: endpoint,
+		offerID:  offerID,
+		statusCh: make(chan types.Status),
+	}
+
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.MaxReconnects <= 0 {
+		s.opts.MaxReconnects = defaultMaxReconnects
+	}
+	if s.opts.ReconnectBackoff <= 0 {
+		s.opts.ReconnectBackoff = defaultReconnectBackoff
+	}
+
+	return s
+}
+
+// Status returns the channel of the swap's status updates, which is closed when the
+// stream ends.
+func (s *StatusStream) Status() <-chan types.Status {
+	return s.statusCh
+}
+
+// Err returns why the stream ended once the channel returned by Status is closed. It
+// is nil if the stream ended because the swap exited.
+func (s *StatusStream) Err() error {
+	return s.err
+}
+
+func (s *StatusStream) dial() (*wsClient, error) {
+	return NewWsClientWithOptions(s.ctx, s.endpoint, &s.opts.Options)
+}
+
+// run delivers the statuses of the connection, starting with the first status if it
+// is set, and reconnects each time the connection is lost, until the swap exits or
+// the stream fails.
+func (s *StatusStream) run(c *wsClient, first *types.Status) {
+	defer close(s.statusCh)
+
+	if first != nil {
+		exited, err := s.deliver(*first)
+		if exited || err != nil {
+			c.Close()
+			s.err = err
+			return
+		}
+	}
+
+	for {
+		err := s.forward(c)
+		c.Close()
+
+		if ctxErr := s.ctx.Err(); ctxErr != nil {
+			s.err = ctxErr
+			return
+		}
+
+		// errors of the server, like an unknown swap, aren't fixed by reconnecting
+		var rpcErr *rpctypes.Error
+		if err == nil || errors.As(err, &rpcErr) {
+			s.err = err
+			return
+		}
+
+		log.Warnf("lost connection to swapd, reconnecting: %s", err)
+		c, err = s.reconnect()
+		if err != nil {
+			s.err = err
+			return
+		}
+	}
+}
+
+// forward delivers the statuses read from the connection until the swap exits, which
+// returns nil, or the read fails.
+func (s *StatusStream) forward(c *wsClient) error {
+	for {
+		status, err := c.readTakeOfferResponse()
+		if err != nil {
+			return err
+		}
+
+		exited, err := s.deliver(status)
+		if exited || err != nil {
+			return err
+		}
+	}
+}
+
+// deliver writes the status to the stream's channel, and returns whether the swap
+// exited.
+func (s *StatusStream) deliver(status types.Status) (bool, error) {
+	select {
+	case s.statusCh <- status:
+		return !status.IsOngoing(), nil
+	case <-s.ctx.Done():
+		return false, s.ctx.Err()
+	}
+}
+
+// reconnect dials swapd again and resubscribes to the swap's status. It fails once
+// the maximum number of attempts failed.
+func (s *StatusStream) reconnect() (*wsClient, error) {
+	backoff := s.opts.ReconnectBackoff
+
+	var err error
+	for attempt := 0; attempt < s.opts.MaxReconnects; attempt++ {
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return nil, s.ctx.Err()
+		}
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+
+		var c *wsClient
+		c, err = s.dial()
+		if err != nil {
+			log.Debugf("failed to reconnect to swapd: %s", err)
+			continue
+		}
+
+		if err = c.writeSubscribeSwapStatus(s.offerID); err != nil {
+			c.Close()
+			continue
+		}
+
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("failed to reconnect to swapd after %d attempts: %w", s.opts.MaxReconnects, err)
+}
@@ -7,8 +7,12 @@ package wsclient
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -27,9 +31,18 @@ var log = logging.Logger("rpcclient")
 // WsClient ...
 type WsClient interface {
 	Close()
+	WithContext(ctx context.Context) WsClient
 	Discover(provides string, searchTime uint64) ([]peer.ID, error)
-	Query(who peer.ID) (*rpctypes.QueryPeerResponse, error)
+	Query(who peer.ID, filter *types.OfferFilter) (*rpctypes.QueryPeerResponse, error)
 	SubscribeSwapStatus(id types.Hash) (<-chan types.Status, error)
+	SubscribeOffers(
+		provides string,
+		searchTime uint64,
+		interval uint64,
+		filter *types.OfferFilter,
+	) (<-chan *rpctypes.OfferBookUpdate, error)
+	SubscribeBalances(params *rpctypes.SubscribeBalancesRequest) (<-chan *rpctypes.BalanceEvent, error)
+	SubscribeMoneroSync(params *rpctypes.SubscribeMoneroSyncRequest) (<-chan *rpctypes.MoneroSyncEvent, error)
 	TakeOfferAndSubscribe(peerID peer.ID, offerID types.Hash, providesAmount *apd.Decimal) (
 		ch <-chan types.Status,
 		err error,
@@ -41,17 +54,94 @@ type WsClient interface {
 		ethAsset types.EthAsset,
 		useRelayer bool,
 	) (*rpctypes.MakeOfferResponse, <-chan types.Status, error)
+	MakePeggedOfferAndSubscribe(
+		min *apd.Decimal,
+		max *apd.Decimal,
+		peg *types.OfferPeg,
+		useRelayer bool,
+	) (*rpctypes.MakeOfferResponse, <-chan types.Status, error)
 }
 
 type wsClient struct {
+	ctx context.Context
+	*wsConn
+}
+
+// wsConn is the connection of a client, which is shared by the copies of the client
+// returned by WithContext.
+type wsConn struct {
 	wmu  sync.Mutex
 	rmu  sync.Mutex
 	conn *websocket.Conn
+
+	// wrapped is the connection wrapped by the client's middleware
+	wrapped Conn
+
+	// profile of swapd that funds the offers made and taken with the client
+	profile string
 }
 
-// NewWsClient ...
+// Options are the optional settings of a websocket client.
+type Options struct {
+	// AuthToken is the bearer token that authenticates the connection to swapd. No
+	// token is sent if it is empty.
+	AuthToken string
+
+	// TLSConfig is used to verify wss endpoints. The system's trusted certificates
+	// are used if it is nil.
+	TLSConfig *tls.Config
+
+	// UnixSocket, if set, is the path of swapd's unix socket, which the client
+	// connects to whatever the host of the endpoint is.
+	UnixSocket string
+
+	// Header holds additional headers of the request opening the connection.
+	Header http.Header
+
+	// Middleware wraps the connection, the first middleware being the outermost.
+	Middleware []Middleware
+
+	// Profile, if set, is the profile of swapd that funds the offers made and taken
+	// with the client, instead of the default profile.
+	Profile string
+}
+
+// NewWsClient returns a websocket client. The passed context is used to dial the
+// endpoint and for the full lifetime of the client, unless calls are made with a
+// client returned by WithContext.
 func NewWsClient(ctx context.Context, endpoint string) (*wsClient, error) { ///nolint:revive
-	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, endpoint, nil)
+	return NewWsClientWithOptions(ctx, endpoint, nil)
+}
+
+// NewWsClientWithOptions returns a websocket client with the optional settings.
+func NewWsClientWithOptions(ctx context.Context, endpoint string, opts *Options) (*wsClient, error) { ///nolint:revive
+	dialer := websocket.DefaultDialer
+	header := make(http.Header)
+	if opts != nil {
+		for key, values := range opts.Header {
+			header[key] = values
+		}
+		if opts.AuthToken != "" {
+			header.Set("Authorization", "Bearer "+opts.AuthToken)
+		}
+		if opts.TLSConfig != nil || opts.UnixSocket != "" {
+			dialer = &websocket.Dialer{
+				Proxy:            websocket.DefaultDialer.Proxy,
+				HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+				TLSClientConfig:  opts.TLSConfig,
+			}
+		}
+		if opts.UnixSocket != "" {
+			socketPath := opts.UnixSocket
+			dialer.Proxy = nil
+			dialer.NetDialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			}
+		}
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, endpoint, header)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial WS endpoint: %w", err)
 	}
@@ -60,8 +150,16 @@ func NewWsClient(ctx context.Context, endpoint string) (*wsClient, error) { ///n
 		return nil, err
 	}
 
+	c := &wsConn{conn: conn}
+	c.wrapped = c
+	if opts != nil {
+		c.wrapped = chainMiddleware(opts.Middleware, c)
+		c.profile = opts.Profile
+	}
+
 	return &wsClient{
-		conn: conn,
+		ctx:    ctx,
+		wsConn: c,
 	}, nil
 }
 
@@ -69,17 +167,57 @@ func (c *wsClient) Close() {
 	_ = c.conn.Close()
 }
 
+// WithContext returns a copy of the client whose calls use the passed context instead
+// of the client's context. The copy shares the client's connection. A call or a
+// subscription ends when its context is done, which leaves the connection unusable,
+// so the client must be closed after a call is cancelled.
+func (c *wsClient) WithContext(ctx context.Context) WsClient {
+	return &wsClient{
+		ctx:    ctx,
+		wsConn: c.wsConn,
+	}
+}
+
 func (c *wsClient) writeJSON(msg *rpctypes.Request) error {
+	return c.wrapped.WriteRequest(c.ctx, msg)
+}
+
+func (c *wsClient) read() ([]byte, error) {
+	return c.wrapped.ReadMessage(c.ctx)
+}
+
+// WriteRequest implements Conn.
+func (c *wsConn) WriteRequest(ctx context.Context, req *rpctypes.Request) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	c.wmu.Lock()
 	defer c.wmu.Unlock()
-	return c.conn.WriteJSON(msg)
+	return c.conn.WriteJSON(req)
 }
 
-func (c *wsClient) read() ([]byte, error) {
+// ReadMessage implements Conn.
+func (c *wsConn) ReadMessage(ctx context.Context) ([]byte, error) {
 	c.rmu.Lock()
 	defer c.rmu.Unlock()
+
+	// A done context interrupts the read by expiring its deadline
+	readDone := make(chan struct{})
+	defer close(readDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.conn.SetReadDeadline(time.Now())
+		case <-readDone:
+		}
+	}()
+
 	_, message, err := c.conn.ReadMessage()
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, err
 	}
 
@@ -132,10 +270,13 @@ func (c *wsClient) Discover(provides string, searchTime uint64) ([]peer.ID, erro
 	return dresp.PeerIDs, nil
 }
 
-func (c *wsClient) Query(id peer.ID) (*rpctypes.QueryPeerResponse, error) {
+func (c *wsClient) Query(id peer.ID, filter *types.OfferFilter) (*rpctypes.QueryPeerResponse, error) {
 	params := &rpctypes.QueryPeerRequest{
 		PeerID: id,
 	}
+	if filter != nil {
+		params.OfferFilter = *filter
+	}
 
 	bz, err := vjson.MarshalStruct(params)
 	if err != nil {
@@ -181,13 +322,61 @@ func (c *wsClient) Query(id peer.ID) (*rpctypes.QueryPeerResponse, error) {
 // SubscribeSwapStatus returns a channel that is written to each time the swap's status updates.
 // If there is no swap with the given ID, it returns an error.
 func (c *wsClient) SubscribeSwapStatus(id types.Hash) (<-chan types.Status, error) {
+	if err := c.writeSubscribeSwapStatus(id); err != nil {
+		return nil, err
+	}
+
+	respCh := make(chan types.Status)
+
+	go func() {
+		defer close(respCh)
+
+		for {
+			message, err := c.read()
+			if err != nil {
+				log.Warnf("failed to read websockets message: %s", err)
+				break
+			}
+
+			resp := new(rpctypes.Response)
+			err = vjson.UnmarshalStruct(message, resp)
+			if err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			if resp.Error != nil {
+				log.Warnf("websocket server returned error: %s", resp.Error)
+				break
+			}
+
+			log.Debugf("received message over websockets: %s", message)
+			statusResp := new(rpctypes.SubscribeSwapStatusResponse)
+			if err := vjson.UnmarshalStruct(resp.Result, statusResp); err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			status := statusResp.Status
+			respCh <- status
+			if !status.IsOngoing() {
+				return
+			}
+		}
+	}()
+
+	return respCh, nil
+}
+
+// writeSubscribeSwapStatus sends the request subscribing to the swap's status.
+func (c *wsClient) writeSubscribeSwapStatus(id types.Hash) error {
 	params := &rpctypes.SubscribeSwapStatusRequest{
 		OfferID: id,
 	}
 
 	bz, err := vjson.MarshalStruct(params)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req := &rpctypes.Request{
@@ -197,11 +386,45 @@ func (c *wsClient) SubscribeSwapStatus(id types.Hash) (<-chan types.Status, erro
 		ID:      0,
 	}
 
+	return c.writeJSON(req)
+}
+
+// SubscribeOffers returns a channel that is written to each time an offer of the
+// network's offer book is added, removed or updated. The offer book is refreshed
+// every interval seconds, or at the server's default interval if zero. Only the
+// offers matching the filter are in the offer book, unless the filter is nil.
+func (c *wsClient) SubscribeOffers(
+	provides string,
+	searchTime uint64,
+	interval uint64,
+	filter *types.OfferFilter,
+) (<-chan *rpctypes.OfferBookUpdate, error) {
+	params := &rpctypes.SubscribeOffersRequest{
+		Provides:   provides,
+		SearchTime: searchTime,
+		Interval:   interval,
+	}
+	if filter != nil {
+		params.OfferFilter = *filter
+	}
+
+	bz, err := vjson.MarshalStruct(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  rpctypes.SubscribeOffers,
+		Params:  bz,
+		ID:      0,
+	}
+
 	if err = c.writeJSON(req); err != nil {
 		return nil, err
 	}
 
-	respCh := make(chan types.Status)
+	respCh := make(chan *rpctypes.OfferBookUpdate)
 
 	go func() {
 		defer close(respCh)
@@ -226,34 +449,86 @@ func (c *wsClient) SubscribeSwapStatus(id types.Hash) (<-chan types.Status, erro
 			}
 
 			log.Debugf("received message over websockets: %s", message)
-			statusResp := new(rpctypes.SubscribeSwapStatusResponse)
-			if err := vjson.UnmarshalStruct(resp.Result, statusResp); err != nil {
+			update := new(rpctypes.OfferBookUpdate)
+			if err := vjson.UnmarshalStruct(resp.Result, update); err != nil {
 				log.Warnf("failed to unmarshal response: %s", err)
 				break
 			}
 
-			status := statusResp.Status
-			respCh <- status
-			if !status.IsOngoing() {
-				return
-			}
+			respCh <- update
 		}
 	}()
 
 	return respCh, nil
 }
 
-func (c *wsClient) TakeOfferAndSubscribe(
-	peerID peer.ID,
-	offerID types.Hash,
-	providesAmount *apd.Decimal,
-) (ch <-chan types.Status, err error) {
-	params := &rpctypes.TakeOfferRequest{
-		PeerID:         peerID,
-		OfferID:        offerID,
-		ProvidesAmount: providesAmount,
+// SubscribeBalances returns a channel that is written to each time one of our ETH,
+// XMR or token balances changes, or drops below its threshold. The balances are
+// checked at the request's interval, or at the server's default interval if zero.
+func (c *wsClient) SubscribeBalances(
+	params *rpctypes.SubscribeBalancesRequest,
+) (<-chan *rpctypes.BalanceEvent, error) {
+	bz, err := vjson.MarshalStruct(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  rpctypes.SubscribeBalances,
+		Params:  bz,
+		ID:      0,
+	}
+
+	if err = c.writeJSON(req); err != nil {
+		return nil, err
 	}
 
+	respCh := make(chan *rpctypes.BalanceEvent)
+
+	go func() {
+		defer close(respCh)
+
+		for {
+			message, err := c.read()
+			if err != nil {
+				log.Warnf("failed to read websockets message: %s", err)
+				break
+			}
+
+			resp := new(rpctypes.Response)
+			err = vjson.UnmarshalStruct(message, resp)
+			if err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			if resp.Error != nil {
+				log.Warnf("websocket server returned error: %s", resp.Error)
+				break
+			}
+
+			log.Debugf("received message over websockets: %s", message)
+			event := new(rpctypes.BalanceEvent)
+			if err := vjson.UnmarshalStruct(resp.Result, event); err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			respCh <- event
+		}
+	}()
+
+	return respCh, nil
+}
+
+// SubscribeMoneroSync returns a channel that is written to each time the sync
+// progress of the monero wallet changes, starting with the current progress. The
+// progress is checked at the request's interval, or at the server's default interval
+// if zero.
+func (c *wsClient) SubscribeMoneroSync(
+	params *rpctypes.SubscribeMoneroSyncRequest,
+) (<-chan *rpctypes.MoneroSyncEvent, error) {
 	bz, err := vjson.MarshalStruct(params)
 	if err != nil {
 		return nil, err
@@ -261,7 +536,7 @@ func (c *wsClient) TakeOfferAndSubscribe(
 
 	req := &rpctypes.Request{
 		JSONRPC: rpctypes.DefaultJSONRPCVersion,
-		Method:  rpctypes.SubscribeTakeOffer,
+		Method:  rpctypes.SubscribeMoneroSync,
 		Params:  bz,
 		ID:      0,
 	}
@@ -270,8 +545,50 @@ func (c *wsClient) TakeOfferAndSubscribe(
 		return nil, err
 	}
 
-	// read resp from connection to see if there's an immediate error
-	status, err := c.readTakeOfferResponse()
+	respCh := make(chan *rpctypes.MoneroSyncEvent)
+
+	go func() {
+		defer close(respCh)
+
+		for {
+			message, err := c.read()
+			if err != nil {
+				log.Warnf("failed to read websockets message: %s", err)
+				break
+			}
+
+			resp := new(rpctypes.Response)
+			err = vjson.UnmarshalStruct(message, resp)
+			if err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			if resp.Error != nil {
+				log.Warnf("websocket server returned error: %s", resp.Error)
+				break
+			}
+
+			log.Debugf("received message over websockets: %s", message)
+			event := new(rpctypes.MoneroSyncEvent)
+			if err := vjson.UnmarshalStruct(resp.Result, event); err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			respCh <- event
+		}
+	}()
+
+	return respCh, nil
+}
+
+func (c *wsClient) TakeOfferAndSubscribe(
+	peerID peer.ID,
+	offerID types.Hash,
+	providesAmount *apd.Decimal,
+) (ch <-chan types.Status, err error) {
+	status, err := c.takeOffer(peerID, offerID, providesAmount)
 	if err != nil {
 		return nil, err
 	}
@@ -298,6 +615,36 @@ func (c *wsClient) TakeOfferAndSubscribe(
 	return respCh, nil
 }
 
+// takeOffer sends the request taking the offer, and returns the first status of the
+// swap.
+func (c *wsClient) takeOffer(peerID peer.ID, offerID types.Hash, providesAmount *apd.Decimal) (types.Status, error) {
+	params := &rpctypes.TakeOfferRequest{
+		PeerID:         peerID,
+		OfferID:        offerID,
+		ProvidesAmount: providesAmount,
+		Profile:        c.profile,
+	}
+
+	bz, err := vjson.MarshalStruct(params)
+	if err != nil {
+		return 0, err
+	}
+
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  rpctypes.SubscribeTakeOffer,
+		Params:  bz,
+		ID:      0,
+	}
+
+	if err = c.writeJSON(req); err != nil {
+		return 0, err
+	}
+
+	// read resp from connection to see if there's an immediate error
+	return c.readTakeOfferResponse()
+}
+
 func (c *wsClient) readTakeOfferResponse() (types.Status, error) {
 	message, err := c.read()
 	if err != nil {
@@ -330,14 +677,34 @@ func (c *wsClient) MakeOfferAndSubscribe(
 	ethAsset types.EthAsset,
 	useRelayer bool,
 ) (*rpctypes.MakeOfferResponse, <-chan types.Status, error) {
-	params := &rpctypes.MakeOfferRequest{
+	return c.makeOfferAndSubscribe(&rpctypes.MakeOfferRequest{
 		MinAmount:    min,
 		MaxAmount:    max,
 		ExchangeRate: exchangeRate,
 		EthAsset:     ethAsset,
 		UseRelayer:   useRelayer,
-	}
+		Profile:      c.profile,
+	})
+}
 
+func (c *wsClient) MakePeggedOfferAndSubscribe(
+	min *apd.Decimal,
+	max *apd.Decimal,
+	peg *types.OfferPeg,
+	useRelayer bool,
+) (*rpctypes.MakeOfferResponse, <-chan types.Status, error) {
+	return c.makeOfferAndSubscribe(&rpctypes.MakeOfferRequest{
+		MinAmount:  min,
+		MaxAmount:  max,
+		UseRelayer: useRelayer,
+		Profile:    c.profile,
+		Peg:        peg,
+	})
+}
+
+func (c *wsClient) makeOfferAndSubscribe(
+	params *rpctypes.MakeOfferRequest,
+) (*rpctypes.MakeOfferResponse, <-chan types.Status, error) {
 	bz, err := vjson.MarshalStruct(params)
 	if err != nil {
 		return nil, nil, err
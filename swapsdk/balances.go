@@ -0,0 +1,87 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package swapsdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/rpcclient"
+)
+
+// Balances returns the balances of swapd's ethereum and monero accounts, including
+// the balances of the tokens.
+func (c *Client) Balances(ctx context.Context, tokenAddrs ...ethcommon.Address) (*rpctypes.BalancesResponse, error) {
+	return c.RPC(ctx).Balances(&rpctypes.BalancesRequest{TokenAddrs: tokenAddrs})
+}
+
+// AssetBalance returns our balance of the ETH asset in standard units, ETH or the
+// token's standard units.
+func (c *Client) AssetBalance(ctx context.Context, asset types.EthAsset) (*apd.Decimal, error) {
+	if asset.IsETH() {
+		balances, err := c.Balances(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return balances.WeiBalance.AsEther(), nil
+	}
+
+	tokenAddr := ethcommon.Address(asset)
+	balances, err := c.Balances(ctx, tokenAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, balance := range balances.TokenBalances {
+		if balance.TokenAddress() == tokenAddr {
+			return balance.AsStandard(), nil
+		}
+	}
+	return nil, fmt.Errorf("no balance of token %s was returned", tokenAddr)
+}
+
+// UnlockedXMRBalance returns the balance of swapd's monero account that can be spent
+// now, in XMR.
+func (c *Client) UnlockedXMRBalance(ctx context.Context) (*apd.Decimal, error) {
+	balances, err := c.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return balances.PiconeroUnlockedBalance.AsMonero(), nil
+}
+
+// CheckAssetBalance returns an error matching rpcclient.ErrInsufficientBalance if our
+// balance of the ETH asset is below the amount, in standard units. The gas of the
+// swap's transactions is not included, so taking an offer with the whole ETH balance
+// still fails.
+func (c *Client) CheckAssetBalance(ctx context.Context, asset types.EthAsset, amount *apd.Decimal) error {
+	balance, err := c.AssetBalance(ctx, asset)
+	if err != nil {
+		return err
+	}
+	return checkBalance(asset.String(), balance, amount)
+}
+
+// CheckXMRBalance returns an error matching rpcclient.ErrInsufficientBalance if our
+// unlocked XMR balance is below the amount, in XMR.
+func (c *Client) CheckXMRBalance(ctx context.Context, amount *apd.Decimal) error {
+	balance, err := c.UnlockedXMRBalance(ctx)
+	if err != nil {
+		return err
+	}
+	return checkBalance("XMR", balance, amount)
+}
+
+func checkBalance(symbol string, balance *apd.Decimal, amount *apd.Decimal) error {
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("%w: balance of %s %s is below %s %s",
+			rpcclient.ErrInsufficientBalance, balance.Text('f'), symbol, amount.Text('f'), symbol)
+	}
+	return nil
+}
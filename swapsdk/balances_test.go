@@ -0,0 +1,22 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package swapsdk
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/rpcclient"
+)
+
+func TestCheckBalance(t *testing.T) {
+	require.NoError(t, checkBalance("ETH", apd.New(15, -1), apd.New(15, -1)))
+	require.NoError(t, checkBalance("ETH", apd.New(2, 0), apd.New(15, -1)))
+
+	err := checkBalance("XMR", apd.New(1, 0), apd.New(15, -1))
+	require.ErrorIs(t, err, rpcclient.ErrInsufficientBalance)
+	require.ErrorContains(t, err, "balance of 1 XMR is below 1.5 XMR")
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package swapsdk provides task-level operations on a swapd instance for programs that
+// swap, like trading bots. It wraps the JSON-RPC and websocket clients of rpcclient, so
+// making or taking an offer and following its swap to the end is a single call.
+package swapsdk
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/rpcclient"
+	"github.com/athanorlabs/atomic-swap/rpcclient/wsclient"
+)
+
+// Options are the optional settings of a Client.
+type Options struct {
+	// Options are the settings of the JSON-RPC client, whose auth token, TLS config
+	// and unix socket are used by the websocket connections too.
+	rpcclient.Options
+
+	// MaxReconnects and ReconnectBackoff configure how the connections following a
+	// swap's stages are restored when they are lost, see wsclient.StreamOptions.
+	MaxReconnects    int
+	ReconnectBackoff time.Duration
+
+	// WsMiddleware wraps the websocket connections, like Middleware wraps the
+	// JSON-RPC calls.
+	WsMiddleware []wsclient.Middleware
+}
+
+// Client performs swaps with a swapd instance.
+type Client struct {
+	rpc        *rpcclient.Client
+	wsEndpoint string
+	streamOpts *wsclient.StreamOptions
+}
+
+// NewClient returns a client of the swapd instance whose JSON-RPC server is at the
+// http or https endpoint, like http://127.0.0.1:5000. Its websocket endpoint is
+// derived from it.
+func NewClient(endpoint string, opts *Options) (*Client, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+
+	wsEndpoint, err := websocketEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		rpc:        rpcclient.NewClientWithOptions(context.Background(), endpoint, &opts.Options),
+		wsEndpoint: wsEndpoint,
+		streamOpts: &wsclient.StreamOptions{
+			Options: wsclient.Options{
+				AuthToken:  opts.AuthToken,
+				TLSConfig:  opts.TLSConfig,
+				UnixSocket: opts.UnixSocket,
+				Middleware: opts.WsMiddleware,
+			},
+			MaxReconnects:    opts.MaxReconnects,
+			ReconnectBackoff: opts.ReconnectBackoff,
+		},
+	}, nil
+}
+
+// RPC returns the JSON-RPC client whose calls use the context, for the operations
+// that the SDK doesn't wrap.
+func (c *Client) RPC(ctx context.Context) *rpcclient.Client {
+	return c.rpc.WithContext(ctx)
+}
+
+// websocketEndpoint returns the websocket endpoint of swapd's JSON-RPC endpoint.
+func websocketEndpoint(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid swapd endpoint %q: %w", endpoint, err)
+	}
+
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("invalid swapd endpoint %q: scheme must be http or https", endpoint)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/ws"
+	return u.String(), nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package swapsdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsocketEndpoint(t *testing.T) {
+	for endpoint, expected := range map[string]string{
+		"http://127.0.0.1:5000":      "ws://127.0.0.1:5000/ws",
+		"https://swapd.example.com/": "wss://swapd.example.com/ws",
+		"https://example.com/swapd":  "wss://example.com/swapd/ws",
+		"http://localhost:5000/?a=1": "ws://localhost:5000/ws?a=1",
+	} {
+		wsEndpoint, err := websocketEndpoint(endpoint)
+		require.NoError(t, err)
+		require.Equal(t, expected, wsEndpoint)
+	}
+
+	_, err := websocketEndpoint("127.0.0.1:5000")
+	require.Error(t, err)
+	_, err = websocketEndpoint("ftp://127.0.0.1:5000")
+	require.ErrorContains(t, err, "scheme must be http or https")
+}
@@ -0,0 +1,241 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package swapsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/rpcclient"
+	"github.com/athanorlabs/atomic-swap/rpcclient/wsclient"
+)
+
+// StageEvent is a change of the stage of a swap.
+type StageEvent struct {
+	OfferID types.Hash
+	Status  types.Status
+	// Time is when the client received the event
+	Time time.Time
+}
+
+// Done returns whether the swap exited at the event's stage.
+func (e *StageEvent) Done() bool {
+	return !e.Status.IsOngoing()
+}
+
+// Swap is a swap of ours, or an offer of ours that is waiting to be taken, whose
+// stage events are delivered on the channel returned by Events.
+type Swap struct {
+	OfferID types.Hash
+	// PeerID is the peer ID of the maker
+	PeerID peer.ID
+
+	events chan *StageEvent
+	status types.Status
+	err    error
+}
+
+func newSwap(offerID types.Hash, peerID peer.ID) *Swap {
+	return &Swap{
+		OfferID: offerID,
+		PeerID:  peerID,
+		events:  make(chan *StageEvent),
+	}
+}
+
+// Events returns the channel of the swap's stage events, which is closed after the
+// event of the swap's exit, or when following the swap fails.
+func (s *Swap) Events() <-chan *StageEvent {
+	return s.events
+}
+
+// Err returns why following the swap failed once the channel returned by Events is
+// closed. It is nil if the swap exited.
+func (s *Swap) Err() error {
+	return s.err
+}
+
+// Wait consumes the swap's stage events until the swap exits, and returns its exit
+// status.
+func (s *Swap) Wait() (types.Status, error) {
+	for range s.events { //nolint:revive
+	}
+
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.status, nil
+}
+
+// forward delivers the statuses of the channel as stage events. It returns true once
+// the swap exited.
+func (s *Swap) forward(ctx context.Context, statusCh <-chan types.Status) (bool, error) {
+	for status := range statusCh {
+		event := &StageEvent{
+			OfferID: s.OfferID,
+			Status:  status,
+			Time:    time.Now(),
+		}
+
+		select {
+		case s.events <- event:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+
+		s.status = status
+		if event.Done() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// MakeOfferAndWait makes the offer and returns its swap, whose events start when the
+// offer is taken. The offer is followed over a single websocket connection until it
+// is taken, so its swap fails if that connection is lost. Once taken, a lost
+// connection is restored.
+func (c *Client) MakeOfferAndWait(ctx context.Context, req *rpctypes.MakeOfferRequest) (*Swap, error) {
+	ws, err := wsclient.NewWsClientWithOptions(ctx, c.wsEndpoint, &c.streamOpts.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *rpctypes.MakeOfferResponse
+	var statusCh <-chan types.Status
+	if req.Peg != nil {
+		resp, statusCh, err = ws.MakePeggedOfferAndSubscribe(req.MinAmount, req.MaxAmount, req.Peg, req.UseRelayer)
+	} else {
+		resp, statusCh, err = ws.MakeOfferAndSubscribe(
+			req.MinAmount,
+			req.MaxAmount,
+			req.ExchangeRate,
+			req.EthAsset,
+			req.UseRelayer,
+		)
+	}
+	if err != nil {
+		ws.Close()
+		return nil, err
+	}
+
+	s := newSwap(resp.OfferID, resp.PeerID)
+	go func() {
+		defer close(s.events)
+
+		done, fwdErr := s.forward(ctx, statusCh)
+		ws.Close()
+		if done || fwdErr != nil {
+			s.err = fwdErr
+			return
+		}
+
+		// The subscription ended without the swap's exit, so the connection was lost.
+		// The swap can only be followed again if the offer was taken.
+		if s.status == types.UnknownStatus {
+			s.err = fmt.Errorf("lost the connection to swapd before offer %s was taken", s.OfferID)
+			return
+		}
+
+		s.err = c.followSwap(ctx, s)
+	}()
+
+	return s, nil
+}
+
+// TakeOfferAndWait takes the offer and returns its swap. Errors taking the offer are
+// returned, after which the swap's stage events follow, starting with its current
+// stage. A lost connection is restored without taking the offer again.
+func (c *Client) TakeOfferAndWait(
+	ctx context.Context,
+	peerID peer.ID,
+	offerID types.Hash,
+	providesAmount *apd.Decimal,
+) (*Swap, error) {
+	stream, err := wsclient.SubscribeTakeOffer(ctx, c.wsEndpoint, c.streamOpts, peerID, offerID, providesAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSwap(offerID, peerID)
+	go func() {
+		defer close(s.events)
+		s.err = s.forwardStream(ctx, stream)
+	}()
+
+	return s, nil
+}
+
+// WaitForSwap returns the swap of the offer that is ongoing, or that exited, whose
+// stage events start with its next stage, or with its exit status if it exited.
+func (c *Client) WaitForSwap(ctx context.Context, offerID types.Hash) (*Swap, error) {
+	stream, err := wsclient.SubscribeSwapStatus(ctx, c.wsEndpoint, c.streamOpts, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSwap(offerID, "")
+	go func() {
+		defer close(s.events)
+		s.err = s.forwardStream(ctx, stream)
+	}()
+
+	return s, nil
+}
+
+// followSwap delivers the stage events of the swap until it exits.
+func (c *Client) followSwap(ctx context.Context, s *Swap) error {
+	stream, err := wsclient.SubscribeSwapStatus(ctx, c.wsEndpoint, c.streamOpts, s.OfferID)
+	if err != nil {
+		return err
+	}
+
+	return s.forwardStream(ctx, stream)
+}
+
+// forwardStream delivers the statuses of the stream until it ends.
+func (s *Swap) forwardStream(ctx context.Context, stream *wsclient.StatusStream) error {
+	done, err := s.forward(ctx, stream.Status())
+	if done || err != nil {
+		// drain the stream, which ends when its context is done
+		for range stream.Status() { //nolint:revive
+		}
+		return err
+	}
+
+	if err = stream.Err(); err != nil {
+		return err
+	}
+	return errors.New("swap status stream ended before the swap exited")
+}
+
+// CancelAndRefund exits the ongoing swap of the offer, which refunds our locked funds
+// if the swap's stage allows it, and returns the swap's exit status. If the swap
+// already exited, its exit status is returned, so it can be retried safely.
+func (c *Client) CancelAndRefund(ctx context.Context, offerID types.Hash) (types.Status, error) {
+	rc := c.RPC(ctx)
+
+	status, err := rc.Cancel(offerID)
+	if err == nil {
+		return status, nil
+	}
+	if !errors.Is(err, rpcclient.ErrSwapNotFound) {
+		return 0, err
+	}
+
+	past, pastErr := rc.GetPastSwap(&offerID)
+	if pastErr != nil || len(past.Swaps) == 0 {
+		return 0, err
+	}
+
+	return past.Swaps[0].Status, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package swapsdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestSwap_forward(t *testing.T) {
+	s := newSwap(types.Hash{1}, "")
+
+	statusCh := make(chan types.Status, 3)
+	statusCh <- types.ExpectingKeys
+	statusCh <- types.ETHLocked
+	statusCh <- types.CompletedSuccess
+	close(statusCh)
+
+	var done bool
+	go func() {
+		defer close(s.events)
+		done, s.err = s.forward(context.Background(), statusCh)
+	}()
+
+	event := <-s.Events()
+	require.Equal(t, types.Hash{1}, event.OfferID)
+	require.Equal(t, types.ExpectingKeys, event.Status)
+	require.False(t, event.Done())
+
+	status, err := s.Wait()
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedSuccess, status)
+	require.True(t, done)
+}
+
+func TestSwap_forward_cancelled(t *testing.T) {
+	s := newSwap(types.Hash{1}, "")
+
+	statusCh := make(chan types.Status, 1)
+	statusCh <- types.ExpectingKeys
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// nobody reads the events, so the cancellation ends the forwarding
+	done, err := s.forward(ctx, statusCh)
+	require.False(t, done)
+	require.ErrorIs(t, err, context.Canceled)
+}
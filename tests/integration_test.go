@@ -159,7 +159,7 @@ func (s *IntegrationTestSuite) testXMRTakerQuery(asset types.EthAsset) {
 	require.NoError(s.T(), err)
 	require.Equal(s.T(), 1, len(peerIDs))
 
-	resp, err := ac.Query(peerIDs[0])
+	resp, err := ac.Query(peerIDs[0], nil)
 	require.NoError(s.T(), err)
 	require.GreaterOrEqual(s.T(), len(resp.Offers), 1)
 	var respOffer *types.Offer
@@ -0,0 +1,378 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package webhook delivers the lifecycle events of swaps to user-registered URLs, so
+// that swaps can be integrated into existing alerting and accounting pipelines.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	logging "github.com/ipfs/go-log"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+const (
+	// SignatureHeader is the header holding the hex encoded HMAC-SHA256 of the
+	// payload, keyed with the webhook's secret, prefixed with "sha256=".
+	SignatureHeader = "X-Swapd-Signature"
+
+	// EventHeader is the header holding the event of the payload.
+	EventHeader = "X-Swapd-Event"
+
+	// queueSize is the number of events that can wait for delivery to a webhook
+	// before new events are dropped.
+	queueSize = 256
+
+	deliveryTimeout  = 10 * time.Second
+	deliveryAttempts = 4
+	retryBaseDelay   = 2 * time.Second
+)
+
+var log = logging.Logger("webhook")
+
+var errInvalidURL = errors.New("webhook URL must be an absolute http or https URL")
+
+// Event is a swap lifecycle event delivered to webhooks.
+type Event string
+
+// Events delivered to webhooks
+const (
+	// EventOfferTaken is delivered when a swap starts, as we took an offer or one
+	// of our offers was taken.
+	EventOfferTaken Event = "offer_taken"
+	// EventETHLocked is delivered when we locked the ETH of a swap.
+	EventETHLocked Event = "eth_locked"
+	// EventXMRLocked is delivered when we locked the XMR of a swap.
+	EventXMRLocked Event = "xmr_locked"
+	// EventReady is delivered when we set the swap contract to ready.
+	EventReady Event = "ready"
+	// EventClaimed is delivered when a swap completed successfully.
+	EventClaimed Event = "claimed"
+	// EventRefunded is delivered when a swap was refunded.
+	EventRefunded Event = "refunded"
+	// EventFailed is delivered when a swap aborted before any funds were locked.
+	EventFailed Event = "failed"
+)
+
+// allEvents are the valid events.
+var allEvents = map[Event]struct{}{
+	EventOfferTaken: {},
+	EventETHLocked:  {},
+	EventXMRLocked:  {},
+	EventReady:      {},
+	EventClaimed:    {},
+	EventRefunded:   {},
+	EventFailed:     {},
+}
+
+// eventForStatus returns the event of a swap changing to the status, if any.
+func eventForStatus(status types.Status) (Event, bool) {
+	switch status {
+	case types.ETHLocked:
+		return EventETHLocked, true
+	case types.XMRLocked:
+		return EventXMRLocked, true
+	case types.ContractReady:
+		return EventReady, true
+	case types.CompletedSuccess:
+		return EventClaimed, true
+	case types.CompletedRefund:
+		return EventRefunded, true
+	case types.CompletedAbort:
+		return EventFailed, true
+	default:
+		return "", false
+	}
+}
+
+// Webhook is a URL that the events are POSTed to.
+type Webhook struct {
+	URL string `json:"url" validate:"required"`
+	// Secret, if set, is the key of the HMAC-SHA256 signature of the payloads.
+	Secret string `json:"secret,omitempty"`
+	// Events are the events delivered to the webhook, all events if empty.
+	Events []Event `json:"events,omitempty"`
+}
+
+// Validate returns an error if the webhook's URL or events are invalid.
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", errInvalidURL, w.URL)
+	}
+
+	for _, event := range w.Events {
+		if _, ok := allEvents[event]; !ok {
+			return fmt.Errorf("unknown webhook event %q", event)
+		}
+	}
+
+	return nil
+}
+
+func (w *Webhook) wants(event Event) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body POSTed to webhooks.
+type Payload struct {
+	Event          Event               `json:"event"`
+	SwapID         types.Hash          `json:"swapID"`
+	Provided       coins.ProvidesCoin  `json:"provided"`
+	EthAsset       types.EthAsset      `json:"ethAsset"`
+	ProvidedAmount *apd.Decimal        `json:"providedAmount"`
+	ExpectedAmount *apd.Decimal        `json:"expectedAmount"`
+	ExchangeRate   *coins.ExchangeRate `json:"exchangeRate"`
+	Status         types.Status        `json:"status"`
+	Timestamp      time.Time           `json:"timestamp"`
+}
+
+// Sign returns the value of the signature header of the payload for the secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// subscriber delivers the events of one webhook in order.
+type subscriber struct {
+	hook   *Webhook
+	queue  chan *delivery
+	cancel context.CancelFunc
+}
+
+type delivery struct {
+	event Event
+	body  []byte
+}
+
+// Dispatcher delivers the lifecycle events of swaps to the registered webhooks. It
+// implements swap.EventListener.
+type Dispatcher struct {
+	ctx    context.Context
+	client *http.Client
+
+	mu   sync.Mutex
+	subs map[string]*subscriber // by URL
+}
+
+var _ swap.EventListener = (*Dispatcher)(nil)
+
+// NewDispatcher returns a Dispatcher delivering events to the webhooks until the
+// context is cancelled. Non-local webhooks are connected to through the SOCKS5
+// proxy, unless it is nil.
+func NewDispatcher(ctx context.Context, hooks []*Webhook, proxy *common.Proxy) (*Dispatcher, error) {
+	d := &Dispatcher{
+		ctx: ctx,
+		client: &http.Client{
+			Transport: proxy.HTTPTransport(),
+			Timeout:   deliveryTimeout,
+		},
+		subs: make(map[string]*subscriber),
+	}
+
+	for _, hook := range hooks {
+		if err := d.Add(hook); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// Add registers the webhook, replacing any webhook with the same URL.
+func (d *Dispatcher) Add(hook *Webhook) error {
+	if err := hook.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(d.ctx)
+	sub := &subscriber{
+		hook:   hook,
+		queue:  make(chan *delivery, queueSize),
+		cancel: cancel,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if old, ok := d.subs[hook.URL]; ok {
+		old.cancel()
+	}
+	d.subs[hook.URL] = sub
+	go d.deliverAll(ctx, sub)
+
+	return nil
+}
+
+// Remove unregisters the webhook with the URL. Events that were not delivered yet
+// are dropped.
+func (d *Dispatcher) Remove(hookURL string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sub, ok := d.subs[hookURL]
+	if !ok {
+		return fmt.Errorf("no webhook with URL %q", hookURL)
+	}
+
+	sub.cancel()
+	delete(d.subs, hookURL)
+	return nil
+}
+
+// Webhooks returns the registered webhooks, without their secrets.
+func (d *Dispatcher) Webhooks() []*Webhook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	hooks := make([]*Webhook, 0, len(d.subs))
+	for _, sub := range d.subs {
+		hooks = append(hooks, &Webhook{
+			URL:    sub.hook.URL,
+			Events: sub.hook.Events,
+		})
+	}
+
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].URL < hooks[j].URL
+	})
+
+	return hooks
+}
+
+// SwapStarted implements swap.EventListener.
+func (d *Dispatcher) SwapStarted(info *swap.Info) {
+	d.publish(EventOfferTaken, info)
+}
+
+// SwapStatusChanged implements swap.EventListener.
+func (d *Dispatcher) SwapStatusChanged(info *swap.Info) {
+	if event, ok := eventForStatus(info.Status); ok {
+		d.publish(event, info)
+	}
+}
+
+// publish queues the event for delivery to the webhooks that want it. It doesn't
+// block, as it's called with the swap manager's lock held.
+func (d *Dispatcher) publish(event Event, info *swap.Info) {
+	body, err := json.Marshal(&Payload{
+		Event:          event,
+		SwapID:         info.OfferID,
+		Provided:       info.Provides,
+		EthAsset:       info.EthAsset,
+		ProvidedAmount: info.ProvidedAmount,
+		ExpectedAmount: info.ExpectedAmount,
+		ExchangeRate:   info.ExchangeRate,
+		Status:         info.Status,
+		Timestamp:      time.Now(),
+	})
+	if err != nil {
+		log.Warnf("failed to serialize %s event of swap %s: %s", event, info.OfferID, err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, sub := range d.subs {
+		if !sub.hook.wants(event) {
+			continue
+		}
+
+		select {
+		case sub.queue <- &delivery{event: event, body: body}:
+		default:
+			log.Warnf("dropped %s event of swap %s for webhook %s, too many undelivered events",
+				event, info.OfferID, sub.hook.URL)
+		}
+	}
+}
+
+// deliverAll delivers the queued events of the subscriber in order until the
+// context is cancelled.
+func (d *Dispatcher) deliverAll(ctx context.Context, sub *subscriber) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case del := <-sub.queue:
+			d.deliver(ctx, sub.hook, del)
+		}
+	}
+}
+
+// deliver POSTs the event to the webhook, retrying with exponential backoff until
+// it is answered with a 2xx status.
+func (d *Dispatcher) deliver(ctx context.Context, hook *Webhook, del *delivery) {
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := d.post(ctx, hook, del)
+		if err == nil {
+			return
+		}
+
+		if attempt == deliveryAttempts {
+			log.Warnf("failed to deliver %s event to webhook %s: %s", del.event, hook.URL, err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+			delay *= 2
+		}
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, hook *Webhook, del *delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(del.body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(del.event))
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, del.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered with status %s", resp.Status)
+	}
+
+	return nil
+}
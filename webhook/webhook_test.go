@@ -0,0 +1,108 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, <-chan *received) {
+	ch := make(chan *received, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		ch <- &received{header: r.Header, body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, ch
+}
+
+func TestDispatcher(t *testing.T) {
+	server, ch := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// nothing listens on the proxy's port, so the webhook is only called if local
+	// webhooks are connected to directly
+	proxy, err := common.NewProxy("127.0.0.1:1")
+	require.NoError(t, err)
+
+	d, err := NewDispatcher(ctx, []*Webhook{{
+		URL:    server.URL,
+		Secret: "secret",
+		Events: []Event{EventClaimed},
+	}}, proxy)
+	require.NoError(t, err)
+
+	info := &swap.Info{
+		OfferID:        types.Hash{1},
+		Provides:       coins.ProvidesXMR,
+		ProvidedAmount: apd.New(1, 0),
+		ExpectedAmount: apd.New(2, 0),
+		ExchangeRate:   coins.ToExchangeRate(apd.New(2, 0)),
+		EthAsset:       types.EthAssetETH,
+		Status:         types.ExpectingKeys,
+	}
+
+	// the webhook only wants claimed events
+	d.SwapStarted(info)
+	info.Status = types.CompletedSuccess
+	d.SwapStatusChanged(info)
+
+	var r *received
+	select {
+	case r = <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+
+	require.Equal(t, string(EventClaimed), r.header.Get(EventHeader))
+	require.Equal(t, Sign("secret", r.body), r.header.Get(SignatureHeader))
+
+	payload := new(Payload)
+	require.NoError(t, json.Unmarshal(r.body, payload))
+	require.Equal(t, EventClaimed, payload.Event)
+	require.Equal(t, info.OfferID, payload.SwapID)
+	require.Equal(t, types.CompletedSuccess, payload.Status)
+
+	select {
+	case r = <-ch:
+		t.Fatalf("unexpected %s event", r.header.Get(EventHeader))
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// secrets are not listed
+	require.Equal(t, []*Webhook{{URL: server.URL, Events: []Event{EventClaimed}}}, d.Webhooks())
+	require.NoError(t, d.Remove(server.URL))
+	require.Empty(t, d.Webhooks())
+	require.Error(t, d.Remove(server.URL))
+}
+
+func TestWebhook_Validate(t *testing.T) {
+	require.NoError(t, (&Webhook{URL: "https://example.org/hook"}).Validate())
+	require.ErrorIs(t, (&Webhook{URL: "example.org/hook"}).Validate(), errInvalidURL)
+	require.ErrorIs(t, (&Webhook{URL: "ftp://example.org/hook"}).Validate(), errInvalidURL)
+
+	hook := &Webhook{URL: "https://example.org/hook", Events: []Event{"exploded"}}
+	require.ErrorContains(t, hook.Validate(), `unknown webhook event "exploded"`)
+}